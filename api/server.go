@@ -2,10 +2,14 @@ package api
 
 import (
 	"log"
+	"net/http"
 
 	"github.com/ThinkInkTeam/thinkink-core-backend/docs"
+	_ "github.com/ThinkInkTeam/thinkink-core-backend/docs/v1"
 	"github.com/ThinkInkTeam/thinkink-core-backend/handlers"
 	"github.com/ThinkInkTeam/thinkink-core-backend/middleware"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -29,41 +33,204 @@ func SetupRouter() *gin.Engine {
 	docs.SwaggerInfo.BasePath = "/"
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	r.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization")
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-		c.Next()
-	})
+	// Versioned OpenAPI documents for client code generation
+	r.GET("/openapi/:version", handlers.GetOpenAPISpec)
+
+	// Browsers may call the API from CORS_ALLOWED_ORIGINS; embedded widgets get
+	// their own origin-scoped access to the shared report endpoint only
+	allowedOrigins, err := middleware.AllowedOriginsFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	r.Use(middleware.CORS(allowedOrigins))
+
+	// Bound JSON request bodies; strict routes also reject unknown fields
+	strict := middleware.DefaultJSONLimitOptions()
+	strict.MaxBytes = 64 << 10
+	strict.MaxDepth = 8
+	strict.DisallowUnknownFields = true
+
+	r.Use(middleware.JSONLimits(middleware.DefaultJSONLimitOptions(), map[string]middleware.JSONLimitOptions{
+		"POST /signin":                         strict,
+		"POST /signup":                         strict,
+		"POST /forgot-password":                strict,
+		"POST /reset-password":                 strict,
+		"POST /device-tokens":                  strict,
+		"POST /match":                          strict,
+		"PUT /user/:id/update":                 strict,
+		"PATCH /reports/:id":                   strict,
+		"POST /reports/:id/share":              strict,
+		"POST /reports/batch":                  strict,
+		"PUT /admin/orgs/:id/branding":         strict,
+		"PUT /admin/orgs/:id/moderation":       strict,
+		"POST /admin/orgs/:id/widgets":         strict,
+		"POST /admin/report-templates":         strict,
+		"PUT /admin/report-templates/:id":      strict,
+		"POST /webhooks":                       strict,
+		"POST /payment/checkout/subscription":  strict,
+		"POST /payment/checkout/one-time":      strict,
+		"POST /payment/tax-ids":                strict,
+		"POST /payment/iap/apple":              strict,
+		"POST /payment/iap/google":             strict,
+		"POST /orgs/:id/subscription/checkout": strict,
+		"PUT /orgs/:id/subscription/seats":     strict,
+	}))
+
+	// Injected latency and errors when chaos testing is enabled (never in production)
+	r.Use(middleware.Chaos())
 
 	// Public routes
 	r.POST("/signin", handlers.SignIn)
 	r.POST("/signup", handlers.SignUp)
 	r.POST("/validate-ml-token", handlers.ValidateMLToken)
+	r.POST("/verify-email", handlers.VerifyEmail)
+	r.POST("/forgot-password", handlers.ForgotPassword)
+	r.POST("/reset-password", handlers.ResetPassword)
+
+	// Read-only views of reports shared by link
+	shared := r.Group("/shared")
+	shared.Use(middleware.WidgetCORS(allowedOrigins))
+	{
+		shared.GET("/:token", handlers.GetSharedReport)
+		shared.OPTIONS("/:token", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	}
+
+	// Fake in-memory data for frontend development; never served in production
+	if utils.GetEnvWithDefault("APP_ENV", "development") != "production" {
+		demoRoutes := r.Group("/demo")
+		{
+			demoRoutes.GET("/users", handlers.GetDemoUsers)
+			demoRoutes.GET("/users/:id", handlers.GetDemoUser)
+			demoRoutes.GET("/users/:id/reports", handlers.GetDemoUserReports)
+			demoRoutes.GET("/users/:id/subscription", handlers.GetDemoUserSubscription)
+			demoRoutes.GET("/reports/:id", handlers.GetDemoReport)
+			demoRoutes.GET("/subscriptions", handlers.GetDemoSubscriptions)
+		}
+	}
+
+	// Signed download links of background report exports
+	r.GET("/exports/:id/download", handlers.DownloadSignedReportExport)
+
+	// Organization logos shown in branded emails
+	r.GET("/branding/:id/logo", handlers.GetOrgLogo)
+
+	// Public status page data and readiness probe
+	r.GET("/status", handlers.GetStatus)
+	r.GET("/readyz", handlers.GetReadiness)
+
+	// Plan catalog for pricing pages
+	r.GET("/payment/plans", handlers.GetPlansHandler)
 
 	// Stripe webhook handler - needs to be public to receive Stripe events
 	r.POST("/stripe/webhook", handlers.StripeWebhookHandler)
 
+	// Email provider bounce/complaint webhook
+	r.POST("/email/webhook", handlers.EmailWebhookHandler)
+
+	// ML team exports, authenticated with a service key
+	ml := r.Group("/ml")
+	ml.Use(middleware.RequireServiceCredential(models.ScopeMLExport))
+	{
+		ml.GET("/feedback-export", handlers.ExportModelFeedback)
+	}
+
 	// Protected routes - require authentication
 	authenticated := r.Group("/")
-	authenticated.Use(middleware.AuthMiddleware())
+	authenticated.Use(middleware.AuthMiddleware(), middleware.RateLimit())
 	{
 		// User routes
 		authenticated.GET("/user/:id", handlers.GetUser)
 		authenticated.PUT("/user/:id/update", handlers.UpdateUser)
+		authenticated.POST("/user/:id/deactivate", handlers.DeactivateUser)
+		authenticated.PUT("/user/encryption", handlers.SetReportEncryption)
+		authenticated.GET("/user/text-processing", handlers.GetTextProcessing)
+		authenticated.PUT("/user/text-processing", handlers.SetTextProcessing)
+
+		// Tokens for headsets, limited to uploading recordings
+		authenticated.POST("/device-tokens", handlers.IssueDeviceToken)
 
 		// File upload route
 		authenticated.POST("/upload", handlers.UploadSignalFile)
+		authenticated.POST("/upload/session", handlers.UploadSession)
+		authenticated.GET("/sessions/:id", handlers.GetSession)
+		authenticated.GET("/files", handlers.GetUserFiles)
+		authenticated.GET("/files/:id/status", handlers.GetFileStatus)
+		authenticated.GET("/files/:id/preview", handlers.GetFilePreview)
+		authenticated.POST("/files/:id/retranslate", handlers.RetranslateFile)
+		authenticated.GET("/events", handlers.StreamEvents)
+		authenticated.DELETE("/files/:id", handlers.DeleteFile)
 
 		// Reports routes
 		authenticated.GET("/reports", handlers.GetUserReports)
-		authenticated.GET("/reports/sorted", handlers.GetUserReportsSortedByScale)
+		authenticated.GET("/reports/matching-scale/timeseries", handlers.GetMatchingScaleSeries)
+		authenticated.GET("/reports/search", handlers.SearchReports)
+		authenticated.GET("/reports/export", handlers.ExportReports)
+		authenticated.GET("/exports", handlers.GetReportExports)
+		authenticated.GET("/reports/exports/:id", handlers.GetReportExport)
+		authenticated.GET("/reports/exports/:id/download", handlers.DownloadReportExport)
+		authenticated.POST("/reports/batch", handlers.BatchReports)
+		authenticated.GET("/reports/:id", handlers.GetReport)
+		authenticated.GET("/reports/:id/download", handlers.DownloadReport)
+		authenticated.GET("/reports/:id/source-file", handlers.DownloadReportSourceFile)
+		authenticated.GET("/reports/:id/export", handlers.ExportReport)
+		authenticated.GET("/reports/:id/similar", handlers.GetSimilarReports)
+		authenticated.PATCH("/reports/:id", handlers.UpdateReport)
+		authenticated.DELETE("/reports/:id", handlers.DeleteReport)
+		authenticated.POST("/reports/:id/archive", handlers.ArchiveReport)
+		authenticated.POST("/reports/:id/unarchive", handlers.UnarchiveReport)
+		authenticated.PUT("/reports/:id/correction", handlers.SetReportCorrection)
+		authenticated.GET("/reports/:id/revisions", handlers.GetReportRevisions)
+		authenticated.GET("/reports/:id/access-log", handlers.GetReportAccessLog)
+		authenticated.POST("/reports/:id/revisions/:revisionId/restore", handlers.RestoreReportRevision)
+		authenticated.POST("/reports/:id/share", handlers.CreateReportShare)
+		authenticated.GET("/reports/:id/shares", handlers.GetReportShares)
+		authenticated.DELETE("/reports/:id/shares/:shareId", handlers.RevokeReportShare)
+		authenticated.POST("/reports/:id/tags", handlers.AddReportTag)
+		authenticated.DELETE("/reports/:id/tags/:name", handlers.RemoveReportTag)
+		authenticated.GET("/tags", handlers.GetTags)
+		authenticated.GET("/report-templates", handlers.GetReportTemplates)
+		authenticated.GET("/report-templates/:id", handlers.GetReportTemplate)
 		authenticated.POST("/match", handlers.UpdateReportMatchingScale)
 
+		// Email verification and onboarding checklist
+		authenticated.POST("/verify-email/resend", handlers.ResendVerificationEmail)
+		authenticated.GET("/onboarding", handlers.GetOnboarding)
+
+		// Plan quotas and usage
+		authenticated.GET("/usage", handlers.GetUsage)
+		authenticated.GET("/entitlements", handlers.GetEntitlements)
+
+		// Outbound webhooks for report lifecycle events
+		authenticated.GET("/webhooks", handlers.GetWebhooks)
+		authenticated.POST("/webhooks", handlers.CreateWebhook)
+		authenticated.DELETE("/webhooks/:id", handlers.DeleteWebhook)
+		authenticated.GET("/webhooks/:id/deliveries", handlers.GetWebhookDeliveries)
+
+		// In-app notifications
+		authenticated.GET("/notifications", handlers.GetNotifications)
+		authenticated.POST("/notifications/:id/read", handlers.MarkNotificationRead)
+
+		// Caregiver/clinician account links
+		authenticated.GET("/links", handlers.GetLinks)
+		authenticated.POST("/links", handlers.CreateLinkInvitation)
+		authenticated.POST("/links/accept", handlers.AcceptLinkInvitation)
+		authenticated.PUT("/links/:id/expiry", handlers.UpdateLinkExpiry)
+		authenticated.DELETE("/links/:id", handlers.RevokeLink)
+
+		// GraphQL API over the user's profile, reports, tags and files
+		graphQL := handlers.GraphQL()
+		authenticated.GET("/graphql", graphQL)
+		authenticated.POST("/graphql", graphQL)
+
+		// Organization audit log, for the organization's admins
+		authenticated.GET("/orgs/:id/audit-logs", handlers.GetOrgAuditLogs)
+		authenticated.GET("/orgs/:id/audit-logs/export", handlers.ExportOrgAuditLogs)
+
+		// Seat-based organization subscriptions, for the organization's admins
+		authenticated.GET("/orgs/:id/subscription", handlers.GetOrgSubscription)
+		authenticated.POST("/orgs/:id/subscription/checkout", handlers.CreateOrgCheckoutHandler)
+		authenticated.PUT("/orgs/:id/subscription/seats", handlers.SetOrgSeatsHandler)
+
 		// Payment routes
 		payment := authenticated.Group("/payment")
 		{
@@ -74,6 +241,75 @@ func SetupRouter() *gin.Engine {
 			// Subscription management
 			payment.GET("/subscription", handlers.GetSubscriptionHandler)
 			payment.POST("/subscription/cancel", handlers.CancelSubscriptionHandler)
+
+			// Invoice PDFs, proxied from Stripe
+			payment.GET("/invoices/:id/pdf", handlers.GetInvoicePDFHandler)
+
+			// Subscriptions bought in the mobile apps
+			payment.POST("/iap/apple", handlers.ValidateAppleReceiptHandler)
+			payment.POST("/iap/google", handlers.ValidateGooglePurchaseHandler)
+
+			// Tax IDs for invoices
+			payment.GET("/tax-ids", handlers.GetTaxIDsHandler)
+			payment.POST("/tax-ids", handlers.CreateTaxIDHandler)
+			payment.DELETE("/tax-ids/:id", handlers.DeleteTaxIDHandler)
+		}
+
+		// Admin routes
+		admin := authenticated.Group("/admin")
+		admin.Use(middleware.RequireAdmin())
+		{
+			admin.GET("/config", handlers.GetConfigHandler)
+			admin.POST("/config/reload", handlers.ReloadConfigHandler)
+			admin.GET("/migrations", handlers.GetPendingMigrations)
+			admin.POST("/users/:id/deactivate", handlers.AdminDeactivateUser)
+			admin.POST("/users/:id/reactivate", handlers.AdminReactivateUser)
+			admin.PUT("/users/:id/organization", handlers.SetUserOrganization)
+			admin.POST("/users/import", handlers.ImportUsers)
+			admin.GET("/users/inactive", handlers.GetInactiveUsers)
+			admin.GET("/users/:id/logins", handlers.GetUserLoginHistory)
+			admin.GET("/analytics/logins", handlers.GetLoginSummary)
+			admin.GET("/stripe-events", handlers.GetStripeEvents)
+			admin.POST("/stripe-events/:id/retry", handlers.RetryStripeEvent)
+			admin.GET("/billing/metrics", handlers.GetBillingMetrics)
+
+			// Organizations and their custom rate plans
+			admin.GET("/orgs", handlers.GetOrganizations)
+			admin.POST("/orgs", handlers.CreateOrganization)
+			admin.GET("/orgs/:id/rate-plan", handlers.GetOrgRatePlan)
+			admin.PUT("/orgs/:id/rate-plan", handlers.SetOrgRatePlan)
+			admin.DELETE("/orgs/:id/rate-plan", handlers.DeleteOrgRatePlan)
+			admin.GET("/orgs/:id/retention-report", handlers.GetOrgRetentionReport)
+			admin.PUT("/orgs/:id/text-processing", handlers.SetOrgTextProcessing)
+			admin.GET("/orgs/:id/moderation", handlers.GetOrgModeration)
+			admin.PUT("/orgs/:id/moderation", handlers.SetOrgModeration)
+			admin.DELETE("/orgs/:id/moderation", handlers.DeleteOrgModeration)
+			admin.GET("/orgs/:id/branding", handlers.GetOrgBranding)
+			admin.PUT("/orgs/:id/branding", handlers.SetOrgBranding)
+			admin.DELETE("/orgs/:id/branding", handlers.DeleteOrgBranding)
+			admin.PUT("/orgs/:id/branding/logo", handlers.UploadOrgLogo)
+			admin.DELETE("/orgs/:id/branding/logo", handlers.DeleteOrgLogo)
+			admin.GET("/orgs/:id/widgets", handlers.GetOrgWidgets)
+			admin.POST("/orgs/:id/widgets", handlers.CreateOrgWidget)
+			admin.DELETE("/orgs/:id/widgets/:widgetId", handlers.RevokeOrgWidget)
+
+			// Service keys and the audit trail
+			admin.GET("/service-credentials", handlers.GetServiceCredentials)
+			admin.POST("/service-credentials", handlers.CreateServiceCredential)
+			admin.DELETE("/service-credentials/:id", handlers.RevokeServiceCredential)
+			admin.GET("/audit-logs", handlers.GetAuditLogs)
+
+			// Report templates for structured clinical notes
+			admin.GET("/report-templates", handlers.GetAllReportTemplates)
+			admin.POST("/report-templates", handlers.CreateReportTemplate)
+			admin.PUT("/report-templates/:id", handlers.UpdateReportTemplate)
+			admin.DELETE("/report-templates/:id", handlers.ArchiveReportTemplate)
+
+			// Broadcast notifications
+			admin.GET("/broadcasts", handlers.GetBroadcasts)
+			admin.POST("/broadcasts", handlers.CreateBroadcast)
+			admin.GET("/broadcasts/:id", handlers.GetBroadcast)
+			admin.DELETE("/broadcasts/:id", handlers.CancelBroadcast)
 		}
 	}
 
@@ -81,8 +317,7 @@ func SetupRouter() *gin.Engine {
 }
 
 // RunServer starts the API server on the specified port
-func RunServer(port string) {
-	r := SetupRouter()
+func RunServer(r *gin.Engine, port string) {
 	log.Printf("Server starting on port %s", port)
 	if err := r.Run(":" + port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// SignInResult is the response of signing in
+type SignInResult struct {
+	Token string `json:"token"`
+	User  struct {
+		ID    uint   `json:"id"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"user"`
+}
+
+// SignIn exchanges an email and password for a token, which authenticates the
+// client's later requests
+func (c *Client) SignIn(ctx context.Context, email, password string) (*SignInResult, error) {
+	c.mu.Lock()
+	audience := c.audience
+	c.mu.Unlock()
+
+	var result SignInResult
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/signin",
+		body: map[string]string{
+			"email":    email,
+			"password": password,
+			"audience": audience,
+		},
+		public: true,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	c.SetToken(result.Token)
+	return &result, nil
+}
+
+// canSignIn reports whether the client can sign in on its own
+func (c *Client) canSignIn() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.email != "" && c.password != ""
+}
+
+// ensureToken signs in with the configured credentials when the client has no token
+func (c *Client) ensureToken(ctx context.Context) error {
+	if c.Token() != "" {
+		return nil
+	}
+	if !c.canSignIn() {
+		return errors.New("thinkink: no token or credentials configured")
+	}
+	c.mu.Lock()
+	email, password := c.email, c.password
+	c.mu.Unlock()
+	_, err := c.SignIn(ctx, email, password)
+	return err
+}
@@ -0,0 +1,328 @@
+// Package client is a typed Go client for the ThinkInk API, for internal
+// services and CLI tooling. It handles authentication, retries throttled and
+// failed requests, and pages through listings with iterators.
+//
+//	c, err := client.New("https://api.thinkink.app", client.WithCredentials(email, password))
+//	if err != nil {
+//		return err
+//	}
+//	for report, err := range c.Reports(ctx, client.ReportQuery{Tags: []string{"morning"}}) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(report.ID, report.Title)
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of a new client
+const (
+	DefaultTimeout    = 30 * time.Second
+	DefaultMaxRetries = 3
+	DefaultUserAgent  = "thinkink-go-client"
+)
+
+// maxRetryDelay caps how long a retry waits, whatever Retry-After says
+const maxRetryDelay = time.Minute
+
+// Client calls the ThinkInk REST API. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	userAgent  string
+	maxRetries int
+	backoff    time.Duration
+
+	mu       sync.Mutex
+	token    string
+	email    string
+	password string
+	audience string
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithToken authenticates requests with a JWT from POST /signin
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithCredentials signs in with the account's email and password before the
+// first authenticated request, and again when the token expires
+func WithCredentials(email, password string) Option {
+	return func(c *Client) { c.email, c.password = email, password }
+}
+
+// WithAudience sets the client the token is issued for: web (default) or mobile
+func WithAudience(audience string) Option {
+	return func(c *Client) { c.audience = audience }
+}
+
+// WithHTTPClient sends requests with the given HTTP client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithRetries sets how many times a failed request is retried and the base
+// delay of the exponential backoff between attempts; 0 retries disables them
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) { c.maxRetries, c.backoff = maxRetries, backoff }
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.userAgent = userAgent }
+}
+
+// New creates a client for the API at baseURL, e.g. http://localhost:8080
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		userAgent:  DefaultUserAgent,
+		maxRetries: DefaultMaxRetries,
+		backoff:    500 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Token returns the JWT requests are authenticated with, if any
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// SetToken replaces the JWT requests are authenticated with
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+// APIError is an error response from the API
+type APIError struct {
+	StatusCode int
+	// The error message from the response body
+	Message string
+	// How long the server asked to wait before retrying, from Retry-After
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("thinkink: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// IsNotFound reports whether err is a 404 response
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// request describes one API call
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	// JSON-encoded unless contentType is set
+	body        interface{}
+	contentType string
+	headers     http.Header
+	// Sent without a token, e.g. signing in
+	public bool
+}
+
+// do sends the request, retrying as configured, and decodes a successful
+// JSON response into out unless it is nil
+func (c *Client) do(ctx context.Context, req request, out interface{}) error {
+	var payload []byte
+	contentType := req.contentType
+	switch body := req.body.(type) {
+	case nil:
+	case []byte:
+		payload = body
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("thinkink: encode request: %w", err)
+		}
+		payload = data
+		contentType = "application/json"
+	}
+
+	if !req.public {
+		if err := c.ensureToken(ctx); err != nil {
+			return err
+		}
+	}
+
+	reauthenticated := false
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, req, payload, contentType)
+		if err != nil {
+			if ctx.Err() != nil || attempt >= c.maxRetries || !idempotent(req.method) {
+				return fmt.Errorf("thinkink: %s %s: %w", req.method, req.path, err)
+			}
+			if err := c.wait(ctx, attempt, 0); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if resp.StatusCode < 300 {
+			defer resp.Body.Close()
+			if out == nil {
+				return nil
+			}
+			if w, ok := out.(io.Writer); ok {
+				_, err := io.Copy(w, resp.Body)
+				return err
+			}
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return fmt.Errorf("thinkink: decode %s %s response: %w", req.method, req.path, err)
+			}
+			return nil
+		}
+
+		apiErr := readError(resp)
+
+		// An expired token is replaced once when the client can sign in again
+		if apiErr.StatusCode == http.StatusUnauthorized && !req.public && !reauthenticated && c.canSignIn() {
+			reauthenticated = true
+			c.SetToken("")
+			if err := c.ensureToken(ctx); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if attempt >= c.maxRetries || !retryable(req.method, apiErr.StatusCode) {
+			return apiErr
+		}
+		if err := c.wait(ctx, attempt, apiErr.RetryAfter); err != nil {
+			return err
+		}
+	}
+}
+
+// send makes a single attempt at the request
+func (c *Client) send(ctx context.Context, req request, payload []byte, contentType string) (*http.Response, error) {
+	u := c.baseURL.JoinPath(req.path)
+	if len(req.query) > 0 {
+		u.RawQuery = req.query.Encode()
+	}
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range req.headers {
+		for _, value := range values {
+			httpReq.Header.Add(name, value)
+		}
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("User-Agent", c.userAgent)
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	if token := c.Token(); token != "" && !req.public {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	return c.httpClient.Do(httpReq)
+}
+
+// wait sleeps before the next attempt: Retry-After when the server sent it,
+// exponential backoff with jitter otherwise
+func (c *Client) wait(ctx context.Context, attempt int, retryAfter time.Duration) error {
+	delay := retryAfter
+	if delay <= 0 {
+		delay = c.backoff << attempt
+		delay += time.Duration(rand.Int63n(int64(delay)/2 + 1))
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// idempotent reports whether repeating a request has no further effect
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// retryable reports whether a request that failed with status is worth
+// repeating. Throttled and unavailable responses were refused before any work
+// was done, so every method is retried; gateway errors only for idempotent ones.
+func retryable(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent(method)
+	}
+	return false
+}
+
+// readError reads an error response
+func readError(resp *http.Response) *APIError {
+	defer resp.Body.Close()
+	apiErr := &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var body struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(data, &body) == nil {
+		if body.Error != "" {
+			apiErr.Message = body.Error
+		} else if body.Message != "" {
+			apiErr.Message = body.Message
+		}
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return apiErr
+}
@@ -0,0 +1,300 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"iter"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+)
+
+// UploadOptions are the optional fields of a recording upload
+type UploadOptions struct {
+	// Matching scale (1-10); the server defaults to 5
+	MatchingScale int
+	// Recording metadata as a JSON object
+	Metadata string
+	// Report template the structured notes follow, with the notes as a JSON object
+	TemplateID uint
+	Notes      string
+	// ID the device assigned to the recording; a re-sent recording returns the
+	// first upload's result instead of a new report
+	RecordingID string
+	// Create a new report even if the same file was uploaded before
+	AllowDuplicate bool
+	// Hex SHA-256 of the file, verified by the server once it is stored.
+	// UploadFile computes it itself; UploadClient.Upload sends it when set.
+	SHA256 string
+}
+
+// UploadResult is the response of uploading a recording
+type UploadResult struct {
+	Message string `json:"message"`
+	FileID  uint   `json:"file_id"`
+	// The background job turning the file into a report; follow it with FileStatus
+	JobID     uint   `json:"job_id,omitempty"`
+	StatusURL string `json:"status_url,omitempty"`
+	// Set for an existing report returned for a duplicate upload
+	ReportID      uint     `json:"report_id,omitempty"`
+	Description   string   `json:"description,omitempty"`
+	MatchingScale int      `json:"matching_scale"`
+	Warnings      []string `json:"warnings,omitempty"`
+	// Set while the file waits for processing, with how long that should take
+	Queued     bool `json:"queued,omitempty"`
+	ETASeconds int  `json:"eta_seconds,omitempty"`
+	// Set when the file was uploaded before
+	Duplicate     bool  `json:"duplicate,omitempty"`
+	DuplicateOfID *uint `json:"duplicate_of_id,omitempty"`
+}
+
+// UploadFile uploads a recording and queues it for processing into a report;
+// poll FileStatus with the returned file ID until it is processed or failed.
+// The file is read into memory so the upload can be retried, and sent with
+// its SHA-256 so a file corrupted on the way is refused.
+func (c *Client) UploadFile(ctx context.Context, filename string, file io.Reader, opts UploadOptions) (*UploadResult, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, err
+	}
+	// The server checks the file it receives against its SHA-256
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(part, hash), file); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]string{
+		"metadata": opts.Metadata,
+		"notes":    opts.Notes,
+		"sha256":   hex.EncodeToString(hash.Sum(nil)),
+	}
+	if opts.MatchingScale != 0 {
+		fields["matchingScale"] = strconv.Itoa(opts.MatchingScale)
+	}
+	if opts.TemplateID != 0 {
+		fields["template_id"] = strconv.FormatUint(uint64(opts.TemplateID), 10)
+	}
+	if opts.AllowDuplicate {
+		fields["allow_duplicate"] = "true"
+	}
+	for name, value := range fields {
+		if value == "" {
+			continue
+		}
+		if err := form.WriteField(name, value); err != nil {
+			return nil, err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	req := request{
+		method:      http.MethodPost,
+		path:        "/upload",
+		body:        body.Bytes(),
+		contentType: form.FormDataContentType(),
+	}
+	if opts.RecordingID != "" {
+		req.headers = http.Header{"X-Recording-ID": {opts.RecordingID}}
+	}
+
+	var result UploadResult
+	if err := c.do(ctx, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SessionUploadResult is the response of uploading a recording session
+type SessionUploadResult struct {
+	Message   string `json:"message"`
+	SessionID uint   `json:"session_id"`
+	StatusURL string `json:"status_url"`
+	// The file and upload job of each segment, in session order
+	Segments []struct {
+		File   string `json:"file"`
+		FileID uint   `json:"file_id"`
+		JobID  uint   `json:"job_id"`
+	} `json:"segments"`
+	Warnings   []string `json:"warnings,omitempty"`
+	ETASeconds int      `json:"eta_seconds,omitempty"`
+}
+
+// UploadSession uploads a zip archive holding the segments of one recording
+// session, listed by a manifest.json at its root. Each segment is queued for
+// processing into a report of its own; follow them with Session. The archive
+// is read into memory so the upload can be retried.
+func (c *Client) UploadSession(ctx context.Context, filename string, archive io.Reader) (*SessionUploadResult, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(part, hash), archive); err != nil {
+		return nil, err
+	}
+	if err := form.WriteField("sha256", hex.EncodeToString(hash.Sum(nil))); err != nil {
+		return nil, err
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	req := request{
+		method:      http.MethodPost,
+		path:        "/upload/session",
+		body:        body.Bytes(),
+		contentType: form.FormDataContentType(),
+	}
+	var result SessionUploadResult
+	if err := c.do(ctx, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Session fetches one of the caller's recording sessions with its segments' files
+func (c *Client) Session(ctx context.Context, id uint) (*models.RecordingSession, error) {
+	var session models.RecordingSession
+	if err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/sessions/%d", id)}, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// FileQuery filters a file listing; unset fields match every file
+type FileQuery struct {
+	From time.Time // uploaded at or after
+	To   time.Time // uploaded before
+	// processing, processed, failed or unlinked
+	Status string
+	// pending, scanning, translating, completed or failed
+	State string
+}
+
+// values encodes the filter as query parameters of GET /files
+func (q FileQuery) values() url.Values {
+	query := url.Values{}
+	if !q.From.IsZero() {
+		query.Set("from", q.From.Format(time.RFC3339))
+	}
+	if !q.To.IsZero() {
+		query.Set("to", q.To.Format(time.RFC3339))
+	}
+	if q.Status != "" {
+		query.Set("status", q.Status)
+	}
+	if q.State != "" {
+		query.Set("state", q.State)
+	}
+	return query
+}
+
+// ListFiles fetches one page of the caller's uploaded files, newest first
+func (c *Client) ListFiles(ctx context.Context, q FileQuery, page PageOptions) ([]models.SingleFile, Pagination, error) {
+	query := q.values()
+	page.apply(query)
+
+	var resp struct {
+		Files      []models.SingleFile `json:"files"`
+		Pagination Pagination          `json:"pagination"`
+	}
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/files", query: query}, &resp); err != nil {
+		return nil, Pagination{}, err
+	}
+	return resp.Files, resp.Pagination, nil
+}
+
+// Files iterates over the caller's matching uploaded files, newest first,
+// fetching pages of 100 as needed
+func (c *Client) Files(ctx context.Context, q FileQuery) iter.Seq2[models.SingleFile, error] {
+	return iterate(ctx, func(ctx context.Context, cursor string) ([]models.SingleFile, Pagination, error) {
+		return c.ListFiles(ctx, q, PageOptions{Limit: 100, Cursor: cursor})
+	})
+}
+
+// DeleteFile deletes one of the caller's uploaded files and, with cascade,
+// the report made from it, returning the IDs of the deleted reports. Files
+// whose report is shared are refused with a 409 APIError.
+func (c *Client) DeleteFile(ctx context.Context, id uint, cascade bool) ([]uint, error) {
+	query := url.Values{}
+	if cascade {
+		query.Set("cascade", "true")
+	}
+
+	var resp struct {
+		DeletedReportIDs []uint `json:"deleted_report_ids"`
+	}
+	req := request{method: http.MethodDelete, path: fmt.Sprintf("/files/%d", id), query: query}
+	if err := c.do(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.DeletedReportIDs, nil
+}
+
+// FileStatus is the processing state of an uploaded file
+type FileStatus struct {
+	FileID uint `json:"file_id"`
+	// processing, processed, failed, unlinked or quarantined
+	Status   string `json:"status"`
+	ReportID *uint  `json:"report_id,omitempty"`
+	// pending, scanning, translating, completed or failed
+	State          string     `json:"state"`
+	StateChangedAt *time.Time `json:"state_changed_at,omitempty"`
+	// Why processing or translation last failed, e.g. ml_unavailable
+	ErrorClass   string `json:"error_class,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+	// Channel count, sampling rate and duration, once the recording was processed
+	models.RecordingInfo
+	Job *models.UploadJob `json:"job,omitempty"`
+}
+
+// FileStatus fetches the processing state of one of the caller's uploaded files
+func (c *Client) FileStatus(ctx context.Context, id uint) (*FileStatus, error) {
+	var status FileStatus
+	if err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/files/%d/status", id)}, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Retranslation is a file's report queued to be translated again
+type Retranslation struct {
+	FileID    uint   `json:"file_id"`
+	ReportID  uint   `json:"report_id"`
+	StatusURL string `json:"status_url"`
+}
+
+// RetranslateFile queues the report made from one of the caller's uploaded
+// files to be translated again. Files without a report, or already being
+// translated, are refused with a 409 APIError.
+func (c *Client) RetranslateFile(ctx context.Context, id uint) (*Retranslation, error) {
+	var resp Retranslation
+	if err := c.do(ctx, request{method: http.MethodPost, path: fmt.Sprintf("/files/%d/retranslate", id)}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// FilePreview fetches the downsampled waveform of one of the caller's
+// uploaded files, made when the file was processed
+func (c *Client) FilePreview(ctx context.Context, id uint) (*models.FilePreview, error) {
+	var preview models.FilePreview
+	if err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/files/%d/preview", id)}, &preview); err != nil {
+		return nil, err
+	}
+	return &preview, nil
+}
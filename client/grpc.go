@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	uploadpb "github.com/ThinkInkTeam/thinkink-core-backend/proto-gen/proto/upload"
+	pb "github.com/ThinkInkTeam/thinkink-core-backend/proto-gen/proto/validation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// ValidationClient calls the token validation gRPC service, e.g. from the ML
+// service to check the tokens it is sent
+type ValidationClient struct {
+	conn   *grpc.ClientConn
+	client pb.TokenValidationServiceClient
+}
+
+// NewValidationClient connects to the gRPC server at target, e.g.
+// localhost:50051. Without options the connection is unencrypted.
+func NewValidationClient(target string, opts ...grpc.DialOption) (*ValidationClient, error) {
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("thinkink: connect to %s: %w", target, err)
+	}
+	return &ValidationClient{conn: conn, client: pb.NewTokenValidationServiceClient(conn)}, nil
+}
+
+// ValidateMLToken reports whether an ML token is valid and not revoked
+func (v *ValidationClient) ValidateMLToken(ctx context.Context, token string) (bool, error) {
+	resp, err := v.client.ValidateMLToken(ctx, &pb.ValidateTokenRequest{
+		Token: strings.TrimPrefix(strings.TrimSpace(token), "Bearer "),
+	})
+	if err != nil {
+		return false, fmt.Errorf("thinkink: validate token: %w", err)
+	}
+	return resp.GetIsValid(), nil
+}
+
+// Close closes the connection
+func (v *ValidationClient) Close() error {
+	return v.conn.Close()
+}
+
+// uploadChunkSize is how many bytes of a recording each streamed message carries
+const uploadChunkSize = 64 << 10
+
+// UploadClient streams recordings to the gRPC upload service, e.g. from a
+// headset companion app
+type UploadClient struct {
+	conn   *grpc.ClientConn
+	client uploadpb.SignalUploadServiceClient
+}
+
+// NewUploadClient connects to the gRPC server at target, e.g.
+// localhost:50051. Without options the connection is unencrypted.
+func NewUploadClient(target string, opts ...grpc.DialOption) (*UploadClient, error) {
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("thinkink: connect to %s: %w", target, err)
+	}
+	return &UploadClient{conn: conn, client: uploadpb.NewSignalUploadServiceClient(conn)}, nil
+}
+
+// Upload streams a recording with the caller's token and queues it for
+// processing like Client.UploadFile. The file is sent in chunks as it is
+// read, so it is never held in memory; set opts.SHA256 to have it verified.
+func (u *UploadClient) Upload(ctx context.Context, token, filename string, file io.Reader, opts UploadOptions) (*UploadResult, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+strings.TrimPrefix(token, "Bearer "))
+	stream, err := u.client.UploadSignal(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("thinkink: upload: %w", err)
+	}
+
+	header := &uploadpb.UploadHeader{
+		Filename:       filename,
+		MatchingScale:  int32(opts.MatchingScale),
+		MetadataJson:   opts.Metadata,
+		TemplateId:     uint32(opts.TemplateID),
+		NotesJson:      opts.Notes,
+		AllowDuplicate: opts.AllowDuplicate,
+		Sha256:         opts.SHA256,
+		RecordingId:    opts.RecordingID,
+	}
+	err = stream.Send(&uploadpb.UploadSignalRequest{Payload: &uploadpb.UploadSignalRequest_Header{Header: header}})
+	buf := make([]byte, uploadChunkSize)
+	for err == nil {
+		n, readErr := file.Read(buf)
+		if n > 0 {
+			chunk := append([]byte(nil), buf[:n]...)
+			err = stream.Send(&uploadpb.UploadSignalRequest{Payload: &uploadpb.UploadSignalRequest_Chunk{Chunk: chunk}})
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			stream.CloseSend()
+			return nil, readErr
+		}
+	}
+	// A refused upload ends the stream early; its status comes with the response
+	resp, recvErr := stream.CloseAndRecv()
+	if recvErr != nil {
+		return nil, fmt.Errorf("thinkink: upload: %w", recvErr)
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("thinkink: upload: %w", err)
+	}
+
+	result := &UploadResult{
+		Message:       resp.GetMessage(),
+		FileID:        uint(resp.GetFileId()),
+		JobID:         uint(resp.GetJobId()),
+		StatusURL:     resp.GetStatusUrl(),
+		ReportID:      uint(resp.GetReportId()),
+		MatchingScale: int(resp.GetMatchingScale()),
+		Warnings:      resp.GetWarnings(),
+		Queued:        resp.GetQueued(),
+		ETASeconds:    int(resp.GetEtaSeconds()),
+		Duplicate:     resp.GetDuplicate(),
+	}
+	if id := resp.GetDuplicateOfId(); id != 0 {
+		duplicateOf := uint(id)
+		result.DuplicateOfID = &duplicateOf
+	}
+	return result, nil
+}
+
+// Close closes the connection
+func (u *UploadClient) Close() error {
+	return u.conn.Close()
+}
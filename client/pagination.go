@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+// Pagination describes a page of a listing
+type Pagination struct {
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// PageOptions selects one page of a listing
+type PageOptions struct {
+	// Page size; the server defaults to 50 and allows at most 100
+	Limit int
+	// Cursor from a previous page's Pagination.NextCursor
+	Cursor string
+}
+
+// apply adds the page parameters to a query
+func (p PageOptions) apply(query url.Values) {
+	if p.Limit > 0 {
+		query.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+}
+
+// fetchPage fetches one page of a listing starting at cursor
+type fetchPage[T any] func(ctx context.Context, cursor string) ([]T, Pagination, error)
+
+// iterate yields every item of a listing, following next cursors until the
+// last page. Iteration stops at the first error, which is yielded.
+func iterate[T any](ctx context.Context, fetch fetchPage[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		cursor := ""
+		for {
+			items, page, err := fetch(ctx, cursor)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if !page.HasMore || page.NextCursor == "" {
+				return
+			}
+			cursor = page.NextCursor
+		}
+	}
+}
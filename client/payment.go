@@ -0,0 +1,36 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
+)
+
+// Plan is one purchasable price of the plan catalog
+type Plan struct {
+	// Stripe price ID to subscribe with
+	ID            string   `json:"id"`
+	ProductID     string   `json:"product_id"`
+	Name          string   `json:"name"`
+	Description   string   `json:"description"`
+	Features      []string `json:"features"`
+	Currency      string   `json:"currency"`
+	UnitAmount    int64    `json:"unit_amount"`
+	Currencies    []string `json:"currencies"`
+	Interval      string   `json:"interval"`
+	IntervalCount int64    `json:"interval_count"`
+	// Quotas granted while subscribed; nil for one-time prices
+	Quota *config.PlanQuota `json:"quota"`
+}
+
+// Plans lists the plans that can be purchased
+func (c *Client) Plans(ctx context.Context) ([]Plan, error) {
+	var result struct {
+		Plans []Plan `json:"plans"`
+	}
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/payment/plans", public: true}, &result); err != nil {
+		return nil, err
+	}
+	return result.Plans, nil
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+)
+
+// ReportQuery filters a report listing; unset fields match every report
+type ReportQuery struct {
+	// Owner of the reports when they linked the caller's account; defaults to the caller
+	UserID   uint
+	From     time.Time // created at or after
+	To       time.Time // created before
+	MinScale *int
+	MaxScale *int
+	// Case-insensitive match on title or description
+	Query string
+	// Tags the reports must all carry
+	Tags []string
+	// "" hides archived reports, "true" lists only them and "all" lists both
+	Archived string
+	// Only reports flagged by moderation
+	Flagged bool
+}
+
+// values encodes the filter as query parameters of GET /reports
+func (q ReportQuery) values() url.Values {
+	query := url.Values{}
+	if q.UserID != 0 {
+		query.Set("user_id", strconv.FormatUint(uint64(q.UserID), 10))
+	}
+	if !q.From.IsZero() {
+		query.Set("from", q.From.Format(time.RFC3339))
+	}
+	if !q.To.IsZero() {
+		query.Set("to", q.To.Format(time.RFC3339))
+	}
+	if q.MinScale != nil {
+		query.Set("min_scale", strconv.Itoa(*q.MinScale))
+	}
+	if q.MaxScale != nil {
+		query.Set("max_scale", strconv.Itoa(*q.MaxScale))
+	}
+	if q.Query != "" {
+		query.Set("q", q.Query)
+	}
+	for _, tag := range q.Tags {
+		query.Add("tag", tag)
+	}
+	if q.Archived != "" {
+		query.Set("archived", q.Archived)
+	}
+	if q.Flagged {
+		query.Set("flagged", "true")
+	}
+	return query
+}
+
+// ListReports fetches one page of reports, newest first
+func (c *Client) ListReports(ctx context.Context, q ReportQuery, page PageOptions) ([]models.Report, Pagination, error) {
+	query := q.values()
+	page.apply(query)
+
+	var resp struct {
+		Reports    []models.Report `json:"reports"`
+		Pagination Pagination      `json:"pagination"`
+	}
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/reports", query: query}, &resp); err != nil {
+		return nil, Pagination{}, err
+	}
+	return resp.Reports, resp.Pagination, nil
+}
+
+// Reports iterates over every matching report, newest first, fetching pages
+// of 100 as needed
+func (c *Client) Reports(ctx context.Context, q ReportQuery) iter.Seq2[models.Report, error] {
+	return iterate(ctx, func(ctx context.Context, cursor string) ([]models.Report, Pagination, error) {
+		return c.ListReports(ctx, q, PageOptions{Limit: 100, Cursor: cursor})
+	})
+}
+
+// GetReport fetches a report with its tags and source file
+func (c *Client) GetReport(ctx context.Context, id uint) (*models.Report, error) {
+	var resp struct {
+		Report models.Report `json:"report"`
+	}
+	if err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/reports/%d", id)}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Report, nil
+}
+
+// DeleteReport deletes one of the caller's reports
+func (c *Client) DeleteReport(ctx context.Context, id uint) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/reports/%d", id)}, nil)
+}
+
+// Tags lists the caller's tags with how many reports carry each
+func (c *Client) Tags(ctx context.Context) ([]models.TagUsage, error) {
+	var resp struct {
+		Tags []models.TagUsage `json:"tags"`
+	}
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/tags"}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Tags, nil
+}
+
+// GetUser fetches the caller's profile; users can only read their own
+func (c *Client) GetUser(ctx context.Context, id uint) (*models.User, error) {
+	var resp struct {
+		User models.User `json:"user"`
+	}
+	if err := c.do(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/user/%d", id)}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.User, nil
+}
+
+// GraphQLError is an error reported by a GraphQL query
+type GraphQLError struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// GraphQLErrors are the errors reported by a GraphQL query
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	if len(e) == 1 {
+		return "thinkink: graphql: " + e[0].Message
+	}
+	return fmt.Sprintf("thinkink: graphql: %s (and %d more errors)", e[0].Message, len(e)-1)
+}
+
+// GraphQL runs a query against /graphql and decodes its data into out. Field
+// errors are returned as GraphQLErrors, with out holding the partial data.
+func (c *Client) GraphQL(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	resp := struct {
+		Data   interface{}   `json:"data"`
+		Errors GraphQLErrors `json:"errors"`
+	}{Data: out}
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/graphql",
+		body: map[string]interface{}{
+			"query":     query,
+			"variables": variables,
+		},
+	}, &resp)
+	if err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		return resp.Errors
+	}
+	return nil
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/handlers"
+	"github.com/ThinkInkTeam/thinkink-core-backend/middleware"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/billing"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/branding"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/chaos"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/coldstorage"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/encryption"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/health"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/ingest"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/jobs"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/malware"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+)
+
+// doctorTimeout bounds each network check
+const doctorTimeout = 5 * time.Second
+
+// Outcomes of a doctor check
+const (
+	doctorOK   = "OK"
+	doctorWarn = "WARN"
+	doctorFail = "FAIL"
+)
+
+// doctorResult is the outcome of one check with what to do about it
+type doctorResult struct {
+	name   string
+	status string
+	detail string
+	hint   string
+}
+
+// runDoctor checks the configuration and the services the server depends on,
+// prints a line per check with a hint for anything wrong, and returns the
+// process exit code: 1 if any check failed, 0 otherwise
+func runDoctor() int {
+	fmt.Println("ThinkInk doctor")
+	fmt.Println()
+
+	var results []doctorResult
+	results = append(results, checkConfiguration()...)
+	results = append(results, checkDatabase()...)
+	results = append(results, checkStripe(), checkMLService(), checkMalwareScanner())
+	results = append(results, checkStorage()...)
+
+	failed, warned := 0, 0
+	for _, r := range results {
+		fmt.Printf("[%-4s] %-22s %s\n", r.status, r.name, r.detail)
+		if r.hint != "" && r.status != doctorOK {
+			fmt.Printf("       %-22s -> %s\n", "", r.hint)
+		}
+		switch r.status {
+		case doctorFail:
+			failed++
+		case doctorWarn:
+			warned++
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("%d checks: %d passed, %d warnings, %d failed\n", len(results), len(results)-failed-warned, warned, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// checkConfiguration validates the reloadable settings and the environment
+// variables read at startup
+func checkConfiguration() []doctorResult {
+	var results []doctorResult
+
+	if err := config.Init(); err != nil {
+		results = append(results, doctorResult{"settings", doctorFail, err.Error(), "Fix the variable named above in the environment or .env file"})
+	} else {
+		results = append(results, doctorResult{name: "settings", status: doctorOK, detail: "reloadable settings are valid"})
+	}
+
+	var problems []string
+	if _, err := jobs.Mode(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, _, err := database.MigrationSettings(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := jobs.ColdStorageAfterMonths(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := jobs.FileRetentionDays(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := coldstorage.FromEnv(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := chaos.FromEnv(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := malware.FromEnv(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := middleware.AllowedOriginsFromEnv(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	durations := []struct {
+		name, fallback string
+	}{
+		{"SUBSCRIPTION_EXPIRY_GRACE", "24h"},
+		{"HEALTH_PROBE_INTERVAL", "1m"},
+		{"BLACKLIST_REFRESH_INTERVAL", "30s"},
+		{"BLACKLIST_MAX_STALENESS", "5m"},
+		{"REPORT_EXPORT_TTL", "24h"},
+		{"WORKER_DRAIN_TIMEOUT", "25s"},
+	}
+	for _, d := range durations {
+		if value, err := time.ParseDuration(utils.GetEnvWithDefault(d.name, d.fallback)); err != nil || value <= 0 {
+			problems = append(problems, d.name+" must be a positive duration such as 30s or 24h")
+		}
+	}
+	integers := []struct {
+		name, fallback string
+		min            int
+	}{
+		{"TRANSLATION_WORKERS", strconv.Itoa(ingest.DefaultWorkers), 1},
+		{"INACTIVE_USER_DAYS", "90", 1},
+		{"REPORT_PURGE_AFTER_DAYS", "30", 0},
+		{"REPORT_EXPORT_SYNC_ROWS", "1000", 0},
+	}
+	for _, i := range integers {
+		if value, err := strconv.Atoi(utils.GetEnvWithDefault(i.name, i.fallback)); err != nil || value < i.min {
+			problems = append(problems, fmt.Sprintf("%s must be an integer of at least %d", i.name, i.min))
+		}
+	}
+	if masterKey := utils.GetEnvWithDefault("REPORT_KMS_MASTER_KEY", ""); masterKey != "" {
+		if _, err := encryption.NewLocalKMS(masterKey); err != nil {
+			problems = append(problems, "REPORT_KMS_MASTER_KEY: "+err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		results = append(results, doctorResult{"environment", doctorFail, strings.Join(problems, "; "), "Fix these variables; the server refuses to start with them"})
+	} else {
+		results = append(results, doctorResult{name: "environment", status: doctorOK, detail: "startup variables are valid"})
+	}
+
+	if utils.GetEnvWithDefault("JWT_SECRET", "your_jwt_secret") == "your_jwt_secret" {
+		results = append(results, doctorResult{"jwt secret", doctorWarn, "JWT_SECRET is not set; tokens are signed with a public default", "Set JWT_SECRET to a long random value"})
+	} else {
+		results = append(results, doctorResult{name: "jwt secret", status: doctorOK, detail: "JWT_SECRET is set"})
+	}
+	return results
+}
+
+// checkDatabase connects without migrating and reports missing tables and columns
+func checkDatabase() []doctorResult {
+	host, user, password, dbname, port, sslMode := databaseEnv()
+	target := fmt.Sprintf("%s@%s:%s/%s", user, host, port, dbname)
+
+	dm := database.NewDatabaseManager()
+	if err := dm.Open(host, user, password, dbname, port, sslMode); err != nil {
+		return []doctorResult{{"database", doctorFail, target + ": " + err.Error(), "Check that Postgres is running and DB_HOST, DB_PORT, DB_USER, DB_PASSWORD, DB_NAME and DB_SSL_MODE are correct"}}
+	}
+	defer dm.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+	if err := health.DatabaseCheck(dm.DB)(ctx); err != nil {
+		return []doctorResult{{"database", doctorFail, target + ": " + err.Error(), "Check that Postgres is running and DB_HOST, DB_PORT, DB_USER, DB_PASSWORD, DB_NAME and DB_SSL_MODE are correct"}}
+	}
+	results := []doctorResult{{name: "database", status: doctorOK, detail: "connected to " + target}}
+
+	pending, err := dm.PendingMigrations()
+	switch {
+	case err != nil:
+		results = append(results, doctorResult{"migrations", doctorFail, err.Error(), "Check that DB_USER may read the schema"})
+	case len(pending) > 0:
+		hint := "Run thinkink-server migrate -dry-run to review the plan, then migrate; add -contract once every instance runs this release"
+		if mode, _, _ := database.MigrationSettings(); mode == database.MigrationAuto {
+			hint = "Start the server once to apply expand migrations; run thinkink-server migrate -contract for the rest once every instance runs this release"
+		}
+		results = append(results, doctorResult{"migrations", doctorWarn, "pending " + strings.Join(pending, ", "), hint})
+	default:
+		results = append(results, doctorResult{name: "migrations", status: doctorOK, detail: "schema is up to date"})
+	}
+	return results
+}
+
+// checkStripe verifies the Stripe key with an authenticated API call
+func checkStripe() doctorResult {
+	if utils.GetEnvWithDefault("BILLING_GATEWAY", "stripe") == "stub" {
+		if utils.GetEnvWithDefault("APP_ENV", "development") == "production" {
+			return doctorResult{"stripe", doctorFail, "BILLING_GATEWAY=stub is refused in production", "Unset BILLING_GATEWAY to take payments through Stripe"}
+		}
+		return doctorResult{"stripe", doctorWarn, "BILLING_GATEWAY=stub; no payments are processed", "Unset BILLING_GATEWAY to take payments through Stripe"}
+	}
+	stripeKey := utils.GetEnvWithDefault("STRIPE_SECRET_KEY", "sk_test_example_key_replace_in_production")
+	if stripeKey == "sk_test_example_key_replace_in_production" {
+		return doctorResult{"stripe", doctorFail, "STRIPE_SECRET_KEY is not set", "Set STRIPE_SECRET_KEY to the secret key from the Stripe dashboard, or BILLING_GATEWAY=stub to run without payments"}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+	if err := billing.NewStripeGateway(stripeKey).Ping(ctx); err != nil {
+		return doctorResult{"stripe", doctorFail, err.Error(), "Check STRIPE_SECRET_KEY and that the server can reach api.stripe.com"}
+	}
+	mode := "live"
+	if strings.HasPrefix(stripeKey, "sk_test_") {
+		mode = "test"
+	}
+	return doctorResult{name: "stripe", status: doctorOK, detail: "key accepted (" + mode + " mode)"}
+}
+
+// checkMLService verifies that the translation service accepts connections
+func checkMLService() doctorResult {
+	address := config.Current().MLServiceAddress
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+	if err := health.TCPCheck(func() string { return address })(ctx); err != nil {
+		return doctorResult{"ml service", doctorFail, address + ": " + err.Error(), "Check that the ML service is running and ML_SERVICE_ADDRESS points to it"}
+	}
+	return doctorResult{name: "ml service", status: doctorOK, detail: "reachable at " + address}
+}
+
+// checkMalwareScanner verifies that the configured malware scanner answers
+func checkMalwareScanner() doctorResult {
+	scanner, err := malware.FromEnv()
+	if err != nil {
+		return doctorResult{"malware scanner", doctorFail, err.Error(), "Fix MALWARE_SCANNER and the CLAMAV_* variables"}
+	}
+	if scanner == nil {
+		return doctorResult{"malware scanner", doctorWarn, "MALWARE_SCANNER=none; uploads are not scanned", "Set MALWARE_SCANNER=clamav and CLAMAV_ADDRESS to scan uploads"}
+	}
+	clamav, ok := scanner.(*malware.ClamAV)
+	if !ok {
+		return doctorResult{name: "malware scanner", status: doctorOK, detail: scanner.Name()}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+	if err := clamav.Ping(ctx); err != nil {
+		return doctorResult{"malware scanner", doctorFail, err.Error(), "Check that clamd is running and CLAMAV_ADDRESS points to it"}
+	}
+	return doctorResult{name: "malware scanner", status: doctorOK, detail: "clamd answers at " + clamav.Address()}
+}
+
+// checkStorage verifies that the file directories can be created and written
+func checkStorage() []doctorResult {
+	dirs := []struct{ name, path, env string }{
+		{"uploads", handlers.UploadDir, ""},
+		{"recording archive", utils.GetEnvWithDefault("RECORDING_ARCHIVE_DIR", "./archive"), "RECORDING_ARCHIVE_DIR"},
+		{"report exports", handlers.ReportExportDir, ""},
+		{"branding", branding.Dir(), "BRANDING_DIR"},
+	}
+	if scanner, _ := malware.FromEnv(); scanner != nil {
+		dirs = append(dirs, struct{ name, path, env string }{"malware quarantine", malware.QuarantineDir(), "MALWARE_QUARANTINE_DIR"})
+	}
+	if months, _ := jobs.ColdStorageAfterMonths(); months > 0 {
+		if archive, err := coldstorage.FromEnv(); err == nil {
+			if store, ok := archive.(*coldstorage.FileStore); ok {
+				dirs = append(dirs, struct{ name, path, env string }{"report cold storage", store.Dir(), "REPORT_COLD_STORAGE_DIR"})
+			}
+		}
+	}
+
+	var results []doctorResult
+	for _, dir := range dirs {
+		hint := "Make " + dir.path + " writable by the server's user"
+		if dir.env != "" {
+			hint += ", or point " + dir.env + " elsewhere"
+		}
+		if err := checkWritable(dir.path); err != nil {
+			results = append(results, doctorResult{dir.name, doctorFail, err.Error(), hint})
+			continue
+		}
+		abs, err := filepath.Abs(dir.path)
+		if err != nil {
+			abs = dir.path
+		}
+		results = append(results, doctorResult{name: dir.name, status: doctorOK, detail: "writable at " + abs})
+	}
+	return results
+}
+
+// checkWritable creates dir if needed and writes and removes a probe file in it
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	probe, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return err
+	}
+	_, err = probe.WriteString("ok")
+	if closeErr := probe.Close(); err == nil {
+		err = closeErr
+	}
+	if removeErr := os.Remove(probe.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
@@ -1,35 +1,65 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
+	_ "time/tzdata" // embed the zone database so user time zones resolve on minimal images
 
 	"github.com/ThinkInkTeam/thinkink-core-backend/api"
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
 	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	uploadpb "github.com/ThinkInkTeam/thinkink-core-backend/proto-gen/proto/upload"
 	pb "github.com/ThinkInkTeam/thinkink-core-backend/proto-gen/proto/validation"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/billing"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/chaos"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/coldstorage"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/embedding"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/encryption"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/fileevents"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/health"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/iap"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/ingest"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/jobs"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/malware"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/uploadstream"
 	"github.com/ThinkInkTeam/thinkink-core-backend/services/validation"
 	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
 	"github.com/joho/godotenv"
-	"github.com/stripe/stripe-go/v72"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 )
 
 func main() {
 	_ = godotenv.Load()
+
+	// "doctor" diagnoses the deployment instead of starting the servers
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		os.Exit(runDoctor())
+	}
+
+	// "migrate" applies or, with -dry-run, prints the pending schema migrations
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+
+	// Load reloadable settings; SIGHUP re-reads them without a restart
+	if err := config.Init(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	go config.WatchSignals(context.Background())
+
 	// Initialize database connection using environment variables
 	databaseManager := database.NewDatabaseManager()
 
-	// Get database configuration from environment variables
-	host := utils.GetEnvWithDefault("DB_HOST", "localhost")
-	user := utils.GetEnvWithDefault("DB_USER", "postgres")
-	password := utils.GetEnvWithDefault("DB_PASSWORD", "postgres")
-	dbname := utils.GetEnvWithDefault("DB_NAME", "postgres")
-	port := utils.GetEnvWithDefault("DB_PORT", "5432")
-	sslMode := utils.GetEnvWithDefault("DB_SSL_MODE", "disable")
-
-	if err := databaseManager.Connect(host, user, password, dbname, port, sslMode); err != nil {
+	if err := databaseManager.Connect(databaseEnv()); err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 		return
 	}
@@ -39,13 +69,134 @@ func main() {
 	if stripeKey == "sk_test_example_key_replace_in_production" {
 		log.Println("Warning: Using default Stripe test key. Set STRIPE_SECRET_KEY environment variable for production.")
 	}
-	stripe.Key = stripeKey
+	if utils.GetEnvWithDefault("BILLING_GATEWAY", "stripe") == "stub" {
+		// The stub skips webhook signatures and accepts any store receipt
+		if utils.GetEnvWithDefault("APP_ENV", "development") == "production" {
+			log.Fatalf("Invalid configuration: BILLING_GATEWAY=stub must not be set in production")
+		}
+		log.Println("Warning: Using in-memory billing stub. No payments will be processed.")
+		billing.SetGateway(billing.NewStubGateway())
+	} else {
+		billing.SetGateway(billing.NewStripeGateway(stripeKey))
+	}
+	configureStoreVerifiers()
+
+	// Per-user keys for report text encryption are derived from the KMS master key
+	if masterKey := utils.GetEnvWithDefault("REPORT_KMS_MASTER_KEY", ""); masterKey != "" {
+		kms, err := encryption.NewLocalKMS(masterKey)
+		if err != nil {
+			log.Fatalf("Invalid REPORT_KMS_MASTER_KEY: %v", err)
+		}
+		encryption.Configure(kms)
+	}
+
+	// Content of reports moved to cold storage is read back from the archive
+	archive, err := coldstorage.FromEnv()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	models.SetContentArchive(archive)
+
+	// Fault injection for rehearsing failures; refused in production
+	chaosConfig, err := chaos.FromEnv()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if chaosConfig.Enabled {
+		log.Println("Warning: CHAOS_ENABLED is set; faults will be injected into requests and translations")
+	}
+	chaos.Configure(chaosConfig)
+
+	// Uploads are scanned for malware before processing when a scanner is configured
+	scanner, err := malware.FromEnv()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if scanner == nil && utils.GetEnvWithDefault("APP_ENV", "development") == "production" {
+		log.Println("Warning: MALWARE_SCANNER is not set; uploads are not scanned for malware")
+	}
+	malware.Configure(scanner)
+
+	// Report similarity uses the built-in hashing embedder unless an embeddings API is configured
+	if embeddingURL := utils.GetEnvWithDefault("EMBEDDING_API_URL", ""); embeddingURL != "" {
+		embedding.SetDefault(embedding.NewHTTPEmbedder(embeddingURL,
+			utils.GetEnvWithDefault("EMBEDDING_MODEL", "text-embedding-3-small"),
+			utils.GetEnvWithDefault("EMBEDDING_API_KEY", "")))
+	}
+
+	// Bound concurrent translations; uploads apply backpressure when the queue backs up
+	translationWorkers, err := strconv.Atoi(utils.GetEnvWithDefault("TRANSLATION_WORKERS", strconv.Itoa(ingest.DefaultWorkers)))
+	if err != nil || translationWorkers < 1 {
+		log.Fatalf("Invalid TRANSLATION_WORKERS: must be a positive integer")
+	}
+	ingest.SetDefault(ingest.NewPool(translationWorkers))
+
+	mode, err := jobs.Mode()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	// "worker" runs only the background jobs, so they can be scaled separately from the API
+	if len(os.Args) > 1 && os.Args[1] == "worker" {
+		runWorker()
+		return
+	}
+	if mode == jobs.ModeEmbedded {
+		startWorkers(context.Background())
+	} else {
+		log.Println("WORKER_MODE=external: background jobs run in a separate worker process")
+	}
 
 	// Determine port from environment variable or use default
 	restPort := utils.GetEnvWithDefault("PORT", "8080")
 
+	// Probe component health for the public status page and readiness checks
+	probeInterval, err := time.ParseDuration(utils.GetEnvWithDefault("HEALTH_PROBE_INTERVAL", "1m"))
+	if err != nil {
+		log.Fatalf("Invalid HEALTH_PROBE_INTERVAL: %v", err)
+	}
+	// Only the database is critical for readiness; without the ML service or
+	// payments the instance keeps serving and the dependent features fail fast
+	prober := health.Default()
+	prober.Interval = probeInterval
+	prober.Register(health.ComponentAPI, health.TCPCheck(func() string { return "localhost:" + restPort }))
+	prober.RegisterCritical(health.ComponentDatabase, health.DatabaseCheck(database.DB))
+	prober.Register(health.ComponentMLService, health.TCPCheck(func() string { return config.Current().MLServiceAddress }))
+	prober.Register(health.ComponentPayments, func(ctx context.Context) error { return billing.Client().Ping(ctx) })
+	go jobs.RunPeriodic(context.Background(), "health-probe", probeInterval, prober.Probe)
+
 	grpcPort := utils.GetEnvWithDefault("GRPC_PORT", "50051")
 
+	// ML token validation answers blacklist checks from an in-memory snapshot so
+	// it keeps working through brief database outages
+	blacklistRefresh, err := time.ParseDuration(utils.GetEnvWithDefault("BLACKLIST_REFRESH_INTERVAL", "30s"))
+	if err != nil || blacklistRefresh <= 0 {
+		log.Fatalf("Invalid BLACKLIST_REFRESH_INTERVAL: must be a positive duration")
+	}
+	blacklistMaxStaleness, err := time.ParseDuration(utils.GetEnvWithDefault("BLACKLIST_MAX_STALENESS", "5m"))
+	if err != nil || blacklistMaxStaleness < blacklistRefresh {
+		log.Fatalf("Invalid BLACKLIST_MAX_STALENESS: must be a duration of at least BLACKLIST_REFRESH_INTERVAL")
+	}
+	blacklist := validation.NewBlacklistSnapshot(database.DB, blacklistMaxStaleness)
+	go jobs.RunPeriodic(context.Background(), "blacklist-snapshot", blacklistRefresh, blacklist.Refresh)
+	go blacklist.Listen(context.Background())
+	validation.SetDefault(validation.NewTokenValidator(blacklist))
+
+	// Upload progress is announced over Postgres NOTIFY, also by external
+	// workers, and streamed to connected users at /events
+	events := fileevents.NewBroker(database.DB)
+	go events.Listen(context.Background())
+	fileevents.SetDefault(events)
+
+	// gRPC uploads from device clients are served by the REST router
+	router := api.SetupRouter()
+
+	// Client IPs, which token replay checks bind tokens to, come from
+	// X-Forwarded-For only when the peer is one of the deployment's proxies
+	if err := router.SetTrustedProxies(trustedProxies()); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// Create a WaitGroup to run both servers concurrently
 	var wg sync.WaitGroup
 	wg.Add(2)
@@ -53,13 +204,13 @@ func main() {
 	// Start the gRPC server in a goroutine
 	go func() {
 		defer wg.Done()
-		startGRPCServer(grpcPort)
+		startGRPCServer(grpcPort, router)
 	}()
 
 	// Start the REST API server in a goroutine
 	go func() {
 		defer wg.Done()
-		api.RunServer(restPort)
+		api.RunServer(router, restPort)
 	}()
 
 	log.Printf("Starting servers - REST API on port %s, gRPC on port %s", restPort, grpcPort)
@@ -68,8 +219,20 @@ func main() {
 	wg.Wait()
 }
 
-// startGRPCServer starts the gRPC validation server
-func startGRPCServer(port string) {
+// databaseEnv returns the database connection settings from the environment:
+// host, user, password, database name, port and SSL mode
+func databaseEnv() (string, string, string, string, string, string) {
+	return utils.GetEnvWithDefault("DB_HOST", "localhost"),
+		utils.GetEnvWithDefault("DB_USER", "postgres"),
+		utils.GetEnvWithDefault("DB_PASSWORD", "postgres"),
+		utils.GetEnvWithDefault("DB_NAME", "postgres"),
+		utils.GetEnvWithDefault("DB_PORT", "5432"),
+		utils.GetEnvWithDefault("DB_SSL_MODE", "disable")
+}
+
+// startGRPCServer starts the gRPC server: token validation for the ML service
+// and streamed recording uploads, handled by router like REST uploads
+func startGRPCServer(port string, router http.Handler) {
 	lis, err := net.Listen("tcp", ":"+port)
 	if err != nil {
 		log.Fatalf("Failed to listen on port %s: %v", port, err)
@@ -78,6 +241,7 @@ func startGRPCServer(port string) {
 	grpcServer := grpc.NewServer()
 	validationServer := validation.NewServer()
 	pb.RegisterTokenValidationServiceServer(grpcServer, validationServer)
+	uploadpb.RegisterSignalUploadServiceServer(grpcServer, uploadstream.NewServer(router))
 
 	if utils.GetEnvWithDefault("APP_ENV", "development") != "production" {
 		reflection.Register(grpcServer)
@@ -88,3 +252,56 @@ func startGRPCServer(port string) {
 		log.Fatalf("Failed to serve gRPC server: %v", err)
 	}
 }
+
+// trustedProxies returns the addresses and CIDRs of TRUSTED_PROXIES; none are
+// trusted by default, so the peer address is the client IP
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(utils.GetEnvWithDefault("TRUSTED_PROXIES", ""), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// configureStoreVerifiers enables validation of App Store and Google Play
+// purchases for the stores whose credentials are set. The billing stub
+// accepts every purchase instead. Sandbox purchases are refused in
+// production unless IAP_ALLOW_SANDBOX is set.
+func configureStoreVerifiers() {
+	allowSandbox, err := strconv.ParseBool(utils.GetEnvWithDefault("IAP_ALLOW_SANDBOX", "false"))
+	if err != nil {
+		log.Fatalf("Invalid IAP_ALLOW_SANDBOX: must be true or false")
+	}
+	iap.SetAllowSandbox(allowSandbox || utils.GetEnvWithDefault("APP_ENV", "development") != "production")
+
+	if utils.GetEnvWithDefault("BILLING_GATEWAY", "stripe") == "stub" {
+		if utils.GetEnvWithDefault("APP_ENV", "development") == "production" {
+			log.Fatalf("Invalid configuration: stub store verifiers must not be used in production")
+		}
+		iap.SetVerifier(iap.AppStore, iap.StubVerifier{Store: iap.AppStore})
+		iap.SetVerifier(iap.GooglePlay, iap.StubVerifier{Store: iap.GooglePlay})
+		return
+	}
+
+	bundleID := utils.GetEnvWithDefault("APPLE_IAP_BUNDLE_ID", "")
+	sharedSecret := utils.GetEnvWithDefault("APPLE_IAP_SHARED_SECRET", "")
+	if bundleID != "" && sharedSecret != "" {
+		iap.SetVerifier(iap.AppStore, iap.NewAppleVerifier(bundleID, sharedSecret))
+	}
+
+	packageName := utils.GetEnvWithDefault("GOOGLE_PLAY_PACKAGE_NAME", "")
+	keyFile := utils.GetEnvWithDefault("GOOGLE_PLAY_SERVICE_ACCOUNT_FILE", "")
+	if packageName != "" && keyFile != "" {
+		key, err := os.ReadFile(keyFile)
+		if err != nil {
+			log.Fatalf("Invalid GOOGLE_PLAY_SERVICE_ACCOUNT_FILE: %v", err)
+		}
+		verifier, err := iap.NewGoogleVerifier(packageName, key)
+		if err != nil {
+			log.Fatalf("Invalid GOOGLE_PLAY_SERVICE_ACCOUNT_FILE: %v", err)
+		}
+		iap.SetVerifier(iap.GooglePlay, verifier)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+)
+
+// runMigrate applies the pending schema migrations, or with -dry-run prints
+// the plan without changing anything. Contract steps only run with -contract,
+// once every instance runs the release that no longer needs what they remove.
+// It returns the process exit code.
+func runMigrate(args []string) int {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "print the planned statements without applying them")
+	contract := flags.Bool("contract", false, "apply contract steps as well as expand steps")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	_, opts, err := database.MigrationSettings()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		return 1
+	}
+	opts.Contract = *contract
+
+	dm := database.NewDatabaseManager()
+	if err := dm.Open(databaseEnv()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer dm.Close()
+
+	if *dryRun {
+		steps, err := database.PlanMigrations(dm.DB)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(steps) == 0 {
+			fmt.Println("Schema is up to date")
+			return 0
+		}
+		for _, step := range steps {
+			notes := []string{step.Phase}
+			if step.Concurrent {
+				notes = append(notes, "concurrent")
+			}
+			if step.Optional {
+				notes = append(notes, "optional")
+			}
+			if step.Phase == database.PhaseContract && !opts.Contract {
+				notes = append(notes, "skipped without -contract")
+			}
+			fmt.Printf("-- %s (%s)\n", step, strings.Join(notes, ", "))
+			for _, statement := range step.Statements {
+				fmt.Println(statement + ";")
+			}
+		}
+		fmt.Printf("-- lock_timeout %s, %d retries\n", opts.LockTimeout, opts.Retries)
+		return 0
+	}
+
+	applied, err := database.ApplyMigrations(dm.DB, opts)
+	for _, step := range applied {
+		fmt.Printf("Applied %s (%s)\n", step, step.Phase)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("%d migrations applied\n", len(applied))
+	return 0
+}
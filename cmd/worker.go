@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/handlers"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/coldstorage"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/email"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/embedding"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/ingest"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/jobs"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/malware"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/notifications"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/reportexport"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/stripeevents"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/webhooks"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+)
+
+// runWorker runs the background jobs in the foreground until SIGINT or SIGTERM.
+// On a signal, as sent when the fleet scales down, it stops claiming
+// translations and lets running ones finish for WORKER_DRAIN_TIMEOUT before
+// handing the rest back to the queue.
+func runWorker() {
+	drainTimeout, err := time.ParseDuration(utils.GetEnvWithDefault("WORKER_DRAIN_TIMEOUT", "25s"))
+	if err != nil || drainTimeout <= 0 {
+		log.Fatalf("Invalid WORKER_DRAIN_TIMEOUT: must be a positive duration")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Println("Starting background worker")
+	startWorkers(ctx)
+
+	var draining atomic.Bool
+	metricsServer := startMetricsServer(utils.GetEnvWithDefault("WORKER_METRICS_ADDR", ":9090"), &draining)
+
+	// Translations queued by API processes in external worker mode
+	translated := make(chan struct{})
+	go func() {
+		defer close(translated)
+		jobs.RunPeriodic(ctx, "queued-translations", 5*time.Second, func(ctx context.Context) error {
+			return jobs.TranslatePending(ctx, database.DB, ingest.Default(), config.Current().MLServiceAddress)
+		})
+	}()
+
+	<-ctx.Done()
+	draining.Store(true)
+	log.Printf("Draining: waiting up to %s for %d running translations", drainTimeout, ingest.Default().Running())
+	select {
+	case <-translated:
+	case <-time.After(drainTimeout):
+		released, err := jobs.ReleaseInFlight(database.DB)
+		if err != nil {
+			log.Printf("Failed to release unfinished translations: %v", err)
+		} else {
+			log.Printf("Drain timed out; returned %d unfinished translations to the queue", released)
+		}
+	}
+
+	if metricsServer != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = metricsServer.Shutdown(shutdownCtx)
+	}
+	log.Println("Background worker stopped")
+}
+
+// startMetricsServer serves the translation queue metrics autoscalers read,
+// with the storage cleanup metrics as Prometheus text on /metrics and as JSON on /metrics/queue, and a
+// readiness probe on /readyz that fails while draining. An empty addr
+// disables it.
+func startMetricsServer(addr string, draining *atomic.Bool) *http.Server {
+	if addr == "" {
+		return nil
+	}
+
+	collect := func(w http.ResponseWriter) (jobs.TranslationMetrics, bool) {
+		metrics, err := jobs.CollectTranslationMetrics(database.DB, ingest.Default(), draining.Load())
+		if err != nil {
+			log.Printf("Failed to collect translation metrics: %v", err)
+			http.Error(w, "failed to collect metrics", http.StatusInternalServerError)
+			return metrics, false
+		}
+		return metrics, true
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		metrics, ok := collect(w)
+		if !ok {
+			return
+		}
+		storage, err := jobs.CollectStorageMetrics(database.DB)
+		if err != nil {
+			log.Printf("Failed to collect storage metrics: %v", err)
+			http.Error(w, "failed to collect metrics", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_ = metrics.WritePrometheus(w)
+		_ = storage.WritePrometheus(w)
+	})
+	mux.HandleFunc("/metrics/queue", func(w http.ResponseWriter, r *http.Request) {
+		metrics, ok := collect(w)
+		if !ok {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(metrics)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if draining.Load() {
+			http.Error(w, "draining", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		log.Printf("Worker metrics listening on %s", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to serve worker metrics: %v", err)
+		}
+	}()
+	return server
+}
+
+// startWorkers starts the email queue and the periodic jobs. They run until ctx is cancelled.
+func startWorkers(ctx context.Context) {
+	// Start the email queue worker
+	go email.NewService(database.DB).Run(ctx)
+
+	// Expire subscriptions whose renewal webhook never arrived
+	expiryGrace, err := time.ParseDuration(utils.GetEnvWithDefault("SUBSCRIPTION_EXPIRY_GRACE", "24h"))
+	if err != nil {
+		log.Fatalf("Invalid SUBSCRIPTION_EXPIRY_GRACE: %v", err)
+	}
+	go jobs.RunPeriodic(ctx, "subscription-expiry", 15*time.Minute, func(ctx context.Context) error {
+		return jobs.ExpireLapsedSubscriptions(ctx, database.DB, expiryGrace)
+	})
+
+	// Process queued Stripe webhook events with the handlers registered for
+	// their types, parking those that keep failing
+	stripeMaxAttempts, err := strconv.Atoi(utils.GetEnvWithDefault("STRIPE_EVENT_MAX_ATTEMPTS", "10"))
+	if err != nil || stripeMaxAttempts < 1 {
+		log.Fatalf("Invalid STRIPE_EVENT_MAX_ATTEMPTS: must be a positive integer")
+	}
+	go jobs.RunPeriodic(ctx, "stripe-events", 2*time.Second, func(ctx context.Context) error {
+		return jobs.ProcessStripeEvents(ctx, database.DB, stripeevents.Handle, stripeMaxAttempts)
+	})
+
+	// Repair subscriptions that drifted from Stripe, e.g. through missed
+	// webhooks. The in-memory stub knows none of the stored subscriptions.
+	reconcileInterval, err := time.ParseDuration(utils.GetEnvWithDefault("SUBSCRIPTION_RECONCILE_INTERVAL", "6h"))
+	if err != nil || reconcileInterval < 0 {
+		log.Fatalf("Invalid SUBSCRIPTION_RECONCILE_INTERVAL: must be a non-negative duration")
+	}
+	if reconcileInterval > 0 && utils.GetEnvWithDefault("BILLING_GATEWAY", "stripe") != "stub" {
+		go jobs.RunPeriodic(ctx, "subscription-reconciliation", reconcileInterval, func(ctx context.Context) error {
+			return jobs.ReconcileSubscriptions(ctx, database.DB)
+		})
+	}
+
+	// Downgrade past-due subscriptions whose payment grace period lapsed
+	if _, err := jobs.PaymentGracePeriod(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	go jobs.RunPeriodic(ctx, "payment-grace", 15*time.Minute, func(ctx context.Context) error {
+		return jobs.DowngradeUnpaidSubscriptions(ctx, database.DB)
+	})
+
+	// Pick up renewals and refunds of App Store and Google Play subscriptions
+	go jobs.RunPeriodic(ctx, "store-purchases", 15*time.Minute, func(ctx context.Context) error {
+		return jobs.RefreshStorePurchases(ctx, database.DB)
+	})
+
+	// Process uploads queued by API processes that did not start them, or
+	// whose process stopped before finishing them
+	go jobs.RunPeriodic(ctx, "queued-uploads", 5*time.Second, func(ctx context.Context) error {
+		return jobs.ProcessQueuedUploads(ctx, database.DB, ingest.Default(), config.Current().MLServiceAddress)
+	})
+
+	// Archive or purge raw recordings of lapsed subscribers per the lapse data policy
+	archiveDir := utils.GetEnvWithDefault("RECORDING_ARCHIVE_DIR", "./archive")
+	go jobs.RunPeriodic(ctx, "recording-retention", 6*time.Hour, func(ctx context.Context) error {
+		return jobs.ApplyRecordingRetention(ctx, database.DB, handlers.UploadDir, archiveDir)
+	})
+
+	// Flag (and optionally email) users who stopped signing in
+	inactiveDays, err := strconv.Atoi(utils.GetEnvWithDefault("INACTIVE_USER_DAYS", "90"))
+	if err != nil || inactiveDays < 1 {
+		log.Fatalf("Invalid INACTIVE_USER_DAYS: must be a positive integer")
+	}
+	notifyInactive := utils.GetEnvWithDefault("INACTIVE_USER_NOTIFY", "false") == "true"
+	go jobs.RunPeriodic(ctx, "inactive-users", 24*time.Hour, func(ctx context.Context) error {
+		return jobs.FlagInactiveUsers(ctx, database.DB, time.Duration(inactiveDays)*24*time.Hour, notifyInactive)
+	})
+
+	// Deliver scheduled broadcast notifications
+	go jobs.RunPeriodic(ctx, "broadcasts", time.Minute, func(ctx context.Context) error {
+		return notifications.SendDueBroadcasts(ctx, database.DB)
+	})
+
+	// Permanently remove reports deleted longer ago than the retention window
+	purgeAfterDays, err := jobs.ReportPurgeAfterDays()
+	if err != nil {
+		log.Fatalf("Invalid REPORT_PURGE_AFTER_DAYS: must be a non-negative integer")
+	}
+	go jobs.RunPeriodic(ctx, "report-purge", 24*time.Hour, func(ctx context.Context) error {
+		purged, err := models.PurgeDeletedReports(database.DB.WithContext(ctx), time.Now().AddDate(0, 0, -purgeAfterDays))
+		if purged > 0 {
+			log.Printf("Purged %d deleted reports", purged)
+		}
+		return err
+	})
+
+	// Delete files past FILE_RETENTION_DAYS and stored files or records
+	// missing their counterpart
+	retentionDays, err := jobs.FileRetentionDays()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	go jobs.RunPeriodic(ctx, "storage-cleanup", 24*time.Hour, func(ctx context.Context) error {
+		run, err := jobs.CleanUpStorage(ctx, database.DB.WithContext(ctx), retentionDays, []string{handlers.UploadDir, malware.QuarantineDir()})
+		if run.ExpiredFiles+run.OrphanedBlobs+run.OrphanedRecords > 0 {
+			log.Printf("Storage cleanup deleted %d expired files, %d orphaned files and %d orphaned records, reclaiming %d bytes",
+				run.ExpiredFiles, run.OrphanedBlobs, run.OrphanedRecords, run.ReclaimedBytes)
+		}
+		return err
+	})
+
+	// Move the content of old reports to cold storage
+	coldStorageMonths, err := jobs.ColdStorageAfterMonths()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if coldStorageMonths > 0 {
+		archive, err := coldstorage.FromEnv()
+		if err != nil {
+			log.Fatalf("Invalid configuration: %v", err)
+		}
+		go jobs.RunPeriodic(ctx, "report-cold-storage", 24*time.Hour, func(ctx context.Context) error {
+			archived, err := jobs.ArchiveReportContents(ctx, database.DB.WithContext(ctx), archive, coldStorageMonths, 200)
+			if archived > 0 {
+				log.Printf("Moved the content of %d reports to cold storage", archived)
+			}
+			return err
+		})
+	}
+
+	// Rewrite reports stored under an older content schema
+	go jobs.RunPeriodic(ctx, "report-content-migration", 24*time.Hour, func(ctx context.Context) error {
+		upgraded, err := models.MigrateReportContents(ctx, database.DB, 200)
+		if upgraded > 0 {
+			log.Printf("Upgraded %d reports to content schema version %d", upgraded, models.CurrentContentSchemaVersion)
+		}
+		return err
+	})
+
+	// Generate queued bulk report exports and remove expired export files
+	exportTTL, err := time.ParseDuration(utils.GetEnvWithDefault("REPORT_EXPORT_TTL", "24h"))
+	if err != nil || exportTTL <= 0 {
+		log.Fatalf("Invalid REPORT_EXPORT_TTL: must be a positive duration")
+	}
+	go jobs.RunPeriodic(ctx, "report-exports", 10*time.Second, func(ctx context.Context) error {
+		return reportexport.RunPending(ctx, database.DB, handlers.ReportExportDir, exportTTL)
+	})
+	go jobs.RunPeriodic(ctx, "report-export-cleanup", time.Hour, func(ctx context.Context) error {
+		return reportexport.PurgeExpired(ctx, database.DB)
+	})
+
+	// Deliver queued report events to user webhooks
+	go jobs.RunPeriodic(ctx, "webhook-deliveries", 5*time.Second, func(ctx context.Context) error {
+		return webhooks.DeliverDue(ctx, database.DB.WithContext(ctx))
+	})
+
+	// Embed new and changed report text for similarity search
+	if database.VectorSearch {
+		go jobs.RunPeriodic(ctx, "report-embeddings", time.Minute, func(ctx context.Context) error {
+			indexed, err := embedding.IndexReports(ctx, database.DB, embedding.Default(), 100)
+			if indexed > 0 {
+				log.Printf("Embedded %d reports", indexed)
+			}
+			return err
+		})
+	}
+
+	// Prune revoked tokens past their expiry, stale token-use records and
+	// expired checkout tokens
+	go jobs.RunPeriodic(ctx, "token-cleanup", time.Hour, func(ctx context.Context) error {
+		if err := models.CleanupExpiredTokens(database.DB.WithContext(ctx)); err != nil {
+			return err
+		}
+		if err := models.CleanupTokenUses(database.DB.WithContext(ctx), time.Now().Add(-24*time.Hour)); err != nil {
+			return err
+		}
+		return models.CleanupCheckoutTokens(database.DB.WithContext(ctx), time.Now().Add(-7*24*time.Hour))
+	})
+}
@@ -0,0 +1,492 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/joho/godotenv"
+)
+
+// Settings holds the non-secret tunables that can be changed without a restart.
+// Secrets (JWT, Stripe, database credentials) are read once at startup and are
+// deliberately not part of this struct.
+type Settings struct {
+	MLServiceAddress   string                 `json:"ml_service_address"`
+	MaxUploadSizeMB    int                    `json:"max_upload_size_mb"`
+	EmailRatePerMinute int                    `json:"email_rate_per_minute"`
+	EmailProviderRates map[string]int         `json:"email_provider_rates"`
+	EmailMaxAttempts   int                    `json:"email_max_attempts"`
+	FeatureFlags       map[string]bool        `json:"feature_flags"`
+	FreeRatePerMinute  int                    `json:"free_rate_per_minute"`
+	PaidRatePerMinute  int                    `json:"paid_rate_per_minute"`
+	PlanQuotas         map[string]PlanQuota   `json:"plan_quotas"`
+	PlanCapabilities   map[string][]string    `json:"plan_capabilities"`
+	UploadQueueLimit   int                    `json:"upload_queue_limit"`
+	LapsePolicies      map[string]LapsePolicy `json:"lapse_policies"`
+	UpgradeURL         string                 `json:"upgrade_url"`
+	UploadFormats      []string               `json:"upload_formats"`
+}
+
+// Built-in plan keys used when a user's plan has no quota entry of its own
+const (
+	PlanFree = "free"
+	PlanPaid = "paid"
+)
+
+// File formats uploads may have, recognized by their content
+const (
+	UploadFormatJSON = "json" // EEG recordings as a JSON object
+	UploadFormatEDF  = "edf"  // European Data Format
+	UploadFormatBDF  = "bdf"  // BioSemi Data Format
+	UploadFormatCSV  = "csv"  // Delimited text
+)
+
+// uploadFormats are the formats UPLOAD_ALLOWED_FORMATS may list: those a
+// recording can be decoded from. EDF, BDF and CSV are recognized so they are
+// refused with a clear error, but cannot be listed until they can be decoded.
+var uploadFormats = map[string]bool{UploadFormatJSON: true}
+
+// Capabilities a plan can include beyond its quotas
+const (
+	CapabilityPDFExport = "pdf_export" // Export reports as PDF documents
+	CapabilitySharing   = "sharing"    // Share reports by public link
+	CapabilityMLAPI     = "ml_api"     // Call the ML service directly with an API token
+)
+
+// capabilities are the capabilities PLAN_CAPABILITIES may list
+var capabilities = map[string]bool{CapabilityPDFExport: true, CapabilitySharing: true, CapabilityMLAPI: true}
+
+// What happens to a user's raw recordings after their subscription lapses
+const (
+	LapseRetain  = "retain"
+	LapseArchive = "archive"
+	LapsePurge   = "purge"
+)
+
+// LapsePolicy controls what happens to raw recordings once a subscription has
+// lapsed for GraceDays
+type LapsePolicy struct {
+	Action    string `json:"action"`
+	GraceDays int    `json:"grace_days"`
+}
+
+// PlanQuota holds the monthly usage limits for a plan; -1 means unlimited
+type PlanQuota struct {
+	UploadsPerMonth      int   `json:"uploads_per_month"`
+	TranslationsPerMonth int   `json:"translations_per_month"`
+	StorageBytes         int64 `json:"storage_bytes"`
+	// Largest file the plan may upload, within MAX_UPLOAD_SIZE_MB
+	MaxFileBytes int64 `json:"max_file_bytes"`
+	// Total size of the files the plan may upload per month
+	UploadBytesPerMonth int64 `json:"upload_bytes_per_month"`
+}
+
+// Change describes a single setting that differs between two snapshots
+type Change struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+var (
+	current  atomic.Pointer[Settings]
+	reloadMu sync.Mutex
+)
+
+// Current returns the active settings snapshot. The returned value must be treated as read-only.
+func Current() *Settings {
+	if s := current.Load(); s != nil {
+		return s
+	}
+
+	// Lazily load so packages can read settings before Init has run
+	s, err := Load()
+	if err != nil {
+		log.Printf("Invalid configuration, using defaults: %v", err)
+		s = defaults()
+	}
+	current.CompareAndSwap(nil, s)
+	return current.Load()
+}
+
+// Init loads the initial settings and fails if they are invalid
+func Init() error {
+	s, err := Load()
+	if err != nil {
+		return err
+	}
+	current.Store(s)
+	return nil
+}
+
+// Reload re-reads the settings, validates them and swaps them in atomically.
+// On validation failure the active settings are left untouched.
+func Reload() ([]Change, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	next, err := Load()
+	if err != nil {
+		log.Printf("Config reload rejected: %v", err)
+		return nil, err
+	}
+
+	prev := Current()
+	changes := Diff(prev, next)
+	current.Store(next)
+
+	if len(changes) == 0 {
+		log.Println("Config reloaded, no changes")
+	}
+	for _, ch := range changes {
+		log.Printf("Config changed: %s %v -> %v", ch.Field, ch.Old, ch.New)
+	}
+
+	return changes, nil
+}
+
+// Load builds a settings snapshot from the env file and the process environment and validates it
+func Load() (*Settings, error) {
+	lookup := newLookup()
+	s := defaults()
+
+	s.MLServiceAddress = lookup.str("ML_SERVICE_ADDRESS", s.MLServiceAddress)
+	s.UpgradeURL = strings.TrimSpace(lookup.str("UPGRADE_URL", s.UpgradeURL))
+
+	var err error
+	if s.MaxUploadSizeMB, err = lookup.int("MAX_UPLOAD_SIZE_MB", s.MaxUploadSizeMB); err != nil {
+		return nil, err
+	}
+	if s.EmailRatePerMinute, err = lookup.int("EMAIL_RATE_PER_MINUTE", s.EmailRatePerMinute); err != nil {
+		return nil, err
+	}
+	if s.EmailMaxAttempts, err = lookup.int("EMAIL_MAX_ATTEMPTS", s.EmailMaxAttempts); err != nil {
+		return nil, err
+	}
+	if s.FreeRatePerMinute, err = lookup.int("RATE_LIMIT_FREE_PER_MINUTE", s.FreeRatePerMinute); err != nil {
+		return nil, err
+	}
+	if s.PaidRatePerMinute, err = lookup.int("RATE_LIMIT_PAID_PER_MINUTE", s.PaidRatePerMinute); err != nil {
+		return nil, err
+	}
+	if s.UploadQueueLimit, err = lookup.int("UPLOAD_QUEUE_LIMIT", s.UploadQueueLimit); err != nil {
+		return nil, err
+	}
+
+	for _, key := range lookup.keysWithPrefix("EMAIL_RATE_PER_MINUTE_") {
+		rate, err := lookup.int(key, 0)
+		if err != nil {
+			return nil, err
+		}
+		s.EmailProviderRates[strings.ToLower(strings.TrimPrefix(key, "EMAIL_RATE_PER_MINUTE_"))] = rate
+	}
+
+	if raw := lookup.str("PLAN_QUOTAS", ""); raw != "" {
+		var quotas map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(raw), &quotas); err != nil {
+			return nil, fmt.Errorf("PLAN_QUOTAS must be a JSON object of plan quotas: %v", err)
+		}
+		for plan, rawQuota := range quotas {
+			// The upload size limits came later; entries written before them stay unlimited
+			quota := PlanQuota{MaxFileBytes: -1, UploadBytesPerMonth: -1}
+			if err := json.Unmarshal(rawQuota, &quota); err != nil {
+				return nil, fmt.Errorf("PLAN_QUOTAS[%s] must be a JSON object of limits: %v", plan, err)
+			}
+			s.PlanQuotas[plan] = quota
+		}
+	}
+
+	if raw := lookup.str("PLAN_CAPABILITIES", ""); raw != "" {
+		var planCapabilities map[string][]string
+		if err := json.Unmarshal([]byte(raw), &planCapabilities); err != nil {
+			return nil, fmt.Errorf("PLAN_CAPABILITIES must be a JSON object of capability lists: %v", err)
+		}
+		for plan, list := range planCapabilities {
+			s.PlanCapabilities[plan] = list
+		}
+	}
+
+	if raw := lookup.str("LAPSE_DATA_POLICY", ""); raw != "" {
+		var policies map[string]LapsePolicy
+		if err := json.Unmarshal([]byte(raw), &policies); err != nil {
+			return nil, fmt.Errorf("LAPSE_DATA_POLICY must be a JSON object of lapse policies: %v", err)
+		}
+		for plan, policy := range policies {
+			s.LapsePolicies[plan] = policy
+		}
+	}
+
+	if raw := lookup.str("UPLOAD_ALLOWED_FORMATS", ""); raw != "" {
+		s.UploadFormats = nil
+		for _, format := range strings.Split(raw, ",") {
+			if format = strings.ToLower(strings.TrimSpace(format)); format != "" {
+				s.UploadFormats = append(s.UploadFormats, format)
+			}
+		}
+	}
+
+	for _, flag := range strings.Split(lookup.str("FEATURE_FLAGS", ""), ",") {
+		if flag = strings.TrimSpace(flag); flag != "" {
+			s.FeatureFlags[flag] = true
+		}
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Validate checks that all settings are within sane bounds
+func (s *Settings) Validate() error {
+	if strings.TrimSpace(s.MLServiceAddress) == "" {
+		return fmt.Errorf("ML_SERVICE_ADDRESS must not be empty")
+	}
+	if s.MaxUploadSizeMB < 1 || s.MaxUploadSizeMB > 1024 {
+		return fmt.Errorf("MAX_UPLOAD_SIZE_MB must be between 1 and 1024, got %d", s.MaxUploadSizeMB)
+	}
+	if s.EmailRatePerMinute < 1 {
+		return fmt.Errorf("EMAIL_RATE_PER_MINUTE must be positive, got %d", s.EmailRatePerMinute)
+	}
+	for provider, rate := range s.EmailProviderRates {
+		if rate < 1 {
+			return fmt.Errorf("EMAIL_RATE_PER_MINUTE_%s must be positive, got %d", strings.ToUpper(provider), rate)
+		}
+	}
+	if s.EmailMaxAttempts < 1 || s.EmailMaxAttempts > 50 {
+		return fmt.Errorf("EMAIL_MAX_ATTEMPTS must be between 1 and 50, got %d", s.EmailMaxAttempts)
+	}
+	if s.FreeRatePerMinute < 1 {
+		return fmt.Errorf("RATE_LIMIT_FREE_PER_MINUTE must be positive, got %d", s.FreeRatePerMinute)
+	}
+	if s.PaidRatePerMinute < 1 {
+		return fmt.Errorf("RATE_LIMIT_PAID_PER_MINUTE must be positive, got %d", s.PaidRatePerMinute)
+	}
+	if s.UploadQueueLimit < 0 {
+		return fmt.Errorf("UPLOAD_QUEUE_LIMIT must not be negative, got %d", s.UploadQueueLimit)
+	}
+	if s.UpgradeURL != "" {
+		if u, err := url.Parse(s.UpgradeURL); err != nil || !u.IsAbs() {
+			return fmt.Errorf("UPGRADE_URL must be an absolute URL, got %q", s.UpgradeURL)
+		}
+	}
+	if len(s.UploadFormats) == 0 {
+		return fmt.Errorf("UPLOAD_ALLOWED_FORMATS must list at least one format")
+	}
+	for _, format := range s.UploadFormats {
+		if !uploadFormats[format] {
+			return fmt.Errorf("UPLOAD_ALLOWED_FORMATS may only list json, got %q", format)
+		}
+	}
+	for plan, policy := range s.LapsePolicies {
+		if err := ValidateLapsePolicy(policy.Action, policy.GraceDays); err != nil {
+			return fmt.Errorf("LAPSE_DATA_POLICY[%s]: %v", plan, err)
+		}
+	}
+	for plan, quota := range s.PlanQuotas {
+		if quota.UploadsPerMonth < -1 || quota.TranslationsPerMonth < -1 || quota.StorageBytes < -1 ||
+			quota.MaxFileBytes < -1 || quota.UploadBytesPerMonth < -1 {
+			return fmt.Errorf("PLAN_QUOTAS[%s] limits must be -1 (unlimited) or non-negative", plan)
+		}
+	}
+	for plan, list := range s.PlanCapabilities {
+		for _, capability := range list {
+			if !capabilities[capability] {
+				return fmt.Errorf("PLAN_CAPABILITIES[%s] may only list pdf_export, sharing and ml_api, got %q", plan, capability)
+			}
+		}
+	}
+	return nil
+}
+
+// EmailRateFor returns the per-minute send limit for an email provider
+func (s *Settings) EmailRateFor(provider string) int {
+	if rate, ok := s.EmailProviderRates[provider]; ok {
+		return rate
+	}
+	return s.EmailRatePerMinute
+}
+
+// QuotaFor returns the quota for a plan ID, falling back to the built-in paid
+// or free plan when the ID has no entry of its own
+func (s *Settings) QuotaFor(planID string, subscribed bool) PlanQuota {
+	if !subscribed {
+		return s.PlanQuotas[PlanFree]
+	}
+	if quota, ok := s.PlanQuotas[planID]; ok {
+		return quota
+	}
+	return s.PlanQuotas[PlanPaid]
+}
+
+// CapabilitiesFor returns the capabilities of a plan ID, falling back to the
+// built-in paid or free plan when the ID has no entry of its own
+func (s *Settings) CapabilitiesFor(planID string, subscribed bool) []string {
+	if !subscribed {
+		return s.PlanCapabilities[PlanFree]
+	}
+	if list, ok := s.PlanCapabilities[planID]; ok {
+		return list
+	}
+	return s.PlanCapabilities[PlanPaid]
+}
+
+// LapsePolicyFor returns the lapse policy for a plan ID, falling back to the
+// built-in paid plan when the ID has no entry of its own
+func (s *Settings) LapsePolicyFor(planID string) LapsePolicy {
+	if policy, ok := s.LapsePolicies[planID]; ok {
+		return policy
+	}
+	return s.LapsePolicies[PlanPaid]
+}
+
+// ValidateLapsePolicy checks a lapse action and grace period
+func ValidateLapsePolicy(action string, graceDays int) error {
+	switch action {
+	case LapseRetain, LapseArchive, LapsePurge:
+	default:
+		return fmt.Errorf("action must be one of retain, archive or purge, got %q", action)
+	}
+	if graceDays < 0 {
+		return fmt.Errorf("grace_days must not be negative, got %d", graceDays)
+	}
+	return nil
+}
+
+// UploadFormatAllowed reports whether uploads of a format are accepted
+func (s *Settings) UploadFormatAllowed(format string) bool {
+	for _, allowed := range s.UploadFormats {
+		if allowed == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Enabled reports whether a feature flag is switched on
+func (s *Settings) Enabled(flag string) bool {
+	return s.FeatureFlags[flag]
+}
+
+// Diff lists the fields that differ between two snapshots
+func Diff(prev, next *Settings) []Change {
+	var changes []Change
+
+	pv := reflect.ValueOf(prev).Elem()
+	nv := reflect.ValueOf(next).Elem()
+	t := pv.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		oldValue := pv.Field(i).Interface()
+		newValue := nv.Field(i).Interface()
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, Change{
+				Field: t.Field(i).Tag.Get("json"),
+				Old:   oldValue,
+				New:   newValue,
+			})
+		}
+	}
+
+	return changes
+}
+
+// defaults returns the built-in settings
+func defaults() *Settings {
+	return &Settings{
+		MLServiceAddress:   "ml-service:50052",
+		MaxUploadSizeMB:    50,
+		EmailRatePerMinute: 60,
+		EmailProviderRates: map[string]int{},
+		EmailMaxAttempts:   8,
+		FeatureFlags:       map[string]bool{},
+		FreeRatePerMinute:  60,
+		PaidRatePerMinute:  300,
+		UploadQueueLimit:   20,
+		UploadFormats:      []string{UploadFormatJSON},
+		LapsePolicies: map[string]LapsePolicy{
+			PlanPaid: {Action: LapseRetain, GraceDays: 30},
+		},
+		PlanQuotas: map[string]PlanQuota{
+			PlanFree: {UploadsPerMonth: 20, TranslationsPerMonth: 20, StorageBytes: 500 << 20, MaxFileBytes: 20 << 20, UploadBytesPerMonth: 500 << 20},
+			PlanPaid: {UploadsPerMonth: 1000, TranslationsPerMonth: 1000, StorageBytes: 20 << 30, MaxFileBytes: -1, UploadBytesPerMonth: 50 << 30},
+		},
+		PlanCapabilities: map[string][]string{
+			PlanFree: {CapabilityPDFExport, CapabilitySharing},
+			PlanPaid: {CapabilityPDFExport, CapabilitySharing, CapabilityMLAPI},
+		},
+	}
+}
+
+// lookup resolves settings from the env file first, then the process environment.
+// The file takes precedence so that editing it and reloading has an effect even
+// when the same variable was exported at startup.
+type lookup struct {
+	file map[string]string
+}
+
+func newLookup() *lookup {
+	path := os.Getenv("CONFIG_ENV_FILE")
+	if path == "" {
+		path = ".env"
+	}
+
+	file, err := godotenv.Read(path)
+	if err != nil {
+		file = map[string]string{}
+	}
+	return &lookup{file: file}
+}
+
+func (l *lookup) str(key, defaultValue string) string {
+	if value, ok := l.file[key]; ok && value != "" {
+		return value
+	}
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func (l *lookup) int(key string, defaultValue int) (int, error) {
+	raw := l.str(key, "")
+	if raw == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer, got %q", key, raw)
+	}
+	return value, nil
+}
+
+func (l *lookup) keysWithPrefix(prefix string) []string {
+	seen := map[string]bool{}
+	for key := range l.file {
+		if strings.HasPrefix(key, prefix) {
+			seen[key] = true
+		}
+	}
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(key, prefix) {
+			seen[key] = true
+		}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
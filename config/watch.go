@@ -0,0 +1,26 @@
+package config
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// WatchSignals reloads the settings every time the process receives SIGHUP
+func WatchSignals(ctx context.Context) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	defer signal.Stop(sigs)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigs:
+			log.Println("Received SIGHUP, reloading config")
+			_, _ = Reload()
+		}
+	}
+}
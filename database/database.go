@@ -2,6 +2,7 @@ package database
 
 import (
 	"fmt"
+	"log"
 	"os"
 
 	"gorm.io/driver/postgres"
@@ -21,8 +22,22 @@ func NewDatabaseManager() *DatabaseManager {
 	return &DatabaseManager{}
 }
 
-// Connect establishes a connection to the PostgreSQL database
+// Connect establishes a connection to the PostgreSQL database and migrates the models
 func (dm *DatabaseManager) Connect(host, user, password, dbname, port, sslMode string) error {
+	if err := dm.Open(host, user, password, dbname, port, sslMode); err != nil {
+		return err
+	}
+
+	err := dm.MigrateModels()
+	if err != nil {
+		return fmt.Errorf("failed to migrate database models: %w", err)
+	}
+	DB = dm.GetDB()
+	return nil
+}
+
+// Open connects to the PostgreSQL database without migrating it
+func (dm *DatabaseManager) Open(host, user, password, dbname, port, sslMode string) error {
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
 		host, user, password, dbname, port, sslMode)
 
@@ -43,26 +58,125 @@ func (dm *DatabaseManager) Connect(host, user, password, dbname, port, sslMode s
 	}
 
 	dm.DB = db
-	err = dm.MigrateModels()
-	if err != nil {
-		return fmt.Errorf("failed to migrate database models: %w", err)
-	}
-	DB = dm.GetDB()
 	return nil
 }
 
-// MigrateModels runs auto migration for the database models
+// migratedModels are the models kept in sync with the schema by MigrateModels
+var migratedModels = []interface{}{
+	&models.User{},
+	&models.Report{},
+	&models.BlacklistedToken{},
+	&models.SingleFile{},
+	&models.DeviceRecording{},
+	&models.UploadJob{},
+	&models.RecordingSession{},
+	&models.FilePreview{},
+	&models.StorageCleanupRun{},
+	&models.EmailMessage{},
+	&models.EmailSuppression{},
+	&models.UserLink{},
+	&models.Organization{},
+	&models.OrgRatePlan{},
+	&models.OrgBranding{},
+	&models.OrgModerationPolicy{},
+	&models.UsageCounter{},
+	&models.EmailVerification{},
+	&models.TokenUse{},
+	&models.CheckoutToken{},
+	&models.LoginEvent{},
+	&models.Notification{},
+	&models.Broadcast{},
+	&models.ServiceCredential{},
+	&models.EmbedWidget{},
+	&models.AuditLog{},
+	&models.Tag{},
+	&models.ReportTag{},
+	&models.ReportExport{},
+	&models.ReportShare{},
+	&models.ReportRevision{},
+	&models.ReportAccess{},
+	&models.ReportEmbeddingFailure{},
+	&models.ReportTemplate{},
+	&models.StripeEvent{},
+	&models.CustomerTaxID{},
+	&models.OrgSubscription{},
+	&models.StorePurchase{},
+	&models.WebhookSubscription{},
+	&models.WebhookDelivery{},
+}
+
+// MigrateModels brings the schema in line with the models according to
+// MIGRATION_MODE: in auto mode the expand steps are applied, while contract
+// steps and, in manual mode, every step are left to the migrate command
 func (dm *DatabaseManager) MigrateModels() error {
 	if dm.DB == nil {
 		return fmt.Errorf("database connection not established")
 	}
 
-	return dm.DB.AutoMigrate(
-		&models.User{},
-		&models.Report{},
-		&models.BlacklistedToken{},
-		&models.SingleFile{},
-	)
+	mode, opts, err := MigrationSettings()
+	if err != nil {
+		return err
+	}
+
+	if mode == MigrationManual {
+		pending, err := PlanMigrations(dm.DB)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			log.Printf("%d schema migrations pending; run the migrate command to apply them", len(pending))
+		}
+		VectorSearch = dm.DB.Migrator().HasTable("report_embeddings")
+		return nil
+	}
+
+	applied, err := ApplyMigrations(dm.DB, opts)
+	if err != nil {
+		return err
+	}
+	for _, step := range applied {
+		log.Printf("Applied migration %s", step)
+	}
+
+	steps, err := PlanMigrations(dm.DB)
+	if err != nil {
+		return err
+	}
+	contract := 0
+	for _, step := range steps {
+		if step.Phase == PhaseContract {
+			contract++
+		}
+	}
+	if contract > 0 {
+		log.Printf("%d contract migrations pending; run the migrate command with -contract once every instance runs this release", contract)
+	}
+	if !VectorSearch {
+		log.Printf("Report similarity search disabled, pgvector is unavailable")
+	}
+	return nil
+}
+
+// VectorSearch reports whether the pgvector extension and the report
+// embeddings table are available
+var VectorSearch bool
+
+// PendingMigrations lists the planned migration steps that have not been
+// applied, as "kind table.column"
+func (dm *DatabaseManager) PendingMigrations() ([]string, error) {
+	if dm.DB == nil {
+		return nil, fmt.Errorf("database connection not established")
+	}
+
+	steps, err := PlanMigrations(dm.DB)
+	if err != nil {
+		return nil, err
+	}
+	pending := make([]string, 0, len(steps))
+	for _, step := range steps {
+		pending = append(pending, step.String())
+	}
+	return pending, nil
 }
 
 // GetDB returns the gorm DB instance
@@ -0,0 +1,441 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+)
+
+// Migration modes
+const (
+	// MigrationAuto applies expand migrations on startup
+	MigrationAuto = "auto"
+	// MigrationManual leaves every migration to the migrate command
+	MigrationManual = "manual"
+)
+
+// Migration phases. Expand steps only add to the schema, so instances still
+// running the previous release keep working; contract steps change or remove
+// what the previous release may still use and run once every instance is
+// on the new code.
+const (
+	PhaseExpand   = "expand"
+	PhaseContract = "contract"
+)
+
+// Kinds of migration steps
+const (
+	StepCreateTable   = "create_table"
+	StepAddColumn     = "add_column"
+	StepCreateIndex   = "create_index"
+	StepAddConstraint = "add_constraint"
+	StepAlterColumn   = "alter_column"
+	StepDropColumn    = "drop_column"
+	StepCustom        = "custom"
+)
+
+// migrationLockKey is the advisory lock held while migrating, so instances
+// starting together do not migrate concurrently
+const migrationLockKey = 0x7468696e6b696e6b
+
+// lockNotAvailable is the SQLSTATE of a statement that gave up waiting for a lock
+const lockNotAvailable = "55P03"
+
+// unmanagedColumns exist outside the models and must not be dropped
+var unmanagedColumns = map[string]bool{
+	"reports.search_vector": true,
+}
+
+// MigrationStep is a single schema change and the statements that make it
+type MigrationStep struct {
+	Phase      string   `json:"phase" example:"expand"`
+	Kind       string   `json:"kind" example:"add_column"`
+	Table      string   `json:"table" example:"reports"`
+	Name       string   `json:"name,omitempty" example:"source_file_id"`
+	Statements []string `json:"statements" example:"ALTER TABLE \"reports\" ADD \"source_file_id\" bigint"`
+	// Concurrent steps build indexes without blocking writes; they run outside a transaction
+	Concurrent bool `json:"concurrent,omitempty"`
+	// Optional steps may fail without failing the migration
+	Optional bool `json:"optional,omitempty"`
+}
+
+// String names the step, e.g. "add_column reports.source_file_id"
+func (s MigrationStep) String() string {
+	if s.Name == "" {
+		return s.Kind + " " + s.Table
+	}
+	return s.Kind + " " + s.Table + "." + s.Name
+}
+
+// MigrationOptions bound how migrations wait for table locks
+type MigrationOptions struct {
+	// How long a statement may wait for a lock before it is abandoned, so a
+	// migration queued behind a long transaction does not block all traffic
+	LockTimeout time.Duration
+	// How often a step abandoned on a lock timeout is tried again
+	Retries int
+	// Apply contract steps too; otherwise only expand steps run
+	Contract bool
+}
+
+// MigrationSettings returns the configured MIGRATION_MODE and the lock
+// guardrails from MIGRATION_LOCK_TIMEOUT and MIGRATION_LOCK_RETRIES
+func MigrationSettings() (string, MigrationOptions, error) {
+	var opts MigrationOptions
+	mode := getEnvWithDefault("MIGRATION_MODE", MigrationAuto)
+	if mode != MigrationAuto && mode != MigrationManual {
+		return "", opts, fmt.Errorf("MIGRATION_MODE must be %q or %q", MigrationAuto, MigrationManual)
+	}
+	lockTimeout, err := time.ParseDuration(getEnvWithDefault("MIGRATION_LOCK_TIMEOUT", "5s"))
+	if err != nil || lockTimeout <= 0 {
+		return "", opts, fmt.Errorf("MIGRATION_LOCK_TIMEOUT must be a positive duration")
+	}
+	retries, err := strconv.Atoi(getEnvWithDefault("MIGRATION_LOCK_RETRIES", "3"))
+	if err != nil || retries < 0 {
+		return "", opts, fmt.Errorf("MIGRATION_LOCK_RETRIES must be a non-negative integer")
+	}
+	opts.LockTimeout = lockTimeout
+	opts.Retries = retries
+	return mode, opts, nil
+}
+
+// statementRecorder stands in for the connection pool while planning: reads
+// reach the database, while schema changes are recorded instead of executed
+type statementRecorder struct {
+	db         *sql.DB
+	dialector  gorm.Dialector
+	statements []string
+}
+
+func (r *statementRecorder) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return r.db.PrepareContext(ctx, query)
+}
+
+func (r *statementRecorder) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	r.statements = append(r.statements, r.dialector.Explain(query, args...))
+	return driver.RowsAffected(0), nil
+}
+
+func (r *statementRecorder) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return r.db.QueryContext(ctx, query, args...)
+}
+
+func (r *statementRecorder) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return r.db.QueryRowContext(ctx, query, args...)
+}
+
+// PlanMigrations lists the steps that bring the schema in line with the
+// models, in the order they must run. Nothing is changed.
+func PlanMigrations(db *gorm.DB) ([]MigrationStep, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	recorder := &statementRecorder{db: sqlDB, dialector: db.Dialector}
+	planner := db.Session(&gorm.Session{NewDB: true})
+	planner.Statement.ConnPool = recorder
+	migrator := planner.Migrator()
+
+	var steps []MigrationStep
+	record := func(step MigrationStep, change func() error) error {
+		recorder.statements = nil
+		if err := change(); err != nil {
+			return fmt.Errorf("failed to plan %s: %w", step, err)
+		}
+		if len(recorder.statements) > 0 {
+			step.Statements = recorder.statements
+			steps = append(steps, step)
+		}
+		return nil
+	}
+
+	ordered := migratedModels
+	if reorderer, ok := migrator.(interface {
+		ReorderModels([]interface{}, bool) []interface{}
+	}); ok {
+		ordered = reorderer.ReorderModels(migratedModels, true)
+	}
+
+	for _, model := range ordered {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		table := stmt.Schema.Table
+
+		if !migrator.HasTable(model) {
+			if err := record(MigrationStep{Phase: PhaseExpand, Kind: StepCreateTable, Table: table}, func() error {
+				return migrator.CreateTable(model)
+			}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		columnTypes, err := migrator.ColumnTypes(model)
+		if err != nil {
+			return nil, err
+		}
+		existing := make(map[string]gorm.ColumnType, len(columnTypes))
+		for _, columnType := range columnTypes {
+			existing[columnType.Name()] = columnType
+		}
+
+		for _, dbName := range stmt.Schema.DBNames {
+			field := stmt.Schema.FieldsByDBName[dbName]
+			columnType, found := existing[dbName]
+			step := MigrationStep{Phase: PhaseExpand, Kind: StepAddColumn, Table: table, Name: dbName}
+			change := func() error { return migrator.AddColumn(model, dbName) }
+			if found {
+				step = MigrationStep{Phase: PhaseContract, Kind: StepAlterColumn, Table: table, Name: dbName}
+				change = func() error { return migrator.MigrateColumn(model, field, columnType) }
+			}
+			if err := record(step, change); err != nil {
+				return nil, err
+			}
+		}
+
+		var constraints []string
+		for _, rel := range stmt.Schema.Relationships.Relations {
+			if rel.Field.IgnoreMigration {
+				continue
+			}
+			if constraint := rel.ParseConstraint(); constraint != nil && constraint.Schema == stmt.Schema {
+				constraints = append(constraints, constraint.Name)
+			}
+		}
+		for name := range stmt.Schema.ParseCheckConstraints() {
+			constraints = append(constraints, name)
+		}
+		sort.Strings(constraints)
+		for _, name := range constraints {
+			if migrator.HasConstraint(model, name) {
+				continue
+			}
+			if err := record(MigrationStep{Phase: PhaseExpand, Kind: StepAddConstraint, Table: table, Name: name}, func() error {
+				return migrator.CreateConstraint(model, name)
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		var indexes []string
+		for name := range stmt.Schema.ParseIndexes() {
+			indexes = append(indexes, name)
+		}
+		sort.Strings(indexes)
+		for _, name := range indexes {
+			if migrator.HasIndex(model, name) {
+				continue
+			}
+			// Indexes on existing tables are built without blocking writes
+			if err := record(MigrationStep{Phase: PhaseExpand, Kind: StepCreateIndex, Table: table, Name: name, Concurrent: true}, func() error {
+				if err := migrator.CreateIndex(model, name); err != nil {
+					return err
+				}
+				for i, statement := range recorder.statements {
+					recorder.statements[i] = strings.Replace(statement, " INDEX IF NOT EXISTS ", " INDEX CONCURRENTLY IF NOT EXISTS ", 1)
+				}
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		var removed []string
+		for name := range existing {
+			if _, ok := stmt.Schema.FieldsByDBName[name]; !ok && !unmanagedColumns[table+"."+name] {
+				removed = append(removed, name)
+			}
+		}
+		sort.Strings(removed)
+		for _, name := range removed {
+			steps = append(steps, MigrationStep{
+				Phase:      PhaseContract,
+				Kind:       StepDropColumn,
+				Table:      table,
+				Name:       name,
+				Statements: []string{fmt.Sprintf(`ALTER TABLE %q DROP COLUMN %q`, table, name)},
+			})
+		}
+	}
+
+	steps = append(steps, planFileStateBackfill(db)...)
+	return append(steps, planSearchIndex(db)...), nil
+}
+
+// planFileStateBackfill sets the processing state of files uploaded before it
+// was persisted, deriving it from their upload job and report. It is planned
+// only while the state column is missing, so it runs once, right after the
+// column is added.
+func planFileStateBackfill(db *gorm.DB) []MigrationStep {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&models.SingleFile{}) || migrator.HasColumn(&models.SingleFile{}, "state") {
+		return nil
+	}
+	return []MigrationStep{{
+		Phase: PhaseExpand,
+		Kind:  StepCustom,
+		Table: "single_files",
+		Name:  "state",
+		Statements: []string{`UPDATE single_files f SET state_changed_at = now(), state = CASE
+			WHEN f.quarantined_at IS NOT NULL
+				OR EXISTS (SELECT 1 FROM reports r WHERE r.source_file_id = f.id AND r.translation_status = 'failed')
+				OR (EXISTS (SELECT 1 FROM upload_jobs j WHERE j.file_id = f.id AND j.status = 'failed')
+					AND NOT EXISTS (SELECT 1 FROM reports r WHERE r.source_file_id = f.id)) THEN 'failed'
+			WHEN EXISTS (SELECT 1 FROM reports r WHERE r.source_file_id = f.id AND r.translation_status IN ('pending', 'running'))
+				OR EXISTS (SELECT 1 FROM upload_jobs j WHERE j.file_id = f.id AND j.status = 'running') THEN 'translating'
+			WHEN EXISTS (SELECT 1 FROM upload_jobs j WHERE j.file_id = f.id AND j.status = 'queued') THEN 'pending'
+			ELSE 'completed' END`},
+	}}
+}
+
+// planSearchIndex adds the full-text search vector over report titles,
+// descriptions and translated content, kept current by Postgres as a
+// generated column, and the report embeddings table. Embeddings need the
+// pgvector extension; without it the server still starts and similarity
+// search stays disabled.
+func planSearchIndex(db *gorm.DB) []MigrationStep {
+	var steps []MigrationStep
+	migrator := db.Migrator()
+	if !migrator.HasColumn(&models.Report{}, "search_vector") {
+		steps = append(steps, MigrationStep{
+			Phase: PhaseExpand,
+			Kind:  StepAddColumn,
+			Table: "reports",
+			Name:  "search_vector",
+			Statements: []string{`ALTER TABLE reports ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+			setweight(to_tsvector('english', coalesce(description, '')), 'B') ||
+			setweight(to_tsvector('english', coalesce(content->>'translation', '')), 'C')
+		) STORED`},
+		})
+	}
+	if !migrator.HasIndex(&models.Report{}, "idx_reports_search_vector") {
+		steps = append(steps, MigrationStep{
+			Phase:      PhaseExpand,
+			Kind:       StepCreateIndex,
+			Table:      "reports",
+			Name:       "idx_reports_search_vector",
+			Statements: []string{`CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_reports_search_vector ON reports USING GIN (search_vector)`},
+			Concurrent: true,
+		})
+	}
+	if !migrator.HasTable("report_embeddings") {
+		steps = append(steps, MigrationStep{
+			Phase: PhaseExpand,
+			Kind:  StepCustom,
+			Table: "report_embeddings",
+			Statements: []string{
+				`CREATE EXTENSION IF NOT EXISTS vector`,
+				`CREATE TABLE IF NOT EXISTS report_embeddings (
+			report_id bigint PRIMARY KEY REFERENCES reports(id) ON DELETE CASCADE,
+			user_id bigint NOT NULL,
+			model varchar(100) NOT NULL,
+			digest varchar(32) NOT NULL,
+			embedding vector NOT NULL,
+			updated_at timestamptz NOT NULL DEFAULT now()
+		)`,
+				`CREATE INDEX IF NOT EXISTS idx_report_embeddings_user_model ON report_embeddings (user_id, model)`,
+			},
+			Optional: true,
+		})
+	}
+	return steps
+}
+
+// ApplyMigrations runs the planned expand steps, and the contract steps too
+// with opts.Contract, and returns the steps applied. Each statement waits at
+// most opts.LockTimeout for its locks; steps abandoned on a lock timeout are
+// retried with backoff. Only one instance migrates at a time.
+func ApplyMigrations(db *gorm.DB, opts MigrationOptions) ([]MigrationStep, error) {
+	var applied []MigrationStep
+	err := db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+			return fmt.Errorf("failed to take the migration lock: %w", err)
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey)
+
+		// Plan under the lock; another instance may have just migrated
+		steps, err := PlanMigrations(db)
+		if err != nil {
+			return err
+		}
+
+		if err := conn.Exec(fmt.Sprintf("SET lock_timeout = %d", opts.LockTimeout.Milliseconds())).Error; err != nil {
+			return err
+		}
+		defer conn.Exec("RESET lock_timeout")
+
+		for _, step := range steps {
+			if step.Phase == PhaseContract && !opts.Contract {
+				continue
+			}
+			if err := applyStep(conn, step, opts.Retries); err != nil {
+				if step.Optional {
+					log.Printf("Skipped optional migration %s: %v", step, err)
+					continue
+				}
+				return fmt.Errorf("migration %s failed: %w", step, err)
+			}
+			applied = append(applied, step)
+		}
+		return nil
+	})
+
+	VectorSearch = db.Migrator().HasTable("report_embeddings")
+	return applied, err
+}
+
+// applyStep runs one step, in a transaction unless it is concurrent
+func applyStep(conn *gorm.DB, step MigrationStep, retries int) error {
+	for attempt := 0; ; attempt++ {
+		var err error
+		if step.Concurrent {
+			for _, statement := range step.Statements {
+				if err = conn.Exec(statement).Error; err != nil {
+					break
+				}
+			}
+		} else {
+			err = conn.Transaction(func(tx *gorm.DB) error {
+				for _, statement := range step.Statements {
+					if err := tx.Exec(statement).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+		if err == nil || !isLockTimeout(err) || attempt >= retries {
+			return err
+		}
+
+		// An interrupted concurrent build leaves an invalid index behind
+		if step.Kind == StepCreateIndex && step.Concurrent {
+			if err := conn.Exec(fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %q", step.Name)).Error; err != nil {
+				return err
+			}
+		}
+		backoff := time.Second << attempt
+		log.Printf("Migration %s timed out waiting for a lock; retrying in %s", step, backoff)
+		time.Sleep(backoff)
+	}
+}
+
+// isLockTimeout reports whether err is Postgres abandoning a lock wait
+func isLockTimeout(err error) bool {
+	var pgErr interface{ SQLState() string }
+	return errors.As(err, &pgErr) && pgErr.SQLState() == lockNotAvailable
+}
@@ -0,0 +1,13738 @@
+// Package v1 Code generated by swaggo/swag. DO NOT EDIT
+package v1
+
+import "github.com/swaggo/swag"
+
+const docTemplatev1 = `{
+    "schemes": {{ marshal .Schemes }},
+    "swagger": "2.0",
+    "info": {
+        "description": "{{escape .Description}}",
+        "title": "{{.Title}}",
+        "contact": {},
+        "version": "{{.Version}}"
+    },
+    "host": "{{.Host}}",
+    "basePath": "{{.BasePath}}",
+    "paths": {
+        "/admin/analytics/logins": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns distinct users signed in over the last day, 7 days and 30 days (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get login analytics",
+                "responses": {
+                    "200": {
+                        "description": "Login analytics",
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginSummary"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/audit-logs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the most recent audit entries, e.g. ML feedback exports and service key changes (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List audit logs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only entries for this action, e.g. ml_export.feedback",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of entries (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Audit entries",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AuditLogsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/billing/metrics": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reports active subscriptions by plan and source (stripe, app_store, google_play or organization), monthly recurring revenue by currency, new and churned subscriptions and failed payments this month. Computed from the stored subscriptions and Stripe webhook events without calling Stripe. MRR covers active and past-due Stripe subscriptions before discounts and tax, not trials or app store subscriptions (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get billing metrics",
+                "responses": {
+                    "200": {
+                        "description": "Billing metrics",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.BillingMetrics"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/broadcasts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the 100 most recent broadcasts with their status and delivery stats (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List broadcasts",
+                "responses": {
+                    "200": {
+                        "description": "Broadcasts",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BroadcastsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sends an in-app notification to all users, a cohort, or an organization's members, optionally also by email and push. Broadcasts are sent immediately or at scheduled_at (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Send a broadcast",
+                "parameters": [
+                    {
+                        "description": "Broadcast details",
+                        "name": "broadcast",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateBroadcastRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Scheduled broadcast",
+                        "schema": {
+                            "$ref": "#/definitions/models.Broadcast"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/broadcasts/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a broadcast with its delivery stats: recipients, in-app deliveries, emails queued, pushes sent or failed, and how many recipients have read it (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get broadcast stats",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Broadcast ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Broadcast and stats",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BroadcastResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Broadcast not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancels a broadcast that has not started sending (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Cancel a broadcast",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Broadcast ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Broadcast canceled",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Broadcast not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - Broadcast already sent",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/config": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the active non-secret settings (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get runtime settings",
+                "responses": {
+                    "200": {
+                        "description": "Active settings",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ConfigResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/config/reload": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Re-reads tunable settings from the env file and environment, validates them and applies them (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reload runtime settings",
+                "responses": {
+                    "200": {
+                        "description": "Config reloaded",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ConfigReloadResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid configuration, previous settings kept",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/migrations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the migration mode, the lock guardrails and the planned expand and contract steps with their SQL, without applying anything (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List pending migrations",
+                "responses": {
+                    "200": {
+                        "description": "Pending migrations",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MigrationsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/orgs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists all organizations (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List organizations",
+                "responses": {
+                    "200": {
+                        "description": "Organizations",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OrganizationsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates an organization that users can be assigned to (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create an organization",
+                "parameters": [
+                    {
+                        "description": "Organization details",
+                        "name": "organization",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created organization",
+                        "schema": {
+                            "$ref": "#/definitions/models.Organization"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/orgs/{id}/branding": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the display name, colors, footer text and logo details applied to the PDF exports and emails of an organization's members, or null if it uses the ThinkInk defaults (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get an organization's branding",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Branding",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OrgBrandingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sets the display name, #RRGGBB primary and accent colors and footer text used in the PDF exports and emails of an organization's members. The logo is kept; upload it separately (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set an organization's branding",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Branding",
+                        "name": "branding",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OrgBrandingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Branding",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OrgBrandingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes the branding and deletes the logo so the organization's members get the ThinkInk defaults again (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Remove an organization's branding",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Branding removed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/orgs/{id}/branding/logo": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stores a PNG or JPEG logo (max 512KB and 2000x2000 pixels) drawn at the top of the organization's PDF exports and emails, replacing any previous logo (admin only)",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Upload an organization's logo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "PNG or JPEG logo",
+                        "name": "logo",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Branding",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OrgBrandingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Missing or invalid logo",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes the organization's logo and keeps the rest of its branding (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Remove an organization's logo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Logo removed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization or logo not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/orgs/{id}/moderation": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns how members' translations are moderated, or a null policy if the defaults apply: the self_harm_keywords moderator, with in-app, push and email alerts to linked clinicians (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get an organization's moderation policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Moderation policy",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OrgModerationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Chooses whether and how members' new translations are moderated: the moderators to run, extra self-harm keywords, and whether clinicians linked to the member are alerted when a report is flagged, optionally by email (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set an organization's moderation policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Moderation policy",
+                        "name": "policy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OrgModerationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Moderation policy",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OrgModerationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Unknown moderator or invalid keyword",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes the organization's moderation policy so its members get the defaults (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Remove an organization's moderation policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Moderation policy removed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/orgs/{id}/rate-plan": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the custom limits configured for an organization, or null if it uses plan defaults (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get an organization's rate plan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rate plan",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OrgRatePlanResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sets custom rate limits, quotas and lapse data policy that override plan defaults for all members of an organization (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set an organization's rate plan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Custom limits",
+                        "name": "plan",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OrgRatePlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rate plan",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OrgRatePlanResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes custom limits so members fall back to their plan defaults (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Remove an organization's rate plan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rate plan removed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/orgs/{id}/retention-report": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a point-in-time audit snapshot of an organization's data: the effective lapse and purge policies, stored reports and report revisions by age, raw recording status of members, and reports and recordings pending deletion or archival. Controls this deployment does not record, such as legal holds and data residency, are listed under not_tracked (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get an organization's retention compliance report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Retention report",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.RetentionReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/orgs/{id}/text-processing": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Chooses the post-processors applied, in order, to new translations of organization members who have not chosen their own. Null restores the defaults (punctuation and casing); an empty list turns post-processing off. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set an organization's translation post-processing",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Post-processors",
+                        "name": "processing",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TextProcessingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated organization",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OrganizationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Unknown processor",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/orgs/{id}/widgets": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists an organization's embeddable widgets, including revoked ones, without their tokens (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List an organization's widgets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Widgets",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WidgetsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issues a widget token an organization's patient portal sends in X-Widget-Token to show read-only shared reports of its members from the allowed origins. Cross-origin access is granted to GET /shared/{token} only. The token is returned only once; only its hash is stored (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Issue an embeddable widget",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Widget details",
+                        "name": "widget",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateWidgetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Issued widget and token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateWidgetResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid name or origins",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/orgs/{id}/widgets/{widgetId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes a widget so its token stops working and its origins lose cross-origin access immediately (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Revoke a widget",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Widget ID",
+                        "name": "widgetId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Widget revoked",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization or widget not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/report-templates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists every report template by name, including archived ones (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List all report templates",
+                "responses": {
+                    "200": {
+                        "description": "Report templates",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportTemplatesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Defines a template for structured clinical notes: named sections of fields, each of type text, number, boolean or choice (with options) and optionally required. Reports uploaded with the template carry the notes in content.notes and are validated against it (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create a report template",
+                "parameters": [
+                    {
+                        "description": "Template definition",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created template",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReportTemplate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid sections",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - Name already used",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/report-templates/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces the name, description and sections of a report template. New and updated reports are validated against the new definition; stored reports keep their notes as they are (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Update a report template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Template definition",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated template",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReportTemplate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID or sections",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report template not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - Name already used",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Archives a report template so it can no longer be chosen for new reports. Reports made with it keep it and it stays readable via GET /report-templates/{id} (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Archive a report template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Archived template",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReportTemplate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report template not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/service-credentials": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists issued service credentials without their keys (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List service keys",
+                "responses": {
+                    "200": {
+                        "description": "Service credentials",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ServiceCredentialsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issues an API key for a backend service, e.g. the ML team's feedback export (scope ml_export). The key is returned only once; only its hash is stored (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Issue a service key",
+                "parameters": [
+                    {
+                        "description": "Credential details",
+                        "name": "credential",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateServiceCredentialRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Issued credential and key",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateServiceCredentialResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/service-credentials/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes a service credential so its key stops working immediately (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Revoke a service key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Credential ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Credential revoked",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Credential not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/stripe-events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists received Stripe webhook events, newest first, optionally only those in one state: pending, processed or dead (parked after failing permanently) (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List Stripe webhook events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pending, processed or dead",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of events (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stripe events",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.StripeEventsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid state or limit",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/stripe-events/{id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Puts a dead Stripe webhook event back in the queue with a fresh attempt budget (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Retry a Stripe webhook event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stripe event record ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Requeued event",
+                        "schema": {
+                            "$ref": "#/definitions/models.StripeEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Event not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - Event is not dead",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates accounts from a CSV with a header row. Required columns: name, email, date_of_birth (YYYY-MM-DD). Optional: mobile, country_code, address, city, country, postal_code, timezone, locale. Each user is emailed a single-use link, valid for 7 days, to choose their password; the account cannot use the API until they do. Rows are created in transactions of 100; failing rows are skipped and reported (admin only)",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Bulk import users",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Organization to assign the imported users to",
+                        "name": "organization_id",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Import result with per-row errors",
+                        "schema": {
+                            "$ref": "#/definitions/userimport.Result"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Missing or malformed CSV",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - Not enough free seats in the organization for the imported users",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/inactive": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists users with no sign-in in the last N days (default INACTIVE_USER_DAYS), longest inactive first or in the given sort order (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List inactive users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Inactivity threshold in days",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated field:direction pairs, e.g. created_at:desc; fields are created_at, last_login, name and email, directions asc (default) or desc",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Inactive users",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InactiveUsersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid days or sort",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/deactivate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Soft-deletes a user account, preserving its data (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Deactivate a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Account deactivated",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/logins": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the user's most recent sign-ins with IP and user agent (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get a user's login history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of entries (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login history",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LoginHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/organization": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Assigns a user to an organization, or removes them with a null organization_id, optionally setting their role within it; organization admins can view its audit log (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Assign a user to an organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Organization assignment",
+                        "name": "organization",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SetUserOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Organization updated",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - User or organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - All of the organization's seats are taken",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/reactivate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Restores a deactivated user account so it can sign in again (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reactivate a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reactivated user",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - User not found or not deactivated",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/branding/{id}/logo": {
+            "get": {
+                "description": "Serves the organization's logo image. It is public so that email clients can load it.",
+                "produces": [
+                    "image/png",
+                    "image/jpeg"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Get an organization's logo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Logo",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Logo not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/check-auth": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Check if the current token is valid and not blacklisted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Validate authentication token",
+                "responses": {
+                    "200": {
+                        "description": "User authentication status",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AuthResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or blacklisted token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/demo/reports/{id}": {
+            "get": {
+                "description": "Returns a demo report with EEG content, tags and metadata in the shape of GET /reports/{id}. Only available outside production.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "demo"
+                ],
+                "summary": "Get a demo report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Demo report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Demo report",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/demo/subscriptions": {
+            "get": {
+                "description": "Returns the subscription of every demo user keyed by user ID: active, trialing, cancelling at period end, past due, canceled, expired and none. Only available outside production.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "demo"
+                ],
+                "summary": "List demo subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "Demo subscriptions",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.DemoSubscriptionsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/demo/users": {
+            "get": {
+                "description": "Lists the deterministic fake users of the demo dataset, covering every subscription state. Only available outside production; nothing is read from the database.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "demo"
+                ],
+                "summary": "List demo users",
+                "responses": {
+                    "200": {
+                        "description": "Demo users",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.DemoUsersResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/demo/users/{id}": {
+            "get": {
+                "description": "Returns a demo user in the shape of GET /user/{id}. Only available outside production.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "demo"
+                ],
+                "summary": "Get a demo user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Demo user ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Demo user",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/demo/users/{id}/reports": {
+            "get": {
+                "description": "Returns a page of a demo user's unarchived reports, newest first, in the shape of GET /reports. Only available outside production.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "demo"
+                ],
+                "summary": "List a demo user's reports",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Demo user ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Page size (max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of reports to skip (at most 10000)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Demo reports",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID or paging",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/demo/users/{id}/subscription": {
+            "get": {
+                "description": "Returns a demo user's subscription in the shape of GET /payment/subscription. Only available outside production.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "demo"
+                ],
+                "summary": "Get a demo user's subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Demo user ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Demo subscription",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/device-tokens": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issues a token for a headset to upload recordings with. Device tokens are valid for 30 days but may only call POST /upload, so a leaked headset token cannot read reports or change the account.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Issue a device token",
+                "parameters": [
+                    {
+                        "description": "Headset to issue the token to",
+                        "name": "device",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.DeviceTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Device token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.DeviceTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Device tokens cannot issue tokens",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/email/webhook": {
+            "post": {
+                "description": "Adds hard-bounced and complaining addresses to the suppression list. Soft bounces are ignored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Process email provider events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Shared webhook secret",
+                        "name": "X-Webhook-Secret",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Email event",
+                        "name": "event",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.EmailEventRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Event processed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid webhook secret",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/entitlements": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the plan the authenticated user's limits are based on, its quotas and the capabilities it includes (pdf_export, sharing, ml_api), so clients can hide what the plan does not allow. Plans are mapped to capabilities by PLAN_CAPABILITIES.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "usage"
+                ],
+                "summary": "Get entitlements",
+                "responses": {
+                    "200": {
+                        "description": "Entitlements",
+                        "schema": {
+                            "$ref": "#/definitions/entitlements.Entitlements"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Streams Server-Sent Events as the authenticated user's uploaded files move through processing, so clients need not poll GET /files/{id}/status. Each event is named after the state reached: uploaded, translating, report_ready or failed; its data is a JSON object with file_id, job_id, state, report_id once the report is ready, error for failed files and the time at which the state was reached. Pass file_id to only receive events of one file. Idle streams receive a comment every 15 seconds. Events are best effort: after reconnecting, check GET /files/{id}/status for anything missed.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Stream file processing events",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only stream events of this file",
+                        "name": "file_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Event stream",
+                        "schema": {
+                            "$ref": "#/definitions/models.FileEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid file ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Event streaming unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exports": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the background report exports requested by the authenticated user, newest first, with their status and progress. Completed exports carry a signed download_url; exports are removed once they expire after REPORT_EXPORT_TTL.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "List report exports",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Page size (max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of exports to skip (at most 10000); ignored when cursor is set",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page's pagination.next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exports",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportExportsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid pagination",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exports/{id}/download": {
+            "get": {
+                "description": "Downloads the file of a completed background report export through the signed download_url of its status. No authentication is needed; links work for 15 minutes.",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/pdf",
+                    "application/zip",
+                    "application/fhir+json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Download a report export by signed link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Export ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Link expiry as a Unix timestamp",
+                        "name": "expires",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Link signature",
+                        "name": "signature",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exported reports",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Invalid or expired link",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Export not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone - Export has expired",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/files": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the signal files the authenticated user uploaded with their size, upload time, status and the ID of the report made from them, newest first or in the given sort order, optionally filtered by status and upload date. A file is processing while its report's translation is queued or running, failed when the translation failed, processed once its report is ready, unlinked after its report was deleted and quarantined when the malware scan found it infected. Each file also carries its persisted processing state: pending while it waits for a worker, scanning while the recording is decoded and checked, translating while it is with the ML service, then completed or failed, with state_changed_at. Results are paginated by limit/offset or, unless sorted, by the cursor returned as pagination.next_cursor.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "List uploaded files",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of files to skip (at most 10000); cannot be combined with cursor",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from a previous page's pagination.next_cursor; cannot be combined with sort",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated field:direction pairs, e.g. file_size:desc; fields are uploaded_at, filename and file_size, directions asc (default) or desc",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "processing",
+                            "processed",
+                            "failed",
+                            "unlinked",
+                            "quarantined"
+                        ],
+                        "type": "string",
+                        "description": "Only files with this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "scanning",
+                            "translating",
+                            "completed",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Only files in this processing state",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only files uploaded on or after this date (YYYY-MM-DD or RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only files uploaded before this timestamp, or on or before this date (YYYY-MM-DD or RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of uploaded files",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.FilesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid sort, pagination or filter parameters",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes a signal file the authenticated user uploaded: the original is removed from storage and the file disappears from listings. With cascade=true the report made from the file is deleted too, and purged after the retention window like DELETE /reports/{id}; otherwise the report is kept without its source file. Files whose report has an active share link cannot be deleted until the link is revoked.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Delete an uploaded file",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "File ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Also delete the report made from the file",
+                        "name": "cascade",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "File deleted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.DeleteFileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID or cascade",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - The report made from the file is shared",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/{id}/preview": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a downsampled waveform of the recording in a file the authenticated user uploaded, made when the file is processed, for drawing without loading the samples. Each channel's series holds up to 200 points; each point covers samples_per_point consecutive samples and holds their lowest (min) and highest (max) value in µV, so spikes survive the downsampling. Rows the mask marks as padding are left out. Files not processed yet, whose processing failed before the recording was read, or processed before previews were made have none.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Get a file's waveform preview",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "File ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Waveform preview",
+                        "schema": {
+                            "$ref": "#/definitions/models.FilePreview"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "File not found, or it has no preview",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/{id}/retranslate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Runs the ML translation of a file the authenticated user uploaded again, for example after a model upgrade, without uploading it again. The report made from the file gets the new translation; the translation it replaces is kept in the report's revision history (GET /reports/{id}/revisions) with change translation and can be restored. A failed translation leaves the report as it was. Follow progress with GET /files/{id}/status. Counts against the monthly translation quota once it succeeds.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Re-translate a file",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "File ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Translation queued",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RetranslateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required - Translation quota exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/plans.LimitError"
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - The file has no report yet, its recording was removed, or it is already being translated",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable - Translation service is down (see Retry-After), or the recording could not be read from cold storage",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/{id}/status": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reports how far a file the authenticated user uploaded got: its status as in GET /files, its processing state (pending, scanning, translating, completed or failed) and when it got there, the class and message of the error when parsing, checking or translating the recording failed (a file can be completed without a translation and still carry the translation error), the report made from it once there is one, and its upload job with the job's status (queued, running, completed or failed), the error of a failed job and warnings about the recording. Poll it after POST /upload until the job completes or fails; the user is also notified either way.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Get a file's processing status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "File ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "File status",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.FileStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/forgot-password": {
+            "post": {
+                "description": "Send a password reset link to the user's email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Request password reset",
+                "parameters": [
+                    {
+                        "description": "User email",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ForgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password reset email sent",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ForgotPasswordResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/graphql": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Runs a GraphQL query over the authenticated user's profile, reports, tags and files, returning only the selected fields. Report tags, source files and decrypted text are only loaded when selected. Lists are connections paged with first (at most 100) and the after cursor from pageInfo.endCursor. The schema is in graph/schema.graphqls; introspection is disabled in production. GET takes the query in the query parameter.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphql"
+                ],
+                "summary": "Query the GraphQL API",
+                "parameters": [
+                    {
+                        "description": "GraphQL query",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GraphQLRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Query result; field errors are listed in errors",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GraphQLResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid or too complex query",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GraphQLResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/links": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists links where the authenticated user is the report owner or the viewer",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "links"
+                ],
+                "summary": "List account links",
+                "responses": {
+                    "200": {
+                        "description": "Account links",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LinksResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sends an invitation granting view and download access to the authenticated user's reports once accepted, optionally until access_expires_at",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "links"
+                ],
+                "summary": "Invite a caregiver or clinician",
+                "parameters": [
+                    {
+                        "description": "Invitation details",
+                        "name": "invitation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Invitation sent",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LinkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/links/accept": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Accepts a caregiver or clinician invitation addressed to the authenticated user's email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "links"
+                ],
+                "summary": "Accept an invitation",
+                "parameters": [
+                    {
+                        "description": "Invitation token",
+                        "name": "invitation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AcceptLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Invitation accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LinkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid, expired or mismatched invitation",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/links/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes a pending or accepted link. Either the owner or the viewer may revoke.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "links"
+                ],
+                "summary": "Revoke an account link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Link ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Link revoked",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Link not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/links/{id}/expiry": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sets or clears the date the viewer's access ends. Only the report owner may change it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "links"
+                ],
+                "summary": "Change link access expiry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Link ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New access expiry",
+                        "name": "expiry",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UpdateLinkExpiryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Link updated",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LinkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Link not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Logs out a user and invalidates the session token",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "User logout",
+                "responses": {
+                    "200": {
+                        "description": "Logged out successfully",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/match": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates the matching scale for a report that belongs to the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Update report matching scale",
+                "parameters": [
+                    {
+                        "description": "Match report request",
+                        "name": "match",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MatchReportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report matching scale updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MatchReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ml/feedback-export": {
+            "get": {
+                "description": "Streams anonymized (EEG reference, translation, user correction) pairs as JSON lines for model training. Users and reports are identified only by stable pseudonyms, report titles are dropped, dates are coarsened to the month, and email addresses, links and phone-like numbers are scrubbed from text. Reports of users who opted in to report text encryption are never exported. Requires an ml_export service key in X-Service-Key; every export is audited.",
+                "produces": [
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "ml"
+                ],
+                "summary": "Export model feedback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only reports created on or after this date (YYYY-MM-DD or RFC 3339)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only reports created before this date (YYYY-MM-DD or RFC 3339)",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of records (default and max 100000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Inline the EEG signal (eeg, mask, impedance) in each record",
+                        "name": "include_eeg",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ML export service key",
+                        "name": "X-Service-Key",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "One JSON record per line",
+                        "schema": {
+                            "$ref": "#/definitions/mlexport.Record"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Missing service key",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Invalid service key",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Export is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the authenticated user's 50 most recent in-app notifications with the unread count",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "List notifications",
+                "responses": {
+                    "200": {
+                        "description": "Notifications",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.NotificationsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/{id}/read": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Marks one of the authenticated user's notifications as read",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Mark a notification as read",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Notification ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Notification marked as read",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Notification not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/onboarding": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the onboarding checklist (email verified, first upload, first report, subscription) and the next step to complete",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "onboarding"
+                ],
+                "summary": "Get onboarding progress",
+                "responses": {
+                    "200": {
+                        "description": "Onboarding progress",
+                        "schema": {
+                            "$ref": "#/definitions/models.OnboardingStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/openapi/{version}": {
+            "get": {
+                "description": "Returns the OpenAPI (Swagger 2.0) document generated from the handler annotations for the given version, e.g. /openapi/v1.json, for client code generation",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "docs"
+                ],
+                "summary": "Get the OpenAPI document for an API version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API version document, e.g. v1.json",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OpenAPI document",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown API version",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{id}/audit-logs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists audit entries about an organization and the actions of its members, newest first: membership and role changes, rate plan, moderation, branding and text processing changes, and members' report deletions, shares and exports. Available to the organization's admins and to platform admins; platform-wide entries are only listed by GET /admin/audit-logs.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "List an organization's audit log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries by this actor, e.g. user:42",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries of this event type, e.g. report.delete",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries about this resource, e.g. report:12",
+                        "name": "resource",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries on or after this date (YYYY-MM-DD or RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries before this timestamp, or on or before this date (YYYY-MM-DD or RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of entries to skip (at most 10000)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from a previous page's next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Audit entries",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OrgAuditLogsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID, filter or page",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Organization admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{id}/audit-logs/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Streams the organization's audit entries matching the same filters as GET /orgs/{id}/audit-logs as CSV, newest first, up to 100000 rows. Columns are id, created_at, actor, action, resource, outcome, ip and details (JSON). Every export is itself audited.",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Export an organization's audit log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries by this actor, e.g. user:42",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries of this event type, e.g. report.delete",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries about this resource, e.g. report:12",
+                        "name": "resource",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries on or after this date (YYYY-MM-DD or RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries before this timestamp, or on or before this date (YYYY-MM-DD or RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Audit entries as CSV",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID or filter",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Organization admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{id}/subscription": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the organization's seat-based subscription, or null if it has none, and how many seats its members take (organization admins and admins)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Get an organization's seat subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Seat subscription",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OrgSubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Organization admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{id}/subscription/checkout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a Stripe checkout session for a subscription to the plan with the given number of seats, billed to the organization. Members hold the plan while the subscription is active; there must be a seat for every current member. Currency and promo_code work as for personal subscriptions (organization admins and admins)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Buy seats for an organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Checkout session details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateOrgCheckoutRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Checkout session created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CheckoutResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Organization admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - The organization already has a subscription, or fewer seats than members",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Plan catalog unavailable to check the requested currency",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{id}/subscription/seats": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sets the quantity of the organization's subscription in Stripe, prorating the change for the rest of the billing period. Seats cannot drop below the number of members (organization admins and admins)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Change an organization's seats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Seats",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SetOrgSeatsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Seats updated",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OrgSubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Organization admin access required",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - Organization or subscription not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - Fewer seats than members",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/checkout/one-time": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a Stripe checkout session for one-time payments. A promo_code is checked against Stripe and applied up front; without one the customer can enter a code on the checkout page.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "summary": "Create a one-time payment checkout session",
+                "parameters": [
+                    {
+                        "description": "One-time checkout details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateOneTimeCheckoutRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Checkout session created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CheckoutResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/checkout/subscription": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a Stripe checkout session for subscription payments. A promo_code is checked against Stripe and applied up front; without one the customer can enter a code on the checkout page. The plan is charged in the requested currency, or else the currency of the user's country when the plan offers it, switching to the plan's price in that currency if it has a separate one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "summary": "Create a subscription checkout session",
+                "parameters": [
+                    {
+                        "description": "Checkout session details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateCheckoutSessionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Checkout session created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CheckoutResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Plan catalog unavailable to check the requested currency",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/iap/apple": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Validates an App Store receipt with Apple and grants the subscription it holds, as a Stripe subscription would. The store's product ID serves as the plan, so PLAN_QUOTAS can be keyed by it. Validating the same subscription again, e.g. after a renewal or restore, refreshes it. A subscription already validated for another account is rejected, and one validated while the user holds another active subscription is recorded without replacing it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "summary": "Validate an App Store subscription",
+                "parameters": [
+                    {
+                        "description": "App Store receipt",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AppleReceiptRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Subscription granted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid receipt, or a sandbox purchase in production",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Purchase belongs to another account, or the user already holds another subscription",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "App Store could not validate the receipt",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "App Store purchases are not configured",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/iap/google": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Validates a Google Play subscription purchase token with Google, acknowledging new purchases, and grants the subscription as a Stripe subscription would. The store's product ID serves as the plan, so PLAN_QUOTAS can be keyed by it. Validating the same purchase again refreshes it. A purchase already validated for another account is rejected, and one validated while the user holds another active subscription is recorded without replacing it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "summary": "Validate a Google Play subscription",
+                "parameters": [
+                    {
+                        "description": "Google Play purchase",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GooglePurchaseRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Subscription granted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid purchase token, or a test purchase in production",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Purchase belongs to another account, or the user already holds another subscription",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Google Play could not validate the purchase",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Google Play purchases are not configured",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/invoices/{id}/pdf": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Streams the PDF of one of the user's Stripe invoices, or of an invoice of an organization the user administers, without exposing Stripe URLs. Invoices of other customers are reported as not found.",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "summary": "Download an invoice PDF",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stripe invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Invoice PDF",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Invoice not found, or it has no PDF yet",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "PDF could not be downloaded from Stripe",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/plans": {
+            "get": {
+                "description": "Lists the active products and prices that can be purchased, with their features, currencies, billing interval, plan quotas and capabilities. With a currency, only plans available in it are listed, priced in it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "summary": "List plans",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 currency code, e.g. eur",
+                        "name": "currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Plan catalog",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PlansResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid currency",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Payment provider unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/subscription": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns details about the user's current subscription, including subscriptions bought in the App Store or Google Play as last validated",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "summary": "Get subscription details",
+                "responses": {
+                    "200": {
+                        "description": "Subscription details",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SubscriptionResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/subscription/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancels the user's subscription at the end of the current billing period. Subscriptions bought in the mobile apps are canceled in the App Store or Google Play instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "summary": "Cancel a subscription",
+                "responses": {
+                    "200": {
+                        "description": "Subscription canceled",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CancelSubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Subscription was bought in an app store",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/tax-ids": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the tax IDs, such as EU VAT numbers, shown on the user's invoices, with their verification status",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "summary": "List tax IDs",
+                "responses": {
+                    "200": {
+                        "description": "Tax IDs",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TaxIDsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds a tax ID, such as an EU VAT number, to the user's Stripe customer so it appears on invoices and, with Stripe Tax, enables the reverse charge. Stripe checks the format at once and verifies EU VAT numbers in the background.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "summary": "Add a tax ID",
+                "parameters": [
+                    {
+                        "description": "Tax ID",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateTaxIDRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Tax ID added",
+                        "schema": {
+                            "$ref": "#/definitions/models.CustomerTaxID"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid tax ID type or value",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment/tax-ids/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes a tax ID from the user's Stripe customer; invoices already issued keep it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payment"
+                ],
+                "summary": "Remove a tax ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tax ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tax ID removed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Tax ID not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Readiness check for load balancers and orchestrators. The database is checked live; if it is down the instance is not ready (503). The ML service and payments are reported from their latest probe: if they are down or slow the instance stays ready (200) with status degraded, and uploads fail fast with 503 until the ML service recovers.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "status"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "Ready, possibly degraded",
+                        "schema": {
+                            "$ref": "#/definitions/health.Readiness"
+                        }
+                    },
+                    "503": {
+                        "description": "Not ready",
+                        "schema": {
+                            "$ref": "#/definitions/health.Readiness"
+                        }
+                    }
+                }
+            }
+        },
+        "/refresh-token": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Generate a new JWT token for the same audience using a valid existing token. Device tokens cannot be refreshed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Refresh authentication token",
+                "responses": {
+                    "200": {
+                        "description": "Token refreshed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TokenResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or blacklisted token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/report-templates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the report templates that can be chosen when uploading a recording, by name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "report-templates"
+                ],
+                "summary": "List report templates",
+                "responses": {
+                    "200": {
+                        "description": "Report templates",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportTemplatesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/report-templates/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a report template with its sections and fields, including archived templates that existing reports still follow",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "report-templates"
+                ],
+                "summary": "Get a report template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report template",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReportTemplate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report template not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves the reports belonging to the authenticated user, or to a user who granted them access via an account link, newest first or in the given sort order, optionally filtered by date range, matching scale and text. Results are paginated by limit/offset or, unless sorted, by the cursor returned as pagination.next_cursor.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get all user reports",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Owner of the reports (defaults to the authenticated user)",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of reports to skip (at most 10000); cannot be combined with cursor",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from a previous page's pagination.next_cursor; cannot be combined with sort",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated field:direction pairs, e.g. matching_scale:desc; fields are created_at, updated_at, matching_scale, title and size_bytes, directions asc (default) or desc",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only reports created on or after this date (YYYY-MM-DD or RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only reports created before this timestamp, or on or before this date (YYYY-MM-DD or RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum matching scale",
+                        "name": "min_scale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum matching scale",
+                        "name": "max_scale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Case-insensitive text to find in the title or description",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only reports carrying all of these tags; repeat for several",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "false (default) hides archived reports, true lists only archived reports, all lists both",
+                        "name": "archived",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "true lists only reports flagged by moderation",
+                        "name": "flagged",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of user reports",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid user ID, filter, sort or pagination parameters",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - No access to this user's reports",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/batch": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes, tags or sets the matching scale of up to 100 reports in one transaction and returns a result per report, in request order. Reports that cannot be acted on (not found, no access, too many tags) are reported as failed and the rest are applied; a database error rolls back the whole batch. Every action requires owning the report.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Act on several reports",
+                "parameters": [
+                    {
+                        "description": "Batch action",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BatchReportsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Per-report results",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BatchReportsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error - Nothing was changed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Exports the reports of the authenticated user, or of a user who granted them access via an account link, oldest first: as CSV or XLSX with the selected columns, as one PDF with a page per report, as a ZIP with each report as JSON and PDF, or as a FHIR R4 bundle. The same filters as GET /reports apply. Small exports are streamed directly; exports of more than REPORT_EXPORT_SYNC_ROWS reports, or any export with async=true, are generated in the background and answered with 202 and a status URL to poll.",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/pdf",
+                    "application/zip",
+                    "application/fhir+json",
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Export reports",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "xlsx",
+                            "pdf",
+                            "zip",
+                            "fhir"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Export format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated columns of CSV and XLSX exports (default all): id, title, description, correction, matching_scale, tags, metadata, translation_status, size_bytes, created_at, updated_at, archived_at",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Generate the export in the background regardless of its size",
+                        "name": "async",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Owner of the reports (defaults to the authenticated user)",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only reports created on or after this date (YYYY-MM-DD or RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only reports created before this timestamp, or on or before this date (YYYY-MM-DD or RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum matching scale",
+                        "name": "min_scale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum matching scale",
+                        "name": "max_scale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Case-insensitive text to find in the title or description",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only reports carrying all of these tags; repeat for several",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "false (default) hides archived reports, true exports only archived reports, all exports both",
+                        "name": "archived",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "true exports only reports flagged by moderation",
+                        "name": "flagged",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exported reports",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "202": {
+                        "description": "Export queued for background generation",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportExportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid format, columns, user ID or filter",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - No access to this user's reports",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/exports/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the status and progress of a background report export requested by the authenticated user. Once completed, download_url is a signed link to the file that works for 15 minutes; fetch the status again for a fresh link until export.expires_at.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get a report export",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Export ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Export status",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportExportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Export not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/exports/{id}/download": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Downloads the file of a completed background report export requested by the authenticated user",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/pdf",
+                    "application/zip",
+                    "application/fhir+json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Download a report export",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Export ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exported reports",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Export not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - Export is not completed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone - Export has expired",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/matching-scale/timeseries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Aggregates the matching scale of the reports belonging to the authenticated user, or to a user who granted them access via an account link, per day or week in the owner's time zone, oldest first: the number of reports and the average, lowest and highest scale. Periods without reports are left out. Takes the same filters as listing reports.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get the matching scale over time",
+                "parameters": [
+                    {
+                        "enum": [
+                            "day",
+                            "week"
+                        ],
+                        "type": "string",
+                        "default": "day",
+                        "description": "Length of each period",
+                        "name": "granularity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Owner of the reports (defaults to the authenticated user)",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only reports created on or after this date (YYYY-MM-DD or RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only reports created before this timestamp, or on or before this date (YYYY-MM-DD or RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum matching scale",
+                        "name": "min_scale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum matching scale",
+                        "name": "max_scale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Case-insensitive text to find in the title or description",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only reports carrying all of these tags; repeat for several",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "false (default) leaves out archived reports, true uses only archived reports, all uses both",
+                        "name": "archived",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "true uses only reports flagged by moderation",
+                        "name": "flagged",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Matching scale per period",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MatchingScaleSeriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid user ID, granularity or filter",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - No access to this user's reports",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Full-text search over report titles, descriptions and translated content, ranked by relevance with matching passages highlighted in \u003cmark\u003e tags. Supports quoted phrases, \"or\" and -exclusions. Linked viewers can search the reports they have access to.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Search reports",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search text, e.g. coffee",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Owner of the reports (defaults to the authenticated user)",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of results to skip (at most 10000)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ranked search results",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportSearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Missing query, invalid user ID or pagination parameters",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - No access to this user's reports",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves a report owned by the authenticated user or by a user who granted them access via an account link. Reports created from an upload include the source file's metadata and a download link under source_file.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get a report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report details",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Report content could not be read from cold storage",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes a report owned by the authenticated user. The report disappears immediately and is permanently purged after the retention window (REPORT_PURGE_AFTER_DAYS).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Delete a report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report deleted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates the title, description and/or metadata of a report owned by the authenticated user. Omitted fields are left unchanged; metadata is replaced as a whole (at most 20 entries, keys up to 64 and values up to 500 characters) and an empty object clears it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Update a report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "report",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UpdateReportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated report",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID or fields",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/access-log": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists every read of a report owned by the authenticated user, newest first: who read it (the owner or a linked viewer, with their name and email, or the share link used), when, from which IP address and how (view, list, search, similar, download, export, source_file, revisions or shared_link)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get a report's access log",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Page size (max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of entries to skip (at most 10000); ignored when cursor is set",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page's pagination.next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Access log",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportAccessLogResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID or paging",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/archive": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Archives a report owned by the authenticated user. Archived reports are hidden from GET /reports unless archived=true or archived=all, and remain searchable and viewable by ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Archive a report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report archived",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ArchiveReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/correction": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stores what the authenticated user actually meant, as feedback for improving translations. Owner only; an empty correction removes it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Correct a report's translation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Corrected translation",
+                        "name": "correction",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportCorrectionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated report",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/download": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Downloads a report owned by the authenticated user or by a user who granted them access via an account link. Linked viewers can download but not modify reports.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Download a report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report file",
+                        "schema": {
+                            "$ref": "#/definitions/models.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Report content could not be read from cold storage",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Exports a report owned by the authenticated user or by a user who granted them access via an account link as a formatted PDF for sharing with doctors, with the patient's name and date of birth, the translated text and any correction, the matching scale, tags and timestamps in the patient's time zone. Patients in an organization with branding get its name, logo, colors and footer text. Requires a plan with the pdf_export capability.",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Export a report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pdf"
+                        ],
+                        "type": "string",
+                        "default": "pdf",
+                        "description": "Export format; only pdf is supported",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report document",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID or unsupported format",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Plan does not include PDF export",
+                        "schema": {
+                            "$ref": "#/definitions/entitlements.CapabilityError"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the earlier versions of a report owned by the authenticated user or by a user who granted them access via an account link, newest first. A revision is recorded whenever the matching scale is updated, the title, description or metadata are edited, the report is re-translated or an earlier revision is restored; it holds the values that change replaced.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "List a report's revisions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Page size (max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of revisions to skip (at most 10000); ignored when cursor is set",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page's pagination.next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Revisions",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportRevisionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID or paging",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/revisions/{revisionId}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Puts the title, description, matching scale and metadata of an earlier revision back on a report owned by the authenticated user. The values it replaces are recorded as a new revision, so a restore can itself be undone.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Restore a report revision",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision ID",
+                        "name": "revisionId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Restored report",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report or revision not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/share": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a signed, expiring public link to a read-only view of a report owned by the authenticated user, for sharing with someone without an account. The link can be password protected; it stops working when it expires or is revoked. Requires a plan with the sharing capability.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Share a report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Share options",
+                        "name": "share",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateShareRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Share link created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ShareResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Plan does not include sharing",
+                        "schema": {
+                            "$ref": "#/definitions/entitlements.CapabilityError"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/shares": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the share links of a report owned by the authenticated user, newest first, including expired and revoked ones with their view counts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "List a report's share links",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Share links",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SharesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/shares/{shareId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes a share link of a report owned by the authenticated user; the link stops working immediately",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Revoke a share link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Share ID",
+                        "name": "shareId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Share link revoked",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ShareResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report or share link not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/similar": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the owner's other reports whose translated text is most similar to this report's, most similar first, with a cosine similarity score. Reports are embedded in the background shortly after they are translated or edited; reports of users who encrypt their report text are never embedded.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Find similar reports",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Number of reports to return (max 20)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Similar reports",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SimilarReportsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID or limit",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - Report text is encrypted or not indexed yet",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable - Similarity search is not enabled on this server",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/source-file": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Downloads the original uploaded signal file of a report owned by the authenticated user or by a user who granted them access via an account link",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Download a report's source file",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Source file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found or it has no source file",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone - The source file was archived or purged by the retention policy",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/tags": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Attaches a tag to a report, creating it in the report owner's tag set if needed. Names are lowercased and may contain letters, digits, spaces, '-' and '_' (at most 50 characters); a report can carry at most 20 tags. Only the report's owner may tag it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Tag a report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tag to attach",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AddReportTagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tags on the report",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportTagsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID or tag name, or too many tags",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/tags/{name}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Detaches a tag from a report. The tag stays in the owner's tag set. Only the report's owner may untag it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Untag a report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tags on the report",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportTagsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID or tag name",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report or tag not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/unarchive": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Restores an archived report owned by the authenticated user to default listings",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Unarchive a report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report unarchived",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ArchiveReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reset-password": {
+            "post": {
+                "description": "Reset the user's password using a valid reset token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Reset user password",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password reset successful",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or expired token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a recording session the authenticated user uploaded with POST /upload/session, with its segments' files in session order. Each file carries its status and processing state as in GET /files and the report made from it once there is one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Get a recording session",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session",
+                        "schema": {
+                            "$ref": "#/definitions/models.RecordingSession"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/shared/{token}": {
+            "get": {
+                "description": "Returns the read-only view of a report shared by its owner. No account is needed; password-protected links require the password in X-Share-Password and lock after 10 wrong attempts; requests without it are not counted. Organizations' embedded widgets call it cross-origin with their token in X-Widget-Token from one of the widget's allowed origins, and can only show reports of the organization's members.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "View a shared report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Share token from the link",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Password of a protected link",
+                        "name": "X-Share-Password",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token of the embedded widget making the request",
+                        "name": "X-Widget-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Shared report",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SharedReportResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Wrong or missing password",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Link locked after too many wrong passwords, or invalid widget token for the origin",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Link not found, revoked or expired",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/signin": {
+            "post": {
+                "description": "Authenticate a user with email and password. The token is issued to the requested audience: web tokens are valid for 24 hours, mobile tokens for 7 days; admin routes accept web tokens only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Authenticate a user",
+                "parameters": [
+                    {
+                        "description": "User Credentials",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SignInRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User authenticated successfully with token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/signup": {
+            "post": {
+                "description": "Register a new user with the provided information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User Registration Information",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SignUpRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "User created successfully with token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/status": {
+            "get": {
+                "description": "Returns the current status and 24-hour hourly uptime history of the API, database, ML service and payments. No internal details are exposed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "status"
+                ],
+                "summary": "Service status",
+                "responses": {
+                    "200": {
+                        "description": "Component health",
+                        "schema": {
+                            "$ref": "#/definitions/health.Summary"
+                        }
+                    }
+                }
+            }
+        },
+        "/stripe/webhook": {
+            "post": {
+                "description": "Verifies the signature of a Stripe webhook event and queues it for background processing of subscription updates, payments, etc. Redeliveries of a queued event are acknowledged without queueing it again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Receive Stripe webhook events",
+                "responses": {
+                    "200": {
+                        "description": "Webhook received",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Event could not be queued",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the tags of the authenticated user, or of a user who granted them access via an account link, with how many reports carry each",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "List tags",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Owner of the tags (defaults to the authenticated user)",
+                        "name": "user_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tags",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TagsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - No access to this user's reports",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/upload": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Uploads a signal file and queues it for processing: the response returns as soon as the file is stored, with the ID of the background job that translates the recording and creates the report. Follow it at GET /files/{id}/status; the user is notified when the report is ready or processing failed. Per-channel signal quality is stored on the report, and the job carries warnings when quality is too low for a reliable translation. A file the user uploaded before, recognized by its SHA-256, returns the existing report instead of creating another one, unless allow_duplicate is true; the new report then carries duplicate_of_id. When a malware scanner is configured, files are scanned before any processing; infected files are quarantined, listed with status quarantined and the user is notified.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Upload a signal file",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "File to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Matching scale (1-10)",
+                        "name": "matchingScale",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Recording metadata as a JSON object with optional session_notes, device_id, electrode_montage and recording_conditions strings (each up to 500 characters)",
+                        "name": "metadata",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Report template the report's structured notes follow",
+                        "name": "template_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID the device assigned to the recording; a re-sent recording returns the first upload's result instead of a new report",
+                        "name": "X-Recording-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Device the recording comes from; taken from the token for device tokens",
+                        "name": "X-Device-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Structured notes as a JSON object of template sections and their field values; defaults to the file's own notes object",
+                        "name": "notes",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Create a new report, marked as a duplicate, even if the same file was uploaded before",
+                        "name": "allow_duplicate",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Hex SHA-256 of the file; the upload is refused when the file received does not match",
+                        "name": "sha256",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The existing report (duplicate=true) if the same file was uploaded before",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.FileUploadResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "File stored; processing queued",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.FileUploadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - No file uploaded, checksum mismatch, invalid matching scale, metadata, template or notes",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required - Upload or storage quota exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/plans.LimitError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - The recording is already being uploaded; see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large - The file exceeds the upload size limit (ErrorResponse) or is larger than the plan allows",
+                        "schema": {
+                            "$ref": "#/definitions/plans.LimitError"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type - The file is an executable, not an allowed format, or its content does not match its extension or media type",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity - The recording is not valid EEG data (issues lists what is wrong), or the file contains malware and was quarantined",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.EEGValidationResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests - Rate limit exceeded, or translation queue is full (free plan); see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/plans.LimitError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable - Translation service or malware scanner is down; see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/upload/session": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Uploads a zip archive holding the segments of one recording session, listed in session order by a manifest.json at the root of the archive: {\"title\": \"...\", \"recorded_at\": \"RFC 3339 time\", \"segments\": [{\"file\": \"segment-01.json\", \"matching_scale\": 6, \"metadata\": {...}}]}. Between 1 and 100 segments may be listed, each a path inside the archive; matching_scale (1-10, default 5) and metadata (as for POST /upload) are optional, and entries the manifest does not list are ignored. Segments must be in one of the accepted upload formats, and JSON segments must be valid EEG data; together they may unpack to at most the upload size limit. The archive is unpacked on the server and a session is created grouping the segments: each is stored as a file and queued for processing into a report of its own, as for POST /upload. Every segment counts as an upload against the plan's quotas. Follow the session at GET /sessions/{id}.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Upload a recording session",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Zip archive with a manifest.json and the segments it lists",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Hex SHA-256 of the archive; the upload is refused when the archive received does not match",
+                        "name": "sha256",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Session stored; processing queued",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SessionUploadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - No file uploaded, checksum mismatch, invalid archive or manifest, or invalid segment metadata",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required - Upload or storage quota exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/plans.LimitError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large - The archive or its unpacked segments exceed the upload size limit (ErrorResponse), or a segment is larger than the plan allows",
+                        "schema": {
+                            "$ref": "#/definitions/plans.LimitError"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type - The file is not a zip archive, or a segment is an executable, not an allowed format, or does not match its extension",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity - Segments are not valid EEG data (segments lists what is wrong with each), or the archive contains malware and was quarantined",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SessionValidationResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests - Rate limit exceeded, or translation queue is full (free plan); see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/plans.LimitError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable - Translation service or malware scanner is down; see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/usage": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the plan limits (uploads, translations, storage) and the usage counted against them this month. Storage counts the user's reports and the uploaded files not yet made into a report, and storage_remaining_bytes is what can still be uploaded before uploads are refused with 402.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "usage"
+                ],
+                "summary": "Get usage and quotas",
+                "responses": {
+                    "200": {
+                        "description": "Limits and usage",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UsageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/encryption": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Turns encryption of the authenticated user's report translations on or off. When on, translated text is stored encrypted with a key derived for this user from the KMS and decrypted only when served to authenticated requests; existing reports are encrypted or decrypted to match. Encrypted text is not covered by full-text search.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Set report text encryption",
+                "parameters": [
+                    {
+                        "description": "Encryption setting",
+                        "name": "encryption",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReportEncryptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Setting updated",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Encryption is not configured on this server",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/text-processing": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the post-processors applied to the authenticated user's new translations and whether they are the user's own choice, their organization's or the defaults (punctuation and casing). Reports keep the raw ML output in raw_description.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get translation post-processing",
+                "responses": {
+                    "200": {
+                        "description": "Post-processing settings",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TextProcessingResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Chooses the post-processors applied, in order, to the authenticated user's new translations: punctuation (sentence marks, including questions), casing (sentence starts and the English pronoun I) and profanity (masks profane words). Processing follows the user's locale. Null falls back to the organization's choice; an empty list stores translations as the ML service returned them. Existing reports are unchanged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Set translation post-processing",
+                "parameters": [
+                    {
+                        "description": "Post-processors",
+                        "name": "processing",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TextProcessingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Post-processing settings",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TextProcessingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Unknown processor",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get user details by ID (must be authenticated)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User details",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Trying to access other user's profile",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/deactivate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deactivates the account (must be own profile). Data is preserved, but sign-in and all existing tokens stop working until an admin reactivates it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Deactivate user account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Account deactivated",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Trying to deactivate other user's account",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/{id}/update": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update user details (must be authenticated and own profile)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update user profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User details to update",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated user details",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UserUpdateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - Trying to update other user's profile",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found - User not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/validate-ml-token": {
+            "post": {
+                "description": "Validates a JWT token and checks user subscription for ML services",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Validate ML token",
+                "parameters": [
+                    {
+                        "description": "Token to validate",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ValidateMLTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token validation result",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ValidateMLTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify-email": {
+            "post": {
+                "description": "Verifies the user's email address using the token sent by email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Verify email address",
+                "parameters": [
+                    {
+                        "description": "Verification token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.VerifyEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email verified",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid or expired token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify-email/resend": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sends a new email verification link to the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Resend verification email",
+                "responses": {
+                    "200": {
+                        "description": "Verification email sent",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Email already verified",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the authenticated user's webhooks, oldest first, without their secrets",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhooks",
+                "responses": {
+                    "200": {
+                        "description": "Webhooks",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WebhooksResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Registers a URL that receives a signed JSON POST whenever one of the authenticated user's reports is created (report.created), changed (report.updated) or finishes translating (translation.completed). Payloads carry the report's ID and metadata but not its text. Each request has an X-ThinkInk-Signature header of the form t=\u003cunix seconds\u003e,v1=\u003chex HMAC-SHA256 of \"\u003ct\u003e.\u003cbody\u003e\"\u003e keyed with the webhook's secret, which is returned only once. Failed deliveries are retried with exponential backoff up to 8 times. At most 10 webhooks per user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Register a webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook details",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Registered webhook and secret",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateWebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes one of the authenticated user's webhooks together with its delivery log; queued deliveries are dropped",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook deleted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the deliveries of one of the authenticated user's webhooks, newest first, with their payload, status (pending, delivered or failed), number of attempts, the last HTTP status received and the last error",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List a webhook's deliveries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Page size (max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of deliveries to skip (at most 10000); ignored when cursor is set",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page's pagination.next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deliveries",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WebhookDeliveriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request - Invalid ID or paging",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "config.Change": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "new": {},
+                "old": {}
+            }
+        },
+        "config.LapsePolicy": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "grace_days": {
+                    "type": "integer"
+                }
+            }
+        },
+        "config.PlanQuota": {
+            "type": "object",
+            "properties": {
+                "max_file_bytes": {
+                    "description": "Largest file the plan may upload, within MAX_UPLOAD_SIZE_MB",
+                    "type": "integer"
+                },
+                "storage_bytes": {
+                    "type": "integer"
+                },
+                "translations_per_month": {
+                    "type": "integer"
+                },
+                "upload_bytes_per_month": {
+                    "description": "Total size of the files the plan may upload per month",
+                    "type": "integer"
+                },
+                "uploads_per_month": {
+                    "type": "integer"
+                }
+            }
+        },
+        "config.Settings": {
+            "type": "object",
+            "properties": {
+                "email_max_attempts": {
+                    "type": "integer"
+                },
+                "email_provider_rates": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "email_rate_per_minute": {
+                    "type": "integer"
+                },
+                "feature_flags": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
+                },
+                "free_rate_per_minute": {
+                    "type": "integer"
+                },
+                "lapse_policies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/config.LapsePolicy"
+                    }
+                },
+                "max_upload_size_mb": {
+                    "type": "integer"
+                },
+                "ml_service_address": {
+                    "type": "string"
+                },
+                "paid_rate_per_minute": {
+                    "type": "integer"
+                },
+                "plan_capabilities": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "plan_quotas": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/config.PlanQuota"
+                    }
+                },
+                "upgrade_url": {
+                    "type": "string"
+                },
+                "upload_formats": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "upload_queue_limit": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.MigrationStep": {
+            "type": "object",
+            "properties": {
+                "concurrent": {
+                    "description": "Concurrent steps build indexes without blocking writes; they run outside a transaction",
+                    "type": "boolean"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "add_column"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "source_file_id"
+                },
+                "optional": {
+                    "description": "Optional steps may fail without failing the migration",
+                    "type": "boolean"
+                },
+                "phase": {
+                    "type": "string",
+                    "example": "expand"
+                },
+                "statements": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ALTER TABLE \"reports\" ADD \"source_file_id\" bigint"
+                    ]
+                },
+                "table": {
+                    "type": "string",
+                    "example": "reports"
+                }
+            }
+        },
+        "entitlements.CapabilityError": {
+            "type": "object",
+            "properties": {
+                "capability": {
+                    "type": "string",
+                    "example": "pdf_export"
+                },
+                "code": {
+                    "type": "string",
+                    "example": "capability_unavailable"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Your plan does not include pdf_export"
+                },
+                "plan": {
+                    "type": "string",
+                    "example": "free"
+                },
+                "upgrade_url": {
+                    "description": "Where the user can change plans; omitted when an organization sets the plan",
+                    "type": "string",
+                    "example": "https://app.thinkink.io/billing"
+                }
+            }
+        },
+        "entitlements.Entitlements": {
+            "type": "object",
+            "properties": {
+                "capabilities": {
+                    "description": "Capabilities included in the plan",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "pdf_export",
+                        "sharing"
+                    ]
+                },
+                "max_file_bytes": {
+                    "type": "integer",
+                    "example": 20971520
+                },
+                "plan": {
+                    "type": "string",
+                    "example": "free"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "plan"
+                },
+                "storage_bytes": {
+                    "type": "integer",
+                    "example": 524288000
+                },
+                "translations_per_month": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "uploads_per_month": {
+                    "type": "integer",
+                    "example": 20
+                }
+            }
+        },
+        "handlers.AcceptLinkRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "invitation-token"
+                }
+            }
+        },
+        "handlers.AddReportTagRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "post-medication"
+                }
+            }
+        },
+        "handlers.AppleReceiptRequest": {
+            "type": "object",
+            "required": [
+                "receipt_data"
+            ],
+            "properties": {
+                "product_id": {
+                    "description": "Optional product ID of the subscription; defaults to the one expiring last in the receipt",
+                    "type": "string",
+                    "example": "io.thinkink.pro.monthly"
+                },
+                "receipt_data": {
+                    "description": "Base64 app receipt from the device",
+                    "type": "string",
+                    "example": "MIIT0gYJKoZIhvcNAQcCoIITwzCCE78CAQEx..."
+                }
+            }
+        },
+        "handlers.ArchiveReportResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Report archived"
+                },
+                "report": {
+                    "$ref": "#/definitions/models.Report"
+                }
+            }
+        },
+        "handlers.AuditLogsResponse": {
+            "type": "object",
+            "properties": {
+                "logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditLog"
+                    }
+                }
+            }
+        },
+        "handlers.AuthResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Login successful"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                },
+                "user": {
+                    "$ref": "#/definitions/handlers.UserInfo"
+                }
+            }
+        },
+        "handlers.BatchItemResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Report not found"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "ok": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "handlers.BatchReportsRequest": {
+            "type": "object",
+            "required": [
+                "action",
+                "ids"
+            ],
+            "properties": {
+                "action": {
+                    "description": "One of delete, tag or matching_scale",
+                    "type": "string",
+                    "enum": [
+                        "delete",
+                        "tag",
+                        "matching_scale"
+                    ],
+                    "example": "tag"
+                },
+                "ids": {
+                    "description": "Reports to act on (at most 100)",
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3
+                    ]
+                },
+                "matching_scale": {
+                    "description": "New matching scale (0-100), for the matching_scale action",
+                    "type": "integer",
+                    "example": 85
+                },
+                "tag": {
+                    "description": "Tag to attach, for the tag action",
+                    "type": "string",
+                    "example": "morning"
+                }
+            }
+        },
+        "handlers.BatchReportsResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "tag"
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.BatchItemResult"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "handlers.BroadcastResponse": {
+            "type": "object",
+            "properties": {
+                "broadcast": {
+                    "$ref": "#/definitions/models.Broadcast"
+                },
+                "read_count": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "handlers.BroadcastsResponse": {
+            "type": "object",
+            "properties": {
+                "broadcasts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Broadcast"
+                    }
+                }
+            }
+        },
+        "handlers.CancelSubscriptionResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Subscription will be canceled at the end of the current billing period"
+                },
+                "subscription": {
+                    "$ref": "#/definitions/handlers.SubscriptionDetails"
+                }
+            }
+        },
+        "handlers.CheckoutResponse": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "description": "The currency charged, when it was chosen or inferred",
+                    "type": "string",
+                    "example": "eur"
+                },
+                "plan_id": {
+                    "description": "For subscriptions: the price charged, which may be another currency's price of the requested plan",
+                    "type": "string",
+                    "example": "price_1Oxy3JExamplePriceID"
+                },
+                "sessionId": {
+                    "type": "string",
+                    "example": "cs_test_a1b2c3d4e5f6g7h8i9j0"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://checkout.stripe.com/pay/cs_test_a1b2c3d4e5f6g7h8i9j0"
+                }
+            }
+        },
+        "handlers.ConfigReloadResponse": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/config.Change"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Config reloaded"
+                },
+                "settings": {
+                    "$ref": "#/definitions/config.Settings"
+                }
+            }
+        },
+        "handlers.ConfigResponse": {
+            "type": "object",
+            "properties": {
+                "settings": {
+                    "$ref": "#/definitions/config.Settings"
+                }
+            }
+        },
+        "handlers.CreateBroadcastRequest": {
+            "type": "object",
+            "required": [
+                "audience",
+                "body",
+                "title"
+            ],
+            "properties": {
+                "audience": {
+                    "type": "string",
+                    "enum": [
+                        "all",
+                        "cohort",
+                        "organization"
+                    ],
+                    "example": "all"
+                },
+                "body": {
+                    "type": "string",
+                    "example": "ThinkInk will be unavailable on Sunday from 02:00 to 03:00 UTC."
+                },
+                "cohort": {
+                    "$ref": "#/definitions/models.CohortFilter"
+                },
+                "organization_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "scheduled_at": {
+                    "type": "string",
+                    "example": "2026-11-01T09:00:00Z"
+                },
+                "send_email": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "send_push": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "example": "Scheduled maintenance"
+                }
+            }
+        },
+        "handlers.CreateCheckoutSessionRequest": {
+            "type": "object",
+            "required": [
+                "cancel_url",
+                "plan_id",
+                "success_url"
+            ],
+            "properties": {
+                "cancel_url": {
+                    "type": "string",
+                    "example": "https://yourapp.com/cancel"
+                },
+                "currency": {
+                    "description": "Optional ISO 4217 currency to pay in; defaults to the currency of the user's country when the plan offers it",
+                    "type": "string",
+                    "example": "eur"
+                },
+                "plan_id": {
+                    "type": "string",
+                    "example": "price_1Oxy3JExamplePriceID"
+                },
+                "promo_code": {
+                    "description": "Optional promotion code applied up front; without one the customer can enter a code at checkout",
+                    "type": "string",
+                    "example": "SPRING25"
+                },
+                "success_url": {
+                    "type": "string",
+                    "example": "https://yourapp.com/success?session_id={CHECKOUT_SESSION_ID}"
+                }
+            }
+        },
+        "handlers.CreateLinkRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "relationship"
+            ],
+            "properties": {
+                "access_expires_at": {
+                    "description": "Optional end of the grant; omit for access until revoked",
+                    "type": "string",
+                    "example": "2027-01-01T00:00:00Z"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "doctor@example.com"
+                },
+                "relationship": {
+                    "type": "string",
+                    "enum": [
+                        "caregiver",
+                        "clinician"
+                    ],
+                    "example": "clinician"
+                }
+            }
+        },
+        "handlers.CreateOneTimeCheckoutRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "cancel_url",
+                "currency",
+                "product_name",
+                "success_url"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Amount in cents, e.g., 2000 = $20.00",
+                    "type": "integer",
+                    "example": 2000
+                },
+                "cancel_url": {
+                    "type": "string",
+                    "example": "https://yourapp.com/cancel"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "usd"
+                },
+                "product_name": {
+                    "type": "string",
+                    "example": "Premium Report"
+                },
+                "promo_code": {
+                    "description": "Optional promotion code applied up front; without one the customer can enter a code at checkout",
+                    "type": "string",
+                    "example": "SPRING25"
+                },
+                "success_url": {
+                    "type": "string",
+                    "example": "https://yourapp.com/success?session_id={CHECKOUT_SESSION_ID}"
+                }
+            }
+        },
+        "handlers.CreateOrgCheckoutRequest": {
+            "type": "object",
+            "required": [
+                "cancel_url",
+                "plan_id",
+                "seats",
+                "success_url"
+            ],
+            "properties": {
+                "cancel_url": {
+                    "type": "string",
+                    "example": "https://yourapp.com/cancel"
+                },
+                "currency": {
+                    "description": "Optional ISO 4217 currency to pay in; defaults to the currency of the buyer's country when the plan offers it",
+                    "type": "string",
+                    "example": "eur"
+                },
+                "plan_id": {
+                    "type": "string",
+                    "example": "price_1Oxy3JExamplePriceID"
+                },
+                "promo_code": {
+                    "description": "Optional promotion code applied up front; without one the customer can enter a code at checkout",
+                    "type": "string",
+                    "example": "SPRING25"
+                },
+                "seats": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 10
+                },
+                "success_url": {
+                    "type": "string",
+                    "example": "https://yourapp.com/success?session_id={CHECKOUT_SESSION_ID}"
+                }
+            }
+        },
+        "handlers.CreateOrganizationRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Northside Neurology Clinic"
+                }
+            }
+        },
+        "handlers.CreateServiceCredentialRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scope"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "ML training pipeline"
+                },
+                "scope": {
+                    "type": "string",
+                    "enum": [
+                        "ml_export"
+                    ],
+                    "example": "ml_export"
+                }
+            }
+        },
+        "handlers.CreateServiceCredentialResponse": {
+            "type": "object",
+            "properties": {
+                "credential": {
+                    "$ref": "#/definitions/models.ServiceCredential"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "tik_svc_3q2x..."
+                }
+            }
+        },
+        "handlers.CreateShareRequest": {
+            "type": "object",
+            "properties": {
+                "expires_in_hours": {
+                    "description": "Hours until the link expires (default 72, max 720)",
+                    "type": "integer",
+                    "maximum": 720,
+                    "minimum": 1,
+                    "example": 72
+                },
+                "password": {
+                    "description": "Optional password the recipient must send in X-Share-Password",
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 6,
+                    "example": "tea-time"
+                }
+            }
+        },
+        "handlers.CreateTaxIDRequest": {
+            "type": "object",
+            "required": [
+                "type",
+                "value"
+            ],
+            "properties": {
+                "type": {
+                    "description": "Stripe tax ID type, e.g. eu_vat, gb_vat or ch_vat",
+                    "type": "string",
+                    "example": "eu_vat"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "DE123456789"
+                }
+            }
+        },
+        "handlers.CreateWebhookRequest": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "example": "Clinic EHR sync"
+                },
+                "events": {
+                    "description": "Events to receive: report.created, report.updated, translation.completed",
+                    "type": "array",
+                    "maxItems": 3,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "report.created",
+                        "translation.completed"
+                    ]
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048,
+                    "example": "https://example.com/thinkink/events"
+                }
+            }
+        },
+        "handlers.CreateWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "type": "string",
+                    "example": "whsec_3q2x..."
+                },
+                "webhook": {
+                    "$ref": "#/definitions/models.WebhookSubscription"
+                }
+            }
+        },
+        "handlers.CreateWidgetRequest": {
+            "type": "object",
+            "required": [
+                "allowed_origins",
+                "name"
+            ],
+            "properties": {
+                "allowed_origins": {
+                    "description": "Origins the widget is embedded from, as sent in the browser's Origin header",
+                    "type": "array",
+                    "maxItems": 10,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://portal.clinic.example"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Patient portal"
+                }
+            }
+        },
+        "handlers.CreateWidgetResponse": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "tik_wgt_3q2x..."
+                },
+                "widget": {
+                    "$ref": "#/definitions/models.EmbedWidget"
+                }
+            }
+        },
+        "handlers.DeleteFileResponse": {
+            "type": "object",
+            "properties": {
+                "deleted_report_ids": {
+                    "description": "Reports made from the file that were deleted with it",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        12
+                    ]
+                },
+                "message": {
+                    "type": "string",
+                    "example": "File deleted"
+                }
+            }
+        },
+        "handlers.DemoSubscriptionsResponse": {
+            "type": "object",
+            "properties": {
+                "subscriptions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/handlers.SubscriptionResponse"
+                    }
+                }
+            }
+        },
+        "handlers.DemoUsersResponse": {
+            "type": "object",
+            "properties": {
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.User"
+                    }
+                }
+            }
+        },
+        "handlers.DeviceTokenRequest": {
+            "type": "object",
+            "required": [
+                "device_id"
+            ],
+            "properties": {
+                "device_id": {
+                    "description": "Identifier of the headset, e.g. its serial number",
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "TI-HS-000142"
+                }
+            }
+        },
+        "handlers.DeviceTokenResponse": {
+            "type": "object",
+            "properties": {
+                "device_id": {
+                    "type": "string",
+                    "example": "TI-HS-000142"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                }
+            }
+        },
+        "handlers.EEGValidationResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "The recording is not valid EEG data"
+                },
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.EEGIssue"
+                    }
+                }
+            }
+        },
+        "handlers.EmailEventRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "type"
+            ],
+            "properties": {
+                "bounce_type": {
+                    "type": "string",
+                    "example": "hard"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "550 5.1.1 mailbox does not exist"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "bounce",
+                        "complaint"
+                    ],
+                    "example": "bounce"
+                }
+            }
+        },
+        "handlers.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Error message"
+                }
+            }
+        },
+        "handlers.FileStatusResponse": {
+            "type": "object",
+            "properties": {
+                "channel_count": {
+                    "type": "integer",
+                    "example": 32
+                },
+                "duration_seconds": {
+                    "type": "number",
+                    "example": 60
+                },
+                "error_class": {
+                    "description": "Why processing or translation last failed: storage, parse, validation,\nml_unavailable, translation or processing, and the error message",
+                    "type": "string",
+                    "example": "ml_unavailable"
+                },
+                "error_message": {
+                    "type": "string",
+                    "example": "translation service unavailable: context deadline exceeded"
+                },
+                "file_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "job": {
+                    "description": "The background job that turns the file into a report; absent for files\nuploaded before uploads were processed in the background",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.UploadJob"
+                        }
+                    ]
+                },
+                "report_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "sample_count": {
+                    "description": "Samples per channel, not counting rows the mask marks as padding",
+                    "type": "integer",
+                    "example": 15360
+                },
+                "sampling_rate_hz": {
+                    "description": "The sampling rate the recording declares, and the duration it gives\nthe samples; both empty when the recording declares none",
+                    "type": "number",
+                    "example": 256
+                },
+                "state": {
+                    "description": "pending, scanning, translating, completed or failed, and when the file got there",
+                    "type": "string",
+                    "example": "translating"
+                },
+                "state_changed_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "processing, processed, failed, unlinked or quarantined, as in GET /files",
+                    "type": "string",
+                    "example": "processing"
+                }
+            }
+        },
+        "handlers.FileUploadResponse": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Sample brain activity data"
+                },
+                "duplicate": {
+                    "description": "Set when the file was uploaded before: Duplicate when the existing\nreport is returned, DuplicateOfID when a new report was created anyway",
+                    "type": "boolean",
+                    "example": false
+                },
+                "duplicate_of_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "eta_seconds": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "file_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "job_id": {
+                    "description": "The background job turning the file into a report, and where to follow it",
+                    "type": "integer",
+                    "example": 31
+                },
+                "matching_scale": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "message": {
+                    "type": "string",
+                    "example": "File stored; processing queued"
+                },
+                "queued": {
+                    "description": "Set while the file waits for processing, with how long that should take",
+                    "type": "boolean",
+                    "example": true
+                },
+                "report_id": {
+                    "description": "Set for an existing report returned for a duplicate upload",
+                    "type": "integer",
+                    "example": 2
+                },
+                "status_url": {
+                    "type": "string",
+                    "example": "/files/1/status"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.FilesResponse": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SingleFile"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/handlers.Pagination"
+                }
+            }
+        },
+        "handlers.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                }
+            }
+        },
+        "handlers.ForgotPasswordResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Password reset instructions sent to your email"
+                }
+            }
+        },
+        "handlers.GooglePurchaseRequest": {
+            "type": "object",
+            "required": [
+                "product_id",
+                "purchase_token"
+            ],
+            "properties": {
+                "product_id": {
+                    "type": "string",
+                    "example": "io.thinkink.pro.monthly"
+                },
+                "purchase_token": {
+                    "type": "string",
+                    "example": "opaque-token-up-to-150-characters"
+                }
+            }
+        },
+        "handlers.GraphQLRequest": {
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string",
+                    "example": "{ reports(first: 10) { nodes { id title createdAt tags } pageInfo { hasNextPage endCursor } } }"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "handlers.GraphQLResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "object",
+                        "additionalProperties": true
+                    }
+                }
+            }
+        },
+        "handlers.InactiveUser": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "inactive_flagged_at": {
+                    "type": "string"
+                },
+                "last_login": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                }
+            }
+        },
+        "handlers.InactiveUsersResponse": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "integer",
+                    "example": 90
+                },
+                "sort": {
+                    "type": "string",
+                    "example": "created_at:desc"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.InactiveUser"
+                    }
+                }
+            }
+        },
+        "handlers.LinkResponse": {
+            "type": "object",
+            "properties": {
+                "link": {
+                    "$ref": "#/definitions/models.UserLink"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Invitation sent"
+                }
+            }
+        },
+        "handlers.LinksResponse": {
+            "type": "object",
+            "properties": {
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserLink"
+                    }
+                }
+            }
+        },
+        "handlers.LoginHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "logins": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LoginEvent"
+                    }
+                }
+            }
+        },
+        "handlers.MatchReportRequest": {
+            "type": "object",
+            "required": [
+                "matching_scale",
+                "report_id"
+            ],
+            "properties": {
+                "matching_scale": {
+                    "type": "integer",
+                    "example": 85
+                },
+                "report_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "handlers.MatchReportResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Report matching scale updated successfully"
+                },
+                "report": {
+                    "$ref": "#/definitions/models.Report"
+                }
+            }
+        },
+        "handlers.MatchingScaleSeriesResponse": {
+            "type": "object",
+            "properties": {
+                "granularity": {
+                    "type": "string",
+                    "example": "week"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MatchingScalePoint"
+                    }
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Europe/Berlin"
+                }
+            }
+        },
+        "handlers.MessageResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Operation completed successfully"
+                }
+            }
+        },
+        "handlers.MigrationsResponse": {
+            "type": "object",
+            "properties": {
+                "lock_retries": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "lock_timeout": {
+                    "type": "string",
+                    "example": "5s"
+                },
+                "mode": {
+                    "type": "string",
+                    "example": "auto"
+                },
+                "pending": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.MigrationStep"
+                    }
+                },
+                "pending_count": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "handlers.NotificationsResponse": {
+            "type": "object",
+            "properties": {
+                "notifications": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Notification"
+                    }
+                },
+                "unread_count": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "handlers.OrgAuditLogsResponse": {
+            "type": "object",
+            "properties": {
+                "logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditLog"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/handlers.Pagination"
+                }
+            }
+        },
+        "handlers.OrgBrandingRequest": {
+            "type": "object",
+            "properties": {
+                "accent_color": {
+                    "type": "string",
+                    "example": "#F2A900"
+                },
+                "display_name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Northside Neurology"
+                },
+                "footer_text": {
+                    "type": "string",
+                    "maxLength": 300,
+                    "example": "Northside Neurology Clinic, 12 High Street"
+                },
+                "primary_color": {
+                    "type": "string",
+                    "example": "#1F4E79"
+                }
+            }
+        },
+        "handlers.OrgBrandingResponse": {
+            "type": "object",
+            "properties": {
+                "branding": {
+                    "$ref": "#/definitions/models.OrgBranding"
+                },
+                "logo_url": {
+                    "description": "Public address of the logo used in emails; only set when there is a logo",
+                    "type": "string",
+                    "example": "http://localhost:8080/branding/1/logo?v=1767225600"
+                }
+            }
+        },
+        "handlers.OrgModerationRequest": {
+            "type": "object",
+            "required": [
+                "alert_clinicians",
+                "enabled"
+            ],
+            "properties": {
+                "alert_clinicians": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "alert_email": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "keywords": {
+                    "description": "Extra phrases flagged as self-harm on top of the built-in list",
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "give up on everything"
+                    ]
+                },
+                "moderators": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "self_harm_keywords"
+                    ]
+                }
+            }
+        },
+        "handlers.OrgModerationResponse": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "self_harm_keywords"
+                    ]
+                },
+                "policy": {
+                    "$ref": "#/definitions/models.OrgModerationPolicy"
+                }
+            }
+        },
+        "handlers.OrgRatePlanRequest": {
+            "type": "object",
+            "properties": {
+                "lapse_action": {
+                    "description": "What happens to members' raw recordings after their subscription lapses",
+                    "type": "string",
+                    "enum": [
+                        "retain",
+                        "archive",
+                        "purge"
+                    ],
+                    "example": "archive"
+                },
+                "lapse_grace_days": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 90
+                },
+                "max_file_bytes": {
+                    "description": "Capped by MAX_UPLOAD_SIZE_MB whatever the plan says",
+                    "type": "integer",
+                    "minimum": -1,
+                    "example": 209715200
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "Enterprise contract 2026"
+                },
+                "requests_per_minute": {
+                    "type": "integer",
+                    "minimum": -1,
+                    "example": 1200
+                },
+                "storage_bytes": {
+                    "type": "integer",
+                    "minimum": -1,
+                    "example": 107374182400
+                },
+                "translations_per_month": {
+                    "type": "integer",
+                    "minimum": -1,
+                    "example": 5000
+                },
+                "upload_bytes_per_month": {
+                    "type": "integer",
+                    "minimum": -1,
+                    "example": 536870912000
+                },
+                "uploads_per_month": {
+                    "type": "integer",
+                    "minimum": -1,
+                    "example": 5000
+                }
+            }
+        },
+        "handlers.OrgRatePlanResponse": {
+            "type": "object",
+            "properties": {
+                "rate_plan": {
+                    "$ref": "#/definitions/models.OrgRatePlan"
+                }
+            }
+        },
+        "handlers.OrgSubscriptionResponse": {
+            "type": "object",
+            "properties": {
+                "seats_used": {
+                    "description": "Members of the organization, each taking one seat",
+                    "type": "integer",
+                    "example": 8
+                },
+                "subscription": {
+                    "description": "Null until seats were bought",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.OrgSubscription"
+                        }
+                    ]
+                }
+            }
+        },
+        "handlers.OrganizationResponse": {
+            "type": "object",
+            "properties": {
+                "organization": {
+                    "$ref": "#/definitions/models.Organization"
+                }
+            }
+        },
+        "handlers.OrganizationsResponse": {
+            "type": "object",
+            "properties": {
+                "organizations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Organization"
+                    }
+                }
+            }
+        },
+        "handlers.Pagination": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "MTcxNjQ2NTYwMDAwMDAwMDAwMDo0Mg"
+                },
+                "offset": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "sort": {
+                    "type": "string",
+                    "example": "matching_scale:desc"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 128
+                }
+            }
+        },
+        "handlers.PlanResponse": {
+            "type": "object",
+            "properties": {
+                "capabilities": {
+                    "description": "Capabilities granted while subscribed, from PLAN_CAPABILITIES; only set for recurring prices",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "pdf_export",
+                        "sharing",
+                        "ml_api"
+                    ]
+                },
+                "currencies": {
+                    "description": "Every currency the plan can be charged in, the price's default first",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "usd",
+                        "eur"
+                    ]
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "usd"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Unlimited translations"
+                },
+                "features": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "description": "Stripe price ID, passed as plan_id to POST /payment/checkout/subscription",
+                    "type": "string",
+                    "example": "price_1Oxy3JExamplePriceID"
+                },
+                "interval": {
+                    "type": "string",
+                    "example": "month"
+                },
+                "interval_count": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "ThinkInk Pro"
+                },
+                "product_id": {
+                    "type": "string",
+                    "example": "prod_PmExampleID"
+                },
+                "quota": {
+                    "description": "Quotas granted while subscribed; only set for recurring prices",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/config.PlanQuota"
+                        }
+                    ]
+                },
+                "unit_amount": {
+                    "description": "Amount in cents",
+                    "type": "integer",
+                    "example": 1500
+                }
+            }
+        },
+        "handlers.PlansResponse": {
+            "type": "object",
+            "properties": {
+                "plans": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.PlanResponse"
+                    }
+                }
+            }
+        },
+        "handlers.ReportAccessLogResponse": {
+            "type": "object",
+            "properties": {
+                "accesses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReportAccess"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/handlers.Pagination"
+                }
+            }
+        },
+        "handlers.ReportCorrectionRequest": {
+            "type": "object",
+            "properties": {
+                "correction": {
+                    "type": "string",
+                    "maxLength": 10000,
+                    "example": "I would like a cup of tea"
+                }
+            }
+        },
+        "handlers.ReportEncryptionRequest": {
+            "type": "object",
+            "required": [
+                "enabled"
+            ],
+            "properties": {
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "handlers.ReportExportResponse": {
+            "type": "object",
+            "properties": {
+                "download_url": {
+                    "description": "Signed link that downloads the file without authentication for 15 minutes",
+                    "type": "string",
+                    "example": "/exports/12/download?expires=1767225600\u0026signature=c2lnbmF0dXJl"
+                },
+                "export": {
+                    "$ref": "#/definitions/models.ReportExport"
+                },
+                "status_url": {
+                    "description": "Poll until export.status is completed, then fetch DownloadURL",
+                    "type": "string",
+                    "example": "/reports/exports/12"
+                }
+            }
+        },
+        "handlers.ReportExportsResponse": {
+            "type": "object",
+            "properties": {
+                "exports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.ReportExportResponse"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/handlers.Pagination"
+                }
+            }
+        },
+        "handlers.ReportResponse": {
+            "type": "object",
+            "properties": {
+                "report": {
+                    "$ref": "#/definitions/models.Report"
+                }
+            }
+        },
+        "handlers.ReportRevisionsResponse": {
+            "type": "object",
+            "properties": {
+                "pagination": {
+                    "$ref": "#/definitions/handlers.Pagination"
+                },
+                "revisions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReportRevision"
+                    }
+                }
+            }
+        },
+        "handlers.ReportSearchResponse": {
+            "type": "object",
+            "properties": {
+                "pagination": {
+                    "$ref": "#/definitions/handlers.Pagination"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReportSearchResult"
+                    }
+                }
+            }
+        },
+        "handlers.ReportTagsResponse": {
+            "type": "object",
+            "properties": {
+                "report_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "morning",
+                        "post-medication"
+                    ]
+                }
+            }
+        },
+        "handlers.ReportTemplateRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "sections"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "example": "Notes taken after each speech therapy session"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Speech therapy session"
+                },
+                "sections": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TemplateSection"
+                    }
+                }
+            }
+        },
+        "handlers.ReportTemplatesResponse": {
+            "type": "object",
+            "properties": {
+                "templates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReportTemplate"
+                    }
+                }
+            }
+        },
+        "handlers.ReportsResponse": {
+            "type": "object",
+            "properties": {
+                "pagination": {
+                    "$ref": "#/definitions/handlers.Pagination"
+                },
+                "reports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Report"
+                    }
+                }
+            }
+        },
+        "handlers.ResetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "minLength": 8,
+                    "example": "new-password"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "reset-token"
+                }
+            }
+        },
+        "handlers.RetranslateResponse": {
+            "type": "object",
+            "properties": {
+                "file_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Translation queued"
+                },
+                "report_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "status_url": {
+                    "type": "string",
+                    "example": "/files/7/status"
+                }
+            }
+        },
+        "handlers.SegmentIssues": {
+            "type": "object",
+            "properties": {
+                "file": {
+                    "type": "string",
+                    "example": "segment-02.json"
+                },
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.EEGIssue"
+                    }
+                }
+            }
+        },
+        "handlers.ServiceCredentialsResponse": {
+            "type": "object",
+            "properties": {
+                "credentials": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ServiceCredential"
+                    }
+                }
+            }
+        },
+        "handlers.SessionSegmentResult": {
+            "type": "object",
+            "properties": {
+                "file": {
+                    "type": "string",
+                    "example": "segment-01.json"
+                },
+                "file_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "job_id": {
+                    "type": "integer",
+                    "example": 31
+                }
+            }
+        },
+        "handlers.SessionUploadResponse": {
+            "type": "object",
+            "properties": {
+                "eta_seconds": {
+                    "description": "How long the segments should wait for processing",
+                    "type": "integer",
+                    "example": 12
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Session stored; processing queued"
+                },
+                "segments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.SessionSegmentResult"
+                    }
+                },
+                "session_id": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "status_url": {
+                    "type": "string",
+                    "example": "/sessions/4"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.SessionValidationResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Some segments are not valid EEG data"
+                },
+                "segments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.SegmentIssues"
+                    }
+                }
+            }
+        },
+        "handlers.SetOrgSeatsRequest": {
+            "type": "object",
+            "required": [
+                "seats"
+            ],
+            "properties": {
+                "seats": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 12
+                }
+            }
+        },
+        "handlers.SetUserOrganizationRequest": {
+            "type": "object",
+            "properties": {
+                "org_role": {
+                    "description": "Role within the organization; defaults to member when the organization changes",
+                    "type": "string",
+                    "enum": [
+                        "member",
+                        "admin"
+                    ],
+                    "example": "admin"
+                },
+                "organization_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "handlers.ShareResponse": {
+            "type": "object",
+            "properties": {
+                "share": {
+                    "$ref": "#/definitions/models.ReportShare"
+                },
+                "url": {
+                    "description": "Public link to the read-only view; only set while the share is active",
+                    "type": "string",
+                    "example": "http://localhost:3000/shared/12.QmFzZTY0.c2lnbmF0dXJl"
+                }
+            }
+        },
+        "handlers.SharedReportResponse": {
+            "type": "object",
+            "properties": {
+                "correction": {
+                    "type": "string",
+                    "example": "I would like a cup of coffee"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "I would like a cup of tea"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "matching_scale": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "morning"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "example": "morning-session.json"
+                },
+                "translation_status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.SharesResponse": {
+            "type": "object",
+            "properties": {
+                "shares": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.ShareResponse"
+                    }
+                }
+            }
+        },
+        "handlers.SignInRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "audience": {
+                    "description": "Client the token is for: web (default, valid 24 hours) or mobile (valid 7 days)",
+                    "type": "string",
+                    "enum": [
+                        "web",
+                        "mobile"
+                    ],
+                    "example": "web"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "password123"
+                }
+            }
+        },
+        "handlers.SignUpRequest": {
+            "type": "object",
+            "required": [
+                "date_of_birth",
+                "email",
+                "name",
+                "password"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "123 Main St"
+                },
+                "city": {
+                    "type": "string",
+                    "example": "New York"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "US"
+                },
+                "country_code": {
+                    "type": "string",
+                    "example": "+1"
+                },
+                "date_of_birth": {
+                    "type": "string",
+                    "example": "1990-01-01T00:00:00Z"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "locale": {
+                    "type": "string",
+                    "example": "en-US"
+                },
+                "mobile": {
+                    "type": "string",
+                    "example": "5551234567"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 8,
+                    "example": "password123"
+                },
+                "payment_info": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "{\"card_type\"": "\"visa\"}"
+                    }
+                },
+                "postal_code": {
+                    "type": "string",
+                    "example": "10001"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "America/New_York"
+                }
+            }
+        },
+        "handlers.SimilarReportsResponse": {
+            "type": "object",
+            "properties": {
+                "model": {
+                    "description": "Embedding model the similarities were computed with",
+                    "type": "string",
+                    "example": "hash-256"
+                },
+                "reports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SimilarReport"
+                    }
+                }
+            }
+        },
+        "handlers.StripeEventsResponse": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StripeEvent"
+                    }
+                }
+            }
+        },
+        "handlers.SubscriptionDetails": {
+            "type": "object",
+            "properties": {
+                "cancel_at_period_end": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "current_period_end": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "sub_12345"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                }
+            }
+        },
+        "handlers.SubscriptionResponse": {
+            "type": "object",
+            "properties": {
+                "cancel_at_period_end": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "current_period_end": {
+                    "type": "string"
+                },
+                "discount": {
+                    "description": "Coupon applied to the subscription, if any",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SubscriptionDiscount"
+                        }
+                    ]
+                },
+                "has_subscription": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "payment_grace_ends_at": {
+                    "description": "Set while a renewal payment has failed: premium features stay available until then",
+                    "type": "string"
+                },
+                "plan_id": {
+                    "type": "string",
+                    "example": "price_1Oxy3JExamplePriceID"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                },
+                "store": {
+                    "description": "app_store or google_play for subscriptions bought in the mobile apps",
+                    "type": "string",
+                    "example": "app_store"
+                },
+                "subscription_id": {
+                    "type": "string",
+                    "example": "sub_12345"
+                }
+            }
+        },
+        "handlers.SuccessResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Operation completed successfully"
+                }
+            }
+        },
+        "handlers.TagsResponse": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TagUsage"
+                    }
+                }
+            }
+        },
+        "handlers.TaxIDsResponse": {
+            "type": "object",
+            "properties": {
+                "tax_ids": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CustomerTaxID"
+                    }
+                }
+            }
+        },
+        "handlers.TextProcessingRequest": {
+            "type": "object",
+            "properties": {
+                "processors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "punctuation",
+                        "casing",
+                        "profanity"
+                    ]
+                }
+            }
+        },
+        "handlers.TextProcessingResponse": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "description": "Every processor that can be chosen",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "casing",
+                        "profanity",
+                        "punctuation"
+                    ]
+                },
+                "processors": {
+                    "description": "Processors applied to new translations, in order",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "punctuation",
+                        "casing"
+                    ]
+                },
+                "source": {
+                    "description": "Where they come from: user, organization or default",
+                    "type": "string",
+                    "example": "default"
+                }
+            }
+        },
+        "handlers.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Token refreshed successfully"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                }
+            }
+        },
+        "handlers.UpdateLinkExpiryRequest": {
+            "type": "object",
+            "properties": {
+                "access_expires_at": {
+                    "type": "string",
+                    "example": "2027-01-01T00:00:00Z"
+                }
+            }
+        },
+        "handlers.UpdateReportRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 10000,
+                    "example": "I would like a cup of tea"
+                },
+                "metadata": {
+                    "description": "Replaces all metadata; an empty object clears it",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "session": "morning"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Morning session"
+                }
+            }
+        },
+        "handlers.UpdateUserRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "123 Main St"
+                },
+                "city": {
+                    "type": "string",
+                    "example": "New York"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "US"
+                },
+                "country_code": {
+                    "type": "string",
+                    "example": "+1"
+                },
+                "locale": {
+                    "type": "string",
+                    "example": "en-US"
+                },
+                "mobile": {
+                    "type": "string",
+                    "example": "5551234567"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "payment_info": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "{\"card_type\"": "\"visa\"}"
+                    }
+                },
+                "postal_code": {
+                    "type": "string",
+                    "example": "10001"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "America/New_York"
+                }
+            }
+        },
+        "handlers.UsageResponse": {
+            "type": "object",
+            "properties": {
+                "limits": {
+                    "$ref": "#/definitions/plans.Limits"
+                },
+                "storage_remaining_bytes": {
+                    "description": "Bytes that can still be stored before uploads are refused; -1 when unlimited",
+                    "type": "integer",
+                    "example": 1047527424
+                },
+                "usage": {
+                    "$ref": "#/definitions/plans.Usage"
+                }
+            }
+        },
+        "handlers.UserInfo": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "must_change_password": {
+                    "description": "Set when the account still uses a temporary password, e.g. after a bulk import",
+                    "type": "boolean",
+                    "example": false
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                }
+            }
+        },
+        "handlers.UserResponse": {
+            "type": "object",
+            "properties": {
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                }
+            }
+        },
+        "handlers.UserUpdateResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "User updated successfully"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                }
+            }
+        },
+        "handlers.ValidateMLTokenRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                }
+            }
+        },
+        "handlers.ValidateMLTokenResponse": {
+            "type": "object",
+            "properties": {
+                "is_valid": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "handlers.VerifyEmailRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "verification-token"
+                }
+            }
+        },
+        "handlers.WebhookDeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WebhookDelivery"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/handlers.Pagination"
+                }
+            }
+        },
+        "handlers.WebhookResponse": {
+            "type": "object",
+            "properties": {
+                "received": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "handlers.WebhooksResponse": {
+            "type": "object",
+            "properties": {
+                "webhooks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WebhookSubscription"
+                    }
+                }
+            }
+        },
+        "handlers.WidgetsResponse": {
+            "type": "object",
+            "properties": {
+                "widgets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.EmbedWidget"
+                    }
+                }
+            }
+        },
+        "health.Bucket": {
+            "type": "object",
+            "properties": {
+                "start": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "operational"
+                },
+                "uptime_percent": {
+                    "type": "number",
+                    "example": 100
+                }
+            }
+        },
+        "health.ComponentStatus": {
+            "type": "object",
+            "properties": {
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/health.Bucket"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "database"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "operational"
+                },
+                "uptime_percent": {
+                    "type": "number",
+                    "example": 99.95
+                }
+            }
+        },
+        "health.Readiness": {
+            "type": "object",
+            "properties": {
+                "degraded": {
+                    "description": "Optional components that are down or slow",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ml_service"
+                    ]
+                },
+                "failing": {
+                    "description": "Critical components that are down",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "database"
+                    ]
+                },
+                "probed_at": {
+                    "description": "When the optional components were last probed",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "degraded"
+                }
+            }
+        },
+        "health.Summary": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/health.ComponentStatus"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "operational"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "jobs.BillingMetrics": {
+            "type": "object",
+            "properties": {
+                "active_subscriptions": {
+                    "description": "Subscriptions granting their plan now, including past-due ones in their payment grace period",
+                    "type": "integer",
+                    "example": 120
+                },
+                "by_plan": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jobs.PlanMetrics"
+                    }
+                },
+                "churn_rate": {
+                    "description": "Churned subscriptions over those held at the start of the month",
+                    "type": "number",
+                    "example": 0.026
+                },
+                "churned_this_month": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "failed_payment_amount": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "failed_payments_this_month": {
+                    "description": "Invoices whose payment failed this month, and their amounts due by currency",
+                    "type": "integer",
+                    "example": 4
+                },
+                "month_start": {
+                    "description": "Start of the current month (UTC) the counts below cover",
+                    "type": "string"
+                },
+                "mrr": {
+                    "description": "Monthly recurring revenue of paying Stripe subscriptions by currency,\nin the smallest currency unit, before discounts and tax",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "new_this_month": {
+                    "type": "integer",
+                    "example": 9
+                }
+            }
+        },
+        "jobs.PendingDeletions": {
+            "type": "object",
+            "properties": {
+                "deleted_reports": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "next_purge_at": {
+                    "type": "string"
+                },
+                "oldest_deleted_at": {
+                    "type": "string"
+                },
+                "scheduled_recordings_actions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ScheduledRecordingsActions"
+                    }
+                }
+            }
+        },
+        "jobs.PlanMetrics": {
+            "type": "object",
+            "properties": {
+                "mrr": {
+                    "description": "Monthly recurring revenue by currency, in the smallest currency unit",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "plan_id": {
+                    "type": "string",
+                    "example": "price_1Oxy3JExamplePriceID"
+                },
+                "seats": {
+                    "description": "Seats of organization subscriptions",
+                    "type": "integer",
+                    "example": 0
+                },
+                "source": {
+                    "description": "stripe, app_store, google_play or organization",
+                    "type": "string",
+                    "example": "stripe"
+                },
+                "subscriptions": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "jobs.RetentionPolicy": {
+            "type": "object",
+            "properties": {
+                "default_lapse_action": {
+                    "type": "string",
+                    "example": "archive"
+                },
+                "default_lapse_grace_days": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "deleted_report_purge_days": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "lapse_action": {
+                    "description": "LapseAction and LapseGraceDays are nil when members follow their plan defaults",
+                    "type": "string",
+                    "example": "purge"
+                },
+                "lapse_grace_days": {
+                    "type": "integer",
+                    "example": 30
+                }
+            }
+        },
+        "jobs.RetentionRecordings": {
+            "type": "object",
+            "properties": {
+                "stored_reports": {
+                    "type": "integer",
+                    "example": 840
+                },
+                "users_by_status": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "jobs.RetentionRecords": {
+            "type": "object",
+            "properties": {
+                "report_revisions": {
+                    "$ref": "#/definitions/models.RetentionAgeCounts"
+                },
+                "reports": {
+                    "$ref": "#/definitions/models.RetentionAgeCounts"
+                }
+            }
+        },
+        "jobs.RetentionReport": {
+            "type": "object",
+            "properties": {
+                "generated_at": {
+                    "type": "string"
+                },
+                "members": {
+                    "type": "integer",
+                    "example": 25
+                },
+                "not_tracked": {
+                    "description": "NotTracked names compliance controls this deployment does not record,\nso auditors can tell them apart from an empty result",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "legal_holds",
+                        "data_residency"
+                    ]
+                },
+                "organization_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "organization_name": {
+                    "type": "string",
+                    "example": "Acme Clinic"
+                },
+                "pending_deletions": {
+                    "$ref": "#/definitions/jobs.PendingDeletions"
+                },
+                "policy": {
+                    "$ref": "#/definitions/jobs.RetentionPolicy"
+                },
+                "recordings": {
+                    "$ref": "#/definitions/jobs.RetentionRecordings"
+                },
+                "records": {
+                    "$ref": "#/definitions/jobs.RetentionRecords"
+                }
+            }
+        },
+        "mlexport.Record": {
+            "type": "object",
+            "properties": {
+                "content_schema_version": {
+                    "type": "integer"
+                },
+                "correction": {
+                    "type": "string"
+                },
+                "eeg": {
+                    "type": "object"
+                },
+                "eeg_ref": {
+                    "type": "string"
+                },
+                "matching_scale": {
+                    "type": "integer"
+                },
+                "record_id": {
+                    "type": "string"
+                },
+                "recorded_month": {
+                    "type": "string"
+                },
+                "subject_id": {
+                    "type": "string"
+                },
+                "translation": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AuditLog": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "ml_export.feedback"
+                },
+                "actor": {
+                    "type": "string",
+                    "example": "service:3"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "description": "Set for entries about an organization or its members, which its admins can view",
+                    "type": "integer",
+                    "example": 1
+                },
+                "outcome": {
+                    "type": "string",
+                    "example": "success"
+                },
+                "resource": {
+                    "description": "What the action applied to, e.g. \"report:12\" or \"organization:1\"",
+                    "type": "string",
+                    "example": "report:12"
+                }
+            }
+        },
+        "models.Broadcast": {
+            "type": "object",
+            "properties": {
+                "audience": {
+                    "type": "string"
+                },
+                "body": {
+                    "type": "string"
+                },
+                "cohort": {
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "emails_queued": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "in_app_delivered": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "type": "integer"
+                },
+                "push_failed": {
+                    "type": "integer"
+                },
+                "push_sent": {
+                    "type": "integer"
+                },
+                "recipients": {
+                    "type": "integer"
+                },
+                "scheduled_at": {
+                    "type": "string"
+                },
+                "send_email": {
+                    "type": "boolean"
+                },
+                "send_push": {
+                    "type": "boolean"
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CohortFilter": {
+            "type": "object",
+            "properties": {
+                "created_after": {
+                    "type": "string"
+                },
+                "created_before": {
+                    "type": "string"
+                },
+                "inactive_only": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "locale": {
+                    "type": "string",
+                    "example": "en-US"
+                },
+                "plan_id": {
+                    "type": "string",
+                    "example": "price_1Oxy3JExamplePriceID"
+                },
+                "subscription_status": {
+                    "type": "string",
+                    "example": "active"
+                }
+            }
+        },
+        "models.CustomerTaxID": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "type": "string",
+                    "example": "DE"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "stripe_tax_id": {
+                    "type": "string",
+                    "example": "txi_1NExampleID"
+                },
+                "type": {
+                    "description": "Stripe tax ID type, e.g. eu_vat or gb_vat",
+                    "type": "string",
+                    "example": "eu_vat"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "value": {
+                    "type": "string",
+                    "example": "DE123456789"
+                },
+                "verification_status": {
+                    "description": "pending, verified, unverified or unavailable",
+                    "type": "string",
+                    "example": "verified"
+                }
+            }
+        },
+        "models.EmbedWidget": {
+            "type": "object",
+            "properties": {
+                "allowed_origins": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://portal.clinic.example"
+                    ]
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Patient portal"
+                },
+                "organization_id": {
+                    "type": "integer"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "token_hint": {
+                    "type": "string",
+                    "example": "tik_wgt_3q2x"
+                }
+            }
+        },
+        "models.FileEvent": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Why processing failed",
+                    "type": "string"
+                },
+                "file_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "job_id": {
+                    "type": "integer",
+                    "example": 31
+                },
+                "report_id": {
+                    "description": "Set once the report is ready",
+                    "type": "integer",
+                    "example": 12
+                },
+                "state": {
+                    "type": "string",
+                    "example": "report_ready"
+                }
+            }
+        },
+        "models.FilePreview": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "file_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "points": {
+                    "description": "Points per channel, and how many samples each point covers",
+                    "type": "integer",
+                    "example": 200
+                },
+                "samples_per_point": {
+                    "type": "number",
+                    "example": 76.8
+                },
+                "sampling_rate_hz": {
+                    "description": "The recording's sampling rate, when it declares one",
+                    "type": "number",
+                    "example": 256
+                },
+                "series": {
+                    "description": "Per channel: {\"channel\", \"min\", \"max\"}, the lowest and highest sample of each point",
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                }
+            }
+        },
+        "models.LoginEvent": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LoginSummary": {
+            "type": "object",
+            "properties": {
+                "active_last_30_days": {
+                    "type": "integer",
+                    "example": 410
+                },
+                "active_last_7_days": {
+                    "type": "integer",
+                    "example": 180
+                },
+                "active_last_day": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "logins_last_30_days": {
+                    "type": "integer",
+                    "example": 2150
+                }
+            }
+        },
+        "models.MatchingScalePoint": {
+            "type": "object",
+            "properties": {
+                "average": {
+                    "type": "number",
+                    "example": 72.5
+                },
+                "max": {
+                    "type": "integer",
+                    "example": 85
+                },
+                "min": {
+                    "type": "integer",
+                    "example": 60
+                },
+                "period": {
+                    "description": "First day of the period in the owner's time zone; weeks start on Monday",
+                    "type": "string",
+                    "example": "2026-10-12"
+                },
+                "reports": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "models.Notification": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "broadcast_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "read_at": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OnboardingStatus": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "first_upload"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OnboardingStep"
+                    }
+                }
+            }
+        },
+        "models.OnboardingStep": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "completed_at": {
+                    "type": "string"
+                },
+                "step": {
+                    "type": "string",
+                    "example": "first_upload"
+                }
+            }
+        },
+        "models.OrgBranding": {
+            "type": "object",
+            "properties": {
+                "accent_color": {
+                    "type": "string",
+                    "example": "#F2A900"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "description": "Name shown in place of \"ThinkInk\" in document titles and email senders",
+                    "type": "string",
+                    "example": "Northside Neurology"
+                },
+                "footer_text": {
+                    "description": "Printed in document footers and appended to emails",
+                    "type": "string",
+                    "example": "Northside Neurology Clinic, 12 High Street"
+                },
+                "has_logo": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "logo_content_type": {
+                    "type": "string",
+                    "example": "image/png"
+                },
+                "logo_updated_at": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "type": "integer"
+                },
+                "primary_color": {
+                    "description": "Colors as #RRGGBB: the primary color is used for titles, the accent for rules",
+                    "type": "string",
+                    "example": "#1F4E79"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OrgModerationPolicy": {
+            "type": "object",
+            "properties": {
+                "alert_clinicians": {
+                    "description": "Notify clinicians linked to the member when a report is flagged, in-app and by push, and also by email with AlertEmail",
+                    "type": "boolean"
+                },
+                "alert_email": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "keywords": {
+                    "description": "Extra phrases flagged as self-harm on top of the built-in list",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "give up on everything"
+                    ]
+                },
+                "moderators": {
+                    "description": "Moderators run on members' translations; null uses the defaults",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "self_harm_keywords"
+                    ]
+                },
+                "organization_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OrgRatePlan": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lapse_action": {
+                    "type": "string"
+                },
+                "lapse_grace_days": {
+                    "type": "integer"
+                },
+                "max_file_bytes": {
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "type": "integer"
+                },
+                "requests_per_minute": {
+                    "type": "integer"
+                },
+                "storage_bytes": {
+                    "type": "integer"
+                },
+                "translations_per_month": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "upload_bytes_per_month": {
+                    "type": "integer"
+                },
+                "uploads_per_month": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OrgSubscription": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current_period_end": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "organization_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "plan_id": {
+                    "type": "string",
+                    "example": "price_1Oxy3JExamplePriceID"
+                },
+                "seats": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                },
+                "subscription_id": {
+                    "type": "string",
+                    "example": "sub_12345"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Organization": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "text_processors": {
+                    "description": "Post-processors applied to members' translations, in order; null uses the defaults",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "punctuation",
+                        "casing",
+                        "profanity"
+                    ]
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RecordingSession": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "files": {
+                    "description": "The segments in session order; loaded by FindUserSession",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SingleFile"
+                    }
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "recorded_at": {
+                    "type": "string"
+                },
+                "segment_count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Morning session"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.Report": {
+            "type": "object",
+            "properties": {
+                "archived_at": {
+                    "description": "Archived reports are hidden from default listings; deleted reports are purged after a retention window",
+                    "type": "string"
+                },
+                "channel_count": {
+                    "type": "integer",
+                    "example": 32
+                },
+                "content": {
+                    "type": "string",
+                    "example": "{\"key\":\"value\"}"
+                },
+                "content_archived_at": {
+                    "description": "Set once Content was moved to cold storage; Content is then empty\nunless read back with LoadArchivedContent",
+                    "type": "string"
+                },
+                "content_schema_version": {
+                    "description": "Schema version of Content; older reports are upgraded on read",
+                    "type": "integer"
+                },
+                "correction": {
+                    "description": "The user's corrected version of the translation, used as model feedback",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "duplicate_of_id": {
+                    "description": "Set when the report was created from a file the user had already\nuploaded, with allow_duplicate; points at the earlier report",
+                    "type": "integer",
+                    "example": 12
+                },
+                "duration_seconds": {
+                    "type": "number",
+                    "example": 60
+                },
+                "flagged_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "matching_scale": {
+                    "type": "integer"
+                },
+                "metadata": {
+                    "description": "User-editable key/value labels, e.g. session or device notes",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "session": "morning"
+                    }
+                },
+                "moderation_flags": {
+                    "description": "Set when moderation flagged the translation for a clinician to review",
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "quality": {
+                    "type": "object"
+                },
+                "raw_description": {
+                    "description": "The ML service's output before post-processing; Description holds the processed text",
+                    "type": "string"
+                },
+                "sample_count": {
+                    "description": "Samples per channel, not counting rows the mask marks as padding",
+                    "type": "integer",
+                    "example": 15360
+                },
+                "sampling_rate_hz": {
+                    "description": "The sampling rate the recording declares, and the duration it gives\nthe samples; both empty when the recording declares none",
+                    "type": "number",
+                    "example": 256
+                },
+                "session_id": {
+                    "description": "The recording session the source file is a segment of",
+                    "type": "integer",
+                    "example": 4
+                },
+                "size_bytes": {
+                    "type": "integer"
+                },
+                "source_file": {
+                    "$ref": "#/definitions/models.SingleFile"
+                },
+                "source_file_id": {
+                    "description": "The uploaded file the report was created from; SourceFile is only\nloaded with PreloadSourceFile",
+                    "type": "integer",
+                    "example": 7
+                },
+                "tags": {
+                    "description": "Names of the tags attached to the report; filled in by LoadReportTags",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "morning"
+                    ]
+                },
+                "template_id": {
+                    "description": "Template the structured notes in Content[\"notes\"] follow",
+                    "type": "integer",
+                    "example": 3
+                },
+                "title": {
+                    "type": "string"
+                },
+                "translation_status": {
+                    "description": "Set while a translation waits for or runs on a dedicated worker",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReportAccess": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "view"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip_address": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "report_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "share_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "viewer_email": {
+                    "type": "string",
+                    "example": "jane.roe@clinic.example"
+                },
+                "viewer_id": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "viewer_name": {
+                    "description": "Name and email of the viewer at the time of listing; filled in by FindReportAccessPage",
+                    "type": "string",
+                    "example": "Dr. Jane Roe"
+                }
+            }
+        },
+        "models.ReportExport": {
+            "type": "object",
+            "properties": {
+                "columns": {
+                    "type": "string",
+                    "example": "id,title,created_at"
+                },
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string",
+                    "example": "csv"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "owner_id": {
+                    "description": "Whose reports are exported",
+                    "type": "integer"
+                },
+                "progress": {
+                    "description": "Percentage of the reports written",
+                    "type": "integer",
+                    "example": 50
+                },
+                "requested_by": {
+                    "description": "The user who requested the export and may download it",
+                    "type": "integer"
+                },
+                "row_count": {
+                    "description": "Reports written so far out of the reports matching when it was requested",
+                    "type": "integer",
+                    "example": 600
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "completed"
+                },
+                "total_rows": {
+                    "type": "integer",
+                    "example": 1200
+                }
+            }
+        },
+        "models.ReportRevision": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "description": "The change that replaced this version",
+                    "type": "string",
+                    "example": "matching_scale"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "matching_scale": {
+                    "type": "integer"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "session": "morning"
+                    }
+                },
+                "report_id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReportSearchResult": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "I would like a cup of coffee"
+                },
+                "highlight": {
+                    "type": "string",
+                    "example": "I would like a cup of \u003cmark\u003ecoffee\u003c/mark\u003e"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "matching_scale": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "rank": {
+                    "type": "number",
+                    "example": 0.6079
+                },
+                "title": {
+                    "type": "string",
+                    "example": "session-2026-03-14.json"
+                }
+            }
+        },
+        "models.ReportShare": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_viewed_at": {
+                    "type": "string"
+                },
+                "owner_id": {
+                    "type": "integer"
+                },
+                "password_protected": {
+                    "type": "boolean"
+                },
+                "report_id": {
+                    "type": "integer"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "view_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReportTemplate": {
+            "type": "object",
+            "properties": {
+                "archived_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Notes taken after each speech therapy session"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Speech therapy session"
+                },
+                "sections": {
+                    "description": "The template's sections; see TemplateSection",
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RetentionAgeCounts": {
+            "type": "object",
+            "properties": {
+                "1_to_3_years": {
+                    "type": "integer",
+                    "example": 300
+                },
+                "30_to_90_days": {
+                    "type": "integer",
+                    "example": 250
+                },
+                "90_to_365_days": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "oldest_created_at": {
+                    "type": "string"
+                },
+                "over_3_years": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "under_30_days": {
+                    "type": "integer",
+                    "example": 150
+                }
+            }
+        },
+        "models.ScheduledRecordingsActions": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "purge"
+                },
+                "earliest_due_at": {
+                    "type": "string"
+                },
+                "users": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "models.ServiceCredential": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key_hint": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SimilarReport": {
+            "type": "object",
+            "properties": {
+                "archived_at": {
+                    "description": "Archived reports are hidden from default listings; deleted reports are purged after a retention window",
+                    "type": "string"
+                },
+                "channel_count": {
+                    "type": "integer",
+                    "example": 32
+                },
+                "content": {
+                    "type": "string",
+                    "example": "{\"key\":\"value\"}"
+                },
+                "content_archived_at": {
+                    "description": "Set once Content was moved to cold storage; Content is then empty\nunless read back with LoadArchivedContent",
+                    "type": "string"
+                },
+                "content_schema_version": {
+                    "description": "Schema version of Content; older reports are upgraded on read",
+                    "type": "integer"
+                },
+                "correction": {
+                    "description": "The user's corrected version of the translation, used as model feedback",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "duplicate_of_id": {
+                    "description": "Set when the report was created from a file the user had already\nuploaded, with allow_duplicate; points at the earlier report",
+                    "type": "integer",
+                    "example": 12
+                },
+                "duration_seconds": {
+                    "type": "number",
+                    "example": 60
+                },
+                "flagged_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "matching_scale": {
+                    "type": "integer"
+                },
+                "metadata": {
+                    "description": "User-editable key/value labels, e.g. session or device notes",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "session": "morning"
+                    }
+                },
+                "moderation_flags": {
+                    "description": "Set when moderation flagged the translation for a clinician to review",
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "quality": {
+                    "type": "object"
+                },
+                "raw_description": {
+                    "description": "The ML service's output before post-processing; Description holds the processed text",
+                    "type": "string"
+                },
+                "sample_count": {
+                    "description": "Samples per channel, not counting rows the mask marks as padding",
+                    "type": "integer",
+                    "example": 15360
+                },
+                "sampling_rate_hz": {
+                    "description": "The sampling rate the recording declares, and the duration it gives\nthe samples; both empty when the recording declares none",
+                    "type": "number",
+                    "example": 256
+                },
+                "session_id": {
+                    "description": "The recording session the source file is a segment of",
+                    "type": "integer",
+                    "example": 4
+                },
+                "similarity": {
+                    "type": "number",
+                    "example": 0.83
+                },
+                "size_bytes": {
+                    "type": "integer"
+                },
+                "source_file": {
+                    "$ref": "#/definitions/models.SingleFile"
+                },
+                "source_file_id": {
+                    "description": "The uploaded file the report was created from; SourceFile is only\nloaded with PreloadSourceFile",
+                    "type": "integer",
+                    "example": 7
+                },
+                "tags": {
+                    "description": "Names of the tags attached to the report; filled in by LoadReportTags",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "morning"
+                    ]
+                },
+                "template_id": {
+                    "description": "Template the structured notes in Content[\"notes\"] follow",
+                    "type": "integer",
+                    "example": 3
+                },
+                "title": {
+                    "type": "string"
+                },
+                "translation_status": {
+                    "description": "Set while a translation waits for or runs on a dedicated worker",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SingleFile": {
+            "type": "object",
+            "properties": {
+                "channel_count": {
+                    "type": "integer",
+                    "example": 32
+                },
+                "content_hash": {
+                    "description": "SHA-256 of the file's bytes, used to recognize the same recording uploaded again",
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                },
+                "download_url": {
+                    "description": "Where the original file can be fetched; filled in by the report detail handler",
+                    "type": "string",
+                    "example": "/reports/12/source-file"
+                },
+                "duration_seconds": {
+                    "type": "number",
+                    "example": 60
+                },
+                "error_class": {
+                    "description": "Why processing or translation last failed, one of the FileError* classes;\ncleared when the file is processed again",
+                    "type": "string",
+                    "example": "ml_unavailable"
+                },
+                "error_message": {
+                    "type": "string",
+                    "example": "translation service unavailable: context deadline exceeded"
+                },
+                "file_size": {
+                    "type": "integer",
+                    "example": 48213
+                },
+                "filename": {
+                    "type": "string",
+                    "example": "morning-session.json"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "quarantined_at": {
+                    "description": "Set when the malware scan found the file infected; it was quarantined and never processed",
+                    "type": "string"
+                },
+                "report_id": {
+                    "description": "The report made from the file; filled in by LoadFileReports",
+                    "type": "integer",
+                    "example": 12
+                },
+                "sample_count": {
+                    "description": "Samples per channel, not counting rows the mask marks as padding",
+                    "type": "integer",
+                    "example": 15360
+                },
+                "sampling_rate_hz": {
+                    "description": "The sampling rate the recording declares, and the duration it gives\nthe samples; both empty when the recording declares none",
+                    "type": "number",
+                    "example": 256
+                },
+                "session_id": {
+                    "description": "The recording session the file is a segment of, and its position there",
+                    "type": "integer",
+                    "example": 4
+                },
+                "session_index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "state": {
+                    "description": "Processing state, persisted as each step starts; one of the FileState* values",
+                    "type": "string",
+                    "example": "completed"
+                },
+                "state_changed_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status derived from the linked report; filled in by LoadFileReports",
+                    "type": "string",
+                    "example": "processed"
+                },
+                "threat": {
+                    "description": "Signature the scanner matched",
+                    "type": "string",
+                    "example": "Eicar-Signature"
+                },
+                "uploaded_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.StripeEvent": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "description": "Stripe's event ID; redeliveries of a stored event are ignored",
+                    "type": "string",
+                    "example": "evt_1NExampleID"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "object_id": {
+                    "description": "ID of the Stripe object the event is about, such as a subscription",
+                    "type": "string",
+                    "example": "sub_1NExampleID"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "processed_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "stripe_created_at": {
+                    "description": "When Stripe created the event; events can be delivered out of order",
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "customer.subscription.updated"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SubscriptionDiscount": {
+            "type": "object",
+            "properties": {
+                "amount_off": {
+                    "description": "Amount off in the currency's minor unit, for fixed-amount coupons",
+                    "type": "integer"
+                },
+                "coupon_id": {
+                    "type": "string",
+                    "example": "SPRING25"
+                },
+                "coupon_name": {
+                    "type": "string",
+                    "example": "Spring sale"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "duration": {
+                    "description": "once, repeating or forever",
+                    "type": "string",
+                    "example": "repeating"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "percent_off": {
+                    "type": "number",
+                    "example": 25
+                },
+                "promotion_code_id": {
+                    "type": "string",
+                    "example": "promo_1NExampleID"
+                }
+            }
+        },
+        "models.TagUsage": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "morning"
+                },
+                "report_count": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "models.TemplateField": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string",
+                    "example": "chief_complaint"
+                },
+                "label": {
+                    "type": "string",
+                    "example": "Chief complaint"
+                },
+                "options": {
+                    "description": "Allowed values of a choice field",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "mild",
+                        "moderate",
+                        "severe"
+                    ]
+                },
+                "required": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "type": {
+                    "description": "One of text, number, boolean or choice",
+                    "type": "string",
+                    "example": "text"
+                }
+            }
+        },
+        "models.TemplateSection": {
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TemplateField"
+                    }
+                },
+                "key": {
+                    "type": "string",
+                    "example": "assessment"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Assessment"
+                }
+            }
+        },
+        "models.UploadJob": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Why processing failed",
+                    "type": "string"
+                },
+                "file_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 31
+                },
+                "report_id": {
+                    "description": "Set once the report was created",
+                    "type": "integer",
+                    "example": 12
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "queued"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "warnings": {
+                    "description": "Warnings about the recording, e.g. low signal quality",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.User": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "city": {
+                    "type": "string"
+                },
+                "country": {
+                    "type": "string"
+                },
+                "country_code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "current_plan_id": {
+                    "type": "string"
+                },
+                "date_of_birth": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_verified_at": {
+                    "description": "Onboarding progress; each timestamp is set the first time the step is reached",
+                    "type": "string"
+                },
+                "encrypt_report_text": {
+                    "description": "Report translations are stored encrypted with a per-user key",
+                    "type": "boolean"
+                },
+                "first_report_at": {
+                    "type": "string"
+                },
+                "first_subscribed_at": {
+                    "type": "string"
+                },
+                "first_upload_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "inactive_flagged_at": {
+                    "description": "Set by the inactive-user job; cleared on the next sign-in",
+                    "type": "string"
+                },
+                "last_login": {
+                    "type": "string"
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "mobile": {
+                    "type": "string"
+                },
+                "must_change_password": {
+                    "description": "Set for accounts created with a temporary password; cleared when the password is changed",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "onboarding_completed_at": {
+                    "type": "string"
+                },
+                "org_role": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "type": "integer"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "past_due_since": {
+                    "description": "Set while a renewal payment has failed; subscriber entitlements are\nkept until the grace period ends",
+                    "type": "string"
+                },
+                "payment_grace_ends_at": {
+                    "type": "string"
+                },
+                "payment_info": {
+                    "type": "string",
+                    "example": "{\"card_type\":\"visa\"}"
+                },
+                "postal_code": {
+                    "type": "string"
+                },
+                "recordings_action": {
+                    "type": "string"
+                },
+                "recordings_action_due_at": {
+                    "type": "string"
+                },
+                "recordings_status": {
+                    "description": "Raw recording retention after a subscription lapses",
+                    "type": "string"
+                },
+                "reports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Report"
+                    }
+                },
+                "role": {
+                    "type": "string"
+                },
+                "stripe_customer_id": {
+                    "description": "Stripe fields",
+                    "type": "string"
+                },
+                "stripe_default_payment_method": {
+                    "type": "string"
+                },
+                "subscription_discount": {
+                    "description": "Coupon applied to the subscription, kept in step by Stripe webhooks",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SubscriptionDiscount"
+                        }
+                    ]
+                },
+                "subscription_ends_at": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "string"
+                },
+                "subscription_status": {
+                    "type": "string"
+                },
+                "subscription_store": {
+                    "description": "Where the subscription was bought: empty for Stripe, app_store or google_play",
+                    "type": "string"
+                },
+                "text_processors": {
+                    "description": "Post-processors applied to translations, in order; null uses the organization's choice",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "punctuation",
+                        "casing"
+                    ]
+                },
+                "timezone": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserLink": {
+            "type": "object",
+            "properties": {
+                "accepted_at": {
+                    "type": "string"
+                },
+                "access_expires_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invite_email": {
+                    "type": "string"
+                },
+                "owner_id": {
+                    "type": "integer"
+                },
+                "relationship": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "viewer_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string",
+                    "example": "report.created"
+                },
+                "event_id": {
+                    "type": "string",
+                    "example": "evt_0b6f3a52-5d1e-4a8e-9d0c-2f9e3c1b7a44"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "response_status": {
+                    "description": "HTTP status of the last attempt; 0 when no response was received",
+                    "type": "integer",
+                    "example": 200
+                },
+                "status": {
+                    "type": "string",
+                    "example": "delivered"
+                },
+                "subscription_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WebhookSubscription": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Clinic EHR sync"
+                },
+                "events": {
+                    "description": "Comma-separated events the webhook receives",
+                    "type": "string",
+                    "example": "report.created,translation.completed"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/thinkink/events"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "plans.LimitError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "quota_exceeded"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "monthly uploads quota exceeded (limit 20)"
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "plan": {
+                    "type": "string",
+                    "example": "free"
+                },
+                "remaining": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "reset": {
+                    "description": "When the limit resets; omitted for storage, which only frees up as reports\nare deleted, and for file size",
+                    "type": "string"
+                },
+                "resource": {
+                    "type": "string",
+                    "example": "uploads"
+                },
+                "retry_after": {
+                    "description": "Seconds until retrying can succeed, also sent as Retry-After",
+                    "type": "integer",
+                    "example": 30
+                },
+                "upgrade_url": {
+                    "description": "Where the user can change plans; omitted when an organization's rate plan sets the limits",
+                    "type": "string",
+                    "example": "https://app.thinkink.io/billing"
+                }
+            }
+        },
+        "plans.Limits": {
+            "type": "object",
+            "properties": {
+                "max_file_bytes": {
+                    "description": "Largest file that may be uploaded; never more than MAX_UPLOAD_SIZE_MB",
+                    "type": "integer",
+                    "example": 52428800
+                },
+                "plan": {
+                    "type": "string",
+                    "example": "free"
+                },
+                "requests_per_minute": {
+                    "type": "integer",
+                    "example": 300
+                },
+                "source": {
+                    "type": "string",
+                    "example": "plan"
+                },
+                "storage_bytes": {
+                    "type": "integer",
+                    "example": -1
+                },
+                "translations_per_month": {
+                    "type": "integer",
+                    "example": -1
+                },
+                "upload_bytes_per_month": {
+                    "description": "Total size of the files that may be uploaded per month",
+                    "type": "integer",
+                    "example": -1
+                },
+                "uploads_per_month": {
+                    "type": "integer",
+                    "example": -1
+                }
+            }
+        },
+        "plans.Usage": {
+            "type": "object",
+            "properties": {
+                "period": {
+                    "type": "string",
+                    "example": "2026-10"
+                },
+                "resets_at": {
+                    "type": "string"
+                },
+                "storage_bytes": {
+                    "type": "integer",
+                    "example": 1048576
+                },
+                "translations": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "upload_bytes": {
+                    "description": "Total size of the files uploaded this period",
+                    "type": "integer",
+                    "example": 3145728
+                },
+                "uploads": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "services.EEGIssue": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "integer"
+                },
+                "code": {
+                    "type": "string",
+                    "example": "row_length"
+                },
+                "count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Row 12 has 31 values, but the recording has 32 channels"
+                },
+                "row": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "userimport.CreatedUser": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "line": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "userimport.Result": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/userimport.CreatedUser"
+                    }
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/userimport.RowError"
+                    }
+                },
+                "total_rows": {
+                    "type": "integer",
+                    "example": 120
+                }
+            }
+        },
+        "userimport.RowError": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "jane@example.com"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "email already exists"
+                },
+                "line": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "Type \"Bearer\" followed by a space and the JWT token.",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
+    }
+}`
+
+// SwaggerInfov1 holds exported Swagger Info so clients can modify it
+var SwaggerInfov1 = &swag.Spec{
+	Version:          "1.0",
+	Host:             "localhost:8080",
+	BasePath:         "/",
+	Schemes:          []string{"http", "https"},
+	Title:            "ThinkInk API",
+	Description:      "ThinkInk backend API with Stripe Checkout integration",
+	InfoInstanceName: "v1",
+	SwaggerTemplate:  docTemplatev1,
+	LeftDelim:        "{{",
+	RightDelim:       "}}",
+}
+
+func init() {
+	swag.Register(SwaggerInfov1.InstanceName(), SwaggerInfov1)
+}
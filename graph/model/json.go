@@ -0,0 +1,30 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/99designs/gqlgen/graphql"
+	"gorm.io/datatypes"
+)
+
+// MarshalJSON writes a JSON column as a GraphQL JSON scalar
+func MarshalJSON(value datatypes.JSON) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		if len(value) == 0 {
+			io.WriteString(w, "null")
+			return
+		}
+		w.Write(value)
+	})
+}
+
+// UnmarshalJSON reads a GraphQL JSON scalar into a JSON column value
+func UnmarshalJSON(v interface{}) (datatypes.JSON, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON value: %w", err)
+	}
+	return datatypes.JSON(data), nil
+}
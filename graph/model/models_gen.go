@@ -0,0 +1,95 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+)
+
+type FileConnection struct {
+	Nodes      []models.SingleFile `json:"nodes"`
+	TotalCount int                 `json:"totalCount"`
+	PageInfo   *PageInfo           `json:"pageInfo"`
+}
+
+type PageInfo struct {
+	HasNextPage bool `json:"hasNextPage"`
+	// Pass as after to fetch the next page
+	EndCursor *string `json:"endCursor,omitempty"`
+}
+
+type Query struct {
+}
+
+type ReportConnection struct {
+	Nodes      []models.Report `json:"nodes"`
+	TotalCount int             `json:"totalCount"`
+	PageInfo   *PageInfo       `json:"pageInfo"`
+}
+
+type ReportFilter struct {
+	// Created at or after
+	From *time.Time `json:"from,omitempty"`
+	// Created before
+	To       *time.Time `json:"to,omitempty"`
+	MinScale *int       `json:"minScale,omitempty"`
+	MaxScale *int       `json:"maxScale,omitempty"`
+	// Case-insensitive match on title or description
+	Query *string `json:"query,omitempty"`
+	// Tags the report must all carry
+	Tags     []string         `json:"tags,omitempty"`
+	Archived *ArchivedReports `json:"archived,omitempty"`
+	// Only reports flagged by moderation
+	Flagged *bool `json:"flagged,omitempty"`
+}
+
+type ArchivedReports string
+
+const (
+	// Hide archived reports
+	ArchivedReportsExclude ArchivedReports = "EXCLUDE"
+	// Only archived reports
+	ArchivedReportsOnly ArchivedReports = "ONLY"
+	// Archived and active reports
+	ArchivedReportsAll ArchivedReports = "ALL"
+)
+
+var AllArchivedReports = []ArchivedReports{
+	ArchivedReportsExclude,
+	ArchivedReportsOnly,
+	ArchivedReportsAll,
+}
+
+func (e ArchivedReports) IsValid() bool {
+	switch e {
+	case ArchivedReportsExclude, ArchivedReportsOnly, ArchivedReportsAll:
+		return true
+	}
+	return false
+}
+
+func (e ArchivedReports) String() string {
+	return string(e)
+}
+
+func (e *ArchivedReports) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ArchivedReports(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ArchivedReports", str)
+	}
+	return nil
+}
+
+func (e ArchivedReports) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
@@ -0,0 +1,220 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/ThinkInkTeam/thinkink-core-backend/graph/model"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/encryption"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"gorm.io/gorm"
+)
+
+// Page sizes of GraphQL connections, matching the REST listings
+const (
+	defaultPageSize = 50
+	maxPageSize     = 100
+)
+
+// Resolver resolves GraphQL queries against the database
+type Resolver struct {
+	DB *gorm.DB
+}
+
+type viewerKey struct{}
+
+// Viewer is the authenticated caller of a GraphQL request
+type Viewer struct {
+	UserID uint
+	IP     string
+}
+
+// WithViewer returns a context carrying the authenticated caller
+func WithViewer(ctx context.Context, viewer Viewer) context.Context {
+	return context.WithValue(ctx, viewerKey{}, viewer)
+}
+
+// viewerFrom returns the authenticated caller of the request
+func viewerFrom(ctx context.Context) (Viewer, error) {
+	viewer, ok := ctx.Value(viewerKey{}).(Viewer)
+	if !ok {
+		return Viewer{}, errors.New("unauthorized")
+	}
+	return viewer, nil
+}
+
+// parseID reads a numeric ID argument
+func parseID(id string) (uint, error) {
+	value, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return 0, gqlerror.Errorf("invalid ID %q", id)
+	}
+	return uint(value), nil
+}
+
+// connectionPage reads the first and after arguments of a connection
+func connectionPage(first *int, after *string) (models.Page, error) {
+	page := models.Page{Limit: defaultPageSize}
+	if first != nil {
+		if *first < 1 || *first > maxPageSize {
+			return page, gqlerror.Errorf("first must be between 1 and %d", maxPageSize)
+		}
+		page.Limit = *first
+	}
+	if after != nil {
+		page.Cursor = *after
+	}
+	return page, nil
+}
+
+// pageInfo describes a fetched page of a connection
+func pageInfo(info models.PageInfo) *model.PageInfo {
+	result := &model.PageInfo{HasNextPage: info.HasMore}
+	if info.NextCursor != "" {
+		result.EndCursor = &info.NextCursor
+	}
+	return result
+}
+
+// pageError maps a paging error to the error returned to the client
+func pageError(err error, what string) error {
+	if err.Error() == "invalid cursor" {
+		return gqlerror.Errorf("invalid cursor")
+	}
+	return gqlerror.Errorf("failed to fetch %s", what)
+}
+
+// reportFilter converts the GraphQL filter input to a report listing filter
+func reportFilter(input *model.ReportFilter) (models.ReportFilter, error) {
+	var filter models.ReportFilter
+	if input == nil {
+		return filter, nil
+	}
+
+	filter.From = input.From
+	filter.To = input.To
+	filter.MinScale = input.MinScale
+	filter.MaxScale = input.MaxScale
+	if input.Query != nil {
+		filter.Query = *input.Query
+	}
+	if input.Flagged != nil {
+		filter.Flagged = *input.Flagged
+	}
+	if input.Archived != nil {
+		switch *input.Archived {
+		case model.ArchivedReportsOnly:
+			filter.Archived = models.ArchivedOnly
+		case model.ArchivedReportsAll:
+			filter.Archived = models.ArchivedInclude
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, value := range input.Tags {
+		name, err := models.NormalizeTagName(value)
+		if err != nil {
+			return filter, gqlerror.Errorf("%s", err.Error())
+		}
+		if !seen[name] {
+			seen[name] = true
+			filter.Tags = append(filter.Tags, name)
+		}
+	}
+	return filter, nil
+}
+
+// selects reports whether the query selects the field at path below the
+// field being resolved, e.g. "nodes", "tags". Costly fields are only loaded
+// when a query asks for them.
+func selects(ctx context.Context, path ...string) bool {
+	fields := graphql.CollectFieldsCtx(ctx, nil)
+	for i, name := range path {
+		var next *graphql.CollectedField
+		for j := range fields {
+			if fields[j].Name == name {
+				next = &fields[j]
+				break
+			}
+		}
+		if next == nil {
+			return false
+		}
+		if i < len(path)-1 {
+			fields = graphql.CollectFields(graphql.GetOperationContext(ctx), next.Selections, nil)
+		}
+	}
+	return true
+}
+
+// reportConnection fetches a page of the user's reports, loading source
+// files and tags only when the query selects them
+func (r *Resolver) reportConnection(ctx context.Context, userID uint, first *int, after *string, input *model.ReportFilter) (*model.ReportConnection, error) {
+	page, err := connectionPage(first, after)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := reportFilter(input)
+	if err != nil {
+		return nil, err
+	}
+
+	db := r.DB
+	if selects(ctx, "nodes", "sourceFile") {
+		db = db.Scopes(models.PreloadSourceFile)
+	}
+	user := &models.User{ID: userID}
+	reports, info, err := user.FindUserReportsPage(db, filter, page)
+	if err != nil {
+		return nil, pageError(err, "reports")
+	}
+
+	if err := r.openReports(ctx, reports, []string{"nodes"}); err != nil {
+		return nil, err
+	}
+
+	// Reads that cannot be logged are refused, so the access log stays complete
+	viewer, err := viewerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	access := models.ReportAccess{ViewerID: &viewer.UserID, Action: models.AccessList, IPAddress: viewer.IP}
+	if err := models.RecordReportAccesses(r.DB, access, models.ReportIDs(reports)); err != nil {
+		return nil, gqlerror.Errorf("failed to record report access")
+	}
+
+	return &model.ReportConnection{
+		Nodes:      reports,
+		TotalCount: int(info.Total),
+		PageInfo:   pageInfo(info),
+	}, nil
+}
+
+// openReports decrypts the reports' text, reads content moved to cold storage
+// back and loads their tags when the query selects them at path
+func (r *Resolver) openReports(ctx context.Context, reports []models.Report, path []string) error {
+	field := func(name string) bool { return selects(ctx, append(path, name)...) }
+
+	if field("description") || field("rawDescription") || field("correction") {
+		if err := encryption.OpenReports(ctx, reports); err != nil {
+			log.Printf("Failed to decrypt report text: %v", err)
+			return gqlerror.Errorf("failed to decrypt report text")
+		}
+	}
+	if field("content") {
+		if err := models.LoadArchivedContents(ctx, reports); err != nil {
+			log.Printf("Failed to load report content: %v", err)
+			return gqlerror.Errorf("report content could not be read from cold storage")
+		}
+	}
+	if field("tags") {
+		if err := models.LoadReportTags(r.DB, reports); err != nil {
+			return gqlerror.Errorf("failed to fetch report tags")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.55
+
+import (
+	"context"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/graph/model"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*models.User, error) {
+	viewer, err := viewerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := models.FindUserByID(r.DB, viewer.UserID)
+	if err != nil {
+		return nil, gqlerror.Errorf("user not found")
+	}
+	return user, nil
+}
+
+// Report is the resolver for the report field.
+func (r *queryResolver) Report(ctx context.Context, id string) (*models.Report, error) {
+	viewer, err := viewerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reportID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	db := r.DB
+	if selects(ctx, "sourceFile") {
+		db = db.Scopes(models.PreloadSourceFile)
+	}
+	report, err := models.FindReportByID(db, reportID)
+	if err != nil {
+		return nil, nil
+	}
+
+	// Reports without access are reported as missing so IDs cannot be probed
+	allowed, err := models.CanViewReports(r.DB, viewer.UserID, report.UserID)
+	if err != nil {
+		return nil, gqlerror.Errorf("failed to check access")
+	}
+	if !allowed {
+		return nil, nil
+	}
+
+	reports := []models.Report{*report}
+	if err := r.openReports(ctx, reports, nil); err != nil {
+		return nil, err
+	}
+	report = &reports[0]
+
+	// Reads that cannot be logged are refused, so the access log stays complete
+	access := &models.ReportAccess{
+		ReportID:  report.ID,
+		ViewerID:  &viewer.UserID,
+		Action:    models.AccessView,
+		IPAddress: viewer.IP,
+	}
+	if err := models.RecordReportAccess(r.DB, access); err != nil {
+		return nil, gqlerror.Errorf("failed to record report access")
+	}
+	return report, nil
+}
+
+// Reports is the resolver for the reports field.
+func (r *queryResolver) Reports(ctx context.Context, first *int, after *string, filter *model.ReportFilter) (*model.ReportConnection, error) {
+	viewer, err := viewerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.reportConnection(ctx, viewer.UserID, first, after, filter)
+}
+
+// Tags is the resolver for the tags field.
+func (r *queryResolver) Tags(ctx context.Context) ([]models.TagUsage, error) {
+	viewer, err := viewerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := models.FindUserTags(r.DB, viewer.UserID)
+	if err != nil {
+		return nil, gqlerror.Errorf("failed to fetch tags")
+	}
+	return tags, nil
+}
+
+// Files is the resolver for the files field.
+func (r *queryResolver) Files(ctx context.Context, first *int, after *string) (*model.FileConnection, error) {
+	viewer, err := viewerFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	page, err := connectionPage(first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	files, info, err := models.FindUserFilesPage(r.DB, viewer.UserID, models.FileFilter{}, page)
+	if err != nil {
+		return nil, pageError(err, "files")
+	}
+	return &model.FileConnection{
+		Nodes:      files,
+		TotalCount: int(info.Total),
+		PageInfo:   pageInfo(info),
+	}, nil
+}
+
+// Reports is the resolver for the reports field.
+func (r *userResolver) Reports(ctx context.Context, obj *models.User, first *int, after *string, filter *model.ReportFilter) (*model.ReportConnection, error) {
+	return r.reportConnection(ctx, obj.ID, first, after, filter)
+}
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// User returns UserResolver implementation.
+func (r *Resolver) User() UserResolver { return &userResolver{r} }
+
+type queryResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
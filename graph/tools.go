@@ -0,0 +1,6 @@
+//go:build tools
+
+// Keeps the gqlgen generator in go.mod for `make gen-graphql`
+package graph
+
+import _ "github.com/99designs/gqlgen"
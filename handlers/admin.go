@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ConfigResponse represents the active runtime settings
+type ConfigResponse struct {
+	Settings *config.Settings `json:"settings"`
+}
+
+// ConfigReloadResponse represents the result of a config reload
+type ConfigReloadResponse struct {
+	Message  string           `json:"message" example:"Config reloaded"`
+	Changes  []config.Change  `json:"changes"`
+	Settings *config.Settings `json:"settings"`
+}
+
+// GetConfigHandler returns the active non-secret settings
+// @Summary Get runtime settings
+// @Description Returns the active non-secret settings (admin only)
+// @Tags admin
+// @Produce json
+// @Success 200 {object} ConfigResponse "Active settings"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Security BearerAuth
+// @Router /admin/config [get]
+func GetConfigHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, ConfigResponse{Settings: config.Current()})
+}
+
+// ReloadConfigHandler re-reads the non-secret settings without restarting the servers
+// @Summary Reload runtime settings
+// @Description Re-reads tunable settings from the env file and environment, validates them and applies them (admin only)
+// @Tags admin
+// @Produce json
+// @Success 200 {object} ConfigReloadResponse "Config reloaded"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 422 {object} ErrorResponse "Invalid configuration, previous settings kept"
+// @Security BearerAuth
+// @Router /admin/config/reload [post]
+func ReloadConfigHandler(c *gin.Context) {
+	changes, err := config.Reload()
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Error: "Invalid configuration: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ConfigReloadResponse{
+		Message:  "Config reloaded",
+		Changes:  changes,
+		Settings: config.Current(),
+	})
+}
+
+// AdminDeactivateUser deactivates any user account
+// @Summary Deactivate a user
+// @Description Soft-deletes a user account, preserving its data (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} MessageResponse "Account deactivated"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - User not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/users/{id}/deactivate [post]
+func AdminDeactivateUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	user, err := models.FindUserByID(database.DB, uint(userID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		return
+	}
+
+	if err := user.Deactivate(database.DB); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to deactivate account"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Account deactivated"})
+}
+
+// AdminReactivateUser restores a deactivated user account
+// @Summary Reactivate a user
+// @Description Restores a deactivated user account so it can sign in again (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} UserResponse "Reactivated user"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - User not found or not deactivated"
+// @Security BearerAuth
+// @Router /admin/users/{id}/reactivate [post]
+func AdminReactivateUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	user, err := models.ReactivateUser(database.DB, uint(userID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Clear sensitive fields
+	user.PasswordHash = ""
+
+	c.JSON(http.StatusOK, UserResponse{User: *user})
+}
+
+// InactiveUser summarises a user who has not signed in recently
+type InactiveUser struct {
+	ID                uint       `json:"id" example:"1"`
+	Name              string     `json:"name" example:"John Doe"`
+	Email             string     `json:"email" example:"john@example.com"`
+	CreatedAt         time.Time  `json:"created_at"`
+	LastLogin         *time.Time `json:"last_login,omitempty"`
+	InactiveFlaggedAt *time.Time `json:"inactive_flagged_at,omitempty"`
+}
+
+// InactiveUsersResponse represents the list of inactive users
+type InactiveUsersResponse struct {
+	Days  int            `json:"days" example:"90"`
+	Sort  string         `json:"sort,omitempty" example:"created_at:desc"`
+	Users []InactiveUser `json:"users"`
+}
+
+// LoginHistoryResponse represents a user's recent sign-ins
+type LoginHistoryResponse struct {
+	Logins []models.LoginEvent `json:"logins"`
+}
+
+// GetInactiveUsers lists users who have not signed in for a number of days
+// @Summary List inactive users
+// @Description Lists users with no sign-in in the last N days (default INACTIVE_USER_DAYS), longest inactive first or in the given sort order (admin only)
+// @Tags admin
+// @Produce json
+// @Param days query int false "Inactivity threshold in days"
+// @Param sort query string false "Comma-separated field:direction pairs, e.g. created_at:desc; fields are created_at, last_login, name and email, directions asc (default) or desc"
+// @Success 200 {object} InactiveUsersResponse "Inactive users"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid days or sort"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/users/inactive [get]
+func GetInactiveUsers(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", utils.GetEnvWithDefault("INACTIVE_USER_DAYS", "90")))
+	if err != nil || days < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "days must be a positive integer"})
+		return
+	}
+
+	orders, err := models.UserSortFields.Parse(c.Query("sort"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	users, err := models.FindInactiveUsers(database.DB, time.Now().AddDate(0, 0, -days), false, orders)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch inactive users"})
+		return
+	}
+
+	response := InactiveUsersResponse{Days: days, Sort: formatSort(orders), Users: make([]InactiveUser, 0, len(users))}
+	for _, u := range users {
+		response.Users = append(response.Users, InactiveUser{
+			ID:                u.ID,
+			Name:              u.Name,
+			Email:             u.Email,
+			CreatedAt:         u.CreatedAt,
+			LastLogin:         u.LastLogin,
+			InactiveFlaggedAt: u.InactiveFlaggedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetUserLoginHistory returns a user's recent sign-ins
+// @Summary Get a user's login history
+// @Description Returns the user's most recent sign-ins with IP and user agent (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Param limit query int false "Maximum number of entries (default 50, max 500)"
+// @Success 200 {object} LoginHistoryResponse "Login history"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/users/{id}/logins [get]
+func GetUserLoginHistory(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "limit must be between 1 and 500"})
+		return
+	}
+
+	logins, err := models.FindLoginHistory(database.DB, uint(userID), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch login history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, LoginHistoryResponse{Logins: logins})
+}
+
+// GetLoginSummary returns active-user counts
+// @Summary Get login analytics
+// @Description Returns distinct users signed in over the last day, 7 days and 30 days (admin only)
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.LoginSummary "Login analytics"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/analytics/logins [get]
+func GetLoginSummary(c *gin.Context) {
+	summary, err := models.SummarizeLogins(database.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to compute login analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/ThinkInkTeam/thinkink-core-backend/database"
 	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/email"
 	"github.com/ThinkInkTeam/thinkink-core-backend/services/validation"
 	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
 	"github.com/gin-gonic/gin"
@@ -26,6 +28,8 @@ type SignUpRequest struct {
 	City        string                 `json:"city" example:"New York"`
 	Country     string                 `json:"country" example:"US"`
 	PostalCode  string                 `json:"postal_code" example:"10001"`
+	Timezone    string                 `json:"timezone" example:"America/New_York"`
+	Locale      string                 `json:"locale" example:"en-US"`
 	PaymentInfo map[string]interface{} `json:"payment_info" swaggertype:"object,string" example:"{\"card_type\":\"visa\"}"`
 }
 
@@ -33,6 +37,21 @@ type SignUpRequest struct {
 type SignInRequest struct {
 	Email    string `json:"email" binding:"required,email" example:"john@example.com"`
 	Password string `json:"password" binding:"required" example:"password123"`
+	// Client the token is for: web (default, valid 24 hours) or mobile (valid 7 days)
+	Audience string `json:"audience" binding:"omitempty,oneof=web mobile" example:"web"`
+}
+
+// DeviceTokenRequest represents the request for a headset's device token
+type DeviceTokenRequest struct {
+	// Identifier of the headset, e.g. its serial number
+	DeviceID string `json:"device_id" binding:"required,max=100" example:"TI-HS-000142"`
+}
+
+// DeviceTokenResponse carries a device token
+type DeviceTokenResponse struct {
+	Token     string    `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	DeviceID  string    `json:"device_id" example:"TI-HS-000142"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 // AuthResponse represents the response for authentication endpoints
@@ -47,6 +66,8 @@ type UserInfo struct {
 	ID    uint   `json:"id" example:"1"`
 	Name  string `json:"name" example:"John Doe"`
 	Email string `json:"email" example:"john@example.com"`
+	// Set when the account still uses a temporary password, e.g. after a bulk import
+	MustChangePassword bool `json:"must_change_password,omitempty" example:"false"`
 }
 
 // TokenResponse represents a response containing just a token
@@ -90,11 +111,26 @@ type ResetPasswordRequest struct {
 func SignUp(c *gin.Context) {
 	var req SignUpRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
+	if req.Timezone != "" {
+		if err := utils.ValidateTimezone(req.Timezone); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+	if req.Locale != "" {
+		locale, err := utils.ValidateLocale(req.Locale)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		req.Locale = locale
+	}
+
 	user, err := models.CreateUser(
 		database.DB,
 		req.Name,
@@ -107,6 +143,8 @@ func SignUp(c *gin.Context) {
 		req.City,
 		req.Country,
 		req.PostalCode,
+		req.Timezone,
+		req.Locale,
 		req.PaymentInfo,
 	)
 
@@ -121,6 +159,11 @@ func SignUp(c *gin.Context) {
 		return
 	}
 
+	// Verification is the first onboarding step; a failed send can be retried via /verify-email/resend
+	if _, err := sendVerificationEmail(database.DB, user); err != nil {
+		log.Printf("Failed to send verification email: %v", err)
+	}
+
 	c.JSON(http.StatusCreated, AuthResponse{
 		Message: "User registered successfully",
 		User: UserInfo{
@@ -134,7 +177,7 @@ func SignUp(c *gin.Context) {
 
 // SignIn handles user authentication
 // @Summary Authenticate a user
-// @Description Authenticate a user with email and password
+// @Description Authenticate a user with email and password. The token is issued to the requested audience: web tokens are valid for 24 hours, mobile tokens for 7 days; admin routes accept web tokens only
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -147,7 +190,7 @@ func SignUp(c *gin.Context) {
 func SignIn(c *gin.Context) {
 	var req SignInRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -163,14 +206,17 @@ func SignIn(c *gin.Context) {
 		return
 	}
 
-	token, err := user.GenerateJWT()
+	if req.Audience == "" {
+		req.Audience = models.TokenAudienceWeb
+	}
+	token, err := user.GenerateAudienceJWT(req.Audience, "")
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to generate token"})
 		return
 	}
 
 	// Update last login time
-	if err := user.UpdateLastLogin(database.DB); err != nil {
+	if err := user.UpdateLastLogin(database.DB, c.ClientIP(), c.Request.UserAgent()); err != nil {
 		// Non-critical error, just log it
 		log.Printf("Failed to update last login time: %v", err)
 	}
@@ -178,9 +224,10 @@ func SignIn(c *gin.Context) {
 	c.JSON(http.StatusOK, AuthResponse{
 		Message: "Login successful",
 		User: UserInfo{
-			ID:    user.ID,
-			Name:  user.Name,
-			Email: user.Email,
+			ID:                 user.ID,
+			Name:               user.Name,
+			Email:              user.Email,
+			MustChangePassword: user.MustChangePassword,
 		},
 		Token: token,
 	})
@@ -240,7 +287,7 @@ func Logout(c *gin.Context) {
 
 // RefreshToken generates a new JWT token for the user
 // @Summary Refresh authentication token
-// @Description Generate a new JWT token using a valid existing token
+// @Description Generate a new JWT token for the same audience using a valid existing token. Device tokens cannot be refreshed
 // @Tags auth
 // @Produce json
 // @Security BearerAuth
@@ -263,8 +310,8 @@ func RefreshToken(c *gin.Context) {
 		return
 	}
 
-	// Generate a new token
-	token, err := user.GenerateJWT()
+	// Generate a new token for the client the current one was issued to
+	token, err := user.GenerateAudienceJWT(c.GetString("tokenAudience"), "")
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to generate token"})
 		return
@@ -276,6 +323,53 @@ func RefreshToken(c *gin.Context) {
 	})
 }
 
+// IssueDeviceToken issues a token for a headset
+// @Summary Issue a device token
+// @Description Issues a token for a headset to upload recordings with. Device tokens are valid for 30 days but may only call POST /upload, so a leaked headset token cannot read reports or change the account.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param device body DeviceTokenRequest true "Headset to issue the token to"
+// @Success 201 {object} DeviceTokenResponse "Device token"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid input"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Device tokens cannot issue tokens"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /device-tokens [post]
+func IssueDeviceToken(c *gin.Context) {
+	// Get the user ID from the middleware context
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	var req DeviceTokenRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, err := models.FindUserByID(database.DB, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "User not found"})
+		return
+	}
+
+	token, err := user.GenerateAudienceJWT(models.TokenAudienceDevice, req.DeviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, DeviceTokenResponse{
+		Token:     token,
+		DeviceID:  req.DeviceID,
+		ExpiresAt: time.Now().Add(models.TokenLifetimes[models.TokenAudienceDevice]),
+	})
+}
+
 // CheckAuth validates if a user's token is valid
 // @Summary Validate authentication token
 // @Description Check if the current token is valid and not blacklisted
@@ -325,7 +419,7 @@ func CheckAuth(c *gin.Context) {
 func ForgotPassword(c *gin.Context) {
 	var req ForgotPasswordRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -343,9 +437,15 @@ func ForgotPassword(c *gin.Context) {
 		return
 	}
 
-	// Here you would normally send an email with the reset link
-	// For this implementation, we'll just return the token in the response
-	// In production, you should send an email and not expose the token in the response
+	// Queue the reset email; delivery is retried by the email worker
+	resetURL := utils.GetEnvWithDefault("PASSWORD_RESET_URL", "http://localhost:3000/reset-password?token=") + resetToken
+	expiresAt := user.FormatTimestamp(time.Now().Add(1 * time.Hour))
+	body := fmt.Sprintf("Hi %s,\n\nUse the link below to reset your password. It expires at %s.\n\n%s\n\nIf you did not request a reset, you can ignore this email.\n", user.Name, expiresAt, resetURL)
+	if err := email.Enqueue(database.DB, user.Email, "Reset your ThinkInk password", body, "transactional"); err != nil {
+		log.Printf("Failed to queue password reset email: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send reset email"})
+		return
+	}
 
 	response := ForgotPasswordResponse{
 		Message: "Password reset instructions sent to your email",
@@ -377,7 +477,7 @@ func ForgotPassword(c *gin.Context) {
 func ResetPassword(c *gin.Context) {
 	var req ResetPasswordRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -421,14 +521,12 @@ type ValidateMLTokenResponse struct {
 func ValidateMLToken(c *gin.Context) {
 	var req ValidateMLTokenRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	// Create token validator and validate
-	tokenValidator := validation.NewTokenValidator()
-	isValid := tokenValidator.ValidateToken(req.Token)
+	isValid := validation.Default().ValidateToken(req.Token)
 
 	c.JSON(http.StatusOK, ValidateMLTokenResponse{IsValid: isValid})
 }
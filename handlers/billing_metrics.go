@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/jobs"
+	"github.com/gin-gonic/gin"
+)
+
+// GetBillingMetrics reports subscription and revenue metrics
+// @Summary Get billing metrics
+// @Description Reports active subscriptions by plan and source (stripe, app_store, google_play or organization), monthly recurring revenue by currency, new and churned subscriptions and failed payments this month. Computed from the stored subscriptions and Stripe webhook events without calling Stripe. MRR covers active and past-due Stripe subscriptions before discounts and tax, not trials or app store subscriptions (admin only)
+// @Tags admin
+// @Produce json
+// @Success 200 {object} jobs.BillingMetrics "Billing metrics"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/billing/metrics [get]
+func GetBillingMetrics(c *gin.Context) {
+	metrics, err := jobs.CollectBillingMetrics(database.DB, time.Now())
+	if err != nil {
+		log.Printf("Failed to compute billing metrics: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to compute billing metrics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, metrics)
+}
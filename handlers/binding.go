@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// errNotJSON rejects bodies not declared as JSON, which middleware.JSONLimits
+// does not bound
+var errNotJSON = errors.New("Content-Type must be application/json")
+
+// bindJSON decodes and validates the request body like ShouldBindJSON, but
+// only once middleware.JSONLimits has checked it, and also rejects unknown
+// fields when the route is marked strict
+func bindJSON(c *gin.Context, obj interface{}) error {
+	if !c.GetBool(middleware.CheckedJSONKey) {
+		return errNotJSON
+	}
+	if !c.GetBool(middleware.StrictJSONKey) {
+		return c.ShouldBindJSON(obj)
+	}
+
+	if c.Request == nil || c.Request.Body == nil {
+		return fmt.Errorf("invalid request")
+	}
+
+	dec := json.NewDecoder(c.Request.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(obj); err != nil {
+		return err
+	}
+
+	return binding.Validator.ValidateStruct(obj)
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/branding"
+	"github.com/gin-gonic/gin"
+)
+
+// OrgBrandingRequest represents the request body for setting an organization's
+// branding. Empty fields keep the ThinkInk defaults.
+type OrgBrandingRequest struct {
+	DisplayName  string `json:"display_name" binding:"max=100" example:"Northside Neurology"`
+	PrimaryColor string `json:"primary_color" example:"#1F4E79"`
+	AccentColor  string `json:"accent_color" example:"#F2A900"`
+	FooterText   string `json:"footer_text" binding:"max=300" example:"Northside Neurology Clinic, 12 High Street"`
+}
+
+// OrgBrandingResponse represents an organization's branding
+type OrgBrandingResponse struct {
+	Branding *models.OrgBranding `json:"branding"`
+	// Public address of the logo used in emails; only set when there is a logo
+	LogoURL string `json:"logo_url,omitempty" example:"http://localhost:8080/branding/1/logo?v=1767225600"`
+}
+
+func newOrgBrandingResponse(b *models.OrgBranding) OrgBrandingResponse {
+	response := OrgBrandingResponse{Branding: b}
+	if b != nil && b.HasLogo {
+		response.LogoURL = branding.LogoURL(b)
+	}
+	return response
+}
+
+// GetOrgBranding returns an organization's branding
+// @Summary Get an organization's branding
+// @Description Returns the display name, colors, footer text and logo details applied to the PDF exports and emails of an organization's members, or null if it uses the ThinkInk defaults (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} OrgBrandingResponse "Branding"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/orgs/{id}/branding [get]
+func GetOrgBranding(c *gin.Context) {
+	org, ok := organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	b, err := models.FindOrgBranding(database.DB, org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch branding"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newOrgBrandingResponse(b))
+}
+
+// SetOrgBranding sets an organization's display name, colors and footer text
+// @Summary Set an organization's branding
+// @Description Sets the display name, #RRGGBB primary and accent colors and footer text used in the PDF exports and emails of an organization's members. The logo is kept; upload it separately (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param branding body OrgBrandingRequest true "Branding"
+// @Success 200 {object} OrgBrandingResponse "Branding"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/orgs/{id}/branding [put]
+func SetOrgBranding(c *gin.Context) {
+	org, ok := organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	var req OrgBrandingRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	b := &models.OrgBranding{
+		OrganizationID: org.ID,
+		DisplayName:    req.DisplayName,
+		PrimaryColor:   req.PrimaryColor,
+		AccentColor:    req.AccentColor,
+		FooterText:     req.FooterText,
+	}
+	if err := b.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := models.UpsertOrgBranding(database.DB, b); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save branding"})
+		return
+	}
+
+	saved, err := models.FindOrgBranding(database.DB, org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch branding"})
+		return
+	}
+	auditOrgAction(c, c.MustGet("userID").(uint), org.ID, "organization.branding_update", orgResource(org.ID), nil)
+
+	c.JSON(http.StatusOK, newOrgBrandingResponse(saved))
+}
+
+// DeleteOrgBranding removes an organization's branding and logo
+// @Summary Remove an organization's branding
+// @Description Removes the branding and deletes the logo so the organization's members get the ThinkInk defaults again (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} MessageResponse "Branding removed"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/orgs/{id}/branding [delete]
+func DeleteOrgBranding(c *gin.Context) {
+	org, ok := organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	if err := branding.Delete(database.DB, org.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to remove branding"})
+		return
+	}
+	auditOrgAction(c, c.MustGet("userID").(uint), org.ID, "organization.branding_delete", orgResource(org.ID), nil)
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Branding removed"})
+}
+
+// UploadOrgLogo stores an organization's logo
+// @Summary Upload an organization's logo
+// @Description Stores a PNG or JPEG logo (max 512KB and 2000x2000 pixels) drawn at the top of the organization's PDF exports and emails, replacing any previous logo (admin only)
+// @Tags admin
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param logo formData file true "PNG or JPEG logo"
+// @Success 200 {object} OrgBrandingResponse "Branding"
+// @Failure 400 {object} ErrorResponse "Bad Request - Missing or invalid logo"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/orgs/{id}/branding/logo [put]
+func UploadOrgLogo(c *gin.Context) {
+	org, ok := organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	// Leave room for the multipart framing around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, branding.MaxLogoBytes+64<<10)
+	fileHeader, err := c.FormFile("logo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "No logo uploaded (max 512KB)"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to read file"})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, branding.MaxLogoBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to read file"})
+		return
+	}
+
+	saved, err := branding.SaveLogo(database.DB, org.ID, data)
+	if err != nil {
+		if errors.Is(err, branding.ErrInvalidLogo) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save logo"})
+		return
+	}
+	auditOrgAction(c, c.MustGet("userID").(uint), org.ID, "organization.logo_upload", orgResource(org.ID), gin.H{"bytes": len(data)})
+
+	c.JSON(http.StatusOK, newOrgBrandingResponse(saved))
+}
+
+// DeleteOrgLogo removes an organization's logo
+// @Summary Remove an organization's logo
+// @Description Deletes the organization's logo and keeps the rest of its branding (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} MessageResponse "Logo removed"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization or logo not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/orgs/{id}/branding/logo [delete]
+func DeleteOrgLogo(c *gin.Context) {
+	org, ok := organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	b, err := models.FindOrgBranding(database.DB, org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch branding"})
+		return
+	}
+	if b == nil || !b.HasLogo {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Organization has no logo"})
+		return
+	}
+	if err := branding.DeleteLogo(database.DB, b); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to remove logo"})
+		return
+	}
+	auditOrgAction(c, c.MustGet("userID").(uint), org.ID, "organization.logo_delete", orgResource(org.ID), nil)
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Logo removed"})
+}
+
+// GetOrgLogo serves an organization's logo
+// @Summary Get an organization's logo
+// @Description Serves the organization's logo image. It is public so that email clients can load it.
+// @Tags organizations
+// @Produce image/png
+// @Produce image/jpeg
+// @Param id path string true "Organization ID"
+// @Success 200 {file} file "Logo"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 404 {object} ErrorResponse "Logo not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /branding/{id}/logo [get]
+func GetOrgLogo(c *gin.Context) {
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid organization ID"})
+		return
+	}
+
+	b, err := models.FindOrgBranding(database.DB, uint(orgID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch branding"})
+		return
+	}
+	if b == nil || !b.HasLogo {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Logo not found"})
+		return
+	}
+	data, err := branding.ReadLogo(b)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to read logo"})
+		return
+	}
+
+	// Uploads change the version parameter of the logo URL, so it can be cached
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Data(http.StatusOK, b.LogoContentType, data)
+}
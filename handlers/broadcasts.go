@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/jobs"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/notifications"
+	"github.com/gin-gonic/gin"
+	"gorm.io/datatypes"
+)
+
+// CreateBroadcastRequest represents the request body for sending a broadcast.
+// organization_id is required for the organization audience and cohort for the cohort audience.
+// Omitting scheduled_at sends the broadcast immediately.
+type CreateBroadcastRequest struct {
+	Title          string               `json:"title" binding:"required,max=200" example:"Scheduled maintenance"`
+	Body           string               `json:"body" binding:"required" example:"ThinkInk will be unavailable on Sunday from 02:00 to 03:00 UTC."`
+	Audience       string               `json:"audience" binding:"required,oneof=all cohort organization" example:"all"`
+	OrganizationID *uint                `json:"organization_id" example:"1"`
+	Cohort         *models.CohortFilter `json:"cohort"`
+	SendEmail      bool                 `json:"send_email" example:"false"`
+	SendPush       bool                 `json:"send_push" example:"false"`
+	ScheduledAt    *time.Time           `json:"scheduled_at" example:"2026-11-01T09:00:00Z"`
+}
+
+// BroadcastsResponse represents a list of broadcasts
+type BroadcastsResponse struct {
+	Broadcasts []models.Broadcast `json:"broadcasts"`
+}
+
+// BroadcastResponse represents a broadcast with its delivery stats
+type BroadcastResponse struct {
+	Broadcast models.Broadcast `json:"broadcast"`
+	ReadCount int64            `json:"read_count" example:"42"`
+}
+
+// CreateBroadcast schedules a broadcast notification
+// @Summary Send a broadcast
+// @Description Sends an in-app notification to all users, a cohort, or an organization's members, optionally also by email and push. Broadcasts are sent immediately or at scheduled_at (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param broadcast body CreateBroadcastRequest true "Broadcast details"
+// @Success 201 {object} models.Broadcast "Scheduled broadcast"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/broadcasts [post]
+func CreateBroadcast(c *gin.Context) {
+	// Get authenticated user ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	var req CreateBroadcastRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	broadcast := &models.Broadcast{
+		Title:       req.Title,
+		Body:        req.Body,
+		Audience:    req.Audience,
+		SendEmail:   req.SendEmail,
+		SendPush:    req.SendPush,
+		ScheduledAt: time.Now(),
+		CreatedBy:   userID.(uint),
+	}
+	if req.ScheduledAt != nil {
+		broadcast.ScheduledAt = *req.ScheduledAt
+	}
+
+	switch req.Audience {
+	case models.AudienceOrganization:
+		if req.OrganizationID == nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "organization_id is required for the organization audience"})
+			return
+		}
+		if _, err := models.FindOrganizationByID(database.DB, *req.OrganizationID); err != nil {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Organization not found"})
+			return
+		}
+		broadcast.OrganizationID = req.OrganizationID
+	case models.AudienceCohort:
+		if req.Cohort == nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "cohort is required for the cohort audience"})
+			return
+		}
+		cohort, err := json.Marshal(req.Cohort)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid cohort"})
+			return
+		}
+		broadcast.Cohort = datatypes.JSON(cohort)
+	}
+
+	if err := models.CreateBroadcast(database.DB, broadcast); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create broadcast"})
+		return
+	}
+
+	// Send immediately instead of waiting for the next scheduler run,
+	// unless a dedicated worker process handles deliveries
+	if jobs.Embedded() && !broadcast.ScheduledAt.After(time.Now()) {
+		go func() {
+			if err := notifications.SendDueBroadcasts(context.Background(), database.DB); err != nil {
+				log.Printf("Failed to send broadcast %d: %v", broadcast.ID, err)
+			}
+		}()
+	}
+
+	c.JSON(http.StatusCreated, broadcast)
+}
+
+// GetBroadcasts lists broadcasts
+// @Summary List broadcasts
+// @Description Lists the 100 most recent broadcasts with their status and delivery stats (admin only)
+// @Tags admin
+// @Produce json
+// @Success 200 {object} BroadcastsResponse "Broadcasts"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/broadcasts [get]
+func GetBroadcasts(c *gin.Context) {
+	broadcasts, err := models.FindBroadcasts(database.DB, 100)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch broadcasts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, BroadcastsResponse{Broadcasts: broadcasts})
+}
+
+// GetBroadcast returns a broadcast with its delivery stats
+// @Summary Get broadcast stats
+// @Description Returns a broadcast with its delivery stats: recipients, in-app deliveries, emails queued, pushes sent or failed, and how many recipients have read it (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path int true "Broadcast ID"
+// @Success 200 {object} BroadcastResponse "Broadcast and stats"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Broadcast not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/broadcasts/{id} [get]
+func GetBroadcast(c *gin.Context) {
+	broadcast, ok := broadcastFromParam(c)
+	if !ok {
+		return
+	}
+
+	reads, err := models.CountBroadcastReads(database.DB, broadcast.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch broadcast stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, BroadcastResponse{Broadcast: *broadcast, ReadCount: reads})
+}
+
+// CancelBroadcast cancels a scheduled broadcast
+// @Summary Cancel a broadcast
+// @Description Cancels a broadcast that has not started sending (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path int true "Broadcast ID"
+// @Success 200 {object} MessageResponse "Broadcast canceled"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Broadcast not found"
+// @Failure 409 {object} ErrorResponse "Conflict - Broadcast already sent"
+// @Security BearerAuth
+// @Router /admin/broadcasts/{id} [delete]
+func CancelBroadcast(c *gin.Context) {
+	broadcast, ok := broadcastFromParam(c)
+	if !ok {
+		return
+	}
+
+	if err := broadcast.Cancel(database.DB); err != nil {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Broadcast canceled"})
+}
+
+// broadcastFromParam loads the broadcast named by the :id path parameter.
+// It writes the error response and returns false on failure.
+func broadcastFromParam(c *gin.Context) (*models.Broadcast, bool) {
+	broadcastID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid broadcast ID"})
+		return nil, false
+	}
+
+	broadcast, err := models.FindBroadcastByID(database.DB, uint(broadcastID))
+	if err != nil {
+		if err.Error() == "broadcast not found" {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Broadcast not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch broadcast"})
+		return nil, false
+	}
+
+	return broadcast, true
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/demo"
+	"github.com/gin-gonic/gin"
+)
+
+// DemoUsersResponse represents the demo users
+type DemoUsersResponse struct {
+	Users []models.User `json:"users"`
+}
+
+// DemoSubscriptionsResponse represents the subscription state of every demo user
+type DemoSubscriptionsResponse struct {
+	Subscriptions map[uint]SubscriptionResponse `json:"subscriptions"`
+}
+
+// GetDemoUsers lists the demo users
+// @Summary List demo users
+// @Description Lists the deterministic fake users of the demo dataset, covering every subscription state. Only available outside production; nothing is read from the database.
+// @Tags demo
+// @Produce json
+// @Success 200 {object} DemoUsersResponse "Demo users"
+// @Router /demo/users [get]
+func GetDemoUsers(c *gin.Context) {
+	c.JSON(http.StatusOK, DemoUsersResponse{Users: demo.Default().Users})
+}
+
+// GetDemoUser returns a demo user
+// @Summary Get a demo user
+// @Description Returns a demo user in the shape of GET /user/{id}. Only available outside production.
+// @Tags demo
+// @Produce json
+// @Param id path int true "Demo user ID"
+// @Success 200 {object} UserResponse "Demo user"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Router /demo/users/{id} [get]
+func GetDemoUser(c *gin.Context) {
+	user, ok := findDemoUser(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, UserResponse{User: *user})
+}
+
+// GetDemoUserReports lists a demo user's reports
+// @Summary List a demo user's reports
+// @Description Returns a page of a demo user's unarchived reports, newest first, in the shape of GET /reports. Only available outside production.
+// @Tags demo
+// @Produce json
+// @Param id path int true "Demo user ID"
+// @Param limit query int false "Page size (max 100)" default(50)
+// @Param offset query int false "Number of reports to skip (at most 10000)" default(0)
+// @Success 200 {object} ReportsResponse "Demo reports"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID or paging"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Router /demo/users/{id}/reports [get]
+func GetDemoUserReports(c *gin.Context) {
+	user, ok := findDemoUser(c)
+	if !ok {
+		return
+	}
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+	if page.Cursor != "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Demo reports are paged by offset only"})
+		return
+	}
+
+	reports := demo.Default().UserReports(user.ID)
+	total := len(reports)
+	start := min(page.Offset, total)
+	end := min(start+page.Limit, total)
+	c.JSON(http.StatusOK, ReportsResponse{
+		Reports: reports[start:end],
+		Pagination: newPagination(page, models.PageInfo{
+			Total:   int64(total),
+			HasMore: end < total,
+		}),
+	})
+}
+
+// GetDemoReport returns a demo report
+// @Summary Get a demo report
+// @Description Returns a demo report with EEG content, tags and metadata in the shape of GET /reports/{id}. Only available outside production.
+// @Tags demo
+// @Produce json
+// @Param id path int true "Demo report ID"
+// @Success 200 {object} ReportResponse "Demo report"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 404 {object} ErrorResponse "Report not found"
+// @Router /demo/reports/{id} [get]
+func GetDemoReport(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid report ID"})
+		return
+	}
+	report, found := demo.Default().Report(uint(id))
+	if !found {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Report not found"})
+		return
+	}
+	c.JSON(http.StatusOK, ReportResponse{Report: *report})
+}
+
+// GetDemoUserSubscription returns a demo user's subscription
+// @Summary Get a demo user's subscription
+// @Description Returns a demo user's subscription in the shape of GET /payment/subscription. Only available outside production.
+// @Tags demo
+// @Produce json
+// @Param id path int true "Demo user ID"
+// @Success 200 {object} SubscriptionResponse "Demo subscription"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Router /demo/users/{id}/subscription [get]
+func GetDemoUserSubscription(c *gin.Context) {
+	user, ok := findDemoUser(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, demoSubscriptionResponse(demo.Default().Subscriptions[user.ID]))
+}
+
+// GetDemoSubscriptions lists the subscription state of every demo user
+// @Summary List demo subscriptions
+// @Description Returns the subscription of every demo user keyed by user ID: active, trialing, cancelling at period end, past due, canceled, expired and none. Only available outside production.
+// @Tags demo
+// @Produce json
+// @Success 200 {object} DemoSubscriptionsResponse "Demo subscriptions"
+// @Router /demo/subscriptions [get]
+func GetDemoSubscriptions(c *gin.Context) {
+	response := DemoSubscriptionsResponse{Subscriptions: make(map[uint]SubscriptionResponse)}
+	for id, subscription := range demo.Default().Subscriptions {
+		response.Subscriptions[id] = demoSubscriptionResponse(subscription)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// findDemoUser reads the id path parameter and finds the demo user. It writes
+// the error response and returns false on failure.
+func findDemoUser(c *gin.Context) (*models.User, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return nil, false
+	}
+	user, found := demo.Default().User(uint(id))
+	if !found {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		return nil, false
+	}
+	return user, true
+}
+
+func demoSubscriptionResponse(subscription *demo.Subscription) SubscriptionResponse {
+	if subscription.SubscriptionID == "" {
+		return SubscriptionResponse{HasSubscription: false}
+	}
+	return SubscriptionResponse{
+		HasSubscription:   subscription.Active(),
+		SubscriptionID:    subscription.SubscriptionID,
+		PlanID:            subscription.PlanID,
+		Status:            subscription.Status,
+		CancelAtPeriodEnd: subscription.CancelAtPeriodEnd,
+		CurrentPeriodEnd:  subscription.CurrentPeriodEnd,
+	}
+}
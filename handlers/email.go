@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// EmailEventRequest represents a bounce or complaint notification from the email provider
+type EmailEventRequest struct {
+	Type       string `json:"type" binding:"required,oneof=bounce complaint" example:"bounce"`
+	Email      string `json:"email" binding:"required,email" example:"user@example.com"`
+	BounceType string `json:"bounce_type" example:"hard"`
+	Reason     string `json:"reason" example:"550 5.1.1 mailbox does not exist"`
+}
+
+// EmailWebhookHandler processes bounce and complaint events from the email provider
+// @Summary Process email provider events
+// @Description Adds hard-bounced and complaining addresses to the suppression list. Soft bounces are ignored.
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param X-Webhook-Secret header string true "Shared webhook secret"
+// @Param event body EmailEventRequest true "Email event"
+// @Success 200 {object} WebhookResponse "Event processed"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Invalid webhook secret"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /email/webhook [post]
+func EmailWebhookHandler(c *gin.Context) {
+	secret := utils.GetEnvWithDefault("EMAIL_WEBHOOK_SECRET", "")
+	provided := c.GetHeader("X-Webhook-Secret")
+	if secret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(provided)) != 1 {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid webhook secret"})
+		return
+	}
+
+	var req EmailEventRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	reason := models.SuppressionReasonComplaint
+	if req.Type == "bounce" {
+		// Soft bounces (full mailbox, greylisting) are handled by the retry queue
+		if req.BounceType == "soft" {
+			log.Printf("Soft bounce for %s ignored: %s", req.Email, req.Reason)
+			c.JSON(http.StatusOK, WebhookResponse{Received: true})
+			return
+		}
+		reason = models.SuppressionReasonBounce
+	}
+
+	if err := models.SuppressEmail(database.DB, req.Email, reason, req.Reason); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to record email event"})
+		return
+	}
+
+	log.Printf("Suppressed %s after %s", req.Email, req.Type)
+	c.JSON(http.StatusOK, WebhookResponse{Received: true})
+}
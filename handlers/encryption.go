@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/encryption"
+	"github.com/gin-gonic/gin"
+)
+
+// ReportEncryptionRequest represents the request body for turning report text encryption on or off
+type ReportEncryptionRequest struct {
+	Enabled *bool `json:"enabled" binding:"required" example:"true"`
+}
+
+// SetReportEncryption turns per-user encryption of report text on or off
+// @Summary Set report text encryption
+// @Description Turns encryption of the authenticated user's report translations on or off. When on, translated text is stored encrypted with a key derived for this user from the KMS and decrypted only when served to authenticated requests; existing reports are encrypted or decrypted to match. Encrypted text is not covered by full-text search.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param encryption body ReportEncryptionRequest true "Encryption setting"
+// @Success 200 {object} MessageResponse "Setting updated"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Failure 503 {object} ErrorResponse "Encryption is not configured on this server"
+// @Security BearerAuth
+// @Router /user/encryption [put]
+func SetReportEncryption(c *gin.Context) {
+	// Get authenticated user ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	var req ReportEncryptionRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, err := models.FindUserByID(database.DB, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch user"})
+		return
+	}
+
+	if err := encryption.SetReportTextEncryption(c.Request.Context(), database.DB, user, *req.Enabled); err != nil {
+		if errors.Is(err, encryption.ErrNotConfigured) {
+			c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "Report encryption is not available"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update report encryption"})
+		return
+	}
+
+	message := "Report text encryption disabled"
+	if *req.Enabled {
+		message = "Report text encryption enabled"
+	}
+	c.JSON(http.StatusOK, MessageResponse{Message: message})
+}
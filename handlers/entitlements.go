@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/entitlements"
+	"github.com/gin-gonic/gin"
+)
+
+// GetEntitlements returns what the authenticated user's plan allows
+// @Summary Get entitlements
+// @Description Returns the plan the authenticated user's limits are based on, its quotas and the capabilities it includes (pdf_export, sharing, ml_api), so clients can hide what the plan does not allow. Plans are mapped to capabilities by PLAN_CAPABILITIES.
+// @Tags usage
+// @Produce json
+// @Success 200 {object} entitlements.Entitlements "Entitlements"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /entitlements [get]
+func GetEntitlements(c *gin.Context) {
+	ent, err := entitlements.ForUser(database.DB, c.GetUint("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to load entitlements"})
+		return
+	}
+	c.JSON(http.StatusOK, ent)
+}
+
+// requireCapability checks that the authenticated user's plan includes a
+// capability. It writes a 402 with where to upgrade and returns false if not.
+func requireCapability(c *gin.Context, capability string) bool {
+	userID := c.GetUint("userID")
+	ent, err := entitlements.ForUser(database.DB, userID)
+	if err != nil {
+		log.Printf("Failed to load entitlements of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to load entitlements"})
+		return false
+	}
+	if !ent.Allows(capability) {
+		c.JSON(http.StatusPaymentRequired, ent.Unavailable(capability))
+		return false
+	}
+	return true
+}
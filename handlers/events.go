@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/fileevents"
+	"github.com/gin-gonic/gin"
+)
+
+// eventHeartbeat is how often an idle stream sends a comment, keeping
+// proxies from closing it
+const eventHeartbeat = 15 * time.Second
+
+// StreamEvents streams processing progress of the user's uploaded files
+// @Summary Stream file processing events
+// @Description Streams Server-Sent Events as the authenticated user's uploaded files move through processing, so clients need not poll GET /files/{id}/status. Each event is named after the state reached: uploaded, translating, report_ready or failed; its data is a JSON object with file_id, job_id, state, report_id once the report is ready, error for failed files and the time at which the state was reached. Pass file_id to only receive events of one file. Idle streams receive a comment every 15 seconds. Events are best effort: after reconnecting, check GET /files/{id}/status for anything missed.
+// @Tags files
+// @Produce text/event-stream
+// @Param file_id query int false "Only stream events of this file"
+// @Success 200 {object} models.FileEvent "Event stream"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid file ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 503 {object} ErrorResponse "Event streaming unavailable"
+// @Security BearerAuth
+// @Router /events [get]
+func StreamEvents(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	var fileID uint64
+	if raw := c.Query("file_id"); raw != "" {
+		var err error
+		if fileID, err = strconv.ParseUint(raw, 10, 64); err != nil || fileID == 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid file ID"})
+			return
+		}
+	}
+
+	broker := fileevents.Default()
+	if broker == nil {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "Event streaming is unavailable"})
+		return
+	}
+	events, unsubscribe := broker.Subscribe(userID.(uint))
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Keep reverse proxies such as nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": heartbeat\n\n")
+			return err == nil
+		case event := <-events:
+			if fileID == 0 || uint64(event.FileID) == fileID {
+				c.SSEvent(event.State, event)
+			}
+			return true
+		}
+	})
+}
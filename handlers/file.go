@@ -1,49 +1,83 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"math"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
 	"github.com/ThinkInkTeam/thinkink-core-backend/database"
 	"github.com/ThinkInkTeam/thinkink-core-backend/models"
-	"github.com/ThinkInkTeam/thinkink-core-backend/services"
-	"github.com/google/uuid"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/health"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/ingest"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/jobs"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/plans"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
 
 	"net/http"
-	"os"
-	"path/filepath"
 
 	"github.com/gin-gonic/gin"
 )
 
 const (
-	UploadDir     = "./uploads"
-	MaxUploadSize = 50 << 20
+	UploadDir = "./uploads"
 )
 
 // FileUploadResponse represents a successful file upload response
 type FileUploadResponse struct {
-	Message       string `json:"message" example:"File processed successfully"`
-	FileID        uint   `json:"file_id" example:"1"`
-	ReportID      uint   `json:"report_id" example:"2"`
-	Description   string `json:"description" example:"Sample brain activity data"`
-	MatchingScale int    `json:"matching_scale" example:"7"`
+	Message string `json:"message" example:"File stored; processing queued"`
+	FileID  uint   `json:"file_id" example:"1"`
+	// The background job turning the file into a report, and where to follow it
+	JobID     uint   `json:"job_id,omitempty" example:"31"`
+	StatusURL string `json:"status_url,omitempty" example:"/files/1/status"`
+	// Set for an existing report returned for a duplicate upload
+	ReportID      uint     `json:"report_id,omitempty" example:"2"`
+	Description   string   `json:"description,omitempty" example:"Sample brain activity data"`
+	MatchingScale int      `json:"matching_scale" example:"7"`
+	Warnings      []string `json:"warnings,omitempty"`
+	// Set while the file waits for processing, with how long that should take
+	Queued     bool `json:"queued,omitempty" example:"true"`
+	ETASeconds int  `json:"eta_seconds,omitempty" example:"12"`
+	// Set when the file was uploaded before: Duplicate when the existing
+	// report is returned, DuplicateOfID when a new report was created anyway
+	Duplicate     bool  `json:"duplicate,omitempty" example:"false"`
+	DuplicateOfID *uint `json:"duplicate_of_id,omitempty" example:"12"`
 }
 
 // UploadSignalFile handles the upload of signal files.
 // @Summary Upload a signal file
-// @Description Uploads a signal file and stores metadata in the database with matching scale
+// @Description Uploads a signal file and queues it for processing: the response returns as soon as the file is stored, with the ID of the background job that translates the recording and creates the report. Follow it at GET /files/{id}/status; the user is notified when the report is ready or processing failed. Per-channel signal quality is stored on the report, and the job carries warnings when quality is too low for a reliable translation. A file the user uploaded before, recognized by its SHA-256, returns the existing report instead of creating another one, unless allow_duplicate is true; the new report then carries duplicate_of_id. When a malware scanner is configured, files are scanned before any processing; infected files are quarantined, listed with status quarantined and the user is notified.
 // @Tags files
 // @Accept multipart/form-data
 // @Produce json
 // @Param file formData file true "File to upload"
 // @Param matchingScale formData int false "Matching scale (1-10)" default(5)
-// @Param description formData string false "Description of the file" default("")
-// @Success 200 {object} FileUploadResponse "File uploaded successfully"
-// @Failure 400 {object} ErrorResponse "Bad Request - No file uploaded, file too large, or invalid matching scale"
+// @Param metadata formData string false "Recording metadata as a JSON object with optional session_notes, device_id, electrode_montage and recording_conditions strings (each up to 500 characters)"
+// @Param template_id formData int false "Report template the report's structured notes follow"
+// @Param X-Recording-ID header string false "ID the device assigned to the recording; a re-sent recording returns the first upload's result instead of a new report"
+// @Param X-Device-ID header string false "Device the recording comes from; taken from the token for device tokens"
+// @Param notes formData string false "Structured notes as a JSON object of template sections and their field values; defaults to the file's own notes object"
+// @Param allow_duplicate formData bool false "Create a new report, marked as a duplicate, even if the same file was uploaded before" default(false)
+// @Param sha256 formData string false "Hex SHA-256 of the file; the upload is refused when the file received does not match"
+// @Success 200 {object} FileUploadResponse "The existing report (duplicate=true) if the same file was uploaded before"
+// @Success 202 {object} FileUploadResponse "File stored; processing queued"
+// @Failure 400 {object} ErrorResponse "Bad Request - No file uploaded, checksum mismatch, invalid matching scale, metadata, template or notes"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 402 {object} plans.LimitError "Payment Required - Upload or storage quota exceeded"
+// @Failure 413 {object} plans.LimitError "Request Entity Too Large - The file exceeds the upload size limit (ErrorResponse) or is larger than the plan allows"
+// @Failure 409 {object} ErrorResponse "Conflict - The recording is already being uploaded; see Retry-After"
+// @Failure 415 {object} ErrorResponse "Unsupported Media Type - The file is an executable, not an allowed format, or its content does not match its extension or media type"
+// @Failure 422 {object} EEGValidationResponse "Unprocessable Entity - The recording is not valid EEG data (issues lists what is wrong), or the file contains malware and was quarantined"
+// @Failure 429 {object} plans.LimitError "Too Many Requests - Rate limit exceeded, or translation queue is full (free plan); see Retry-After"
 // @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Failure 503 {object} ErrorResponse "Service Unavailable - Translation service or malware scanner is down; see Retry-After"
 // @Security BearerAuth
 // @Router /upload [post]
 func UploadSignalFile(c *gin.Context) {
@@ -53,101 +87,689 @@ func UploadSignalFile(c *gin.Context) {
 		return
 	}
 
-	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxUploadSize)
-	if err := c.Request.ParseMultipartForm(MaxUploadSize); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "File too large (max 50MB)"})
+	// Fail fast while the translation service is down instead of reading the
+	// upload and storing a recording that cannot be translated
+	if serviceUnavailable(c, health.ComponentMLService, "Translation service is unavailable, please retry later") {
 		return
 	}
 
-	file, err := c.FormFile("file")
+	// Re-sent recordings get the result of their first upload instead of a new report
+	recording, ok := claimRecording(c, userID.(uint))
+	if !ok {
+		return
+	}
+	if recording != nil {
+		defer func() {
+			if recording.Status != models.DeviceRecordingCompleted {
+				if err := recording.Release(database.DB); err != nil {
+					log.Printf("Failed to release recording %s of device %q: %v", recording.RecordingID, recording.DeviceID, err)
+				}
+			}
+		}()
+	}
+
+	settings := config.Current()
+
+	// The file is streamed to storage and hashed on the way rather than
+	// buffered, so large files do not grow memory use. It is removed again
+	// unless the upload gets as far as recording it.
+	form, ok := readUploadForm(c, userID.(uint), int64(settings.MaxUploadSizeMB)<<20, recordingUpload)
+	if !ok {
+		return
+	}
+	stored := false
+	defer func() {
+		if !stored {
+			form.remove()
+		}
+	}()
+
+	// A file corrupted on the way would only produce a garbage translation
+	if !verifyChecksum(c, form) {
+		return
+	}
+
+	// Scan for malware before anything reads the file; infected files are quarantined
+	if !scanUpload(c, userID.(uint), form) {
+		return
+	}
+
+	// The same file uploaded again returns the existing report, before it
+	// counts against any quota, unless the user asks for a new one
+	original, err := models.FindDuplicateReport(database.DB, userID.(uint), form.ContentHash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to check for duplicates"})
+		return
+	}
+	if original != nil && form.value("allow_duplicate") != "true" {
+		respondDuplicateUpload(c, recording, original)
+		return
+	}
+
+	// Enforce the plan's monthly upload and storage quotas
+	limits, err := plans.LimitsForUser(database.DB, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to load plan limits"})
+		return
+	}
+	usage, err := plans.CurrentUsage(database.DB, userID.(uint))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "No file uploaded"})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to load usage"})
+		return
+	}
+	if err := plans.CheckUpload(limits, usage, form.Size); err != nil {
+		var quotaErr *plans.QuotaError
+		if !errors.As(err, &quotaErr) {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to check quotas"})
+			return
+		}
+		respondQuotaError(c, limits, usage, quotaErr)
+		return
+	}
+	canTranslate := plans.CanTranslate(limits, usage)
+
+	// Apply backpressure when the translation queue is backed up: free plans are
+	// asked to retry later, paid plans are queued behind it
+	pool := ingest.Default()
+	backedUp := canTranslate && settings.UploadQueueLimit > 0 && pool.Depth() >= settings.UploadQueueLimit
+	if backedUp && limits.Plan == config.PlanFree {
+		respondLimitError(c, http.StatusTooManyRequests, plans.QueueFull(limits, pool.Depth(), settings.UploadQueueLimit, pool.ETA()))
+		return
+	}
+
+	// Check the recording before it is queued, so what is wrong with it is
+	// reported right away rather than by a failed job
+	if !validateRecording(c, form) {
 		return
 	}
 
 	// Get matching scale from form, default to 5 if not provided
-	matchingScaleStr := c.DefaultPostForm("matchingScale", "5")
+	matchingScaleStr := form.valueOr("matchingScale", "5")
 	matchingScale, err := strconv.Atoi(matchingScaleStr)
 	if err != nil || matchingScale < 1 || matchingScale > 10 {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Matching scale must be between 1 and 10"})
 		return
 	}
 
-	if err := os.MkdirAll(UploadDir, os.ModePerm); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Could not create upload directory"})
-		return
+	// Recording details are sent as a JSON part and stored as report metadata
+	var metadata datatypes.JSON
+	if raw := form.value("metadata"); raw != "" {
+		metadata, err = models.ParseRecordingMetadata([]byte(raw))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
 	}
 
-	ext := filepath.Ext(file.Filename)
-	filename := fmt.Sprintf("%d-%s%s", userID, uuid.New().String(), ext)
-	filePath := filepath.Join(UploadDir, filename)
+	// A report template gives the report structured notes, sent as a JSON part
+	// or included in the file
+	var template *models.ReportTemplate
+	var notes map[string]interface{}
+	if raw := form.value("template_id"); raw != "" {
+		templateID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid template ID"})
+			return
+		}
+		template, err = models.FindReportTemplate(database.DB, uint(templateID))
+		if err != nil || template.ArchivedAt != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Report template not found"})
+			return
+		}
+		if raw := form.value("notes"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &notes); err != nil || notes == nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: "notes must be a JSON object"})
+				return
+			}
+		}
+	}
 
-	if err := c.SaveUploadedFile(file, filePath); err != nil {
+	signalFile, err := models.CreateSingleFile(userID.(uint), form.Filename, form.Path, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to process file: " + err.Error()})
+		return
+	}
+	signalFile.ContentHash = form.ContentHash
+	if err := signalFile.Save(database.DB); err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save file"})
 		return
 	}
 
-	// Get description from form, default to empty string if not provided
-	description := ""
+	// Translation and report creation run in the background; the upload
+	// returns as soon as the file is stored
+	job := &models.UploadJob{
+		UserID:        userID.(uint),
+		FileID:        signalFile.ID,
+		MatchingScale: matchingScale,
+		Metadata:      metadata,
+		Translate:     canTranslate,
+	}
+	if template != nil {
+		job.TemplateID = &template.ID
+	}
+	if notes != nil {
+		if job.Notes, err = json.Marshal(notes); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "notes must be a JSON object"})
+			return
+		}
+	}
+	if original != nil {
+		job.DuplicateOfID = &original.ID
+	}
+	if recording != nil {
+		job.DeviceRecordingID = &recording.ID
+	}
+	if err := job.Create(database.DB); err != nil {
+		_ = signalFile.Delete(database.DB)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to queue file for processing"})
+		return
+	}
+	stored = true
+	models.PublishFileEvent(database.DB, job, models.FileEventUploaded)
+	if jobs.Embedded() {
+		go jobs.StartUploadJob(context.WithoutCancel(c.Request.Context()), database.DB, pool, job, settings.MLServiceAddress)
+	}
 
-	// If no description provided, try to get translation from ML server
-	if description == "" {
-		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
-			// Connect to translation service
-			translationClient, err := services.NewTranslationClient("ml-service:50052")
-			if err == nil {
-				defer translationClient.Close()
-				fileData, err := os.ReadFile(filePath)
-				if err != nil {
-					c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to read file"})
-					return
-				}
-				// Get translation using the file data
-				translations, err := translationClient.TranslateEEGFromBytes(authHeader, fileData)
-				if err == nil && len(translations) > 0 {
-					description = strings.Join(translations, " ")
-				}
-			}
+	var warnings []string
+	if original != nil {
+		warnings = append(warnings, fmt.Sprintf("The same file was uploaded before as report %d", original.ID))
+	}
+	if !canTranslate {
+		warnings = append(warnings, "Monthly translation quota exceeded; the file will be stored without a translation")
+	}
+
+	respondUpload(c, recording, http.StatusAccepted, FileUploadResponse{
+		Message:       "File stored; processing queued",
+		FileID:        signalFile.ID,
+		JobID:         job.ID,
+		StatusURL:     fmt.Sprintf("/files/%d/status", signalFile.ID),
+		MatchingScale: matchingScale,
+		Warnings:      warnings,
+		Queued:        true,
+		ETASeconds:    int(math.Ceil(pool.ETA().Seconds())),
+		DuplicateOfID: job.DuplicateOfID,
+	})
+}
+
+// respondDuplicateUpload answers an upload of a file the user uploaded before
+// with the report created from it
+func respondDuplicateUpload(c *gin.Context, recording *models.DeviceRecording, original *models.Report) {
+	if !openReport(c, original) {
+		return
+	}
+	if !recordReportAccess(c, original, models.AccessView, nil) {
+		return
+	}
+	response := FileUploadResponse{
+		Message:       "File was uploaded before; returning the existing report",
+		ReportID:      original.ID,
+		Description:   original.Description,
+		MatchingScale: original.MatchingScale,
+		Duplicate:     true,
+	}
+	if original.SourceFileID != nil {
+		response.FileID = *original.SourceFileID
+	}
+	respondUpload(c, recording, http.StatusOK, response)
+}
+
+// maxRecordingIDLength bounds device and recording IDs
+const maxRecordingIDLength = 100
+
+// claimRecording claims the recording named by X-Recording-ID for this
+// upload. Device tokens identify the device; other clients may name it in
+// X-Device-ID. A recording uploaded before is answered with the stored result
+// and X-Idempotent-Replay: true. It returns nil without X-Recording-ID, and
+// writes the response and returns false when the upload must stop.
+func claimRecording(c *gin.Context, userID uint) (*models.DeviceRecording, bool) {
+	recordingID := c.GetHeader("X-Recording-ID")
+	if recordingID == "" {
+		return nil, true
+	}
+	deviceID := c.GetString("deviceID")
+	if deviceID == "" {
+		deviceID = c.GetHeader("X-Device-ID")
+	}
+	if len(recordingID) > maxRecordingIDLength || len(deviceID) > maxRecordingIDLength {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("X-Recording-ID and X-Device-ID must be at most %d characters", maxRecordingIDLength)})
+		return nil, false
+	}
+
+	recording, replay, err := models.ClaimDeviceRecording(database.DB, userID, deviceID, recordingID)
+	if err != nil {
+		if errors.Is(err, models.ErrRecordingInProgress) {
+			c.Header("Retry-After", "30")
+			c.JSON(http.StatusConflict, ErrorResponse{Error: "Recording is already being uploaded"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to check recording"})
+		return nil, false
+	}
+	if replay {
+		c.Header("X-Idempotent-Replay", "true")
+		c.Data(recording.ResponseStatus, "application/json; charset=utf-8", recording.Response)
+		return nil, false
+	}
+	return recording, true
+}
+
+// respondUpload writes the upload's response and stores it on the claimed
+// recording for retransmissions
+func respondUpload(c *gin.Context, recording *models.DeviceRecording, status int, response FileUploadResponse) {
+	if recording != nil {
+		var reportID *uint
+		if response.ReportID != 0 {
+			reportID = &response.ReportID
 		}
+		if body, err := json.Marshal(response); err != nil {
+			log.Printf("Failed to encode upload response: %v", err)
+		} else if err := recording.Complete(database.DB, reportID, status, datatypes.JSON(body)); err != nil {
+			log.Printf("Failed to store result of recording %s: %v", recording.RecordingID, err)
+		}
+	}
+	c.JSON(status, response)
+}
+
+// FilesResponse represents a page of uploaded files
+type FilesResponse struct {
+	Files      []models.SingleFile `json:"files"`
+	Pagination Pagination          `json:"pagination"`
+}
+
+// parseFileFilter reads the from, to, status and state query parameters. Dates are
+// RFC 3339 timestamps or YYYY-MM-DD days; a day given as to includes that
+// whole day. It writes the error response and returns false on failure.
+func parseFileFilter(c *gin.Context) (models.FileFilter, bool) {
+	var filter models.FileFilter
+
+	var ok bool
+	if filter.From, ok = parseDateQuery(c, "from", false); !ok {
+		return filter, false
+	}
+	if filter.To, ok = parseDateQuery(c, "to", true); !ok {
+		return filter, false
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "from must be before to"})
+		return filter, false
 	}
 
-	signalFile, err := models.CreateSingleFile(
-		userID.(uint),
-		file.Filename,
-		filePath,
-		description,
-	)
+	if status := c.Query("status"); status != "" {
+		if !models.ValidFileStatus(status) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "status must be one of " + strings.Join(models.FileStatuses, ", ")})
+			return filter, false
+		}
+		filter.Status = status
+	}
+	if state := c.Query("state"); state != "" {
+		if !models.ValidFileState(state) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "state must be one of " + strings.Join(models.FileStates, ", ")})
+			return filter, false
+		}
+		filter.State = state
+	}
+
+	return filter, true
+}
+
+// GetUserFiles lists the signal files the user uploaded
+// @Summary List uploaded files
+// @Description Lists the signal files the authenticated user uploaded with their size, upload time, status and the ID of the report made from them, newest first or in the given sort order, optionally filtered by status and upload date. A file is processing while its report's translation is queued or running, failed when the translation failed, processed once its report is ready, unlinked after its report was deleted and quarantined when the malware scan found it infected. Each file also carries its persisted processing state: pending while it waits for a worker, scanning while the recording is decoded and checked, translating while it is with the ML service, then completed or failed, with state_changed_at. Results are paginated by limit/offset or, unless sorted, by the cursor returned as pagination.next_cursor.
+// @Tags files
+// @Produce json
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Param offset query int false "Number of files to skip (at most 10000); cannot be combined with cursor"
+// @Param cursor query string false "Cursor from a previous page's pagination.next_cursor; cannot be combined with sort"
+// @Param sort query string false "Comma-separated field:direction pairs, e.g. file_size:desc; fields are uploaded_at, filename and file_size, directions asc (default) or desc"
+// @Param status query string false "Only files with this status" Enums(processing, processed, failed, unlinked, quarantined)
+// @Param state query string false "Only files in this processing state" Enums(pending, scanning, translating, completed, failed)
+// @Param from query string false "Only files uploaded on or after this date (YYYY-MM-DD or RFC 3339)"
+// @Param to query string false "Only files uploaded before this timestamp, or on or before this date (YYYY-MM-DD or RFC 3339)"
+// @Success 200 {object} FilesResponse "Page of uploaded files"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid sort, pagination or filter parameters"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /files [get]
+func GetUserFiles(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	page, ok := parseSortedPage(c, models.FileSortFields)
+	if !ok {
+		return
+	}
+	filter, ok := parseFileFilter(c)
+	if !ok {
+		return
+	}
+
+	files, info, err := models.FindUserFilesPage(database.DB, userID.(uint), filter, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to process file: " + err.Error()})
+		if err.Error() == "invalid cursor" {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid cursor"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch files"})
+		return
+	}
+
+	c.JSON(http.StatusOK, FilesResponse{
+		Files:      files,
+		Pagination: newPagination(page, info),
+	})
+}
+
+// DeleteFileResponse reports a deleted file and the reports deleted with it
+type DeleteFileResponse struct {
+	Message string `json:"message" example:"File deleted"`
+	// Reports made from the file that were deleted with it
+	DeletedReportIDs []uint `json:"deleted_report_ids,omitempty" example:"12"`
+}
+
+// DeleteFile deletes an uploaded file
+// @Summary Delete an uploaded file
+// @Description Deletes a signal file the authenticated user uploaded: the original is removed from storage and the file disappears from listings. With cascade=true the report made from the file is deleted too, and purged after the retention window like DELETE /reports/{id}; otherwise the report is kept without its source file. Files whose report has an active share link cannot be deleted until the link is revoked.
+// @Tags files
+// @Produce json
+// @Param id path int true "File ID"
+// @Param cascade query bool false "Also delete the report made from the file" default(false)
+// @Success 200 {object} DeleteFileResponse "File deleted"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID or cascade"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "File not found"
+// @Failure 409 {object} ErrorResponse "Conflict - The report made from the file is shared"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /files/{id} [delete]
+func DeleteFile(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
 		return
 	}
 
-	// Convert the file to a report
-	report, err := signalFile.ConvertToReport()
+	fileID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to convert file to report: " + err.Error()})
-		// Clean up the file
-		_ = os.Remove(filePath)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid file ID"})
 		return
 	}
 
-	// Set the matching scale provided by the user
-	report.MatchingScale = matchingScale
+	var cascade bool
+	switch c.DefaultQuery("cascade", "false") {
+	case "false":
+	case "true":
+		cascade = true
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "cascade must be true or false"})
+		return
+	}
 
-	// Use the CreateReport method to save the report to the database
-	savedReport, err := report.CreateReport(database.DB, userID.(uint))
+	file, err := models.FindUserFile(database.DB, uint(fileID), userID.(uint))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save report: " + err.Error()})
-		// Clean up the file
-		_ = os.Remove(filePath)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "File not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch file"})
 		return
 	}
 
-	c.JSON(http.StatusOK, FileUploadResponse{
-		Message:       "File processed successfully",
-		FileID:        signalFile.ID,
-		ReportID:      savedReport.ID,
-		Description:   signalFile.Description,
-		MatchingScale: savedReport.MatchingScale,
+	reportIDs, err := file.DeleteWithReports(database.DB, cascade)
+	if err != nil {
+		if errors.Is(err, models.ErrFileShared) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: "The report made from this file is shared; revoke its share links first"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete file"})
+		return
+	}
+	// The record is already gone, so a leftover original is only logged
+	if err := file.RemoveStored(); err != nil {
+		log.Printf("Failed to remove stored file %d: %v", file.ID, err)
+	}
+
+	auditMemberAction(c, file.UserID, "file.delete", fmt.Sprintf("file:%d", file.ID), gin.H{"cascade": cascade})
+	for _, reportID := range reportIDs {
+		auditMemberAction(c, file.UserID, "report.delete", reportResource(reportID), gin.H{"file_id": file.ID})
+	}
+
+	c.JSON(http.StatusOK, DeleteFileResponse{Message: "File deleted", DeletedReportIDs: reportIDs})
+}
+
+// FileStatusResponse reports how far an uploaded file got through processing
+type FileStatusResponse struct {
+	FileID uint `json:"file_id" example:"7"`
+	// processing, processed, failed, unlinked or quarantined, as in GET /files
+	Status   string `json:"status" example:"processing"`
+	ReportID *uint  `json:"report_id,omitempty" example:"12"`
+	// pending, scanning, translating, completed or failed, and when the file got there
+	State          string     `json:"state" example:"translating"`
+	StateChangedAt *time.Time `json:"state_changed_at,omitempty"`
+	// Why processing or translation last failed: storage, parse, validation,
+	// ml_unavailable, translation or processing, and the error message
+	ErrorClass   string `json:"error_class,omitempty" example:"ml_unavailable"`
+	ErrorMessage string `json:"error_message,omitempty" example:"translation service unavailable: context deadline exceeded"`
+	// Channel count, sampling rate and duration, once the recording was processed
+	models.RecordingInfo
+	// The background job that turns the file into a report; absent for files
+	// uploaded before uploads were processed in the background
+	Job *models.UploadJob `json:"job,omitempty"`
+}
+
+// GetFilePreview returns a downsampled waveform of an uploaded file's recording
+// @Summary Get a file's waveform preview
+// @Description Returns a downsampled waveform of the recording in a file the authenticated user uploaded, made when the file is processed, for drawing without loading the samples. Each channel's series holds up to 200 points; each point covers samples_per_point consecutive samples and holds their lowest (min) and highest (max) value in µV, so spikes survive the downsampling. Rows the mask marks as padding are left out. Files not processed yet, whose processing failed before the recording was read, or processed before previews were made have none.
+// @Tags files
+// @Produce json
+// @Param id path int true "File ID"
+// @Success 200 {object} models.FilePreview "Waveform preview"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "File not found, or it has no preview"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /files/{id}/preview [get]
+func GetFilePreview(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	fileID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid file ID"})
+		return
+	}
+
+	preview, err := models.FindFilePreview(database.DB, uint(fileID), userID.(uint))
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch preview"})
+			return
+		}
+		if _, err := models.FindUserFile(database.DB, uint(fileID), userID.(uint)); err != nil {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "File not found"})
+			return
+		}
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "The file has no preview; previews are made when the file is processed"})
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+// GetFileStatus reports the processing status of an uploaded file
+// @Summary Get a file's processing status
+// @Description Reports how far a file the authenticated user uploaded got: its status as in GET /files, its processing state (pending, scanning, translating, completed or failed) and when it got there, the class and message of the error when parsing, checking or translating the recording failed (a file can be completed without a translation and still carry the translation error), the report made from it once there is one, and its upload job with the job's status (queued, running, completed or failed), the error of a failed job and warnings about the recording. Poll it after POST /upload until the job completes or fails; the user is also notified either way.
+// @Tags files
+// @Produce json
+// @Param id path int true "File ID"
+// @Success 200 {object} FileStatusResponse "File status"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "File not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /files/{id}/status [get]
+func GetFileStatus(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	fileID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid file ID"})
+		return
+	}
+
+	file, err := models.FindUserFile(database.DB, uint(fileID), userID.(uint))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "File not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch file"})
+		return
+	}
+	files := []models.SingleFile{*file}
+	if err := models.LoadFileReports(database.DB, files); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch file status"})
+		return
+	}
+
+	response := FileStatusResponse{
+		FileID:         file.ID,
+		Status:         files[0].Status,
+		ReportID:       files[0].ReportID,
+		State:          file.State,
+		StateChangedAt: file.StateChangedAt,
+		ErrorClass:     file.ErrorClass,
+		ErrorMessage:   file.ErrorMessage,
+		RecordingInfo:  file.RecordingInfo,
+	}
+	job, err := models.FindUploadJobForFile(database.DB, file.ID, file.UserID)
+	switch {
+	case err == nil:
+		response.Job = job
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch file status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RetranslateResponse is returned once a file's report is queued for translation
+type RetranslateResponse struct {
+	Message   string `json:"message" example:"Translation queued"`
+	FileID    uint   `json:"file_id" example:"7"`
+	ReportID  uint   `json:"report_id" example:"12"`
+	StatusURL string `json:"status_url" example:"/files/7/status"`
+}
+
+// RetranslateFile translates the recording of an uploaded file again
+// @Summary Re-translate a file
+// @Description Runs the ML translation of a file the authenticated user uploaded again, for example after a model upgrade, without uploading it again. The report made from the file gets the new translation; the translation it replaces is kept in the report's revision history (GET /reports/{id}/revisions) with change translation and can be restored. A failed translation leaves the report as it was. Follow progress with GET /files/{id}/status. Counts against the monthly translation quota once it succeeds.
+// @Tags files
+// @Produce json
+// @Param id path int true "File ID"
+// @Success 202 {object} RetranslateResponse "Translation queued"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 402 {object} plans.LimitError "Payment Required - Translation quota exceeded"
+// @Failure 404 {object} ErrorResponse "File not found"
+// @Failure 409 {object} ErrorResponse "Conflict - The file has no report yet, its recording was removed, or it is already being translated"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Failure 503 {object} ErrorResponse "Service Unavailable - Translation service is down (see Retry-After), or the recording could not be read from cold storage"
+// @Security BearerAuth
+// @Router /files/{id}/retranslate [post]
+func RetranslateFile(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	fileID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid file ID"})
+		return
+	}
+
+	if serviceUnavailable(c, health.ComponentMLService, "Translation service is unavailable, please retry later") {
+		return
+	}
+
+	file, err := models.FindUserFile(database.DB, uint(fileID), userID.(uint))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "File not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch file"})
+		return
+	}
+
+	// The report keeps the recording, so the stored file itself is not needed
+	report, err := models.FindFileReport(database.DB, file.ID, file.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: "The file has no report yet; wait for its processing to finish"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch report"})
+		return
+	}
+	if !loadReportContent(c, report) {
+		return
+	}
+	if len(report.Content) == 0 || string(report.Content) == "null" {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "The recording of this file was removed; upload it again"})
+		return
+	}
+
+	limits, err := plans.LimitsForUser(database.DB, file.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to load plan limits"})
+		return
+	}
+	usage, err := plans.CurrentUsage(database.DB, file.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to load usage"})
+		return
+	}
+	if !plans.CanTranslate(limits, usage) {
+		quotaErr := &plans.QuotaError{Resource: plans.ResourceTranslations, Limit: int64(limits.TranslationsPerMonth)}
+		respondLimitError(c, http.StatusPaymentRequired, plans.QuotaExceeded(limits, usage, quotaErr))
+		return
+	}
+
+	queued, err := report.QueueRetranslation(database.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to queue translation"})
+		return
+	}
+	if !queued {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "The file is already being translated"})
+		return
+	}
+	if err := file.SetState(database.DB, models.FileStatePending); err != nil {
+		log.Printf("Failed to move file %d to state %s: %v", file.ID, models.FileStatePending, err)
+	}
+	if jobs.Embedded() {
+		go jobs.StartTranslation(context.WithoutCancel(c.Request.Context()), database.DB, ingest.Default(), report, config.Current().MLServiceAddress)
+	}
+
+	c.JSON(http.StatusAccepted, RetranslateResponse{
+		Message:   "Translation queued",
+		FileID:    file.ID,
+		ReportID:  report.ID,
+		StatusURL: fmt.Sprintf("/files/%d/status", file.ID),
 	})
 }
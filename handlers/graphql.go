@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/graph"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// graphQLComplexityLimit bounds how many fields a single GraphQL query may resolve
+const graphQLComplexityLimit = 1000
+
+// GraphQLRequest represents a GraphQL query
+type GraphQLRequest struct {
+	Query         string                 `json:"query" example:"{ reports(first: 10) { nodes { id title createdAt tags } pageInfo { hasNextPage endCursor } } }"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// GraphQLResponse represents the result of a GraphQL query
+type GraphQLResponse struct {
+	Data   map[string]interface{}   `json:"data"`
+	Errors []map[string]interface{} `json:"errors,omitempty"`
+}
+
+// GraphQL returns the handler serving the GraphQL API
+// @Summary Query the GraphQL API
+// @Description Runs a GraphQL query over the authenticated user's profile, reports, tags and files, returning only the selected fields. Report tags, source files and decrypted text are only loaded when selected. Lists are connections paged with first (at most 100) and the after cursor from pageInfo.endCursor. The schema is in graph/schema.graphqls; introspection is disabled in production. GET takes the query in the query parameter.
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param query body GraphQLRequest true "GraphQL query"
+// @Success 200 {object} GraphQLResponse "Query result; field errors are listed in errors"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 422 {object} GraphQLResponse "Invalid or too complex query"
+// @Security BearerAuth
+// @Router /graphql [post]
+func GraphQL() gin.HandlerFunc {
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{DB: database.DB}}))
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.FixedComplexityLimit(graphQLComplexityLimit))
+	if utils.GetEnvWithDefault("APP_ENV", "development") != "production" {
+		srv.Use(extension.Introspection{})
+	}
+
+	return func(c *gin.Context) {
+		ctx := graph.WithViewer(c.Request.Context(), graph.Viewer{
+			UserID: c.MustGet("userID").(uint),
+			IP:     c.ClientIP(),
+		})
+		srv.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+	}
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/email"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateLinkRequest represents the request body for inviting a caregiver or clinician
+type CreateLinkRequest struct {
+	Email        string `json:"email" binding:"required,email" example:"doctor@example.com"`
+	Relationship string `json:"relationship" binding:"required,oneof=caregiver clinician" example:"clinician"`
+	// Optional end of the grant; omit for access until revoked
+	AccessExpiresAt *time.Time `json:"access_expires_at" example:"2027-01-01T00:00:00Z"`
+}
+
+// UpdateLinkExpiryRequest represents the request body for changing when a grant ends.
+// A null access_expires_at keeps access until the link is revoked.
+type UpdateLinkExpiryRequest struct {
+	AccessExpiresAt *time.Time `json:"access_expires_at" example:"2027-01-01T00:00:00Z"`
+}
+
+// AcceptLinkRequest represents the request body for accepting an invitation
+type AcceptLinkRequest struct {
+	Token string `json:"token" binding:"required" example:"invitation-token"`
+}
+
+// LinkResponse represents a single account link
+type LinkResponse struct {
+	Message string          `json:"message" example:"Invitation sent"`
+	Link    models.UserLink `json:"link"`
+}
+
+// LinksResponse represents a list of account links
+type LinksResponse struct {
+	Links []models.UserLink `json:"links"`
+}
+
+// CreateLinkInvitation invites another person to read the authenticated user's reports
+// @Summary Invite a caregiver or clinician
+// @Description Sends an invitation granting view and download access to the authenticated user's reports once accepted, optionally until access_expires_at
+// @Tags links
+// @Accept json
+// @Produce json
+// @Param invitation body CreateLinkRequest true "Invitation details"
+// @Success 201 {object} LinkResponse "Invitation sent"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /links [post]
+func CreateLinkInvitation(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	var req CreateLinkRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	owner, err := models.FindUserByID(database.DB, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch user"})
+		return
+	}
+
+	link, err := models.CreateLinkInvitation(database.DB, owner, req.Email, req.Relationship, req.AccessExpiresAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	acceptURL := utils.GetEnvWithDefault("LINK_ACCEPT_URL", "http://localhost:3000/links/accept?token=") + link.Token
+	body := fmt.Sprintf("Hello,\n\n%s has invited you to view their ThinkInk reports as their %s.\n\nSign in or create an account with this email address and open the link below to accept. The invitation expires on %s.\n\n%s\n", owner.Name, link.Relationship, owner.FormatTimestamp(link.ExpiresAt), acceptURL)
+	if err := email.Enqueue(database.DB, link.InviteEmail, owner.Name+" shared their ThinkInk reports with you", body, "transactional"); err != nil {
+		log.Printf("Failed to queue link invitation email: %v", err)
+	}
+
+	// In development mode, include the token for testing
+	if utils.GetEnvWithDefault("APP_ENV", "development") != "production" {
+		c.JSON(http.StatusCreated, gin.H{
+			"message":          "Invitation sent",
+			"link":             link,
+			"invitation_token": link.Token, // Only included in non-production environments
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, LinkResponse{Message: "Invitation sent", Link: *link})
+}
+
+// AcceptLinkInvitation accepts an invitation sent to the authenticated user's email
+// @Summary Accept an invitation
+// @Description Accepts a caregiver or clinician invitation addressed to the authenticated user's email
+// @Tags links
+// @Accept json
+// @Produce json
+// @Param invitation body AcceptLinkRequest true "Invitation token"
+// @Success 200 {object} LinkResponse "Invitation accepted"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid, expired or mismatched invitation"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /links/accept [post]
+func AcceptLinkInvitation(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	var req AcceptLinkRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	viewer, err := models.FindUserByID(database.DB, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch user"})
+		return
+	}
+
+	link, err := models.AcceptLinkInvitation(database.DB, req.Token, viewer)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, LinkResponse{Message: "Invitation accepted", Link: *link})
+}
+
+// GetLinks lists the authenticated user's account links
+// @Summary List account links
+// @Description Lists links where the authenticated user is the report owner or the viewer
+// @Tags links
+// @Produce json
+// @Success 200 {object} LinksResponse "Account links"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /links [get]
+func GetLinks(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	links, err := models.FindLinksForUser(database.DB, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch links"})
+		return
+	}
+
+	c.JSON(http.StatusOK, LinksResponse{Links: links})
+}
+
+// UpdateLinkExpiry changes when an account link's access ends
+// @Summary Change link access expiry
+// @Description Sets or clears the date the viewer's access ends. Only the report owner may change it.
+// @Tags links
+// @Accept json
+// @Produce json
+// @Param id path string true "Link ID"
+// @Param expiry body UpdateLinkExpiryRequest true "New access expiry"
+// @Success 200 {object} LinkResponse "Link updated"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Link not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /links/{id}/expiry [put]
+func UpdateLinkExpiry(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid link ID"})
+		return
+	}
+
+	var req UpdateLinkExpiryRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	link, err := models.FindLinkForOwner(database.DB, uint(linkID), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Link not found"})
+		return
+	}
+
+	if err := link.SetAccessExpiry(database.DB, req.AccessExpiresAt); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, LinkResponse{Message: "Link updated", Link: *link})
+}
+
+// RevokeLink revokes an account link
+// @Summary Revoke an account link
+// @Description Revokes a pending or accepted link. Either the owner or the viewer may revoke.
+// @Tags links
+// @Produce json
+// @Param id path string true "Link ID"
+// @Success 200 {object} MessageResponse "Link revoked"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Link not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /links/{id} [delete]
+func RevokeLink(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid link ID"})
+		return
+	}
+
+	link, err := models.FindLinkForParticipant(database.DB, uint(linkID), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Link not found"})
+		return
+	}
+
+	if err := link.Revoke(database.DB); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to revoke link"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Link revoked"})
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/gin-gonic/gin"
+)
+
+// MigrationsResponse describes the migration settings and the schema changes not yet applied
+type MigrationsResponse struct {
+	Mode         string                   `json:"mode" example:"auto"`
+	LockTimeout  string                   `json:"lock_timeout" example:"5s"`
+	LockRetries  int                      `json:"lock_retries" example:"3"`
+	Pending      []database.MigrationStep `json:"pending"`
+	PendingCount int                      `json:"pending_count" example:"1"`
+}
+
+// GetPendingMigrations lists the schema migrations that have not been applied
+// @Summary List pending migrations
+// @Description Returns the migration mode, the lock guardrails and the planned expand and contract steps with their SQL, without applying anything (admin only)
+// @Tags admin
+// @Produce json
+// @Success 200 {object} MigrationsResponse "Pending migrations"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /admin/migrations [get]
+func GetPendingMigrations(c *gin.Context) {
+	mode, opts, err := database.MigrationSettings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Invalid migration settings: " + err.Error()})
+		return
+	}
+
+	steps, err := database.PlanMigrations(database.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to plan migrations"})
+		return
+	}
+	if steps == nil {
+		steps = []database.MigrationStep{}
+	}
+
+	c.JSON(http.StatusOK, MigrationsResponse{
+		Mode:         mode,
+		LockTimeout:  opts.LockTimeout.String(),
+		LockRetries:  opts.Retries,
+		Pending:      steps,
+		PendingCount: len(steps),
+	})
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/mlexport"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// maxExportRecords bounds a single feedback export
+const maxExportRecords = 100000
+
+// ExportModelFeedback streams anonymized model-feedback pairs for training
+// @Summary Export model feedback
+// @Description Streams anonymized (EEG reference, translation, user correction) pairs as JSON lines for model training. Users and reports are identified only by stable pseudonyms, report titles are dropped, dates are coarsened to the month, and email addresses, links and phone-like numbers are scrubbed from text. Reports of users who opted in to report text encryption are never exported. Requires an ml_export service key in X-Service-Key; every export is audited.
+// @Tags ml
+// @Produce application/x-ndjson
+// @Param since query string false "Only reports created on or after this date (YYYY-MM-DD or RFC 3339)"
+// @Param until query string false "Only reports created before this date (YYYY-MM-DD or RFC 3339)"
+// @Param limit query int false "Maximum number of records (default and max 100000)"
+// @Param include_eeg query bool false "Inline the EEG signal (eeg, mask, impedance) in each record"
+// @Param X-Service-Key header string true "ML export service key"
+// @Success 200 {object} mlexport.Record "One JSON record per line"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid parameters"
+// @Failure 401 {object} ErrorResponse "Unauthorized - Missing service key"
+// @Failure 403 {object} ErrorResponse "Forbidden - Invalid service key"
+// @Failure 503 {object} ErrorResponse "Export is not configured"
+// @Router /ml/feedback-export [get]
+func ExportModelFeedback(c *gin.Context) {
+	credential := c.MustGet("serviceCredential").(*models.ServiceCredential)
+
+	opts := mlexport.Options{Limit: maxExportRecords}
+	for name, target := range map[string]**time.Time{"since": &opts.Since, "until": &opts.Until} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			if t, err = time.Parse("2006-01-02", value); err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: name + " must be a date (YYYY-MM-DD) or RFC 3339 timestamp"})
+				return
+			}
+		}
+		*target = &t
+	}
+	if limitParam := c.Query("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > maxExportRecords {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("limit must be between 1 and %d", maxExportRecords)})
+			return
+		}
+		opts.Limit = limit
+	}
+	opts.IncludeEEG = c.Query("include_eeg") == "true"
+
+	pseudonymKey := utils.GetEnvWithDefault("ML_EXPORT_PSEUDONYM_KEY", "")
+	if pseudonymKey == "" {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "Feedback export is not configured"})
+		return
+	}
+	opts.PseudonymKey = []byte(pseudonymKey)
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="feedback-%s.jsonl"`, time.Now().UTC().Format("20060102-150405")))
+	c.Status(http.StatusOK)
+
+	started := time.Now()
+	written, err := mlexport.Write(c.Request.Context(), database.DB, c.Writer, opts)
+
+	outcome := "success"
+	details := gin.H{
+		"credential":  credential.Name,
+		"since":       opts.Since,
+		"until":       opts.Until,
+		"limit":       opts.Limit,
+		"include_eeg": opts.IncludeEEG,
+		"records":     written,
+		"duration_ms": time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		outcome = "failed"
+		details["error"] = err.Error()
+		log.Printf("Feedback export for service credential %d failed after %d records: %v", credential.ID, written, err)
+	}
+	actor := fmt.Sprintf("service:%d", credential.ID)
+	if err := models.RecordAudit(database.DB, actor, "ml_export.feedback", outcome, c.ClientIP(), details); err != nil {
+		log.Printf("Failed to audit feedback export: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/moderation"
+	"github.com/gin-gonic/gin"
+)
+
+// OrgModerationRequest represents the request body for setting an
+// organization's moderation policy. A null moderators list uses the defaults.
+type OrgModerationRequest struct {
+	Enabled    *bool    `json:"enabled" binding:"required" example:"true"`
+	Moderators []string `json:"moderators" example:"self_harm_keywords"`
+	// Extra phrases flagged as self-harm on top of the built-in list
+	Keywords        []string `json:"keywords" binding:"max=100,dive,min=1,max=100" example:"give up on everything"`
+	AlertClinicians *bool    `json:"alert_clinicians" binding:"required" example:"true"`
+	AlertEmail      bool     `json:"alert_email" example:"true"`
+}
+
+// OrgModerationResponse represents an organization's moderation policy, null
+// when members get the defaults, and the moderators that can be chosen
+type OrgModerationResponse struct {
+	Policy    *models.OrgModerationPolicy `json:"policy"`
+	Available []string                    `json:"available" example:"self_harm_keywords"`
+}
+
+// GetOrgModeration returns an organization's moderation policy
+// @Summary Get an organization's moderation policy
+// @Description Returns how members' translations are moderated, or a null policy if the defaults apply: the self_harm_keywords moderator, with in-app, push and email alerts to linked clinicians (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} OrgModerationResponse "Moderation policy"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/orgs/{id}/moderation [get]
+func GetOrgModeration(c *gin.Context) {
+	org, ok := organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	policy, err := models.FindOrgModerationPolicy(database.DB, org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch moderation policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, OrgModerationResponse{Policy: policy, Available: moderation.Available()})
+}
+
+// SetOrgModeration creates or replaces an organization's moderation policy
+// @Summary Set an organization's moderation policy
+// @Description Chooses whether and how members' new translations are moderated: the moderators to run, extra self-harm keywords, and whether clinicians linked to the member are alerted when a report is flagged, optionally by email (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param policy body OrgModerationRequest true "Moderation policy"
+// @Success 200 {object} OrgModerationResponse "Moderation policy"
+// @Failure 400 {object} ErrorResponse "Bad Request - Unknown moderator or invalid keyword"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/orgs/{id}/moderation [put]
+func SetOrgModeration(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	org, ok := organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	var req OrgModerationRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	moderators, err := moderation.EncodeModerators(req.Moderators)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	keywords, err := moderation.EncodeKeywords(req.Keywords)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	policy := &models.OrgModerationPolicy{
+		OrganizationID:  org.ID,
+		Enabled:         *req.Enabled,
+		Moderators:      moderators,
+		Keywords:        keywords,
+		AlertClinicians: *req.AlertClinicians,
+		AlertEmail:      req.AlertEmail,
+	}
+	if err := models.UpsertOrgModerationPolicy(database.DB, policy); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save moderation policy"})
+		return
+	}
+	auditOrgAction(c, userID.(uint), org.ID, "organization.moderation_update", orgResource(org.ID), gin.H{"enabled": *req.Enabled})
+
+	saved, err := models.FindOrgModerationPolicy(database.DB, org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch moderation policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, OrgModerationResponse{Policy: saved, Available: moderation.Available()})
+}
+
+// DeleteOrgModeration removes an organization's moderation policy
+// @Summary Remove an organization's moderation policy
+// @Description Removes the organization's moderation policy so its members get the defaults (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} MessageResponse "Moderation policy removed"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/orgs/{id}/moderation [delete]
+func DeleteOrgModeration(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	org, ok := organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	if err := models.DeleteOrgModerationPolicy(database.DB, org.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to remove moderation policy"})
+		return
+	}
+	auditOrgAction(c, userID.(uint), org.ID, "organization.moderation_reset", orgResource(org.ID), nil)
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Moderation policy removed"})
+}
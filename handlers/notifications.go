@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationsResponse represents the user's in-app notifications
+type NotificationsResponse struct {
+	Notifications []models.Notification `json:"notifications"`
+	UnreadCount   int64                 `json:"unread_count" example:"3"`
+}
+
+// GetNotifications lists the authenticated user's in-app notifications
+// @Summary List notifications
+// @Description Lists the authenticated user's 50 most recent in-app notifications with the unread count
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} NotificationsResponse "Notifications"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /notifications [get]
+func GetNotifications(c *gin.Context) {
+	// Get authenticated user ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	notifications, unread, err := models.FindNotificationsForUser(database.DB, userID.(uint), 50)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, NotificationsResponse{Notifications: notifications, UnreadCount: unread})
+}
+
+// MarkNotificationRead marks an in-app notification as read
+// @Summary Mark a notification as read
+// @Description Marks one of the authenticated user's notifications as read
+// @Tags notifications
+// @Produce json
+// @Param id path int true "Notification ID"
+// @Success 200 {object} MessageResponse "Notification marked as read"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Notification not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /notifications/{id}/read [post]
+func MarkNotificationRead(c *gin.Context) {
+	// Get authenticated user ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	notificationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid notification ID"})
+		return
+	}
+
+	if err := models.MarkNotificationRead(database.DB, userID.(uint), uint(notificationID)); err != nil {
+		if err.Error() == "notification not found" {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Notification not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update notification"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Notification marked as read"})
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/email"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// VerifyEmailRequest represents the request body for verifying an email address
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required" example:"verification-token"`
+}
+
+// GetOnboarding returns the authenticated user's onboarding checklist
+// @Summary Get onboarding progress
+// @Description Returns the onboarding checklist (email verified, first upload, first report, subscription) and the next step to complete
+// @Tags onboarding
+// @Produce json
+// @Success 200 {object} models.OnboardingStatus "Onboarding progress"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /onboarding [get]
+func GetOnboarding(c *gin.Context) {
+	// Get authenticated user ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	user, err := models.FindUserByID(database.DB, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, user.Onboarding())
+}
+
+// VerifyEmail confirms the user's email address
+// @Summary Verify email address
+// @Description Verifies the user's email address using the token sent by email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body VerifyEmailRequest true "Verification token"
+// @Success 200 {object} MessageResponse "Email verified"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid or expired token"
+// @Router /verify-email [post]
+func VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if _, err := models.VerifyEmailToken(database.DB, req.Token); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid or expired verification token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Email verified"})
+}
+
+// ResendVerificationEmail sends a new verification email to the authenticated user
+// @Summary Resend verification email
+// @Description Sends a new email verification link to the authenticated user
+// @Tags auth
+// @Produce json
+// @Success 200 {object} MessageResponse "Verification email sent"
+// @Failure 400 {object} ErrorResponse "Bad Request - Email already verified"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /verify-email/resend [post]
+func ResendVerificationEmail(c *gin.Context) {
+	// Get authenticated user ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	user, err := models.FindUserByID(database.DB, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch user"})
+		return
+	}
+
+	if user.EmailVerifiedAt != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Email already verified"})
+		return
+	}
+
+	token, err := sendVerificationEmail(database.DB, user)
+	if err != nil {
+		log.Printf("Failed to send verification email: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to send verification email"})
+		return
+	}
+
+	// In development mode, include the token for testing
+	if utils.GetEnvWithDefault("APP_ENV", "development") != "production" {
+		c.JSON(http.StatusOK, gin.H{
+			"message":            "Verification email sent",
+			"verification_token": token, // Only included in non-production environments
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Verification email sent"})
+}
+
+// sendVerificationEmail creates a verification token and queues the email, returning the token
+func sendVerificationEmail(db *gorm.DB, user *models.User) (string, error) {
+	token, err := user.GenerateEmailVerificationToken(db)
+	if err != nil {
+		return "", err
+	}
+
+	verifyURL := utils.GetEnvWithDefault("EMAIL_VERIFY_URL", "http://localhost:3000/verify-email?token=") + token
+	expiresAt := user.FormatTimestamp(time.Now().Add(models.EmailVerificationTTL))
+	body := fmt.Sprintf("Hi %s,\n\nPlease confirm your email address using the link below. It expires at %s.\n\n%s\n", user.Name, expiresAt, verifyURL)
+	if err := email.Enqueue(db, user.Email, "Confirm your ThinkInk email address", body, "transactional"); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// completeOnboardingStep records an onboarding step, logging rather than failing the request on error
+func completeOnboardingStep(db *gorm.DB, user *models.User, step string) {
+	if err := user.CompleteOnboardingStep(db, step); err != nil {
+		log.Printf("Failed to record onboarding step %s for user %d: %v", step, user.ID, err)
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swaggo/swag"
+)
+
+// GetOpenAPISpec serves the OpenAPI document generated for an API version
+// @Summary Get the OpenAPI document for an API version
+// @Description Returns the OpenAPI (Swagger 2.0) document generated from the handler annotations for the given version, e.g. /openapi/v1.json, for client code generation
+// @Tags docs
+// @Produce json
+// @Param version path string true "API version document, e.g. v1.json"
+// @Success 200 {object} object "OpenAPI document"
+// @Failure 404 {object} ErrorResponse "Unknown API version"
+// @Router /openapi/{version} [get]
+func GetOpenAPISpec(c *gin.Context) {
+	version, ok := strings.CutSuffix(c.Param("version"), ".json")
+	if !ok {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Unknown API version"})
+		return
+	}
+
+	// Each version's document is registered under its version name by the generated docs package
+	doc, err := swag.ReadDoc(version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Unknown API version"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(doc))
+}
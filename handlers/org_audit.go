@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/gin-gonic/gin"
+)
+
+// maxOrgAuditExportRows bounds a single audit log CSV export
+const maxOrgAuditExportRows = 100000
+
+// OrgAuditLogsResponse represents one page of an organization's audit log
+type OrgAuditLogsResponse struct {
+	Logs       []models.AuditLog `json:"logs"`
+	Pagination Pagination        `json:"pagination"`
+}
+
+// auditOrgAction records an action taken on an organization or one of its
+// members in the organization's audit log
+func auditOrgAction(c *gin.Context, actorID, orgID uint, action, resource string, details gin.H) {
+	if err := models.RecordOrgAudit(database.DB, orgID, fmt.Sprintf("user:%d", actorID), action, resource, "success", c.ClientIP(), details); err != nil {
+		log.Printf("Failed to audit %s: %v", action, err)
+	}
+}
+
+// orgResource names an organization as the resource of an audit entry
+func orgResource(orgID uint) string {
+	return fmt.Sprintf("organization:%d", orgID)
+}
+
+// reportResource names a report as the resource of an audit entry
+func reportResource(reportID uint) string {
+	return fmt.Sprintf("report:%d", reportID)
+}
+
+// auditMemberAction records an action the authenticated user took on the data
+// of ownerID in the audit log of the owner's organization; data of users
+// outside an organization is not audited
+func auditMemberAction(c *gin.Context, ownerID uint, action, resource string, details gin.H) {
+	owner, err := models.FindUserByID(database.DB, ownerID)
+	if err != nil || owner.OrganizationID == nil {
+		return
+	}
+	auditOrgAction(c, c.MustGet("userID").(uint), *owner.OrganizationID, action, resource, details)
+}
+
+// orgAdminAccess resolves the organization in the path and checks the caller
+// may manage it, e.g. read its audit log or buy its seats: platform admins and
+// the organization's own admins.
+// It writes the error response and returns false on failure.
+func orgAdminAccess(c *gin.Context) (*models.Organization, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return nil, false
+	}
+
+	org, ok := organizationFromParam(c)
+	if !ok {
+		return nil, false
+	}
+
+	user, err := models.FindUserByID(database.DB, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "User not found"})
+		return nil, false
+	}
+	if !user.IsAdmin() && !user.IsOrgAdmin(org.ID) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Organization admin access required"})
+		return nil, false
+	}
+
+	return org, true
+}
+
+// parseAuditLogFilter reads the actor, action, resource, from and to query
+// parameters. It writes the error response and returns false on failure.
+func parseAuditLogFilter(c *gin.Context) (models.AuditLogFilter, bool) {
+	filter := models.AuditLogFilter{
+		Actor:    c.Query("actor"),
+		Action:   c.Query("action"),
+		Resource: c.Query("resource"),
+	}
+
+	var ok bool
+	if filter.From, ok = parseDateQuery(c, "from", false); !ok {
+		return filter, false
+	}
+	if filter.To, ok = parseDateQuery(c, "to", true); !ok {
+		return filter, false
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "from must be before to"})
+		return filter, false
+	}
+
+	return filter, true
+}
+
+// GetOrgAuditLogs lists an organization's audit log
+// @Summary List an organization's audit log
+// @Description Lists audit entries about an organization and the actions of its members, newest first: membership and role changes, rate plan, moderation, branding and text processing changes, and members' report deletions, shares and exports. Available to the organization's admins and to platform admins; platform-wide entries are only listed by GET /admin/audit-logs.
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param actor query string false "Only entries by this actor, e.g. user:42"
+// @Param action query string false "Only entries of this event type, e.g. report.delete"
+// @Param resource query string false "Only entries about this resource, e.g. report:12"
+// @Param from query string false "Only entries on or after this date (YYYY-MM-DD or RFC 3339)"
+// @Param to query string false "Only entries before this timestamp, or on or before this date (YYYY-MM-DD or RFC 3339)"
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Param offset query int false "Number of entries to skip (at most 10000)"
+// @Param cursor query string false "Cursor from a previous page's next_cursor"
+// @Success 200 {object} OrgAuditLogsResponse "Audit entries"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID, filter or page"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Organization admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /orgs/{id}/audit-logs [get]
+func GetOrgAuditLogs(c *gin.Context) {
+	org, ok := orgAdminAccess(c)
+	if !ok {
+		return
+	}
+	filter, ok := parseAuditLogFilter(c)
+	if !ok {
+		return
+	}
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	logs, info, err := models.FindOrgAuditLogsPage(database.DB, org.ID, filter, page)
+	if err != nil {
+		if err.Error() == "invalid cursor" {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid cursor"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch audit logs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, OrgAuditLogsResponse{Logs: logs, Pagination: newPagination(page, info)})
+}
+
+// ExportOrgAuditLogs exports an organization's audit log as CSV
+// @Summary Export an organization's audit log
+// @Description Streams the organization's audit entries matching the same filters as GET /orgs/{id}/audit-logs as CSV, newest first, up to 100000 rows. Columns are id, created_at, actor, action, resource, outcome, ip and details (JSON). Every export is itself audited.
+// @Tags organizations
+// @Produce text/csv
+// @Param id path string true "Organization ID"
+// @Param actor query string false "Only entries by this actor, e.g. user:42"
+// @Param action query string false "Only entries of this event type, e.g. report.delete"
+// @Param resource query string false "Only entries about this resource, e.g. report:12"
+// @Param from query string false "Only entries on or after this date (YYYY-MM-DD or RFC 3339)"
+// @Param to query string false "Only entries before this timestamp, or on or before this date (YYYY-MM-DD or RFC 3339)"
+// @Success 200 {file} file "Audit entries as CSV"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID or filter"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Organization admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization not found"
+// @Security BearerAuth
+// @Router /orgs/{id}/audit-logs/export [get]
+func ExportOrgAuditLogs(c *gin.Context) {
+	org, ok := orgAdminAccess(c)
+	if !ok {
+		return
+	}
+	filter, ok := parseAuditLogFilter(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="audit-log-org-%d-%s.csv"`, org.ID, time.Now().UTC().Format("20060102-150405")))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"id", "created_at", "actor", "action", "resource", "outcome", "ip", "details"})
+
+	// Page through by cursor so rows written during the export do not shift pages
+	page := models.Page{Limit: maxPageLimit}
+	written := 0
+	var exportErr error
+	for written < maxOrgAuditExportRows {
+		logs, info, err := models.FindOrgAuditLogsPage(database.DB, org.ID, filter, page)
+		if err != nil {
+			exportErr = err
+			break
+		}
+		for _, entry := range logs {
+			w.Write([]string{
+				strconv.FormatUint(uint64(entry.ID), 10),
+				entry.CreatedAt.UTC().Format(time.RFC3339),
+				entry.Actor,
+				entry.Action,
+				entry.Resource,
+				entry.Outcome,
+				entry.IP,
+				string(entry.Details),
+			})
+		}
+		written += len(logs)
+		if !info.HasMore {
+			break
+		}
+		page.Cursor = info.NextCursor
+	}
+	w.Flush()
+	if exportErr == nil {
+		exportErr = w.Error()
+	}
+	if exportErr != nil {
+		log.Printf("Audit log export of organization %d failed after %d rows: %v", org.ID, written, exportErr)
+	}
+
+	userID := c.MustGet("userID").(uint)
+	auditOrgAction(c, userID, org.ID, "audit_log.export", orgResource(org.ID), gin.H{
+		"actor":    filter.Actor,
+		"action":   filter.Action,
+		"resource": filter.Resource,
+		"from":     filter.From,
+		"to":       filter.To,
+		"rows":     written,
+	})
+}
@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/billing"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/plans"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// orgSubscriptionKey names the organization a checkout session buys seats for
+const orgSubscriptionKey = "organization_id"
+
+// CreateOrgCheckoutRequest represents the request body for buying seats for an organization
+type CreateOrgCheckoutRequest struct {
+	PlanID     string `json:"plan_id" binding:"required" example:"price_1Oxy3JExamplePriceID"`
+	Seats      int    `json:"seats" binding:"required,min=1" example:"10"`
+	SuccessURL string `json:"success_url" binding:"required" example:"https://yourapp.com/success?session_id={CHECKOUT_SESSION_ID}"`
+	CancelURL  string `json:"cancel_url" binding:"required" example:"https://yourapp.com/cancel"`
+	// Optional promotion code applied up front; without one the customer can enter a code at checkout
+	PromoCode string `json:"promo_code" example:"SPRING25"`
+	// Optional ISO 4217 currency to pay in; defaults to the currency of the buyer's country when the plan offers it
+	Currency string `json:"currency" example:"eur"`
+}
+
+// SetOrgSeatsRequest represents the request body for changing an organization's seats
+type SetOrgSeatsRequest struct {
+	Seats int `json:"seats" binding:"required,min=1" example:"12"`
+}
+
+// OrgSubscriptionResponse represents an organization's seat subscription
+type OrgSubscriptionResponse struct {
+	// Null until seats were bought
+	Subscription *models.OrgSubscription `json:"subscription"`
+	// Members of the organization, each taking one seat
+	SeatsUsed int `json:"seats_used" example:"8"`
+}
+
+// GetOrgSubscription returns an organization's seat subscription
+// @Summary Get an organization's seat subscription
+// @Description Returns the organization's seat-based subscription, or null if it has none, and how many seats its members take (organization admins and admins)
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} OrgSubscriptionResponse "Seat subscription"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Organization admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /orgs/{id}/subscription [get]
+func GetOrgSubscription(c *gin.Context) {
+	org, ok := orgAdminAccess(c)
+	if !ok {
+		return
+	}
+
+	sub, err := models.FindOrgSubscription(database.DB, org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to load subscription"})
+		return
+	}
+	used, err := models.CountOrgSeatsTaken(database.DB, org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to count seats"})
+		return
+	}
+
+	response := OrgSubscriptionResponse{SeatsUsed: used}
+	if sub != nil && sub.SubscriptionID != "" {
+		response.Subscription = sub
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateOrgCheckoutHandler creates a Stripe Checkout session for an organization's seats
+// @Summary Buy seats for an organization
+// @Description Creates a Stripe checkout session for a subscription to the plan with the given number of seats, billed to the organization. Members hold the plan while the subscription is active; there must be a seat for every current member. Currency and promo_code work as for personal subscriptions (organization admins and admins)
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body CreateOrgCheckoutRequest true "Checkout session details"
+// @Success 200 {object} CheckoutResponse "Checkout session created"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Organization admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization not found"
+// @Failure 409 {object} ErrorResponse "Conflict - The organization already has a subscription, or fewer seats than members"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Failure 503 {object} ErrorResponse "Plan catalog unavailable to check the requested currency"
+// @Security BearerAuth
+// @Router /orgs/{id}/subscription/checkout [post]
+func CreateOrgCheckoutHandler(c *gin.Context) {
+	org, ok := orgAdminAccess(c)
+	if !ok {
+		return
+	}
+
+	var req CreateOrgCheckoutRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	db := database.DB
+	user, err := models.FindUserByID(db, c.GetUint("userID"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		return
+	}
+
+	sub, err := models.FindOrgSubscription(db, org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to load subscription"})
+		return
+	}
+	if sub != nil && sub.LimitsSeats() {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Organization already has a subscription; change its seats instead"})
+		return
+	}
+	used, err := models.CountOrgSeatsTaken(db, org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to count seats"})
+		return
+	}
+	if req.Seats < used {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: fmt.Sprintf("The organization has %d members; buy at least %d seats", used, used)})
+		return
+	}
+
+	// Pick the price of the plan in the requested or the buyer's currency
+	planID, currency, ok := checkoutPlanPrice(c, user, req.PlanID, req.Currency)
+	if !ok {
+		return
+	}
+
+	// The organization is billed as its own Stripe customer
+	if sub == nil {
+		cus, err := billing.Client().CreateCustomer(c.Request.Context(), billing.CustomerInput{
+			Name:     org.Name,
+			Email:    user.Email,
+			Metadata: map[string]string{orgSubscriptionKey: strconv.FormatUint(uint64(org.ID), 10)},
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Error creating Stripe customer: %v", err)})
+			return
+		}
+		if sub, err = models.CreateOrgSubscription(db, org.ID, cus.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save subscription"})
+			return
+		}
+	}
+
+	in := billing.CheckoutSessionInput{
+		CustomerID: sub.StripeCustomerID,
+		Mode:       billing.CheckoutModeSubscription,
+		Currency:   currency,
+		LineItems: []billing.LineItem{
+			{PriceID: planID, Quantity: int64(req.Seats)},
+		},
+		SuccessURL:    req.SuccessURL,
+		CancelURL:     req.CancelURL,
+		CollectTaxIDs: true,
+		AutomaticTax:  automaticTax(),
+		Metadata: map[string]string{
+			"plan_id":          planID,
+			orgSubscriptionKey: strconv.FormatUint(uint64(org.ID), 10),
+		},
+	}
+	if !applyPromoCode(c, req.PromoCode, &in) {
+		return
+	}
+
+	// Create checkout session, with a token identifying the buyer in its metadata
+	sess, err := createCheckoutSession(c.Request.Context(), db, user, in)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Error creating checkout session: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, CheckoutResponse{
+		SessionID: sess.ID,
+		URL:       sess.URL,
+		PlanID:    planID,
+		Currency:  currency,
+	})
+}
+
+// SetOrgSeatsHandler changes the number of seats of an organization's subscription
+// @Summary Change an organization's seats
+// @Description Sets the quantity of the organization's subscription in Stripe, prorating the change for the rest of the billing period. Seats cannot drop below the number of members (organization admins and admins)
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body SetOrgSeatsRequest true "Seats"
+// @Success 200 {object} OrgSubscriptionResponse "Seats updated"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Organization admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization or subscription not found"
+// @Failure 409 {object} ErrorResponse "Conflict - Fewer seats than members"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /orgs/{id}/subscription/seats [put]
+func SetOrgSeatsHandler(c *gin.Context) {
+	org, ok := orgAdminAccess(c)
+	if !ok {
+		return
+	}
+
+	var req SetOrgSeatsRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	db := database.DB
+	sub, err := models.FindOrgSubscription(db, org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to load subscription"})
+		return
+	}
+	if sub == nil || !sub.LimitsSeats() || sub.SubscriptionItemID == "" {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Organization has no subscription"})
+		return
+	}
+	used, err := models.CountOrgSeatsTaken(db, org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to count seats"})
+		return
+	}
+	if req.Seats < used {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: fmt.Sprintf("The organization has %d members; remove members before dropping below %d seats", used, used)})
+		return
+	}
+
+	previous := sub.Seats
+	subscription, err := billing.Client().SetSubscriptionQuantity(c.Request.Context(), sub.SubscriptionID, sub.SubscriptionItemID, int64(req.Seats))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Error updating subscription: %v", err)})
+		return
+	}
+	if _, err := applyOrgSubscription(db, sub, subscription, subscription.Status); err != nil {
+		// The customer.subscription.updated webhook records the change
+		log.Printf("Failed to save seats of organization %d: %v", org.ID, err)
+	}
+	auditOrgAction(c, c.GetUint("userID"), org.ID, "organization.seats", orgResource(org.ID), gin.H{"from": previous, "to": req.Seats})
+
+	c.JSON(http.StatusOK, OrgSubscriptionResponse{Subscription: sub, SeatsUsed: used})
+}
+
+// checkOrgSeats checks the organization has a free seat for each of adding
+// new members while its subscription limits seats. It writes the error
+// response and returns false when it does not.
+func checkOrgSeats(c *gin.Context, orgID uint, adding int) bool {
+	sub, err := models.FindOrgSubscription(database.DB, orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to load organization subscription"})
+		return false
+	}
+	if sub == nil || !sub.LimitsSeats() {
+		return true
+	}
+
+	used, err := models.CountOrgSeatsTaken(database.DB, orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to count seats"})
+		return false
+	}
+	if used+adding > sub.Seats {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: fmt.Sprintf("Organization has %d of %d seats taken; add seats first", used, sub.Seats)})
+		return false
+	}
+	return true
+}
+
+// completeOrgCheckout records the subscription bought in a checkout session
+// for the organization named in its metadata. Only an admin of the
+// organization may buy its seats.
+func completeOrgCheckout(ctx context.Context, db *gorm.DB, buyer *models.User, sess *billing.CheckoutSession) error {
+	orgID, err := strconv.ParseUint(sess.Metadata[orgSubscriptionKey], 10, 32)
+	if err != nil {
+		fmt.Printf("Invalid organization in metadata of session %s\n", sess.ID)
+		return nil
+	}
+	if !buyer.IsAdmin() && !buyer.IsOrgAdmin(uint(orgID)) {
+		fmt.Printf("User %d no longer administers organization %d of session %s\n", buyer.ID, orgID, sess.ID)
+		return nil
+	}
+
+	sub, err := models.FindOrgSubscription(db, uint(orgID))
+	if err != nil {
+		return err
+	}
+	if sub == nil || sub.StripeCustomerID != sess.CustomerID {
+		fmt.Printf("Checkout session %s is not billed to organization %d\n", sess.ID, orgID)
+		return nil
+	}
+	if !sess.Paid || sess.SubscriptionID == "" {
+		return nil
+	}
+
+	subscription, err := billing.Client().GetSubscription(ctx, sess.SubscriptionID)
+	if err != nil {
+		return fmt.Errorf("error retrieving subscription: %w", err)
+	}
+	_, err = applyOrgSubscription(db, sub, subscription, subscription.Status)
+	return err
+}
+
+// saveOrgSubscriptionEvent stores a subscription webhook for an
+// organization's seats. It reports false when the subscription is not billed
+// to an organization.
+func saveOrgSubscriptionEvent(db *gorm.DB, subscription *billing.Subscription, status string) (bool, error) {
+	sub, err := models.FindOrgSubscriptionByCustomer(db, subscription.CustomerID)
+	if err != nil || sub == nil {
+		return err != nil, err
+	}
+	if sub.SubscriptionID != "" && sub.SubscriptionID != subscription.ID {
+		fmt.Printf("Subscription %s is not the current one of organization %d\n", subscription.ID, sub.OrganizationID)
+		return true, nil
+	}
+	return applyOrgSubscription(db, sub, subscription, status)
+}
+
+// applyOrgSubscription stores the subscription's state on the organization's
+// record and, when that changes whether members hold the plan, drops their
+// cached limits
+func applyOrgSubscription(db *gorm.DB, sub *models.OrgSubscription, subscription *billing.Subscription, status string) (bool, error) {
+	wasActive, previousPlan := sub.IsActive(), sub.PlanID
+	periodEnd := subscription.CurrentPeriodEnd
+	if err := sub.UpdateSubscription(db, subscription.ID, subscription.ItemID, subscription.PriceID, status, int(subscription.Quantity), &periodEnd); err != nil {
+		return true, err
+	}
+
+	if sub.IsActive() != wasActive || sub.PlanID != previousPlan {
+		members, err := models.FindOrgMemberIDs(db, sub.OrganizationID)
+		if err != nil {
+			log.Printf("Failed to refresh limits of organization %d: %v", sub.OrganizationID, err)
+			plans.Invalidate()
+		} else if len(members) > 0 {
+			plans.Invalidate(members...)
+		}
+	}
+	return true, nil
+}
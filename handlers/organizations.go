@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/jobs"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/plans"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateOrganizationRequest represents the request body for creating an organization
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required" example:"Northside Neurology Clinic"`
+}
+
+// OrganizationsResponse represents a list of organizations
+type OrganizationsResponse struct {
+	Organizations []models.Organization `json:"organizations"`
+}
+
+// SetUserOrganizationRequest represents the request body for assigning a user to an organization.
+// A null organization_id removes the user from their organization.
+type SetUserOrganizationRequest struct {
+	OrganizationID *uint `json:"organization_id" example:"1"`
+	// Role within the organization; defaults to member when the organization changes
+	OrgRole *string `json:"org_role" binding:"omitempty,oneof=member admin" example:"admin"`
+}
+
+// OrgRatePlanRequest represents the request body for setting an organization's rate plan.
+// Omitted fields fall back to the member's plan defaults; -1 means unlimited.
+type OrgRatePlanRequest struct {
+	RequestsPerMinute    *int   `json:"requests_per_minute" binding:"omitempty,min=-1" example:"1200"`
+	UploadsPerMonth      *int   `json:"uploads_per_month" binding:"omitempty,min=-1" example:"5000"`
+	TranslationsPerMonth *int   `json:"translations_per_month" binding:"omitempty,min=-1" example:"5000"`
+	StorageBytes         *int64 `json:"storage_bytes" binding:"omitempty,min=-1" example:"107374182400"`
+	// Capped by MAX_UPLOAD_SIZE_MB whatever the plan says
+	MaxFileBytes        *int64 `json:"max_file_bytes" binding:"omitempty,min=-1" example:"209715200"`
+	UploadBytesPerMonth *int64 `json:"upload_bytes_per_month" binding:"omitempty,min=-1" example:"536870912000"`
+	// What happens to members' raw recordings after their subscription lapses
+	LapseAction    *string `json:"lapse_action" binding:"omitempty,oneof=retain archive purge" example:"archive"`
+	LapseGraceDays *int    `json:"lapse_grace_days" binding:"omitempty,min=0" example:"90"`
+	Notes          string  `json:"notes" example:"Enterprise contract 2026"`
+}
+
+// OrgRatePlanResponse represents an organization's custom rate plan
+type OrgRatePlanResponse struct {
+	RatePlan *models.OrgRatePlan `json:"rate_plan"`
+}
+
+// CreateOrganization creates a new organization
+// @Summary Create an organization
+// @Description Creates an organization that users can be assigned to (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param organization body CreateOrganizationRequest true "Organization details"
+// @Success 201 {object} models.Organization "Created organization"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/orgs [post]
+func CreateOrganization(c *gin.Context) {
+	var req CreateOrganizationRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	org, err := models.CreateOrganization(database.DB, req.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create organization"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, org)
+}
+
+// GetOrganizations lists all organizations
+// @Summary List organizations
+// @Description Lists all organizations (admin only)
+// @Tags admin
+// @Produce json
+// @Success 200 {object} OrganizationsResponse "Organizations"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/orgs [get]
+func GetOrganizations(c *gin.Context) {
+	orgs, err := models.FindAllOrganizations(database.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch organizations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, OrganizationsResponse{Organizations: orgs})
+}
+
+// SetUserOrganization assigns a user to an organization
+// @Summary Assign a user to an organization
+// @Description Assigns a user to an organization, or removes them with a null organization_id, optionally setting their role within it; organization admins can view its audit log (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param organization body SetUserOrganizationRequest true "Organization assignment"
+// @Success 200 {object} MessageResponse "Organization updated"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - User or organization not found"
+// @Failure 409 {object} ErrorResponse "Conflict - All of the organization's seats are taken"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/users/{id}/organization [put]
+func SetUserOrganization(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	var req SetUserOrganizationRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, err := models.FindUserByID(database.DB, uint(userID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		return
+	}
+
+	if req.OrganizationID != nil {
+		if _, err := models.FindOrganizationByID(database.DB, *req.OrganizationID); err != nil {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Organization not found"})
+			return
+		}
+	}
+
+	if req.OrgRole != nil && req.OrganizationID == nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "org_role requires an organization_id"})
+		return
+	}
+
+	// A new member takes one of the organization's seats
+	joining := req.OrganizationID != nil && (user.OrganizationID == nil || *user.OrganizationID != *req.OrganizationID)
+	if joining && !checkOrgSeats(c, *req.OrganizationID, 1) {
+		return
+	}
+
+	previousOrgID, previousRole := user.OrganizationID, user.OrgRole
+	if err := user.SetOrganization(database.DB, req.OrganizationID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update organization"})
+		return
+	}
+	if req.OrgRole != nil {
+		if err := user.SetOrgRole(database.DB, *req.OrgRole); err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update organization role"})
+			return
+		}
+	}
+	plans.Invalidate(user.ID)
+
+	adminID := c.MustGet("userID").(uint)
+	resource := fmt.Sprintf("user:%d", user.ID)
+	moved := previousOrgID == nil || req.OrganizationID == nil || *previousOrgID != *req.OrganizationID
+	if previousOrgID != nil && moved {
+		auditOrgAction(c, adminID, *previousOrgID, "organization.member_remove", resource, nil)
+	}
+	if req.OrganizationID != nil {
+		if moved {
+			auditOrgAction(c, adminID, *req.OrganizationID, "organization.member_add", resource, gin.H{"org_role": user.OrgRole})
+		} else if user.OrgRole != previousRole {
+			auditOrgAction(c, adminID, *req.OrganizationID, "organization.member_role", resource, gin.H{"from": previousRole, "to": user.OrgRole})
+		}
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Organization updated"})
+}
+
+// GetOrgRatePlan returns an organization's custom rate plan
+// @Summary Get an organization's rate plan
+// @Description Returns the custom limits configured for an organization, or null if it uses plan defaults (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} OrgRatePlanResponse "Rate plan"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/orgs/{id}/rate-plan [get]
+func GetOrgRatePlan(c *gin.Context) {
+	org, ok := organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	ratePlan, err := models.FindOrgRatePlan(database.DB, org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch rate plan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, OrgRatePlanResponse{RatePlan: ratePlan})
+}
+
+// SetOrgRatePlan creates or replaces an organization's custom rate plan
+// @Summary Set an organization's rate plan
+// @Description Sets custom rate limits, quotas and lapse data policy that override plan defaults for all members of an organization (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param plan body OrgRatePlanRequest true "Custom limits"
+// @Success 200 {object} OrgRatePlanResponse "Rate plan"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/orgs/{id}/rate-plan [put]
+func SetOrgRatePlan(c *gin.Context) {
+	org, ok := organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	var req OrgRatePlanRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ratePlan := &models.OrgRatePlan{
+		OrganizationID:       org.ID,
+		RequestsPerMinute:    req.RequestsPerMinute,
+		UploadsPerMonth:      req.UploadsPerMonth,
+		TranslationsPerMonth: req.TranslationsPerMonth,
+		StorageBytes:         req.StorageBytes,
+		MaxFileBytes:         req.MaxFileBytes,
+		UploadBytesPerMonth:  req.UploadBytesPerMonth,
+		LapseAction:          req.LapseAction,
+		LapseGraceDays:       req.LapseGraceDays,
+		Notes:                req.Notes,
+	}
+	if err := models.UpsertOrgRatePlan(database.DB, ratePlan); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save rate plan"})
+		return
+	}
+	plans.Invalidate()
+
+	saved, err := models.FindOrgRatePlan(database.DB, org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch rate plan"})
+		return
+	}
+	auditOrgAction(c, c.MustGet("userID").(uint), org.ID, "organization.rate_plan_update", orgResource(org.ID), gin.H{"rate_plan": saved})
+
+	c.JSON(http.StatusOK, OrgRatePlanResponse{RatePlan: saved})
+}
+
+// DeleteOrgRatePlan removes an organization's custom rate plan
+// @Summary Remove an organization's rate plan
+// @Description Removes custom limits so members fall back to their plan defaults (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} MessageResponse "Rate plan removed"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/orgs/{id}/rate-plan [delete]
+func DeleteOrgRatePlan(c *gin.Context) {
+	org, ok := organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	if err := models.DeleteOrgRatePlan(database.DB, org.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to remove rate plan"})
+		return
+	}
+	plans.Invalidate()
+	auditOrgAction(c, c.MustGet("userID").(uint), org.ID, "organization.rate_plan_delete", orgResource(org.ID), nil)
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Rate plan removed"})
+}
+
+// organizationFromParam loads the organization named by the :id path parameter,
+// writing an error response and returning false if it cannot
+func organizationFromParam(c *gin.Context) (*models.Organization, bool) {
+	orgID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid organization ID"})
+		return nil, false
+	}
+
+	org, err := models.FindOrganizationByID(database.DB, uint(orgID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Organization not found"})
+		return nil, false
+	}
+
+	return org, true
+}
+
+// GetOrgRetentionReport generates a data-retention compliance report for an organization
+// @Summary Get an organization's retention compliance report
+// @Description Returns a point-in-time audit snapshot of an organization's data: the effective lapse and purge policies, stored reports and report revisions by age, raw recording status of members, and reports and recordings pending deletion or archival. Controls this deployment does not record, such as legal holds and data residency, are listed under not_tracked (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} jobs.RetentionReport "Retention report"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/orgs/{id}/retention-report [get]
+func GetOrgRetentionReport(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	org, ok := organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	purgeAfterDays, err := jobs.ReportPurgeAfterDays()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Invalid retention configuration"})
+		return
+	}
+
+	report, err := jobs.BuildRetentionReport(database.DB, org, purgeAfterDays)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to generate retention report"})
+		return
+	}
+
+	auditOrgAction(c, userID.(uint), org.ID, "organization.retention_report", orgResource(org.ID), nil)
+	c.JSON(http.StatusOK, report)
+}
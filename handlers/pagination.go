@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Default and maximum page sizes for paginated list endpoints
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 100
+)
+
+// maxPageOffset bounds offset paging; deeper pages are fetched by cursor,
+// which seeks in an index instead of scanning every skipped row
+const maxPageOffset = 10000
+
+// Pagination is the standard pagination metadata returned by list endpoints.
+// Pass next_cursor as the cursor query parameter to fetch the following page.
+type Pagination struct {
+	Total      int64  `json:"total" example:"128"`
+	Limit      int    `json:"limit" example:"50"`
+	Offset     int    `json:"offset,omitempty" example:"0"`
+	HasMore    bool   `json:"has_more" example:"true"`
+	NextCursor string `json:"next_cursor,omitempty" example:"MTcxNjQ2NTYwMDAwMDAwMDAwMDo0Mg"`
+	Sort       string `json:"sort,omitempty" example:"matching_scale:desc"`
+}
+
+// parsePage reads the limit, offset and cursor query parameters. It writes the
+// error response and returns false on failure.
+func parsePage(c *gin.Context) (models.Page, bool) {
+	page := models.Page{Cursor: c.Query("cursor")}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultPageLimit)))
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "limit must be between 1 and " + strconv.Itoa(maxPageLimit)})
+		return page, false
+	}
+	page.Limit = limit
+
+	if offsetParam := c.Query("offset"); offsetParam != "" {
+		if page.Cursor != "" {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Use either offset or cursor, not both"})
+			return page, false
+		}
+		offset, err := strconv.Atoi(offsetParam)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "offset must be a non-negative integer"})
+			return page, false
+		}
+		if offset > maxPageOffset {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "offset must be at most " + strconv.Itoa(maxPageOffset) + "; page further with cursor"})
+			return page, false
+		}
+		page.Offset = offset
+	}
+
+	return page, true
+}
+
+// parseSortedPage reads the page parameters and the sort parameter, a
+// comma-separated list of field:direction pairs checked against fields. Sorted
+// lists are paged by offset, so sort cannot be combined with cursor. It writes
+// the error response and returns false on failure.
+func parseSortedPage(c *gin.Context, fields models.SortFields) (models.Page, bool) {
+	page, ok := parsePage(c)
+	if !ok {
+		return page, false
+	}
+
+	orders, err := fields.Parse(c.Query("sort"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return page, false
+	}
+	if len(orders) > 0 && page.Cursor != "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Use either sort or cursor, not both"})
+		return page, false
+	}
+	page.Sort = orders
+
+	return page, true
+}
+
+// formatSort writes sort orders back in the form of the sort parameter
+func formatSort(orders []models.SortOrder) string {
+	fields := make([]string, 0, len(orders))
+	for _, order := range orders {
+		fields = append(fields, order.String())
+	}
+	return strings.Join(fields, ",")
+}
+
+// newPagination builds the response metadata for a page
+func newPagination(page models.Page, info models.PageInfo) Pagination {
+	return Pagination{
+		Total:      info.Total,
+		Limit:      page.Limit,
+		Offset:     page.Offset,
+		HasMore:    info.HasMore,
+		NextCursor: info.NextCursor,
+		Sort:       formatSort(page.Sort),
+	}
+}
@@ -1,20 +1,24 @@
 package handlers
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/ThinkInkTeam/thinkink-core-backend/database"
 	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/billing"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/iap"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/jobs"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/stripeevents"
 	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
 	"github.com/gin-gonic/gin"
-	"github.com/stripe/stripe-go/v72"
-	"github.com/stripe/stripe-go/v72/checkout/session"
-	"github.com/stripe/stripe-go/v72/customer"
-	"github.com/stripe/stripe-go/v72/sub"
-	"github.com/stripe/stripe-go/v72/webhook"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 // CreateCheckoutSessionRequest represents the request body for creating a checkout session
@@ -22,6 +26,10 @@ type CreateCheckoutSessionRequest struct {
 	PlanID     string `json:"plan_id" binding:"required" example:"price_1Oxy3JExamplePriceID"`
 	SuccessURL string `json:"success_url" binding:"required" example:"https://yourapp.com/success?session_id={CHECKOUT_SESSION_ID}"`
 	CancelURL  string `json:"cancel_url" binding:"required" example:"https://yourapp.com/cancel"`
+	// Optional promotion code applied up front; without one the customer can enter a code at checkout
+	PromoCode string `json:"promo_code" example:"SPRING25"`
+	// Optional ISO 4217 currency to pay in; defaults to the currency of the user's country when the plan offers it
+	Currency string `json:"currency" example:"eur"`
 }
 
 // CreateOneTimeCheckoutRequest represents the request body for one-time checkout
@@ -31,12 +39,18 @@ type CreateOneTimeCheckoutRequest struct {
 	ProductName string `json:"product_name" binding:"required" example:"Premium Report"`
 	SuccessURL  string `json:"success_url" binding:"required" example:"https://yourapp.com/success?session_id={CHECKOUT_SESSION_ID}"`
 	CancelURL   string `json:"cancel_url" binding:"required" example:"https://yourapp.com/cancel"`
+	// Optional promotion code applied up front; without one the customer can enter a code at checkout
+	PromoCode string `json:"promo_code" example:"SPRING25"`
 }
 
 // CheckoutResponse is the response returned for checkout session creation
 type CheckoutResponse struct {
 	SessionID string `json:"sessionId" example:"cs_test_a1b2c3d4e5f6g7h8i9j0"`
 	URL       string `json:"url" example:"https://checkout.stripe.com/pay/cs_test_a1b2c3d4e5f6g7h8i9j0"`
+	// For subscriptions: the price charged, which may be another currency's price of the requested plan
+	PlanID string `json:"plan_id,omitempty" example:"price_1Oxy3JExamplePriceID"`
+	// The currency charged, when it was chosen or inferred
+	Currency string `json:"currency,omitempty" example:"eur"`
 }
 
 // SubscriptionResponse represents a subscription response
@@ -47,6 +61,12 @@ type SubscriptionResponse struct {
 	Status            string     `json:"status,omitempty" example:"active"`
 	CancelAtPeriodEnd bool       `json:"cancel_at_period_end,omitempty" example:"false"`
 	CurrentPeriodEnd  *time.Time `json:"current_period_end,omitempty"`
+	// Coupon applied to the subscription, if any
+	Discount *models.SubscriptionDiscount `json:"discount,omitempty"`
+	// Set while a renewal payment has failed: premium features stay available until then
+	PaymentGraceEndsAt *time.Time `json:"payment_grace_ends_at,omitempty"`
+	// app_store or google_play for subscriptions bought in the mobile apps
+	Store string `json:"store,omitempty" example:"app_store"`
 }
 
 // ErrorResponse represents an error response
@@ -80,7 +100,7 @@ type WebhookResponse struct {
 
 // CreateCheckoutSessionHandler creates a Stripe Checkout session for subscription
 // @Summary Create a subscription checkout session
-// @Description Creates a Stripe checkout session for subscription payments
+// @Description Creates a Stripe checkout session for subscription payments. A promo_code is checked against Stripe and applied up front; without one the customer can enter a code on the checkout page. The plan is charged in the requested currency, or else the currency of the user's country when the plan offers it, switching to the plan's price in that currency if it has a separate one.
 // @Tags payment
 // @Accept json
 // @Produce json
@@ -89,13 +109,14 @@ type WebhookResponse struct {
 // @Failure 400 {object} ErrorResponse "Bad request"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
 // @Failure 500 {object} ErrorResponse "Internal server error"
+// @Failure 503 {object} ErrorResponse "Plan catalog unavailable to check the requested currency"
 // @Security BearerAuth
 // @Router /payment/checkout/subscription [post]
 func CreateCheckoutSessionHandler(c *gin.Context) {
 	// Parse request
 	var req CreateCheckoutSessionRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -111,50 +132,40 @@ func CreateCheckoutSessionHandler(c *gin.Context) {
 		return
 	}
 
-	// Create or retrieve customer
-	var customerID string
-	if user.StripeCustomerID != nil {
-		customerID = *user.StripeCustomerID
-	} else {
-		// Create new customer in Stripe
-		customerParams := user.ToStripeCustomerParams()
-		newCustomer, err := customer.New(customerParams)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Error creating Stripe customer: %v", err)})
-			return
-		}
-
-		// Update user with Stripe customer ID
-		if err := user.UpdateStripeData(db, newCustomer.ID, ""); err != nil {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Error updating user data: %v", err)})
-			return
-		}
+	// Pick the price of the plan in the requested or the user's currency
+	planID, currency, ok := checkoutPlanPrice(c, user, req.PlanID, req.Currency)
+	if !ok {
+		return
+	}
 
-		customerID = newCustomer.ID
+	// Create or retrieve customer
+	customerID, err := ensureStripeCustomer(c.Request.Context(), db, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
 	}
 
-	// Create checkout session params
-	params := &stripe.CheckoutSessionParams{
-		Customer: stripe.String(customerID),
-		PaymentMethodTypes: stripe.StringSlice([]string{
-			"card",
-		}),
-		Mode: stripe.String(string(stripe.CheckoutSessionModeSubscription)),
-		LineItems: []*stripe.CheckoutSessionLineItemParams{
-			{
-				Price:    stripe.String(req.PlanID),
-				Quantity: stripe.Int64(1),
-			},
+	in := billing.CheckoutSessionInput{
+		CustomerID: customerID,
+		Mode:       billing.CheckoutModeSubscription,
+		Currency:   currency,
+		LineItems: []billing.LineItem{
+			{PriceID: planID, Quantity: 1},
+		},
+		SuccessURL:    req.SuccessURL,
+		CancelURL:     req.CancelURL,
+		CollectTaxIDs: true,
+		AutomaticTax:  automaticTax(),
+		Metadata: map[string]string{
+			"plan_id": planID,
 		},
-		SuccessURL: stripe.String(req.SuccessURL),
-		CancelURL:  stripe.String(req.CancelURL),
+	}
+	if !applyPromoCode(c, req.PromoCode, &in) {
+		return
 	}
 
-	// Add metadata to identify user in webhook
-	params.AddMetadata("user_id", fmt.Sprintf("%d", user.ID))
-	params.AddMetadata("plan_id", req.PlanID)
-
-	sess, err := session.New(params)
+	// Create checkout session, with a token identifying the user in its metadata
+	sess, err := createCheckoutSession(c.Request.Context(), db, user, in)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Error creating checkout session: %v", err)})
 		return
@@ -163,12 +174,14 @@ func CreateCheckoutSessionHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, CheckoutResponse{
 		SessionID: sess.ID,
 		URL:       sess.URL,
+		PlanID:    planID,
+		Currency:  currency,
 	})
 }
 
 // CreateOneTimeCheckoutHandler creates a Stripe Checkout session for one-time payment
 // @Summary Create a one-time payment checkout session
-// @Description Creates a Stripe checkout session for one-time payments
+// @Description Creates a Stripe checkout session for one-time payments. A promo_code is checked against Stripe and applied up front; without one the customer can enter a code on the checkout page.
 // @Tags payment
 // @Accept json
 // @Produce json
@@ -183,7 +196,7 @@ func CreateOneTimeCheckoutHandler(c *gin.Context) {
 	// Parse request
 	var req CreateOneTimeCheckoutRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -200,54 +213,34 @@ func CreateOneTimeCheckoutHandler(c *gin.Context) {
 	}
 
 	// Create or retrieve customer
-	var customerID string
-	if user.StripeCustomerID != nil {
-		customerID = *user.StripeCustomerID
-	} else {
-		// Create new customer in Stripe
-		customerParams := user.ToStripeCustomerParams()
-		newCustomer, err := customer.New(customerParams)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Error creating Stripe customer: %v", err)})
-			return
-		}
-
-		// Update user with Stripe customer ID
-		if err := user.UpdateStripeData(db, newCustomer.ID, ""); err != nil {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Error updating user data: %v", err)})
-			return
-		}
-
-		customerID = newCustomer.ID
+	customerID, err := ensureStripeCustomer(c.Request.Context(), db, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
 	}
 
-	// Create checkout session params
-	params := &stripe.CheckoutSessionParams{
-		Customer: stripe.String(customerID),
-		PaymentMethodTypes: stripe.StringSlice([]string{
-			"card",
-		}),
-		Mode: stripe.String(string(stripe.CheckoutSessionModePayment)),
-		LineItems: []*stripe.CheckoutSessionLineItemParams{
+	in := billing.CheckoutSessionInput{
+		CustomerID: customerID,
+		Mode:       billing.CheckoutModePayment,
+		LineItems: []billing.LineItem{
 			{
-				PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
-					Currency: stripe.String(req.Currency),
-					ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
-						Name: stripe.String(req.ProductName),
-					},
-					UnitAmount: stripe.Int64(req.Amount),
-				},
-				Quantity: stripe.Int64(1),
+				Currency:    req.Currency,
+				ProductName: req.ProductName,
+				UnitAmount:  req.Amount,
+				Quantity:    1,
 			},
 		},
-		SuccessURL: stripe.String(req.SuccessURL),
-		CancelURL:  stripe.String(req.CancelURL),
+		SuccessURL:    req.SuccessURL,
+		CancelURL:     req.CancelURL,
+		CollectTaxIDs: true,
+		AutomaticTax:  automaticTax(),
+	}
+	if !applyPromoCode(c, req.PromoCode, &in) {
+		return
 	}
 
-	// Add metadata to identify user in webhook
-	params.AddMetadata("user_id", fmt.Sprintf("%d", user.ID))
-
-	sess, err := session.New(params)
+	// Create checkout session, with a token identifying the user in its metadata
+	sess, err := createCheckoutSession(c.Request.Context(), db, user, in)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Error creating checkout session: %v", err)})
 		return
@@ -261,7 +254,7 @@ func CreateOneTimeCheckoutHandler(c *gin.Context) {
 
 // CancelSubscriptionHandler cancels a subscription at the end of the current period
 // @Summary Cancel a subscription
-// @Description Cancels the user's subscription at the end of the current billing period
+// @Description Cancels the user's subscription at the end of the current billing period. Subscriptions bought in the mobile apps are canceled in the App Store or Google Play instead.
 // @Tags payment
 // @Accept json
 // @Produce json
@@ -269,6 +262,7 @@ func CreateOneTimeCheckoutHandler(c *gin.Context) {
 // @Failure 400 {object} ErrorResponse "Bad request"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
 // @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 409 {object} ErrorResponse "Subscription was bought in an app store"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Security BearerAuth
 // @Router /payment/subscription/cancel [post]
@@ -289,22 +283,21 @@ func CancelSubscriptionHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "No active subscription found"})
 		return
 	}
-
-	// Cancel the subscription at period end
-	params := &stripe.SubscriptionParams{
-		CancelAtPeriodEnd: stripe.Bool(true),
+	if user.SubscriptionStore != "" {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Subscription is billed by " + storeName(iap.Store(user.SubscriptionStore)) + " and must be canceled there"})
+		return
 	}
 
-	// Make the API call to cancel
-	subscription, err := sub.Update(*user.SubscriptionID, params)
+	// Cancel the subscription at period end
+	subscription, err := billing.Client().SetCancelAtPeriodEnd(c.Request.Context(), *user.SubscriptionID, true)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Error canceling subscription: %v", err)})
 		return
 	}
 
 	// Update subscription status in database
-	periodEnd := time.Unix(subscription.CurrentPeriodEnd, 0)
-	if err := user.UpdateSubscriptionData(db, subscription.ID, *user.CurrentPlanID, string(subscription.Status), &periodEnd); err != nil {
+	periodEnd := subscription.CurrentPeriodEnd
+	if err := user.UpdateSubscriptionData(db, subscription.ID, *user.CurrentPlanID, subscription.Status, &periodEnd); err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Error updating subscription data: %v", err)})
 		return
 	}
@@ -313,16 +306,16 @@ func CancelSubscriptionHandler(c *gin.Context) {
 		Message: "Subscription will be canceled at the end of the current billing period",
 		Subscription: SubscriptionDetails{
 			ID:                subscription.ID,
-			Status:            string(subscription.Status),
+			Status:            subscription.Status,
 			CancelAtPeriodEnd: subscription.CancelAtPeriodEnd,
-			CurrentPeriodEnd:  time.Unix(subscription.CurrentPeriodEnd, 0),
+			CurrentPeriodEnd:  subscription.CurrentPeriodEnd,
 		},
 	})
 }
 
 // GetSubscriptionHandler gets the current subscription status
 // @Summary Get subscription details
-// @Description Returns details about the user's current subscription
+// @Description Returns details about the user's current subscription, including subscriptions bought in the App Store or Google Play as last validated
 // @Tags payment
 // @Accept json
 // @Produce json
@@ -352,43 +345,64 @@ func GetSubscriptionHandler(c *gin.Context) {
 		return
 	}
 
-	// Get subscription details from Stripe
-	subscription, err := sub.Get(*user.SubscriptionID, nil)
+	// Get subscription details from Stripe, unless bought in an app store
+	var subscription *billing.Subscription
+	if user.SubscriptionStore == "" {
+		subscription, err = billing.Client().GetSubscription(c.Request.Context(), *user.SubscriptionID)
+	}
 
-	if err != nil {
+	if user.SubscriptionStore != "" || err != nil {
 		// If can't retrieve from Stripe, return the local data
 		endsAt := user.SubscriptionEndsAt
 
-		c.JSON(http.StatusOK, SubscriptionResponse{
-			HasSubscription:  user.IsSubscribed(),
-			PlanID:           *user.CurrentPlanID,
-			Status:           *user.SubscriptionStatus,
-			CurrentPeriodEnd: endsAt,
-		})
+		response := SubscriptionResponse{
+			HasSubscription:    user.IsSubscribed(),
+			PlanID:             *user.CurrentPlanID,
+			Status:             *user.SubscriptionStatus,
+			CurrentPeriodEnd:   endsAt,
+			PaymentGraceEndsAt: user.PaymentGraceEndsAt,
+			Store:              user.SubscriptionStore,
+		}
+		if user.SubscriptionStore != "" {
+			response.SubscriptionID = *user.SubscriptionID
+		}
+		if user.SubscriptionDiscount.CouponID != "" {
+			response.Discount = &user.SubscriptionDiscount
+		}
+		c.JSON(http.StatusOK, response)
 		return
 	}
 
 	// Return subscription details
-	periodEnd := time.Unix(subscription.CurrentPeriodEnd, 0)
+	periodEnd := subscription.CurrentPeriodEnd
 
-	c.JSON(http.StatusOK, SubscriptionResponse{
-		HasSubscription:   subscription.Status == stripe.SubscriptionStatusActive || subscription.Status == stripe.SubscriptionStatusTrialing,
+	// A past-due subscription keeps its entitlements during the payment grace period
+	response := SubscriptionResponse{
+		HasSubscription:   subscription.IsActive() || (subscription.Status == models.SubscriptionStatusPastDue && user.IsSubscribed()),
 		SubscriptionID:    subscription.ID,
 		PlanID:            *user.CurrentPlanID,
-		Status:            string(subscription.Status),
+		Status:            subscription.Status,
 		CancelAtPeriodEnd: subscription.CancelAtPeriodEnd,
 		CurrentPeriodEnd:  &periodEnd,
-	})
+	}
+	if subscription.Status == models.SubscriptionStatusPastDue {
+		response.PaymentGraceEndsAt = user.PaymentGraceEndsAt
+	}
+	if discount := subscriptionDiscount(subscription); discount.CouponID != "" {
+		response.Discount = &discount
+	}
+	c.JSON(http.StatusOK, response)
 }
 
-// StripeWebhookHandler processes incoming webhook events from Stripe
-// @Summary Process Stripe webhook events
-// @Description Handles Stripe webhook events for subscription updates, payments, etc.
+// StripeWebhookHandler verifies and queues incoming webhook events from Stripe
+// @Summary Receive Stripe webhook events
+// @Description Verifies the signature of a Stripe webhook event and queues it for background processing of subscription updates, payments, etc. Redeliveries of a queued event are acknowledged without queueing it again.
 // @Tags webhook
 // @Accept json
 // @Produce json
-// @Success 200 {object} WebhookResponse "Webhook processed"
+// @Success 200 {object} WebhookResponse "Webhook received"
 // @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 500 {object} ErrorResponse "Event could not be queued"
 // @Router /stripe/webhook [post]
 func StripeWebhookHandler(c *gin.Context) {
 	// Read request body
@@ -402,169 +416,397 @@ func StripeWebhookHandler(c *gin.Context) {
 	webhookSecret := utils.GetEnvWithDefault("STRIPE_WEBHOOK_SECRET", "whsec_your_webhook_secret")
 
 	// Verify signature
-	event, err := webhook.ConstructEvent(payload, c.GetHeader("Stripe-Signature"), webhookSecret)
+	event, err := billing.Client().ConstructEvent(payload, c.GetHeader("Stripe-Signature"), webhookSecret)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("Webhook signature verification failed: %v", err)})
 		return
 	}
 
-	db := database.DB
+	// Catalog changes refresh the plan list
+	invalidatePlanCatalog(event.Type)
 
-	// Handle the event based on its type
-	switch event.Type {
-	case "checkout.session.completed":
-		var sess stripe.CheckoutSession
-		err := json.Unmarshal(event.Data.Raw, &sess)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Error parsing webhook payload"})
-			return
-		}
+	// Acknowledge once stored; the background worker processes the event
+	queued, err := models.EnqueueStripeEvent(database.DB, &models.StripeEvent{
+		EventID:         event.ID,
+		Type:            event.Type,
+		Payload:         datatypes.JSON(event.Data),
+		StripeCreatedAt: &event.Created,
+	})
+	if err != nil {
+		// Let Stripe redeliver the event once the database is back
+		log.Printf("Error queueing Stripe event %s: %v", event.ID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Error queueing event"})
+		return
+	}
+	if !queued {
+		log.Printf("Stripe event %s already received", event.ID)
+	}
 
-		// Process the session completion
-		userIDStr, ok := sess.Metadata["user_id"]
-		if !ok {
-			fmt.Println("No user_id in session metadata")
-			break
-		}
+	c.JSON(http.StatusOK, WebhookResponse{Received: true})
+}
 
-		var userID uint
-		fmt.Sscanf(userIDStr, "%d", &userID)
+// Stripe webhook events applied by the payment handlers. Failures that may
+// pass, such as database or Stripe outages, are returned to be retried.
+func init() {
+	stripeevents.OnCheckoutSession("checkout.session.completed", handleCheckoutCompleted)
+	for _, eventType := range []string{"customer.subscription.updated", "customer.subscription.created", "customer.subscription.past_due"} {
+		stripeevents.OnSubscription(eventType, handleSubscriptionChanged)
+	}
+	stripeevents.OnSubscription("customer.subscription.deleted", handleSubscriptionDeleted)
+	stripeevents.OnInvoice("invoice.payment_failed", handleInvoicePaymentFailed)
+	stripeevents.OnPaymentMethod("payment_method.attached", handlePaymentMethodAttached)
+	stripeevents.OnTaxID("customer.tax_id.created", handleTaxIDSaved)
+	stripeevents.OnTaxID("customer.tax_id.updated", handleTaxIDSaved)
+	stripeevents.OnTaxID("customer.tax_id.deleted", handleTaxIDDeleted)
+}
 
-		user, err := models.FindUserByID(db, userID)
-		if err != nil {
-			fmt.Printf("User not found: %v\n", err)
-			break
+// handleCheckoutCompleted stores the customer, payment method and
+// subscription of a completed checkout
+func handleCheckoutCompleted(ctx context.Context, db *gorm.DB, event *billing.Event, sess *billing.CheckoutSession) error {
+	// Identify the user by the session's signed checkout token
+	signed, ok := sess.Metadata[checkoutTokenKey]
+	if !ok {
+		fmt.Printf("No checkout token in metadata of session %s\n", sess.ID)
+		return nil
+	}
+	userID, err := models.ConsumeCheckoutToken(db, checkoutTokenSecret(), signed, sess.ID)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCheckoutToken) {
+			fmt.Printf("Rejected checkout token of session %s: %v\n", sess.ID, err)
+			return nil
 		}
+		return fmt.Errorf("error verifying checkout token: %w", err)
+	}
 
-		// Update the payment method if available
-		if sess.PaymentStatus == stripe.CheckoutSessionPaymentStatusPaid && sess.Customer != nil {
-			customerID := sess.Customer.ID
-
-			// Update customer ID if needed
-			if user.StripeCustomerID == nil {
-				user.UpdateStripeData(db, customerID, "")
-			}
-
-			// If this was a subscription purchase
-			if sess.Mode == stripe.CheckoutSessionModeSubscription && sess.Subscription != nil {
-				// Get subscription details
-				subscription, err := sub.Get(sess.Subscription.ID, nil)
-
-				if err != nil {
-					fmt.Printf("Error retrieving subscription: %v\n", err)
-					break
-				}
-
-				// Get plan ID
-				var planID string
-				if len(subscription.Items.Data) > 0 && subscription.Items.Data[0].Price != nil {
-					planID = subscription.Items.Data[0].Price.ID
-				} else {
-					planID = sess.Metadata["plan_id"]
-				}
-
-				// Store subscription details
-				periodEnd := time.Unix(subscription.CurrentPeriodEnd, 0)
-				if err := user.UpdateSubscriptionData(db, subscription.ID, planID, string(subscription.Status), &periodEnd); err != nil {
-					fmt.Printf("Error updating subscription data: %v\n", err)
-				}
-			}
-
-			// Get customer's payment methods and set the default if needed
-			if user.StripeDefaultPM == nil {
-				// Get customer to find default payment method
-				cus, err := customer.Get(customerID, nil)
-				if err == nil && cus.InvoiceSettings.DefaultPaymentMethod != nil {
-					user.UpdateStripeData(db, customerID, cus.InvoiceSettings.DefaultPaymentMethod.ID)
-				}
-			}
-		}
+	user, err := models.FindUserByID(db, userID)
+	if errors.Is(err, models.ErrUserNotFound) {
+		// The account was deleted after checkout; retrying cannot help
+		fmt.Printf("User not found: %v\n", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, ok := sess.Metadata[orgSubscriptionKey]; ok {
+		// Seats bought for an organization
+		return completeOrgCheckout(ctx, db, user, sess)
+	}
+	if user.StripeCustomerID != nil && sess.CustomerID != "" && *user.StripeCustomerID != sess.CustomerID {
+		fmt.Printf("Checkout session %s belongs to another customer than user %d\n", sess.ID, user.ID)
+		return nil
+	}
 
-	case "customer.subscription.updated", "customer.subscription.created":
-		var subscription stripe.Subscription
-		err := json.Unmarshal(event.Data.Raw, &subscription)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Error parsing webhook payload"})
-			return
-		}
+	// Update the payment method if available
+	if !sess.Paid || sess.CustomerID == "" {
+		return nil
+	}
+	gateway := billing.Client()
+	customerID := sess.CustomerID
 
-		// Find customer in our database
-		if subscription.Customer == nil {
-			fmt.Println("No customer attached to subscription")
-			break
-		}
+	// Update customer ID if needed
+	if user.StripeCustomerID == nil {
+		user.UpdateStripeData(db, customerID, "")
+	}
 
-		// Find user by Stripe customer ID
-		var user models.User
-		if err := db.Where("stripe_customer_id = ?", subscription.Customer.ID).First(&user).Error; err != nil {
-			fmt.Printf("User with Stripe customer ID not found: %v\n", err)
-			break
+	// If this was a subscription purchase
+	if sess.Mode == billing.CheckoutModeSubscription && sess.SubscriptionID != "" {
+		// Get subscription details
+		subscription, err := gateway.GetSubscription(ctx, sess.SubscriptionID)
+		if err != nil {
+			return fmt.Errorf("error retrieving subscription: %w", err)
 		}
 
 		// Get plan ID
-		var planID string
-		if len(subscription.Items.Data) > 0 && subscription.Items.Data[0].Price != nil {
-			planID = subscription.Items.Data[0].Price.ID
+		planID := subscription.PriceID
+		if planID == "" {
+			planID = sess.Metadata["plan_id"]
 		}
 
-		// Update subscription details
-		periodEnd := time.Unix(subscription.CurrentPeriodEnd, 0)
-		if err := user.UpdateSubscriptionData(db, subscription.ID, planID, string(subscription.Status), &periodEnd); err != nil {
-			fmt.Printf("Error updating subscription data: %v\n", err)
+		// Store subscription details
+		periodEnd := subscription.CurrentPeriodEnd
+		if err := user.UpdateSubscriptionData(db, subscription.ID, planID, subscription.Status, &periodEnd); err != nil {
+			return fmt.Errorf("error updating subscription data: %w", err)
 		}
-
-	case "customer.subscription.deleted":
-		var subscription stripe.Subscription
-		err := json.Unmarshal(event.Data.Raw, &subscription)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Error parsing webhook payload"})
-			return
+		if user.IsSubscribed() {
+			completeOnboardingStep(db, user, models.OnboardingSubscription)
 		}
+		saveSubscriptionDiscount(db, user, subscriptionDiscount(subscription))
+	}
 
-		// Find customer in our database
-		if subscription.Customer == nil {
-			fmt.Println("No customer attached to subscription")
-			break
+	// Get customer's payment methods and set the default if needed
+	if user.StripeDefaultPM == nil {
+		// Get customer to find default payment method
+		cus, err := gateway.GetCustomer(ctx, customerID)
+		if err == nil && cus.DefaultPaymentMethodID != "" {
+			user.UpdateStripeData(db, customerID, cus.DefaultPaymentMethodID)
 		}
+	}
+	return nil
+}
 
-		// Find user by Stripe customer ID
-		var user models.User
-		if err := db.Where("stripe_customer_id = ?", subscription.Customer.ID).First(&user).Error; err != nil {
-			fmt.Printf("User with Stripe customer ID not found: %v\n", err)
-			break
-		}
+// handleSubscriptionChanged stores a created or updated subscription,
+// starting the payment grace period when a renewal failed
+func handleSubscriptionChanged(ctx context.Context, db *gorm.DB, event *billing.Event, subscription *billing.Subscription) error {
+	if org, err := saveOrgSubscriptionEvent(db, subscription, subscription.Status); org || err != nil {
+		return err
+	}
 
-		// Clear subscription details
-		if err := user.UpdateSubscriptionData(db, "", "", "canceled", nil); err != nil {
-			fmt.Printf("Error updating subscription data: %v\n", err)
-		}
+	user, err := findStripeCustomer(db, subscription.CustomerID)
+	if err != nil || user == nil {
+		return err
+	}
 
-	case "payment_method.attached":
-		var pm stripe.PaymentMethod
-		err := json.Unmarshal(event.Data.Raw, &pm)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Error parsing webhook payload"})
-			return
-		}
+	// Update subscription details
+	periodEnd := subscription.CurrentPeriodEnd
+	status := dunningStatus(user, subscription.Status)
+	if err := user.UpdateSubscriptionData(db, subscription.ID, subscription.PriceID, status, &periodEnd); err != nil {
+		return fmt.Errorf("error updating subscription data: %w", err)
+	}
+	if user.IsSubscribed() {
+		completeOnboardingStep(db, user, models.OnboardingSubscription)
+	}
+	saveSubscriptionDiscount(db, user, subscriptionDiscount(subscription))
 
-		// Find customer in our database
-		if pm.Customer == nil {
-			fmt.Println("No customer attached to payment method")
-			break
+	// A renewal failed; keep entitlements for the grace period
+	if status == models.SubscriptionStatusPastDue {
+		if err := jobs.StartDunning(ctx, db, user, ""); err != nil {
+			return fmt.Errorf("error starting payment grace period: %w", err)
 		}
+	}
+	return nil
+}
 
-		// Find user by Stripe customer ID
-		var user models.User
-		if err := db.Where("stripe_customer_id = ?", pm.Customer.ID).First(&user).Error; err != nil {
-			fmt.Printf("User with Stripe customer ID not found: %v\n", err)
-			break
-		}
+// handleInvoicePaymentFailed starts the payment grace period of the
+// subscription a failed invoice renews
+func handleInvoicePaymentFailed(ctx context.Context, db *gorm.DB, event *billing.Event, invoice *billing.Invoice) error {
+	if invoice.SubscriptionID == "" {
+		// One-time payments have no entitlements to keep or revoke
+		return nil
+	}
+
+	user, err := findStripeCustomer(db, invoice.CustomerID)
+	if err != nil || user == nil {
+		return err
+	}
+	if user.SubscriptionID == nil || *user.SubscriptionID != invoice.SubscriptionID {
+		fmt.Printf("Failed invoice %s is for subscription %s, not the current one of user %d\n", invoice.ID, invoice.SubscriptionID, user.ID)
+		return nil
+	}
+
+	if err := jobs.StartDunning(ctx, db, user, invoice.HostedInvoiceURL); err != nil {
+		return fmt.Errorf("error starting payment grace period: %w", err)
+	}
+	return nil
+}
+
+// handleSubscriptionDeleted clears the subscription of its holder
+func handleSubscriptionDeleted(ctx context.Context, db *gorm.DB, event *billing.Event, subscription *billing.Subscription) error {
+	if org, err := saveOrgSubscriptionEvent(db, subscription, "canceled"); org || err != nil {
+		return err
+	}
+
+	user, err := findStripeCustomer(db, subscription.CustomerID)
+	if err != nil || user == nil {
+		return err
+	}
+
+	// Clear subscription details
+	if err := user.UpdateSubscriptionData(db, "", "", "canceled", nil); err != nil {
+		return fmt.Errorf("error updating subscription data: %w", err)
+	}
+	saveSubscriptionDiscount(db, user, models.SubscriptionDiscount{})
+	return nil
+}
+
+// handlePaymentMethodAttached makes a customer's first payment method their default
+func handlePaymentMethodAttached(ctx context.Context, db *gorm.DB, event *billing.Event, pm *billing.PaymentMethod) error {
+	user, err := findStripeCustomer(db, pm.CustomerID)
+	if err != nil || user == nil {
+		return err
+	}
+
+	// If this is the first payment method, set it as default
+	if user.StripeDefaultPM == nil {
+		user.UpdateStripeData(db, pm.CustomerID, pm.ID)
+	}
+	return nil
+}
+
+// handleTaxIDSaved keeps the local copy of a customer's tax ID, e.g. of tax
+// IDs entered at checkout or verified since
+func handleTaxIDSaved(ctx context.Context, db *gorm.DB, event *billing.Event, taxID *billing.TaxID) error {
+	user, err := findStripeCustomer(db, taxID.CustomerID)
+	if err != nil || user == nil {
+		return err
+	}
+	return models.SaveCustomerTaxID(db, customerTaxID(user.ID, taxID))
+}
+
+// handleTaxIDDeleted removes the local copy of a deleted tax ID
+func handleTaxIDDeleted(ctx context.Context, db *gorm.DB, event *billing.Event, taxID *billing.TaxID) error {
+	return models.DeleteCustomerTaxID(db, taxID.ID)
+}
+
+// findStripeCustomer finds the user holding a Stripe customer ID. It returns
+// no user and no error when the event concerns no customer of ours, and the
+// error when the lookup failed and the event should be retried.
+func findStripeCustomer(db *gorm.DB, customerID string) (*models.User, error) {
+	if customerID == "" {
+		fmt.Println("No customer attached to event object")
+		return nil, nil
+	}
 
-		// If this is the first payment method, set it as default
-		if user.StripeDefaultPM == nil {
-			user.UpdateStripeData(db, pm.Customer.ID, pm.ID)
+	user, err := models.FindUserByStripeCustomerID(db, customerID)
+	if err == nil && user == nil {
+		fmt.Printf("User with Stripe customer ID %s not found\n", customerID)
+	}
+	return user, err
+}
+
+// checkoutTokenKey is the checkout session metadata key holding the signed checkout token
+const checkoutTokenKey = "checkout_token"
+
+// checkoutTokenTTL is how long a checkout token is accepted; it covers
+// Stripe's 24 hour session lifetime and its webhook retries
+const checkoutTokenTTL = 72 * time.Hour
+
+// checkoutTokenSecret returns the key checkout tokens are signed with
+func checkoutTokenSecret() []byte {
+	return []byte(utils.GetEnvWithDefault("CHECKOUT_TOKEN_SECRET", utils.GetEnvWithDefault("JWT_SECRET", "your_jwt_secret")))
+}
+
+// createCheckoutSession creates a checkout session whose metadata carries a
+// signed, expiring, single-use token identifying the user, bound to the session
+func createCheckoutSession(ctx context.Context, db *gorm.DB, user *models.User, in billing.CheckoutSessionInput) (*billing.CheckoutSession, error) {
+	token, err := models.CreateCheckoutToken(db, user.ID, checkoutTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	if in.Metadata == nil {
+		in.Metadata = map[string]string{}
+	}
+	in.Metadata[checkoutTokenKey] = token.Signed(checkoutTokenSecret())
+
+	sess, err := billing.Client().CreateCheckoutSession(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	if err := token.AttachSession(db, sess.ID); err != nil {
+		return nil, fmt.Errorf("failed to save checkout token: %w", err)
+	}
+	return sess, nil
+}
+
+// ensureStripeCustomer returns the user's Stripe customer ID, creating the customer on first use
+func ensureStripeCustomer(ctx context.Context, db *gorm.DB, user *models.User) (string, error) {
+	if user.StripeCustomerID != nil {
+		return *user.StripeCustomerID, nil
+	}
+
+	// Create new customer in Stripe
+	newCustomer, err := billing.Client().CreateCustomer(ctx, customerInputForUser(user))
+	if err != nil {
+		return "", fmt.Errorf("Error creating Stripe customer: %v", err)
+	}
+
+	// Update user with Stripe customer ID
+	if err := user.UpdateStripeData(db, newCustomer.ID, ""); err != nil {
+		return "", fmt.Errorf("Error updating user data: %v", err)
+	}
+
+	return newCustomer.ID, nil
+}
+
+// customerInputForUser converts user data to billing customer details
+func customerInputForUser(u *models.User) billing.CustomerInput {
+	in := billing.CustomerInput{
+		Name:  u.Name,
+		Email: u.Email,
+	}
+
+	if u.Mobile != "" && u.CountryCode != "" {
+		in.Phone = u.CountryCode + u.Mobile
+	}
+
+	if u.Address != "" && u.City != "" && u.Country != "" {
+		in.Address = &billing.Address{
+			Line1:      u.Address,
+			City:       u.City,
+			Country:    u.Country,
+			PostalCode: u.PostalCode,
 		}
 	}
 
-	c.JSON(http.StatusOK, WebhookResponse{Received: true})
+	return in
+}
+
+// dunningStatus keeps a subscription downgraded after its payment grace
+// period lapsed while Stripe still reports it past due, so the lapse is not
+// undone until a payment succeeds
+func dunningStatus(user *models.User, status string) string {
+	if status == models.SubscriptionStatusPastDue && user.PaymentGraceEndsAt != nil && time.Now().After(*user.PaymentGraceEndsAt) {
+		return models.SubscriptionStatusUnpaid
+	}
+	return status
+}
+
+// applyPromoCode applies the requested promotion code to a checkout session
+// once Stripe confirms it is active, or lets the customer enter a code on the
+// checkout page when none was requested. It writes the error response and
+// returns false when the code cannot be used.
+func applyPromoCode(c *gin.Context, code string, in *billing.CheckoutSessionInput) bool {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		in.AllowPromotionCodes = true
+		return true
+	}
+
+	promo, err := billing.Client().FindPromotionCode(c.Request.Context(), code)
+	if errors.Is(err, billing.ErrPromotionCodeNotFound) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid or expired promotion code"})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Error checking promotion code: %v", err)})
+		return false
+	}
+
+	in.PromotionCodeID = promo.ID
+	if in.Metadata == nil {
+		in.Metadata = map[string]string{}
+	}
+	in.Metadata["promo_code"] = promo.Code
+	return true
+}
+
+// subscriptionDiscount converts the discount Stripe reports on a subscription
+// to its local form, the zero value when there is none
+func subscriptionDiscount(s *billing.Subscription) models.SubscriptionDiscount {
+	if s.Discount == nil {
+		return models.SubscriptionDiscount{}
+	}
+	coupon := s.Discount.Coupon
+	discount := models.SubscriptionDiscount{
+		CouponID:        coupon.ID,
+		CouponName:      coupon.Name,
+		PromotionCodeID: s.Discount.PromotionCodeID,
+		PercentOff:      coupon.PercentOff,
+		AmountOff:       coupon.AmountOff,
+		Currency:        coupon.Currency,
+		Duration:        coupon.Duration,
+	}
+	if !s.Discount.End.IsZero() {
+		end := s.Discount.End
+		discount.EndsAt = &end
+	}
+	return discount
+}
+
+// saveSubscriptionDiscount records the subscription's discount on the user.
+// A failure is only logged: the next subscription webhook carries the
+// discount again.
+func saveSubscriptionDiscount(db *gorm.DB, user *models.User, discount models.SubscriptionDiscount) {
+	if err := user.UpdateSubscriptionDiscount(db, discount); err != nil {
+		log.Printf("Error saving subscription discount for user %d: %v", user.ID, err)
+	}
 }
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/billing"
+	"github.com/gin-gonic/gin"
+)
+
+// countryCurrencies maps ISO 3166 country codes to the currency customers
+// there are charged in when they do not choose one
+var countryCurrencies = map[string]string{
+	// Euro area
+	"AT": "eur", "BE": "eur", "BG": "eur", "CY": "eur", "DE": "eur", "EE": "eur",
+	"ES": "eur", "FI": "eur", "FR": "eur", "GR": "eur", "HR": "eur", "IE": "eur",
+	"IT": "eur", "LT": "eur", "LU": "eur", "LV": "eur", "MT": "eur", "NL": "eur",
+	"PT": "eur", "SI": "eur", "SK": "eur",
+	// Other European countries
+	"GB": "gbp", "CH": "chf", "LI": "chf", "SE": "sek", "DK": "dkk", "NO": "nok",
+	"PL": "pln", "CZ": "czk", "HU": "huf", "RO": "ron",
+	// Elsewhere
+	"US": "usd", "CA": "cad", "AU": "aud", "NZ": "nzd", "JP": "jpy", "SG": "sgd",
+	"IN": "inr", "BR": "brl", "MX": "mxn",
+}
+
+// normalizeCurrency lower-cases an ISO 4217 currency code and reports whether
+// it is well-formed
+func normalizeCurrency(currency string) (string, bool) {
+	currency = strings.ToLower(strings.TrimSpace(currency))
+	if len(currency) != 3 {
+		return "", false
+	}
+	for _, r := range currency {
+		if r < 'a' || r > 'z' {
+			return "", false
+		}
+	}
+	return currency, true
+}
+
+// userCurrency infers the currency to charge the user in from their country,
+// or returns "" when the country is unknown
+func userCurrency(user *models.User) string {
+	return countryCurrencies[strings.ToUpper(strings.TrimSpace(user.Country))]
+}
+
+// priceCurrencies lists the currencies a price can be charged in, the
+// default currency first
+func priceCurrencies(p billing.Price) []string {
+	others := make([]string, 0, len(p.CurrencyOptions))
+	for currency := range p.CurrencyOptions {
+		others = append(others, currency)
+	}
+	sort.Strings(others)
+	return append([]string{p.Currency}, others...)
+}
+
+// selectPlanPrice finds the price to charge for a plan in a currency: the
+// plan's own price when it offers the currency, otherwise another price of
+// the same product and billing interval in that currency. found reports
+// whether the plan is in the catalog at all.
+func selectPlanPrice(catalog []billing.Price, planID, currency string) (price billing.Price, found, ok bool) {
+	var plan billing.Price
+	for _, p := range catalog {
+		if p.ID == planID {
+			plan, found = p, true
+			break
+		}
+	}
+	if !found {
+		return billing.Price{}, false, false
+	}
+	if _, ok := plan.AmountIn(currency); ok {
+		return plan, true, true
+	}
+
+	for _, p := range catalog {
+		if p.ProductID != plan.ProductID || p.Interval != plan.Interval || p.IntervalCount != plan.IntervalCount {
+			continue
+		}
+		if _, ok := p.AmountIn(currency); ok {
+			return p, true, true
+		}
+	}
+	return plan, true, false
+}
+
+// checkoutPlanPrice resolves the price and currency to charge for a plan. A
+// requested currency must be offered by the plan; a currency inferred from
+// the user's country is only used when it is, and otherwise the plan is
+// charged as requested. It writes the error response and returns false on
+// failure.
+func checkoutPlanPrice(c *gin.Context, user *models.User, planID, requested string) (string, string, bool) {
+	currency := userCurrency(user)
+	if requested != "" {
+		var ok bool
+		if currency, ok = normalizeCurrency(requested); !ok {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "currency must be a three-letter ISO 4217 code"})
+			return "", "", false
+		}
+	}
+	if currency == "" {
+		return planID, "", true
+	}
+
+	catalog, err := loadPlanCatalog(c)
+	if err != nil {
+		if requested == "" {
+			return planID, "", true
+		}
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "Plan catalog is temporarily unavailable"})
+		return "", "", false
+	}
+
+	price, found, ok := selectPlanPrice(catalog, planID, currency)
+	switch {
+	case ok:
+		return price.ID, currency, true
+	case requested == "":
+		return planID, "", true
+	case !found:
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Unknown plan_id"})
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("Plan is not available in %s; see GET /payment/plans for its currencies", strings.ToUpper(currency))})
+	}
+	return "", "", false
+}
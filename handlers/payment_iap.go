@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/iap"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/jobs"
+	"github.com/gin-gonic/gin"
+)
+
+// AppleReceiptRequest represents the request body for validating an App Store receipt
+type AppleReceiptRequest struct {
+	// Base64 app receipt from the device
+	ReceiptData string `json:"receipt_data" binding:"required" example:"MIIT0gYJKoZIhvcNAQcCoIITwzCCE78CAQEx..."`
+	// Optional product ID of the subscription; defaults to the one expiring last in the receipt
+	ProductID string `json:"product_id" example:"io.thinkink.pro.monthly"`
+}
+
+// GooglePurchaseRequest represents the request body for validating a Google Play purchase
+type GooglePurchaseRequest struct {
+	ProductID     string `json:"product_id" binding:"required" example:"io.thinkink.pro.monthly"`
+	PurchaseToken string `json:"purchase_token" binding:"required" example:"opaque-token-up-to-150-characters"`
+}
+
+// ValidateAppleReceiptHandler validates an App Store subscription receipt
+// @Summary Validate an App Store subscription
+// @Description Validates an App Store receipt with Apple and grants the subscription it holds, as a Stripe subscription would. The store's product ID serves as the plan, so PLAN_QUOTAS can be keyed by it. Validating the same subscription again, e.g. after a renewal or restore, refreshes it. A subscription already validated for another account is rejected, and one validated while the user holds another active subscription is recorded without replacing it.
+// @Tags payment
+// @Accept json
+// @Produce json
+// @Param request body AppleReceiptRequest true "App Store receipt"
+// @Success 200 {object} SubscriptionResponse "Subscription granted"
+// @Failure 400 {object} ErrorResponse "Invalid receipt, or a sandbox purchase in production"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 409 {object} ErrorResponse "Purchase belongs to another account, or the user already holds another subscription"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Failure 502 {object} ErrorResponse "App Store could not validate the receipt"
+// @Failure 503 {object} ErrorResponse "App Store purchases are not configured"
+// @Security BearerAuth
+// @Router /payment/iap/apple [post]
+func ValidateAppleReceiptHandler(c *gin.Context) {
+	var req AppleReceiptRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	validateStorePurchase(c, iap.AppStore, req.ProductID, req.ReceiptData)
+}
+
+// ValidateGooglePurchaseHandler validates a Google Play subscription purchase
+// @Summary Validate a Google Play subscription
+// @Description Validates a Google Play subscription purchase token with Google, acknowledging new purchases, and grants the subscription as a Stripe subscription would. The store's product ID serves as the plan, so PLAN_QUOTAS can be keyed by it. Validating the same purchase again refreshes it. A purchase already validated for another account is rejected, and one validated while the user holds another active subscription is recorded without replacing it.
+// @Tags payment
+// @Accept json
+// @Produce json
+// @Param request body GooglePurchaseRequest true "Google Play purchase"
+// @Success 200 {object} SubscriptionResponse "Subscription granted"
+// @Failure 400 {object} ErrorResponse "Invalid purchase token, or a test purchase in production"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 409 {object} ErrorResponse "Purchase belongs to another account, or the user already holds another subscription"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Failure 502 {object} ErrorResponse "Google Play could not validate the purchase"
+// @Failure 503 {object} ErrorResponse "Google Play purchases are not configured"
+// @Security BearerAuth
+// @Router /payment/iap/google [post]
+func ValidateGooglePurchaseHandler(c *gin.Context) {
+	var req GooglePurchaseRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	validateStorePurchase(c, iap.GooglePlay, req.ProductID, req.PurchaseToken)
+}
+
+// validateStorePurchase verifies a purchase with its store, records it for
+// the authenticated user and grants it unless they hold another subscription
+func validateStorePurchase(c *gin.Context, store iap.Store, productID, receipt string) {
+	verifier := iap.VerifierFor(store)
+	if verifier == nil {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: storeName(store) + " purchases are not configured"})
+		return
+	}
+
+	db := database.DB
+	user, err := models.FindUserByID(db, c.GetUint("userID"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		return
+	}
+
+	sub, err := verifier.Verify(c.Request.Context(), productID, receipt)
+	if errors.Is(err, iap.ErrInvalidReceipt) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid or unknown " + storeName(store) + " purchase"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, ErrorResponse{Error: fmt.Sprintf("Error validating purchase: %v", err)})
+		return
+	}
+	if sub.Sandbox && !iap.SandboxAllowed() {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Sandbox " + storeName(store) + " purchases are not accepted"})
+		return
+	}
+
+	purchase, err := models.FindStorePurchase(db, string(store), sub.TransactionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if purchase != nil && purchase.UserID != user.ID {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "This purchase belongs to another account"})
+		return
+	}
+	if purchase == nil {
+		purchase = &models.StorePurchase{UserID: user.ID, Store: string(store), TransactionID: sub.TransactionID}
+	}
+	purchase.Receipt = receipt
+
+	// Another live subscription, from Stripe or the other store, is not replaced
+	holder := user.HoldsStorePurchase(purchase)
+	if !holder && user.IsSubscribed() {
+		if err := jobs.SaveStoreSubscription(db, user, purchase, sub); err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "User already has an active subscription"})
+		return
+	}
+
+	if !holder {
+		// Make the user the holder so the purchase is applied
+		user.SubscriptionStore = purchase.Store
+		user.SubscriptionID = &purchase.TransactionID
+	}
+	if err := jobs.SaveStoreSubscription(db, user, purchase, sub); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if user.IsSubscribed() {
+		completeOnboardingStep(db, user, models.OnboardingSubscription)
+	}
+
+	c.JSON(http.StatusOK, SubscriptionResponse{
+		HasSubscription:   user.IsSubscribed(),
+		SubscriptionID:    purchase.TransactionID,
+		PlanID:            purchase.ProductID,
+		Status:            purchase.Status,
+		CancelAtPeriodEnd: !purchase.AutoRenew,
+		CurrentPeriodEnd:  purchase.ExpiresAt,
+		Store:             purchase.Store,
+	})
+}
+
+// storeName returns the display name of an app store
+func storeName(store iap.Store) string {
+	if store == iap.GooglePlay {
+		return "Google Play"
+	}
+	return "App Store"
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/billing"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// unsafeFileNameChars matches characters not kept in download file names
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// GetInvoicePDFHandler streams the PDF of an invoice
+// @Summary Download an invoice PDF
+// @Description Streams the PDF of one of the user's Stripe invoices, or of an invoice of an organization the user administers, without exposing Stripe URLs. Invoices of other customers are reported as not found.
+// @Tags payment
+// @Produce application/pdf
+// @Param id path string true "Stripe invoice ID"
+// @Success 200 {file} file "Invoice PDF"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Invoice not found, or it has no PDF yet"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Failure 502 {object} ErrorResponse "PDF could not be downloaded from Stripe"
+// @Security BearerAuth
+// @Router /payment/invoices/{id}/pdf [get]
+func GetInvoicePDFHandler(c *gin.Context) {
+	db := database.DB
+	user, err := models.FindUserByID(db, c.GetUint("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "User not found"})
+		return
+	}
+
+	inv, err := billing.Client().GetInvoice(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, billing.ErrInvoiceNotFound) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Invoice not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Error retrieving invoice: %v", err)})
+		return
+	}
+
+	owned, err := ownsBillingCustomer(db, user, inv.CustomerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if !owned {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Invoice not found"})
+		return
+	}
+
+	body, length, err := billing.OpenInvoicePDF(c.Request.Context(), inv)
+	if errors.Is(err, billing.ErrNoInvoicePDF) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Invoice has no PDF yet"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, ErrorResponse{Error: fmt.Sprintf("Error downloading invoice PDF: %v", err)})
+		return
+	}
+	defer body.Close()
+
+	name := inv.Number
+	if name == "" {
+		name = inv.ID
+	}
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="invoice-%s.pdf"`, unsafeFileNameChars.ReplaceAllString(name, "_")))
+	c.Header("Cache-Control", "private, no-store")
+	if length >= 0 {
+		c.Header("Content-Length", fmt.Sprint(length))
+	}
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, body); err != nil {
+		// Headers are already sent; the client sees a truncated download
+		log.Printf("Failed to stream invoice %s to user %d: %v", inv.ID, user.ID, err)
+	}
+}
+
+// ownsBillingCustomer reports whether a billing customer is the user's own or
+// that of an organization the user administers
+func ownsBillingCustomer(db *gorm.DB, user *models.User, customerID string) (bool, error) {
+	if customerID == "" {
+		return false, nil
+	}
+	if user.StripeCustomerID != nil && *user.StripeCustomerID == customerID {
+		return true, nil
+	}
+
+	org, err := models.FindOrgSubscriptionByCustomer(db, customerID)
+	if err != nil || org == nil {
+		return false, err
+	}
+	return user.IsAdmin() || user.IsOrgAdmin(org.OrganizationID), nil
+}
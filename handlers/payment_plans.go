@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/billing"
+	"github.com/gin-gonic/gin"
+)
+
+// planCatalogTTL bounds how long the plan catalog is served from memory;
+// product and price webhooks refresh it sooner
+const planCatalogTTL = 10 * time.Minute
+
+// PlanResponse is one purchasable price of the catalog
+type PlanResponse struct {
+	// Stripe price ID, passed as plan_id to POST /payment/checkout/subscription
+	ID          string   `json:"id" example:"price_1Oxy3JExamplePriceID"`
+	ProductID   string   `json:"product_id" example:"prod_PmExampleID"`
+	Name        string   `json:"name" example:"ThinkInk Pro"`
+	Description string   `json:"description,omitempty" example:"Unlimited translations"`
+	Features    []string `json:"features"`
+	Currency    string   `json:"currency" example:"usd"`
+	UnitAmount  int64    `json:"unit_amount" example:"1500"` // Amount in cents
+	// Every currency the plan can be charged in, the price's default first
+	Currencies    []string `json:"currencies" example:"usd,eur"`
+	Interval      string   `json:"interval,omitempty" example:"month"`
+	IntervalCount int64    `json:"interval_count,omitempty" example:"1"`
+	// Quotas granted while subscribed; only set for recurring prices
+	Quota *config.PlanQuota `json:"quota,omitempty"`
+	// Capabilities granted while subscribed, from PLAN_CAPABILITIES; only set for recurring prices
+	Capabilities []string `json:"capabilities,omitempty" example:"pdf_export,sharing,ml_api"`
+}
+
+// PlansResponse lists the plan catalog
+type PlansResponse struct {
+	Plans []PlanResponse `json:"plans"`
+}
+
+var (
+	planCatalog        []billing.Price
+	planCatalogExpires time.Time
+	planCatalogMu      sync.Mutex
+)
+
+// GetPlansHandler lists the active Stripe prices so clients need not hard-code price IDs
+// @Summary List plans
+// @Description Lists the active products and prices that can be purchased, with their features, currencies, billing interval, plan quotas and capabilities. With a currency, only plans available in it are listed, priced in it.
+// @Tags payment
+// @Produce json
+// @Param currency query string false "ISO 4217 currency code, e.g. eur"
+// @Success 200 {object} PlansResponse "Plan catalog"
+// @Failure 400 {object} ErrorResponse "Invalid currency"
+// @Failure 503 {object} ErrorResponse "Payment provider unavailable"
+// @Router /payment/plans [get]
+func GetPlansHandler(c *gin.Context) {
+	var currency string
+	if param := c.Query("currency"); param != "" {
+		var ok bool
+		if currency, ok = normalizeCurrency(param); !ok {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "currency must be a three-letter ISO 4217 code"})
+			return
+		}
+	}
+
+	prices, err := loadPlanCatalog(c)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "Plan catalog is temporarily unavailable"})
+		return
+	}
+
+	settings := config.Current()
+	plans := make([]PlanResponse, 0, len(prices))
+	for _, p := range prices {
+		plan := PlanResponse{
+			ID:            p.ID,
+			ProductID:     p.ProductID,
+			Name:          p.ProductName,
+			Description:   p.Description,
+			Features:      p.Features,
+			Currency:      p.Currency,
+			UnitAmount:    p.UnitAmount,
+			Currencies:    priceCurrencies(p),
+			Interval:      p.Interval,
+			IntervalCount: p.IntervalCount,
+		}
+		if currency != "" {
+			amount, ok := p.AmountIn(currency)
+			if !ok {
+				continue
+			}
+			plan.Currency, plan.UnitAmount = currency, amount
+		}
+		if plan.Features == nil {
+			plan.Features = []string{}
+		}
+		if p.Interval != "" {
+			quota := settings.QuotaFor(p.ID, true)
+			plan.Quota = &quota
+			plan.Capabilities = settings.CapabilitiesFor(p.ID, true)
+		}
+		plans = append(plans, plan)
+	}
+
+	c.JSON(http.StatusOK, PlansResponse{Plans: plans})
+}
+
+// loadPlanCatalog returns the cached catalog, fetching it from the payment
+// provider once it expired. A stale catalog is served when the fetch fails.
+func loadPlanCatalog(c *gin.Context) ([]billing.Price, error) {
+	planCatalogMu.Lock()
+	defer planCatalogMu.Unlock()
+
+	if planCatalog != nil && time.Now().Before(planCatalogExpires) {
+		return planCatalog, nil
+	}
+
+	prices, err := billing.Client().ListPrices(c.Request.Context())
+	if err != nil {
+		if planCatalog != nil {
+			log.Printf("Serving stale plan catalog: %v", err)
+			return planCatalog, nil
+		}
+		log.Printf("Error listing prices: %v", err)
+		return nil, err
+	}
+	if prices == nil {
+		prices = []billing.Price{}
+	}
+
+	planCatalog = prices
+	planCatalogExpires = time.Now().Add(planCatalogTTL)
+	return planCatalog, nil
+}
+
+// invalidatePlanCatalog refetches the catalog on the next request after
+// products or prices changed in Stripe
+func invalidatePlanCatalog(eventType string) {
+	if !strings.HasPrefix(eventType, "product.") && !strings.HasPrefix(eventType, "price.") {
+		return
+	}
+	planCatalogMu.Lock()
+	planCatalogExpires = time.Time{}
+	planCatalogMu.Unlock()
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/billing"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateTaxIDRequest represents the request body for adding a tax ID
+type CreateTaxIDRequest struct {
+	// Stripe tax ID type, e.g. eu_vat, gb_vat or ch_vat
+	Type  string `json:"type" binding:"required" example:"eu_vat"`
+	Value string `json:"value" binding:"required" example:"DE123456789"`
+}
+
+// TaxIDsResponse lists the user's tax IDs
+type TaxIDsResponse struct {
+	TaxIDs []models.CustomerTaxID `json:"tax_ids"`
+}
+
+// automaticTax reports whether checkout sessions have Stripe Tax calculate tax
+func automaticTax() bool {
+	return utils.GetEnvWithDefault("STRIPE_AUTOMATIC_TAX", "false") == "true"
+}
+
+// customerTaxID converts a Stripe tax ID to its local copy
+func customerTaxID(userID uint, t *billing.TaxID) *models.CustomerTaxID {
+	return &models.CustomerTaxID{
+		UserID:             userID,
+		StripeTaxID:        t.ID,
+		Type:               t.Type,
+		Value:              t.Value,
+		Country:            t.Country,
+		VerificationStatus: t.VerificationStatus,
+	}
+}
+
+// GetTaxIDsHandler lists the user's tax IDs
+// @Summary List tax IDs
+// @Description Lists the tax IDs, such as EU VAT numbers, shown on the user's invoices, with their verification status
+// @Tags payment
+// @Produce json
+// @Success 200 {object} TaxIDsResponse "Tax IDs"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /payment/tax-ids [get]
+func GetTaxIDsHandler(c *gin.Context) {
+	taxIDs, err := models.FindUserTaxIDs(database.DB, c.GetUint("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch tax IDs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, TaxIDsResponse{TaxIDs: taxIDs})
+}
+
+// CreateTaxIDHandler adds a tax ID to the user's billing details
+// @Summary Add a tax ID
+// @Description Adds a tax ID, such as an EU VAT number, to the user's Stripe customer so it appears on invoices and, with Stripe Tax, enables the reverse charge. Stripe checks the format at once and verifies EU VAT numbers in the background.
+// @Tags payment
+// @Accept json
+// @Produce json
+// @Param request body CreateTaxIDRequest true "Tax ID"
+// @Success 201 {object} models.CustomerTaxID "Tax ID added"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid tax ID type or value"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /payment/tax-ids [post]
+func CreateTaxIDHandler(c *gin.Context) {
+	var req CreateTaxIDRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	taxType := strings.ToLower(strings.TrimSpace(req.Type))
+	value := strings.TrimSpace(req.Value)
+
+	db := database.DB
+	user, err := models.FindUserByID(db, c.GetUint("userID"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		return
+	}
+
+	customerID, err := ensureStripeCustomer(c.Request.Context(), db, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	created, err := billing.Client().CreateTaxID(c.Request.Context(), customerID, taxType, value)
+	if errors.Is(err, billing.ErrInvalidTaxID) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Error adding tax ID: %v", err)})
+		return
+	}
+
+	taxID := customerTaxID(user.ID, created)
+	if err := models.SaveCustomerTaxID(db, taxID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save tax ID"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, taxID)
+}
+
+// DeleteTaxIDHandler removes a tax ID from the user's billing details
+// @Summary Remove a tax ID
+// @Description Removes a tax ID from the user's Stripe customer; invoices already issued keep it
+// @Tags payment
+// @Produce json
+// @Param id path string true "Tax ID"
+// @Success 200 {object} SuccessResponse "Tax ID removed"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Tax ID not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /payment/tax-ids/{id} [delete]
+func DeleteTaxIDHandler(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid tax ID"})
+		return
+	}
+
+	db := database.DB
+	user, err := models.FindUserByID(db, c.GetUint("userID"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		return
+	}
+	taxID, err := models.FindUserTaxID(db, uint(id), user.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Tax ID not found"})
+		return
+	}
+
+	if user.StripeCustomerID != nil {
+		if err := billing.Client().DeleteTaxID(c.Request.Context(), *user.StripeCustomerID, taxID.StripeTaxID); err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("Error removing tax ID: %v", err)})
+			return
+		}
+	}
+	if err := models.DeleteCustomerTaxID(db, taxID.StripeTaxID); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to remove tax ID"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Tax ID removed"})
+}
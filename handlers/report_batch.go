@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Batch report actions
+const (
+	BatchDelete        = "delete"
+	BatchTag           = "tag"
+	BatchMatchingScale = "matching_scale"
+)
+
+// maxBatchReports caps the number of reports in one batch request
+const maxBatchReports = 100
+
+// BatchReportsRequest represents one action applied to several reports
+type BatchReportsRequest struct {
+	// One of delete, tag or matching_scale
+	Action string `json:"action" binding:"required,oneof=delete tag matching_scale" example:"tag"`
+	// Reports to act on (at most 100)
+	IDs []uint `json:"ids" binding:"required,min=1,max=100" example:"1,2,3"`
+	// Tag to attach, for the tag action
+	Tag string `json:"tag" example:"morning"`
+	// New matching scale (0-100), for the matching_scale action
+	MatchingScale *int `json:"matching_scale" example:"85"`
+}
+
+// BatchItemResult is the outcome of a batch action on one report
+type BatchItemResult struct {
+	ID    uint   `json:"id" example:"1"`
+	OK    bool   `json:"ok" example:"true"`
+	Error string `json:"error,omitempty" example:"Report not found"`
+}
+
+// BatchReportsResponse represents the outcome of a batch action
+type BatchReportsResponse struct {
+	Action    string            `json:"action" example:"tag"`
+	Succeeded int               `json:"succeeded" example:"2"`
+	Failed    int               `json:"failed" example:"1"`
+	Results   []BatchItemResult `json:"results"`
+}
+
+// errBatchItem marks a per-report failure that leaves the rest of the batch in place
+type errBatchItem struct{ message string }
+
+func (e errBatchItem) Error() string { return e.message }
+
+// BatchReports applies one action to several reports
+// @Summary Act on several reports
+// @Description Deletes, tags or sets the matching scale of up to 100 reports in one transaction and returns a result per report, in request order. Reports that cannot be acted on (not found, no access, too many tags) are reported as failed and the rest are applied; a database error rolls back the whole batch. Every action requires owning the report.
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param batch body BatchReportsRequest true "Batch action"
+// @Success 200 {object} BatchReportsResponse "Per-report results"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal Server Error - Nothing was changed"
+// @Security BearerAuth
+// @Router /reports/batch [post]
+func BatchReports(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	var req BatchReportsRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if len(req.IDs) > maxBatchReports {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "At most " + strconv.Itoa(maxBatchReports) + " reports per batch"})
+		return
+	}
+
+	var apply func(tx *gorm.DB, report *models.Report) error
+	switch req.Action {
+	case BatchDelete:
+		apply = func(tx *gorm.DB, report *models.Report) error {
+			if report.UserID != userID.(uint) {
+				return errBatchItem{"Only the owner can delete a report"}
+			}
+			return report.Delete(tx)
+		}
+	case BatchTag:
+		name, err := models.NormalizeTagName(req.Tag)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		apply = func(tx *gorm.DB, report *models.Report) error {
+			if report.UserID != userID.(uint) {
+				return errBatchItem{"Only the owner can tag a report"}
+			}
+			err := report.AddTag(tx, name, userID.(uint))
+			if errors.Is(err, models.ErrTooManyTags) {
+				return errBatchItem{err.Error()}
+			}
+			return err
+		}
+	case BatchMatchingScale:
+		if req.MatchingScale == nil || *req.MatchingScale < 0 || *req.MatchingScale > 100 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Matching scale must be between 0 and 100"})
+			return
+		}
+		apply = func(tx *gorm.DB, report *models.Report) error {
+			if report.UserID != userID.(uint) {
+				return errBatchItem{"Only the owner can update the matching scale"}
+			}
+			return report.UpdateMatchingScale(tx, *req.MatchingScale)
+		}
+	}
+
+	response := BatchReportsResponse{Action: req.Action, Results: make([]BatchItemResult, len(req.IDs))}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		done := map[uint]bool{}
+		for i, id := range req.IDs {
+			response.Results[i] = BatchItemResult{ID: id}
+			// A report listed twice is acted on once
+			if done[id] {
+				response.Results[i].OK = true
+				continue
+			}
+
+			report, err := findBatchReport(tx, id, userID.(uint))
+			if err == nil {
+				err = apply(tx, report)
+			}
+			var itemErr errBatchItem
+			if errors.As(err, &itemErr) {
+				response.Results[i].Error = itemErr.message
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			done[id] = true
+			response.Results[i].OK = true
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to apply batch; nothing was changed"})
+		return
+	}
+
+	for _, result := range response.Results {
+		if result.OK {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// findBatchReport finds a report the user may see. Missing reports and
+// reports without access both fail as not found so IDs cannot be probed.
+func findBatchReport(tx *gorm.DB, id, userID uint) (*models.Report, error) {
+	report, err := models.FindReportByID(tx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errBatchItem{"Report not found"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := models.CanViewReports(tx, userID, report.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errBatchItem{"Report not found"}
+	}
+	return report, nil
+}
@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/reportexport"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ReportExportDir holds the files of background report exports
+	ReportExportDir = "./exports"
+	// defaultExportSyncRows is the largest export streamed directly; larger
+	// exports are generated in the background
+	defaultExportSyncRows = 1000
+	// exportLinkTTL is how long a signed download link works, at most until
+	// the export itself expires
+	exportLinkTTL = 15 * time.Minute
+)
+
+// ReportExportResponse describes a background report export
+type ReportExportResponse struct {
+	Export models.ReportExport `json:"export"`
+	// Poll until export.status is completed, then fetch DownloadURL
+	StatusURL string `json:"status_url" example:"/reports/exports/12"`
+	// Signed link that downloads the file without authentication for 15 minutes
+	DownloadURL string `json:"download_url,omitempty" example:"/exports/12/download?expires=1767225600&signature=c2lnbmF0dXJl"`
+}
+
+// ReportExportsResponse represents a page of the user's exports
+type ReportExportsResponse struct {
+	Exports    []ReportExportResponse `json:"exports"`
+	Pagination Pagination             `json:"pagination"`
+}
+
+// exportURLSecret returns the key export download links are signed with
+func exportURLSecret() []byte {
+	return []byte(utils.GetEnvWithDefault("EXPORT_URL_SECRET", utils.GetEnvWithDefault("JWT_SECRET", "your_jwt_secret")))
+}
+
+func newReportExportResponse(export *models.ReportExport) ReportExportResponse {
+	response := ReportExportResponse{
+		Export:    *export,
+		StatusURL: fmt.Sprintf("/reports/exports/%d", export.ID),
+	}
+	now := time.Now()
+	if export.Status == models.ExportCompleted && !export.Expired(now) {
+		expires := now.Add(exportLinkTTL)
+		if export.ExpiresAt != nil && export.ExpiresAt.Before(expires) {
+			expires = *export.ExpiresAt
+		}
+		response.DownloadURL = fmt.Sprintf("/exports/%d/download?expires=%d&signature=%s", export.ID, expires.Unix(), export.SignDownload(exportURLSecret(), expires))
+	}
+	return response
+}
+
+// ExportReports exports the user's reports
+// @Summary Export reports
+// @Description Exports the reports of the authenticated user, or of a user who granted them access via an account link, oldest first: as CSV or XLSX with the selected columns, as one PDF with a page per report, as a ZIP with each report as JSON and PDF, or as a FHIR R4 bundle. The same filters as GET /reports apply. Small exports are streamed directly; exports of more than REPORT_EXPORT_SYNC_ROWS reports, or any export with async=true, are generated in the background and answered with 202 and a status URL to poll.
+// @Tags reports
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/pdf
+// @Produce application/zip
+// @Produce application/fhir+json
+// @Produce json
+// @Param format query string false "Export format" default(csv) Enums(csv, xlsx, pdf, zip, fhir)
+// @Param columns query string false "Comma-separated columns of CSV and XLSX exports (default all): id, title, description, correction, matching_scale, tags, metadata, translation_status, size_bytes, created_at, updated_at, archived_at"
+// @Param async query bool false "Generate the export in the background regardless of its size"
+// @Param user_id query int false "Owner of the reports (defaults to the authenticated user)"
+// @Param from query string false "Only reports created on or after this date (YYYY-MM-DD or RFC 3339)"
+// @Param to query string false "Only reports created before this timestamp, or on or before this date (YYYY-MM-DD or RFC 3339)"
+// @Param min_scale query int false "Minimum matching scale"
+// @Param max_scale query int false "Maximum matching scale"
+// @Param q query string false "Case-insensitive text to find in the title or description"
+// @Param tag query []string false "Only reports carrying all of these tags; repeat for several" collectionFormat(multi)
+// @Param archived query string false "false (default) hides archived reports, true exports only archived reports, all exports both"
+// @Param flagged query bool false "true exports only reports flagged by moderation"
+// @Success 200 {file} file "Exported reports"
+// @Success 202 {object} ReportExportResponse "Export queued for background generation"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid format, columns, user ID or filter"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - No access to this user's reports"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /reports/export [get]
+func ExportReports(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", reportexport.FormatCSV))
+	if !reportexport.ValidFormat(format) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "format must be one of " + strings.Join(reportexport.Formats(), ", ")})
+		return
+	}
+	var columns []string
+	if reportexport.Tabular(format) {
+		var err error
+		if columns, err = reportexport.ParseColumns(c.Query("columns")); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+	async := c.Query("async") == "true"
+
+	ownerID, ok := resolveReportOwner(c, userID.(uint))
+	if !ok {
+		return
+	}
+	filter, ok := parseReportFilter(c)
+	if !ok {
+		return
+	}
+
+	count, err := models.CountFilteredReports(database.DB, ownerID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to count reports"})
+		return
+	}
+
+	syncRows, err := strconv.ParseInt(utils.GetEnvWithDefault("REPORT_EXPORT_SYNC_ROWS", strconv.Itoa(defaultExportSyncRows)), 10, 64)
+	if err != nil || syncRows < 0 {
+		syncRows = defaultExportSyncRows
+	}
+	auditMemberAction(c, ownerID, "report.export", fmt.Sprintf("user:%d", ownerID),
+		gin.H{"format": format, "reports": count, "async": async || count > syncRows})
+
+	if async || count > syncRows {
+		export := &models.ReportExport{
+			RequestedBy: userID.(uint),
+			OwnerID:     ownerID,
+			Format:      format,
+			Columns:     strings.Join(columns, ","),
+			TotalRows:   int(count),
+		}
+		if err := models.CreateReportExport(database.DB, export, filter); err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to queue export"})
+			return
+		}
+		c.JSON(http.StatusAccepted, newReportExportResponse(export))
+		return
+	}
+
+	viewerID := userID.(uint)
+	access := models.ReportAccess{ViewerID: &viewerID, Action: models.AccessExport, IPAddress: c.ClientIP()}
+	c.Header("Content-Type", reportexport.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, reportexport.FileName("reports-"+time.Now().UTC().Format("20060102-150405"), format)))
+	c.Status(http.StatusOK)
+	if _, err := reportexport.Write(c.Request.Context(), database.DB, c.Writer, format, ownerID, filter, columns, access, nil); err != nil {
+		// Headers are already sent; the client sees a truncated download
+		log.Printf("Failed to export reports of user %d: %v", ownerID, err)
+	}
+}
+
+// GetReportExports lists the user's background report exports
+// @Summary List report exports
+// @Description Lists the background report exports requested by the authenticated user, newest first, with their status and progress. Completed exports carry a signed download_url; exports are removed once they expire after REPORT_EXPORT_TTL.
+// @Tags reports
+// @Produce json
+// @Param limit query int false "Page size (max 100)" default(50)
+// @Param offset query int false "Number of exports to skip (at most 10000); ignored when cursor is set" default(0)
+// @Param cursor query string false "Cursor from the previous page's pagination.next_cursor"
+// @Success 200 {object} ReportExportsResponse "Exports"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid pagination"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /exports [get]
+func GetReportExports(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	exports, info, err := models.FindReportExportsPage(database.DB, userID.(uint), page)
+	if err != nil {
+		if err.Error() == "invalid cursor" {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid cursor"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch exports"})
+		return
+	}
+
+	response := ReportExportsResponse{Exports: make([]ReportExportResponse, len(exports)), Pagination: newPagination(page, info)}
+	for i := range exports {
+		response.Exports[i] = newReportExportResponse(&exports[i])
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetReportExport returns the status of a background report export
+// @Summary Get a report export
+// @Description Returns the status and progress of a background report export requested by the authenticated user. Once completed, download_url is a signed link to the file that works for 15 minutes; fetch the status again for a fresh link until export.expires_at.
+// @Tags reports
+// @Produce json
+// @Param id path int true "Export ID"
+// @Success 200 {object} ReportExportResponse "Export status"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Export not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /reports/exports/{id} [get]
+func GetReportExport(c *gin.Context) {
+	export, ok := findRequestedExport(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, newReportExportResponse(export))
+}
+
+// DownloadReportExport downloads the file of a completed background export
+// @Summary Download a report export
+// @Description Downloads the file of a completed background report export requested by the authenticated user
+// @Tags reports
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/pdf
+// @Produce application/zip
+// @Produce application/fhir+json
+// @Param id path int true "Export ID"
+// @Success 200 {file} file "Exported reports"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Export not found"
+// @Failure 409 {object} ErrorResponse "Conflict - Export is not completed"
+// @Failure 410 {object} ErrorResponse "Gone - Export has expired"
+// @Security BearerAuth
+// @Router /reports/exports/{id}/download [get]
+func DownloadReportExport(c *gin.Context) {
+	export, ok := findRequestedExport(c)
+	if !ok {
+		return
+	}
+	serveReportExport(c, export)
+}
+
+// DownloadSignedReportExport downloads an export's file through a signed link
+// @Summary Download a report export by signed link
+// @Description Downloads the file of a completed background report export through the signed download_url of its status. No authentication is needed; links work for 15 minutes.
+// @Tags reports
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/pdf
+// @Produce application/zip
+// @Produce application/fhir+json
+// @Param id path int true "Export ID"
+// @Param expires query int true "Link expiry as a Unix timestamp"
+// @Param signature query string true "Link signature"
+// @Success 200 {file} file "Exported reports"
+// @Failure 403 {object} ErrorResponse "Forbidden - Invalid or expired link"
+// @Failure 404 {object} ErrorResponse "Export not found"
+// @Failure 410 {object} ErrorResponse "Gone - Export has expired"
+// @Router /exports/{id}/download [get]
+func DownloadSignedReportExport(c *gin.Context) {
+	exportID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Export not found"})
+		return
+	}
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Invalid or expired download link"})
+		return
+	}
+
+	export, err := models.FindReportExport(database.DB, uint(exportID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Export not found"})
+		return
+	}
+	if !export.VerifyDownload(exportURLSecret(), expires, c.Query("signature"), time.Now()) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Invalid or expired download link"})
+		return
+	}
+
+	serveReportExport(c, export)
+}
+
+// serveReportExport sends the file of a completed, unexpired export
+func serveReportExport(c *gin.Context, export *models.ReportExport) {
+	if export.Status != models.ExportCompleted {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Export is " + export.Status})
+		return
+	}
+	if export.Expired(time.Now()) {
+		c.JSON(http.StatusGone, ErrorResponse{Error: "Export has expired"})
+		return
+	}
+
+	c.Header("Content-Type", reportexport.ContentType(export.Format))
+	c.FileAttachment(export.FilePath, reportexport.FileName(fmt.Sprintf("reports-%d", export.ID), export.Format))
+}
+
+// findRequestedExport loads the export named by the :id path parameter if the
+// authenticated user requested it and still has access to the owner's reports.
+// It writes the error response and returns false on failure.
+func findRequestedExport(c *gin.Context) (*models.ReportExport, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return nil, false
+	}
+
+	exportID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid export ID"})
+		return nil, false
+	}
+
+	export, err := models.FindReportExportForUser(database.DB, uint(exportID), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Export not found"})
+		return nil, false
+	}
+
+	// Exports of a linked user's reports are hidden once the link ends
+	allowed, err := models.CanViewReports(database.DB, userID.(uint), export.OwnerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to check access"})
+		return nil, false
+	}
+	if !allowed {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Export not found"})
+		return nil, false
+	}
+
+	return export, true
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/encryption"
+	"github.com/gin-gonic/gin"
+)
+
+// ReportRevisionsResponse represents a page of a report's revision history
+type ReportRevisionsResponse struct {
+	Revisions  []models.ReportRevision `json:"revisions"`
+	Pagination Pagination              `json:"pagination"`
+}
+
+// GetReportRevisions lists the earlier versions of a report
+// @Summary List a report's revisions
+// @Description Lists the earlier versions of a report owned by the authenticated user or by a user who granted them access via an account link, newest first. A revision is recorded whenever the matching scale is updated, the title, description or metadata are edited, the report is re-translated or an earlier revision is restored; it holds the values that change replaced.
+// @Tags reports
+// @Produce json
+// @Param id path int true "Report ID"
+// @Param limit query int false "Page size (max 100)" default(50)
+// @Param offset query int false "Number of revisions to skip (at most 10000); ignored when cursor is set" default(0)
+// @Param cursor query string false "Cursor from the previous page's pagination.next_cursor"
+// @Success 200 {object} ReportRevisionsResponse "Revisions"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID or paging"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Report not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /reports/{id}/revisions [get]
+func GetReportRevisions(c *gin.Context) {
+	report, ok := findViewableReport(c)
+	if !ok {
+		return
+	}
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	revisions, info, err := models.FindReportRevisionsPage(database.DB, report.ID, page)
+	if err != nil {
+		if err.Error() == "invalid cursor" {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid cursor"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch revisions"})
+		return
+	}
+	for i := range revisions {
+		description, err := encryption.Decrypt(c.Request.Context(), report.UserID, revisions[i].Description)
+		if err != nil {
+			log.Printf("Failed to decrypt report revision %d: %v", revisions[i].ID, err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to decrypt report text"})
+			return
+		}
+		revisions[i].Description = description
+	}
+	if !recordReportAccess(c, report, models.AccessRevisions, nil) {
+		return
+	}
+
+	c.JSON(http.StatusOK, ReportRevisionsResponse{Revisions: revisions, Pagination: newPagination(page, info)})
+}
+
+// RestoreReportRevision restores an earlier version of a report
+// @Summary Restore a report revision
+// @Description Puts the title, description, matching scale and metadata of an earlier revision back on a report owned by the authenticated user. The values it replaces are recorded as a new revision, so a restore can itself be undone.
+// @Tags reports
+// @Produce json
+// @Param id path int true "Report ID"
+// @Param revisionId path int true "Revision ID"
+// @Success 200 {object} ReportResponse "Restored report"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Report or revision not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /reports/{id}/revisions/{revisionId}/restore [post]
+func RestoreReportRevision(c *gin.Context) {
+	report, ok := findOwnedReport(c)
+	if !ok {
+		return
+	}
+
+	revisionID, err := strconv.ParseUint(c.Param("revisionId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid revision ID"})
+		return
+	}
+	revision, err := models.FindReportRevision(database.DB, report.ID, uint(revisionID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Revision not found"})
+		return
+	}
+
+	if err := report.RestoreRevision(database.DB, revision); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to restore revision"})
+		return
+	}
+	if !openReport(c, report) {
+		return
+	}
+
+	c.JSON(http.StatusOK, ReportResponse{Report: *report})
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/encryption"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultShareTTLHours is how long a share link lasts when no expiry is given
+const defaultShareTTLHours = 72
+
+// CreateShareRequest represents the request body for sharing a report
+type CreateShareRequest struct {
+	// Hours until the link expires (default 72, max 720)
+	ExpiresInHours int `json:"expires_in_hours" binding:"omitempty,min=1,max=720" example:"72"`
+	// Optional password the recipient must send in X-Share-Password
+	Password string `json:"password" binding:"omitempty,min=6,max=72" example:"tea-time"`
+}
+
+// ShareResponse represents a share link
+type ShareResponse struct {
+	Share models.ReportShare `json:"share"`
+	// Public link to the read-only view; only set while the share is active
+	URL string `json:"url,omitempty" example:"http://localhost:3000/shared/12.QmFzZTY0.c2lnbmF0dXJl"`
+}
+
+// SharesResponse represents the share links of a report
+type SharesResponse struct {
+	Shares []ShareResponse `json:"shares"`
+}
+
+// SharedReportResponse is the read-only view of a shared report
+type SharedReportResponse struct {
+	Title             string    `json:"title" example:"morning-session.json"`
+	Description       string    `json:"description" example:"I would like a cup of tea"`
+	Correction        string    `json:"correction,omitempty" example:"I would like a cup of coffee"`
+	MatchingScale     int       `json:"matching_scale" example:"7"`
+	Tags              []string  `json:"tags,omitempty" example:"morning"`
+	TranslationStatus string    `json:"translation_status,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// shareSecret returns the key share tokens are signed with
+func shareSecret() []byte {
+	return []byte(utils.GetEnvWithDefault("SHARE_LINK_SECRET", utils.GetEnvWithDefault("JWT_SECRET", "your_jwt_secret")))
+}
+
+func newShareResponse(share *models.ReportShare) ShareResponse {
+	response := ShareResponse{Share: *share}
+	if share.IsActive() {
+		response.URL = utils.GetEnvWithDefault("SHARE_LINK_URL", "http://localhost:3000/shared/") + share.Token(shareSecret())
+	}
+	return response
+}
+
+// CreateReportShare creates a public share link for a report
+// @Summary Share a report
+// @Description Creates a signed, expiring public link to a read-only view of a report owned by the authenticated user, for sharing with someone without an account. The link can be password protected; it stops working when it expires or is revoked. Requires a plan with the sharing capability.
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param id path int true "Report ID"
+// @Param share body CreateShareRequest false "Share options"
+// @Success 201 {object} ShareResponse "Share link created"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 402 {object} entitlements.CapabilityError "Plan does not include sharing"
+// @Failure 404 {object} ErrorResponse "Report not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /reports/{id}/share [post]
+func CreateReportShare(c *gin.Context) {
+	report, ok := findOwnedReport(c)
+	if !ok {
+		return
+	}
+	if !requireCapability(c, config.CapabilitySharing) {
+		return
+	}
+
+	var req CreateShareRequest
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+	if req.ExpiresInHours == 0 {
+		req.ExpiresInHours = defaultShareTTLHours
+	}
+
+	share, err := models.CreateReportShare(database.DB, report, time.Duration(req.ExpiresInHours)*time.Hour, req.Password)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	auditMemberAction(c, report.UserID, "report.share_create", reportResource(report.ID),
+		gin.H{"share_id": share.ID, "expires_at": share.ExpiresAt, "password": req.Password != ""})
+
+	c.JSON(http.StatusCreated, newShareResponse(share))
+}
+
+// GetReportShares lists the share links of a report
+// @Summary List a report's share links
+// @Description Lists the share links of a report owned by the authenticated user, newest first, including expired and revoked ones with their view counts
+// @Tags reports
+// @Produce json
+// @Param id path int true "Report ID"
+// @Success 200 {object} SharesResponse "Share links"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Report not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /reports/{id}/shares [get]
+func GetReportShares(c *gin.Context) {
+	report, ok := findOwnedReport(c)
+	if !ok {
+		return
+	}
+
+	shares, err := models.FindReportShares(database.DB, report.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch share links"})
+		return
+	}
+
+	response := SharesResponse{Shares: make([]ShareResponse, len(shares))}
+	for i := range shares {
+		response.Shares[i] = newShareResponse(&shares[i])
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeReportShare revokes a share link
+// @Summary Revoke a share link
+// @Description Revokes a share link of a report owned by the authenticated user; the link stops working immediately
+// @Tags reports
+// @Produce json
+// @Param id path int true "Report ID"
+// @Param shareId path int true "Share ID"
+// @Success 200 {object} ShareResponse "Share link revoked"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Report or share link not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /reports/{id}/shares/{shareId} [delete]
+func RevokeReportShare(c *gin.Context) {
+	report, ok := findOwnedReport(c)
+	if !ok {
+		return
+	}
+
+	shareID, err := strconv.ParseUint(c.Param("shareId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid share ID"})
+		return
+	}
+
+	share, err := models.FindReportShare(database.DB, report.ID, uint(shareID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Share link not found"})
+		return
+	}
+	if err := share.Revoke(database.DB); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to revoke share link"})
+		return
+	}
+	auditMemberAction(c, report.UserID, "report.share_revoke", reportResource(report.ID), gin.H{"share_id": share.ID})
+
+	c.JSON(http.StatusOK, newShareResponse(share))
+}
+
+// GetSharedReport serves the read-only view behind a share link
+// @Summary View a shared report
+// @Description Returns the read-only view of a report shared by its owner. No account is needed; password-protected links require the password in X-Share-Password and lock after 10 wrong attempts; requests without it are not counted. Organizations' embedded widgets call it cross-origin with their token in X-Widget-Token from one of the widget's allowed origins, and can only show reports of the organization's members.
+// @Tags reports
+// @Produce json
+// @Param token path string true "Share token from the link"
+// @Param X-Share-Password header string false "Password of a protected link"
+// @Param X-Widget-Token header string false "Token of the embedded widget making the request"
+// @Success 200 {object} SharedReportResponse "Shared report"
+// @Failure 401 {object} ErrorResponse "Unauthorized - Wrong or missing password"
+// @Failure 403 {object} ErrorResponse "Forbidden - Link locked after too many wrong passwords, or invalid widget token for the origin"
+// @Failure 404 {object} ErrorResponse "Link not found, revoked or expired"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Router /shared/{token} [get]
+func GetSharedReport(c *gin.Context) {
+	share, err := models.FindReportShareByToken(database.DB, shareSecret(), c.Param("token"))
+	if err != nil {
+		if errors.Is(err, models.ErrShareNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Share link not found or expired"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch share link"})
+		return
+	}
+
+	// Widgets only show reports of their organization's members
+	if widget, ok := c.Get("embedWidget"); ok {
+		owner, err := models.FindUserByID(database.DB, share.OwnerID)
+		if err != nil || owner.OrganizationID == nil || *owner.OrganizationID != widget.(*models.EmbedWidget).OrganizationID {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Share link not found or expired"})
+			return
+		}
+	}
+
+	if err := share.CheckPassword(database.DB, c.GetHeader("X-Share-Password")); err != nil {
+		switch {
+		case errors.Is(err, models.ErrShareLocked):
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "Share link is locked after too many wrong passwords"})
+		case errors.Is(err, models.ErrSharePasswordMissing), errors.Is(err, models.ErrSharePasswordWrong):
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to check share password"})
+		}
+		return
+	}
+
+	// Deleted reports are no longer served
+	report, err := models.FindReportByID(database.DB, share.ReportID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Share link not found or expired"})
+		return
+	}
+	if err := encryption.OpenReport(c.Request.Context(), report); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to decrypt report text"})
+		return
+	}
+	if err := report.LoadTags(database.DB); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch report tags"})
+		return
+	}
+	if err := share.RecordView(database.DB); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to record view"})
+		return
+	}
+	if !recordReportAccess(c, report, models.AccessSharedLink, share) {
+		return
+	}
+
+	// Shared links must not be cached by browsers or proxies beyond their lifetime
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, SharedReportResponse{
+		Title:             report.Title,
+		Description:       report.Description,
+		Correction:        report.Correction,
+		MatchingScale:     report.MatchingScale,
+		Tags:              report.Tags,
+		TranslationStatus: report.TranslationStatus,
+		CreatedAt:         report.CreatedAt,
+		UpdatedAt:         report.UpdatedAt,
+		ExpiresAt:         share.ExpiresAt,
+	})
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/embedding"
+	"github.com/gin-gonic/gin"
+)
+
+// maxSimilarReports caps the limit parameter of GetSimilarReports
+const maxSimilarReports = 20
+
+// SimilarReportsResponse represents the reports most similar to a report
+type SimilarReportsResponse struct {
+	Reports []models.SimilarReport `json:"reports"`
+	// Embedding model the similarities were computed with
+	Model string `json:"model" example:"hash-256"`
+}
+
+// GetSimilarReports finds the sessions whose translated text is closest to a report's
+// @Summary Find similar reports
+// @Description Returns the owner's other reports whose translated text is most similar to this report's, most similar first, with a cosine similarity score. Reports are embedded in the background shortly after they are translated or edited; reports of users who encrypt their report text are never embedded.
+// @Tags reports
+// @Produce json
+// @Param id path int true "Report ID"
+// @Param limit query int false "Number of reports to return (max 20)" default(5)
+// @Success 200 {object} SimilarReportsResponse "Similar reports"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID or limit"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Report not found"
+// @Failure 409 {object} ErrorResponse "Conflict - Report text is encrypted or not indexed yet"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Failure 503 {object} ErrorResponse "Service Unavailable - Similarity search is not enabled on this server"
+// @Security BearerAuth
+// @Router /reports/{id}/similar [get]
+func GetSimilarReports(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if err != nil || limit < 1 || limit > maxSimilarReports {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "limit must be between 1 and 20"})
+		return
+	}
+
+	report, ok := findViewableReport(c)
+	if !ok {
+		return
+	}
+
+	if !database.VectorSearch {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "Similarity search requires the pgvector extension"})
+		return
+	}
+
+	owner, err := models.FindUserByID(database.DB, report.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch report owner"})
+		return
+	}
+	if owner.EncryptReportText {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Similarity search is unavailable for encrypted report text"})
+		return
+	}
+
+	model := embedding.Default().Model()
+	indexed, err := models.HasCurrentEmbedding(database.DB, report, model)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to find similar reports"})
+		return
+	}
+	if !indexed {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Report is not indexed yet; try again in a minute"})
+		return
+	}
+
+	similar, err := models.FindSimilarReports(database.DB, report, model, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to find similar reports"})
+		return
+	}
+
+	reports := make([]models.Report, len(similar))
+	for i := range similar {
+		reports[i] = similar[i].Report
+	}
+	if !openReports(c, reports) {
+		return
+	}
+	if !recordReportAccesses(c, models.ReportIDs(reports), models.AccessSimilar) {
+		return
+	}
+	for i := range similar {
+		similar[i].Report = reports[i]
+	}
+
+	c.JSON(http.StatusOK, SimilarReportsResponse{Reports: similar, Model: model})
+}
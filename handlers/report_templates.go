@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ReportTemplateRequest represents the request body for creating or replacing a report template
+type ReportTemplateRequest struct {
+	Name        string                   `json:"name" binding:"required,max=100" example:"Speech therapy session"`
+	Description string                   `json:"description" binding:"max=1000" example:"Notes taken after each speech therapy session"`
+	Sections    []models.TemplateSection `json:"sections" binding:"required"`
+}
+
+// ReportTemplatesResponse represents a list of report templates
+type ReportTemplatesResponse struct {
+	Templates []models.ReportTemplate `json:"templates"`
+}
+
+// findReportTemplate loads the template named by the id path parameter,
+// writing the error response if there is none
+func findReportTemplate(c *gin.Context) (*models.ReportTemplate, bool) {
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid template ID"})
+		return nil, false
+	}
+	template, err := models.FindReportTemplate(database.DB, uint(templateID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Report template not found"})
+		return nil, false
+	}
+	return template, true
+}
+
+// GetReportTemplates lists the report templates to choose from
+// @Summary List report templates
+// @Description Lists the report templates that can be chosen when uploading a recording, by name
+// @Tags report-templates
+// @Produce json
+// @Success 200 {object} ReportTemplatesResponse "Report templates"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /report-templates [get]
+func GetReportTemplates(c *gin.Context) {
+	templates, err := models.FindReportTemplates(database.DB, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch report templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ReportTemplatesResponse{Templates: templates})
+}
+
+// GetAllReportTemplates lists every report template
+// @Summary List all report templates
+// @Description Lists every report template by name, including archived ones (admin only)
+// @Tags admin
+// @Produce json
+// @Success 200 {object} ReportTemplatesResponse "Report templates"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/report-templates [get]
+func GetAllReportTemplates(c *gin.Context) {
+	templates, err := models.FindReportTemplates(database.DB, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch report templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ReportTemplatesResponse{Templates: templates})
+}
+
+// GetReportTemplate returns a report template
+// @Summary Get a report template
+// @Description Returns a report template with its sections and fields, including archived templates that existing reports still follow
+// @Tags report-templates
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} models.ReportTemplate "Report template"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Report template not found"
+// @Security BearerAuth
+// @Router /report-templates/{id} [get]
+func GetReportTemplate(c *gin.Context) {
+	template, ok := findReportTemplate(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// CreateReportTemplate defines a report template
+// @Summary Create a report template
+// @Description Defines a template for structured clinical notes: named sections of fields, each of type text, number, boolean or choice (with options) and optionally required. Reports uploaded with the template carry the notes in content.notes and are validated against it (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param template body ReportTemplateRequest true "Template definition"
+// @Success 201 {object} models.ReportTemplate "Created template"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid sections"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 409 {object} ErrorResponse "Conflict - Name already used"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/report-templates [post]
+func CreateReportTemplate(c *gin.Context) {
+	// Get authenticated user ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	var req ReportTemplateRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	template := &models.ReportTemplate{Name: req.Name, Description: req.Description, CreatedBy: userID.(uint)}
+	if err := template.SetSections(req.Sections); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := models.CreateReportTemplate(database.DB, template); err != nil {
+		if errors.Is(err, models.ErrTemplateNameTaken) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create report template"})
+		return
+	}
+
+	auditAdminAction(c, userID.(uint), "report_template.create", gin.H{"template_id": template.ID})
+	c.JSON(http.StatusCreated, template)
+}
+
+// UpdateReportTemplate replaces a report template's definition
+// @Summary Update a report template
+// @Description Replaces the name, description and sections of a report template. New and updated reports are validated against the new definition; stored reports keep their notes as they are (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Param template body ReportTemplateRequest true "Template definition"
+// @Success 200 {object} models.ReportTemplate "Updated template"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID or sections"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Report template not found"
+// @Failure 409 {object} ErrorResponse "Conflict - Name already used"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/report-templates/{id} [put]
+func UpdateReportTemplate(c *gin.Context) {
+	// Get authenticated user ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	template, ok := findReportTemplate(c)
+	if !ok {
+		return
+	}
+
+	var req ReportTemplateRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	template.Name = req.Name
+	template.Description = req.Description
+	if err := template.SetSections(req.Sections); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := template.Save(database.DB); err != nil {
+		if errors.Is(err, models.ErrTemplateNameTaken) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update report template"})
+		return
+	}
+
+	auditAdminAction(c, userID.(uint), "report_template.update", gin.H{"template_id": template.ID})
+	c.JSON(http.StatusOK, template)
+}
+
+// ArchiveReportTemplate retires a report template
+// @Summary Archive a report template
+// @Description Archives a report template so it can no longer be chosen for new reports. Reports made with it keep it and it stays readable via GET /report-templates/{id} (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} models.ReportTemplate "Archived template"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Report template not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/report-templates/{id} [delete]
+func ArchiveReportTemplate(c *gin.Context) {
+	// Get authenticated user ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	template, ok := findReportTemplate(c)
+	if !ok {
+		return
+	}
+	if err := template.Archive(database.DB); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to archive report template"})
+		return
+	}
+
+	auditAdminAction(c, userID.(uint), "report_template.archive", gin.H{"template_id": template.ID})
+	c.JSON(http.StatusOK, template)
+}
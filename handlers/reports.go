@@ -1,38 +1,241 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
 	"github.com/ThinkInkTeam/thinkink-core-backend/database"
 	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/branding"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/encryption"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/pdf"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-// ReportsResponse represents a response containing a list of reports
+// ReportsResponse represents a page of reports
 type ReportsResponse struct {
-	Reports []models.Report `json:"reports"`
+	Reports    []models.Report `json:"reports"`
+	Pagination Pagination      `json:"pagination"`
 }
 
-// SortedReportsResponse represents a response containing sorted reports
-type SortedReportsResponse struct {
-	Reports []models.Report `json:"reports"`
-	Sorting SortingInfo     `json:"sorting"`
+// MatchingScaleSeriesResponse represents the matching scale aggregated over time
+type MatchingScaleSeriesResponse struct {
+	Granularity string                      `json:"granularity" example:"week"`
+	Timezone    string                      `json:"timezone" example:"Europe/Berlin"`
+	Points      []models.MatchingScalePoint `json:"points"`
 }
 
-// SortingInfo represents sorting information
-type SortingInfo struct {
-	Field string `json:"field" example:"matching_scale"`
-	Order string `json:"order" example:"descending"`
+// resolveReportOwner returns whose reports the request targets: the optional
+// user_id query parameter when the caller holds an accepted link to that user,
+// otherwise the caller. It writes the error response and returns false on failure.
+func resolveReportOwner(c *gin.Context, viewerID uint) (uint, bool) {
+	ownerParam := c.Query("user_id")
+	if ownerParam == "" {
+		return viewerID, true
+	}
+
+	ownerID, err := strconv.ParseUint(ownerParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return 0, false
+	}
+
+	allowed, err := models.CanViewReports(database.DB, viewerID, uint(ownerID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to check access"})
+		return 0, false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "You do not have access to this user's reports"})
+		return 0, false
+	}
+
+	return uint(ownerID), true
+}
+
+// openReports decrypts report text and loads tags for the response. It writes
+// the error response and returns false on failure.
+func openReports(c *gin.Context, reports []models.Report) bool {
+	if err := encryption.OpenReports(c.Request.Context(), reports); err != nil {
+		log.Printf("Failed to decrypt report text: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to decrypt report text"})
+		return false
+	}
+	if err := models.LoadReportTags(database.DB, reports); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch report tags"})
+		return false
+	}
+	return true
+}
+
+// openReport decrypts a single report's text and loads its tags for the
+// response. It writes the error response and returns false on failure.
+func openReport(c *gin.Context, report *models.Report) bool {
+	if err := encryption.OpenReport(c.Request.Context(), report); err != nil {
+		log.Printf("Failed to decrypt report text: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to decrypt report text"})
+		return false
+	}
+	if err := report.LoadTags(database.DB); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch report tags"})
+		return false
+	}
+	return true
+}
+
+// loadReportContent reads a report's content back from cold storage for the
+// response. It writes the error response and returns false on failure.
+func loadReportContent(c *gin.Context, report *models.Report) bool {
+	if err := report.LoadArchivedContent(c.Request.Context()); err != nil {
+		log.Printf("Failed to load report content: %v", err)
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "Report content could not be read from cold storage; try again later"})
+		return false
+	}
+	return true
+}
+
+// parseTagFilter reads the repeatable tag query parameter. It writes the error
+// response and returns false on failure.
+func parseTagFilter(c *gin.Context) ([]string, bool) {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, value := range c.QueryArray("tag") {
+		name, err := models.NormalizeTagName(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return nil, false
+		}
+		if !seen[name] {
+			seen[name] = true
+			tags = append(tags, name)
+		}
+	}
+	return tags, true
+}
+
+// parseDateQuery reads a date query parameter given as an RFC 3339 timestamp
+// or a YYYY-MM-DD day; with endOfDay a day means the start of the next day, so
+// an exclusive upper bound includes it. It writes the error response and
+// returns false on failure.
+func parseDateQuery(c *gin.Context, name string, endOfDay bool) (*time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, true
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: name + " must be a date (YYYY-MM-DD) or RFC 3339 timestamp"})
+		return nil, false
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, true
+}
+
+// parseReportFilter reads the from, to, min_scale, max_scale, q, tag and archived query
+// parameters. Dates are RFC 3339 timestamps or YYYY-MM-DD days; a day given as
+// to includes that whole day. It writes the error response and returns false on failure.
+func parseReportFilter(c *gin.Context) (models.ReportFilter, bool) {
+	var filter models.ReportFilter
+
+	parseScale := func(name string) (*int, bool) {
+		value := c.Query(name)
+		if value == "" {
+			return nil, true
+		}
+		scale, err := strconv.Atoi(value)
+		if err != nil || scale < 0 || scale > 100 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: name + " must be between 0 and 100"})
+			return nil, false
+		}
+		return &scale, true
+	}
+
+	var ok bool
+	if filter.From, ok = parseDateQuery(c, "from", false); !ok {
+		return filter, false
+	}
+	if filter.To, ok = parseDateQuery(c, "to", true); !ok {
+		return filter, false
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "from must be before to"})
+		return filter, false
+	}
+	if filter.MinScale, ok = parseScale("min_scale"); !ok {
+		return filter, false
+	}
+	if filter.MaxScale, ok = parseScale("max_scale"); !ok {
+		return filter, false
+	}
+	if filter.MinScale != nil && filter.MaxScale != nil && *filter.MinScale > *filter.MaxScale {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "min_scale must not exceed max_scale"})
+		return filter, false
+	}
+	filter.Query = strings.TrimSpace(c.Query("q"))
+	if filter.Tags, ok = parseTagFilter(c); !ok {
+		return filter, false
+	}
+
+	switch c.DefaultQuery("flagged", "false") {
+	case "false":
+	case "true":
+		filter.Flagged = true
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "flagged must be true or false"})
+		return filter, false
+	}
+
+	switch c.DefaultQuery("archived", "false") {
+	case "false":
+		filter.Archived = models.ArchivedExclude
+	case "true":
+		filter.Archived = models.ArchivedOnly
+	case "all":
+		filter.Archived = models.ArchivedInclude
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "archived must be true, false or all"})
+		return filter, false
+	}
+
+	return filter, true
 }
 
 // GetUserReports retrieves all reports for the authenticated user
 // @Summary Get all user reports
-// @Description Retrieves all reports belonging to the authenticated user
+// @Description Retrieves the reports belonging to the authenticated user, or to a user who granted them access via an account link, newest first or in the given sort order, optionally filtered by date range, matching scale and text. Results are paginated by limit/offset or, unless sorted, by the cursor returned as pagination.next_cursor.
 // @Tags reports
 // @Produce json
-// @Success 200 {object} ReportsResponse "List of user reports"
+// @Param user_id query int false "Owner of the reports (defaults to the authenticated user)"
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Param offset query int false "Number of reports to skip (at most 10000); cannot be combined with cursor"
+// @Param cursor query string false "Cursor from a previous page's pagination.next_cursor; cannot be combined with sort"
+// @Param sort query string false "Comma-separated field:direction pairs, e.g. matching_scale:desc; fields are created_at, updated_at, matching_scale, title and size_bytes, directions asc (default) or desc"
+// @Param from query string false "Only reports created on or after this date (YYYY-MM-DD or RFC 3339)"
+// @Param to query string false "Only reports created before this timestamp, or on or before this date (YYYY-MM-DD or RFC 3339)"
+// @Param min_scale query int false "Minimum matching scale"
+// @Param max_scale query int false "Maximum matching scale"
+// @Param q query string false "Case-insensitive text to find in the title or description"
+// @Param tag query []string false "Only reports carrying all of these tags; repeat for several" collectionFormat(multi)
+// @Param archived query string false "false (default) hides archived reports, true lists only archived reports, all lists both"
+// @Param flagged query bool false "true lists only reports flagged by moderation"
+// @Success 200 {object} ReportsResponse "Page of user reports"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid user ID, filter, sort or pagination parameters"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - No access to this user's reports"
 // @Failure 500 {object} ErrorResponse "Internal Server Error"
 // @Security BearerAuth
 // @Router /reports [get]
@@ -44,37 +247,75 @@ func GetUserReports(c *gin.Context) {
 		return
 	}
 
+	ownerID, ok := resolveReportOwner(c, userID.(uint))
+	if !ok {
+		return
+	}
+
+	filter, ok := parseReportFilter(c)
+	if !ok {
+		return
+	}
+
+	page, ok := parseSortedPage(c, models.ReportSortFields)
+	if !ok {
+		return
+	}
+
 	// Fetch user from database
-	user, err := models.FindUserByID(database.DB, userID.(uint))
+	user, err := models.FindUserByID(database.DB, ownerID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch user"})
 		return
 	}
 
-	// Get all reports for the user
-	reports, err := user.FindAllUserReports(database.DB)
+	// Get one page of matching reports for the user
+	reports, info, err := user.FindUserReportsPage(database.DB, filter, page)
 	if err != nil {
+		if err.Error() == "invalid cursor" {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid cursor"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch reports"})
 		return
 	}
 
+	if !openReports(c, reports) {
+		return
+	}
+	if !recordReportAccesses(c, models.ReportIDs(reports), models.AccessList) {
+		return
+	}
+
 	c.JSON(http.StatusOK, ReportsResponse{
-		Reports: reports,
+		Reports:    reports,
+		Pagination: newPagination(page, info),
 	})
 }
 
-// GetUserReportsSortedByScale retrieves all reports for the authenticated user sorted by matching scale
-// @Summary Get user reports sorted by matching scale
-// @Description Retrieves all reports belonging to the authenticated user, sorted by matching scale
+// ReportSearchResponse represents a page of full-text search results
+type ReportSearchResponse struct {
+	Results    []models.ReportSearchResult `json:"results"`
+	Pagination Pagination                  `json:"pagination"`
+}
+
+// SearchReports runs a full-text search over the user's reports
+// @Summary Search reports
+// @Description Full-text search over report titles, descriptions and translated content, ranked by relevance with matching passages highlighted in <mark> tags. Supports quoted phrases, "or" and -exclusions. Linked viewers can search the reports they have access to.
 // @Tags reports
 // @Produce json
-// @Param asc query string false "Sort ascending (true) or descending (false, default)"
-// @Success 200 {object} SortedReportsResponse "List of user reports sorted by matching scale"
+// @Param q query string true "Search text, e.g. coffee"
+// @Param user_id query int false "Owner of the reports (defaults to the authenticated user)"
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Param offset query int false "Number of results to skip (at most 10000)"
+// @Success 200 {object} ReportSearchResponse "Ranked search results"
+// @Failure 400 {object} ErrorResponse "Bad Request - Missing query, invalid user ID or pagination parameters"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - No access to this user's reports"
 // @Failure 500 {object} ErrorResponse "Internal Server Error"
 // @Security BearerAuth
-// @Router /reports/sorted [get]
-func GetUserReportsSortedByScale(c *gin.Context) {
+// @Router /reports/search [get]
+func SearchReports(c *gin.Context) {
 	// Get authenticated user ID
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -82,39 +323,330 @@ func GetUserReportsSortedByScale(c *gin.Context) {
 		return
 	}
 
-	// Parse sort direction from query parameter
-	ascendingParam := c.DefaultQuery("asc", "false")
-	ascending, err := strconv.ParseBool(ascendingParam)
+	text := strings.TrimSpace(c.Query("q"))
+	if text == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "q is required"})
+		return
+	}
+
+	ownerID, ok := resolveReportOwner(c, userID.(uint))
+	if !ok {
+		return
+	}
+
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+	if page.Cursor != "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Search results are paginated by offset, not cursor"})
+		return
+	}
+
+	results, info, err := models.SearchUserReports(database.DB, ownerID, text, page)
 	if err != nil {
-		ascending = false // Default to descending (highest matching scale first)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to search reports"})
+		return
 	}
 
-	// Fetch user from database
-	user, err := models.FindUserByID(database.DB, userID.(uint))
+	// Encrypted text is decrypted for the response; its highlight would only show ciphertext
+	for i := range results {
+		if !encryption.IsEncrypted(results[i].Description) {
+			continue
+		}
+		description, err := encryption.Decrypt(c.Request.Context(), ownerID, results[i].Description)
+		if err != nil {
+			log.Printf("Failed to decrypt report %d text: %v", results[i].ID, err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to decrypt report text"})
+			return
+		}
+		results[i].Description = description
+		results[i].Highlight = ""
+	}
+
+	ids := make([]uint, len(results))
+	for i := range results {
+		ids[i] = results[i].ID
+	}
+	if !recordReportAccesses(c, ids, models.AccessSearch) {
+		return
+	}
+
+	c.JSON(http.StatusOK, ReportSearchResponse{
+		Results:    results,
+		Pagination: newPagination(page, info),
+	})
+}
+
+// ReportResponse represents a response containing a single report
+type ReportResponse struct {
+	Report models.Report `json:"report"`
+}
+
+// GetReport retrieves a single report
+// @Summary Get a report
+// @Description Retrieves a report owned by the authenticated user or by a user who granted them access via an account link. Reports created from an upload include the source file's metadata and a download link under source_file.
+// @Tags reports
+// @Produce json
+// @Param id path int true "Report ID"
+// @Success 200 {object} ReportResponse "Report details"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Report not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Failure 503 {object} ErrorResponse "Report content could not be read from cold storage"
+// @Security BearerAuth
+// @Router /reports/{id} [get]
+func GetReport(c *gin.Context) {
+	report, ok := findViewableReport(c, models.PreloadSourceFile)
+	if !ok {
+		return
+	}
+	if report.SourceFile != nil {
+		report.SourceFile.DownloadURL = fmt.Sprintf("/reports/%d/source-file", report.ID)
+	}
+	if !loadReportContent(c, report) {
+		return
+	}
+	if !recordReportAccess(c, report, models.AccessView, nil) {
+		return
+	}
+
+	c.JSON(http.StatusOK, ReportResponse{Report: *report})
+}
+
+// DownloadReportSourceFile downloads the file a report was created from
+// @Summary Download a report's source file
+// @Description Downloads the original uploaded signal file of a report owned by the authenticated user or by a user who granted them access via an account link
+// @Tags reports
+// @Produce octet-stream
+// @Param id path int true "Report ID"
+// @Success 200 {file} file "Source file"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Report not found or it has no source file"
+// @Failure 410 {object} ErrorResponse "Gone - The source file was archived or purged by the retention policy"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /reports/{id}/source-file [get]
+func DownloadReportSourceFile(c *gin.Context) {
+	report, ok := findViewableReport(c, models.PreloadSourceFile)
+	if !ok {
+		return
+	}
+	if report.SourceFile == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Report has no source file"})
+		return
+	}
+	if !report.SourceFile.Available() {
+		c.JSON(http.StatusGone, ErrorResponse{Error: "Source file is no longer available"})
+		return
+	}
+	if !recordReportAccess(c, report, models.AccessSourceFile, nil) {
+		return
+	}
+
+	c.FileAttachment(report.SourceFile.FilePath, report.SourceFile.Filename)
+}
+
+// DownloadReport downloads a single report as a JSON file
+// @Summary Download a report
+// @Description Downloads a report owned by the authenticated user or by a user who granted them access via an account link. Linked viewers can download but not modify reports.
+// @Tags reports
+// @Produce json
+// @Param id path int true "Report ID"
+// @Success 200 {object} models.Report "Report file"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Report not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Failure 503 {object} ErrorResponse "Report content could not be read from cold storage"
+// @Security BearerAuth
+// @Router /reports/{id}/download [get]
+func DownloadReport(c *gin.Context) {
+	report, ok := findViewableReport(c)
+	if !ok {
+		return
+	}
+	if !loadReportContent(c, report) {
+		return
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to encode report"})
+		return
+	}
+	if !recordReportAccess(c, report, models.AccessDownload, nil) {
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="report-%d.json"`, report.ID))
+	c.Data(http.StatusOK, "application/json", data)
+}
+
+// ExportReport exports a single report as a formatted document
+// @Summary Export a report
+// @Description Exports a report owned by the authenticated user or by a user who granted them access via an account link as a formatted PDF for sharing with doctors, with the patient's name and date of birth, the translated text and any correction, the matching scale, tags and timestamps in the patient's time zone. Patients in an organization with branding get its name, logo, colors and footer text. Requires a plan with the pdf_export capability.
+// @Tags reports
+// @Produce application/pdf
+// @Param id path int true "Report ID"
+// @Param format query string false "Export format; only pdf is supported" default(pdf) Enums(pdf)
+// @Success 200 {file} file "Report document"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID or unsupported format"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 402 {object} entitlements.CapabilityError "Plan does not include PDF export"
+// @Failure 404 {object} ErrorResponse "Report not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /reports/{id}/export [get]
+func ExportReport(c *gin.Context) {
+	if format := c.DefaultQuery("format", "pdf"); format != "pdf" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Unsupported export format; supported formats: pdf"})
+		return
+	}
+	if !requireCapability(c, config.CapabilityPDFExport) {
+		return
+	}
+
+	report, ok := findViewableReport(c)
+	if !ok {
+		return
+	}
+
+	patient, err := models.FindUserByID(database.DB, report.UserID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch user"})
 		return
 	}
 
-	// Get reports sorted by matching scale
-	reports, err := user.FindAllUserReportsSortedByScale(database.DB, ascending)
+	// Reports carry the branding of the patient's organization
+	orgBranding, err := models.FindUserBranding(database.DB, patient)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch sorted reports"})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch branding"})
+		return
+	}
+	pdfBranding, err := branding.ForPDF(orgBranding)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to read branding logo"})
+		return
+	}
+	if !recordReportAccess(c, report, models.AccessExport, nil) {
 		return
 	}
 
-	orderText := "descending"
-	if ascending {
-		orderText = "ascending"
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="report-%d.pdf"`, report.ID))
+	c.Status(http.StatusOK)
+	if _, err := pdf.WriteReport(c.Writer, report, patient, pdfBranding); err != nil {
+		// Headers are already sent; the client sees a truncated download
+		log.Printf("Failed to export report %d as PDF: %v", report.ID, err)
 	}
+}
 
-	c.JSON(http.StatusOK, SortedReportsResponse{
-		Reports: reports,
-		Sorting: SortingInfo{
-			Field: "matching_scale",
-			Order: orderText,
-		},
-	})
+// GetMatchingScaleSeries aggregates the matching scale of the user's reports over time
+// @Summary Get the matching scale over time
+// @Description Aggregates the matching scale of the reports belonging to the authenticated user, or to a user who granted them access via an account link, per day or week in the owner's time zone, oldest first: the number of reports and the average, lowest and highest scale. Periods without reports are left out. Takes the same filters as listing reports.
+// @Tags reports
+// @Produce json
+// @Param granularity query string false "Length of each period" default(day) Enums(day, week)
+// @Param user_id query int false "Owner of the reports (defaults to the authenticated user)"
+// @Param from query string false "Only reports created on or after this date (YYYY-MM-DD or RFC 3339)"
+// @Param to query string false "Only reports created before this timestamp, or on or before this date (YYYY-MM-DD or RFC 3339)"
+// @Param min_scale query int false "Minimum matching scale"
+// @Param max_scale query int false "Maximum matching scale"
+// @Param q query string false "Case-insensitive text to find in the title or description"
+// @Param tag query []string false "Only reports carrying all of these tags; repeat for several" collectionFormat(multi)
+// @Param archived query string false "false (default) leaves out archived reports, true uses only archived reports, all uses both"
+// @Param flagged query bool false "true uses only reports flagged by moderation"
+// @Success 200 {object} MatchingScaleSeriesResponse "Matching scale per period"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid user ID, granularity or filter"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - No access to this user's reports"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /reports/matching-scale/timeseries [get]
+func GetMatchingScaleSeries(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	granularity := c.DefaultQuery("granularity", models.GranularityDay)
+	if granularity != models.GranularityDay && granularity != models.GranularityWeek {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "granularity must be day or week"})
+		return
+	}
+
+	ownerID, ok := resolveReportOwner(c, userID.(uint))
+	if !ok {
+		return
+	}
+
+	filter, ok := parseReportFilter(c)
+	if !ok {
+		return
+	}
+
+	owner, err := models.FindUserByID(database.DB, ownerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch user"})
+		return
+	}
+	timezone := owner.Timezone
+	if timezone == "" {
+		timezone = utils.DefaultTimezone
+	}
+
+	points, err := models.FindMatchingScaleSeries(database.DB, ownerID, filter, granularity, timezone)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to aggregate matching scale"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MatchingScaleSeriesResponse{Granularity: granularity, Timezone: timezone, Points: points})
+}
+
+// findViewableReport loads the report named by the :id path parameter if the
+// authenticated user may view it. It writes the error response and returns
+// false on failure.
+func findViewableReport(c *gin.Context, scopes ...func(*gorm.DB) *gorm.DB) (*models.Report, bool) {
+	// Get authenticated user ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return nil, false
+	}
+
+	reportID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid report ID"})
+		return nil, false
+	}
+
+	report, err := models.FindReportByID(database.DB.Scopes(scopes...), uint(reportID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Report not found"})
+		return nil, false
+	}
+
+	// Reports without access are reported as missing so IDs cannot be probed
+	allowed, err := models.CanViewReports(database.DB, userID.(uint), report.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to check access"})
+		return nil, false
+	}
+	if !allowed {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Report not found"})
+		return nil, false
+	}
+
+	if !openReport(c, report) {
+		return nil, false
+	}
+
+	return report, true
 }
 
 // MatchReportRequest represents the request body for updating a report's matching scale
@@ -153,7 +685,7 @@ func UpdateReportMatchingScale(c *gin.Context) {
 
 	// Parse request body
 	var req MatchReportRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request body: " + err.Error()})
 		return
 	}
@@ -181,8 +713,256 @@ func UpdateReportMatchingScale(c *gin.Context) {
 		return
 	}
 
+	if !openReport(c, report) {
+		return
+	}
+
 	c.JSON(http.StatusOK, MatchReportResponse{
 		Message: "Report matching scale updated successfully",
 		Report:  *report,
 	})
 }
+
+// UpdateReportRequest represents a partial update of a report; omitted fields are left unchanged
+type UpdateReportRequest struct {
+	Title       *string `json:"title" binding:"omitempty,max=255" example:"Morning session"`
+	Description *string `json:"description" binding:"omitempty,max=10000" example:"I would like a cup of tea"`
+	// Replaces all metadata; an empty object clears it
+	Metadata *map[string]string `json:"metadata" example:"session:morning"`
+}
+
+// UpdateReport partially updates a report
+// @Summary Update a report
+// @Description Updates the title, description and/or metadata of a report owned by the authenticated user. Omitted fields are left unchanged; metadata is replaced as a whole (at most 20 entries, keys up to 64 and values up to 500 characters) and an empty object clears it.
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param id path int true "Report ID"
+// @Param report body UpdateReportRequest true "Fields to update"
+// @Success 200 {object} ReportResponse "Updated report"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID or fields"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Report not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /reports/{id} [patch]
+func UpdateReport(c *gin.Context) {
+	report, ok := findOwnedReport(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateReportRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if req.Title == nil && req.Description == nil && req.Metadata == nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Nothing to update"})
+		return
+	}
+
+	update := models.ReportUpdate{Metadata: req.Metadata}
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if title == "" {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Title must not be empty"})
+			return
+		}
+		update.Title = &title
+	}
+	if req.Metadata != nil {
+		if err := models.ValidateReportMetadata(*req.Metadata); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+	if req.Description != nil {
+		// Users who opted in have their report text stored encrypted
+		description, err := encryption.SealReportText(c.Request.Context(), database.DB, report.UserID, strings.TrimSpace(*req.Description))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to encrypt description"})
+			return
+		}
+		update.Description = &description
+	}
+
+	if err := report.ApplyUpdate(database.DB, update); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update report"})
+		return
+	}
+	if !openReport(c, report) {
+		return
+	}
+
+	c.JSON(http.StatusOK, ReportResponse{Report: *report})
+}
+
+// ReportCorrectionRequest represents the request body for correcting a report's translation.
+// An empty correction removes it.
+type ReportCorrectionRequest struct {
+	Correction string `json:"correction" binding:"max=10000" example:"I would like a cup of tea"`
+}
+
+// SetReportCorrection stores the user's correction of a report's translation
+// @Summary Correct a report's translation
+// @Description Stores what the authenticated user actually meant, as feedback for improving translations. Owner only; an empty correction removes it.
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param id path int true "Report ID"
+// @Param correction body ReportCorrectionRequest true "Corrected translation"
+// @Success 200 {object} ReportResponse "Updated report"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Report not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /reports/{id}/correction [put]
+func SetReportCorrection(c *gin.Context) {
+	report, ok := findOwnedReport(c)
+	if !ok {
+		return
+	}
+
+	var req ReportCorrectionRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	correction, err := encryption.SealReportText(c.Request.Context(), database.DB, report.UserID, strings.TrimSpace(req.Correction))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to encrypt correction"})
+		return
+	}
+	if err := report.SetCorrection(database.DB, correction); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update report"})
+		return
+	}
+	if !openReport(c, report) {
+		return
+	}
+
+	c.JSON(http.StatusOK, ReportResponse{Report: *report})
+}
+
+// ArchiveReportResponse represents the response for archiving or unarchiving a report
+type ArchiveReportResponse struct {
+	Message string        `json:"message" example:"Report archived"`
+	Report  models.Report `json:"report"`
+}
+
+// ArchiveReport hides a report from default listings
+// @Summary Archive a report
+// @Description Archives a report owned by the authenticated user. Archived reports are hidden from GET /reports unless archived=true or archived=all, and remain searchable and viewable by ID.
+// @Tags reports
+// @Produce json
+// @Param id path int true "Report ID"
+// @Success 200 {object} ArchiveReportResponse "Report archived"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Report not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /reports/{id}/archive [post]
+func ArchiveReport(c *gin.Context) {
+	setReportArchived(c, true)
+}
+
+// UnarchiveReport restores an archived report to default listings
+// @Summary Unarchive a report
+// @Description Restores an archived report owned by the authenticated user to default listings
+// @Tags reports
+// @Produce json
+// @Param id path int true "Report ID"
+// @Success 200 {object} ArchiveReportResponse "Report unarchived"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Report not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /reports/{id}/unarchive [post]
+func UnarchiveReport(c *gin.Context) {
+	setReportArchived(c, false)
+}
+
+// setReportArchived archives or unarchives the caller's report named by the :id path parameter
+func setReportArchived(c *gin.Context, archived bool) {
+	report, ok := findOwnedReport(c)
+	if !ok {
+		return
+	}
+
+	if err := report.SetArchived(database.DB, archived); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update report"})
+		return
+	}
+	if !openReport(c, report) {
+		return
+	}
+
+	message := "Report unarchived"
+	if archived {
+		message = "Report archived"
+	}
+	c.JSON(http.StatusOK, ArchiveReportResponse{Message: message, Report: *report})
+}
+
+// DeleteReport deletes a report
+// @Summary Delete a report
+// @Description Deletes a report owned by the authenticated user. The report disappears immediately and is permanently purged after the retention window (REPORT_PURGE_AFTER_DAYS).
+// @Tags reports
+// @Produce json
+// @Param id path int true "Report ID"
+// @Success 200 {object} MessageResponse "Report deleted"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Report not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /reports/{id} [delete]
+func DeleteReport(c *gin.Context) {
+	report, ok := findOwnedReport(c)
+	if !ok {
+		return
+	}
+
+	if err := report.Delete(database.DB); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete report"})
+		return
+	}
+	auditMemberAction(c, report.UserID, "report.delete", reportResource(report.ID), nil)
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Report deleted"})
+}
+
+// findOwnedReport loads the report named by the :id path parameter if it
+// belongs to the authenticated user; linked viewers cannot modify reports.
+// It writes the error response and returns false on failure.
+func findOwnedReport(c *gin.Context) (*models.Report, bool) {
+	// Get authenticated user ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return nil, false
+	}
+
+	reportID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid report ID"})
+		return nil, false
+	}
+
+	report, err := models.FindReportByIDForUser(database.DB, uint(reportID), userID.(uint))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Report not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch report"})
+		return nil, false
+	}
+
+	return report, true
+}
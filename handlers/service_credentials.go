@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateServiceCredentialRequest represents the request body for issuing a service key
+type CreateServiceCredentialRequest struct {
+	Name  string `json:"name" binding:"required,max=100" example:"ML training pipeline"`
+	Scope string `json:"scope" binding:"required,oneof=ml_export" example:"ml_export"`
+}
+
+// CreateServiceCredentialResponse carries a newly issued service key, shown only once
+type CreateServiceCredentialResponse struct {
+	Credential models.ServiceCredential `json:"credential"`
+	Key        string                   `json:"key" example:"tik_svc_3q2x..."`
+}
+
+// ServiceCredentialsResponse represents a list of service credentials
+type ServiceCredentialsResponse struct {
+	Credentials []models.ServiceCredential `json:"credentials"`
+}
+
+// AuditLogsResponse represents a list of audit entries
+type AuditLogsResponse struct {
+	Logs []models.AuditLog `json:"logs"`
+}
+
+// CreateServiceCredential issues a service key
+// @Summary Issue a service key
+// @Description Issues an API key for a backend service, e.g. the ML team's feedback export (scope ml_export). The key is returned only once; only its hash is stored (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param credential body CreateServiceCredentialRequest true "Credential details"
+// @Success 201 {object} CreateServiceCredentialResponse "Issued credential and key"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/service-credentials [post]
+func CreateServiceCredential(c *gin.Context) {
+	// Get authenticated user ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	var req CreateServiceCredentialRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	credential, key, err := models.CreateServiceCredential(database.DB, req.Name, req.Scope, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create service credential"})
+		return
+	}
+
+	auditAdminAction(c, userID.(uint), "service_credential.create", gin.H{"credential_id": credential.ID, "scope": credential.Scope})
+	c.JSON(http.StatusCreated, CreateServiceCredentialResponse{Credential: *credential, Key: key})
+}
+
+// GetServiceCredentials lists service credentials
+// @Summary List service keys
+// @Description Lists issued service credentials without their keys (admin only)
+// @Tags admin
+// @Produce json
+// @Success 200 {object} ServiceCredentialsResponse "Service credentials"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/service-credentials [get]
+func GetServiceCredentials(c *gin.Context) {
+	credentials, err := models.FindServiceCredentials(database.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch service credentials"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ServiceCredentialsResponse{Credentials: credentials})
+}
+
+// RevokeServiceCredential revokes a service key
+// @Summary Revoke a service key
+// @Description Revokes a service credential so its key stops working immediately (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path int true "Credential ID"
+// @Success 200 {object} MessageResponse "Credential revoked"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Credential not found"
+// @Security BearerAuth
+// @Router /admin/service-credentials/{id} [delete]
+func RevokeServiceCredential(c *gin.Context) {
+	// Get authenticated user ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	credentialID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid credential ID"})
+		return
+	}
+
+	if err := models.RevokeServiceCredential(database.DB, uint(credentialID)); err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Service credential not found"})
+		return
+	}
+
+	auditAdminAction(c, userID.(uint), "service_credential.revoke", gin.H{"credential_id": credentialID})
+	c.JSON(http.StatusOK, MessageResponse{Message: "Service credential revoked"})
+}
+
+// GetAuditLogs lists audit entries
+// @Summary List audit logs
+// @Description Lists the most recent audit entries, e.g. ML feedback exports and service key changes (admin only)
+// @Tags admin
+// @Produce json
+// @Param action query string false "Only entries for this action, e.g. ml_export.feedback"
+// @Param limit query int false "Maximum number of entries (default 100, max 1000)"
+// @Success 200 {object} AuditLogsResponse "Audit entries"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/audit-logs [get]
+func GetAuditLogs(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 1000 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "limit must be between 1 and 1000"})
+		return
+	}
+
+	logs, err := models.FindAuditLogs(database.DB, c.Query("action"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch audit logs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuditLogsResponse{Logs: logs})
+}
+
+// auditAdminAction records an action taken by an admin
+func auditAdminAction(c *gin.Context, adminID uint, action string, details gin.H) {
+	if err := models.RecordAudit(database.DB, fmt.Sprintf("user:%d", adminID), action, "success", c.ClientIP(), details); err != nil {
+		log.Printf("Failed to audit %s: %v", action, err)
+	}
+}
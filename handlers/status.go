@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/health"
+	"github.com/gin-gonic/gin"
+)
+
+// readinessTimeout bounds the live checks of a readiness probe
+const readinessTimeout = 2 * time.Second
+
+// GetStatus returns aggregated component health for the public status page
+// @Summary Service status
+// @Description Returns the current status and 24-hour hourly uptime history of the API, database, ML service and payments. No internal details are exposed.
+// @Tags status
+// @Produce json
+// @Success 200 {object} health.Summary "Component health"
+// @Router /status [get]
+func GetStatus(c *gin.Context) {
+	// Allow status pages and CDNs to cache briefly; probes only run periodically anyway
+	c.Header("Cache-Control", "public, max-age=30")
+	c.JSON(http.StatusOK, health.Default().Summary())
+}
+
+// GetReadiness reports whether this instance can serve traffic
+// @Summary Readiness probe
+// @Description Readiness check for load balancers and orchestrators. The database is checked live; if it is down the instance is not ready (503). The ML service and payments are reported from their latest probe: if they are down or slow the instance stays ready (200) with status degraded, and uploads fail fast with 503 until the ML service recovers.
+// @Tags status
+// @Produce json
+// @Success 200 {object} health.Readiness "Ready, possibly degraded"
+// @Failure 503 {object} health.Readiness "Not ready"
+// @Router /readyz [get]
+func GetReadiness(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	readiness := health.Default().Readiness(ctx)
+	c.Header("Cache-Control", "no-store")
+	if readiness.Status == health.ReadinessNotReady {
+		c.JSON(http.StatusServiceUnavailable, readiness)
+		return
+	}
+	c.JSON(http.StatusOK, readiness)
+}
+
+// serviceUnavailable writes a 503 telling the client to retry once the
+// component has been probed again, and returns true, if the component is down
+func serviceUnavailable(c *gin.Context, component, message string) bool {
+	prober := health.Default()
+	if prober.Status(component) != health.StatusOutage {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(prober.Interval.Seconds()))))
+	c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: message})
+	return true
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/gin-gonic/gin"
+)
+
+// StripeEventsResponse lists queued Stripe webhook events
+type StripeEventsResponse struct {
+	Events []models.StripeEvent `json:"events"`
+}
+
+// GetStripeEvents lists received Stripe webhook events
+// @Summary List Stripe webhook events
+// @Description Lists received Stripe webhook events, newest first, optionally only those in one state: pending, processed or dead (parked after failing permanently) (admin only)
+// @Tags admin
+// @Produce json
+// @Param state query string false "pending, processed or dead"
+// @Param limit query int false "Maximum number of events (default 50, max 500)"
+// @Success 200 {object} StripeEventsResponse "Stripe events"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid state or limit"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/stripe-events [get]
+func GetStripeEvents(c *gin.Context) {
+	state := c.Query("state")
+	switch state {
+	case "", models.StripeEventPending, models.StripeEventProcessed, models.StripeEventDead:
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "state must be pending, processed or dead"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "limit must be between 1 and 500"})
+		return
+	}
+
+	events, err := models.FindStripeEvents(database.DB, state, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch Stripe events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, StripeEventsResponse{Events: events})
+}
+
+// RetryStripeEvent queues a parked Stripe webhook event again
+// @Summary Retry a Stripe webhook event
+// @Description Puts a dead Stripe webhook event back in the queue with a fresh attempt budget (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Stripe event record ID"
+// @Success 200 {object} models.StripeEvent "Requeued event"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Event not found"
+// @Failure 409 {object} ErrorResponse "Conflict - Event is not dead"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/stripe-events/{id}/retry [post]
+func RetryStripeEvent(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid event ID"})
+		return
+	}
+
+	db := database.DB
+	event, err := models.FindStripeEventByID(db, uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Event not found"})
+		return
+	}
+	if event.State != models.StripeEventDead {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Only dead events can be retried"})
+		return
+	}
+
+	if err := event.Requeue(db); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to requeue event"})
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
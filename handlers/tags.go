@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/gin-gonic/gin"
+)
+
+// TagsResponse represents a user's tags with usage counts
+type TagsResponse struct {
+	Tags []models.TagUsage `json:"tags"`
+}
+
+// AddReportTagRequest represents the request body for tagging a report
+type AddReportTagRequest struct {
+	Name string `json:"name" binding:"required" example:"post-medication"`
+}
+
+// ReportTagsResponse represents the tags attached to a report
+type ReportTagsResponse struct {
+	ReportID uint     `json:"report_id" example:"1"`
+	Tags     []string `json:"tags" example:"morning,post-medication"`
+}
+
+// GetTags lists a user's tags
+// @Summary List tags
+// @Description Lists the tags of the authenticated user, or of a user who granted them access via an account link, with how many reports carry each
+// @Tags tags
+// @Produce json
+// @Param user_id query int false "Owner of the tags (defaults to the authenticated user)"
+// @Success 200 {object} TagsResponse "Tags"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid user ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - No access to this user's reports"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /tags [get]
+func GetTags(c *gin.Context) {
+	// Get authenticated user ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	ownerID, ok := resolveReportOwner(c, userID.(uint))
+	if !ok {
+		return
+	}
+
+	tags, err := models.FindUserTags(database.DB, ownerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, TagsResponse{Tags: tags})
+}
+
+// AddReportTag attaches a tag to a report
+// @Summary Tag a report
+// @Description Attaches a tag to a report, creating it in the report owner's tag set if needed. Names are lowercased and may contain letters, digits, spaces, '-' and '_' (at most 50 characters); a report can carry at most 20 tags. Only the report's owner may tag it.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "Report ID"
+// @Param tag body AddReportTagRequest true "Tag to attach"
+// @Success 200 {object} ReportTagsResponse "Tags on the report"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID or tag name, or too many tags"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Report not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /reports/{id}/tags [post]
+func AddReportTag(c *gin.Context) {
+	report, ok := findOwnedReport(c)
+	if !ok {
+		return
+	}
+
+	var req AddReportTagRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	name, err := models.NormalizeTagName(req.Name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// findOwnedReport has checked the caller is authenticated
+	userID, _ := c.Get("userID")
+	if err := report.AddTag(database.DB, name, userID.(uint)); err != nil {
+		if errors.Is(err, models.ErrTooManyTags) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to tag report"})
+		return
+	}
+
+	respondReportTags(c, report)
+}
+
+// RemoveReportTag detaches a tag from a report
+// @Summary Untag a report
+// @Description Detaches a tag from a report. The tag stays in the owner's tag set. Only the report's owner may untag it.
+// @Tags tags
+// @Produce json
+// @Param id path int true "Report ID"
+// @Param name path string true "Tag name"
+// @Success 200 {object} ReportTagsResponse "Tags on the report"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID or tag name"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Report or tag not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /reports/{id}/tags/{name} [delete]
+func RemoveReportTag(c *gin.Context) {
+	report, ok := findOwnedReport(c)
+	if !ok {
+		return
+	}
+
+	name, err := models.NormalizeTagName(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := report.RemoveTag(database.DB, name); err != nil {
+		if err.Error() == "tag not found" {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Tag not found on this report"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to untag report"})
+		return
+	}
+
+	respondReportTags(c, report)
+}
+
+// respondReportTags writes the report's current tags
+func respondReportTags(c *gin.Context, report *models.Report) {
+	if err := report.LoadTags(database.DB); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch report tags"})
+		return
+	}
+
+	tags := report.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	c.JSON(http.StatusOK, ReportTagsResponse{ReportID: report.ID, Tags: tags})
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/postprocess"
+	"github.com/gin-gonic/gin"
+)
+
+// TextProcessingRequest represents the request body for choosing translation
+// post-processors. Null inherits the organization's choice (for users) or the
+// defaults (for organizations); an empty list turns post-processing off.
+type TextProcessingRequest struct {
+	Processors []string `json:"processors" example:"punctuation,casing,profanity"`
+}
+
+// TextProcessingResponse represents the post-processors applied to a user's translations
+type TextProcessingResponse struct {
+	// Processors applied to new translations, in order
+	Processors []string `json:"processors" example:"punctuation,casing"`
+	// Where they come from: user, organization or default
+	Source string `json:"source" example:"default"`
+	// Every processor that can be chosen
+	Available []string `json:"available" example:"casing,profanity,punctuation"`
+}
+
+// OrganizationResponse represents a single organization
+type OrganizationResponse struct {
+	Organization models.Organization `json:"organization"`
+}
+
+// GetTextProcessing returns the post-processors applied to the user's translations
+// @Summary Get translation post-processing
+// @Description Returns the post-processors applied to the authenticated user's new translations and whether they are the user's own choice, their organization's or the defaults (punctuation and casing). Reports keep the raw ML output in raw_description.
+// @Tags users
+// @Produce json
+// @Success 200 {object} TextProcessingResponse "Post-processing settings"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /user/text-processing [get]
+func GetTextProcessing(c *gin.Context) {
+	// Get authenticated user ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+	user, err := models.FindUserByID(database.DB, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch user"})
+		return
+	}
+	respondTextProcessing(c, user)
+}
+
+// SetTextProcessing chooses the post-processors applied to the user's translations
+// @Summary Set translation post-processing
+// @Description Chooses the post-processors applied, in order, to the authenticated user's new translations: punctuation (sentence marks, including questions), casing (sentence starts and the English pronoun I) and profanity (masks profane words). Processing follows the user's locale. Null falls back to the organization's choice; an empty list stores translations as the ML service returned them. Existing reports are unchanged.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param processing body TextProcessingRequest true "Post-processors"
+// @Success 200 {object} TextProcessingResponse "Post-processing settings"
+// @Failure 400 {object} ErrorResponse "Bad Request - Unknown processor"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /user/text-processing [put]
+func SetTextProcessing(c *gin.Context) {
+	// Get authenticated user ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+	user, err := models.FindUserByID(database.DB, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch user"})
+		return
+	}
+
+	var req TextProcessingRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	processors, err := postprocess.Encode(req.Processors)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := user.SetTextProcessors(database.DB, processors); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update post-processing"})
+		return
+	}
+
+	respondTextProcessing(c, user)
+}
+
+// SetOrgTextProcessing chooses the post-processors applied to an organization's translations
+// @Summary Set an organization's translation post-processing
+// @Description Chooses the post-processors applied, in order, to new translations of organization members who have not chosen their own. Null restores the defaults (punctuation and casing); an empty list turns post-processing off. Admin only.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param processing body TextProcessingRequest true "Post-processors"
+// @Success 200 {object} OrganizationResponse "Updated organization"
+// @Failure 400 {object} ErrorResponse "Bad Request - Unknown processor"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Organization not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/orgs/{id}/text-processing [put]
+func SetOrgTextProcessing(c *gin.Context) {
+	org, ok := organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	var req TextProcessingRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	processors, err := postprocess.Encode(req.Processors)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := org.SetTextProcessors(database.DB, processors); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update post-processing"})
+		return
+	}
+	auditOrgAction(c, c.MustGet("userID").(uint), org.ID, "organization.text_processing_update", orgResource(org.ID), gin.H{"processors": req.Processors})
+
+	c.JSON(http.StatusOK, OrganizationResponse{Organization: *org})
+}
+
+func respondTextProcessing(c *gin.Context, user *models.User) {
+	processors, source, err := postprocess.Effective(database.DB, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch post-processing"})
+		return
+	}
+	c.JSON(http.StatusOK, TextProcessingResponse{
+		Processors: processors,
+		Source:     source,
+		Available:  postprocess.Available(),
+	})
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/filetype"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxUploadFieldSize bounds each form field sent with an upload, such as
+// metadata and notes
+const maxUploadFieldSize = 1 << 20
+
+// uploadForm is an upload read part by part: the file is streamed to storage
+// and hashed on the way, so memory use does not grow with the file size
+type uploadForm struct {
+	fields map[string]string
+	// Name the client gave the file
+	Filename string
+	// Format recognized from the file's content, e.g. json
+	Format string
+	// Where the file was stored, its size and the hex SHA-256 of its bytes
+	Path        string
+	Size        int64
+	ContentHash string
+}
+
+// value returns a form field, or "" when it was not sent
+func (f *uploadForm) value(name string) string {
+	return f.fields[name]
+}
+
+// valueOr returns a form field, or def when it was not sent or is empty
+func (f *uploadForm) valueOr(name, def string) string {
+	if v := f.fields[name]; v != "" {
+		return v
+	}
+	return def
+}
+
+// remove deletes the stored file, unless it was moved away
+func (f *uploadForm) remove() {
+	if err := os.Remove(f.Path); err != nil && !os.IsNotExist(err) {
+		log.Printf("Failed to remove upload %s: %v", f.Path, err)
+	}
+}
+
+// uploadCheck returns the format of a file part from its first bytes, name
+// and media type, or an error when the file is not accepted
+type uploadCheck func(head []byte, filename, mediaType string) (string, error)
+
+// recordingUpload accepts the upload formats allowed by the settings
+func recordingUpload(head []byte, filename, mediaType string) (string, error) {
+	return filetype.Check(head, filename, mediaType, config.Current())
+}
+
+// readUploadForm streams a multipart upload of at most maxSize bytes,
+// storing its "file" part under UploadDir and keeping the other fields. The
+// file's content is checked with accept before any of it is stored. It
+// writes the error response and returns false on failure; on success the
+// caller owns the stored file.
+func readUploadForm(c *gin.Context, userID uint, maxSize int64, accept uploadCheck) (*uploadForm, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize)
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Request must be a multipart form"})
+		return nil, false
+	}
+
+	form := &uploadForm{fields: make(map[string]string)}
+	fail := func(status int, message string) (*uploadForm, bool) {
+		if form.Path != "" {
+			form.remove()
+		}
+		c.JSON(status, ErrorResponse{Error: message})
+		return nil, false
+	}
+	// Reading fails with a MaxBytesError once the body passes maxSize
+	readFailed := func(err error) (*uploadForm, bool) {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return fail(http.StatusRequestEntityTooLarge, fmt.Sprintf("File too large (max %dMB)", maxSize>>20))
+		}
+		return fail(http.StatusBadRequest, "Invalid multipart form")
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return readFailed(err)
+		}
+
+		name := part.FormName()
+		switch {
+		case name == "file" && part.FileName() != "" && form.Path == "":
+			content := bufio.NewReaderSize(part, filetype.SniffLen)
+			head, err := content.Peek(filetype.SniffLen)
+			if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+				return readFailed(err)
+			}
+			format, err := accept(head, part.FileName(), part.Header.Get("Content-Type"))
+			if err != nil {
+				return fail(http.StatusUnsupportedMediaType, "Unsupported file: "+err.Error())
+			}
+			form.Format = format
+			if err := os.MkdirAll(UploadDir, os.ModePerm); err != nil {
+				return fail(http.StatusInternalServerError, "Could not create upload directory")
+			}
+			form.Filename = part.FileName()
+			form.Path = filepath.Join(UploadDir, fmt.Sprintf("%d-%s%s", userID, uuid.New().String(), filepath.Ext(form.Filename)))
+			if err := form.store(content); err != nil {
+				var maxErr *http.MaxBytesError
+				if errors.As(err, &maxErr) {
+					return readFailed(err)
+				}
+				return fail(http.StatusInternalServerError, "Failed to save file")
+			}
+		case part.FileName() != "":
+			// Only the first file is kept; further ones are read past
+			if _, err := io.Copy(io.Discard, part); err != nil {
+				return readFailed(err)
+			}
+		default:
+			value, err := io.ReadAll(io.LimitReader(part, maxUploadFieldSize+1))
+			if err != nil {
+				return readFailed(err)
+			}
+			if len(value) > maxUploadFieldSize {
+				return fail(http.StatusBadRequest, fmt.Sprintf("Form field %s is too large (max %dKB)", name, maxUploadFieldSize>>10))
+			}
+			form.fields[name] = string(value)
+		}
+		part.Close()
+	}
+
+	if form.Path == "" {
+		return fail(http.StatusBadRequest, "No file uploaded")
+	}
+	return form, true
+}
+
+// verifyChecksum compares the file with the SHA-256 the client sent in the
+// sha256 field, if any, so a file corrupted on the way is refused rather
+// than processed. It writes the error response and returns false when the
+// checksum is malformed or does not match.
+func verifyChecksum(c *gin.Context, form *uploadForm) bool {
+	expected := strings.ToLower(strings.TrimSpace(form.value("sha256")))
+	if expected == "" {
+		return true
+	}
+	if decoded, err := hex.DecodeString(expected); err != nil || len(decoded) != sha256.Size {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "sha256 must be 64 hexadecimal characters"})
+		return false
+	}
+	if expected != form.ContentHash {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("Checksum mismatch: the file received has SHA-256 %s, not %s; it may have been corrupted in transit, please upload it again", form.ContentHash, expected)})
+		return false
+	}
+	return true
+}
+
+// store copies the file part to form.Path, hashing and counting it on the way
+func (f *uploadForm) store(src io.Reader) error {
+	out, err := os.OpenFile(f.Path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o640)
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(out, hash), src)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	f.Size = size
+	f.ContentHash = hex.EncodeToString(hash.Sum(nil))
+	return nil
+}
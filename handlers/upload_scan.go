@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/malware"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/notifications"
+	"github.com/gin-gonic/gin"
+)
+
+// scanUpload checks an uploaded file with the configured malware scanner
+// before anything reads it. Infected files are quarantined, recorded with
+// status quarantined and the user is notified. It writes the error response
+// and returns false when the upload must stop.
+func scanUpload(c *gin.Context, userID uint, form *uploadForm) bool {
+	scanner := malware.Current()
+	if scanner == nil {
+		return true
+	}
+
+	f, err := os.Open(form.Path)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to read file"})
+		return false
+	}
+	defer f.Close()
+
+	result, err := scanner.Scan(c.Request.Context(), f)
+	if err != nil {
+		// Unscanned files are never processed
+		log.Printf("Failed to scan upload of user %d with %s: %v", userID, scanner.Name(), err)
+		c.Header("Retry-After", "60")
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "Malware scanner is unavailable, please retry later"})
+		return false
+	}
+	if !result.Infected {
+		return true
+	}
+
+	signalFile, err := quarantineUpload(userID, form, result.Signature)
+	if err != nil {
+		log.Printf("Failed to quarantine infected upload of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to quarantine infected file"})
+		return false
+	}
+	log.Printf("Quarantined upload %d of user %d: %s", signalFile.ID, userID, result.Signature)
+
+	if user, err := models.FindUserByID(database.DB, userID); err == nil {
+		title := "An uploaded file was quarantined"
+		body := fmt.Sprintf("Hi %s,\n\nThe file %q you uploaded was found to contain malware (%s) and was quarantined. It was not processed and no report was created from it. Please check the device or computer it came from before uploading again.\n", user.Name, form.Filename, result.Signature)
+		if err := notifications.Notify(c.Request.Context(), database.DB, user, title, body, notifications.Channels{Email: true}); err != nil {
+			log.Printf("Failed to notify user %d of quarantined file %d: %v", userID, signalFile.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Error: fmt.Sprintf("File contains malware (%s) and was quarantined", result.Signature)})
+	return false
+}
+
+// quarantineUpload moves an infected upload into the quarantine directory and
+// records it as a quarantined file
+func quarantineUpload(userID uint, form *uploadForm, signature string) (*models.SingleFile, error) {
+	dir := malware.QuarantineDir()
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	filePath := filepath.Join(dir, filepath.Base(form.Path))
+	if err := moveUpload(form.Path, filePath); err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(filePath, 0o400); err != nil {
+		log.Printf("Failed to restrict permissions of quarantined file %s: %v", filePath, err)
+	}
+
+	signalFile, err := models.CreateSingleFile(userID, form.Filename, filePath, "")
+	if err != nil {
+		_ = os.Remove(filePath)
+		return nil, err
+	}
+	now := time.Now()
+	signalFile.QuarantinedAt = &now
+	signalFile.Threat = signature
+	signalFile.State = models.FileStateFailed
+	if err := signalFile.Save(database.DB); err != nil {
+		_ = os.Remove(filePath)
+		return nil, err
+	}
+	return signalFile, nil
+}
+
+// moveUpload renames src to dst, copying when they are on different filesystems
+func moveUpload(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
@@ -0,0 +1,407 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/filetype"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/health"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/ingest"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/jobs"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/plans"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/sessionarchive"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// sessionArchiveFormat is the format of a session upload
+const sessionArchiveFormat = "zip"
+
+// sessionArchiveUpload accepts zip archives only
+func sessionArchiveUpload(head []byte, filename, mediaType string) (string, error) {
+	if err := filetype.CheckArchive(head, filename, mediaType); err != nil {
+		return "", err
+	}
+	return sessionArchiveFormat, nil
+}
+
+// SessionSegmentResult is one segment of an uploaded session
+type SessionSegmentResult struct {
+	File   string `json:"file" example:"segment-01.json"`
+	FileID uint   `json:"file_id" example:"7"`
+	JobID  uint   `json:"job_id" example:"31"`
+}
+
+// SessionUploadResponse represents a successful session upload response
+type SessionUploadResponse struct {
+	Message   string                 `json:"message" example:"Session stored; processing queued"`
+	SessionID uint                   `json:"session_id" example:"4"`
+	StatusURL string                 `json:"status_url" example:"/sessions/4"`
+	Segments  []SessionSegmentResult `json:"segments"`
+	Warnings  []string               `json:"warnings,omitempty"`
+	// How long the segments should wait for processing
+	ETASeconds int `json:"eta_seconds,omitempty" example:"12"`
+}
+
+// SegmentIssues lists what is wrong with one segment of a session
+type SegmentIssues struct {
+	File   string              `json:"file" example:"segment-02.json"`
+	Issues []services.EEGIssue `json:"issues"`
+}
+
+// SessionValidationResponse lists what is wrong with the segments of an uploaded session
+type SessionValidationResponse struct {
+	Error    string          `json:"error" example:"Some segments are not valid EEG data"`
+	Segments []SegmentIssues `json:"segments"`
+}
+
+// sessionSegment is a segment unpacked from a session archive
+type sessionSegment struct {
+	form          *uploadForm
+	matchingScale int
+	metadata      datatypes.JSON
+	translate     bool
+}
+
+// UploadSession handles the upload of a zip archive holding the segments of one recording session.
+// @Summary Upload a recording session
+// @Description Uploads a zip archive holding the segments of one recording session, listed in session order by a manifest.json at the root of the archive: {"title": "...", "recorded_at": "RFC 3339 time", "segments": [{"file": "segment-01.json", "matching_scale": 6, "metadata": {...}}]}. Between 1 and 100 segments may be listed, each a path inside the archive; matching_scale (1-10, default 5) and metadata (as for POST /upload) are optional, and entries the manifest does not list are ignored. Segments must be in one of the accepted upload formats, and JSON segments must be valid EEG data; together they may unpack to at most the upload size limit. The archive is unpacked on the server and a session is created grouping the segments: each is stored as a file and queued for processing into a report of its own, as for POST /upload. Every segment counts as an upload against the plan's quotas. Follow the session at GET /sessions/{id}.
+// @Tags files
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Zip archive with a manifest.json and the segments it lists"
+// @Param sha256 formData string false "Hex SHA-256 of the archive; the upload is refused when the archive received does not match"
+// @Success 202 {object} SessionUploadResponse "Session stored; processing queued"
+// @Failure 400 {object} ErrorResponse "Bad Request - No file uploaded, checksum mismatch, invalid archive or manifest, or invalid segment metadata"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 402 {object} plans.LimitError "Payment Required - Upload or storage quota exceeded"
+// @Failure 413 {object} plans.LimitError "Request Entity Too Large - The archive or its unpacked segments exceed the upload size limit (ErrorResponse), or a segment is larger than the plan allows"
+// @Failure 415 {object} ErrorResponse "Unsupported Media Type - The file is not a zip archive, or a segment is an executable, not an allowed format, or does not match its extension"
+// @Failure 422 {object} SessionValidationResponse "Unprocessable Entity - Segments are not valid EEG data (segments lists what is wrong with each), or the archive contains malware and was quarantined"
+// @Failure 429 {object} plans.LimitError "Too Many Requests - Rate limit exceeded, or translation queue is full (free plan); see Retry-After"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Failure 503 {object} ErrorResponse "Service Unavailable - Translation service or malware scanner is down; see Retry-After"
+// @Security BearerAuth
+// @Router /upload/session [post]
+func UploadSession(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	if serviceUnavailable(c, health.ComponentMLService, "Translation service is unavailable, please retry later") {
+		return
+	}
+
+	settings := config.Current()
+	maxSize := int64(settings.MaxUploadSizeMB) << 20
+
+	// The archive is only kept while its segments are unpacked
+	form, ok := readUploadForm(c, userID.(uint), maxSize, sessionArchiveUpload)
+	if !ok {
+		return
+	}
+	defer form.remove()
+
+	if !verifyChecksum(c, form) {
+		return
+	}
+	if !scanUpload(c, userID.(uint), form) {
+		return
+	}
+
+	archive, err := sessionarchive.Open(form.Path)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	defer archive.Close()
+	manifest := archive.Manifest
+
+	segments := make([]*sessionSegment, len(manifest.Segments))
+	for i, entry := range manifest.Segments {
+		segment := &sessionSegment{matchingScale: 5}
+		if entry.MatchingScale != nil {
+			segment.matchingScale = *entry.MatchingScale
+		}
+		if len(entry.Metadata) > 0 && string(entry.Metadata) != "null" {
+			segment.metadata, err = models.ParseRecordingMetadata(entry.Metadata)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("Segment %s: %v", entry.File, err)})
+				return
+			}
+		}
+		segments[i] = segment
+	}
+
+	// Unpacked segments are removed again unless the session gets stored
+	stored := false
+	defer func() {
+		if stored {
+			return
+		}
+		for _, segment := range segments {
+			if segment.form != nil {
+				segment.form.remove()
+			}
+		}
+	}()
+
+	var total int64
+	for i, f := range archive.Files {
+		segment, err := unpackSegment(userID.(uint), f, maxSize-total)
+		var unsupported *unsupportedSegmentError
+		switch {
+		case errors.As(err, &unsupported):
+			c.JSON(http.StatusUnsupportedMediaType, ErrorResponse{Error: fmt.Sprintf("Unsupported segment %s: %v", f.Name, unsupported.err)})
+			return
+		case errors.Is(err, errSessionTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: fmt.Sprintf("Session unpacks to more than %dMB", maxSize>>20)})
+			return
+		case err != nil:
+			log.Printf("Failed to unpack segment %s of a session of user %d: %v", f.Name, userID.(uint), err)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("Segment %s cannot be unpacked", f.Name)})
+			return
+		}
+		segments[i].form = segment
+		total += segment.Size
+	}
+
+	// Check every segment before any is queued, so all that is wrong with
+	// the session is reported at once
+	var invalid []SegmentIssues
+	for i, segment := range segments {
+		issues, err := recordingIssues(segment.form)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to read segment " + archive.Files[i].Name})
+			return
+		}
+		if issues != nil {
+			invalid = append(invalid, SegmentIssues{File: archive.Files[i].Name, Issues: issues.Issues})
+		}
+	}
+	if len(invalid) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, SessionValidationResponse{Error: "Some segments are not valid EEG data", Segments: invalid})
+		return
+	}
+
+	// Every segment counts as an upload; the session is refused unless all fit
+	limits, err := plans.LimitsForUser(database.DB, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to load plan limits"})
+		return
+	}
+	usage, err := plans.CurrentUsage(database.DB, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to load usage"})
+		return
+	}
+	pending := *usage
+	untranslated := 0
+	for _, segment := range segments {
+		if err := plans.CheckUpload(limits, &pending, segment.form.Size); err != nil {
+			var quotaErr *plans.QuotaError
+			if !errors.As(err, &quotaErr) {
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to check quotas"})
+				return
+			}
+			respondQuotaError(c, limits, usage, quotaErr)
+			return
+		}
+		segment.translate = plans.CanTranslate(limits, &pending)
+		if segment.translate {
+			pending.Translations++
+		} else {
+			untranslated++
+		}
+		pending.Uploads++
+		pending.UploadBytes += segment.form.Size
+		pending.StorageBytes += segment.form.Size
+	}
+
+	pool := ingest.Default()
+	backedUp := untranslated < len(segments) && settings.UploadQueueLimit > 0 && pool.Depth() >= settings.UploadQueueLimit
+	if backedUp && limits.Plan == config.PlanFree {
+		respondLimitError(c, http.StatusTooManyRequests, plans.QueueFull(limits, pool.Depth(), settings.UploadQueueLimit, pool.ETA()))
+		return
+	}
+
+	session := &models.RecordingSession{
+		UserID:       userID.(uint),
+		Title:        manifest.Title,
+		RecordedAt:   manifest.RecordedAt,
+		SegmentCount: len(segments),
+	}
+	if session.Title == "" {
+		session.Title = form.Filename
+	}
+	uploadJobs := make([]*models.UploadJob, len(segments))
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := session.Create(tx); err != nil {
+			return err
+		}
+		for i, segment := range segments {
+			file, err := models.CreateSingleFile(userID.(uint), segment.form.Filename, segment.form.Path, "")
+			if err != nil {
+				return err
+			}
+			file.ContentHash = segment.form.ContentHash
+			file.SessionID = &session.ID
+			file.SessionIndex = i
+			if err := file.Save(tx); err != nil {
+				return err
+			}
+			uploadJobs[i] = &models.UploadJob{
+				UserID:        userID.(uint),
+				FileID:        file.ID,
+				MatchingScale: segment.matchingScale,
+				Metadata:      segment.metadata,
+				Translate:     segment.translate,
+			}
+			if err := uploadJobs[i].Create(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to store session of user %d: %v", userID.(uint), err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to queue session for processing"})
+		return
+	}
+	stored = true
+
+	results := make([]SessionSegmentResult, len(segments))
+	for i, job := range uploadJobs {
+		models.PublishFileEvent(database.DB, job, models.FileEventUploaded)
+		if jobs.Embedded() {
+			go jobs.StartUploadJob(context.WithoutCancel(c.Request.Context()), database.DB, pool, job, settings.MLServiceAddress)
+		}
+		results[i] = SessionSegmentResult{File: archive.Files[i].Name, FileID: job.FileID, JobID: job.ID}
+	}
+
+	var warnings []string
+	if untranslated > 0 {
+		warnings = append(warnings, fmt.Sprintf("Monthly translation quota exceeded; %d of %d segments will be stored without a translation", untranslated, len(segments)))
+	}
+
+	c.JSON(http.StatusAccepted, SessionUploadResponse{
+		Message:    "Session stored; processing queued",
+		SessionID:  session.ID,
+		StatusURL:  fmt.Sprintf("/sessions/%d", session.ID),
+		Segments:   results,
+		Warnings:   warnings,
+		ETASeconds: int(math.Ceil(pool.ETA().Seconds())),
+	})
+}
+
+// errSessionTooLarge is returned when the segments unpack to more than the upload size limit
+var errSessionTooLarge = errors.New("session is too large")
+
+// unsupportedSegmentError is returned for a segment that is not an accepted upload format
+type unsupportedSegmentError struct {
+	err error
+}
+
+func (e *unsupportedSegmentError) Error() string {
+	return e.err.Error()
+}
+
+// unpackSegment stores a segment of a session archive under UploadDir, as
+// an upload of at most maxSize bytes
+func unpackSegment(userID uint, f *zip.File, maxSize int64) (*uploadForm, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	content := bufio.NewReaderSize(rc, filetype.SniffLen)
+	head, err := content.Peek(filetype.SniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	format, err := recordingUpload(head, f.Name, "")
+	if err != nil {
+		return nil, &unsupportedSegmentError{err: err}
+	}
+
+	if err := os.MkdirAll(UploadDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	segment := &uploadForm{
+		Filename: path.Base(f.Name),
+		Format:   format,
+		Path:     filepath.Join(UploadDir, fmt.Sprintf("%d-%s%s", userID, uuid.New().String(), path.Ext(f.Name))),
+	}
+	// Declared sizes cannot be trusted; one byte past the limit gives it away
+	if err := segment.store(io.LimitReader(content, maxSize+1)); err != nil {
+		segment.remove()
+		return nil, err
+	}
+	if segment.Size > maxSize {
+		segment.remove()
+		return nil, errSessionTooLarge
+	}
+	return segment, nil
+}
+
+// GetSession returns a recording session with its segments
+// @Summary Get a recording session
+// @Description Returns a recording session the authenticated user uploaded with POST /upload/session, with its segments' files in session order. Each file carries its status and processing state as in GET /files and the report made from it once there is one.
+// @Tags files
+// @Produce json
+// @Param id path int true "Session ID"
+// @Success 200 {object} models.RecordingSession "Session"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Session not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /sessions/{id} [get]
+func GetSession(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid session ID"})
+		return
+	}
+
+	session, err := models.FindUserSession(database.DB, uint(sessionID), userID.(uint))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Session not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch session"})
+		return
+	}
+	if err := models.LoadFileReports(database.DB, session.Files); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch session status"})
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
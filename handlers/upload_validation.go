@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services"
+	"github.com/gin-gonic/gin"
+)
+
+// EEGValidationResponse lists what is wrong with an uploaded recording
+type EEGValidationResponse struct {
+	Error  string              `json:"error" example:"The recording is not valid EEG data"`
+	Issues []services.EEGIssue `json:"issues"`
+}
+
+// validateRecording checks that a JSON upload decodes to EEG data the ML
+// service can translate. Other formats are left to the upload job. It
+// writes the error response and returns false when the recording is invalid.
+func validateRecording(c *gin.Context, form *uploadForm) bool {
+	invalid, err := recordingIssues(form)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to read file"})
+		return false
+	}
+	if invalid == nil {
+		return true
+	}
+	c.JSON(http.StatusUnprocessableEntity, EEGValidationResponse{Error: "The recording is not valid EEG data", Issues: invalid.Issues})
+	return false
+}
+
+// recordingIssues returns what is wrong with a stored JSON recording, or nil
+// when it is sound or not JSON
+func recordingIssues(form *uploadForm) (*services.EEGValidationError, error) {
+	if form.Format != config.UploadFormatJSON {
+		return nil, nil
+	}
+
+	f, err := os.Open(form.Path)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := services.DecodeEEGPayload(f)
+	f.Close()
+	if err != nil {
+		return services.InvalidEEGPayload(err), nil
+	}
+	return services.ValidateEEGData(payload), nil
+}
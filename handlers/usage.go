@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/plans"
+	"github.com/gin-gonic/gin"
+)
+
+// UsageResponse represents the authenticated user's plan limits and current usage.
+// A limit of -1 means unlimited.
+type UsageResponse struct {
+	Limits plans.Limits `json:"limits"`
+	Usage  plans.Usage  `json:"usage"`
+	// Bytes that can still be stored before uploads are refused; -1 when unlimited
+	StorageRemainingBytes int64 `json:"storage_remaining_bytes" example:"1047527424"`
+}
+
+// GetUsage returns the authenticated user's quotas and usage for the current month
+// @Summary Get usage and quotas
+// @Description Returns the plan limits (uploads, translations, storage) and the usage counted against them this month. Storage counts the user's reports and the uploaded files not yet made into a report, and storage_remaining_bytes is what can still be uploaded before uploads are refused with 402.
+// @Tags usage
+// @Produce json
+// @Success 200 {object} UsageResponse "Limits and usage"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /usage [get]
+func GetUsage(c *gin.Context) {
+	// Get authenticated user ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	user, err := models.FindUserByID(database.DB, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch user"})
+		return
+	}
+
+	limits, err := plans.ResolveLimits(database.DB, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to load plan limits"})
+		return
+	}
+
+	usage, err := plans.CurrentUsage(database.DB, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to load usage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, UsageResponse{
+		Limits:                limits,
+		Usage:                 *usage,
+		StorageRemainingBytes: plans.StorageRemaining(limits, usage),
+	})
+}
+
+// respondQuotaError writes the limit error of a refused upload: 413 when the
+// file is larger than the plan allows, 402 when it would exceed a quota
+func respondQuotaError(c *gin.Context, limits plans.Limits, usage *plans.Usage, err *plans.QuotaError) {
+	status := http.StatusPaymentRequired
+	if err.Resource == plans.ResourceFileSize {
+		status = http.StatusRequestEntityTooLarge
+	}
+	respondLimitError(c, status, plans.QuotaExceeded(limits, usage, err))
+}
+
+// respondLimitError writes a 402, 413 or 429 limit error with its Retry-After header
+func respondLimitError(c *gin.Context, status int, body plans.LimitError) {
+	if body.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(body.RetryAfter))
+	}
+	c.JSON(status, body)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/ThinkInkTeam/thinkink-core-backend/database"
 	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -29,6 +30,8 @@ type UpdateUserRequest struct {
 	City        string                 `json:"city" example:"New York"`
 	Country     string                 `json:"country" example:"US"`
 	PostalCode  string                 `json:"postal_code" example:"10001"`
+	Timezone    string                 `json:"timezone" example:"America/New_York"`
+	Locale      string                 `json:"locale" example:"en-US"`
 	PaymentInfo map[string]interface{} `json:"payment_info" swaggertype:"object,string" example:"{\"card_type\":\"visa\"}"`
 }
 
@@ -120,7 +123,7 @@ func UpdateUser(c *gin.Context) {
 
 	// Parse update request
 	var req UpdateUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -154,6 +157,21 @@ func UpdateUser(c *gin.Context) {
 	if req.PostalCode != "" {
 		user.PostalCode = req.PostalCode
 	}
+	if req.Timezone != "" {
+		if err := utils.ValidateTimezone(req.Timezone); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		user.Timezone = req.Timezone
+	}
+	if req.Locale != "" {
+		locale, err := utils.ValidateLocale(req.Locale)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		user.Locale = locale
+	}
 	if req.PaymentInfo != nil {
 		// // Convert map to JSON
 		// paymentInfoJSON, err := database.DB.Dialector.Translate(req.PaymentInfo)
@@ -178,3 +196,52 @@ func UpdateUser(c *gin.Context) {
 		User:    *user,
 	})
 }
+
+// DeactivateUser handles deactivating the authenticated user's account
+// @Summary Deactivate user account
+// @Description Deactivates the account (must be own profile). Data is preserved, but sign-in and all existing tokens stop working until an admin reactivates it.
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} MessageResponse "Account deactivated"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Trying to deactivate other user's account"
+// @Failure 404 {object} ErrorResponse "Not Found - User not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /user/{id}/deactivate [post]
+func DeactivateUser(c *gin.Context) {
+	// Get authenticated user ID
+	authenticatedUserID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	// Get requested user ID from path
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	// Check if user is deactivating their own account
+	if authenticatedUserID.(uint) != uint(userID) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "You can only deactivate your own account"})
+		return
+	}
+
+	user, err := models.FindUserByID(database.DB, uint(userID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		return
+	}
+
+	if err := user.Deactivate(database.DB); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to deactivate account"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Account deactivated"})
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/userimport"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize bounds the CSV accepted by the bulk import
+const maxImportFileSize = 5 << 20
+
+// ImportUsers creates user accounts from a CSV file
+// @Summary Bulk import users
+// @Description Creates accounts from a CSV with a header row. Required columns: name, email, date_of_birth (YYYY-MM-DD). Optional: mobile, country_code, address, city, country, postal_code, timezone, locale. Each user is emailed a single-use link, valid for 7 days, to choose their password; the account cannot use the API until they do. Rows are created in transactions of 100; failing rows are skipped and reported (admin only)
+// @Tags admin
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV file"
+// @Param organization_id formData int false "Organization to assign the imported users to"
+// @Success 200 {object} userimport.Result "Import result with per-row errors"
+// @Failure 400 {object} ErrorResponse "Bad Request - Missing or malformed CSV"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization not found"
+// @Failure 409 {object} ErrorResponse "Conflict - Not enough free seats in the organization for the imported users"
+// @Security BearerAuth
+// @Router /admin/users/import [post]
+func ImportUsers(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "No CSV file uploaded (max 5MB)"})
+		return
+	}
+
+	opts := userimport.Options{
+		SetPasswordURL: utils.GetEnvWithDefault("PASSWORD_RESET_URL", "http://localhost:3000/reset-password?token="),
+	}
+	if orgParam := c.PostForm("organization_id"); orgParam != "" {
+		orgID, err := strconv.ParseUint(orgParam, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid organization ID"})
+			return
+		}
+		org, err := models.FindOrganizationByID(database.DB, uint(orgID))
+		if err != nil {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Organization not found"})
+			return
+		}
+		opts.OrganizationID = &org.ID
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to read file"})
+		return
+	}
+	defer file.Close()
+
+	rows, rowErrors, err := userimport.ParseCSV(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Every imported member takes one of the organization's seats
+	if opts.OrganizationID != nil && !checkOrgSeats(c, *opts.OrganizationID, len(rows)) {
+		return
+	}
+
+	result := userimport.Import(database.DB, rows, opts)
+	result.TotalRows += len(rowErrors)
+	result.Errors = append(rowErrors, result.Errors...)
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/webhooks"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateWebhookRequest represents the request body for registering a webhook
+type CreateWebhookRequest struct {
+	URL string `json:"url" binding:"required,max=2048" example:"https://example.com/thinkink/events"`
+	// Events to receive: report.created, report.updated, translation.completed
+	Events      []string `json:"events" binding:"required,min=1,max=3" example:"report.created,translation.completed"`
+	Description string   `json:"description" binding:"max=200" example:"Clinic EHR sync"`
+}
+
+// CreateWebhookResponse carries a newly registered webhook and its signing
+// secret, shown only once
+type CreateWebhookResponse struct {
+	Webhook models.WebhookSubscription `json:"webhook"`
+	Secret  string                     `json:"secret" example:"whsec_3q2x..."`
+}
+
+// WebhooksResponse represents the user's webhooks
+type WebhooksResponse struct {
+	Webhooks []models.WebhookSubscription `json:"webhooks"`
+}
+
+// WebhookDeliveriesResponse represents a page of a webhook's delivery log
+type WebhookDeliveriesResponse struct {
+	Deliveries []models.WebhookDelivery `json:"deliveries"`
+	Pagination Pagination               `json:"pagination"`
+}
+
+// findOwnedWebhook loads the webhook named by the id path parameter if it
+// belongs to the authenticated user, writing the error response otherwise
+func findOwnedWebhook(c *gin.Context) (*models.WebhookSubscription, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return nil, false
+	}
+
+	webhookID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid webhook ID"})
+		return nil, false
+	}
+
+	webhook, err := models.FindWebhookSubscription(database.DB, userID.(uint), uint(webhookID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Webhook not found"})
+		return nil, false
+	}
+	return webhook, true
+}
+
+// CreateWebhook registers a webhook
+// @Summary Register a webhook
+// @Description Registers a URL that receives a signed JSON POST whenever one of the authenticated user's reports is created (report.created), changed (report.updated) or finishes translating (translation.completed). Payloads carry the report's ID and metadata but not its text. Each request has an X-ThinkInk-Signature header of the form t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>"> keyed with the webhook's secret, which is returned only once. Failed deliveries are retried with exponential backoff up to 8 times. At most 10 webhooks per user.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body CreateWebhookRequest true "Webhook details"
+// @Success 201 {object} CreateWebhookResponse "Registered webhook and secret"
+// @Failure 400 {object} ErrorResponse "Bad Request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /webhooks [post]
+func CreateWebhook(c *gin.Context) {
+	// Get authenticated user ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	var req CreateWebhookRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := webhooks.ValidateURL(req.URL); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	webhook, err := models.CreateWebhookSubscription(database.DB, userID.(uint), req.URL, req.Events, req.Description)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateWebhookResponse{Webhook: *webhook, Secret: webhook.Secret})
+}
+
+// GetWebhooks lists the user's webhooks
+// @Summary List webhooks
+// @Description Lists the authenticated user's webhooks, oldest first, without their secrets
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} WebhooksResponse "Webhooks"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /webhooks [get]
+func GetWebhooks(c *gin.Context) {
+	// Get authenticated user ID
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	subscriptions, err := models.FindWebhookSubscriptions(database.DB, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, WebhooksResponse{Webhooks: subscriptions})
+}
+
+// DeleteWebhook removes a webhook
+// @Summary Delete a webhook
+// @Description Removes one of the authenticated user's webhooks together with its delivery log; queued deliveries are dropped
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} MessageResponse "Webhook deleted"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Webhook not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /webhooks/{id} [delete]
+func DeleteWebhook(c *gin.Context) {
+	webhook, ok := findOwnedWebhook(c)
+	if !ok {
+		return
+	}
+
+	if err := webhook.Delete(database.DB); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Webhook deleted"})
+}
+
+// GetWebhookDeliveries lists a webhook's delivery log
+// @Summary List a webhook's deliveries
+// @Description Lists the deliveries of one of the authenticated user's webhooks, newest first, with their payload, status (pending, delivered or failed), number of attempts, the last HTTP status received and the last error
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param limit query int false "Page size (max 100)" default(50)
+// @Param offset query int false "Number of deliveries to skip (at most 10000); ignored when cursor is set" default(0)
+// @Param cursor query string false "Cursor from the previous page's pagination.next_cursor"
+// @Success 200 {object} WebhookDeliveriesResponse "Deliveries"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID or paging"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Webhook not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /webhooks/{id}/deliveries [get]
+func GetWebhookDeliveries(c *gin.Context) {
+	webhook, ok := findOwnedWebhook(c)
+	if !ok {
+		return
+	}
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	deliveries, info, err := models.FindWebhookDeliveriesPage(database.DB, webhook.ID, page)
+	if err != nil {
+		if err.Error() == "invalid cursor" {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid cursor"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, WebhookDeliveriesResponse{Deliveries: deliveries, Pagination: newPagination(page, info)})
+}
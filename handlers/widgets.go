@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateWidgetRequest represents the request body for issuing an embeddable widget
+type CreateWidgetRequest struct {
+	Name string `json:"name" binding:"required,max=100" example:"Patient portal"`
+	// Origins the widget is embedded from, as sent in the browser's Origin header
+	AllowedOrigins []string `json:"allowed_origins" binding:"required,min=1,max=10" example:"https://portal.clinic.example"`
+}
+
+// CreateWidgetResponse carries a newly issued widget and its token, shown only once
+type CreateWidgetResponse struct {
+	Widget models.EmbedWidget `json:"widget"`
+	Token  string             `json:"token" example:"tik_wgt_3q2x..."`
+}
+
+// WidgetsResponse represents an organization's widgets
+type WidgetsResponse struct {
+	Widgets []models.EmbedWidget `json:"widgets"`
+}
+
+// CreateOrgWidget issues an embeddable widget for an organization
+// @Summary Issue an embeddable widget
+// @Description Issues a widget token an organization's patient portal sends in X-Widget-Token to show read-only shared reports of its members from the allowed origins. Cross-origin access is granted to GET /shared/{token} only. The token is returned only once; only its hash is stored (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param widget body CreateWidgetRequest true "Widget details"
+// @Success 201 {object} CreateWidgetResponse "Issued widget and token"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid name or origins"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/orgs/{id}/widgets [post]
+func CreateOrgWidget(c *gin.Context) {
+	org, ok := organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	var req CreateWidgetRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	for _, origin := range req.AllowedOrigins {
+		if _, err := models.NormalizeOrigin(origin); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	adminID := c.MustGet("userID").(uint)
+	widget, token, err := models.CreateEmbedWidget(database.DB, org.ID, req.Name, req.AllowedOrigins, adminID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create widget"})
+		return
+	}
+
+	auditOrgAction(c, adminID, org.ID, "organization.widget_create", widgetResource(widget.ID), gin.H{"allowed_origins": widget.AllowedOrigins})
+	c.JSON(http.StatusCreated, CreateWidgetResponse{Widget: *widget, Token: token})
+}
+
+// GetOrgWidgets lists an organization's embeddable widgets
+// @Summary List an organization's widgets
+// @Description Lists an organization's embeddable widgets, including revoked ones, without their tokens (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} WidgetsResponse "Widgets"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/orgs/{id}/widgets [get]
+func GetOrgWidgets(c *gin.Context) {
+	org, ok := organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	widgets, err := models.FindEmbedWidgets(database.DB, org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch widgets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, WidgetsResponse{Widgets: widgets})
+}
+
+// RevokeOrgWidget revokes an embeddable widget
+// @Summary Revoke a widget
+// @Description Revokes a widget so its token stops working and its origins lose cross-origin access immediately (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param widgetId path int true "Widget ID"
+// @Success 200 {object} MessageResponse "Widget revoked"
+// @Failure 400 {object} ErrorResponse "Bad Request - Invalid ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Not Found - Organization or widget not found"
+// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Security BearerAuth
+// @Router /admin/orgs/{id}/widgets/{widgetId} [delete]
+func RevokeOrgWidget(c *gin.Context) {
+	org, ok := organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	widgetID, err := strconv.ParseUint(c.Param("widgetId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid widget ID"})
+		return
+	}
+
+	if err := models.RevokeEmbedWidget(database.DB, org.ID, uint(widgetID)); err != nil {
+		if errors.Is(err, models.ErrWidgetNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Widget not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to revoke widget"})
+		return
+	}
+
+	auditOrgAction(c, c.MustGet("userID").(uint), org.ID, "organization.widget_revoke", widgetResource(uint(widgetID)), nil)
+	c.JSON(http.StatusOK, MessageResponse{Message: "Widget revoked"})
+}
+
+// widgetResource names a widget as the resource of an audit entry
+func widgetResource(widgetID uint) string {
+	return fmt.Sprintf("widget:%d", widgetID)
+}
@@ -87,8 +87,40 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Set user ID in context for later use in handlers
-		c.Set("userID", uint(userID.(float64)))
+		// Device tokens may only reach the ingestion endpoints
+		audience := models.TokenAudience(claims)
+		if !models.AudienceAllowed(audience, c.Request.Method+" "+c.FullPath()) {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("Tokens issued to a %s cannot call this endpoint", audience)})
+			c.Abort()
+			return
+		}
+
+		// Reject tokens of deactivated or deleted accounts
+		user, err := models.FindUserByID(database.DB, uint(userID.(float64)))
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Account is deactivated or does not exist"})
+			c.Abort()
+			return
+		}
+
+		// Imported accounts must choose their own password first
+		if user.MustChangePassword {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Choose a new password with the link emailed to you, or POST /forgot-password, before using the API"})
+			c.Abort()
+			return
+		}
+
+		// Reject high-value tokens replayed from another IP
+		if !checkTokenReplay(c, user, claims, tokenString) {
+			return
+		}
+
+		// Set user ID and token audience in context for later use in handlers
+		c.Set("userID", user.ID)
+		c.Set("tokenAudience", audience)
+		if deviceID, ok := claims["deviceID"].(string); ok && audience == models.TokenAudienceDevice {
+			c.Set("deviceID", deviceID)
+		}
 		c.Next()
 	}
 }
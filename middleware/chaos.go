@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/chaos"
+	"github.com/gin-gonic/gin"
+)
+
+// Chaos injects faults into requests when chaos testing is enabled: the
+// configured latency and error rates, plus any asked for by the request in
+// X-Chaos-Latency (a duration), X-Chaos-Error (a status to fail with),
+// X-Chaos-Error-Rate (the share of such requests to fail) and X-Chaos-ML:
+// fail (translations fail as if the ML service had). Health probes are left
+// alone so orchestrators keep an accurate view.
+func Chaos() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := chaos.Current()
+		if !cfg.Enabled || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+		route := c.Request.Method + " " + c.FullPath()
+		if route == "GET /readyz" || route == "GET /status" {
+			c.Next()
+			return
+		}
+
+		latency := cfg.Latency
+		if value := c.GetHeader("X-Chaos-Latency"); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil || d < 0 || d > chaos.MaxLatency {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "X-Chaos-Latency must be a duration between 0s and " + chaos.MaxLatency.String()})
+				c.Abort()
+				return
+			}
+			latency = d
+		}
+
+		status := cfg.ErrorStatus
+		rate, ok := cfg.RouteErrorRates[route]
+		if !ok {
+			rate = cfg.ErrorRate
+		}
+		if value := c.GetHeader("X-Chaos-Error"); value != "" {
+			s, err := chaos.ParseStatus(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "X-Chaos-Error " + err.Error()})
+				c.Abort()
+				return
+			}
+			status, rate = s, 1
+		}
+		if value := c.GetHeader("X-Chaos-Error-Rate"); value != "" {
+			r, err := chaos.ParseRate(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "X-Chaos-Error-Rate " + err.Error()})
+				c.Abort()
+				return
+			}
+			rate = r
+		}
+
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-c.Request.Context().Done():
+				c.Abort()
+				return
+			}
+			c.Header("X-Chaos-Latency", latency.String())
+		}
+
+		if chaos.Roll(rate) {
+			log.Printf("Chaos: failing %s with %d", route, status)
+			if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+				c.Header("Retry-After", "1")
+			}
+			c.Header("X-Chaos-Fault", strconv.Itoa(status))
+			c.JSON(status, gin.H{"error": "Injected fault: " + http.StatusText(status)})
+			c.Abort()
+			return
+		}
+
+		if c.GetHeader("X-Chaos-ML") == "fail" {
+			c.Request = c.Request.WithContext(chaos.WithFaults(c.Request.Context(), chaos.Faults{FailML: true}))
+		}
+		c.Next()
+	}
+}
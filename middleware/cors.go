@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ParseAllowedOrigins reads CORS_ALLOWED_ORIGINS: "*" for any origin, or a
+// comma-separated list of origins such as https://app.thinkink.io
+func ParseAllowedOrigins(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "*" {
+		return []string{"*"}, nil
+	}
+	var origins []string
+	for _, part := range strings.Split(value, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		origin, err := models.NormalizeOrigin(part)
+		if err != nil {
+			return nil, fmt.Errorf("CORS_ALLOWED_ORIGINS: %w", err)
+		}
+		origins = append(origins, origin)
+	}
+	if len(origins) == 0 {
+		return nil, fmt.Errorf("CORS_ALLOWED_ORIGINS must be * or a list of origins")
+	}
+	return origins, nil
+}
+
+// AllowedOriginsFromEnv reads CORS_ALLOWED_ORIGINS. Outside production it
+// defaults to any origin; production requires an explicit list of origins.
+func AllowedOriginsFromEnv() ([]string, error) {
+	value := strings.TrimSpace(utils.GetEnvWithDefault("CORS_ALLOWED_ORIGINS", ""))
+	if utils.GetEnvWithDefault("APP_ENV", "development") == "production" {
+		if value == "" || value == "*" {
+			return nil, fmt.Errorf("CORS_ALLOWED_ORIGINS must list the allowed origins in production")
+		}
+	} else if value == "" {
+		value = "*"
+	}
+	return ParseAllowedOrigins(value)
+}
+
+// originAllower reports whether an Origin is one of the allowed origins
+type originAllower struct {
+	any bool
+	set map[string]bool
+}
+
+func newOriginAllower(allowed []string) originAllower {
+	a := originAllower{any: len(allowed) == 1 && allowed[0] == "*", set: make(map[string]bool, len(allowed))}
+	for _, origin := range allowed {
+		a.set[origin] = true
+	}
+	return a
+}
+
+// allow sets the CORS headers for the allowed origins and reports whether
+// the origin is one of them
+func (a originAllower) allow(c *gin.Context, origin string) bool {
+	switch {
+	case a.any:
+		c.Header("Access-Control-Allow-Origin", "*")
+	case origin != "" && a.set[strings.ToLower(origin)]:
+		c.Header("Access-Control-Allow-Origin", origin)
+		c.Header("Vary", "Origin")
+	default:
+		return false
+	}
+	c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+	c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, X-Share-Password, X-Widget-Token, X-Chaos-Latency, X-Chaos-Error, X-Chaos-Error-Rate, X-Chaos-ML")
+	return true
+}
+
+// CORS lets browsers on the allowed origins call the API. Requests from other
+// origins get no CORS headers. Preflights of routes with their own OPTIONS
+// handler, such as the shared report endpoint for widgets, are left to the
+// route's own policy.
+func CORS(allowed []string) gin.HandlerFunc {
+	origins := newOriginAllower(allowed)
+
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodOptions && c.FullPath() != "" {
+			c.Next()
+			return
+		}
+		if !origins.allow(c, c.GetHeader("Origin")) {
+			c.Next()
+			return
+		}
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+// WidgetCORS lets organizations' embedded widgets read shared reports from
+// their portals. Preflights are answered for the allowed origins of the API
+// and for origins any active widget lists. Requests carrying a widget token in
+// X-Widget-Token must come from an Origin its widget lists. It sets
+// embedWidget in the context.
+func WidgetCORS(allowed []string) gin.HandlerFunc {
+	origins := newOriginAllower(allowed)
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+
+		if c.Request.Method == http.MethodOptions {
+			if origins.allow(c, origin) {
+				c.AbortWithStatus(http.StatusNoContent)
+				return
+			}
+			allowed, err := models.EmbedWidgetOriginAllowed(database.DB, origin)
+			if err != nil {
+				log.Printf("Failed to check widget origin %q: %v", origin, err)
+			}
+			if allowed {
+				c.Header("Access-Control-Allow-Origin", origin)
+				c.Header("Access-Control-Allow-Methods", "GET, OPTIONS")
+				c.Header("Access-Control-Allow-Headers", "X-Widget-Token, X-Share-Password")
+				c.Header("Access-Control-Max-Age", "600")
+			}
+			c.Header("Vary", "Origin")
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		token := c.GetHeader("X-Widget-Token")
+		if token == "" {
+			c.Next()
+			return
+		}
+		widget, err := models.FindEmbedWidgetByToken(database.DB, token)
+		if err != nil || origin == "" || !widget.AllowsOrigin(origin) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid widget token for this origin"})
+			c.Abort()
+			return
+		}
+		if err := widget.MarkUsed(database.DB); err != nil {
+			log.Printf("Failed to record use of widget %d: %v", widget.ID, err)
+		}
+
+		if c.Writer.Header().Get("Access-Control-Allow-Origin") == "" {
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Vary", "Origin")
+		}
+		c.Set("embedWidget", widget)
+		c.Next()
+	}
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StrictJSONKey is set in the context when the route rejects unknown JSON fields
+const StrictJSONKey = "strictJSON"
+
+// CheckedJSONKey is set in the context once the body passed the limits;
+// handlers decode only such bodies, so other content types cannot bypass them
+const CheckedJSONKey = "checkedJSON"
+
+// JSONLimitOptions bounds what a JSON request body may look like
+type JSONLimitOptions struct {
+	MaxBytes              int64
+	MaxDepth              int
+	DisallowUnknownFields bool
+}
+
+// DefaultJSONLimitOptions returns the limits applied to routes without an override
+func DefaultJSONLimitOptions() JSONLimitOptions {
+	return JSONLimitOptions{
+		MaxBytes: 1 << 20,
+		MaxDepth: 32,
+	}
+}
+
+// JSONLimits enforces body size and nesting depth on JSON requests before
+// handlers bind them. Overrides are keyed by "METHOD /route/pattern" as
+// registered with gin, e.g. "POST /signup".
+func JSONLimits(defaults JSONLimitOptions, overrides map[string]JSONLimitOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || !isJSONRequest(c.Request) {
+			c.Next()
+			return
+		}
+
+		opts := defaults
+		if override, ok := overrides[c.Request.Method+" "+c.FullPath()]; ok {
+			opts = override
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, opts.MaxBytes+1))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Error reading request body"})
+			c.Abort()
+			return
+		}
+		if int64(len(body)) > opts.MaxBytes {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Request body too large (max %d bytes)", opts.MaxBytes)})
+			c.Abort()
+			return
+		}
+
+		if err := checkJSONDepth(body, opts.MaxDepth); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Set(CheckedJSONKey, true)
+		if opts.DisallowUnknownFields {
+			c.Set(StrictJSONKey, true)
+		}
+		c.Next()
+	}
+}
+
+// isJSONRequest reports whether the request declares a JSON body
+func isJSONRequest(r *http.Request) bool {
+	return strings.HasPrefix(strings.ToLower(r.Header.Get("Content-Type")), "application/json")
+}
+
+// checkJSONDepth walks the token stream and fails once nesting exceeds maxDepth
+func checkJSONDepth(body []byte, maxDepth int) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Invalid JSON: %v", err)
+		}
+
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+			if depth > maxDepth {
+				return fmt.Errorf("JSON nesting too deep (max %d levels)", maxDepth)
+			}
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+}
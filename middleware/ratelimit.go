@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/plans"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit enforces the per-user request rate from the user's plan or their
+// organization's custom rate plan. It must run after AuthMiddleware.
+func RateLimit() gin.HandlerFunc {
+	limiter := ratelimit.NewLimiter()
+
+	return func(c *gin.Context) {
+		userID, exists := c.Get("userID")
+		if !exists {
+			c.Next()
+			return
+		}
+
+		limits, err := plans.LimitsForUser(database.DB, userID.(uint))
+		if err != nil {
+			// Fail open: a lookup problem should not take the API down
+			c.Next()
+			return
+		}
+
+		allowed, remaining, wait := limiter.Allow(strconv.FormatUint(uint64(userID.(uint)), 10), limits.RequestsPerMinute)
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limits.RequestsPerMinute))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if !allowed {
+			body := plans.RateLimited(limits, wait)
+			c.Header("X-RateLimit-Reset", strconv.FormatInt(body.Reset.Unix(), 10))
+			c.Header("Retry-After", strconv.Itoa(body.RetryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, body)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenReplayFlag enables replay tracking for high-value tokens (FEATURE_FLAGS)
+const TokenReplayFlag = "token_replay_protection"
+
+// TokenReplayWindow is how recently another IP must have used a token for the
+// current use to count as a concurrent replay
+const TokenReplayWindow = 2 * time.Minute
+
+// checkTokenReplay tracks where high-value tokens are used from and rejects a
+// token presented from a new IP while another IP is still actively using it.
+// The token is revoked on detection. It writes the error response and returns
+// false when the request must be rejected.
+func checkTokenReplay(c *gin.Context, user *models.User, claims jwt.MapClaims, tokenString string) bool {
+	if !config.Current().Enabled(TokenReplayFlag) || !user.IsAdmin() {
+		return true
+	}
+
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token must be reissued, please sign in again"})
+		c.Abort()
+		return false
+	}
+
+	ip := c.ClientIP()
+	others, err := models.CountOtherTokenIPs(database.DB, jti, ip, time.Now().Add(-TokenReplayWindow))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication error"})
+		c.Abort()
+		return false
+	}
+
+	if others > 0 {
+		log.Printf("Token replay detected for user %d (jti %s) from %s; revoking token", user.ID, jti, ip)
+		expiresAt := time.Now().Add(24 * time.Hour)
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			expiresAt = exp.Time
+		}
+		if err := models.AddToBlacklist(database.DB, tokenString, expiresAt); err != nil {
+			log.Printf("Failed to revoke replayed token: %v", err)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token used from multiple locations and has been revoked"})
+		c.Abort()
+		return false
+	}
+
+	if err := models.RecordTokenUse(database.DB, jti, user.ID, ip); err != nil {
+		log.Printf("Failed to record token use: %v", err)
+	}
+	return true
+}
+
+// checkServiceKeyReplay applies the same tracking to service keys, which are
+// expected to be used from one address: a key presented from a new IP while
+// another IP is still actively using it is revoked. It writes the error
+// response and returns false when the request must be rejected.
+func checkServiceKeyReplay(c *gin.Context, credential *models.ServiceCredential) bool {
+	if !config.Current().Enabled(TokenReplayFlag) {
+		return true
+	}
+
+	key := fmt.Sprintf("service:%d", credential.ID)
+	ip := c.ClientIP()
+	others, err := models.CountOtherTokenIPs(database.DB, key, ip, time.Now().Add(-TokenReplayWindow))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication error"})
+		c.Abort()
+		return false
+	}
+
+	if others > 0 {
+		log.Printf("Replay detected for service credential %d (%s) from %s; revoking it", credential.ID, credential.Name, ip)
+		if err := models.RevokeServiceCredential(database.DB, credential.ID); err != nil {
+			log.Printf("Failed to revoke replayed service credential: %v", err)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Service key used from multiple locations and has been revoked"})
+		c.Abort()
+		return false
+	}
+
+	if err := models.RecordTokenUse(database.DB, key, credential.CreatedBy, ip); err != nil {
+		log.Printf("Failed to record service key use: %v", err)
+	}
+	return true
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/database"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireServiceCredential only lets through requests carrying an active
+// service key with the given scope in the X-Service-Key header. Rejected
+// attempts are audited. It sets serviceCredential in the context.
+func RequireServiceCredential(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("X-Service-Key")
+		if key == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "X-Service-Key header is required"})
+			c.Abort()
+			return
+		}
+
+		credential, err := models.FindServiceCredentialByKey(database.DB, key)
+		if err != nil || credential.Scope != scope {
+			details := gin.H{"path": c.FullPath(), "scope": scope}
+			if auditErr := models.RecordAudit(database.DB, "unknown", scope+".denied", "denied", c.ClientIP(), details); auditErr != nil {
+				log.Printf("Failed to audit rejected service request: %v", auditErr)
+			}
+			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid service credential"})
+			c.Abort()
+			return
+		}
+
+		// Reject keys replayed from another IP
+		if !checkServiceKeyReplay(c, credential) {
+			return
+		}
+
+		if err := credential.MarkUsed(database.DB); err != nil {
+			log.Printf("Failed to record use of service credential %d: %v", credential.ID, err)
+		}
+
+		c.Set("serviceCredential", credential)
+		c.Next()
+	}
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// AuditLog records a sensitive action, such as a bulk data export
+type AuditLog struct {
+	ID        uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	Actor     string         `gorm:"type:varchar(100);not null;index" json:"actor" example:"service:3"`
+	Action    string         `gorm:"type:varchar(100);not null;index" json:"action" example:"ml_export.feedback"`
+	Outcome   string         `gorm:"type:varchar(20);not null" json:"outcome" example:"success"`
+	IP        string         `gorm:"type:varchar(45)" json:"ip"`
+	Details   datatypes.JSON `gorm:"type:json" json:"details,omitempty" swaggertype:"object"`
+	CreatedAt time.Time      `gorm:"index" json:"created_at"`
+	// Set for entries about an organization or its members, which its admins can view
+	OrganizationID *uint `gorm:"index" json:"organization_id,omitempty" example:"1"`
+	// What the action applied to, e.g. "report:12" or "organization:1"
+	Resource string `gorm:"type:varchar(100);index" json:"resource,omitempty" example:"report:12"`
+}
+
+// RecordAudit stores an audit entry; details are marshalled to JSON
+func RecordAudit(db *gorm.DB, actor, action, outcome, ip string, details interface{}) error {
+	return recordAudit(db, AuditLog{Actor: actor, Action: action, Outcome: outcome, IP: ip}, details)
+}
+
+// RecordOrgAudit stores an audit entry about an organization or one of its
+// members, visible in the organization's audit log
+func RecordOrgAudit(db *gorm.DB, orgID uint, actor, action, resource, outcome, ip string, details interface{}) error {
+	return recordAudit(db, AuditLog{OrganizationID: &orgID, Actor: actor, Action: action, Resource: resource, Outcome: outcome, IP: ip}, details)
+}
+
+func recordAudit(db *gorm.DB, entry AuditLog, details interface{}) error {
+	if details != nil {
+		encoded, err := json.Marshal(details)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit details: %w", err)
+		}
+		entry.Details = datatypes.JSON(encoded)
+	}
+	return db.Create(&entry).Error
+}
+
+// FindAuditLogs lists audit entries newest first, optionally for a single action
+func FindAuditLogs(db *gorm.DB, action string, limit int) ([]AuditLog, error) {
+	query := db.Order("created_at desc").Limit(limit)
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+	var logs []AuditLog
+	err := query.Find(&logs).Error
+	return logs, err
+}
+
+// AuditLogFilter narrows an audit log listing. Empty fields match everything;
+// From is inclusive and To exclusive.
+type AuditLogFilter struct {
+	Actor    string
+	Action   string
+	Resource string
+	From     *time.Time
+	To       *time.Time
+}
+
+// Apply adds the filter's conditions to query
+func (f AuditLogFilter) Apply(query *gorm.DB) *gorm.DB {
+	if f.Actor != "" {
+		query = query.Where("actor = ?", f.Actor)
+	}
+	if f.Action != "" {
+		query = query.Where("action = ?", f.Action)
+	}
+	if f.Resource != "" {
+		query = query.Where("resource = ?", f.Resource)
+	}
+	if f.From != nil {
+		query = query.Where("created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		query = query.Where("created_at < ?", *f.To)
+	}
+	return query
+}
+
+// FindOrgAuditLogsPage retrieves one page of an organization's audit entries
+// matching the filter, newest first. A cursor takes precedence over the offset.
+func FindOrgAuditLogsPage(db *gorm.DB, orgID uint, filter AuditLogFilter, page Page) ([]AuditLog, PageInfo, error) {
+	var info PageInfo
+
+	if err := filter.Apply(db.Model(&AuditLog{}).Where("organization_id = ?", orgID)).Count(&info.Total).Error; err != nil {
+		return nil, info, fmt.Errorf("database error: %w", err)
+	}
+
+	query := filter.Apply(db.Where("organization_id = ?", orgID))
+	if page.Cursor != "" {
+		var err error
+		if query, err = afterCursor(query, "created_at", "id", page.Cursor); err != nil {
+			return nil, info, err
+		}
+	} else if page.Offset > 0 {
+		query = query.Offset(page.Offset)
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	var logs []AuditLog
+	if err := query.Order("created_at desc, id desc").Limit(page.Limit + 1).Find(&logs).Error; err != nil {
+		return nil, info, fmt.Errorf("database error: %w", err)
+	}
+
+	if len(logs) > page.Limit {
+		logs = logs[:page.Limit]
+		info.HasMore = true
+		last := logs[len(logs)-1]
+		info.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return logs, info, nil
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCheckoutToken is returned for checkout tokens that are forged,
+// expired, already used or issued for another session
+var ErrInvalidCheckoutToken = fmt.Errorf("invalid or expired checkout token")
+
+// CheckoutToken identifies the user a checkout session was started for. Its
+// signed form travels in the session's metadata and is consumed once, by the
+// webhook for the session it was issued for.
+type CheckoutToken struct {
+	ID     uint   `gorm:"primaryKey;autoIncrement"`
+	UserID uint   `gorm:"not null;index"`
+	Nonce  string `gorm:"type:varchar(64);not null;uniqueIndex"`
+	// Checkout session the token was attached to
+	SessionID string    `gorm:"type:varchar(255);index"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// CreateCheckoutToken issues a token for a checkout session of the user that
+// is accepted until ttl has passed
+func CreateCheckoutToken(db *gorm.DB, userID uint, ttl time.Duration) (*CheckoutToken, error) {
+	nonce, err := utils.GenerateSecureToken(24)
+	if err != nil {
+		return nil, err
+	}
+	token := &CheckoutToken{
+		UserID:    userID,
+		Nonce:     nonce,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := db.Create(token).Error; err != nil {
+		return nil, fmt.Errorf("failed to create checkout token: %w", err)
+	}
+	return token, nil
+}
+
+// Signed returns the token as stored in checkout session metadata:
+// <user id>.<expiry unix>.<nonce>.<signature>
+func (t *CheckoutToken) Signed(secret []byte) string {
+	payload := strconv.FormatUint(uint64(t.UserID), 10) + "." + strconv.FormatInt(t.ExpiresAt.Unix(), 10) + "." + t.Nonce
+	return payload + "." + signCheckoutToken(secret, payload)
+}
+
+// AttachSession binds the token to the checkout session created with it
+func (t *CheckoutToken) AttachSession(db *gorm.DB, sessionID string) error {
+	t.SessionID = sessionID
+	return db.Model(t).Update("session_id", sessionID).Error
+}
+
+// ConsumeCheckoutToken verifies a signed token from the metadata of a
+// completed checkout session and returns the user it was issued for. Each
+// token is accepted for its own session only; a redelivered webhook for that
+// session is accepted again, even after the token expired, so retries stay
+// idempotent.
+func ConsumeCheckoutToken(db *gorm.DB, secret []byte, signed, sessionID string) (uint, error) {
+	parts := strings.Split(signed, ".")
+	if len(parts) != 4 {
+		return 0, ErrInvalidCheckoutToken
+	}
+	payload := parts[0] + "." + parts[1] + "." + parts[2]
+	if !hmac.Equal([]byte(parts[3]), []byte(signCheckoutToken(secret, payload))) {
+		return 0, ErrInvalidCheckoutToken
+	}
+	userID, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return 0, ErrInvalidCheckoutToken
+	}
+	if _, err := strconv.ParseInt(parts[1], 10, 64); err != nil {
+		return 0, ErrInvalidCheckoutToken
+	}
+
+	// Mark the token used if it is unused and unexpired, in one statement so
+	// concurrent deliveries cannot both consume it
+	now := time.Now()
+	result := db.Model(&CheckoutToken{}).
+		Where("nonce = ? AND user_id = ? AND session_id = ? AND used_at IS NULL AND expires_at > ?", parts[2], userID, sessionID, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return 0, fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return uint(userID), nil
+	}
+
+	// Already used, which a redelivery of the event for the same session may
+	// be at any time, or missing, for another session or expired
+	var token CheckoutToken
+	if err := db.Where("nonce = ? AND user_id = ?", parts[2], userID).First(&token).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, ErrInvalidCheckoutToken
+		}
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	if token.SessionID != sessionID || token.UsedAt == nil {
+		return 0, ErrInvalidCheckoutToken
+	}
+	return token.UserID, nil
+}
+
+// CleanupCheckoutTokens removes tokens that expired before the cutoff
+func CleanupCheckoutTokens(db *gorm.DB, cutoff time.Time) error {
+	return db.Where("expires_at < ?", cutoff).Delete(&CheckoutToken{}).Error
+}
+
+// signCheckoutToken returns the URL-safe HMAC-SHA256 signature of a checkout token payload
+func signCheckoutToken(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("checkout-token:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
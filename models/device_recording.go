@@ -0,0 +1,128 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Device recording states
+const (
+	DeviceRecordingProcessing = "processing"
+	DeviceRecordingCompleted  = "completed"
+)
+
+// DeviceRecordingStaleAfter is how long an upload may stay in processing
+// before a retransmission of the recording takes it over
+const DeviceRecordingStaleAfter = 10 * time.Minute
+
+// ErrRecordingInProgress is returned when a recording is already being uploaded
+var ErrRecordingInProgress = errors.New("recording is already being processed")
+
+// DeviceRecording remembers a recording a device uploaded, identified by the
+// ID the device assigned to it, with the response the upload produced.
+// Headsets retransmit whole sessions after connectivity drops; a re-sent
+// recording gets the stored response instead of creating another report.
+type DeviceRecording struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	UserID      uint   `gorm:"not null;uniqueIndex:idx_device_recording"`
+	DeviceID    string `gorm:"type:varchar(100);not null;uniqueIndex:idx_device_recording"`
+	RecordingID string `gorm:"type:varchar(100);not null;uniqueIndex:idx_device_recording"`
+	Status      string `gorm:"type:varchar(20);not null"`
+	ReportID    *uint  `gorm:"index"`
+	// HTTP status and JSON body of the completed upload
+	ResponseStatus int
+	Response       datatypes.JSON `gorm:"type:json"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// ClaimDeviceRecording starts the upload of a recording. It returns the new
+// claim, or the earlier upload of the same recording when it completed.
+// ErrRecordingInProgress is returned while another upload of it is running.
+// Earlier uploads whose report has since been deleted, or that stalled, are
+// taken over.
+func ClaimDeviceRecording(db *gorm.DB, userID uint, deviceID, recordingID string) (recording *DeviceRecording, replay bool, err error) {
+	recording = &DeviceRecording{UserID: userID, DeviceID: deviceID, RecordingID: recordingID, Status: DeviceRecordingProcessing}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(recording)
+	if result.Error != nil {
+		return nil, false, fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return recording, false, nil
+	}
+
+	var existing DeviceRecording
+	err = db.Where("user_id = ? AND device_id = ? AND recording_id = ?", userID, deviceID, recordingID).First(&existing).Error
+	if err != nil {
+		return nil, false, fmt.Errorf("database error: %w", err)
+	}
+
+	switch existing.Status {
+	case DeviceRecordingCompleted:
+		if existing.ReportID == nil {
+			return &existing, true, nil
+		}
+		var count int64
+		if err := db.Model(&Report{}).Where("id = ?", *existing.ReportID).Count(&count).Error; err != nil {
+			return nil, false, fmt.Errorf("database error: %w", err)
+		}
+		if count > 0 {
+			return &existing, true, nil
+		}
+	case DeviceRecordingProcessing:
+		if existing.UpdatedAt.After(time.Now().Add(-DeviceRecordingStaleAfter)) {
+			return nil, false, ErrRecordingInProgress
+		}
+	}
+
+	// Take the recording over, unless a concurrent retransmission already did
+	taken := db.Model(&DeviceRecording{}).
+		Where("id = ? AND status = ? AND updated_at = ?", existing.ID, existing.Status, existing.UpdatedAt).
+		Updates(map[string]interface{}{
+			"status":          DeviceRecordingProcessing,
+			"report_id":       nil,
+			"response_status": 0,
+			"response":        nil,
+			"updated_at":      time.Now(),
+		})
+	if taken.Error != nil {
+		return nil, false, fmt.Errorf("database error: %w", taken.Error)
+	}
+	if taken.RowsAffected == 0 {
+		return nil, false, ErrRecordingInProgress
+	}
+	existing.Status = DeviceRecordingProcessing
+	existing.ReportID = nil
+	existing.ResponseStatus = 0
+	existing.Response = nil
+	return &existing, false, nil
+}
+
+// Complete stores the response of a successful upload for later
+// retransmissions. Uploads processed in the background have no report yet;
+// their upload job records it once created.
+func (d *DeviceRecording) Complete(db *gorm.DB, reportID *uint, status int, response datatypes.JSON) error {
+	d.Status = DeviceRecordingCompleted
+	d.ResponseStatus = status
+	d.Response = response
+	updates := map[string]interface{}{
+		"status":          d.Status,
+		"response_status": status,
+		"response":        response,
+	}
+	if reportID != nil {
+		d.ReportID = reportID
+		updates["report_id"] = *reportID
+	}
+	return db.Model(d).Updates(updates).Error
+}
+
+// Release forgets a claim whose upload failed so the device can send the recording again
+func (d *DeviceRecording) Release(db *gorm.DB) error {
+	return db.Where("id = ? AND status = ?", d.ID, DeviceRecordingProcessing).Delete(&DeviceRecording{}).Error
+}
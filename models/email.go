@@ -0,0 +1,202 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Email queue statuses
+const (
+	EmailStatusPending    = "pending"
+	EmailStatusSent       = "sent"
+	EmailStatusFailed     = "failed"
+	EmailStatusSuppressed = "suppressed"
+)
+
+// Suppression reasons
+const (
+	SuppressionReasonBounce    = "bounce"
+	SuppressionReasonComplaint = "complaint"
+	SuppressionReasonManual    = "manual"
+)
+
+// EmailMessage represents an outgoing email persisted in the send queue
+type EmailMessage struct {
+	gorm.Model
+	Provider string `gorm:"type:varchar(50);not null;index" json:"provider"`
+	To       string `gorm:"type:text;not null;index" json:"to"`
+	Subject  string `gorm:"type:text;not null" json:"subject"`
+	Body     string `gorm:"type:text" json:"body"`
+	// Branded HTML alternative and sender name for members of organizations with branding
+	HTMLBody          string     `gorm:"type:text" json:"html_body,omitempty"`
+	FromName          string     `gorm:"type:varchar(100)" json:"from_name,omitempty"`
+	Category          string     `gorm:"type:varchar(50)" json:"category"`
+	Status            string     `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	Attempts          int        `gorm:"default:0" json:"attempts"`
+	NextAttemptAt     time.Time  `gorm:"index" json:"next_attempt_at"`
+	LastError         string     `gorm:"type:text" json:"last_error,omitempty"`
+	ProviderMessageID string     `gorm:"type:text;index" json:"provider_message_id,omitempty"`
+	SentAt            *time.Time `json:"sent_at,omitempty"`
+}
+
+// EmailSuppression is an address that must not receive any further email
+type EmailSuppression struct {
+	gorm.Model
+	Email  string `gorm:"type:text;uniqueIndex;not null" json:"email"`
+	Reason string `gorm:"type:varchar(20);not null" json:"reason"`
+	Detail string `gorm:"type:text" json:"detail,omitempty"`
+}
+
+// normalizeEmail lowercases and trims an address for suppression lookups
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// IsEmailSuppressed checks if an address is on the suppression list
+func IsEmailSuppressed(db *gorm.DB, email string) (bool, error) {
+	var count int64
+	err := db.Model(&EmailSuppression{}).Where("email = ?", normalizeEmail(email)).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// SuppressEmail adds an address to the suppression list, keeping the first recorded reason
+func SuppressEmail(db *gorm.DB, email, reason, detail string) error {
+	suppression := EmailSuppression{
+		Email:  normalizeEmail(email),
+		Reason: reason,
+		Detail: detail,
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&suppression).Error
+}
+
+// RemoveEmailSuppression removes an address from the suppression list
+func RemoveEmailSuppression(db *gorm.DB, email string) error {
+	return db.Unscoped().Where("email = ?", normalizeEmail(email)).Delete(&EmailSuppression{}).Error
+}
+
+// EnqueueEmail stores a message in the send queue. Messages to suppressed
+// addresses are recorded with the suppressed status and never sent.
+func EnqueueEmail(db *gorm.DB, msg *EmailMessage) error {
+	suppressed, err := IsEmailSuppressed(db, msg.To)
+	if err != nil {
+		return fmt.Errorf("failed to check suppression list: %w", err)
+	}
+
+	msg.Status = EmailStatusPending
+	if suppressed {
+		msg.Status = EmailStatusSuppressed
+	}
+	if msg.NextAttemptAt.IsZero() {
+		msg.NextAttemptAt = time.Now()
+	}
+
+	return db.Create(msg).Error
+}
+
+// ClaimDueEmails locks up to limit pending messages that are due for delivery
+// and pushes their next attempt out by lease so other workers skip them.
+// If the worker dies mid-send the message becomes due again once the lease expires.
+func ClaimDueEmails(db *gorm.DB, limit int, lease time.Duration) ([]EmailMessage, error) {
+	var messages []EmailMessage
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", EmailStatusPending, now).
+			Order("next_attempt_at asc").
+			Limit(limit).
+			Find(&messages).Error; err != nil {
+			return err
+		}
+
+		if len(messages) == 0 {
+			return nil
+		}
+
+		// Stored timestamps keep microseconds, so RenewLease can match this one
+		until := now.Add(lease).Truncate(time.Microsecond)
+		ids := make([]uint, len(messages))
+		for i := range messages {
+			ids[i] = messages[i].ID
+			messages[i].NextAttemptAt = until
+		}
+		return tx.Model(&EmailMessage{}).Where("id IN ?", ids).
+			Update("next_attempt_at", until).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim emails: %w", err)
+	}
+
+	return messages, nil
+}
+
+// RenewLease pushes the claimed message's next attempt out by lease again,
+// just before it is sent. It returns false when the lease ran out and another
+// worker claimed the message in the meantime.
+func (m *EmailMessage) RenewLease(db *gorm.DB, lease time.Duration) (bool, error) {
+	until := time.Now().Add(lease).Truncate(time.Microsecond)
+	result := db.Model(&EmailMessage{}).
+		Where("id = ? AND status = ? AND next_attempt_at = ?", m.ID, EmailStatusPending, m.NextAttemptAt).
+		Update("next_attempt_at", until)
+	if result.Error != nil {
+		return false, fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	m.NextAttemptAt = until
+	return true, nil
+}
+
+// MarkSent records a successful delivery
+func (m *EmailMessage) MarkSent(db *gorm.DB, providerMessageID string) error {
+	now := time.Now()
+	m.Status = EmailStatusSent
+	m.SentAt = &now
+	m.ProviderMessageID = providerMessageID
+	m.Attempts++
+	return db.Model(m).Updates(map[string]interface{}{
+		"status":              m.Status,
+		"sent_at":             now,
+		"provider_message_id": providerMessageID,
+		"attempts":            m.Attempts,
+		"last_error":          "",
+	}).Error
+}
+
+// MarkRetry records a failed attempt and schedules the next one
+func (m *EmailMessage) MarkRetry(db *gorm.DB, sendErr error, nextAttemptAt time.Time) error {
+	m.Attempts++
+	m.LastError = sendErr.Error()
+	m.NextAttemptAt = nextAttemptAt
+	return db.Model(m).Updates(map[string]interface{}{
+		"attempts":        m.Attempts,
+		"last_error":      m.LastError,
+		"next_attempt_at": nextAttemptAt,
+	}).Error
+}
+
+// MarkFailed records a permanent delivery failure
+func (m *EmailMessage) MarkFailed(db *gorm.DB, sendErr error) error {
+	m.Attempts++
+	m.Status = EmailStatusFailed
+	m.LastError = sendErr.Error()
+	return db.Model(m).Updates(map[string]interface{}{
+		"attempts":   m.Attempts,
+		"status":     m.Status,
+		"last_error": m.LastError,
+	}).Error
+}
+
+// MarkSuppressed records that a message was dropped because its recipient is suppressed
+func (m *EmailMessage) MarkSuppressed(db *gorm.DB) error {
+	m.Status = EmailStatusSuppressed
+	return db.Model(m).Update("status", m.Status).Error
+}
@@ -0,0 +1,170 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"gorm.io/gorm"
+)
+
+// widgetTokenPrefix marks widget tokens so they are recognisable in logs and secret scanners
+const widgetTokenPrefix = "tik_wgt_"
+
+// MaxWidgetOrigins bounds how many origins one widget may be embedded from
+const MaxWidgetOrigins = 10
+
+// ErrWidgetNotFound is returned for widget tokens that are unknown or revoked
+var ErrWidgetNotFound = fmt.Errorf("widget not found")
+
+// EmbedWidget lets an organization embed read-only views of shared reports in
+// its patient portal. Browsers may only call the shared report endpoint with
+// the widget's token from one of its allowed origins. Only a hash of the token
+// is stored.
+type EmbedWidget struct {
+	ID             uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID uint   `gorm:"not null;index" json:"organization_id"`
+	Name           string `gorm:"type:text;not null" json:"name" example:"Patient portal"`
+	TokenHash      string `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	TokenHint      string `gorm:"type:varchar(16);not null" json:"token_hint" example:"tik_wgt_3q2x"`
+	// Space-separated origins, exposed as AllowedOrigins
+	Origins        string     `gorm:"type:text;not null" json:"-"`
+	AllowedOrigins []string   `gorm:"-" json:"allowed_origins" example:"https://portal.clinic.example"`
+	CreatedBy      uint       `gorm:"not null" json:"created_by"`
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// AfterFind fills in the derived fields
+func (w *EmbedWidget) AfterFind(tx *gorm.DB) error {
+	w.AllowedOrigins = strings.Fields(w.Origins)
+	return nil
+}
+
+// NormalizeOrigin checks that origin is a bare scheme://host[:port] as sent in
+// the Origin header and returns it lowercased
+func NormalizeOrigin(origin string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(origin))
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" ||
+		(u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return "", fmt.Errorf("%q is not an origin such as https://portal.example.com", origin)
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host), nil
+}
+
+// hashWidgetToken returns the stored form of a widget token
+func hashWidgetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateEmbedWidget issues a widget for an organization, embeddable from the
+// given origins. The token is returned only once.
+func CreateEmbedWidget(db *gorm.DB, orgID uint, name string, origins []string, createdBy uint) (*EmbedWidget, string, error) {
+	if len(origins) == 0 || len(origins) > MaxWidgetOrigins {
+		return nil, "", fmt.Errorf("a widget needs between 1 and %d allowed origins", MaxWidgetOrigins)
+	}
+	normalized := make([]string, 0, len(origins))
+	for _, origin := range origins {
+		value, err := NormalizeOrigin(origin)
+		if err != nil {
+			return nil, "", err
+		}
+		normalized = append(normalized, value)
+	}
+
+	secret, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	token := widgetTokenPrefix + secret
+
+	widget := &EmbedWidget{
+		OrganizationID: orgID,
+		Name:           name,
+		TokenHash:      hashWidgetToken(token),
+		TokenHint:      token[:len(widgetTokenPrefix)+4],
+		Origins:        strings.Join(normalized, " "),
+		AllowedOrigins: normalized,
+		CreatedBy:      createdBy,
+	}
+	if err := db.Create(widget).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to create widget: %w", err)
+	}
+	return widget, token, nil
+}
+
+// FindEmbedWidgetByToken returns the active widget for a token
+func FindEmbedWidgetByToken(db *gorm.DB, token string) (*EmbedWidget, error) {
+	var widget EmbedWidget
+	err := db.Where("token_hash = ? AND revoked_at IS NULL", hashWidgetToken(token)).First(&widget).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrWidgetNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &widget, nil
+}
+
+// FindEmbedWidgets lists an organization's widgets, newest first
+func FindEmbedWidgets(db *gorm.DB, orgID uint) ([]EmbedWidget, error) {
+	var widgets []EmbedWidget
+	if err := db.Where("organization_id = ?", orgID).Order("created_at desc").Find(&widgets).Error; err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return widgets, nil
+}
+
+// EmbedWidgetOriginAllowed reports whether any active widget may be embedded
+// from origin. Preflight requests carry no token, so they are checked against
+// every widget.
+func EmbedWidgetOriginAllowed(db *gorm.DB, origin string) (bool, error) {
+	var widgets []EmbedWidget
+	if err := db.Select("origins").Where("revoked_at IS NULL").Find(&widgets).Error; err != nil {
+		return false, fmt.Errorf("database error: %w", err)
+	}
+	for i := range widgets {
+		if widgets[i].AllowsOrigin(origin) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RevokeEmbedWidget revokes one of an organization's widgets so its token stops working
+func RevokeEmbedWidget(db *gorm.DB, orgID, id uint) error {
+	result := db.Model(&EmbedWidget{}).
+		Where("id = ? AND organization_id = ? AND revoked_at IS NULL", id, orgID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrWidgetNotFound
+	}
+	return nil
+}
+
+// AllowsOrigin reports whether the widget may be embedded from origin
+func (w *EmbedWidget) AllowsOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, allowed := range strings.Fields(w.Origins) {
+		if allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkUsed records when the widget was last used
+func (w *EmbedWidget) MarkUsed(db *gorm.DB) error {
+	now := time.Now()
+	w.LastUsedAt = &now
+	return db.Model(w).Update("last_used_at", now).Error
+}
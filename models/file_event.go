@@ -0,0 +1,89 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FileEventChannel is the Postgres NOTIFY channel on which processing
+// progress of uploaded files is announced as JSON-encoded FileEvents
+const FileEventChannel = "file_events"
+
+// Processing states of an uploaded file, in the order they are reached
+const (
+	FileEventUploaded    = "uploaded"
+	FileEventTranslating = "translating"
+	FileEventReportReady = "report_ready"
+	FileEventFailed      = "failed"
+)
+
+// maxFileEventError bounds the error carried by an event, keeping payloads
+// well below the 8000 byte NOTIFY limit
+const maxFileEventError = 1000
+
+// FileEvent reports that one of a user's uploaded files reached a processing state
+type FileEvent struct {
+	FileID uint   `json:"file_id" example:"7"`
+	UserID uint   `json:"-"`
+	JobID  uint   `json:"job_id,omitempty" example:"31"`
+	State  string `json:"state" example:"report_ready"`
+	// Set once the report is ready
+	ReportID *uint `json:"report_id,omitempty" example:"12"`
+	// Why processing failed
+	Error string    `json:"error,omitempty"`
+	At    time.Time `json:"at"`
+}
+
+// fileEventPayload is how a FileEvent travels over FileEventChannel; unlike
+// the client-facing JSON it carries the owner
+type fileEventPayload struct {
+	FileEvent
+	UserID uint `json:"user_id"`
+}
+
+// PublishFileEvent announces a processing state of the job's file to the
+// event streams of every API process. Events are best effort: the file
+// status endpoint remains the source of truth, so failures are only logged.
+func PublishFileEvent(db *gorm.DB, job *UploadJob, state string) {
+	event := FileEvent{
+		FileID:   job.FileID,
+		UserID:   job.UserID,
+		JobID:    job.ID,
+		State:    state,
+		ReportID: job.ReportID,
+		At:       time.Now(),
+	}
+	if state == FileEventFailed {
+		event.Error = job.Error
+		if len(event.Error) > maxFileEventError {
+			event.Error = event.Error[:maxFileEventError]
+		}
+	}
+
+	payload, err := json.Marshal(fileEventPayload{FileEvent: event, UserID: event.UserID})
+	if err != nil {
+		log.Printf("Failed to encode event for file %d: %v", event.FileID, err)
+		return
+	}
+	if err := db.Exec("SELECT pg_notify(?, ?)", FileEventChannel, string(payload)).Error; err != nil {
+		log.Printf("Failed to announce event for file %d: %v", event.FileID, err)
+	}
+}
+
+// ParseFileEvent decodes an event announced on FileEventChannel
+func ParseFileEvent(payload string) (FileEvent, error) {
+	var decoded fileEventPayload
+	if err := json.Unmarshal([]byte(payload), &decoded); err != nil {
+		return FileEvent{}, fmt.Errorf("malformed payload %q: %w", payload, err)
+	}
+	event := decoded.FileEvent
+	event.UserID = decoded.UserID
+	if event.FileID == 0 || event.UserID == 0 || event.State == "" {
+		return FileEvent{}, fmt.Errorf("incomplete payload %q", payload)
+	}
+	return event, nil
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FilePreview is a downsampled waveform of an uploaded file's recording,
+// made when the file is processed so the UI can draw it without loading the
+// samples
+type FilePreview struct {
+	FileID uint `gorm:"primaryKey;autoIncrement:false" json:"file_id" example:"7"`
+	// Points per channel, and how many samples each point covers
+	Points          int     `gorm:"not null" json:"points" example:"200"`
+	SamplesPerPoint float64 `gorm:"not null" json:"samples_per_point" example:"76.8"`
+	// The recording's sampling rate, when it declares one
+	SamplingRateHz float64 `gorm:"not null;default:0" json:"sampling_rate_hz,omitempty" example:"256"`
+	// Per channel: {"channel", "min", "max"}, the lowest and highest sample of each point
+	Series    datatypes.JSON `gorm:"type:json;not null" json:"series" swaggertype:"array,object"`
+	CreatedAt time.Time      `json:"created_at"`
+
+	File *SingleFile `gorm:"foreignKey:FileID;constraint:OnDelete:CASCADE" json:"-" swaggerignore:"true"`
+}
+
+// SaveFilePreview stores the file's preview, replacing any earlier one
+func SaveFilePreview(db *gorm.DB, preview *FilePreview) error {
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "file_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"points", "samples_per_point", "sampling_rate_hz", "series", "created_at"}),
+	}).Create(preview).Error
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// FindFilePreview returns the preview of one of the user's files
+func FindFilePreview(db *gorm.DB, fileID, userID uint) (*FilePreview, error) {
+	var preview FilePreview
+	err := db.Where("file_id = ? AND file_id IN (?)", fileID,
+		db.Model(&SingleFile{}).Select("id").Where("user_id = ?", userID)).
+		First(&preview).Error
+	if err != nil {
+		return nil, err
+	}
+	return &preview, nil
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Processing states of an uploaded file, persisted as each step starts
+const (
+	FileStatePending     = "pending"     // stored and waiting for a worker
+	FileStateScanning    = "scanning"    // a worker is decoding and checking the recording
+	FileStateTranslating = "translating" // the recording is with the ML service
+	FileStateCompleted   = "completed"   // its report is ready
+	FileStateFailed      = "failed"      // processing failed or the file was quarantined
+)
+
+// Classes of processing errors recorded on a file
+const (
+	FileErrorStorage       = "storage"        // the stored file could not be read
+	FileErrorParse         = "parse"          // the file does not decode as a recording
+	FileErrorValidation    = "validation"     // the recording failed the sanity checks
+	FileErrorMLUnavailable = "ml_unavailable" // the ML service could not be reached
+	FileErrorTranslation   = "translation"    // the ML service returned an error or no text
+	FileErrorProcessing    = "processing"     // creating the report failed
+)
+
+// FileStates lists the states a file listing can be filtered by
+var FileStates = []string{FileStatePending, FileStateScanning, FileStateTranslating, FileStateCompleted, FileStateFailed}
+
+// fileStateSources maps each state to the states a file may reach it from.
+// A worker that stopped part way may start a file over, and finished files
+// are queued again when they are re-translated.
+var fileStateSources = map[string][]string{
+	FileStatePending:     {FileStateCompleted, FileStateFailed},
+	FileStateScanning:    {FileStatePending, FileStateScanning, FileStateTranslating},
+	FileStateTranslating: {FileStatePending, FileStateScanning},
+	FileStateCompleted:   {FileStateScanning, FileStateTranslating},
+	FileStateFailed:      {FileStatePending, FileStateScanning, FileStateTranslating},
+}
+
+// ErrFileStateTransition is returned when a file cannot move to a state from the one it is in
+var ErrFileStateTransition = errors.New("file cannot move to that state from its current one")
+
+// ValidFileState reports whether state is one of the file states
+func ValidFileState(state string) bool {
+	_, ok := fileStateSources[state]
+	return ok
+}
+
+// SetState moves the file to state, failing with ErrFileStateTransition when
+// its current state does not lead there. The check and the update are one
+// statement, so concurrent workers cannot both move the file.
+func (sf *SingleFile) SetState(db *gorm.DB, state string) error {
+	changedAt, err := setFileState(db, sf.ID, state)
+	if err != nil {
+		return err
+	}
+	sf.State = state
+	sf.StateChangedAt = &changedAt
+	if state == FileStatePending || state == FileStateScanning {
+		sf.ErrorClass, sf.ErrorMessage = "", ""
+	}
+	return nil
+}
+
+// SetFileState moves the file with the given ID to state, like SetState
+func SetFileState(db *gorm.DB, fileID uint, state string) error {
+	_, err := setFileState(db, fileID, state)
+	return err
+}
+
+func setFileState(db *gorm.DB, fileID uint, state string) (time.Time, error) {
+	sources, ok := fileStateSources[state]
+	if !ok {
+		return time.Time{}, fmt.Errorf("unknown file state %q", state)
+	}
+	now := time.Now()
+	changes := map[string]interface{}{"state": state, "state_changed_at": now}
+	// A new attempt starts without the previous one's error
+	if state == FileStatePending || state == FileStateScanning {
+		changes["error_class"] = ""
+		changes["error_message"] = ""
+	}
+	result := db.Model(&SingleFile{}).
+		Where("id = ? AND state IN ?", fileID, sources).
+		Updates(changes)
+	if result.Error != nil {
+		return time.Time{}, fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return time.Time{}, fmt.Errorf("file %d to %s: %w", fileID, state, ErrFileStateTransition)
+	}
+	return now, nil
+}
+
+// SetFileError records why processing or translating the file failed. The
+// file's state is left alone: a report may still be made without a translation.
+func SetFileError(db *gorm.DB, fileID uint, class, message string) error {
+	err := db.Model(&SingleFile{}).Where("id = ?", fileID).
+		Updates(map[string]interface{}{"error_class": class, "error_message": message}).Error
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
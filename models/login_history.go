@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LoginEvent records a successful sign-in
+type LoginEvent struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	IP        string    `gorm:"type:varchar(45)" json:"ip"`
+	UserAgent string    `gorm:"type:text" json:"user_agent"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// LoginSummary counts distinct users who signed in over recent windows
+type LoginSummary struct {
+	ActiveLastDay    int64 `json:"active_last_day" example:"42"`
+	ActiveLast7Days  int64 `json:"active_last_7_days" example:"180"`
+	ActiveLast30Days int64 `json:"active_last_30_days" example:"410"`
+	LoginsLast30Days int64 `json:"logins_last_30_days" example:"2150"`
+}
+
+// FindLoginHistory returns the user's most recent sign-ins, newest first
+func FindLoginHistory(db *gorm.DB, userID uint, limit int) ([]LoginEvent, error) {
+	var events []LoginEvent
+	err := db.Where("user_id = ?", userID).Order("created_at desc").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// SummarizeLogins returns active-user counts for the last day, week and month
+func SummarizeLogins(db *gorm.DB) (*LoginSummary, error) {
+	now := time.Now()
+	summary := &LoginSummary{}
+
+	windows := []struct {
+		since time.Time
+		dest  *int64
+	}{
+		{now.Add(-24 * time.Hour), &summary.ActiveLastDay},
+		{now.AddDate(0, 0, -7), &summary.ActiveLast7Days},
+		{now.AddDate(0, 0, -30), &summary.ActiveLast30Days},
+	}
+	for _, w := range windows {
+		if err := db.Model(&LoginEvent{}).Where("created_at > ?", w.since).
+			Distinct("user_id").Count(w.dest).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := db.Model(&LoginEvent{}).Where("created_at > ?", now.AddDate(0, 0, -30)).
+		Count(&summary.LoginsLast30Days).Error; err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// FindInactiveUsers returns users who have not signed in since cutoff (or never,
+// for accounts created before cutoff), in the given sort order or longest
+// inactive first. With unflaggedOnly, users already flagged inactive are skipped.
+func FindInactiveUsers(db *gorm.DB, cutoff time.Time, unflaggedOnly bool, orders []SortOrder) ([]User, error) {
+	query := db.Where("(last_login < ?) OR (last_login IS NULL AND created_at < ?)", cutoff, cutoff)
+	if unflaggedOnly {
+		query = query.Where("inactive_flagged_at IS NULL")
+	}
+
+	if len(orders) > 0 {
+		query = applySort(query, orders)
+	} else {
+		query = query.Order("last_login asc nulls first")
+	}
+
+	var users []User
+	err := query.Find(&users).Error
+	return users, err
+}
+
+// FlagInactive marks the user as inactive; the flag is cleared on next sign-in
+func (u *User) FlagInactive(db *gorm.DB) error {
+	now := time.Now()
+	u.InactiveFlaggedAt = &now
+	return db.Model(u).Update("inactive_flagged_at", now).Error
+}
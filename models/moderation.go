@@ -0,0 +1,85 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Moderation categories a translation can be flagged under
+const (
+	ModerationSelfHarm = "self_harm"
+)
+
+// ModerationFlag records which moderator flagged a report and why. The
+// matched text is not stored.
+type ModerationFlag struct {
+	Moderator string `json:"moderator" example:"self_harm_keywords"`
+	Category  string `json:"category" example:"self_harm"`
+}
+
+// OrgModerationPolicy configures moderation of an organization's translations.
+// Members of organizations without a policy get the defaults.
+type OrgModerationPolicy struct {
+	ID             uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID uint `gorm:"not null;uniqueIndex" json:"organization_id"`
+	Enabled        bool `gorm:"not null" json:"enabled"`
+	// Moderators run on members' translations; null uses the defaults
+	Moderators datatypes.JSON `gorm:"type:json" json:"moderators,omitempty" swaggertype:"array,string" example:"self_harm_keywords"`
+	// Extra phrases flagged as self-harm on top of the built-in list
+	Keywords datatypes.JSON `gorm:"type:json" json:"keywords,omitempty" swaggertype:"array,string" example:"give up on everything"`
+	// Notify clinicians linked to the member when a report is flagged, in-app and by push, and also by email with AlertEmail
+	AlertClinicians bool      `gorm:"not null" json:"alert_clinicians"`
+	AlertEmail      bool      `gorm:"not null" json:"alert_email"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// FindOrgModerationPolicy retrieves the organization's moderation policy, or nil if it has none
+func FindOrgModerationPolicy(db *gorm.DB, orgID uint) (*OrgModerationPolicy, error) {
+	var policy OrgModerationPolicy
+	if err := db.Where("organization_id = ?", orgID).First(&policy).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// UpsertOrgModerationPolicy creates or replaces the organization's moderation policy
+func UpsertOrgModerationPolicy(db *gorm.DB, policy *OrgModerationPolicy) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "moderators", "keywords", "alert_clinicians", "alert_email", "updated_at"}),
+	}).Create(policy).Error
+}
+
+// DeleteOrgModerationPolicy removes the organization's moderation policy
+func DeleteOrgModerationPolicy(db *gorm.DB, orgID uint) error {
+	return db.Where("organization_id = ?", orgID).Delete(&OrgModerationPolicy{}).Error
+}
+
+// Flag marks the report for review with the moderators' findings
+func (r *Report) Flag(db *gorm.DB, flags []ModerationFlag) error {
+	encoded, err := json.Marshal(flags)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	r.ModerationFlags = datatypes.JSON(encoded)
+	r.FlaggedAt = &now
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(r).Updates(map[string]interface{}{
+			"moderation_flags": r.ModerationFlags,
+			"flagged_at":       now,
+		}).Error; err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		return publishReportEvent(tx, EventReportUpdated, r, []string{"moderation_flags"})
+	})
+}
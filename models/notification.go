@@ -0,0 +1,234 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Broadcast audiences
+const (
+	AudienceAll          = "all"
+	AudienceCohort       = "cohort"
+	AudienceOrganization = "organization"
+)
+
+// Broadcast statuses
+const (
+	BroadcastScheduled = "scheduled"
+	BroadcastSending   = "sending"
+	BroadcastSent      = "sent"
+	BroadcastFailed    = "failed"
+	BroadcastCanceled  = "canceled"
+)
+
+// Notification is an in-app message shown to a single user
+type Notification struct {
+	ID          uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	BroadcastID *uint      `gorm:"index" json:"broadcast_id,omitempty"`
+	Title       string     `gorm:"type:text;not null" json:"title"`
+	Body        string     `gorm:"type:text;not null" json:"body"`
+	ReadAt      *time.Time `json:"read_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"index" json:"created_at"`
+}
+
+// CohortFilter selects a subset of users for a broadcast. Empty fields match everyone.
+type CohortFilter struct {
+	SubscriptionStatus string     `json:"subscription_status,omitempty" example:"active"`
+	PlanID             string     `json:"plan_id,omitempty" example:"price_1Oxy3JExamplePriceID"`
+	Locale             string     `json:"locale,omitempty" example:"en-US"`
+	CreatedAfter       *time.Time `json:"created_after,omitempty"`
+	CreatedBefore      *time.Time `json:"created_before,omitempty"`
+	InactiveOnly       bool       `json:"inactive_only,omitempty" example:"false"`
+}
+
+// Broadcast is an admin message sent to many users, optionally at a later time
+type Broadcast struct {
+	ID             uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	Title          string         `gorm:"type:text;not null" json:"title"`
+	Body           string         `gorm:"type:text;not null" json:"body"`
+	Audience       string         `gorm:"type:varchar(20);not null" json:"audience"`
+	OrganizationID *uint          `json:"organization_id,omitempty"`
+	Cohort         datatypes.JSON `gorm:"type:json" json:"cohort,omitempty" swaggertype:"object"`
+	SendEmail      bool           `gorm:"not null;default:false" json:"send_email"`
+	SendPush       bool           `gorm:"not null;default:false" json:"send_push"`
+	Status         string         `gorm:"type:varchar(20);not null;default:'scheduled';index" json:"status"`
+	ScheduledAt    time.Time      `gorm:"not null;index" json:"scheduled_at"`
+	SentAt         *time.Time     `json:"sent_at,omitempty"`
+	CreatedBy      uint           `gorm:"not null" json:"created_by"`
+	Recipients     int            `gorm:"not null;default:0" json:"recipients"`
+	InAppDelivered int            `gorm:"not null;default:0" json:"in_app_delivered"`
+	EmailsQueued   int            `gorm:"not null;default:0" json:"emails_queued"`
+	PushSent       int            `gorm:"not null;default:0" json:"push_sent"`
+	PushFailed     int            `gorm:"not null;default:0" json:"push_failed"`
+	LastError      string         `gorm:"type:text" json:"last_error,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// CohortFilter decodes the broadcast's cohort definition
+func (b *Broadcast) CohortFilter() (CohortFilter, error) {
+	var filter CohortFilter
+	if len(b.Cohort) == 0 {
+		return filter, nil
+	}
+	err := json.Unmarshal(b.Cohort, &filter)
+	return filter, err
+}
+
+// CreateNotifications stores in-app notifications for many users at once
+func CreateNotifications(db *gorm.DB, notifications []Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return db.CreateInBatches(notifications, 500).Error
+}
+
+// FindNotificationsForUser returns the user's most recent notifications and their unread count
+func FindNotificationsForUser(db *gorm.DB, userID uint, limit int) ([]Notification, int64, error) {
+	var notifications []Notification
+	if err := db.Where("user_id = ?", userID).Order("created_at desc").Limit(limit).Find(&notifications).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var unread int64
+	if err := db.Model(&Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&unread).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, unread, nil
+}
+
+// MarkNotificationRead marks one of the user's notifications as read
+func MarkNotificationRead(db *gorm.DB, userID, notificationID uint) error {
+	result := db.Model(&Notification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", notificationID, userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		db.Model(&Notification{}).Where("id = ? AND user_id = ?", notificationID, userID).Count(&count)
+		if count == 0 {
+			return fmt.Errorf("notification not found")
+		}
+	}
+	return nil
+}
+
+// CreateBroadcast stores a new scheduled broadcast
+func CreateBroadcast(db *gorm.DB, b *Broadcast) error {
+	b.Status = BroadcastScheduled
+	if err := db.Create(b).Error; err != nil {
+		return fmt.Errorf("failed to create broadcast: %w", err)
+	}
+	return nil
+}
+
+// FindBroadcastByID retrieves a broadcast
+func FindBroadcastByID(db *gorm.DB, id uint) (*Broadcast, error) {
+	var b Broadcast
+	if err := db.First(&b, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("broadcast not found")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &b, nil
+}
+
+// FindBroadcasts lists broadcasts, newest first
+func FindBroadcasts(db *gorm.DB, limit int) ([]Broadcast, error) {
+	var broadcasts []Broadcast
+	err := db.Order("created_at desc").Limit(limit).Find(&broadcasts).Error
+	return broadcasts, err
+}
+
+// ClaimDueBroadcast atomically moves one due broadcast to sending, or returns nil if none is due
+func ClaimDueBroadcast(db *gorm.DB, now time.Time) (*Broadcast, error) {
+	var b Broadcast
+	if err := db.Where("status = ? AND scheduled_at <= ?", BroadcastScheduled, now).Order("scheduled_at asc").First(&b).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// Only one worker wins the transition
+	result := db.Model(&Broadcast{}).Where("id = ? AND status = ?", b.ID, BroadcastScheduled).Update("status", BroadcastSending)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ClaimDueBroadcast(db, now)
+	}
+
+	b.Status = BroadcastSending
+	return &b, nil
+}
+
+// Cancel cancels a broadcast that has not started sending
+func (b *Broadcast) Cancel(db *gorm.DB) error {
+	result := db.Model(&Broadcast{}).Where("id = ? AND status = ?", b.ID, BroadcastScheduled).Update("status", BroadcastCanceled)
+	if result.Error != nil {
+		return fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("only scheduled broadcasts can be canceled")
+	}
+	b.Status = BroadcastCanceled
+	return nil
+}
+
+// CountBroadcastReads returns how many recipients have read the broadcast
+func CountBroadcastReads(db *gorm.DB, broadcastID uint) (int64, error) {
+	var count int64
+	err := db.Model(&Notification{}).Where("broadcast_id = ? AND read_at IS NOT NULL", broadcastID).Count(&count).Error
+	return count, err
+}
+
+// BroadcastRecipients returns a query selecting the users a broadcast targets
+func BroadcastRecipients(db *gorm.DB, b *Broadcast) (*gorm.DB, error) {
+	query := db.Model(&User{})
+
+	switch b.Audience {
+	case AudienceAll:
+	case AudienceOrganization:
+		if b.OrganizationID == nil {
+			return nil, fmt.Errorf("organization broadcast without organization")
+		}
+		query = query.Where("organization_id = ?", *b.OrganizationID)
+	case AudienceCohort:
+		filter, err := b.CohortFilter()
+		if err != nil {
+			return nil, fmt.Errorf("invalid cohort: %w", err)
+		}
+		if filter.SubscriptionStatus != "" {
+			query = query.Where("subscription_status = ?", filter.SubscriptionStatus)
+		}
+		if filter.PlanID != "" {
+			query = query.Where("current_plan_id = ?", filter.PlanID)
+		}
+		if filter.Locale != "" {
+			query = query.Where("locale = ?", filter.Locale)
+		}
+		if filter.CreatedAfter != nil {
+			query = query.Where("created_at >= ?", *filter.CreatedAfter)
+		}
+		if filter.CreatedBefore != nil {
+			query = query.Where("created_at < ?", *filter.CreatedBefore)
+		}
+		if filter.InactiveOnly {
+			query = query.Where("inactive_flagged_at IS NOT NULL")
+		}
+	default:
+		return nil, fmt.Errorf("unknown audience %q", b.Audience)
+	}
+
+	return query, nil
+}
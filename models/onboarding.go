@@ -0,0 +1,179 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"gorm.io/gorm"
+)
+
+// Onboarding steps, in the order the checklist presents them
+const (
+	OnboardingEmailVerified = "email_verified"
+	OnboardingFirstUpload   = "first_upload"
+	OnboardingFirstReport   = "first_report"
+	OnboardingSubscription  = "subscription"
+
+	// OnboardingCompleted is the state once every step is done
+	OnboardingCompleted = "completed"
+)
+
+// onboardingSteps maps each step to the user column recording when it was completed
+var onboardingSteps = []struct {
+	step   string
+	column string
+}{
+	{OnboardingEmailVerified, "email_verified_at"},
+	{OnboardingFirstUpload, "first_upload_at"},
+	{OnboardingFirstReport, "first_report_at"},
+	{OnboardingSubscription, "first_subscribed_at"},
+}
+
+// EmailVerificationTTL is how long an email verification link stays valid
+const EmailVerificationTTL = 48 * time.Hour
+
+// OnboardingStep is one item of the onboarding checklist
+type OnboardingStep struct {
+	Step        string     `json:"step" example:"first_upload"`
+	Completed   bool       `json:"completed" example:"false"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// OnboardingStatus is the user's position in the onboarding flow. State is the
+// next step to complete, or "completed".
+type OnboardingStatus struct {
+	State       string           `json:"state" example:"first_upload"`
+	Steps       []OnboardingStep `json:"steps"`
+	CompletedAt *time.Time       `json:"completed_at,omitempty"`
+}
+
+// EmailVerification represents a pending email address verification
+type EmailVerification struct {
+	gorm.Model
+	UserID    uint      `gorm:"not null;index"`
+	Token     string    `gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	Used      bool      `gorm:"default:false"`
+}
+
+// completedAt returns the completion time of a step
+func (u *User) completedAt(step string) *time.Time {
+	switch step {
+	case OnboardingEmailVerified:
+		return u.EmailVerifiedAt
+	case OnboardingFirstUpload:
+		return u.FirstUploadAt
+	case OnboardingFirstReport:
+		return u.FirstReportAt
+	case OnboardingSubscription:
+		return u.FirstSubscribedAt
+	}
+	return nil
+}
+
+// Onboarding returns the user's onboarding checklist
+func (u *User) Onboarding() OnboardingStatus {
+	status := OnboardingStatus{
+		State:       OnboardingCompleted,
+		Steps:       make([]OnboardingStep, 0, len(onboardingSteps)),
+		CompletedAt: u.OnboardingCompletedAt,
+	}
+
+	for _, s := range onboardingSteps {
+		at := u.completedAt(s.step)
+		status.Steps = append(status.Steps, OnboardingStep{Step: s.step, Completed: at != nil, CompletedAt: at})
+		if at == nil && status.State == OnboardingCompleted {
+			status.State = s.step
+		}
+	}
+
+	return status
+}
+
+// CompleteOnboardingStep records that the user reached a step. Steps are only
+// recorded the first time, so calling this repeatedly is harmless. Once every
+// step is done the onboarding is marked completed.
+func (u *User) CompleteOnboardingStep(db *gorm.DB, step string) error {
+	var column string
+	for _, s := range onboardingSteps {
+		if s.step == step {
+			column = s.column
+		}
+	}
+	if column == "" {
+		return fmt.Errorf("unknown onboarding step: %s", step)
+	}
+	if u.completedAt(step) != nil {
+		return nil
+	}
+
+	now := time.Now()
+	if err := db.Model(&User{}).Where("id = ? AND "+column+" IS NULL", u.ID).Update(column, now).Error; err != nil {
+		return fmt.Errorf("failed to update onboarding: %w", err)
+	}
+
+	// Reload so steps completed concurrently are seen
+	var fresh User
+	if err := db.First(&fresh, u.ID).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	u.EmailVerifiedAt = fresh.EmailVerifiedAt
+	u.FirstUploadAt = fresh.FirstUploadAt
+	u.FirstReportAt = fresh.FirstReportAt
+	u.FirstSubscribedAt = fresh.FirstSubscribedAt
+	u.OnboardingCompletedAt = fresh.OnboardingCompletedAt
+
+	if u.OnboardingCompletedAt == nil && u.Onboarding().State == OnboardingCompleted {
+		u.OnboardingCompletedAt = &now
+		return db.Model(&User{}).Where("id = ? AND onboarding_completed_at IS NULL", u.ID).
+			Update("onboarding_completed_at", now).Error
+	}
+
+	return nil
+}
+
+// GenerateEmailVerificationToken creates a token for verifying the user's email address
+func (u *User) GenerateEmailVerificationToken(db *gorm.DB) (string, error) {
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	verification := EmailVerification{
+		UserID:    u.ID,
+		Token:     token,
+		ExpiresAt: time.Now().Add(EmailVerificationTTL),
+	}
+	if err := db.Create(&verification).Error; err != nil {
+		return "", fmt.Errorf("error saving verification token: %w", err)
+	}
+
+	return token, nil
+}
+
+// VerifyEmailToken consumes a verification token and marks the user's email as verified
+func VerifyEmailToken(db *gorm.DB, token string) (*User, error) {
+	var verification EmailVerification
+	if err := db.Where("token = ? AND used = ? AND expires_at > ?", token, false, time.Now()).First(&verification).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("invalid or expired token")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if err := db.Model(&verification).Update("used", true).Error; err != nil {
+		return nil, fmt.Errorf("error updating token: %w", err)
+	}
+
+	user, err := FindUserByID(db, verification.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	if err := user.CompleteOnboardingStep(db, OnboardingEmailVerified); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
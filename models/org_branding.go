@@ -0,0 +1,135 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Limits on organization branding
+const (
+	MaxBrandingNameLength   = 100
+	MaxBrandingFooterLength = 300
+)
+
+// brandingColor matches the #RRGGBB colors accepted for branding
+var brandingColor = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// OrgBranding is the look an organization's members get in exported
+// documents and outgoing emails. Empty fields keep the ThinkInk defaults.
+type OrgBranding struct {
+	ID             uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID uint `gorm:"not null;uniqueIndex" json:"organization_id"`
+	// Name shown in place of "ThinkInk" in document titles and email senders
+	DisplayName string `gorm:"type:varchar(100)" json:"display_name,omitempty" example:"Northside Neurology"`
+	// Colors as #RRGGBB: the primary color is used for titles, the accent for rules
+	PrimaryColor string `gorm:"type:varchar(7)" json:"primary_color,omitempty" example:"#1F4E79"`
+	AccentColor  string `gorm:"type:varchar(7)" json:"accent_color,omitempty" example:"#F2A900"`
+	// Printed in document footers and appended to emails
+	FooterText string `gorm:"type:text" json:"footer_text,omitempty" example:"Northside Neurology Clinic, 12 High Street"`
+	// Logo file in the branding directory; empty when the organization has no logo
+	LogoFile        string     `gorm:"type:text" json:"-"`
+	LogoContentType string     `gorm:"type:varchar(20)" json:"logo_content_type,omitempty" example:"image/png"`
+	LogoUpdatedAt   *time.Time `json:"logo_updated_at,omitempty"`
+	HasLogo         bool       `gorm:"-" json:"has_logo"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// AfterFind fills in the derived fields
+func (b *OrgBranding) AfterFind(tx *gorm.DB) error {
+	b.HasLogo = b.LogoFile != ""
+	return nil
+}
+
+// Validate checks the branding's text fields and colors
+func (b *OrgBranding) Validate() error {
+	if len(b.DisplayName) > MaxBrandingNameLength {
+		return fmt.Errorf("display name must be at most %d characters", MaxBrandingNameLength)
+	}
+	if len(b.FooterText) > MaxBrandingFooterLength {
+		return fmt.Errorf("footer text must be at most %d characters", MaxBrandingFooterLength)
+	}
+	for _, color := range []string{b.PrimaryColor, b.AccentColor} {
+		if color != "" && !brandingColor.MatchString(color) {
+			return fmt.Errorf("colors must be given as #RRGGBB, got %q", color)
+		}
+	}
+	return nil
+}
+
+// FindOrgBranding retrieves the organization's branding, or nil if it has none
+func FindOrgBranding(db *gorm.DB, orgID uint) (*OrgBranding, error) {
+	var branding OrgBranding
+	if err := db.Where("organization_id = ?", orgID).First(&branding).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &branding, nil
+}
+
+// FindUserBranding retrieves the branding of the user's organization, or nil
+// if the user has no organization or it has no branding
+func FindUserBranding(db *gorm.DB, user *User) (*OrgBranding, error) {
+	if user.OrganizationID == nil {
+		return nil, nil
+	}
+	return FindOrgBranding(db, *user.OrganizationID)
+}
+
+// FindBrandingForEmail retrieves the branding of the organization of the
+// account with the given email address, or nil if there is none
+func FindBrandingForEmail(db *gorm.DB, email string) (*OrgBranding, error) {
+	var branding OrgBranding
+	err := db.Joins("JOIN users ON users.organization_id = org_brandings.organization_id").
+		Where("LOWER(users.email) = ? AND users.deleted_at IS NULL", normalizeEmail(email)).
+		First(&branding).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &branding, nil
+}
+
+// UpsertOrgBranding creates or replaces the organization's display name,
+// colors and footer; the logo is kept
+func UpsertOrgBranding(db *gorm.DB, branding *OrgBranding) error {
+	if err := branding.Validate(); err != nil {
+		return err
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"display_name", "primary_color", "accent_color", "footer_text", "updated_at"}),
+	}).Create(branding).Error
+}
+
+// SetBrandingLogo records the organization's logo file, creating the branding if needed
+func SetBrandingLogo(db *gorm.DB, orgID uint, file, contentType string) error {
+	now := time.Now()
+	branding := &OrgBranding{OrganizationID: orgID, LogoFile: file, LogoContentType: contentType, LogoUpdatedAt: &now}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"logo_file", "logo_content_type", "logo_updated_at", "updated_at"}),
+	}).Create(branding).Error
+}
+
+// ClearBrandingLogo forgets the organization's logo file
+func ClearBrandingLogo(db *gorm.DB, orgID uint) error {
+	return db.Model(&OrgBranding{}).Where("organization_id = ?", orgID).Updates(map[string]interface{}{
+		"logo_file":         "",
+		"logo_content_type": "",
+		"logo_updated_at":   nil,
+	}).Error
+}
+
+// DeleteOrgBranding removes the organization's branding
+func DeleteOrgBranding(db *gorm.DB, orgID uint) error {
+	return db.Where("organization_id = ?", orgID).Delete(&OrgBranding{}).Error
+}
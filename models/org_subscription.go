@@ -0,0 +1,141 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrgSubscription is an organization's seat-based Stripe subscription, bought
+// by an organization admin. Every member of the organization takes one seat,
+// and members hold the subscription's plan while it is active.
+type OrgSubscription struct {
+	ID             uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID uint `gorm:"not null;uniqueIndex" json:"organization_id" example:"1"`
+	// The organization's own Stripe customer, billed for the seats
+	StripeCustomerID   string     `gorm:"type:varchar(255);not null;index" json:"-"`
+	SubscriptionID     string     `gorm:"type:varchar(255);index" json:"subscription_id,omitempty" example:"sub_12345"`
+	SubscriptionItemID string     `gorm:"type:varchar(255)" json:"-"`
+	PlanID             string     `gorm:"type:varchar(255)" json:"plan_id,omitempty" example:"price_1Oxy3JExamplePriceID"`
+	Status             string     `gorm:"type:varchar(20)" json:"status,omitempty" example:"active"`
+	Seats              int        `gorm:"not null;default:0" json:"seats" example:"10"`
+	CurrentPeriodEnd   *time.Time `json:"current_period_end,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// IsActive reports whether the subscription grants members its plan
+func (s *OrgSubscription) IsActive() bool {
+	return s.Status == "active" || s.Status == "trialing"
+}
+
+// LimitsSeats reports whether members are limited to the subscribed seats:
+// from checkout until the subscription is canceled
+func (s *OrgSubscription) LimitsSeats() bool {
+	return s.SubscriptionID != "" && s.Status != "canceled"
+}
+
+// FindOrgSubscription retrieves the organization's subscription, or nil if
+// it never started a checkout
+func FindOrgSubscription(db *gorm.DB, orgID uint) (*OrgSubscription, error) {
+	var sub OrgSubscription
+	err := db.Where("organization_id = ?", orgID).First(&sub).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &sub, nil
+}
+
+// FindOrgSubscriptionByCustomer retrieves the subscription billed to a Stripe
+// customer, or nil if the customer is not an organization's
+func FindOrgSubscriptionByCustomer(db *gorm.DB, customerID string) (*OrgSubscription, error) {
+	var sub OrgSubscription
+	err := db.Where("stripe_customer_id = ?", customerID).First(&sub).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &sub, nil
+}
+
+// CreateOrgSubscription records the organization's Stripe customer before its
+// first checkout; an existing record is kept
+func CreateOrgSubscription(db *gorm.DB, orgID uint, customerID string) (*OrgSubscription, error) {
+	sub := &OrgSubscription{OrganizationID: orgID, StripeCustomerID: customerID}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(sub).Error; err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return FindOrgSubscription(db, orgID)
+}
+
+// UpdateSubscription stores the state of the Stripe subscription
+func (s *OrgSubscription) UpdateSubscription(db *gorm.DB, subscriptionID, itemID, planID, status string, seats int, periodEnd *time.Time) error {
+	s.SubscriptionID = subscriptionID
+	s.SubscriptionItemID = itemID
+	s.PlanID = planID
+	s.Status = status
+	s.Seats = seats
+	s.CurrentPeriodEnd = periodEnd
+	err := db.Model(s).Updates(map[string]interface{}{
+		"subscription_id":      subscriptionID,
+		"subscription_item_id": itemID,
+		"plan_id":              planID,
+		"status":               status,
+		"seats":                seats,
+		"current_period_end":   periodEnd,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// FindActiveOrgSubscriptions retrieves the organization subscriptions that
+// grant their members a plan
+func FindActiveOrgSubscriptions(db *gorm.DB) ([]OrgSubscription, error) {
+	var subs []OrgSubscription
+	if err := db.Where("status IN ?", []string{"active", "trialing"}).Find(&subs).Error; err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return subs, nil
+}
+
+// CountOrgSeatsTaken counts the active members of an organization, each
+// taking one seat
+func CountOrgSeatsTaken(db *gorm.DB, orgID uint) (int, error) {
+	var count int64
+	if err := db.Model(&User{}).Where("organization_id = ?", orgID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	return int(count), nil
+}
+
+// FindOrgMemberIDs lists the IDs of an organization's members
+func FindOrgMemberIDs(db *gorm.DB, orgID uint) ([]uint, error) {
+	var ids []uint
+	if err := db.Model(&User{}).Where("organization_id = ?", orgID).Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return ids, nil
+}
+
+// SeatSubscription returns the active subscription of the user's
+// organization, whose plan they hold through their seat, or nil
+func (u *User) SeatSubscription(db *gorm.DB) (*OrgSubscription, error) {
+	if u.OrganizationID == nil {
+		return nil, nil
+	}
+	sub, err := FindOrgSubscription(db, *u.OrganizationID)
+	if err != nil || sub == nil || !sub.IsActive() {
+		return nil, err
+	}
+	return sub, nil
+}
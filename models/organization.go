@@ -0,0 +1,128 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Organization groups users under one contract (e.g. a clinic or enterprise customer)
+type Organization struct {
+	ID   uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name string `gorm:"type:text;not null" json:"name"`
+	// Post-processors applied to members' translations, in order; null uses the defaults
+	TextProcessors datatypes.JSON `gorm:"type:json" json:"text_processors,omitempty" swaggertype:"array,string" example:"punctuation,casing,profanity"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// OrgRatePlan holds contract-specific limits and data policies for an
+// organization. Nil fields fall back to the member's plan defaults.
+type OrgRatePlan struct {
+	ID                   uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID       uint      `gorm:"not null;uniqueIndex" json:"organization_id"`
+	RequestsPerMinute    *int      `json:"requests_per_minute,omitempty"`
+	UploadsPerMonth      *int      `json:"uploads_per_month,omitempty"`
+	TranslationsPerMonth *int      `json:"translations_per_month,omitempty"`
+	StorageBytes         *int64    `json:"storage_bytes,omitempty"`
+	MaxFileBytes         *int64    `json:"max_file_bytes,omitempty"`
+	UploadBytesPerMonth  *int64    `json:"upload_bytes_per_month,omitempty"`
+	LapseAction          *string   `gorm:"type:varchar(20)" json:"lapse_action,omitempty"`
+	LapseGraceDays       *int      `json:"lapse_grace_days,omitempty"`
+	Notes                string    `gorm:"type:text" json:"notes,omitempty"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// CreateOrganization creates a new organization
+func CreateOrganization(db *gorm.DB, name string) (*Organization, error) {
+	org := &Organization{Name: name}
+	if err := db.Create(org).Error; err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+	return org, nil
+}
+
+// FindOrganizationByID retrieves an organization by its ID
+func FindOrganizationByID(db *gorm.DB, id uint) (*Organization, error) {
+	var org Organization
+	if err := db.First(&org, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("organization not found")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &org, nil
+}
+
+// FindAllOrganizations retrieves all organizations
+func FindAllOrganizations(db *gorm.DB) ([]Organization, error) {
+	var orgs []Organization
+	err := db.Order("name asc").Find(&orgs).Error
+	return orgs, err
+}
+
+// FindOrgRatePlan retrieves the organization's custom rate plan, or nil if it has none
+func FindOrgRatePlan(db *gorm.DB, orgID uint) (*OrgRatePlan, error) {
+	var plan OrgRatePlan
+	if err := db.Where("organization_id = ?", orgID).First(&plan).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// UpsertOrgRatePlan creates or replaces the organization's custom rate plan
+func UpsertOrgRatePlan(db *gorm.DB, plan *OrgRatePlan) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"requests_per_minute", "uploads_per_month", "translations_per_month", "storage_bytes", "max_file_bytes", "upload_bytes_per_month", "lapse_action", "lapse_grace_days", "notes", "updated_at"}),
+	}).Create(plan).Error
+}
+
+// DeleteOrgRatePlan removes the organization's custom rate plan
+func DeleteOrgRatePlan(db *gorm.DB, orgID uint) error {
+	return db.Where("organization_id = ?", orgID).Delete(&OrgRatePlan{}).Error
+}
+
+// SetOrganization assigns the user to an organization, or removes them when orgID is nil
+func (u *User) SetOrganization(db *gorm.DB, orgID *uint) error {
+	changed := (u.OrganizationID == nil) != (orgID == nil) ||
+		(u.OrganizationID != nil && orgID != nil && *u.OrganizationID != *orgID)
+	u.OrganizationID = orgID
+	if !changed {
+		return db.Model(u).Update("organization_id", orgID).Error
+	}
+	// A role does not carry over to another organization
+	u.OrgRole = OrgRoleMember
+	return db.Model(u).Updates(map[string]interface{}{"organization_id": orgID, "org_role": OrgRoleMember}).Error
+}
+
+// Roles within an organization
+const (
+	OrgRoleMember = "member"
+	OrgRoleAdmin  = "admin"
+)
+
+// SetOrgRole changes the user's role within their organization
+func (u *User) SetOrgRole(db *gorm.DB, role string) error {
+	u.OrgRole = role
+	return db.Model(u).Update("org_role", role).Error
+}
+
+// IsOrgAdmin reports whether the user administers the organization
+func (u *User) IsOrgAdmin(orgID uint) bool {
+	return u.OrganizationID != nil && *u.OrganizationID == orgID && u.OrgRole == OrgRoleAdmin
+}
+
+// SetTextProcessors stores the post-processors applied to members'
+// translations; nil restores the defaults
+func (o *Organization) SetTextProcessors(db *gorm.DB, processors datatypes.JSON) error {
+	o.TextProcessors = processors
+	return db.Model(o).Update("text_processors", processors).Error
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Page selects a slice of a list, either by offset or by the cursor returned
+// with a previous page. Lists given a Sort are paged by offset only.
+type Page struct {
+	Limit  int
+	Offset int
+	Cursor string
+	Sort   []SortOrder
+}
+
+// PageInfo describes the slice of a list that was returned
+type PageInfo struct {
+	Total      int64
+	HasMore    bool
+	NextCursor string
+}
+
+// encodeCursor builds an opaque cursor pointing just past the given row of a
+// list ordered by created_at desc, id desc
+func encodeCursor(createdAt time.Time, id uint) string {
+	raw := fmt.Sprintf("%d:%d", createdAt.UnixNano(), id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor parses a cursor produced by encodeCursor
+func decodeCursor(cursor string) (time.Time, uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid cursor")
+	}
+
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return time.Time{}, 0, fmt.Errorf("invalid cursor")
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid cursor")
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid cursor")
+	}
+
+	return time.Unix(0, nanos), uint(id), nil
+}
+
+// afterCursor narrows a list ordered by timeColumn desc, idColumn desc to the
+// rows past cursor. The row-value comparison lets Postgres seek straight to
+// the cursor in an index ending in (timeColumn, idColumn), so deep pages cost
+// the same as the first instead of scanning every earlier row.
+func afterCursor(query *gorm.DB, timeColumn, idColumn, cursor string) (*gorm.DB, error) {
+	at, id, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	return query.Where(fmt.Sprintf("(%s, %s) < (?, ?)", timeColumn, idColumn), at, id), nil
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// RecordingInfo describes the recording in an uploaded file, measured when
+// the file is processed. Files not processed yet, and files and reports from
+// before it was measured, leave it empty.
+type RecordingInfo struct {
+	ChannelCount int `gorm:"not null;default:0" json:"channel_count,omitempty" example:"32"`
+	// Samples per channel, not counting rows the mask marks as padding
+	SampleCount int `gorm:"not null;default:0" json:"sample_count,omitempty" example:"15360"`
+	// The sampling rate the recording declares, and the duration it gives
+	// the samples; both empty when the recording declares none
+	SamplingRateHz  float64 `gorm:"not null;default:0" json:"sampling_rate_hz,omitempty" example:"256"`
+	DurationSeconds float64 `gorm:"not null;default:0" json:"duration_seconds,omitempty" example:"60"`
+}
+
+// SetRecordingInfo stores what was measured about the file's recording
+func (sf *SingleFile) SetRecordingInfo(db *gorm.DB, info RecordingInfo) error {
+	err := db.Model(&SingleFile{}).Where("id = ?", sf.ID).
+		Select("channel_count", "sample_count", "sampling_rate_hz", "duration_seconds").
+		Updates(&SingleFile{RecordingInfo: info}).Error
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	sf.RecordingInfo = info
+	return nil
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecordingSession groups the segments of one recording uploaded together as
+// a zip archive. Each segment is stored as a file of its own and becomes its
+// own report.
+type RecordingSession struct {
+	ID           uint       `gorm:"primaryKey;autoIncrement" json:"id" example:"4"`
+	UserID       uint       `gorm:"not null;index" json:"user_id" example:"1"`
+	Title        string     `gorm:"type:varchar(255)" json:"title" example:"Morning session"`
+	RecordedAt   *time.Time `json:"recorded_at,omitempty"`
+	SegmentCount int        `gorm:"not null" json:"segment_count" example:"3"`
+	CreatedAt    time.Time  `json:"created_at"`
+	// The segments in session order; loaded by FindUserSession
+	Files []SingleFile `gorm:"foreignKey:SessionID" json:"files,omitempty"`
+}
+
+// Create stores the session
+func (s *RecordingSession) Create(db *gorm.DB) error {
+	if err := db.Create(s).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// FindUserSession returns one of the user's sessions with its segments' files
+func FindUserSession(db *gorm.DB, sessionID, userID uint) (*RecordingSession, error) {
+	var session RecordingSession
+	err := db.Where("id = ? AND user_id = ?", sessionID, userID).
+		Preload("Files", func(q *gorm.DB) *gorm.DB { return q.Order("session_index asc") }).
+		First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
@@ -1,6 +1,10 @@
 package models
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/datatypes"
@@ -9,16 +13,213 @@ import (
 
 // Report defines the structure for an API report
 type Report struct {
-	ID            uint           `gorm:"primaryKey;autoIncrement" json:"id"`
-	UserID        uint           `gorm:"not null" json:"user_id"`
+	ID            uint           `gorm:"primaryKey;autoIncrement;index:idx_reports_user_created,priority:3" json:"id"`
+	UserID        uint           `gorm:"not null;index:idx_reports_user_created,priority:1" json:"user_id"`
 	Title         string         `gorm:"type:varchar(255);not null" json:"title"`
 	Description   string         `gorm:"type:text" json:"description"`
 	Content       datatypes.JSON `gorm:"type:json" json:"content" swaggertype:"string" example:"{\"key\":\"value\"}"`
-	CreatedAt     time.Time      `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	CreatedAt     time.Time      `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;index:idx_reports_user_created,priority:2" json:"created_at"`
 	UpdatedAt     time.Time      `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
 	MatchingScale int            `gorm:"type:int;default:0" json:"matching_scale"`
+	Quality       datatypes.JSON `gorm:"type:json" json:"quality,omitempty" swaggertype:"object"`
+	SizeBytes     int64          `gorm:"not null;default:0" json:"size_bytes"`
+	// Schema version of Content; older reports are upgraded on read
+	ContentSchemaVersion int `gorm:"not null;default:0" json:"content_schema_version"`
+	// Set once Content was moved to cold storage; Content is then empty
+	// unless read back with LoadArchivedContent
+	ContentArchivedAt *time.Time `gorm:"type:timestamp;index" json:"content_archived_at,omitempty"`
+	// Set while a translation waits for or runs on a dedicated worker
+	TranslationStatus string `gorm:"type:varchar(20);index" json:"translation_status,omitempty"`
+	// When a worker claimed the running translation; a stale claim is taken over
+	TranslationClaimedAt *time.Time `gorm:"type:timestamp;index" json:"-"`
+	// User-editable key/value labels, e.g. session or device notes
+	Metadata datatypes.JSON `gorm:"type:json" json:"metadata,omitempty" swaggertype:"object,string" example:"session:morning"`
+	// The ML service's output before post-processing; Description holds the processed text
+	RawDescription string `gorm:"type:text" json:"raw_description,omitempty"`
+	// The user's corrected version of the translation, used as model feedback
+	Correction string `gorm:"type:text" json:"correction,omitempty"`
+	// Template the structured notes in Content["notes"] follow
+	TemplateID *uint `gorm:"index" json:"template_id,omitempty" example:"3"`
+	// The uploaded file the report was created from; SourceFile is only
+	// loaded with PreloadSourceFile
+	SourceFileID *uint       `gorm:"index" json:"source_file_id,omitempty" example:"7"`
+	SourceFile   *SingleFile `gorm:"foreignKey:SourceFileID;constraint:OnDelete:SET NULL" json:"source_file,omitempty"`
+	// The recording session the source file is a segment of
+	SessionID *uint `gorm:"index" json:"session_id,omitempty" example:"4"`
+	// Channel count, sampling rate and duration of the source file's recording
+	RecordingInfo `gorm:"embedded"`
+	// Set when the report was created from a file the user had already
+	// uploaded, with allow_duplicate; points at the earlier report
+	DuplicateOfID *uint `gorm:"index" json:"duplicate_of_id,omitempty" example:"12"`
+	// Set when moderation flagged the translation for a clinician to review
+	ModerationFlags datatypes.JSON `gorm:"type:json" json:"moderation_flags,omitempty" swaggertype:"array,object"`
+	FlaggedAt       *time.Time     `gorm:"type:timestamp;index" json:"flagged_at,omitempty"`
+	// Names of the tags attached to the report; filled in by LoadReportTags
+	Tags []string `gorm:"-" json:"tags,omitempty" example:"morning"`
+	// Archived reports are hidden from default listings; deleted reports are purged after a retention window
+	ArchivedAt *time.Time     `gorm:"type:timestamp;index" json:"archived_at,omitempty"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-" swaggerignore:"true"`
 }
 
+// Translation statuses for reports translated by a dedicated worker
+const (
+	TranslationPending   = "pending"
+	TranslationRunning   = "running"
+	TranslationCompleted = "completed"
+	TranslationFailed    = "failed"
+)
+
+// TranslationStaleAfter is how long a translation may stay running before
+// another worker takes it over, e.g. after the process running it crashed
+const TranslationStaleAfter = 30 * time.Minute
+
+// Archived report selection for listings
+const (
+	ArchivedExclude = ""     // default: hide archived reports
+	ArchivedOnly    = "only" // only archived reports
+	ArchivedInclude = "all"  // archived and active reports
+)
+
+// ReportFilter narrows a report listing. Unset fields match every report
+// except archived ones.
+type ReportFilter struct {
+	From     *time.Time // created at or after
+	To       *time.Time // created before
+	MinScale *int
+	MaxScale *int
+	Query    string   // case-insensitive match on title or description
+	Tags     []string // normalized tag names the report must all carry
+	Archived string   // one of the Archived* selections
+	Flagged  bool     // only reports flagged by moderation
+}
+
+// Apply adds the filter's conditions to a report query
+func (f ReportFilter) Apply(query *gorm.DB) *gorm.DB {
+	if f.From != nil {
+		query = query.Where("created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		query = query.Where("created_at < ?", *f.To)
+	}
+	if f.MinScale != nil {
+		query = query.Where("matching_scale >= ?", *f.MinScale)
+	}
+	if f.MaxScale != nil {
+		query = query.Where("matching_scale <= ?", *f.MaxScale)
+	}
+	if len(f.Tags) > 0 {
+		tagged := query.Session(&gorm.Session{NewDB: true}).
+			Table("report_tags").
+			Select("report_tags.report_id").
+			Joins("JOIN tags ON tags.id = report_tags.tag_id").
+			Where("tags.name IN ?", f.Tags).
+			Group("report_tags.report_id").
+			Having("COUNT(DISTINCT tags.id) = ?", len(f.Tags))
+		query = query.Where("id IN (?)", tagged)
+	}
+	if f.Flagged {
+		query = query.Where("flagged_at IS NOT NULL")
+	}
+	switch f.Archived {
+	case ArchivedExclude:
+		query = query.Where("archived_at IS NULL")
+	case ArchivedOnly:
+		query = query.Where("archived_at IS NOT NULL")
+	}
+	if f.Query != "" {
+		pattern := "%" + likeEscaper.Replace(f.Query) + "%"
+		query = query.Where("(title ILIKE ? OR description ILIKE ?)", pattern, pattern)
+	}
+	return query
+}
+
+// Limits on user-editable report metadata
+const (
+	MaxReportMetadataEntries  = 20
+	MaxReportMetadataKeyLen   = 64
+	MaxReportMetadataValueLen = 500
+)
+
+// ReportUpdate is a partial update of a report's user-editable fields; nil
+// fields are left unchanged
+type ReportUpdate struct {
+	Title       *string
+	Description *string
+	// Replaces all metadata; an empty map clears it
+	Metadata *map[string]string
+}
+
+// ValidateReportMetadata checks user-supplied metadata against the size limits
+func ValidateReportMetadata(metadata map[string]string) error {
+	if len(metadata) > MaxReportMetadataEntries {
+		return fmt.Errorf("metadata may have at most %d entries", MaxReportMetadataEntries)
+	}
+	for key, value := range metadata {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("metadata keys must not be empty")
+		}
+		if len(key) > MaxReportMetadataKeyLen {
+			return fmt.Errorf("metadata key %q is longer than %d characters", key, MaxReportMetadataKeyLen)
+		}
+		if len(value) > MaxReportMetadataValueLen {
+			return fmt.Errorf("metadata value for %q is longer than %d characters", key, MaxReportMetadataValueLen)
+		}
+	}
+	return nil
+}
+
+// RecordingMetadata describes the recording session of an upload. It is
+// stored as report metadata under its JSON keys, so it can later be edited
+// like any other metadata.
+type RecordingMetadata struct {
+	SessionNotes        string `json:"session_notes,omitempty" example:"Morning session after medication"`
+	DeviceID            string `json:"device_id,omitempty" example:"OpenBCI-4F2A"`
+	ElectrodeMontage    string `json:"electrode_montage,omitempty" example:"10-20, 8 channels"`
+	RecordingConditions string `json:"recording_conditions,omitempty" example:"seated, eyes open, quiet room"`
+}
+
+// ParseRecordingMetadata decodes and validates the JSON metadata of an upload
+// and returns it as report metadata. Unknown fields are rejected and empty
+// fields are left out.
+func ParseRecordingMetadata(data []byte) (datatypes.JSON, error) {
+	var recording RecordingMetadata
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&recording); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("invalid metadata: unexpected data after the JSON object")
+	}
+
+	metadata := map[string]string{}
+	for key, value := range map[string]string{
+		"session_notes":        recording.SessionNotes,
+		"device_id":            recording.DeviceID,
+		"electrode_montage":    recording.ElectrodeMontage,
+		"recording_conditions": recording.RecordingConditions,
+	} {
+		if value = strings.TrimSpace(value); value != "" {
+			metadata[key] = value
+		}
+	}
+	if err := ValidateReportMetadata(metadata); err != nil {
+		return nil, err
+	}
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	return datatypes.JSON(encoded), nil
+}
+
+// likeEscaper escapes LIKE wildcards so user input matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 // BeforeSave automatically updates the UpdatedAt field
 func (r *Report) BeforeSave(tx *gorm.DB) (err error) {
 	r.UpdatedAt = time.Now()
@@ -34,13 +235,44 @@ func FindReportsByUserID(db *gorm.DB, userID uint) ([]Report, error) {
 
 // CreateReport creates a new report directly with the provided data
 func (r *Report) CreateReport(db *gorm.DB, userID uint) (*Report, error) {
-	if err := db.Create(r).Error; err != nil {
+	if err := ValidateContent(r.Content); err != nil {
+		return nil, fmt.Errorf("invalid report content: %w", err)
+	}
+	if err := validateTemplateContent(db, r); err != nil {
+		return nil, fmt.Errorf("invalid report notes: %w", err)
+	}
+	r.ContentSchemaVersion = CurrentContentSchemaVersion
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(r).Error; err != nil {
+			return err
+		}
+		if err := publishReportEvent(tx, EventReportCreated, r, nil); err != nil {
+			return err
+		}
+		// Reports translated during the upload are complete right away
+		if r.Description != "" {
+			return publishReportEvent(tx, EventTranslationCompleted, r, nil)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return r, nil
 }
 
+// FindReportIDForFile returns the ID of the first report created from a file,
+// or gorm.ErrRecordNotFound when the file has none yet
+func FindReportIDForFile(db *gorm.DB, fileID uint) (uint, error) {
+	var report Report
+	if err := db.Select("id").Where("source_file_id = ?", fileID).Order("id asc").First(&report).Error; err != nil {
+		return 0, err
+	}
+	return report.ID, nil
+}
+
 // FindReportByIDForUser finds a report by ID that belongs to a specific user
 func FindReportByIDForUser(db *gorm.DB, reportID uint, userID uint) (*Report, error) {
 	var report Report
@@ -51,8 +283,299 @@ func FindReportByIDForUser(db *gorm.DB, reportID uint, userID uint) (*Report, er
 	return &report, nil
 }
 
-// UpdateMatchingScale updates the matching scale for a report
+// PreloadSourceFile is a query scope that loads each report's source file
+func PreloadSourceFile(db *gorm.DB) *gorm.DB {
+	return db.Preload("SourceFile")
+}
+
+// FindReportByID finds a report by ID regardless of owner. Callers must check access.
+func FindReportByID(db *gorm.DB, reportID uint) (*Report, error) {
+	var report Report
+	if err := db.First(&report, reportID).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// UpdateMatchingScale updates the matching scale for a report, recording the
+// previous version as a revision
 func (r *Report) UpdateMatchingScale(db *gorm.DB, matchingScale int) error {
-	r.MatchingScale = matchingScale
-	return db.Model(r).Update("matching_scale", matchingScale).Error
+	next := *r
+	next.MatchingScale = matchingScale
+	return r.updateWithRevision(db, RevisionMatchingScale, next, map[string]interface{}{"matching_scale": matchingScale})
+}
+
+// UpdateTranslation stores a completed translation and the raw ML output it
+// was processed from, recording a replaced translation as a revision
+func (r *Report) UpdateTranslation(db *gorm.DB, description, rawDescription string) error {
+	next := *r
+	next.Description = description
+	if err := r.updateWithRevision(db, RevisionTranslation, next, map[string]interface{}{
+		"description":     description,
+		"raw_description": rawDescription,
+	}); err != nil {
+		return err
+	}
+	r.RawDescription = rawDescription
+	return nil
+}
+
+// MarkTranslationPending queues the report for translation by a dedicated worker
+func (r *Report) MarkTranslationPending(db *gorm.DB) error {
+	r.TranslationStatus = TranslationPending
+	return db.Model(r).Update("translation_status", TranslationPending).Error
+}
+
+// QueueRetranslation queues the report to be translated again, unless a
+// translation of it is already queued or running. It reports whether the
+// report was queued.
+func (r *Report) QueueRetranslation(db *gorm.DB) (bool, error) {
+	result := db.Model(&Report{}).
+		Where("id = ? AND (translation_status IS NULL OR translation_status NOT IN ?)", r.ID, []string{TranslationPending, TranslationRunning}).
+		Update("translation_status", TranslationPending)
+	if result.Error != nil {
+		return false, fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	r.TranslationStatus = TranslationPending
+	return true, nil
+}
+
+// ClaimTranslation marks the pending report as running, unless another worker
+// claimed it first
+func (r *Report) ClaimTranslation(db *gorm.DB) (bool, error) {
+	now := time.Now()
+	result := db.Model(&Report{}).
+		Where("id = ? AND translation_status = ?", r.ID, TranslationPending).
+		Updates(map[string]interface{}{"translation_status": TranslationRunning, "translation_claimed_at": now})
+	if result.Error != nil {
+		return false, fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	r.TranslationStatus = TranslationRunning
+	r.TranslationClaimedAt = &now
+	return true, nil
+}
+
+// FinishTranslation stores the outcome of a worker translation and the raw ML
+// output it was processed from, recording a replaced translation as a
+// revision. A failed translation keeps the report's current text.
+func (r *Report) FinishTranslation(db *gorm.DB, description, rawDescription string) error {
+	if description == "" {
+		if err := db.Model(r).Update("translation_status", TranslationFailed).Error; err != nil {
+			return err
+		}
+		r.TranslationStatus = TranslationFailed
+		return nil
+	}
+	status := TranslationCompleted
+	next := *r
+	next.Description = description
+	if err := r.updateWithRevision(db, RevisionTranslation, next, map[string]interface{}{
+		"description":        description,
+		"raw_description":    rawDescription,
+		"translation_status": status,
+	}); err != nil {
+		return err
+	}
+	r.RawDescription = rawDescription
+	r.TranslationStatus = status
+	return nil
+}
+
+// ClaimPendingTranslations marks up to limit pending or stale running reports
+// as running and returns them, oldest first. A report claimed by another
+// worker in the meantime is skipped.
+func ClaimPendingTranslations(db *gorm.DB, limit int) ([]Report, error) {
+	var pending []Report
+	err := db.Where("translation_status = ? OR (translation_status = ? AND (translation_claimed_at IS NULL OR translation_claimed_at < ?))",
+		TranslationPending, TranslationRunning, time.Now().Add(-TranslationStaleAfter)).
+		Order("created_at asc").Limit(limit).Find(&pending).Error
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := pending[:0]
+	for _, report := range pending {
+		now := time.Now()
+		query := db.Model(&Report{}).Where("id = ? AND translation_status = ?", report.ID, report.TranslationStatus)
+		if report.TranslationStatus == TranslationRunning {
+			if report.TranslationClaimedAt != nil {
+				query = query.Where("translation_claimed_at = ?", *report.TranslationClaimedAt)
+			} else {
+				query = query.Where("translation_claimed_at IS NULL")
+			}
+		}
+		result := query.Updates(map[string]interface{}{"translation_status": TranslationRunning, "translation_claimed_at": now})
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 1 {
+			report.TranslationStatus = TranslationRunning
+			report.TranslationClaimedAt = &now
+			claimed = append(claimed, report)
+		}
+	}
+	return claimed, nil
+}
+
+// ReleaseTranslations returns claimed reports that are still running to the
+// queue, so another worker picks them up
+func ReleaseTranslations(db *gorm.DB, ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := db.Model(&Report{}).
+		Where("id IN ? AND translation_status = ?", ids, TranslationRunning).
+		Update("translation_status", TranslationPending)
+	if result.Error != nil {
+		return 0, fmt.Errorf("database error: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// TranslationQueueStats describes the queue shared by all translation workers
+type TranslationQueueStats struct {
+	Pending int64
+	Running int64
+	// When the longest-waiting pending report was created; nil when none is pending
+	OldestPendingAt *time.Time
+}
+
+// CountTranslationQueue counts pending and running translations across all workers
+func CountTranslationQueue(db *gorm.DB) (TranslationQueueStats, error) {
+	var row struct {
+		Pending         int64
+		Running         int64
+		OldestPendingAt *time.Time
+	}
+	err := db.Model(&Report{}).
+		Select("COUNT(*) FILTER (WHERE translation_status = ?) AS pending, "+
+			"COUNT(*) FILTER (WHERE translation_status = ?) AS running, "+
+			"MIN(created_at) FILTER (WHERE translation_status = ?) AS oldest_pending_at",
+			TranslationPending, TranslationRunning, TranslationPending).
+		Where("translation_status IN ?", []string{TranslationPending, TranslationRunning}).
+		Scan(&row).Error
+	if err != nil {
+		return TranslationQueueStats{}, fmt.Errorf("database error: %w", err)
+	}
+	return TranslationQueueStats{Pending: row.Pending, Running: row.Running, OldestPendingAt: row.OldestPendingAt}, nil
+}
+
+// SetArchived archives or unarchives the report
+func (r *Report) SetArchived(db *gorm.DB, archived bool) error {
+	var archivedAt *time.Time
+	if archived {
+		now := time.Now()
+		archivedAt = &now
+	}
+	r.ArchivedAt = archivedAt
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(r).Update("archived_at", archivedAt).Error; err != nil {
+			return err
+		}
+		return publishReportEvent(tx, EventReportUpdated, r, []string{"archived_at"})
+	})
+}
+
+// Delete soft-deletes the report; it is purged for good by PurgeDeletedReports
+func (r *Report) Delete(db *gorm.DB) error {
+	return db.Delete(r).Error
+}
+
+// PurgeDeletedReports permanently removes reports soft-deleted before cutoff,
+// along with their source files and files deleted before cutoff, and returns
+// how many reports were removed
+func PurgeDeletedReports(db *gorm.DB, cutoff time.Time) (int64, error) {
+	var archived []Report
+	if err := db.Unscoped().Select("id, user_id").
+		Where("deleted_at IS NOT NULL AND deleted_at < ? AND content_archived_at IS NOT NULL", cutoff).
+		Find(&archived).Error; err != nil {
+		return 0, err
+	}
+
+	var purged int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		expired := tx.Unscoped().Model(&Report{}).Select("id").Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff)
+		if err := tx.Where("report_id IN (?)", expired).Delete(&ReportTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("report_id IN (?)", expired).Delete(&ReportShare{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("report_id IN (?)", expired).Delete(&ReportRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("report_id IN (?)", expired).Delete(&DeviceRecording{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("report_id IN (?)", expired).Delete(&ReportAccess{}).Error; err != nil {
+			return err
+		}
+		sourceFiles := tx.Unscoped().Model(&Report{}).Select("source_file_id").
+			Where("deleted_at IS NOT NULL AND deleted_at < ? AND source_file_id IS NOT NULL", cutoff)
+		expiredFiles := tx.Unscoped().Model(&SingleFile{}).Select("id").
+			Where("id IN (?) OR (deleted_at IS NOT NULL AND deleted_at < ?)", sourceFiles, cutoff)
+		if err := tx.Where("file_id IN (?)", expiredFiles).Delete(&UploadJob{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("id IN (?) OR (deleted_at IS NOT NULL AND deleted_at < ?)", sourceFiles, cutoff).Delete(&SingleFile{}).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Delete(&Report{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	if err == nil {
+		deleteArchivedContents(db.Statement.Context, archived)
+	}
+	return purged, err
+}
+
+// SetCorrection stores the user's correction of the translation
+func (r *Report) SetCorrection(db *gorm.DB, correction string) error {
+	r.Correction = correction
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(r).Update("correction", correction).Error; err != nil {
+			return err
+		}
+		return publishReportEvent(tx, EventReportUpdated, r, []string{"correction"})
+	})
+}
+
+// ApplyUpdate saves the fields set in update, recording the previous version
+// as a revision
+func (r *Report) ApplyUpdate(db *gorm.DB, update ReportUpdate) error {
+	next := *r
+	changes := map[string]interface{}{}
+	if update.Title != nil {
+		next.Title = *update.Title
+		changes["title"] = next.Title
+	}
+	if update.Description != nil {
+		next.Description = *update.Description
+		changes["description"] = next.Description
+	}
+	if update.Metadata != nil {
+		if err := ValidateReportMetadata(*update.Metadata); err != nil {
+			return err
+		}
+		next.Metadata = nil
+		if len(*update.Metadata) > 0 {
+			metadata, err := json.Marshal(*update.Metadata)
+			if err != nil {
+				return fmt.Errorf("failed to marshal metadata: %w", err)
+			}
+			next.Metadata = datatypes.JSON(metadata)
+		}
+		changes["metadata"] = next.Metadata
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return r.updateWithRevision(db, RevisionEdit, next, changes)
 }
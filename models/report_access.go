@@ -0,0 +1,108 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// How a report was read
+const (
+	AccessView       = "view"
+	AccessDownload   = "download"
+	AccessExport     = "export"
+	AccessSourceFile = "source_file"
+	AccessRevisions  = "revisions"
+	AccessSharedLink = "shared_link"
+	AccessList       = "list"
+	AccessSearch     = "search"
+	AccessSimilar    = "similar"
+)
+
+// ReportAccess records a single read of a report, so owners can see who
+// looked at their data. Reads through a share link have no viewer.
+type ReportAccess struct {
+	ID       uint  `gorm:"primaryKey;autoIncrement" json:"id"`
+	ReportID uint  `gorm:"not null;index" json:"report_id" example:"12"`
+	ViewerID *uint `gorm:"index" json:"viewer_id,omitempty" example:"4"`
+	// Name and email of the viewer at the time of listing; filled in by FindReportAccessPage
+	ViewerName  string    `gorm:"->;-:migration" json:"viewer_name,omitempty" example:"Dr. Jane Roe"`
+	ViewerEmail string    `gorm:"->;-:migration" json:"viewer_email,omitempty" example:"jane.roe@clinic.example"`
+	ShareID     *uint     `gorm:"index" json:"share_id,omitempty" example:"3"`
+	Action      string    `gorm:"type:varchar(20);not null" json:"action" example:"view"`
+	IPAddress   string    `gorm:"type:varchar(45)" json:"ip_address,omitempty" example:"203.0.113.7"`
+	CreatedAt   time.Time `gorm:"index" json:"created_at"`
+}
+
+// RecordReportAccess stores a read of a report
+func RecordReportAccess(db *gorm.DB, access *ReportAccess) error {
+	if err := db.Create(access).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// RecordReportAccesses stores reads of several reports, each described by
+// access, in one insert
+func RecordReportAccesses(db *gorm.DB, access ReportAccess, reportIDs []uint) error {
+	if len(reportIDs) == 0 {
+		return nil
+	}
+	accesses := make([]ReportAccess, len(reportIDs))
+	for i, id := range reportIDs {
+		accesses[i] = access
+		accesses[i].ReportID = id
+	}
+	if err := db.Create(&accesses).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// ReportIDs returns the IDs of reports
+func ReportIDs(reports []Report) []uint {
+	ids := make([]uint, len(reports))
+	for i := range reports {
+		ids[i] = reports[i].ID
+	}
+	return ids
+}
+
+// FindReportAccessPage retrieves a page of a report's access log, newest
+// first, with each viewer's name and email
+func FindReportAccessPage(db *gorm.DB, reportID uint, page Page) ([]ReportAccess, PageInfo, error) {
+	var info PageInfo
+
+	if err := db.Model(&ReportAccess{}).Where("report_id = ?", reportID).Count(&info.Total).Error; err != nil {
+		return nil, info, fmt.Errorf("failed to count report accesses: %w", err)
+	}
+
+	query := db.Model(&ReportAccess{}).
+		Select("report_accesses.*, users.name AS viewer_name, users.email AS viewer_email").
+		Joins("LEFT JOIN users ON users.id = report_accesses.viewer_id").
+		Where("report_accesses.report_id = ?", reportID)
+	if page.Cursor != "" {
+		var err error
+		if query, err = afterCursor(query, "report_accesses.created_at", "report_accesses.id", page.Cursor); err != nil {
+			return nil, info, err
+		}
+	} else if page.Offset > 0 {
+		query = query.Offset(page.Offset)
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	var accesses []ReportAccess
+	if err := query.Order("report_accesses.created_at desc, report_accesses.id desc").Limit(page.Limit + 1).Find(&accesses).Error; err != nil {
+		return nil, info, fmt.Errorf("failed to fetch report accesses: %w", err)
+	}
+
+	if len(accesses) > page.Limit {
+		accesses = accesses[:page.Limit]
+		info.HasMore = true
+		last := accesses[len(accesses)-1]
+		info.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return accesses, info, nil
+}
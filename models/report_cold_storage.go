@@ -0,0 +1,125 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ContentArchive holds report content moved out of Postgres into object storage
+type ContentArchive interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+var contentArchive atomic.Pointer[ContentArchive]
+
+// SetContentArchive installs the object store archived report content is read
+// from and written to. Call it at startup.
+func SetContentArchive(archive ContentArchive) {
+	contentArchive.Store(&archive)
+}
+
+// currentContentArchive returns the installed archive, or nil
+func currentContentArchive() ContentArchive {
+	if archive := contentArchive.Load(); archive != nil {
+		return *archive
+	}
+	return nil
+}
+
+// ContentArchiveKey is where a report's content is kept once archived
+func ContentArchiveKey(userID, reportID uint) string {
+	return fmt.Sprintf("reports/%d/%d.json", userID, reportID)
+}
+
+// ErrContentUnavailable is returned when archived report content cannot be
+// read back from cold storage
+var ErrContentUnavailable = errors.New("archived report content is unavailable")
+
+// LoadArchivedContent reads content moved to cold storage back into the
+// report. Reports are loaded without it, so call this only where the content
+// is needed; it does nothing when the content is already present.
+func (r *Report) LoadArchivedContent(ctx context.Context) error {
+	if r.ContentArchivedAt == nil || len(r.Content) > 0 {
+		return nil
+	}
+	archive := currentContentArchive()
+	if archive == nil {
+		return fmt.Errorf("%w: no content archive is configured", ErrContentUnavailable)
+	}
+	data, err := archive.Get(ctx, ContentArchiveKey(r.UserID, r.ID))
+	if err != nil {
+		return fmt.Errorf("%w: report %d: %v", ErrContentUnavailable, r.ID, err)
+	}
+	r.Content = datatypes.JSON(data)
+	r.upgradeContent()
+	return nil
+}
+
+// LoadArchivedContents reads the archived content of several reports back,
+// stopping at the first that cannot be read
+func LoadArchivedContents(ctx context.Context, reports []Report) error {
+	for i := range reports {
+		if err := reports[i].LoadArchivedContent(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindReportsToArchive returns up to limit reports created before cutoff whose
+// content is still in Postgres, in id order after afterID. Content under an
+// older schema is left until MigrateReportContents has upgraded it, so
+// archived content is always stored in the version recorded on the report.
+func FindReportsToArchive(db *gorm.DB, cutoff time.Time, afterID uint, limit int) ([]Report, error) {
+	var reports []Report
+	err := db.Unscoped().
+		Select("id, user_id, content, content_schema_version").
+		Where("created_at < ? AND content_archived_at IS NULL AND content IS NOT NULL AND content_schema_version = ? AND id > ?",
+			cutoff, CurrentContentSchemaVersion, afterID).
+		Order("id").Limit(limit).Find(&reports).Error
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return reports, nil
+}
+
+// MarkContentArchived drops the report's content from Postgres once it has
+// been written to the archive. It reports false when the report was archived
+// or cleared concurrently.
+func (r *Report) MarkContentArchived(db *gorm.DB) (bool, error) {
+	now := time.Now()
+	result := db.Unscoped().Model(&Report{}).
+		Where("id = ? AND content_archived_at IS NULL AND content_schema_version = ?", r.ID, r.ContentSchemaVersion).
+		Updates(map[string]interface{}{"content": nil, "content_archived_at": now})
+	if result.Error != nil {
+		return false, fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	r.ContentArchivedAt = &now
+	return true, nil
+}
+
+// deleteArchivedContents removes the archived content of the given reports.
+// Failures are logged; the rows no longer point at the objects.
+func deleteArchivedContents(ctx context.Context, reports []Report) {
+	archive := currentContentArchive()
+	if archive == nil {
+		return
+	}
+	for _, r := range reports {
+		if err := archive.Delete(ctx, ContentArchiveKey(r.UserID, r.ID)); err != nil {
+			log.Printf("Failed to delete archived content of report %d: %v", r.ID, err)
+		}
+	}
+}
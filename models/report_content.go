@@ -0,0 +1,204 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// CurrentContentSchemaVersion is the Report.Content schema written by this API.
+//
+//	0: content stored before schema versioning; any JSON object
+//	1: {"eeg": [[sample channels...]...], "mask": [one per sample], "impedance": [one per channel, optional], ...}
+//	   Reports made with a template also carry "notes": {"<section>": {"<field>": value}}
+//
+// To change the schema, bump the version, register an upgrader from the
+// previous version in contentUpgraders and update validateContent.
+const CurrentContentSchemaVersion = 1
+
+// contentUpgrader converts content from version N to N+1
+type contentUpgrader func(content map[string]interface{}) (map[string]interface{}, error)
+
+// contentUpgraders maps each version to the upgrader that produces the next one
+var contentUpgraders = map[int]contentUpgrader{
+	0: upgradeContentV0,
+}
+
+// upgradeContentV0 fills in the mask older uploads could omit, treating every sample as valid
+func upgradeContentV0(content map[string]interface{}) (map[string]interface{}, error) {
+	eeg, ok := content["eeg"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("missing eeg samples")
+	}
+	if _, ok := content["mask"]; !ok {
+		mask := make([]interface{}, len(eeg))
+		for i := range mask {
+			mask[i] = 1.0
+		}
+		content["mask"] = mask
+	}
+	return content, nil
+}
+
+// contentSchema holds the parts of the current schema that are validated.
+// Decoding into it rather than into a generic map keeps validating large
+// recordings to the memory of their samples.
+type contentSchema struct {
+	Eeg       [][]float64 `json:"eeg"`
+	Mask      []float64   `json:"mask"`
+	Impedance *[]float64  `json:"impedance"`
+}
+
+// ValidateContent checks that content matches the current schema
+func ValidateContent(content []byte) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(content, &object); err != nil || object == nil {
+		return fmt.Errorf("content must be a JSON object")
+	}
+	if _, ok := object["eeg"]; !ok {
+		return fmt.Errorf("eeg must be a non-empty array of samples")
+	}
+	if _, ok := object["mask"]; !ok {
+		return fmt.Errorf("mask must be an array of numbers")
+	}
+
+	var decoded contentSchema
+	if err := json.Unmarshal(object["eeg"], &decoded.Eeg); err != nil {
+		return fmt.Errorf("eeg must be a non-empty array of samples, each an array of numbers")
+	}
+	if err := json.Unmarshal(object["mask"], &decoded.Mask); err != nil || decoded.Mask == nil {
+		return fmt.Errorf("mask must be an array of numbers")
+	}
+	if raw, present := object["impedance"]; present {
+		if err := json.Unmarshal(raw, &decoded.Impedance); err != nil || decoded.Impedance == nil {
+			return fmt.Errorf("impedance must be an array of numbers")
+		}
+	}
+	return validateContent(&decoded)
+}
+
+// validateContent checks decoded content against the current schema
+func validateContent(content *contentSchema) error {
+	eeg := content.Eeg
+	if len(eeg) == 0 {
+		return fmt.Errorf("eeg must be a non-empty array of samples")
+	}
+	channels := -1
+	for i, values := range eeg {
+		if values == nil {
+			return fmt.Errorf("eeg sample %d must be an array of numbers", i)
+		}
+		if channels >= 0 && len(values) != channels {
+			return fmt.Errorf("eeg sample %d has %d channels, expected %d", i, len(values), channels)
+		}
+		channels = len(values)
+	}
+
+	if len(content.Mask) != len(eeg) {
+		return fmt.Errorf("mask has %d entries, expected one per sample (%d)", len(content.Mask), len(eeg))
+	}
+
+	if content.Impedance != nil && len(*content.Impedance) != channels {
+		return fmt.Errorf("impedance has %d entries, expected one per channel (%d)", len(*content.Impedance), channels)
+	}
+	return nil
+}
+
+// UpgradeContent converts content stored at version to the current schema,
+// returning it unchanged if it is already current
+func UpgradeContent(content datatypes.JSON, version int) (datatypes.JSON, error) {
+	if version == CurrentContentSchemaVersion || len(content) == 0 || string(content) == "null" {
+		return content, nil
+	}
+	if version > CurrentContentSchemaVersion {
+		return nil, fmt.Errorf("content schema version %d is newer than this API supports (%d)", version, CurrentContentSchemaVersion)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		return nil, fmt.Errorf("content is not a JSON object: %w", err)
+	}
+	for v := version; v < CurrentContentSchemaVersion; v++ {
+		upgrade, ok := contentUpgraders[v]
+		if !ok {
+			return nil, fmt.Errorf("no upgrade from content schema version %d", v)
+		}
+		var err error
+		if decoded, err = upgrade(decoded); err != nil {
+			return nil, fmt.Errorf("upgrading content from version %d: %w", v, err)
+		}
+	}
+
+	upgraded, err := json.Marshal(decoded)
+	if err != nil {
+		return nil, err
+	}
+	return datatypes.JSON(upgraded), nil
+}
+
+// AfterFind serves older reports in the current content schema. Content moved
+// to cold storage is not loaded here but by LoadArchivedContent, which
+// upgrades it once read back.
+func (r *Report) AfterFind(tx *gorm.DB) error {
+	r.upgradeContent()
+	return nil
+}
+
+// upgradeContent converts loaded content to the current schema. Reports that
+// cannot be upgraded are left as stored.
+func (r *Report) upgradeContent() {
+	if r.ContentSchemaVersion == CurrentContentSchemaVersion || len(r.Content) == 0 {
+		return
+	}
+	content, err := UpgradeContent(r.Content, r.ContentSchemaVersion)
+	if err != nil {
+		log.Printf("Report %d content left at schema version %d: %v", r.ID, r.ContentSchemaVersion, err)
+		return
+	}
+	r.Content = content
+	r.ContentSchemaVersion = CurrentContentSchemaVersion
+}
+
+// MigrateReportContents rewrites stored reports older than the current content
+// schema, batchSize at a time, and returns how many were upgraded
+func MigrateReportContents(ctx context.Context, db *gorm.DB, batchSize int) (int, error) {
+	upgraded := 0
+	lastID := uint(0)
+	for ctx.Err() == nil {
+		var stale []struct {
+			ID                   uint
+			Content              datatypes.JSON
+			ContentSchemaVersion int
+		}
+		err := db.Model(&Report{}).
+			Select("id, content, content_schema_version").
+			Where("content_schema_version < ? AND content_archived_at IS NULL AND id > ?", CurrentContentSchemaVersion, lastID).
+			Order("id").Limit(batchSize).Scan(&stale).Error
+		if err != nil {
+			return upgraded, err
+		}
+		if len(stale) == 0 {
+			break
+		}
+
+		for _, report := range stale {
+			lastID = report.ID
+			content, err := UpgradeContent(report.Content, report.ContentSchemaVersion)
+			if err != nil {
+				log.Printf("Report %d content left at schema version %d: %v", report.ID, report.ContentSchemaVersion, err)
+				continue
+			}
+			err = db.Model(&Report{}).Where("id = ? AND content_schema_version = ?", report.ID, report.ContentSchemaVersion).
+				Updates(map[string]interface{}{"content": content, "content_schema_version": CurrentContentSchemaVersion}).Error
+			if err != nil {
+				return upgraded, err
+			}
+			upgraded++
+		}
+	}
+	return upgraded, nil
+}
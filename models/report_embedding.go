@@ -0,0 +1,168 @@
+package models
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReportEmbeddingsTable stores one pgvector embedding of the translated text per
+// report. It is created with raw SQL because gorm has no vector type.
+const ReportEmbeddingsTable = "report_embeddings"
+
+// ReportEmbeddingFailure records that embedding a report's text failed, so
+// the indexer retries it with backoff instead of on every run. Editing the
+// text or changing the model retries it right away.
+type ReportEmbeddingFailure struct {
+	ReportID      uint      `gorm:"primaryKey;autoIncrement:false" json:"report_id"`
+	Report        *Report   `gorm:"foreignKey:ReportID;constraint:OnDelete:CASCADE" json:"-" swaggerignore:"true"`
+	Model         string    `gorm:"type:varchar(100);not null" json:"model"`
+	Digest        string    `gorm:"type:varchar(32);not null" json:"digest"`
+	Attempts      int       `gorm:"not null;default:0" json:"attempts"`
+	LastError     string    `gorm:"type:text" json:"last_error"`
+	NextAttemptAt time.Time `gorm:"index" json:"next_attempt_at"`
+}
+
+// SimilarReport is a report with its cosine similarity to another report,
+// from -1 to 1 where 1 means the same direction
+type SimilarReport struct {
+	Report
+	Similarity float64 `json:"similarity" example:"0.83"`
+}
+
+// ReportTextDigest fingerprints the text an embedding was computed from, so
+// edited and re-translated reports are embedded again
+func ReportTextDigest(text string) string {
+	sum := md5.Sum([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// FindReportsToEmbed returns up to limit reports with translated text that has
+// no embedding from model yet or changed since it was embedded. Reports of
+// users encrypting their report text are skipped, as are reports whose text
+// failed to embed with model until their next attempt is due.
+func FindReportsToEmbed(db *gorm.DB, model string, limit int) ([]Report, error) {
+	var reports []Report
+	err := db.
+		Joins("LEFT JOIN report_embeddings e ON e.report_id = reports.id").
+		Where("reports.description <> ''").
+		Where("reports.user_id NOT IN (?)", db.Model(&User{}).Select("id").Where("encrypt_report_text")).
+		Where("e.report_id IS NULL OR e.model <> ? OR e.digest <> md5(reports.description)", model).
+		Where("NOT EXISTS (?)", db.Model(&ReportEmbeddingFailure{}).Select("1").
+			Where("report_embedding_failures.report_id = reports.id AND report_embedding_failures.model = ?", model).
+			Where("report_embedding_failures.digest = md5(reports.description) AND report_embedding_failures.next_attempt_at > ?", time.Now())).
+		Order("reports.id").
+		Limit(limit).
+		Find(&reports).Error
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return reports, nil
+}
+
+// SetReportEmbedding stores the embedding of the report's text, replacing any
+// earlier one, and forgets earlier failures to embed it
+func SetReportEmbedding(db *gorm.DB, report *Report, model string, vector []float32) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`INSERT INTO report_embeddings (report_id, user_id, model, digest, embedding, updated_at)
+		VALUES (?, ?, ?, ?, ?::vector, now())
+		ON CONFLICT (report_id) DO UPDATE SET
+			model = EXCLUDED.model, digest = EXCLUDED.digest,
+			embedding = EXCLUDED.embedding, updated_at = EXCLUDED.updated_at`,
+			report.ID, report.UserID, model, ReportTextDigest(report.Description), vectorLiteral(vector)).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("report_id = ?", report.ID).Delete(&ReportEmbeddingFailure{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// RecordReportEmbeddingFailure records a failed attempt to embed the report's
+// text with model and schedules the next one after backoff(attempts), where
+// attempts counts the failures of this text with this model so far
+func RecordReportEmbeddingFailure(db *gorm.DB, report *Report, model string, embedErr error, backoff func(attempts int) time.Duration) error {
+	digest := ReportTextDigest(report.Description)
+	failure := ReportEmbeddingFailure{ReportID: report.ID}
+	err := db.Where("report_id = ?", report.ID).Limit(1).Find(&failure).Error
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if failure.Model != model || failure.Digest != digest {
+		failure.Attempts = 0
+	}
+	failure.Model = model
+	failure.Digest = digest
+	failure.Attempts++
+	failure.LastError = embedErr.Error()
+	failure.NextAttemptAt = time.Now().Add(backoff(failure.Attempts))
+	if err := db.Save(&failure).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// HasCurrentEmbedding reports whether the report's current text has an
+// embedding from model
+func HasCurrentEmbedding(db *gorm.DB, report *Report, model string) (bool, error) {
+	var count int64
+	err := db.Table(ReportEmbeddingsTable).
+		Where("report_id = ? AND model = ? AND digest = ?", report.ID, model, ReportTextDigest(report.Description)).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("database error: %w", err)
+	}
+	return count > 0, nil
+}
+
+// FindSimilarReports returns up to limit of the owner's other reports whose
+// embeddings from model are closest to the report's, most similar first
+func FindSimilarReports(db *gorm.DB, report *Report, model string, limit int) ([]SimilarReport, error) {
+	var similar []SimilarReport
+	err := db.Model(&Report{}).
+		Select("reports.*, 1 - (e.embedding <=> target.embedding) AS similarity").
+		Joins("JOIN report_embeddings e ON e.report_id = reports.id").
+		Joins("JOIN report_embeddings target ON target.report_id = ? AND target.model = e.model", report.ID).
+		Where("reports.user_id = ? AND reports.id <> ? AND e.model = ?", report.UserID, report.ID, model).
+		Order("e.embedding <=> target.embedding").
+		Limit(limit).
+		Find(&similar).Error
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return similar, nil
+}
+
+// DeleteUserEmbeddings removes the embeddings of a user's reports, e.g. when
+// they turn on report text encryption
+func DeleteUserEmbeddings(db *gorm.DB, userID uint) error {
+	if !db.Migrator().HasTable(ReportEmbeddingsTable) {
+		return nil
+	}
+	if err := db.Exec("DELETE FROM report_embeddings WHERE user_id = ?", userID).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// vectorLiteral formats a vector in pgvector's text form, e.g. [0.1,0.2]
+func vectorLiteral(vector []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, v := range vector {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(v), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
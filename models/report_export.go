@@ -0,0 +1,256 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Report export statuses
+const (
+	ExportPending   = "pending"
+	ExportRunning   = "running"
+	ExportCompleted = "completed"
+	ExportFailed    = "failed"
+)
+
+// ReportExport is a bulk export of a user's reports generated in the
+// background. The finished file is kept until ExpiresAt; failed exports are
+// forgotten at the same time.
+type ReportExport struct {
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	// The user who requested the export and may download it
+	RequestedBy uint `gorm:"not null;index" json:"requested_by"`
+	// Whose reports are exported
+	OwnerID uint           `gorm:"not null" json:"owner_id"`
+	Format  string         `gorm:"type:varchar(10);not null" json:"format" example:"csv"`
+	Columns string         `gorm:"type:text;not null" json:"columns,omitempty" example:"id,title,created_at"`
+	Filter  datatypes.JSON `gorm:"type:json" json:"-"`
+	Status  string         `gorm:"type:varchar(20);not null;default:'pending';index" json:"status" example:"completed"`
+	// Reports written so far out of the reports matching when it was requested
+	RowCount  int `gorm:"not null;default:0" json:"row_count" example:"600"`
+	TotalRows int `gorm:"not null;default:0" json:"total_rows" example:"1200"`
+	// Percentage of the reports written
+	Progress    int        `gorm:"-" json:"progress" example:"50"`
+	FilePath    string     `gorm:"type:text" json:"-"`
+	LastError   string     `gorm:"type:text" json:"last_error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `gorm:"index" json:"expires_at,omitempty"`
+}
+
+// AfterFind fills in the derived fields
+func (e *ReportExport) AfterFind(tx *gorm.DB) error {
+	e.setProgress()
+	return nil
+}
+
+func (e *ReportExport) setProgress() {
+	switch {
+	case e.Status == ExportCompleted:
+		e.Progress = 100
+	case e.TotalRows > 0:
+		e.Progress = min(99, e.RowCount*100/e.TotalRows)
+	default:
+		e.Progress = 0
+	}
+}
+
+// CreateReportExport stores a new pending export of the reports matching filter
+func CreateReportExport(db *gorm.DB, e *ReportExport, filter ReportFilter) error {
+	encoded, err := json.Marshal(filter)
+	if err != nil {
+		return fmt.Errorf("failed to marshal filter: %w", err)
+	}
+	e.Filter = datatypes.JSON(encoded)
+	e.Status = ExportPending
+	if err := db.Create(e).Error; err != nil {
+		return fmt.Errorf("failed to create report export: %w", err)
+	}
+	return nil
+}
+
+// ReportFilter decodes the filter the export was requested with
+func (e *ReportExport) ReportFilter() (ReportFilter, error) {
+	var filter ReportFilter
+	if len(e.Filter) == 0 {
+		return filter, nil
+	}
+	err := json.Unmarshal(e.Filter, &filter)
+	return filter, err
+}
+
+// FindReportExportForUser retrieves an export requested by the user
+func FindReportExportForUser(db *gorm.DB, id, userID uint) (*ReportExport, error) {
+	var e ReportExport
+	if err := db.Where("id = ? AND requested_by = ?", id, userID).First(&e).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("report export not found")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &e, nil
+}
+
+// FindReportExportsPage lists the exports requested by the user, newest first
+func FindReportExportsPage(db *gorm.DB, userID uint, page Page) ([]ReportExport, PageInfo, error) {
+	var info PageInfo
+
+	if err := db.Model(&ReportExport{}).Where("requested_by = ?", userID).Count(&info.Total).Error; err != nil {
+		return nil, info, fmt.Errorf("failed to count exports: %w", err)
+	}
+
+	query := db.Where("requested_by = ?", userID)
+	if page.Cursor != "" {
+		var err error
+		if query, err = afterCursor(query, "created_at", "id", page.Cursor); err != nil {
+			return nil, info, err
+		}
+	} else if page.Offset > 0 {
+		query = query.Offset(page.Offset)
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	var exports []ReportExport
+	if err := query.Order("created_at desc, id desc").Limit(page.Limit + 1).Find(&exports).Error; err != nil {
+		return nil, info, fmt.Errorf("failed to fetch exports: %w", err)
+	}
+
+	if len(exports) > page.Limit {
+		exports = exports[:page.Limit]
+		info.HasMore = true
+		last := exports[len(exports)-1]
+		info.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return exports, info, nil
+}
+
+// FindReportExport retrieves an export by its ID
+func FindReportExport(db *gorm.DB, id uint) (*ReportExport, error) {
+	var e ReportExport
+	if err := db.First(&e, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("report export not found")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &e, nil
+}
+
+// ClaimPendingReportExport atomically moves the oldest pending export to
+// running, or returns nil if none is pending
+func ClaimPendingReportExport(db *gorm.DB) (*ReportExport, error) {
+	var e ReportExport
+	if err := db.Where("status = ?", ExportPending).Order("created_at asc").First(&e).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// Only one worker wins the transition
+	now := time.Now()
+	result := db.Model(&ReportExport{}).Where("id = ? AND status = ?", e.ID, ExportPending).Updates(map[string]interface{}{
+		"status":     ExportRunning,
+		"started_at": now,
+	})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ClaimPendingReportExport(db)
+	}
+
+	e.Status = ExportRunning
+	e.StartedAt = &now
+	return &e, nil
+}
+
+// RecordProgress stores how many reports a running export has written
+func (e *ReportExport) RecordProgress(db *gorm.DB, rows int) error {
+	e.RowCount = rows
+	e.setProgress()
+	return db.Model(e).UpdateColumn("row_count", rows).Error
+}
+
+// Finish records the outcome of a running export. A completed export stays
+// downloadable for ttl; a failed one is listed for as long.
+func (e *ReportExport) Finish(db *gorm.DB, filePath string, rows int, exportErr error, ttl time.Duration) error {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	e.CompletedAt = &now
+	e.ExpiresAt = &expiresAt
+	e.RowCount = rows
+	if exportErr != nil {
+		e.Status = ExportFailed
+		e.LastError = exportErr.Error()
+	} else {
+		e.Status = ExportCompleted
+		e.FilePath = filePath
+	}
+	e.setProgress()
+	return db.Model(e).Updates(map[string]interface{}{
+		"status":       e.Status,
+		"row_count":    e.RowCount,
+		"file_path":    e.FilePath,
+		"last_error":   e.LastError,
+		"completed_at": e.CompletedAt,
+		"expires_at":   e.ExpiresAt,
+	}).Error
+}
+
+// Expired reports whether the export's file is no longer available
+func (e *ReportExport) Expired(now time.Time) bool {
+	return e.ExpiresAt != nil && !now.Before(*e.ExpiresAt)
+}
+
+// SignDownload returns the signature of a link that downloads the export's
+// file without authentication until expires
+func (e *ReportExport) SignDownload(secret []byte, expires time.Time) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("report-export:" + strconv.FormatUint(uint64(e.ID), 10) + ":" + strconv.FormatInt(expires.Unix(), 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyDownload reports whether a download link signature is genuine and
+// the link has not expired
+func (e *ReportExport) VerifyDownload(secret []byte, expires int64, signature string, now time.Time) bool {
+	if now.Unix() >= expires {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(e.SignDownload(secret, time.Unix(expires, 0))))
+}
+
+// FindExpiredReportExports returns exports whose files have expired
+func FindExpiredReportExports(db *gorm.DB, now time.Time) ([]ReportExport, error) {
+	var exports []ReportExport
+	err := db.Where("expires_at IS NOT NULL AND expires_at <= ?", now).Find(&exports).Error
+	return exports, err
+}
+
+// DeleteReportExport removes the export record
+func DeleteReportExport(db *gorm.DB, e *ReportExport) error {
+	return db.Delete(e).Error
+}
+
+// CountFilteredReports counts the owner's reports matching filter
+func CountFilteredReports(db *gorm.DB, ownerID uint, filter ReportFilter) (int64, error) {
+	var count int64
+	err := filter.Apply(db.Model(&Report{}).Where("user_id = ?", ownerID)).Count(&count).Error
+	return count, err
+}
+
+// FilteredReportsQuery selects the owner's reports matching filter, for
+// reading in batches in ID order
+func FilteredReportsQuery(db *gorm.DB, ownerID uint, filter ReportFilter) *gorm.DB {
+	return filter.Apply(db.Model(&Report{}).Where("user_id = ?", ownerID))
+}
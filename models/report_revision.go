@@ -0,0 +1,141 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Kinds of change recorded in a report's revision history
+const (
+	RevisionMatchingScale = "matching_scale" // matching scale updated
+	RevisionEdit          = "edit"           // title, description or metadata edited
+	RevisionTranslation   = "translation"    // description replaced by a new translation
+	RevisionRestore       = "restore"        // an earlier revision restored
+)
+
+// ReportRevision is a version of a report that a change replaced. Restoring it
+// puts its values back. Description is stored as the report stores it, so it
+// is encrypted for users who encrypt their report text.
+type ReportRevision struct {
+	ID       uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	ReportID uint `gorm:"not null;index" json:"report_id"`
+	// The change that replaced this version
+	Change        string         `gorm:"type:varchar(20);not null" json:"change" example:"matching_scale"`
+	Title         string         `gorm:"type:varchar(255);not null" json:"title"`
+	Description   string         `gorm:"type:text" json:"description"`
+	MatchingScale int            `gorm:"type:int;not null;default:0" json:"matching_scale"`
+	Metadata      datatypes.JSON `gorm:"type:json" json:"metadata,omitempty" swaggertype:"object,string" example:"session:morning"`
+	CreatedAt     time.Time      `gorm:"index" json:"created_at"`
+}
+
+// saveRevision records the report's current values before change replaces
+// them with next, unless they are the same
+func (r *Report) saveRevision(tx *gorm.DB, change string, next Report) error {
+	if r.Title == next.Title && r.Description == next.Description && r.MatchingScale == next.MatchingScale &&
+		bytes.Equal(r.Metadata, next.Metadata) {
+		return nil
+	}
+	// The first translation of a new report replaces nothing worth restoring
+	if change == RevisionTranslation && r.Description == "" {
+		return nil
+	}
+	revision := ReportRevision{
+		ReportID:      r.ID,
+		Change:        change,
+		Title:         r.Title,
+		Description:   r.Description,
+		MatchingScale: r.MatchingScale,
+		Metadata:      r.Metadata,
+	}
+	if err := tx.Create(&revision).Error; err != nil {
+		return fmt.Errorf("failed to record report revision: %w", err)
+	}
+	return nil
+}
+
+// updateWithRevision records the report's current values as a revision, then
+// applies changes, publishes the matching webhook event and copies next into
+// the report
+func (r *Report) updateWithRevision(db *gorm.DB, change string, next Report, changes map[string]interface{}) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := r.saveRevision(tx, change, next); err != nil {
+			return err
+		}
+		if err := tx.Model(r).Updates(changes).Error; err != nil {
+			return err
+		}
+		return publishReportChange(tx, change, next, changes)
+	})
+	if err != nil {
+		return err
+	}
+	r.Title = next.Title
+	r.Description = next.Description
+	r.MatchingScale = next.MatchingScale
+	r.Metadata = next.Metadata
+	return nil
+}
+
+// FindReportRevisionsPage retrieves one page of the report's revisions, newest
+// first. A cursor takes precedence over the offset.
+func FindReportRevisionsPage(db *gorm.DB, reportID uint, page Page) ([]ReportRevision, PageInfo, error) {
+	var info PageInfo
+
+	if err := db.Model(&ReportRevision{}).Where("report_id = ?", reportID).Count(&info.Total).Error; err != nil {
+		return nil, info, fmt.Errorf("failed to count revisions: %w", err)
+	}
+
+	query := db.Where("report_id = ?", reportID)
+	if page.Cursor != "" {
+		var err error
+		if query, err = afterCursor(query, "created_at", "id", page.Cursor); err != nil {
+			return nil, info, err
+		}
+	} else if page.Offset > 0 {
+		query = query.Offset(page.Offset)
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	var revisions []ReportRevision
+	if err := query.Order("created_at desc, id desc").Limit(page.Limit + 1).Find(&revisions).Error; err != nil {
+		return nil, info, fmt.Errorf("failed to fetch revisions: %w", err)
+	}
+
+	if len(revisions) > page.Limit {
+		revisions = revisions[:page.Limit]
+		info.HasMore = true
+		last := revisions[len(revisions)-1]
+		info.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return revisions, info, nil
+}
+
+// FindReportRevision finds one of the report's revisions
+func FindReportRevision(db *gorm.DB, reportID, revisionID uint) (*ReportRevision, error) {
+	var revision ReportRevision
+	if err := db.Where("id = ? AND report_id = ?", revisionID, reportID).First(&revision).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// RestoreRevision puts the revision's values back on the report, recording the
+// values it replaces as a new revision so the restore can be undone
+func (r *Report) RestoreRevision(db *gorm.DB, revision *ReportRevision) error {
+	next := *r
+	next.Title = revision.Title
+	next.Description = revision.Description
+	next.MatchingScale = revision.MatchingScale
+	next.Metadata = revision.Metadata
+	return r.updateWithRevision(db, RevisionRestore, next, map[string]interface{}{
+		"title":          revision.Title,
+		"description":    revision.Description,
+		"matching_scale": revision.MatchingScale,
+		"metadata":       revision.Metadata,
+	})
+}
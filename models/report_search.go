@@ -0,0 +1,56 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReportSearchResult is a report matching a full-text search, with its rank and
+// the matching passages highlighted with <mark> tags
+type ReportSearchResult struct {
+	ID            uint      `json:"id" example:"12"`
+	Title         string    `json:"title" example:"session-2026-03-14.json"`
+	Description   string    `json:"description" example:"I would like a cup of coffee"`
+	MatchingScale int       `json:"matching_scale" example:"7"`
+	CreatedAt     time.Time `json:"created_at"`
+	Rank          float32   `json:"rank" example:"0.6079"`
+	Highlight     string    `json:"highlight" example:"I would like a cup of <mark>coffee</mark>"`
+}
+
+// searchQuery parses user input with web-search syntax: quoted phrases, "or" and -exclusions
+const searchQuery = "websearch_to_tsquery('english', ?)"
+
+// SearchUserReports runs a ranked full-text search over a user's report titles,
+// descriptions and translated content, including archived reports. Only offset
+// pagination is supported.
+func SearchUserReports(db *gorm.DB, userID uint, text string, page Page) ([]ReportSearchResult, PageInfo, error) {
+	var info PageInfo
+
+	match := func() *gorm.DB {
+		return db.Table("reports").Where("user_id = ? AND deleted_at IS NULL AND search_vector @@ "+searchQuery, userID, text)
+	}
+
+	if err := match().Count(&info.Total).Error; err != nil {
+		return nil, info, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	var results []ReportSearchResult
+	err := match().
+		Select("id, title, description, matching_scale, created_at, "+
+			"ts_rank(search_vector, "+searchQuery+") AS rank, "+
+			"ts_headline('english', concat_ws(' ', description, content->>'translation'), "+searchQuery+", "+
+			"'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') AS highlight",
+			text, text).
+		Order("rank desc, created_at desc").
+		Offset(page.Offset).
+		Limit(page.Limit).
+		Scan(&results).Error
+	if err != nil {
+		return nil, info, fmt.Errorf("failed to search reports: %w", err)
+	}
+
+	info.HasMore = int64(page.Offset+len(results)) < info.Total
+	return results, info, nil
+}
@@ -0,0 +1,206 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Limits on report share links
+const (
+	MaxShareTTL            = 30 * 24 * time.Hour
+	MaxSharePasswordTries  = 10
+	MinSharePasswordLength = 6
+)
+
+// ErrShareNotFound is returned for share tokens that are invalid, revoked or expired
+var ErrShareNotFound = fmt.Errorf("share link not found or expired")
+
+// Errors of CheckPassword
+var (
+	ErrSharePasswordMissing = fmt.Errorf("this share link requires a password")
+	ErrSharePasswordWrong   = fmt.Errorf("wrong share password")
+	ErrShareLocked          = fmt.Errorf("share link is locked after too many wrong passwords")
+)
+
+// ReportShare is a public, read-only link to a report. The link carries a token
+// signed with the server's share secret; revoking the share or reaching
+// ExpiresAt disables it.
+type ReportShare struct {
+	ID       uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	ReportID uint   `gorm:"not null;index" json:"report_id"`
+	OwnerID  uint   `gorm:"not null;index" json:"owner_id"`
+	Nonce    string `gorm:"type:varchar(64);not null" json:"-"`
+	// Set when the link is password protected; only the bcrypt hash is stored
+	PasswordHash      string     `gorm:"type:text" json:"-"`
+	PasswordProtected bool       `gorm:"-" json:"password_protected"`
+	FailedAttempts    int        `gorm:"not null;default:0" json:"-"`
+	ExpiresAt         time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt         *time.Time `json:"revoked_at,omitempty"`
+	ViewCount         int        `gorm:"not null;default:0" json:"view_count"`
+	LastViewedAt      *time.Time `json:"last_viewed_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// AfterFind fills in the derived fields
+func (s *ReportShare) AfterFind(tx *gorm.DB) error {
+	s.PasswordProtected = s.PasswordHash != ""
+	return nil
+}
+
+// CreateReportShare creates a share link for the report that expires after ttl.
+// An empty password leaves the link unprotected.
+func CreateReportShare(db *gorm.DB, report *Report, ttl time.Duration, password string) (*ReportShare, error) {
+	if ttl <= 0 || ttl > MaxShareTTL {
+		return nil, fmt.Errorf("share links must expire within %d days", int(MaxShareTTL.Hours()/24))
+	}
+
+	nonce, err := utils.GenerateSecureToken(16)
+	if err != nil {
+		return nil, err
+	}
+	share := &ReportShare{
+		ReportID:  report.ID,
+		OwnerID:   report.UserID,
+		Nonce:     nonce,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if password != "" {
+		if len(password) < MinSharePasswordLength {
+			return nil, fmt.Errorf("share password must be at least %d characters", MinSharePasswordLength)
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash password: %w", err)
+		}
+		share.PasswordHash = string(hash)
+		share.PasswordProtected = true
+	}
+
+	if err := db.Create(share).Error; err != nil {
+		return nil, fmt.Errorf("failed to create share link: %w", err)
+	}
+	return share, nil
+}
+
+// Token returns the signed token identifying the share in its public link
+func (s *ReportShare) Token(secret []byte) string {
+	payload := strconv.FormatUint(uint64(s.ID), 10) + "." + s.Nonce
+	return payload + "." + signShare(secret, payload)
+}
+
+// FindReportShareByToken verifies a share token and returns the share if it is
+// still active
+func FindReportShareByToken(db *gorm.DB, secret []byte, token string) (*ReportShare, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrShareNotFound
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(signShare(secret, payload))) {
+		return nil, ErrShareNotFound
+	}
+	id, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return nil, ErrShareNotFound
+	}
+
+	var share ReportShare
+	err = db.Where("id = ? AND nonce = ? AND revoked_at IS NULL AND expires_at > ?", id, parts[1], time.Now()).First(&share).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrShareNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &share, nil
+}
+
+// CheckPassword verifies the password of a protected share. Each try is
+// counted before the password is compared, in one conditional update, so
+// concurrent guesses cannot exceed MaxSharePasswordTries; a right password
+// gets its try back. A missing password is not counted.
+func (s *ReportShare) CheckPassword(db *gorm.DB, password string) error {
+	if s.PasswordHash == "" {
+		return nil
+	}
+	if password == "" {
+		return ErrSharePasswordMissing
+	}
+
+	result := db.Model(&ReportShare{}).
+		Where("id = ? AND failed_attempts < ?", s.ID, MaxSharePasswordTries).
+		UpdateColumn("failed_attempts", gorm.Expr("failed_attempts + 1"))
+	if result.Error != nil {
+		return fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrShareLocked
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(s.PasswordHash), []byte(password)) != nil {
+		return ErrSharePasswordWrong
+	}
+	if err := db.Model(&ReportShare{}).Where("id = ?", s.ID).
+		UpdateColumn("failed_attempts", gorm.Expr("failed_attempts - 1")).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// RecordView counts a view of the share
+func (s *ReportShare) RecordView(db *gorm.DB) error {
+	now := time.Now()
+	s.ViewCount++
+	s.LastViewedAt = &now
+	return db.Model(s).UpdateColumns(map[string]interface{}{
+		"view_count":     gorm.Expr("view_count + 1"),
+		"last_viewed_at": now,
+	}).Error
+}
+
+// IsActive reports whether the share link can currently be opened
+func (s *ReportShare) IsActive() bool {
+	return s.RevokedAt == nil && s.ExpiresAt.After(time.Now())
+}
+
+// Revoke disables the share link
+func (s *ReportShare) Revoke(db *gorm.DB) error {
+	if s.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	s.RevokedAt = &now
+	return db.Model(s).Update("revoked_at", now).Error
+}
+
+// FindReportShares lists the report's share links, newest first
+func FindReportShares(db *gorm.DB, reportID uint) ([]ReportShare, error) {
+	var shares []ReportShare
+	err := db.Where("report_id = ?", reportID).Order("created_at desc").Find(&shares).Error
+	return shares, err
+}
+
+// FindReportShare finds one of the report's share links
+func FindReportShare(db *gorm.DB, reportID, shareID uint) (*ReportShare, error) {
+	var share ReportShare
+	if err := db.Where("id = ? AND report_id = ?", shareID, reportID).First(&share).Error; err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// signShare returns the URL-safe HMAC-SHA256 signature of a share token payload
+func signShare(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("report-share:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,354 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Types of template fields
+const (
+	TemplateFieldText    = "text"
+	TemplateFieldNumber  = "number"
+	TemplateFieldBoolean = "boolean"
+	TemplateFieldChoice  = "choice"
+)
+
+// Limits on report templates
+const (
+	MaxTemplateSections     = 20
+	MaxTemplateFields       = 50
+	MaxTemplateChoices      = 50
+	MaxTemplateTextLength   = 5000
+	MaxTemplateLabelLength  = 200
+	MaxTemplateNameLength   = 100
+	maxTemplateKeyLength    = 64
+	templateNotesContentKey = "notes"
+)
+
+// ErrTemplateNameTaken is returned when another template already uses the name
+var ErrTemplateNameTaken = errors.New("a report template with this name already exists")
+
+// templateKeyPattern matches section and field keys
+var templateKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// TemplateField is one entry of a template section
+type TemplateField struct {
+	Key   string `json:"key" example:"chief_complaint"`
+	Label string `json:"label" example:"Chief complaint"`
+	// One of text, number, boolean or choice
+	Type     string `json:"type" example:"text"`
+	Required bool   `json:"required" example:"true"`
+	// Allowed values of a choice field
+	Options []string `json:"options,omitempty" example:"mild,moderate,severe"`
+}
+
+// TemplateSection groups the fields of a template under a heading
+type TemplateSection struct {
+	Key    string          `json:"key" example:"assessment"`
+	Title  string          `json:"title" example:"Assessment"`
+	Fields []TemplateField `json:"fields"`
+}
+
+// ReportTemplate defines the structured clinical notes a report carries in
+// Content["notes"]: an object per section holding its fields' values.
+// Archived templates keep validating the reports made with them but cannot be
+// chosen for new ones.
+type ReportTemplate struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string `gorm:"type:varchar(100);not null;uniqueIndex" json:"name" example:"Speech therapy session"`
+	Description string `gorm:"type:text" json:"description,omitempty" example:"Notes taken after each speech therapy session"`
+	// The template's sections; see TemplateSection
+	Sections   datatypes.JSON `gorm:"type:json;not null" json:"sections" swaggertype:"array,object"`
+	CreatedBy  uint           `gorm:"not null" json:"created_by"`
+	ArchivedAt *time.Time     `gorm:"index" json:"archived_at,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+// ValidateTemplateSections checks a template's structure
+func ValidateTemplateSections(sections []TemplateSection) error {
+	if len(sections) == 0 || len(sections) > MaxTemplateSections {
+		return fmt.Errorf("a template needs between 1 and %d sections", MaxTemplateSections)
+	}
+	sectionKeys := map[string]bool{}
+	for _, section := range sections {
+		if err := validateTemplateKey(section.Key); err != nil {
+			return fmt.Errorf("section %q: %w", section.Key, err)
+		}
+		if sectionKeys[section.Key] {
+			return fmt.Errorf("section %q is defined twice", section.Key)
+		}
+		sectionKeys[section.Key] = true
+		if strings.TrimSpace(section.Title) == "" || len(section.Title) > MaxTemplateLabelLength {
+			return fmt.Errorf("section %q needs a title of at most %d characters", section.Key, MaxTemplateLabelLength)
+		}
+		if len(section.Fields) == 0 || len(section.Fields) > MaxTemplateFields {
+			return fmt.Errorf("section %q needs between 1 and %d fields", section.Key, MaxTemplateFields)
+		}
+
+		fieldKeys := map[string]bool{}
+		for _, field := range section.Fields {
+			if err := validateTemplateKey(field.Key); err != nil {
+				return fmt.Errorf("field %s.%s: %w", section.Key, field.Key, err)
+			}
+			if fieldKeys[field.Key] {
+				return fmt.Errorf("field %s.%s is defined twice", section.Key, field.Key)
+			}
+			fieldKeys[field.Key] = true
+			if strings.TrimSpace(field.Label) == "" || len(field.Label) > MaxTemplateLabelLength {
+				return fmt.Errorf("field %s.%s needs a label of at most %d characters", section.Key, field.Key, MaxTemplateLabelLength)
+			}
+			switch field.Type {
+			case TemplateFieldText, TemplateFieldNumber, TemplateFieldBoolean:
+				if len(field.Options) > 0 {
+					return fmt.Errorf("field %s.%s: only choice fields have options", section.Key, field.Key)
+				}
+			case TemplateFieldChoice:
+				if len(field.Options) == 0 || len(field.Options) > MaxTemplateChoices {
+					return fmt.Errorf("field %s.%s needs between 1 and %d options", section.Key, field.Key, MaxTemplateChoices)
+				}
+			default:
+				return fmt.Errorf("field %s.%s has unknown type %q; use text, number, boolean or choice", section.Key, field.Key, field.Type)
+			}
+		}
+	}
+	return nil
+}
+
+func validateTemplateKey(key string) error {
+	if len(key) > maxTemplateKeyLength || !templateKeyPattern.MatchString(key) {
+		return fmt.Errorf("keys must be lowercase letters, digits and underscores starting with a letter, at most %d characters", maxTemplateKeyLength)
+	}
+	return nil
+}
+
+// SetSections validates sections and stores them on the template
+func (t *ReportTemplate) SetSections(sections []TemplateSection) error {
+	if err := ValidateTemplateSections(sections); err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(sections)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sections: %w", err)
+	}
+	t.Sections = datatypes.JSON(encoded)
+	return nil
+}
+
+// Structure decodes the template's sections
+func (t *ReportTemplate) Structure() ([]TemplateSection, error) {
+	var sections []TemplateSection
+	if err := json.Unmarshal(t.Sections, &sections); err != nil {
+		return nil, fmt.Errorf("template %d has invalid sections: %w", t.ID, err)
+	}
+	return sections, nil
+}
+
+// PopulateNotes shapes notes to the template: every section is present as an
+// object and values of unknown sections and fields are dropped. It does not
+// check the values; ValidateNotes does.
+func (t *ReportTemplate) PopulateNotes(notes map[string]interface{}) (map[string]interface{}, error) {
+	sections, err := t.Structure()
+	if err != nil {
+		return nil, err
+	}
+	populated := make(map[string]interface{}, len(sections))
+	for _, section := range sections {
+		values, _ := notes[section.Key].(map[string]interface{})
+		filled := map[string]interface{}{}
+		for _, field := range section.Fields {
+			if value, ok := values[field.Key]; ok && value != nil {
+				filled[field.Key] = value
+			}
+		}
+		populated[section.Key] = filled
+	}
+	return populated, nil
+}
+
+// ValidateNotes checks notes against the template: only known sections and
+// fields, values of the field's type and every required field filled in
+func (t *ReportTemplate) ValidateNotes(notes map[string]interface{}) error {
+	sections, err := t.Structure()
+	if err != nil {
+		return err
+	}
+	known := map[string]TemplateSection{}
+	for _, section := range sections {
+		known[section.Key] = section
+	}
+	for key := range notes {
+		if _, ok := known[key]; !ok {
+			return fmt.Errorf("notes: unknown section %q", key)
+		}
+	}
+
+	for _, section := range sections {
+		values := map[string]interface{}{}
+		if raw, present := notes[section.Key]; present && raw != nil {
+			var ok bool
+			if values, ok = raw.(map[string]interface{}); !ok {
+				return fmt.Errorf("notes.%s must be an object", section.Key)
+			}
+		}
+		fields := map[string]TemplateField{}
+		for _, field := range section.Fields {
+			fields[field.Key] = field
+		}
+		for key := range values {
+			if _, ok := fields[key]; !ok {
+				return fmt.Errorf("notes.%s: unknown field %q", section.Key, key)
+			}
+		}
+		for _, field := range section.Fields {
+			value, present := values[field.Key]
+			if !present || value == nil {
+				if field.Required {
+					return fmt.Errorf("notes.%s.%s is required", section.Key, field.Key)
+				}
+				continue
+			}
+			if err := validateTemplateValue(field, value); err != nil {
+				return fmt.Errorf("notes.%s.%s %w", section.Key, field.Key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateTemplateValue checks a decoded JSON value against its field
+func validateTemplateValue(field TemplateField, value interface{}) error {
+	switch field.Type {
+	case TemplateFieldText:
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("must be a string")
+		}
+		if field.Required && strings.TrimSpace(text) == "" {
+			return fmt.Errorf("is required")
+		}
+		if len(text) > MaxTemplateTextLength {
+			return fmt.Errorf("must be at most %d characters", MaxTemplateTextLength)
+		}
+	case TemplateFieldNumber:
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("must be a number")
+		}
+	case TemplateFieldBoolean:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("must be true or false")
+		}
+	case TemplateFieldChoice:
+		choice, ok := value.(string)
+		if ok {
+			for _, option := range field.Options {
+				if choice == option {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(field.Options, ", "))
+	}
+	return nil
+}
+
+// validateTemplateContent checks the notes in a report's content against the
+// report's template
+func validateTemplateContent(db *gorm.DB, r *Report) error {
+	if r.TemplateID == nil {
+		return nil
+	}
+	template, err := FindReportTemplate(db, *r.TemplateID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("report template %d not found", *r.TemplateID)
+		}
+		return err
+	}
+
+	// Only the notes are decoded; the samples are skipped
+	var content map[string]json.RawMessage
+	if err := json.Unmarshal(r.Content, &content); err != nil {
+		return fmt.Errorf("content must be a JSON object")
+	}
+	notes := map[string]interface{}{}
+	if raw, present := content[templateNotesContentKey]; present && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &notes); err != nil {
+			return fmt.Errorf("notes must be an object")
+		}
+	}
+	return template.ValidateNotes(notes)
+}
+
+// CreateReportTemplate saves a new template
+func CreateReportTemplate(db *gorm.DB, t *ReportTemplate) error {
+	if err := checkTemplateName(db, t); err != nil {
+		return err
+	}
+	if err := db.Create(t).Error; err != nil {
+		return fmt.Errorf("failed to create report template: %w", err)
+	}
+	return nil
+}
+
+// Save stores changes to the template's name, description and sections
+func (t *ReportTemplate) Save(db *gorm.DB) error {
+	if err := checkTemplateName(db, t); err != nil {
+		return err
+	}
+	return db.Model(t).Updates(map[string]interface{}{
+		"name":        t.Name,
+		"description": t.Description,
+		"sections":    t.Sections,
+	}).Error
+}
+
+// checkTemplateName fails with ErrTemplateNameTaken if another template uses t's name
+func checkTemplateName(db *gorm.DB, t *ReportTemplate) error {
+	var count int64
+	if err := db.Model(&ReportTemplate{}).Where("LOWER(name) = LOWER(?) AND id <> ?", t.Name, t.ID).Count(&count).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if count > 0 {
+		return ErrTemplateNameTaken
+	}
+	return nil
+}
+
+// Archive hides the template from new reports
+func (t *ReportTemplate) Archive(db *gorm.DB) error {
+	if t.ArchivedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	t.ArchivedAt = &now
+	return db.Model(t).Update("archived_at", now).Error
+}
+
+// FindReportTemplate finds a template, archived or not
+func FindReportTemplate(db *gorm.DB, id uint) (*ReportTemplate, error) {
+	var template ReportTemplate
+	if err := db.First(&template, id).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// FindReportTemplates lists templates by name, leaving out archived ones
+// unless includeArchived is set
+func FindReportTemplates(db *gorm.DB, includeArchived bool) ([]ReportTemplate, error) {
+	query := db.Order("name asc")
+	if !includeArchived {
+		query = query.Where("archived_at IS NULL")
+	}
+	var templates []ReportTemplate
+	err := query.Find(&templates).Error
+	return templates, err
+}
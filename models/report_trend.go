@@ -0,0 +1,64 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Time series granularities
+const (
+	GranularityDay  = "day"
+	GranularityWeek = "week"
+)
+
+// MatchingScalePoint aggregates the matching scale of the reports created in
+// one day or week
+type MatchingScalePoint struct {
+	// First day of the period in the owner's time zone; weeks start on Monday
+	Period  string  `json:"period" example:"2026-10-12"`
+	Reports int64   `json:"reports" example:"4"`
+	Average float64 `json:"average" example:"72.5"`
+	Min     int     `json:"min" example:"60"`
+	Max     int     `json:"max" example:"85"`
+}
+
+// FindMatchingScaleSeries aggregates the matching scale of the owner's reports
+// matching filter per day or week in timezone, oldest first. Periods without
+// reports are left out.
+func FindMatchingScaleSeries(db *gorm.DB, ownerID uint, filter ReportFilter, granularity, timezone string) ([]MatchingScalePoint, error) {
+	if granularity != GranularityDay && granularity != GranularityWeek {
+		return nil, fmt.Errorf("granularity must be day or week")
+	}
+
+	var rows []struct {
+		PeriodStart time.Time
+		Reports     int64
+		Average     float64
+		Min         int
+		Max         int
+	}
+	err := filter.Apply(db.Model(&Report{}).Where("user_id = ?", ownerID)).
+		Select("date_trunc(?, timezone(?, created_at AT TIME ZONE 'UTC')) AS period_start, COUNT(*) AS reports, "+
+			"ROUND(AVG(matching_scale)::numeric, 2) AS average, MIN(matching_scale) AS min, MAX(matching_scale) AS max",
+			granularity, timezone).
+		Group("period_start").
+		Order("period_start").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	points := make([]MatchingScalePoint, len(rows))
+	for i, row := range rows {
+		points[i] = MatchingScalePoint{
+			Period:  row.PeriodStart.Format("2006-01-02"),
+			Reports: row.Reports,
+			Average: row.Average,
+			Min:     row.Min,
+			Max:     row.Max,
+		}
+	}
+	return points, nil
+}
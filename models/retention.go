@@ -0,0 +1,201 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Raw recording states
+const (
+	RecordingsActive   = "active"
+	RecordingsArchived = "archived"
+	RecordingsPurged   = "purged"
+)
+
+// lapsedStatuses are subscription statuses that no longer entitle a user to their plan
+var lapsedStatuses = []string{SubscriptionStatusExpired, "canceled", "unpaid", "incomplete_expired"}
+
+// FindUsersWithLapsedRecordings returns users whose subscription has lapsed,
+// whose recordings are still active and who have no retention action scheduled
+func FindUsersWithLapsedRecordings(db *gorm.DB) ([]User, error) {
+	var users []User
+	err := db.Where("subscription_status IN ? AND recordings_status = ? AND recordings_action_due_at IS NULL", lapsedStatuses, RecordingsActive).
+		Find(&users).Error
+	return users, err
+}
+
+// FindUsersWithScheduledRecordingsAction returns users with a pending retention action
+func FindUsersWithScheduledRecordingsAction(db *gorm.DB) ([]User, error) {
+	var users []User
+	err := db.Where("recordings_action_due_at IS NOT NULL AND recordings_status = ?", RecordingsActive).
+		Find(&users).Error
+	return users, err
+}
+
+// ScheduleRecordingsAction records that action will be applied to the user's raw recordings at dueAt
+func (u *User) ScheduleRecordingsAction(db *gorm.DB, action string, dueAt time.Time) error {
+	u.RecordingsAction = &action
+	u.RecordingsActionDueAt = &dueAt
+	return db.Model(u).Updates(map[string]interface{}{
+		"recordings_action":        action,
+		"recordings_action_due_at": dueAt,
+	}).Error
+}
+
+// CancelRecordingsAction clears a pending retention action, e.g. after resubscribing
+func (u *User) CancelRecordingsAction(db *gorm.DB) error {
+	u.RecordingsAction = nil
+	u.RecordingsActionDueAt = nil
+	return db.Model(u).Updates(map[string]interface{}{
+		"recordings_action":        nil,
+		"recordings_action_due_at": nil,
+	}).Error
+}
+
+// CompleteRecordingsAction records the final state of the user's raw recordings
+func (u *User) CompleteRecordingsAction(db *gorm.DB, status string) error {
+	u.RecordingsStatus = status
+	u.RecordingsActionDueAt = nil
+	return db.Model(u).Updates(map[string]interface{}{
+		"recordings_status":        status,
+		"recordings_action_due_at": nil,
+	}).Error
+}
+
+// ClearReportContents removes the raw recording data from the user's reports,
+// keeping titles, translations and quality metrics
+func ClearReportContents(db *gorm.DB, userID uint) error {
+	var archived []Report
+	if err := db.Unscoped().Select("id, user_id").Where("user_id = ? AND content_archived_at IS NOT NULL", userID).
+		Find(&archived).Error; err != nil {
+		return err
+	}
+	if err := db.Unscoped().Model(&Report{}).Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"content": nil, "size_bytes": 0, "content_archived_at": nil}).Error; err != nil {
+		return err
+	}
+	deleteArchivedContents(db.Statement.Context, archived)
+	return nil
+}
+
+// RetentionAgeCounts counts an organization's records by how long ago they were created
+type RetentionAgeCounts struct {
+	Total           int64      `json:"total" example:"1200"`
+	Under30Days     int64      `json:"under_30_days" example:"150"`
+	From30To90Days  int64      `json:"30_to_90_days" example:"250"`
+	From90To365Days int64      `json:"90_to_365_days" example:"500"`
+	From1To3Years   int64      `json:"1_to_3_years" example:"300"`
+	Over3Years      int64      `json:"over_3_years" example:"0"`
+	OldestCreatedAt *time.Time `json:"oldest_created_at,omitempty"`
+}
+
+// ScheduledRecordingsActions counts members whose raw recordings await an action
+type ScheduledRecordingsActions struct {
+	Action        string     `json:"action" example:"purge"`
+	Users         int64      `json:"users" example:"3"`
+	EarliestDueAt *time.Time `json:"earliest_due_at,omitempty"`
+}
+
+// orgMembers selects the IDs of an organization's members
+func orgMembers(db *gorm.DB, orgID uint) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Model(&User{}).Select("id").Where("organization_id = ?", orgID)
+}
+
+// CountOrgMembers counts the users of an organization, including deactivated ones
+func CountOrgMembers(db *gorm.DB, orgID uint) (int64, error) {
+	var count int64
+	err := db.Unscoped().Model(&User{}).Where("organization_id = ?", orgID).Count(&count).Error
+	return count, err
+}
+
+// CountOrgReportsByAge counts the organization's reports by age at now
+func CountOrgReportsByAge(db *gorm.DB, orgID uint, now time.Time) (RetentionAgeCounts, error) {
+	return countByAge(db.Model(&Report{}).Where("user_id IN (?)", orgMembers(db, orgID)), now)
+}
+
+// CountOrgReportRevisionsByAge counts the stored earlier versions of the
+// organization's reports, including those of deleted reports, by age at now
+func CountOrgReportRevisionsByAge(db *gorm.DB, orgID uint, now time.Time) (RetentionAgeCounts, error) {
+	reports := db.Session(&gorm.Session{NewDB: true}).Unscoped().Model(&Report{}).Select("id").
+		Where("user_id IN (?)", orgMembers(db, orgID))
+	return countByAge(db.Model(&ReportRevision{}).Where("report_id IN (?)", reports), now)
+}
+
+// countByAge buckets the rows matched by query by their created_at
+func countByAge(query *gorm.DB, now time.Time) (RetentionAgeCounts, error) {
+	var counts RetentionAgeCounts
+	d30, d90, y1, y3 := now.AddDate(0, 0, -30), now.AddDate(0, 0, -90), now.AddDate(-1, 0, 0), now.AddDate(-3, 0, 0)
+	err := query.
+		Select(`COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE created_at >= ?) AS under30_days,
+			COUNT(*) FILTER (WHERE created_at < ? AND created_at >= ?) AS from30_to90_days,
+			COUNT(*) FILTER (WHERE created_at < ? AND created_at >= ?) AS from90_to365_days,
+			COUNT(*) FILTER (WHERE created_at < ? AND created_at >= ?) AS from1_to3_years,
+			COUNT(*) FILTER (WHERE created_at < ?) AS over3_years,
+			MIN(created_at) AS oldest_created_at`, d30, d30, d90, d90, y1, y1, y3, y3).
+		Scan(&counts).Error
+	if err != nil {
+		return counts, fmt.Errorf("database error: %w", err)
+	}
+	return counts, nil
+}
+
+// CountOrgRecordingsByStatus counts the organization's members by the state of their raw recordings
+func CountOrgRecordingsByStatus(db *gorm.DB, orgID uint) (map[string]int64, error) {
+	var rows []struct {
+		RecordingsStatus string
+		Count            int64
+	}
+	err := db.Unscoped().Model(&User{}).Select("recordings_status, COUNT(*) AS count").
+		Where("organization_id = ?", orgID).Group("recordings_status").Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	counts := map[string]int64{RecordingsActive: 0, RecordingsArchived: 0, RecordingsPurged: 0}
+	for _, row := range rows {
+		counts[row.RecordingsStatus] = row.Count
+	}
+	return counts, nil
+}
+
+// CountOrgStoredRecordings counts the organization's reports that still hold raw recording data
+func CountOrgStoredRecordings(db *gorm.DB, orgID uint) (int64, error) {
+	var count int64
+	err := db.Unscoped().Model(&Report{}).
+		Where("content IS NOT NULL AND user_id IN (?)", orgMembers(db, orgID)).
+		Count(&count).Error
+	return count, err
+}
+
+// FindOrgDeletedReports counts the organization's deleted reports awaiting
+// purge and returns when the oldest was deleted
+func FindOrgDeletedReports(db *gorm.DB, orgID uint) (int64, *time.Time, error) {
+	var row struct {
+		Count          int64
+		OldestDeletion *time.Time
+	}
+	err := db.Unscoped().Model(&Report{}).Select("COUNT(*) AS count, MIN(deleted_at) AS oldest_deletion").
+		Where("deleted_at IS NOT NULL AND user_id IN (?)", orgMembers(db, orgID)).
+		Scan(&row).Error
+	if err != nil {
+		return 0, nil, fmt.Errorf("database error: %w", err)
+	}
+	return row.Count, row.OldestDeletion, nil
+}
+
+// FindOrgScheduledRecordingsActions groups the organization's pending
+// archive and purge actions on raw recordings
+func FindOrgScheduledRecordingsActions(db *gorm.DB, orgID uint) ([]ScheduledRecordingsActions, error) {
+	var actions []ScheduledRecordingsActions
+	err := db.Unscoped().Model(&User{}).
+		Select("recordings_action AS action, COUNT(*) AS users, MIN(recordings_action_due_at) AS earliest_due_at").
+		Where("organization_id = ? AND recordings_action_due_at IS NOT NULL AND recordings_status = ?", orgID, RecordingsActive).
+		Group("recordings_action").Order("recordings_action").
+		Scan(&actions).Error
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return actions, nil
+}
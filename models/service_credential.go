@@ -0,0 +1,99 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"gorm.io/gorm"
+)
+
+// Service credential scopes
+const (
+	ScopeMLExport = "ml_export"
+)
+
+// serviceKeyPrefix marks service keys so they are recognisable in logs and secret scanners
+const serviceKeyPrefix = "tik_svc_"
+
+// ServiceCredential is an API key for a backend service, such as the ML team's
+// training pipeline. Only a hash of the key is stored.
+type ServiceCredential struct {
+	ID         uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name       string     `gorm:"type:text;not null" json:"name"`
+	Scope      string     `gorm:"type:varchar(50);not null" json:"scope"`
+	KeyHash    string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	KeyHint    string     `gorm:"type:varchar(16);not null" json:"key_hint"`
+	CreatedBy  uint       `gorm:"not null" json:"created_by"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// hashServiceKey returns the stored form of a service key
+func hashServiceKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateServiceCredential issues a new service key. The key is returned only once.
+func CreateServiceCredential(db *gorm.DB, name, scope string, createdBy uint) (*ServiceCredential, string, error) {
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	key := serviceKeyPrefix + token
+
+	credential := &ServiceCredential{
+		Name:      name,
+		Scope:     scope,
+		KeyHash:   hashServiceKey(key),
+		KeyHint:   key[:len(serviceKeyPrefix)+4],
+		CreatedBy: createdBy,
+	}
+	if err := db.Create(credential).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to create service credential: %w", err)
+	}
+	return credential, key, nil
+}
+
+// FindServiceCredentialByKey returns the active credential for a key
+func FindServiceCredentialByKey(db *gorm.DB, key string) (*ServiceCredential, error) {
+	var credential ServiceCredential
+	err := db.Where("key_hash = ? AND revoked_at IS NULL", hashServiceKey(key)).First(&credential).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("service credential not found")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &credential, nil
+}
+
+// FindServiceCredentials lists all service credentials, newest first
+func FindServiceCredentials(db *gorm.DB) ([]ServiceCredential, error) {
+	var credentials []ServiceCredential
+	err := db.Order("created_at desc").Find(&credentials).Error
+	return credentials, err
+}
+
+// RevokeServiceCredential revokes a credential so its key stops working
+func RevokeServiceCredential(db *gorm.DB, id uint) error {
+	result := db.Model(&ServiceCredential{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("service credential not found")
+	}
+	return nil
+}
+
+// MarkUsed records when the credential was last presented
+func (sc *ServiceCredential) MarkUsed(db *gorm.DB) error {
+	now := time.Now()
+	sc.LastUsedAt = &now
+	return db.Model(sc).Update("last_used_at", now).Error
+}
@@ -1,42 +1,212 @@
 package models
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"time"
 
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 // SingleFile represents a temporarily uploaded file that will be processed into a Report
 type SingleFile struct {
-	ID          uint      `json:"id"`
-	UserID      uint      `json:"user_id"`
-	Filename    string    `json:"filename"`
-	FilePath    string    `json:"file_path"`
+	ID          uint      `json:"id" example:"7"`
+	UserID      uint      `gorm:"index" json:"user_id" example:"1"`
+	Filename    string    `json:"filename" example:"morning-session.json"`
+	FilePath    string    `json:"-"`
 	UploadedAt  time.Time `json:"uploaded_at"`
-	FileSize    int64
-	Description string `json:"description"`
+	FileSize    int64     `json:"file_size" example:"48213"`
+	Description string    `json:"-"`
+	// SHA-256 of the file's bytes, used to recognize the same recording uploaded again
+	ContentHash string `gorm:"type:varchar(64);index" json:"content_hash,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	// Where the original file can be fetched; filled in by the report detail handler
+	DownloadURL string `gorm:"-" json:"download_url,omitempty" example:"/reports/12/source-file"`
+	// Processing state, persisted as each step starts; one of the FileState* values
+	State          string     `gorm:"type:varchar(20);not null;default:'pending';index" json:"state" example:"completed"`
+	StateChangedAt *time.Time `json:"state_changed_at,omitempty"`
+	// Why processing or translation last failed, one of the FileError* classes;
+	// cleared when the file is processed again
+	ErrorClass   string `gorm:"type:varchar(30)" json:"error_class,omitempty" example:"ml_unavailable"`
+	ErrorMessage string `gorm:"type:text" json:"error_message,omitempty" example:"translation service unavailable: context deadline exceeded"`
+	// Channel count, sampling rate and duration of the recording
+	RecordingInfo `gorm:"embedded"`
+	// Status derived from the linked report; filled in by LoadFileReports
+	Status string `gorm:"-" json:"status,omitempty" example:"processed"`
+	// The report made from the file; filled in by LoadFileReports
+	ReportID *uint `gorm:"-" json:"report_id,omitempty" example:"12"`
+	// Set when the malware scan found the file infected; it was quarantined and never processed
+	QuarantinedAt *time.Time `gorm:"index" json:"quarantined_at,omitempty"`
+	// Signature the scanner matched
+	Threat string `gorm:"type:text" json:"threat,omitempty" example:"Eicar-Signature"`
+	// The recording session the file is a segment of, and its position there
+	SessionID    *uint `gorm:"index" json:"session_id,omitempty" example:"4"`
+	SessionIndex int   `gorm:"not null;default:0" json:"session_index,omitempty" example:"0"`
+	// Deleted files are hidden at once and purged with deleted reports
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-" swaggerignore:"true"`
 }
 
-// ConvertToReport reads the file, parses the JSON content into a Report object and returns it
-// Does not save to database
-func (sf *SingleFile) ConvertToReport() (*Report, error) {
-	// Read the file content
-	fileData, err := os.ReadFile(sf.FilePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+// ErrFileShared is returned when deleting a file whose report has an active share link
+var ErrFileShared = fmt.Errorf("the report made from this file is shared")
+
+// File statuses, derived from the file's upload job and the report made from it
+const (
+	FileProcessing  = "processing"  // its upload job or its report's translation is queued or running
+	FileProcessed   = "processed"   // its report is ready
+	FileFailed      = "failed"      // its upload job or its report's translation failed
+	FileUnlinked    = "unlinked"    // its report was deleted
+	FileQuarantined = "quarantined" // the malware scan found it infected
+)
+
+// FileStatuses lists the statuses a file listing can be filtered by
+var FileStatuses = []string{FileProcessing, FileProcessed, FileFailed, FileUnlinked, FileQuarantined}
+
+// ValidFileStatus reports whether status is one of the file statuses
+func ValidFileStatus(status string) bool {
+	for _, s := range FileStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// FileFilter narrows a file listing. Unset fields match every file
+type FileFilter struct {
+	From   *time.Time // uploaded at or after
+	To     *time.Time // uploaded before
+	Status string     // one of the File* statuses
+	State  string     // one of the FileState* states
+}
+
+// Apply adds the filter's conditions to a file query
+func (f FileFilter) Apply(query *gorm.DB) *gorm.DB {
+	if f.From != nil {
+		query = query.Where("uploaded_at >= ?", *f.From)
 	}
+	if f.To != nil {
+		query = query.Where("uploaded_at < ?", *f.To)
+	}
+	if f.State != "" {
+		query = query.Where("state = ?", f.State)
+	}
+	switch f.Status {
+	case "":
+		return query
+	case FileQuarantined:
+		return query.Where("quarantined_at IS NOT NULL")
+	}
+
+	subquery := func() *gorm.DB { return query.Session(&gorm.Session{NewDB: true}) }
+	reports := func() *gorm.DB {
+		return subquery().Model(&Report{}).Select("source_file_id").Where("source_file_id IS NOT NULL")
+	}
+	jobs := func(statuses ...string) *gorm.DB {
+		return subquery().Model(&UploadJob{}).Select("file_id").Where("status IN ?", statuses)
+	}
+
+	switch f.Status {
+	case FileProcessing:
+		return query.Where("id IN (?) OR id IN (?)",
+			reports().Where("translation_status IN ?", []string{TranslationPending, TranslationRunning}),
+			jobs(UploadJobQueued, UploadJobRunning))
+	case FileFailed:
+		return query.Where("id IN (?) OR (id IN (?) AND id NOT IN (?))",
+			reports().Where("translation_status = ?", TranslationFailed),
+			jobs(UploadJobFailed), reports())
+	case FileProcessed:
+		return query.Where("id IN (?)", reports().Where("translation_status IS NULL OR translation_status NOT IN ?",
+			[]string{TranslationPending, TranslationRunning, TranslationFailed}))
+	case FileUnlinked:
+		return query.Where("quarantined_at IS NULL AND id NOT IN (?) AND id NOT IN (?)",
+			reports(), jobs(UploadJobQueued, UploadJobRunning, UploadJobFailed))
+	}
+	return query
+}
 
-	// Attempt to parse the JSON
-	var jsonData map[string]interface{}
-	if err := json.Unmarshal(fileData, &jsonData); err != nil {
-		return nil, fmt.Errorf("invalid JSON format: %w", err)
+// fileStatus derives a file's status from the translation status of its report
+func fileStatus(translationStatus string) string {
+	switch translationStatus {
+	case TranslationPending, TranslationRunning:
+		return FileProcessing
+	case TranslationFailed:
+		return FileFailed
 	}
-	content, err := json.Marshal(jsonData)
+	return FileProcessed
+}
+
+// LoadFileReports fills in the status and report ID of each file. Files
+// without a report take their status from their upload job; quarantined files
+// have neither.
+func LoadFileReports(db *gorm.DB, files []SingleFile) error {
+	if len(files) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(files))
+	for i := range files {
+		ids[i] = files[i].ID
+	}
+
+	var rows []struct {
+		ID                uint
+		SourceFileID      uint
+		TranslationStatus string
+	}
+	err := db.Model(&Report{}).
+		Select("id, source_file_id, translation_status").
+		Where("source_file_id IN ?", ids).
+		Order("id asc").
+		Scan(&rows).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	byFile := make(map[uint]int, len(rows))
+	for i, row := range rows {
+		if _, seen := byFile[row.SourceFileID]; !seen {
+			byFile[row.SourceFileID] = i
+		}
+	}
+	var jobs []UploadJob
+	if err := db.Select("file_id, status").Where("file_id IN ?", ids).Order("id asc").Find(&jobs).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	jobStatus := make(map[uint]string, len(jobs))
+	for _, job := range jobs {
+		jobStatus[job.FileID] = job.Status
+	}
+
+	for i := range files {
+		if files[i].QuarantinedAt != nil {
+			files[i].Status = FileQuarantined
+			continue
+		}
+		row, ok := byFile[files[i].ID]
+		if !ok {
+			switch jobStatus[files[i].ID] {
+			case UploadJobQueued, UploadJobRunning:
+				files[i].Status = FileProcessing
+			case UploadJobFailed:
+				files[i].Status = FileFailed
+			default:
+				files[i].Status = FileUnlinked
+			}
+			continue
+		}
+		reportID := rows[row].ID
+		files[i].ReportID = &reportID
+		files[i].Status = fileStatus(rows[row].TranslationStatus)
+	}
+	return nil
+}
+
+// ConvertToReport builds a report of the file from its recording's content,
+// already encoded in the current schema. Does not save to database. With a
+// template, the content's notes must have been populated from it.
+func (sf *SingleFile) ConvertToReport(content datatypes.JSON, template *ReportTemplate) *Report {
+	var templateID *uint
+	if template != nil {
+		templateID = &template.ID
 	}
 
 	// Create and return the report without saving to database
@@ -44,12 +214,19 @@ func (sf *SingleFile) ConvertToReport() (*Report, error) {
 		UserID:        sf.UserID,
 		Title:         sf.Filename,
 		Description:   sf.Description,
-		Content:       datatypes.JSON(content),
+		Content:       content,
 		MatchingScale: 0,
+		SizeBytes:     sf.FileSize,
+		TemplateID:    templateID,
+		RecordingInfo: sf.RecordingInfo,
 		CreatedAt:     time.Now(),
 	}
+	if sf.ID != 0 {
+		report.SourceFileID = &sf.ID
+	}
+	report.SessionID = sf.SessionID
 
-	return report, nil
+	return report
 }
 
 // CreateSingleFile creates a new single file entry from a file path
@@ -60,14 +237,168 @@ func CreateSingleFile(userID uint, originalFilename, filePath, description strin
 		return nil, fmt.Errorf("file error: %w", err)
 	}
 
+	now := time.Now()
 	singleFile := &SingleFile{
-		UserID:      userID,
-		Filename:    originalFilename,
-		FilePath:    filePath,
-		Description: description,
-		UploadedAt:  time.Now(),
-		FileSize:    fileInfo.Size(),
+		UserID:         userID,
+		Filename:       originalFilename,
+		FilePath:       filePath,
+		Description:    description,
+		UploadedAt:     now,
+		FileSize:       fileInfo.Size(),
+		State:          FileStatePending,
+		StateChangedAt: &now,
 	}
 
 	return singleFile, nil
 }
+
+// Save stores the file's metadata so reports can link back to it
+func (sf *SingleFile) Save(db *gorm.DB) error {
+	if err := db.Create(sf).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// FindDuplicateReport returns the user's earliest report created from a file
+// with the given content hash, or nil when the file was not uploaded before.
+// Reports that are themselves marked as duplicates are not returned.
+func FindDuplicateReport(db *gorm.DB, userID uint, contentHash string) (*Report, error) {
+	var reports []Report
+	err := db.Where("user_id = ? AND duplicate_of_id IS NULL AND source_file_id IN (?)", userID,
+		db.Model(&SingleFile{}).Select("id").Where("user_id = ? AND content_hash = ?", userID, contentHash)).
+		Order("created_at asc, id asc").Limit(1).Find(&reports).Error
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if len(reports) == 0 {
+		return nil, nil
+	}
+	return &reports[0], nil
+}
+
+// Delete soft-deletes the file's metadata; the file itself is left in place
+func (sf *SingleFile) Delete(db *gorm.DB) error {
+	return db.Delete(sf).Error
+}
+
+// FindUserFile returns one of the user's files
+func FindUserFile(db *gorm.DB, fileID, userID uint) (*SingleFile, error) {
+	var file SingleFile
+	if err := db.Where("id = ? AND user_id = ?", fileID, userID).First(&file).Error; err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// FindFileReport finds the first report made from one of the user's files
+func FindFileReport(db *gorm.DB, fileID, userID uint) (*Report, error) {
+	var report Report
+	if err := db.Where("source_file_id = ? AND user_id = ?", fileID, userID).Order("id asc").First(&report).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// DeleteWithReports soft-deletes the file's metadata and, with cascade, the
+// reports made from it, returning their IDs. Reports that are kept lose their
+// source file. Files whose reports have an active share link are refused with
+// ErrFileShared, as removing them would change what the link shows.
+func (sf *SingleFile) DeleteWithReports(db *gorm.DB, cascade bool) ([]uint, error) {
+	var reportIDs []uint
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Report{}).Where("source_file_id = ?", sf.ID).Pluck("id", &reportIDs).Error; err != nil {
+			return err
+		}
+		if len(reportIDs) > 0 {
+			var shared int64
+			err := tx.Model(&ReportShare{}).
+				Where("report_id IN ? AND revoked_at IS NULL AND expires_at > ?", reportIDs, time.Now()).
+				Count(&shared).Error
+			if err != nil {
+				return err
+			}
+			if shared > 0 {
+				return ErrFileShared
+			}
+			if cascade {
+				if err := tx.Where("id IN ?", reportIDs).Delete(&Report{}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Delete(sf).Error
+	})
+	if err != nil {
+		if err == ErrFileShared {
+			return nil, err
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if !cascade {
+		reportIDs = nil
+	}
+	return reportIDs, nil
+}
+
+// RemoveStored deletes the original file from disk; a file that is already
+// gone is not an error
+func (sf *SingleFile) RemoveStored() error {
+	if err := os.Remove(sf.FilePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Available reports whether the original file is still on disk. Retention
+// may have archived or purged it.
+func (sf *SingleFile) Available() bool {
+	info, err := os.Stat(sf.FilePath)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// FindUserFilesPage retrieves one page of the files the user uploaded that
+// match the filter, in the page's sort order or newest first, with their
+// status and report ID. A cursor takes precedence over the offset; sorted
+// pages carry no next cursor.
+func FindUserFilesPage(db *gorm.DB, userID uint, filter FileFilter, page Page) ([]SingleFile, PageInfo, error) {
+	var info PageInfo
+
+	if err := filter.Apply(db.Model(&SingleFile{}).Where("user_id = ?", userID)).Count(&info.Total).Error; err != nil {
+		return nil, info, fmt.Errorf("database error: %w", err)
+	}
+
+	query := filter.Apply(db.Where("user_id = ?", userID))
+	switch {
+	case len(page.Sort) > 0:
+		query = applySort(query, page.Sort).Offset(page.Offset)
+	case page.Cursor != "":
+		var err error
+		if query, err = afterCursor(query, "uploaded_at", "id", page.Cursor); err != nil {
+			return nil, info, err
+		}
+		query = query.Order("uploaded_at desc, id desc")
+	default:
+		query = query.Offset(page.Offset).Order("uploaded_at desc, id desc")
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	var files []SingleFile
+	if err := query.Limit(page.Limit + 1).Find(&files).Error; err != nil {
+		return nil, info, fmt.Errorf("database error: %w", err)
+	}
+
+	if len(files) > page.Limit {
+		files = files[:page.Limit]
+		info.HasMore = true
+		if len(page.Sort) == 0 {
+			last := files[len(files)-1]
+			info.NextCursor = encodeCursor(last.UploadedAt, last.ID)
+		}
+	}
+
+	if err := LoadFileReports(db, files); err != nil {
+		return nil, info, err
+	}
+	return files, info, nil
+}
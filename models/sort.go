@@ -0,0 +1,118 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxSortFields bounds how many fields one sort parameter may name
+const maxSortFields = 3
+
+// SortOrder orders a list by one column
+type SortOrder struct {
+	Field      string
+	Column     string
+	Descending bool
+}
+
+// String formats the order as it is written in the sort parameter, e.g. "matching_scale:desc"
+func (s SortOrder) String() string {
+	if s.Descending {
+		return s.Field + ":desc"
+	}
+	return s.Field + ":asc"
+}
+
+// SortFields maps the fields a list may be sorted by to their columns
+type SortFields map[string]string
+
+// Sortable fields of each list endpoint
+var (
+	ReportSortFields = SortFields{
+		"created_at":     "created_at",
+		"updated_at":     "updated_at",
+		"matching_scale": "matching_scale",
+		"title":          "title",
+		"size_bytes":     "size_bytes",
+	}
+	FileSortFields = SortFields{
+		"uploaded_at": "uploaded_at",
+		"filename":    "filename",
+		"file_size":   "file_size",
+	}
+	UserSortFields = SortFields{
+		"created_at": "created_at",
+		"last_login": "last_login",
+		"name":       "name",
+		"email":      "email",
+	}
+)
+
+// Names lists the sortable fields alphabetically
+func (f SortFields) Names() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse reads a sort parameter of comma-separated field:direction pairs, e.g.
+// "matching_scale:desc,created_at:asc". The direction is asc or desc and
+// defaults to asc. An empty value returns no orders.
+func (f SortFields) Parse(value string) ([]SortOrder, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(value, ",")
+	if len(parts) > maxSortFields {
+		return nil, fmt.Errorf("sort accepts at most %d fields", maxSortFields)
+	}
+
+	orders := make([]SortOrder, 0, len(parts))
+	seen := make(map[string]bool)
+	for _, part := range parts {
+		field, direction, _ := strings.Cut(strings.TrimSpace(part), ":")
+		column, ok := f[field]
+		if !ok {
+			return nil, fmt.Errorf("cannot sort by %q; sortable fields are %s", field, strings.Join(f.Names(), ", "))
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("sort names %s more than once", field)
+		}
+		seen[field] = true
+
+		order := SortOrder{Field: field, Column: column}
+		switch direction {
+		case "", "asc":
+		case "desc":
+			order.Descending = true
+		default:
+			return nil, fmt.Errorf("sort direction for %s must be asc or desc", field)
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+// applySort orders the query by the given columns, keeping rows without a
+// value last, and breaks ties by id so pages do not overlap
+func applySort(query *gorm.DB, orders []SortOrder) *gorm.DB {
+	for _, order := range orders {
+		direction := "ASC"
+		if order.Descending {
+			direction = "DESC"
+		}
+		query = query.Order(clause.Expr{
+			SQL:  "? " + direction + " NULLS LAST",
+			Vars: []interface{}{clause.Column{Name: order.Column}},
+		})
+	}
+	return query.Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: len(orders) > 0 && orders[0].Descending})
+}
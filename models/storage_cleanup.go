@@ -0,0 +1,105 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StorageCleanupRun records what one run of the storage cleanup job removed
+type StorageCleanupRun struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	StartedAt  time.Time `gorm:"not null" json:"started_at"`
+	FinishedAt time.Time `gorm:"not null;index" json:"finished_at"`
+	// Files deleted because they were older than the retention period
+	ExpiredFiles int64 `gorm:"not null;default:0" json:"expired_files"`
+	// Stored files no file record referred to
+	OrphanedBlobs int64 `gorm:"not null;default:0" json:"orphaned_blobs"`
+	// File records whose stored file was missing
+	OrphanedRecords int64 `gorm:"not null;default:0" json:"orphaned_records"`
+	// Disk space freed by removing expired and orphaned files
+	ReclaimedBytes int64 `gorm:"not null;default:0" json:"reclaimed_bytes"`
+}
+
+// Save records the run
+func (r *StorageCleanupRun) Save(db *gorm.DB) error {
+	if err := db.Create(r).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// StorageCleanupTotals adds up every recorded storage cleanup run
+type StorageCleanupTotals struct {
+	Runs            int64
+	ExpiredFiles    int64
+	OrphanedBlobs   int64
+	OrphanedRecords int64
+	ReclaimedBytes  int64
+	// When the latest run finished; nil before the first run
+	LastRunAt *time.Time
+}
+
+// SumStorageCleanups adds up the storage cleanup runs of every process
+func SumStorageCleanups(db *gorm.DB) (StorageCleanupTotals, error) {
+	var totals StorageCleanupTotals
+	err := db.Model(&StorageCleanupRun{}).
+		Select("COUNT(*) AS runs, " +
+			"COALESCE(SUM(expired_files), 0) AS expired_files, " +
+			"COALESCE(SUM(orphaned_blobs), 0) AS orphaned_blobs, " +
+			"COALESCE(SUM(orphaned_records), 0) AS orphaned_records, " +
+			"COALESCE(SUM(reclaimed_bytes), 0) AS reclaimed_bytes, " +
+			"MAX(finished_at) AS last_run_at").
+		Scan(&totals).Error
+	if err != nil {
+		return StorageCleanupTotals{}, fmt.Errorf("database error: %w", err)
+	}
+	return totals, nil
+}
+
+// FindExpiredFiles returns up to limit files uploaded before cutoff, oldest
+// first, skipping files an upload job is still turning into a report
+func FindExpiredFiles(db *gorm.DB, cutoff time.Time, limit int) ([]SingleFile, error) {
+	busy := db.Session(&gorm.Session{NewDB: true}).Model(&UploadJob{}).Select("file_id").
+		Where("status IN ?", []string{UploadJobQueued, UploadJobRunning})
+
+	var files []SingleFile
+	err := db.Where("uploaded_at < ? AND id NOT IN (?)", cutoff, busy).
+		Order("uploaded_at asc").Limit(limit).Find(&files).Error
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return files, nil
+}
+
+// FindReferencedFilePaths returns which of paths a file record refers to
+func FindReferencedFilePaths(db *gorm.DB, paths []string) (map[string]bool, error) {
+	var found []string
+	if err := db.Model(&SingleFile{}).Where("file_path IN ?", paths).Pluck("file_path", &found).Error; err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	referenced := make(map[string]bool, len(found))
+	for _, p := range found {
+		referenced[p] = true
+	}
+	return referenced, nil
+}
+
+// FindFilesInBatches calls fn with the files of users whose raw recordings
+// are still active, batchSize at a time, stopping at the first error. Files
+// of users whose recordings were archived or purged are expected to be gone
+// from disk and are skipped.
+func FindFilesInBatches(db *gorm.DB, batchSize int, fn func(files []SingleFile) error) error {
+	active := db.Session(&gorm.Session{NewDB: true}).Model(&User{}).Select("id").
+		Where("recordings_status = ?", RecordingsActive)
+
+	var batch []SingleFile
+	result := db.Where("user_id IN (?)", active).FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	})
+	if result.Error != nil {
+		return fmt.Errorf("database error: %w", result.Error)
+	}
+	return nil
+}
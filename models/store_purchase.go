@@ -0,0 +1,106 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StorePurchase is an in-app subscription bought through the App Store or
+// Google Play, as last validated with the store. The receipt is kept so the
+// subscription can be revalidated when it renews.
+type StorePurchase struct {
+	ID     uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID uint `gorm:"not null;index" json:"-"`
+	// app_store or google_play
+	Store string `gorm:"type:varchar(20);not null;uniqueIndex:idx_store_purchase" json:"store" example:"app_store"`
+	// Apple's original transaction ID or the Google Play purchase token
+	TransactionID   string     `gorm:"type:text;not null;uniqueIndex:idx_store_purchase" json:"transaction_id" example:"1000000812345678"`
+	ProductID       string     `gorm:"type:varchar(255);not null" json:"product_id" example:"io.thinkink.pro.monthly"`
+	Status          string     `gorm:"type:varchar(20);not null" json:"status" example:"active"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+	AutoRenew       bool       `json:"auto_renew" example:"true"`
+	Sandbox         bool       `json:"sandbox" example:"false"`
+	Receipt         string     `gorm:"type:text" json:"-"`
+	LastValidatedAt time.Time  `gorm:"index" json:"last_validated_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// FindStorePurchase retrieves a purchase by store and transaction ID, or nil
+// if it was never validated
+func FindStorePurchase(db *gorm.DB, store, transactionID string) (*StorePurchase, error) {
+	var purchase StorePurchase
+	err := db.Where("store = ? AND transaction_id = ?", store, transactionID).First(&purchase).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &purchase, nil
+}
+
+// SaveStorePurchase stores the latest validation of a purchase. The owner of
+// a purchase already stored is kept.
+func SaveStorePurchase(db *gorm.DB, purchase *StorePurchase) error {
+	columns := []string{"product_id", "status", "expires_at", "auto_renew", "sandbox", "receipt", "last_validated_at", "updated_at"}
+	var err error
+	if purchase.ID != 0 {
+		err = db.Model(purchase).Select(columns).Updates(purchase).Error
+	} else {
+		// Validated concurrently, e.g. by a retried request
+		err = db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "store"}, {Name: "transaction_id"}},
+			DoUpdates: clause.AssignmentColumns(columns),
+		}).Create(purchase).Error
+	}
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// FindStorePurchasesDue retrieves live purchases that expire before
+// expiringBefore and were last validated before validatedBefore, so renewals
+// are picked up from the store
+func FindStorePurchasesDue(db *gorm.DB, expiringBefore, validatedBefore time.Time, limit int) ([]StorePurchase, error) {
+	var purchases []StorePurchase
+	err := db.Where("status IN ? AND expires_at < ? AND last_validated_at < ?",
+		[]string{"active", "trialing", SubscriptionStatusPastDue}, expiringBefore, validatedBefore).
+		Order("expires_at asc").Limit(limit).Find(&purchases).Error
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return purchases, nil
+}
+
+// HoldsStorePurchase reports whether the user's subscription is the purchase
+func (u *User) HoldsStorePurchase(p *StorePurchase) bool {
+	return u.SubscriptionStore == p.Store && u.SubscriptionID != nil && *u.SubscriptionID == p.TransactionID
+}
+
+// UpdateStoreSubscription sets the user's subscription from an in-app
+// purchase, the store's product ID serving as the plan
+func (u *User) UpdateStoreSubscription(db *gorm.DB, p *StorePurchase) error {
+	u.SubscriptionStore = p.Store
+	u.SubscriptionID = &p.TransactionID
+	u.CurrentPlanID = &p.ProductID
+	u.SubscriptionStatus = &p.Status
+	u.SubscriptionEndsAt = p.ExpiresAt
+	u.PastDueSince = nil
+	u.PaymentGraceEndsAt = nil
+
+	return db.Model(u).Updates(map[string]interface{}{
+		"subscription_store":    p.Store,
+		"subscription_id":       p.TransactionID,
+		"current_plan_id":       p.ProductID,
+		"subscription_status":   p.Status,
+		"subscription_ends_at":  p.ExpiresAt,
+		"past_due_since":        nil,
+		"payment_grace_ends_at": nil,
+	}).Error
+}
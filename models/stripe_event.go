@@ -0,0 +1,234 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Stripe webhook event processing states
+const (
+	StripeEventPending   = "pending"
+	StripeEventProcessed = "processed"
+	StripeEventDead      = "dead"
+)
+
+// StripeEvent is a verified Stripe webhook event, acknowledged as soon as it
+// is stored and processed by a background worker. Events that keep failing
+// are parked as dead for an admin to inspect and retry.
+type StripeEvent struct {
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id" example:"12"`
+	// Stripe's event ID; redeliveries of a stored event are ignored
+	EventID string         `gorm:"type:varchar(255);not null;uniqueIndex" json:"event_id" example:"evt_1NExampleID"`
+	Type    string         `gorm:"type:varchar(100);not null;index" json:"type" example:"customer.subscription.updated"`
+	Payload datatypes.JSON `gorm:"type:json;not null" json:"payload" swaggertype:"object"`
+	// ID of the Stripe object the event is about, such as a subscription
+	ObjectID string `gorm:"type:varchar(255);index" json:"object_id,omitempty" example:"sub_1NExampleID"`
+	// When Stripe created the event; events can be delivered out of order
+	StripeCreatedAt *time.Time `json:"stripe_created_at,omitempty"`
+	State           string     `gorm:"type:varchar(20);not null;default:'pending';index" json:"state" example:"pending"`
+	Attempts        int        `gorm:"not null;default:0" json:"attempts" example:"0"`
+	NextAttemptAt   time.Time  `gorm:"index" json:"next_attempt_at"`
+	LastError       string     `gorm:"type:text" json:"last_error,omitempty"`
+	ProcessedAt     *time.Time `json:"processed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// EnqueueStripeEvent stores a received event for processing. It reports
+// false when the event was already stored, as for Stripe's redeliveries.
+func EnqueueStripeEvent(db *gorm.DB, event *StripeEvent) (bool, error) {
+	event.State = StripeEventPending
+	event.NextAttemptAt = time.Now()
+	if event.ObjectID == "" {
+		var object struct {
+			ID string `json:"id"`
+		}
+		if json.Unmarshal(event.Payload, &object) == nil {
+			event.ObjectID = object.ID
+		}
+	}
+
+	result := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event_id"}},
+		DoNothing: true,
+	}).Create(event)
+	if result.Error != nil {
+		return false, fmt.Errorf("database error: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ClaimDueStripeEvents locks up to limit pending events that are due, oldest
+// first, and pushes their next attempt out by lease so other workers skip them
+func ClaimDueStripeEvents(db *gorm.DB, limit int, lease time.Duration) ([]StripeEvent, error) {
+	var events []StripeEvent
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		until := now.Add(lease).Truncate(time.Microsecond)
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("state = ? AND next_attempt_at <= ?", StripeEventPending, now).
+			Order("created_at asc").
+			Limit(limit).
+			Find(&events).Error; err != nil {
+			return err
+		}
+
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(events))
+		for i := range events {
+			ids[i] = events[i].ID
+			events[i].NextAttemptAt = until
+		}
+		return tx.Model(&StripeEvent{}).Where("id IN ?", ids).
+			Update("next_attempt_at", until).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim Stripe events: %w", err)
+	}
+
+	return events, nil
+}
+
+// RenewLease pushes the claimed event's next attempt out by lease again, just
+// before it is handled. It returns false when the lease ran out and another
+// worker claimed the event in the meantime.
+func (e *StripeEvent) RenewLease(db *gorm.DB, lease time.Duration) (bool, error) {
+	until := time.Now().Add(lease).Truncate(time.Microsecond)
+	result := db.Model(&StripeEvent{}).
+		Where("id = ? AND state = ? AND next_attempt_at = ?", e.ID, StripeEventPending, e.NextAttemptAt).
+		Update("next_attempt_at", until)
+	if result.Error != nil {
+		return false, fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	e.NextAttemptAt = until
+	return true, nil
+}
+
+// Superseded reports whether a newer event about the same Stripe object has
+// already been processed, so applying this one would undo its change
+func (e *StripeEvent) Superseded(db *gorm.DB) (bool, error) {
+	if e.ObjectID == "" || e.StripeCreatedAt == nil {
+		return false, nil
+	}
+	var count int64
+	err := db.Model(&StripeEvent{}).
+		Where("object_id = ? AND state = ? AND stripe_created_at > ?", e.ObjectID, StripeEventProcessed, *e.StripeCreatedAt).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("database error: %w", err)
+	}
+	return count > 0, nil
+}
+
+// FindStripeEvents lists events in the given state, or in any state when
+// state is empty, newest first
+func FindStripeEvents(db *gorm.DB, state string, limit int) ([]StripeEvent, error) {
+	var events []StripeEvent
+	query := db.Order("created_at desc").Limit(limit)
+	if state != "" {
+		query = query.Where("state = ?", state)
+	}
+	if err := query.Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return events, nil
+}
+
+// FindStripeEventByID retrieves a stored event
+func FindStripeEventByID(db *gorm.DB, id uint) (*StripeEvent, error) {
+	var event StripeEvent
+	if err := db.First(&event, id).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// MarkProcessed records that the event has been handled
+func (e *StripeEvent) MarkProcessed(db *gorm.DB) error {
+	now := time.Now()
+	e.Attempts++
+	e.State = StripeEventProcessed
+	e.ProcessedAt = &now
+	return db.Model(e).Updates(map[string]interface{}{
+		"attempts":     e.Attempts,
+		"state":        e.State,
+		"processed_at": now,
+		"last_error":   "",
+	}).Error
+}
+
+// MarkRetry records a failed attempt and schedules the next one
+func (e *StripeEvent) MarkRetry(db *gorm.DB, processErr error, nextAttemptAt time.Time) error {
+	e.Attempts++
+	e.LastError = processErr.Error()
+	e.NextAttemptAt = nextAttemptAt
+	return db.Model(e).Updates(map[string]interface{}{
+		"attempts":        e.Attempts,
+		"last_error":      e.LastError,
+		"next_attempt_at": nextAttemptAt,
+	}).Error
+}
+
+// MarkDead parks the event after its last failed attempt
+func (e *StripeEvent) MarkDead(db *gorm.DB, processErr error) error {
+	e.Attempts++
+	e.State = StripeEventDead
+	e.LastError = processErr.Error()
+	return db.Model(e).Updates(map[string]interface{}{
+		"attempts":   e.Attempts,
+		"state":      e.State,
+		"last_error": e.LastError,
+	}).Error
+}
+
+// Requeue puts a dead event back in the queue with a fresh attempt budget
+func (e *StripeEvent) Requeue(db *gorm.DB) error {
+	now := time.Now()
+	e.State = StripeEventPending
+	e.Attempts = 0
+	e.NextAttemptAt = now
+	return db.Model(e).Updates(map[string]interface{}{
+		"state":           e.State,
+		"attempts":        0,
+		"next_attempt_at": now,
+	}).Error
+}
+
+// FindStripeEventsSince lists events of the given types received since a time,
+// oldest first
+func FindStripeEventsSince(db *gorm.DB, types []string, since time.Time) ([]StripeEvent, error) {
+	var events []StripeEvent
+	err := db.Where("type IN ? AND created_at >= ?", types, since).Order("created_at asc").Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return events, nil
+}
+
+// FindLatestStripeEvents returns the most recent event of the given types for
+// each of the Stripe objects, such as subscriptions, with the given IDs
+func FindLatestStripeEvents(db *gorm.DB, types []string, objectIDs []string) ([]StripeEvent, error) {
+	if len(objectIDs) == 0 {
+		return nil, nil
+	}
+	var events []StripeEvent
+	err := db.Raw(`SELECT DISTINCT ON (payload->>'id') * FROM stripe_events
+		WHERE type IN ? AND payload->>'id' IN ?
+		ORDER BY payload->>'id', created_at DESC, id DESC`, types, objectIDs).Scan(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return events, nil
+}
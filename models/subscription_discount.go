@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SubscriptionDiscount is the coupon applied to the user's subscription, as
+// last reported by Stripe. It is empty when no discount applies.
+type SubscriptionDiscount struct {
+	CouponID        string  `gorm:"type:text" json:"coupon_id,omitempty" example:"SPRING25"`
+	CouponName      string  `gorm:"type:text" json:"coupon_name,omitempty" example:"Spring sale"`
+	PromotionCodeID string  `gorm:"type:text" json:"promotion_code_id,omitempty" example:"promo_1NExampleID"`
+	PercentOff      float64 `json:"percent_off,omitempty" example:"25"`
+	// Amount off in the currency's minor unit, for fixed-amount coupons
+	AmountOff int64  `json:"amount_off,omitempty"`
+	Currency  string `gorm:"type:varchar(3)" json:"currency,omitempty"`
+	// once, repeating or forever
+	Duration string     `gorm:"type:varchar(20)" json:"duration,omitempty" example:"repeating"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+}
+
+// UpdateSubscriptionDiscount stores the discount applied to the user's
+// subscription; the zero value clears it
+func (u *User) UpdateSubscriptionDiscount(db *gorm.DB, discount SubscriptionDiscount) error {
+	u.SubscriptionDiscount = discount
+	return db.Model(u).Updates(map[string]interface{}{
+		"discount_coupon_id":         discount.CouponID,
+		"discount_coupon_name":       discount.CouponName,
+		"discount_promotion_code_id": discount.PromotionCodeID,
+		"discount_percent_off":       discount.PercentOff,
+		"discount_amount_off":        discount.AmountOff,
+		"discount_currency":          discount.Currency,
+		"discount_duration":          discount.Duration,
+		"discount_ends_at":           discount.EndsAt,
+	}).Error
+}
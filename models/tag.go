@@ -0,0 +1,182 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Limits on report tags
+const (
+	MaxTagNameLength = 50
+	MaxTagsPerReport = 20
+)
+
+// ErrTooManyTags is returned when attaching a tag would exceed MaxTagsPerReport
+var ErrTooManyTags = fmt.Errorf("a report can have at most %d tags", MaxTagsPerReport)
+
+// Tag is a label in a report owner's tag set, e.g. "morning" or "post-medication".
+// Names are unique per owner.
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_tags_user_name" json:"user_id"`
+	Name      string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_tags_user_name" json:"name" example:"post-medication"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReportTag attaches a tag to a report
+type ReportTag struct {
+	ReportID uint `gorm:"primaryKey"`
+	TagID    uint `gorm:"primaryKey;index"`
+	// The owner or a linked viewer who attached the tag
+	TaggedBy  uint `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+// TagUsage is a tag with the number of reports carrying it
+type TagUsage struct {
+	Name        string `json:"name" example:"morning"`
+	ReportCount int64  `json:"report_count" example:"12"`
+}
+
+// NormalizeTagName lowercases and trims a tag name and collapses inner
+// whitespace. Names may contain letters, digits, spaces, '-' and '_'.
+func NormalizeTagName(name string) (string, error) {
+	name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+	if name == "" {
+		return "", fmt.Errorf("tag name must not be empty")
+	}
+	if len([]rune(name)) > MaxTagNameLength {
+		return "", fmt.Errorf("tag name must be at most %d characters", MaxTagNameLength)
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != ' ' && r != '-' && r != '_' {
+			return "", fmt.Errorf("tag name may only contain letters, digits, spaces, '-' and '_'")
+		}
+	}
+	return name, nil
+}
+
+// FindOrCreateTag returns the owner's tag with the given normalized name,
+// creating it if needed
+func FindOrCreateTag(db *gorm.DB, userID uint, name string) (*Tag, error) {
+	tag := Tag{UserID: userID, Name: name}
+	// Concurrent requests may create the same tag; the unique index keeps one
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&tag).Error; err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if err := db.Where("user_id = ? AND name = ?", userID, name).First(&tag).Error; err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &tag, nil
+}
+
+// FindUserTags lists the owner's tags by name with how many of their reports carry each
+func FindUserTags(db *gorm.DB, userID uint) ([]TagUsage, error) {
+	var usage []TagUsage
+	err := db.Table("tags").
+		Select("tags.name, COUNT(reports.id) AS report_count").
+		Joins("LEFT JOIN report_tags ON report_tags.tag_id = tags.id").
+		Joins("LEFT JOIN reports ON reports.id = report_tags.report_id AND reports.deleted_at IS NULL").
+		Where("tags.user_id = ?", userID).
+		Group("tags.id, tags.name").
+		Order("tags.name asc").
+		Scan(&usage).Error
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return usage, nil
+}
+
+// AddTag attaches the named tag to the report, creating the tag in the
+// report owner's tag set if needed. Attaching a tag twice has no effect.
+func (r *Report) AddTag(db *gorm.DB, name string, taggedBy uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		tag, err := FindOrCreateTag(tx, r.UserID, name)
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&ReportTag{}).Where("report_id = ?", r.ID).Count(&count).Error; err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		if count >= MaxTagsPerReport {
+			var existing int64
+			if err := tx.Model(&ReportTag{}).Where("report_id = ? AND tag_id = ?", r.ID, tag.ID).Count(&existing).Error; err != nil {
+				return fmt.Errorf("database error: %w", err)
+			}
+			if existing == 0 {
+				return ErrTooManyTags
+			}
+		}
+
+		link := ReportTag{ReportID: r.ID, TagID: tag.ID, TaggedBy: taggedBy}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&link).Error; err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		return nil
+	})
+}
+
+// RemoveTag detaches the named tag from the report
+func (r *Report) RemoveTag(db *gorm.DB, name string) error {
+	result := db.Where("report_id = ? AND tag_id IN (?)", r.ID,
+		db.Model(&Tag{}).Select("id").Where("user_id = ? AND name = ?", r.UserID, name)).
+		Delete(&ReportTag{})
+	if result.Error != nil {
+		return fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("tag not found")
+	}
+	return nil
+}
+
+// LoadReportTags fills in the tag names of each report
+func LoadReportTags(db *gorm.DB, reports []Report) error {
+	if len(reports) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(reports))
+	for i := range reports {
+		ids[i] = reports[i].ID
+	}
+
+	var rows []struct {
+		ReportID uint
+		Name     string
+	}
+	err := db.Table("report_tags").
+		Select("report_tags.report_id, tags.name").
+		Joins("JOIN tags ON tags.id = report_tags.tag_id").
+		Where("report_tags.report_id IN ?", ids).
+		Order("tags.name asc").
+		Scan(&rows).Error
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	byReport := make(map[uint][]string, len(reports))
+	for _, row := range rows {
+		byReport[row.ReportID] = append(byReport[row.ReportID], row.Name)
+	}
+	for i := range reports {
+		reports[i].Tags = byReport[reports[i].ID]
+	}
+	return nil
+}
+
+// LoadTags fills in the report's tag names
+func (r *Report) LoadTags(db *gorm.DB) error {
+	reports := []Report{{ID: r.ID}}
+	if err := LoadReportTags(db, reports); err != nil {
+		return err
+	}
+	r.Tags = reports[0].Tags
+	return nil
+}
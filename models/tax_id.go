@@ -0,0 +1,64 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CustomerTaxID is a tax ID, such as an EU VAT number, of a user's Stripe
+// customer. Stripe holds the tax IDs; this copy is kept in step by the
+// tax ID endpoints and customer.tax_id webhooks.
+type CustomerTaxID struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement" json:"id" example:"3"`
+	UserID      uint   `gorm:"not null;index" json:"user_id" example:"1"`
+	StripeTaxID string `gorm:"type:varchar(255);not null;uniqueIndex" json:"stripe_tax_id" example:"txi_1NExampleID"`
+	// Stripe tax ID type, e.g. eu_vat or gb_vat
+	Type    string `gorm:"type:varchar(20);not null" json:"type" example:"eu_vat"`
+	Value   string `gorm:"type:varchar(255);not null" json:"value" example:"DE123456789"`
+	Country string `gorm:"type:varchar(2)" json:"country,omitempty" example:"DE"`
+	// pending, verified, unverified or unavailable
+	VerificationStatus string    `gorm:"type:varchar(20)" json:"verification_status,omitempty" example:"verified"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// SaveCustomerTaxID stores a tax ID, updating the copy of one already stored
+func SaveCustomerTaxID(db *gorm.DB, taxID *CustomerTaxID) error {
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "stripe_tax_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "type", "value", "country", "verification_status", "updated_at"}),
+	}).Create(taxID).Error
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// FindUserTaxIDs lists the user's tax IDs, oldest first
+func FindUserTaxIDs(db *gorm.DB, userID uint) ([]CustomerTaxID, error) {
+	var taxIDs []CustomerTaxID
+	if err := db.Where("user_id = ?", userID).Order("created_at asc").Find(&taxIDs).Error; err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return taxIDs, nil
+}
+
+// FindUserTaxID retrieves one of the user's tax IDs
+func FindUserTaxID(db *gorm.DB, id, userID uint) (*CustomerTaxID, error) {
+	var taxID CustomerTaxID
+	if err := db.Where("id = ? AND user_id = ?", id, userID).First(&taxID).Error; err != nil {
+		return nil, err
+	}
+	return &taxID, nil
+}
+
+// DeleteCustomerTaxID removes the copy of a tax ID deleted in Stripe
+func DeleteCustomerTaxID(db *gorm.DB, stripeTaxID string) error {
+	if err := db.Where("stripe_tax_id = ?", stripeTaxID).Delete(&CustomerTaxID{}).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
@@ -1,9 +1,14 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BlacklistedToken struct {
@@ -17,13 +22,41 @@ func (bt *BlacklistedToken) IsExpired() bool {
 	return time.Now().After(bt.ExpiresAt)
 }
 
-// AddToBlacklist adds a token to the blacklist
+// BlacklistChannel is the Postgres NOTIFY channel on which newly blacklisted
+// tokens are announced as "<token hash>:<expiry unix seconds>"
+const BlacklistChannel = "token_blacklist"
+
+// HashToken returns the hex SHA-256 of a token, used to refer to blacklisted
+// tokens without holding or broadcasting the token itself
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// AddToBlacklist adds a token to the blacklist and announces it to in-memory
+// blacklist snapshots on BlacklistChannel
 func AddToBlacklist(db *gorm.DB, token string, expiresAt time.Time) error {
 	blacklistedToken := BlacklistedToken{
 		Token:     token,
 		ExpiresAt: expiresAt,
 	}
-	return db.Create(&blacklistedToken).Error
+	if err := db.Create(&blacklistedToken).Error; err != nil {
+		return err
+	}
+
+	// Snapshots also pick the token up on their next refresh, so a lost notification only delays it
+	payload := fmt.Sprintf("%s:%d", HashToken(token), expiresAt.Unix())
+	if err := db.Exec("SELECT pg_notify(?, ?)", BlacklistChannel, payload).Error; err != nil {
+		log.Printf("Failed to announce blacklisted token: %v", err)
+	}
+	return nil
+}
+
+// FindActiveBlacklistedTokens returns the blacklisted tokens that have not yet expired
+func FindActiveBlacklistedTokens(db *gorm.DB) ([]BlacklistedToken, error) {
+	var tokens []BlacklistedToken
+	err := db.Select("token", "expires_at").Where("expires_at >= ?", time.Now()).Find(&tokens).Error
+	return tokens, err
 }
 
 // IsTokenBlacklisted checks if a token is in the blacklist
@@ -43,3 +76,37 @@ func IsTokenBlacklisted(db *gorm.DB, token string) (bool, error) {
 func CleanupExpiredTokens(db *gorm.DB) error {
 	return db.Where("expires_at < ?", time.Now()).Delete(&BlacklistedToken{}).Error
 }
+
+// TokenUse records a client IP seen presenting a token, identified by its jti claim
+type TokenUse struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement"`
+	JTI         string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_token_use_jti_ip"`
+	IP          string    `gorm:"type:varchar(45);not null;uniqueIndex:idx_token_use_jti_ip"`
+	UserID      uint      `gorm:"not null;index"`
+	FirstSeenAt time.Time `gorm:"not null"`
+	LastSeenAt  time.Time `gorm:"not null;index"`
+}
+
+// RecordTokenUse notes that a token was presented from ip
+func RecordTokenUse(db *gorm.DB, jti string, userID uint, ip string) error {
+	now := time.Now()
+	use := TokenUse{JTI: jti, IP: ip, UserID: userID, FirstSeenAt: now, LastSeenAt: now}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "jti"}, {Name: "ip"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"last_seen_at": now}),
+	}).Create(&use).Error
+}
+
+// CountOtherTokenIPs counts the IPs other than ip that presented the token since the given time
+func CountOtherTokenIPs(db *gorm.DB, jti, ip string, since time.Time) (int64, error) {
+	var count int64
+	err := db.Model(&TokenUse{}).
+		Where("jti = ? AND ip <> ? AND last_seen_at > ?", jti, ip, since).
+		Count(&count).Error
+	return count, err
+}
+
+// CleanupTokenUses removes usage records last seen before the cutoff
+func CleanupTokenUses(db *gorm.DB, cutoff time.Time) error {
+	return db.Where("last_seen_at < ?", cutoff).Delete(&TokenUse{}).Error
+}
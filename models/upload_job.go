@@ -0,0 +1,218 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Upload job states
+const (
+	UploadJobQueued    = "queued"
+	UploadJobRunning   = "running"
+	UploadJobCompleted = "completed"
+	UploadJobFailed    = "failed"
+)
+
+// UploadJobStaleAfter is how long a job may stay running before another
+// worker takes it over, e.g. after the process running it stopped
+const UploadJobStaleAfter = 30 * time.Minute
+
+// ErrUploadJobTakenOver is returned when completing a job that another worker
+// claimed after it went stale
+var ErrUploadJobTakenOver = errors.New("upload job was taken over by another worker")
+
+// UploadJob turns an uploaded file into a report in the background: it
+// translates the recording and creates the report with the options given at
+// upload time, so uploads return as soon as the file is stored
+type UploadJob struct {
+	ID     uint   `gorm:"primaryKey;autoIncrement" json:"id" example:"31"`
+	UserID uint   `gorm:"not null;index" json:"user_id" example:"1"`
+	FileID uint   `gorm:"not null;index" json:"file_id" example:"7"`
+	Status string `gorm:"type:varchar(20);not null;index" json:"status" example:"queued"`
+	// Upload options the report is created with
+	MatchingScale int            `gorm:"not null" json:"-"`
+	Metadata      datatypes.JSON `gorm:"type:json" json:"-"`
+	TemplateID    *uint          `json:"-"`
+	Notes         datatypes.JSON `gorm:"type:json" json:"-"`
+	DuplicateOfID *uint          `json:"-"`
+	// Whether the plan's translation quota allowed translating the recording
+	Translate bool `gorm:"not null;default:false" json:"-"`
+	// The device recording the upload claimed, completed with the report
+	DeviceRecordingID *uint `json:"-"`
+	// Set once the report was created
+	ReportID *uint `json:"report_id,omitempty" example:"12"`
+	// Why processing failed
+	Error string `gorm:"type:text" json:"error,omitempty"`
+	// Warnings about the recording, e.g. low signal quality
+	Warnings   datatypes.JSON `gorm:"type:json" json:"warnings,omitempty" swaggertype:"array,string"`
+	CreatedAt  time.Time      `json:"created_at"`
+	StartedAt  *time.Time     `json:"started_at,omitempty"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+}
+
+// Create queues the job
+func (j *UploadJob) Create(db *gorm.DB) error {
+	j.Status = UploadJobQueued
+	if err := db.Create(j).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// FindUploadJobForFile returns the latest job processing one of the user's files
+func FindUploadJobForFile(db *gorm.DB, fileID, userID uint) (*UploadJob, error) {
+	var job UploadJob
+	if err := db.Where("file_id = ? AND user_id = ?", fileID, userID).Order("id desc").First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Claim marks a queued job as running. It returns false when another worker
+// claimed it first.
+func (j *UploadJob) Claim(db *gorm.DB) (bool, error) {
+	now := time.Now()
+	result := db.Model(&UploadJob{}).
+		Where("id = ? AND status = ?", j.ID, UploadJobQueued).
+		Updates(map[string]interface{}{"status": UploadJobRunning, "started_at": now})
+	if result.Error != nil {
+		return false, fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	j.Status = UploadJobRunning
+	j.StartedAt = &now
+	return true, nil
+}
+
+// ClaimUploadJobs marks up to limit queued or stale running jobs as running
+// and returns them, oldest first. A job claimed by another worker in the
+// meantime is skipped.
+func ClaimUploadJobs(db *gorm.DB, limit int) ([]UploadJob, error) {
+	var waiting []UploadJob
+	err := db.Where("status = ? OR (status = ? AND started_at < ?)", UploadJobQueued, UploadJobRunning, time.Now().Add(-UploadJobStaleAfter)).
+		Order("created_at asc").Limit(limit).Find(&waiting).Error
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	claimed := waiting[:0]
+	for _, job := range waiting {
+		now := time.Now()
+		query := db.Model(&UploadJob{}).Where("id = ? AND status = ?", job.ID, job.Status)
+		if job.StartedAt != nil {
+			query = query.Where("started_at = ?", *job.StartedAt)
+		}
+		result := query.Updates(map[string]interface{}{"status": UploadJobRunning, "started_at": now})
+		if result.Error != nil {
+			return claimed, fmt.Errorf("database error: %w", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			job.Status = UploadJobRunning
+			job.StartedAt = &now
+			claimed = append(claimed, job)
+		}
+	}
+	return claimed, nil
+}
+
+// Requeue hands a claimed job back to the queue
+func (j *UploadJob) Requeue(db *gorm.DB) error {
+	j.Status = UploadJobQueued
+	j.StartedAt = nil
+	return db.Model(j).Updates(map[string]interface{}{"status": UploadJobQueued, "started_at": nil}).Error
+}
+
+// Complete records the report the job created and completes the device
+// recording the upload claimed, so retransmissions of it return this report.
+// It returns ErrUploadJobTakenOver when the job is no longer held by the
+// claim that started it.
+func (j *UploadJob) Complete(db *gorm.DB, reportID uint, warnings datatypes.JSON) error {
+	now := time.Now()
+	return db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&UploadJob{}).Where("id = ? AND status = ?", j.ID, UploadJobRunning)
+		if j.StartedAt != nil {
+			query = query.Where("started_at = ?", *j.StartedAt)
+		}
+		result := query.Updates(map[string]interface{}{
+			"status":      UploadJobCompleted,
+			"report_id":   reportID,
+			"warnings":    warnings,
+			"finished_at": now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrUploadJobTakenOver
+		}
+		if j.DeviceRecordingID != nil {
+			if err := tx.Model(&DeviceRecording{}).Where("id = ?", *j.DeviceRecordingID).Update("report_id", reportID).Error; err != nil {
+				return err
+			}
+		}
+		j.Status = UploadJobCompleted
+		j.ReportID = &reportID
+		j.Warnings = warnings
+		j.FinishedAt = &now
+		return nil
+	})
+}
+
+// Fail records why the job failed and forgets the device recording the
+// upload claimed, so the device can send the recording again
+func (j *UploadJob) Fail(db *gorm.DB, reason string) error {
+	now := time.Now()
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(j).Updates(map[string]interface{}{
+			"status":      UploadJobFailed,
+			"error":       reason,
+			"finished_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if j.DeviceRecordingID != nil {
+			if err := tx.Where("id = ?", *j.DeviceRecordingID).Delete(&DeviceRecording{}).Error; err != nil {
+				return err
+			}
+		}
+		j.Status = UploadJobFailed
+		j.Error = reason
+		j.FinishedAt = &now
+		return nil
+	})
+}
+
+// UploadQueueStats describes the upload jobs waiting for or being processed by a worker
+type UploadQueueStats struct {
+	Queued  int64
+	Running int64
+	// When the longest-waiting job was queued; nil when none is
+	OldestQueuedAt *time.Time
+}
+
+// CountUploadQueue counts queued and running upload jobs across all workers
+func CountUploadQueue(db *gorm.DB) (UploadQueueStats, error) {
+	var row struct {
+		Queued         int64
+		Running        int64
+		OldestQueuedAt *time.Time
+	}
+	err := db.Model(&UploadJob{}).
+		Select("COUNT(*) FILTER (WHERE status = ?) AS queued, "+
+			"COUNT(*) FILTER (WHERE status = ?) AS running, "+
+			"MIN(created_at) FILTER (WHERE status = ?) AS oldest_queued_at",
+			UploadJobQueued, UploadJobRunning, UploadJobQueued).
+		Where("status IN ?", []string{UploadJobQueued, UploadJobRunning}).
+		Scan(&row).Error
+	if err != nil {
+		return UploadQueueStats{}, fmt.Errorf("database error: %w", err)
+	}
+	return UploadQueueStats{Queued: row.Queued, Running: row.Running, OldestQueuedAt: row.OldestQueuedAt}, nil
+}
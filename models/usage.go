@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UsageCounter tracks a user's metered usage for one calendar month (UTC)
+type UsageCounter struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"-"`
+	UserID       uint      `gorm:"not null;uniqueIndex:idx_usage_user_period" json:"user_id"`
+	Period       string    `gorm:"type:varchar(7);not null;uniqueIndex:idx_usage_user_period" json:"period"`
+	Uploads      int       `gorm:"not null;default:0" json:"uploads"`
+	Translations int       `gorm:"not null;default:0" json:"translations"`
+	UploadBytes  int64     `gorm:"not null;default:0" json:"upload_bytes"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// UsagePeriod returns the billing period key (YYYY-MM, UTC) containing t
+func UsagePeriod(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// FindUsage returns the user's usage for a period, or an empty counter if nothing was recorded
+func FindUsage(db *gorm.DB, userID uint, period string) (*UsageCounter, error) {
+	usage := UsageCounter{UserID: userID, Period: period}
+	if err := db.Where("user_id = ? AND period = ?", userID, period).First(&usage).Error; err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// IncrementUsage atomically adds to the user's counters for a period
+func IncrementUsage(db *gorm.DB, userID uint, period string, uploads int, uploadBytes int64, translations int) error {
+	usage := &UsageCounter{UserID: userID, Period: period, Uploads: uploads, UploadBytes: uploadBytes, Translations: translations}
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "period"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"uploads":      gorm.Expr("usage_counters.uploads + ?", uploads),
+			"translations": gorm.Expr("usage_counters.translations + ?", translations),
+			"upload_bytes": gorm.Expr("usage_counters.upload_bytes + ?", uploadBytes),
+			"updated_at":   time.Now(),
+		}),
+	}).Create(usage).Error
+}
+
+// PendingUploadUsage returns what the user's queued and running upload jobs
+// will add to their usage once they finish: one upload each, the size of
+// their files and a translation for each job allowed to translate
+func PendingUploadUsage(db *gorm.DB, userID uint) (*UsageCounter, error) {
+	usage := UsageCounter{UserID: userID}
+	err := db.Model(&UploadJob{}).
+		Joins("JOIN single_files ON single_files.id = upload_jobs.file_id").
+		Where("upload_jobs.user_id = ? AND upload_jobs.status IN ?", userID, []string{UploadJobQueued, UploadJobRunning}).
+		Select("COUNT(*) AS uploads, COALESCE(SUM(single_files.file_size), 0) AS upload_bytes, " +
+			"COUNT(*) FILTER (WHERE upload_jobs.translate) AS translations").
+		Scan(&usage).Error
+	if err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// StorageUsedByUser returns the bytes the user has stored: the size of their
+// reports, and of uploaded files no report was made from yet, such as files
+// waiting for processing or whose processing failed. Quarantined files are
+// not counted.
+func StorageUsedByUser(db *gorm.DB, userID uint) (int64, error) {
+	var reports int64
+	err := db.Model(&Report{}).Where("user_id = ?", userID).
+		Select("COALESCE(SUM(size_bytes), 0)").Scan(&reports).Error
+	if err != nil {
+		return 0, err
+	}
+
+	// Files become reports of the same size; deleted reports leave their file unlinked
+	var files int64
+	err = db.Model(&SingleFile{}).
+		Where("user_id = ? AND quarantined_at IS NULL", userID).
+		Where("id NOT IN (?)", db.Unscoped().Model(&Report{}).Select("source_file_id").
+			Where("user_id = ? AND source_file_id IS NOT NULL", userID)).
+		Select("COALESCE(SUM(file_size), 0)").Scan(&files).Error
+	if err != nil {
+		return 0, err
+	}
+	return reports + files, nil
+}
@@ -4,66 +4,76 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/stripe/stripe-go/v72"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
+// ErrUserNotFound is returned when looking up a user that does not exist
+var ErrUserNotFound = errors.New("user not found")
+
 type User struct {
-	ID           uint           `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name         string         `gorm:"type:text;not null" json:"name"`
-	Email        string         `gorm:"type:text;unique;not null" json:"email"`
-	PasswordHash string         `gorm:"type:text;not null" json:"password"`
-	DateOfBirth  time.Time      `gorm:"type:date;not null" json:"date_of_birth"`
-	Mobile       string         `gorm:"type:varchar(15)" json:"mobile,omitempty"`
-	CountryCode  string         `gorm:"type:varchar(5)" json:"country_code,omitempty"`
-	Address      string         `gorm:"type:text" json:"address,omitempty"`
-	City         string         `gorm:"type:text" json:"city,omitempty"`
-	Country      string         `gorm:"type:text" json:"country,omitempty"`
-	PostalCode   string         `gorm:"type:text" json:"postal_code,omitempty"`
-	PaymentInfo  datatypes.JSON `gorm:"type:json" json:"payment_info,omitempty" swaggertype:"string" example:"{\"card_type\":\"visa\"}"`
-	CreatedAt    time.Time      `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
-	LastLogin    *time.Time     `gorm:"type:timestamp" json:"last_login,omitempty"`
-	Reports      []Report       `gorm:"foreignKey:UserID" json:"reports"`
+	ID             uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name           string         `gorm:"type:text;not null" json:"name"`
+	Email          string         `gorm:"type:text;unique;not null" json:"email"`
+	PasswordHash   string         `gorm:"type:text;not null" json:"password"`
+	DateOfBirth    time.Time      `gorm:"type:date;not null" json:"date_of_birth"`
+	Mobile         string         `gorm:"type:varchar(15)" json:"mobile,omitempty"`
+	CountryCode    string         `gorm:"type:varchar(5)" json:"country_code,omitempty"`
+	Address        string         `gorm:"type:text" json:"address,omitempty"`
+	City           string         `gorm:"type:text" json:"city,omitempty"`
+	Country        string         `gorm:"type:text" json:"country,omitempty"`
+	PostalCode     string         `gorm:"type:text" json:"postal_code,omitempty"`
+	Timezone       string         `gorm:"type:varchar(64);not null;default:'UTC'" json:"timezone"`
+	Locale         string         `gorm:"type:varchar(35);not null;default:'en-US'" json:"locale"`
+	PaymentInfo    datatypes.JSON `gorm:"type:json" json:"payment_info,omitempty" swaggertype:"string" example:"{\"card_type\":\"visa\"}"`
+	CreatedAt      time.Time      `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	LastLogin      *time.Time     `gorm:"type:timestamp" json:"last_login,omitempty"`
+	Role           string         `gorm:"type:varchar(20);not null;default:'user'" json:"role"`
+	OrganizationID *uint          `gorm:"index" json:"organization_id,omitempty"`
+	OrgRole        string         `gorm:"type:varchar(20);not null;default:'member'" json:"org_role,omitempty"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty" swaggertype:"string"`
+	Reports        []Report       `gorm:"foreignKey:UserID" json:"reports"`
+	// Onboarding progress; each timestamp is set the first time the step is reached
+	EmailVerifiedAt       *time.Time `gorm:"type:timestamp" json:"email_verified_at,omitempty"`
+	FirstUploadAt         *time.Time `gorm:"type:timestamp" json:"first_upload_at,omitempty"`
+	FirstReportAt         *time.Time `gorm:"type:timestamp" json:"first_report_at,omitempty"`
+	FirstSubscribedAt     *time.Time `gorm:"type:timestamp" json:"first_subscribed_at,omitempty"`
+	OnboardingCompletedAt *time.Time `gorm:"type:timestamp" json:"onboarding_completed_at,omitempty"`
+	// Set for accounts created with a temporary password; cleared when the password is changed
+	MustChangePassword bool `gorm:"not null;default:false" json:"must_change_password"`
+	// Set by the inactive-user job; cleared on the next sign-in
+	InactiveFlaggedAt *time.Time `gorm:"type:timestamp" json:"inactive_flagged_at,omitempty"`
+	// Raw recording retention after a subscription lapses
+	RecordingsStatus      string     `gorm:"type:varchar(20);not null;default:'active'" json:"recordings_status"`
+	RecordingsAction      *string    `gorm:"type:varchar(20)" json:"recordings_action,omitempty"`
+	RecordingsActionDueAt *time.Time `gorm:"type:timestamp" json:"recordings_action_due_at,omitempty"`
+	// Post-processors applied to translations, in order; null uses the organization's choice
+	TextProcessors datatypes.JSON `gorm:"type:json" json:"text_processors,omitempty" swaggertype:"array,string" example:"punctuation,casing"`
+	// Report translations are stored encrypted with a per-user key
+	EncryptReportText bool `gorm:"not null;default:false" json:"encrypt_report_text"`
 	// Stripe fields
-	StripeCustomerID   *string    `gorm:"type:text;uniqueIndex" json:"stripe_customer_id,omitempty"`
-	StripeDefaultPM    *string    `gorm:"type:text" json:"stripe_default_payment_method,omitempty"`
-	CurrentPlanID      *string    `gorm:"type:text" json:"current_plan_id,omitempty"`
+	StripeCustomerID *string `gorm:"type:text;uniqueIndex" json:"stripe_customer_id,omitempty"`
+	StripeDefaultPM  *string `gorm:"type:text" json:"stripe_default_payment_method,omitempty"`
+	CurrentPlanID    *string `gorm:"type:text" json:"current_plan_id,omitempty"`
+	// Where the subscription was bought: empty for Stripe, app_store or google_play
+	SubscriptionStore  string     `gorm:"type:varchar(20)" json:"subscription_store,omitempty"`
 	SubscriptionID     *string    `gorm:"type:text" json:"subscription_id,omitempty"`
 	SubscriptionStatus *string    `gorm:"type:text" json:"subscription_status,omitempty"`
 	SubscriptionEndsAt *time.Time `gorm:"type:timestamp" json:"subscription_ends_at,omitempty"`
-}
-
-// New function for Stripe integration
-
-// ToStripeCustomerParams converts user data to Stripe customer parameters
-func (u *User) ToStripeCustomerParams() *stripe.CustomerParams {
-	params := &stripe.CustomerParams{
-		Name:  stripe.String(u.Name),
-		Email: stripe.String(u.Email),
-	}
-
-	if u.Mobile != "" && u.CountryCode != "" {
-		phone := u.CountryCode + u.Mobile
-		params.Phone = stripe.String(phone)
-	}
-
-	if u.Address != "" && u.City != "" && u.Country != "" {
-		params.Address = &stripe.AddressParams{
-			Line1:      stripe.String(u.Address),
-			City:       stripe.String(u.City),
-			Country:    stripe.String(u.Country),
-			PostalCode: stripe.String(u.PostalCode),
-		}
-	}
-
-	return params
+	// Set while a renewal payment has failed; subscriber entitlements are
+	// kept until the grace period ends
+	PastDueSince       *time.Time `gorm:"type:timestamp" json:"past_due_since,omitempty"`
+	PaymentGraceEndsAt *time.Time `gorm:"type:timestamp" json:"payment_grace_ends_at,omitempty"`
+	// Coupon applied to the subscription, kept in step by Stripe webhooks
+	SubscriptionDiscount SubscriptionDiscount `gorm:"embedded;embeddedPrefix:discount_" json:"subscription_discount"`
 }
 
 // UpdateStripeData updates the Stripe-related user data
@@ -76,33 +86,204 @@ func (u *User) UpdateStripeData(db *gorm.DB, customerID string, defaultPM string
 	}).Error
 }
 
-// UpdateSubscriptionData updates the subscription data for the user
+// UpdateSubscriptionData updates the Stripe subscription data for the user.
+// Any status but past_due or unpaid ends a payment grace period.
 func (u *User) UpdateSubscriptionData(db *gorm.DB, subscriptionID, planID, status string, endsAt *time.Time) error {
+	u.SubscriptionStore = ""
 	u.SubscriptionID = &subscriptionID
 	u.CurrentPlanID = &planID
 	u.SubscriptionStatus = &status
 	u.SubscriptionEndsAt = endsAt
 
-	return db.Model(u).Updates(map[string]interface{}{
+	updates := map[string]interface{}{
+		"subscription_store":   "",
 		"subscription_id":      subscriptionID,
 		"current_plan_id":      planID,
 		"subscription_status":  status,
 		"subscription_ends_at": endsAt,
-	}).Error
+	}
+	if status != SubscriptionStatusPastDue && status != SubscriptionStatusUnpaid {
+		u.PastDueSince = nil
+		u.PaymentGraceEndsAt = nil
+		updates["past_due_since"] = nil
+		updates["payment_grace_ends_at"] = nil
+	}
+	return db.Model(u).Updates(updates).Error
+}
+
+// User roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// IsAdmin checks if the user has the admin role
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// SetTextProcessors stores the post-processors applied to the user's
+// translations; nil falls back to their organization's choice
+func (u *User) SetTextProcessors(db *gorm.DB, processors datatypes.JSON) error {
+	u.TextProcessors = processors
+	return db.Model(u).Update("text_processors", processors).Error
+}
+
+// FindAdmins retrieves every admin user, e.g. to alert them
+func FindAdmins(db *gorm.DB) ([]User, error) {
+	var admins []User
+	if err := db.Where("role = ?", RoleAdmin).Find(&admins).Error; err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return admins, nil
+}
+
+// SubscriptionStatusExpired is set locally when a subscription period ends without a renewal webhook
+const SubscriptionStatusExpired = "expired"
+
+// SubscriptionStatusPastDue is Stripe's status of a subscription whose renewal payment failed
+const SubscriptionStatusPastDue = "past_due"
+
+// SubscriptionStatusUnpaid is set locally when the payment grace period of a
+// past-due subscription ends
+const SubscriptionStatusUnpaid = "unpaid"
+
+// FindSubscribers retrieves the billing state of users whose subscription is
+// live locally: active, trialing or past due
+func FindSubscribers(db *gorm.DB) ([]User, error) {
+	var users []User
+	err := db.Select("id", "subscription_store", "subscription_id", "current_plan_id", "subscription_status", "payment_grace_ends_at").
+		Where("subscription_status IN ?", []string{"active", "trialing", SubscriptionStatusPastDue}).Find(&users).Error
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return users, nil
+}
+
+// FindLapsedSubscriptions retrieves users who still hold an active subscription
+// whose period ended before cutoff. Past-due subscriptions in a payment grace
+// period are left to the grace period.
+func FindLapsedSubscriptions(db *gorm.DB, cutoff time.Time) ([]User, error) {
+	var users []User
+	err := db.Where("(subscription_status IN ? OR (subscription_status = ? AND past_due_since IS NULL)) AND subscription_ends_at IS NOT NULL AND subscription_ends_at < ?",
+		[]string{"active", "trialing"}, SubscriptionStatusPastDue, cutoff).Find(&users).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch lapsed subscriptions: %w", err)
+	}
+	return users, nil
+}
+
+// ExpireSubscription marks the user's subscription as expired, revoking
+// subscriber entitlements, if it still has the status it was loaded with and
+// its period ended by cutoff. It reports false, changing nothing, when a
+// renewal or cancellation changed the subscription in the meantime.
+func (u *User) ExpireSubscription(db *gorm.DB, cutoff time.Time) (bool, error) {
+	if u.SubscriptionStatus == nil {
+		return false, nil
+	}
+	result := db.Model(&User{}).
+		Where("id = ? AND subscription_status = ? AND subscription_ends_at <= ?", u.ID, *u.SubscriptionStatus, cutoff).
+		Update("subscription_status", SubscriptionStatusExpired)
+	if result.Error != nil {
+		return false, fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	status := SubscriptionStatusExpired
+	u.SubscriptionStatus = &status
+	return true, nil
 }
 
-// IsSubscribed checks if the user has an active subscription
+// IsSubscribed checks if the user has an active subscription, or a past-due
+// one within its payment grace period
 func (u *User) IsSubscribed() bool {
 	if u.SubscriptionStatus == nil {
 		return false
 	}
-	return *u.SubscriptionStatus == "active" || *u.SubscriptionStatus == "trialing"
+	switch *u.SubscriptionStatus {
+	case "active", "trialing":
+		return true
+	case SubscriptionStatusPastDue:
+		return u.PaymentGraceEndsAt != nil && time.Now().Before(*u.PaymentGraceEndsAt)
+	}
+	return false
+}
+
+// StartPaymentGrace marks the user's subscription past due with a grace
+// period ending at graceEndsAt. It reports false, changing nothing, when the
+// subscription is already past due.
+func (u *User) StartPaymentGrace(db *gorm.DB, graceEndsAt time.Time) (bool, error) {
+	now := time.Now()
+	result := db.Model(&User{}).Where("id = ? AND past_due_since IS NULL", u.ID).Updates(map[string]interface{}{
+		"subscription_status":   SubscriptionStatusPastDue,
+		"past_due_since":        now,
+		"payment_grace_ends_at": graceEndsAt,
+	})
+	if result.Error != nil {
+		return false, fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	status := SubscriptionStatusPastDue
+	u.SubscriptionStatus = &status
+	u.PastDueSince = &now
+	u.PaymentGraceEndsAt = &graceEndsAt
+	return true, nil
+}
+
+// FindLapsedPaymentGrace retrieves users whose subscription is still past due
+// after its payment grace period ended
+func FindLapsedPaymentGrace(db *gorm.DB, now time.Time) ([]User, error) {
+	var users []User
+	err := db.Where("subscription_status = ? AND payment_grace_ends_at IS NOT NULL AND payment_grace_ends_at < ?",
+		SubscriptionStatusPastDue, now).Find(&users).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch lapsed payment grace periods: %w", err)
+	}
+	return users, nil
+}
+
+// FindUserByStripeCustomerID retrieves the user holding a Stripe customer, or
+// nil if no user does
+func FindUserByStripeCustomerID(db *gorm.DB, customerID string) (*User, error) {
+	var user User
+	err := db.Where("stripe_customer_id = ?", customerID).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &user, nil
+}
+
+// FindUsersWithLiveSubscription retrieves users whose Stripe subscription is
+// active, trialing or past due locally
+func FindUsersWithLiveSubscription(db *gorm.DB) ([]User, error) {
+	var users []User
+	err := db.Where("(subscription_store IS NULL OR subscription_store = '') AND subscription_id IS NOT NULL AND subscription_id <> '' AND subscription_status IN ?",
+		[]string{"active", "trialing", SubscriptionStatusPastDue}).Find(&users).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch subscribed users: %w", err)
+	}
+	return users, nil
+}
+
+// MarkSubscriptionUnpaid downgrades a past-due subscription whose grace period
+// ended, revoking subscriber entitlements until a payment succeeds
+func (u *User) MarkSubscriptionUnpaid(db *gorm.DB) error {
+	status := SubscriptionStatusUnpaid
+	u.SubscriptionStatus = &status
+	return db.Model(u).Update("subscription_status", status).Error
 }
 
 // Original User functions
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 	var existingUser User
-	if err := tx.Where("email = ?", u.Email).First(&existingUser).Error; err == nil {
+	if err := tx.Unscoped().Where("email = ?", u.Email).First(&existingUser).Error; err == nil {
 		return fmt.Errorf("email already exists")
 	}
 
@@ -111,7 +292,7 @@ func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 
 func (u *User) BeforeSave(tx *gorm.DB) (err error) {
 	var existingUser User
-	result := tx.Where("email = ?", u.Email).First(&existingUser)
+	result := tx.Unscoped().Where("email = ?", u.Email).First(&existingUser)
 
 	if u.ID == 0 && result.Error == nil {
 		return fmt.Errorf("email already exists")
@@ -138,16 +319,48 @@ func (u *User) HashPassword(password string) error {
 	return nil
 }
 
-// GenerateJWT creates a JWT token for the user
+// Token audiences: the kind of client a JWT was issued to
+const (
+	TokenAudienceWeb    = "web"
+	TokenAudienceMobile = "mobile"
+	TokenAudienceDevice = "device"
+)
+
+// TokenLifetimes is how long tokens of each audience stay valid. Device tokens
+// outlive the others because headsets cannot sign in again, but may only call
+// the ingestion endpoints.
+var TokenLifetimes = map[string]time.Duration{
+	TokenAudienceWeb:    24 * time.Hour,
+	TokenAudienceMobile: 7 * 24 * time.Hour,
+	TokenAudienceDevice: 30 * 24 * time.Hour,
+}
+
+// GenerateJWT creates a web JWT token for the user
 func (u *User) GenerateJWT() (string, error) {
-	// Set JWT expiration to 24 hours
-	expirationTime := time.Now().Add(24 * time.Hour)
+	return u.GenerateAudienceJWT(TokenAudienceWeb, "")
+}
+
+// GenerateAudienceJWT creates a JWT token for the user scoped to a client
+// audience. deviceID names the headset a device token is issued to.
+func (u *User) GenerateAudienceJWT(audience, deviceID string) (string, error) {
+	lifetime, ok := TokenLifetimes[audience]
+	if !ok {
+		return "", fmt.Errorf("unknown token audience %q", audience)
+	}
+	expirationTime := time.Now().Add(lifetime)
 
+	// jti uniquely identifies the token so its use can be tracked and revoked
 	claims := jwt.MapClaims{
 		"userID": u.ID,
 		"email":  u.Email,
+		"aud":    audience,
+		"jti":    uuid.NewString(),
+		"iat":    time.Now().Unix(),
 		"exp":    expirationTime.Unix(),
 	}
+	if audience == TokenAudienceDevice {
+		claims["deviceID"] = deviceID
+	}
 
 	// Get JWT secret from environment variable or use a default for development
 	jwtSecret := utils.GetEnvWithDefault("JWT_SECRET", "your_jwt_secret")
@@ -158,18 +371,68 @@ func (u *User) GenerateJWT() (string, error) {
 	return tokenString, err
 }
 
-// UpdateLastLogin updates the user's last login timestamp
-func (u *User) UpdateLastLogin(db *gorm.DB) error {
+// TokenAudience returns the audience a token was issued to. Tokens issued
+// before audiences existed are web tokens.
+func TokenAudience(claims jwt.MapClaims) string {
+	audience, err := claims.GetAudience()
+	if err != nil || len(audience) == 0 {
+		return TokenAudienceWeb
+	}
+	return audience[0]
+}
+
+// MLServiceRoute stands for calls to the ML service in AudienceAllowed
+const MLServiceRoute = "ML service"
+
+// deviceRoutes are the only routes device tokens may call: recording
+// ingestion. A leaked headset token cannot read reports, change the account
+// or call the ML service.
+var deviceRoutes = map[string]bool{
+	"POST /upload":         true,
+	"POST /upload/session": true,
+}
+
+// AudienceAllowed reports whether a token issued to audience may call route,
+// given as "METHOD /path/pattern" or MLServiceRoute
+func AudienceAllowed(audience, route string) bool {
+	switch audience {
+	case TokenAudienceWeb, TokenAudienceMobile:
+		return true
+	case TokenAudienceDevice:
+		return deviceRoutes[route]
+	default:
+		return false
+	}
+}
+
+// FormatTimestamp formats t in the user's time zone and locale
+func (u *User) FormatTimestamp(t time.Time) string {
+	return utils.FormatLocalTime(t, u.Timezone, u.Locale)
+}
+
+// UpdateLastLogin updates the user's last login timestamp, clears any inactivity
+// flag and appends the sign-in to the login history
+func (u *User) UpdateLastLogin(db *gorm.DB, ip, userAgent string) error {
 	now := time.Now()
-	u.LastLogin = &now
-	return db.Model(u).Update("last_login", now).Error
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(u).Updates(map[string]interface{}{
+			"last_login":          now,
+			"inactive_flagged_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+		u.LastLogin = &now
+		u.InactiveFlaggedAt = nil
+
+		return tx.Create(&LoginEvent{UserID: u.ID, IP: ip, UserAgent: userAgent, CreatedAt: now}).Error
+	})
 }
 
 // CreateUser creates a new user in the database with the provided information
-func CreateUser(db *gorm.DB, name, email, password string, dateOfBirth time.Time, mobile, countryCode, address, city, country, postalCode string, paymentInfo map[string]interface{}) (*User, error) {
-	// Check if user with email already exists
+func CreateUser(db *gorm.DB, name, email, password string, dateOfBirth time.Time, mobile, countryCode, address, city, country, postalCode, timezone, locale string, paymentInfo map[string]interface{}) (*User, error) {
+	// Check if user with email already exists, including deactivated accounts
 	var existingUser User
-	if err := db.Where("email = ?", email).First(&existingUser).Error; err == nil {
+	if err := db.Unscoped().Where("email = ?", email).First(&existingUser).Error; err == nil {
 		return nil, fmt.Errorf("email already exists")
 	} else if err != gorm.ErrRecordNotFound {
 		return nil, err
@@ -185,6 +448,13 @@ func CreateUser(db *gorm.DB, name, email, password string, dateOfBirth time.Time
 		paymentInfoJSON = datatypes.JSON(paymentInfoBytes)
 	}
 
+	if timezone == "" {
+		timezone = utils.DefaultTimezone
+	}
+	if locale == "" {
+		locale = utils.DefaultLocale
+	}
+
 	// Create new user
 	user := &User{
 		Name:        name,
@@ -196,6 +466,8 @@ func CreateUser(db *gorm.DB, name, email, password string, dateOfBirth time.Time
 		City:        city,
 		Country:     country,
 		PostalCode:  postalCode,
+		Timezone:    timezone,
+		Locale:      locale,
 		PaymentInfo: paymentInfoJSON,
 		CreatedAt:   time.Now(),
 	}
@@ -225,24 +497,53 @@ func (u *User) FindAllUserReports(db *gorm.DB) ([]Report, error) {
 	return reports, nil
 }
 
-// FindAllUserReportsSortedByScale retrieves all reports belonging to the user sorted by matching scale
-func (u *User) FindAllUserReportsSortedByScale(db *gorm.DB, ascending bool) ([]Report, error) {
-	var reports []Report
+// FindUserReportsPage retrieves one page of the user's reports matching the
+// filter, in the page's sort order or newest first. A cursor takes precedence
+// over the offset; sorted pages carry no next cursor.
+func (u *User) FindUserReportsPage(db *gorm.DB, filter ReportFilter, page Page) ([]Report, PageInfo, error) {
+	var info PageInfo
 
-	query := db.Where("user_id = ?", u.ID)
+	if err := filter.Apply(db.Model(&Report{}).Where("user_id = ?", u.ID)).Count(&info.Total).Error; err != nil {
+		return nil, info, fmt.Errorf("failed to count reports: %w", err)
+	}
+
+	query := filter.Apply(db.Where("user_id = ?", u.ID))
 
-	if ascending {
-		query = query.Order("matching_scale asc")
-	} else {
-		query = query.Order("matching_scale desc")
+	if len(page.Sort) > 0 {
+		var reports []Report
+		if err := applySort(query, page.Sort).Offset(page.Offset).Limit(page.Limit + 1).Find(&reports).Error; err != nil {
+			return nil, info, fmt.Errorf("failed to fetch reports: %w", err)
+		}
+		if len(reports) > page.Limit {
+			reports = reports[:page.Limit]
+			info.HasMore = true
+		}
+		return reports, info, nil
 	}
 
-	err := query.Find(&reports).Error
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch sorted reports: %w", err)
+	if page.Cursor != "" {
+		var err error
+		if query, err = afterCursor(query, "created_at", "id", page.Cursor); err != nil {
+			return nil, info, err
+		}
+	} else if page.Offset > 0 {
+		query = query.Offset(page.Offset)
 	}
 
-	return reports, nil
+	// Fetch one extra row to learn whether another page follows
+	var reports []Report
+	if err := query.Order("created_at desc, id desc").Limit(page.Limit + 1).Find(&reports).Error; err != nil {
+		return nil, info, fmt.Errorf("failed to fetch reports: %w", err)
+	}
+
+	if len(reports) > page.Limit {
+		reports = reports[:page.Limit]
+		info.HasMore = true
+		last := reports[len(reports)-1]
+		info.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return reports, info, nil
 }
 
 // FindUserByID retrieves a user by their ID
@@ -250,19 +551,37 @@ func FindUserByID(db *gorm.DB, id uint) (*User, error) {
 	var user User
 	if err := db.First(&user, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("database error: %w", err)
 	}
 	return &user, nil
 }
 
+// Deactivate soft-deletes the user. The account and its reports are kept but
+// the user can no longer sign in or use existing tokens.
+func (u *User) Deactivate(db *gorm.DB) error {
+	return db.Delete(u).Error
+}
+
+// ReactivateUser restores a deactivated user
+func ReactivateUser(db *gorm.DB, id uint) (*User, error) {
+	result := db.Unscoped().Model(&User{}).Where("id = ? AND deleted_at IS NOT NULL", id).Update("deleted_at", nil)
+	if result.Error != nil {
+		return nil, fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("user not found or not deactivated")
+	}
+	return FindUserByID(db, id)
+}
+
 // FindUserByEmail retrieves a user by their email address
 func FindUserByEmail(db *gorm.DB, email string) (*User, error) {
 	var user User
 	if err := db.Where("email = ?", email).First(&user).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("database error: %w", err)
 	}
@@ -278,8 +597,24 @@ type PasswordReset struct {
 	Used      bool      `gorm:"default:false"`
 }
 
+// InvitationTokenTTL is how long the set-password link of an account created
+// for someone else, e.g. by a user import, stays valid
+const InvitationTokenTTL = 7 * 24 * time.Hour
+
 // GeneratePasswordResetToken creates a token for password reset
 func (u *User) GeneratePasswordResetToken(db *gorm.DB) (string, error) {
+	return u.generatePasswordToken(db, 1*time.Hour)
+}
+
+// GenerateInvitationToken creates a single-use token with which the user
+// chooses the password of an account created for them
+func (u *User) GenerateInvitationToken(db *gorm.DB) (string, error) {
+	return u.generatePasswordToken(db, InvitationTokenTTL)
+}
+
+// generatePasswordToken creates a single-use token for setting the password
+// that expires after ttl
+func (u *User) generatePasswordToken(db *gorm.DB, ttl time.Duration) (string, error) {
 	// Generate random token
 	b := make([]byte, 32)
 	_, err := rand.Read(b)
@@ -289,14 +624,11 @@ func (u *User) GeneratePasswordResetToken(db *gorm.DB) (string, error) {
 
 	token := base64.URLEncoding.EncodeToString(b)
 
-	// Set expiration to 1 hour from now
-	expiresAt := time.Now().Add(1 * time.Hour)
-
 	// Create password reset record
 	reset := PasswordReset{
 		UserID:    u.ID,
 		Token:     token,
-		ExpiresAt: expiresAt,
+		ExpiresAt: time.Now().Add(ttl),
 		Used:      false,
 	}
 
@@ -339,6 +671,10 @@ func (u *User) UpdatePassword(db *gorm.DB, newPassword string) error {
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	// Update in database
-	return db.Model(u).Update("password_hash", u.PasswordHash).Error
+	// Update in database; a chosen password replaces any temporary one
+	u.MustChangePassword = false
+	return db.Model(u).Updates(map[string]interface{}{
+		"password_hash":        u.PasswordHash,
+		"must_change_password": false,
+	}).Error
 }
@@ -0,0 +1,213 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"gorm.io/gorm"
+)
+
+// Link statuses
+const (
+	LinkStatusPending  = "pending"
+	LinkStatusAccepted = "accepted"
+	LinkStatusRevoked  = "revoked"
+)
+
+// Link relationships
+const (
+	LinkRelationshipCaregiver = "caregiver"
+	LinkRelationshipClinician = "clinician"
+)
+
+// LinkInvitationTTL is how long an invitation can be accepted
+const LinkInvitationTTL = 7 * 24 * time.Hour
+
+// UserLink grants a viewer (caregiver or clinician) read access to an owner's reports.
+// Access is view/download only and ends when the link is revoked or AccessExpiresAt passes.
+type UserLink struct {
+	ID              uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	OwnerID         uint       `gorm:"not null;index" json:"owner_id"`
+	ViewerID        *uint      `gorm:"index" json:"viewer_id,omitempty"`
+	InviteEmail     string     `gorm:"type:text;not null" json:"invite_email"`
+	Relationship    string     `gorm:"type:varchar(20);not null" json:"relationship"`
+	Status          string     `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	Token           string     `gorm:"type:text;uniqueIndex;not null" json:"-"`
+	ExpiresAt       time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt      *time.Time `json:"accepted_at,omitempty"`
+	AccessExpiresAt *time.Time `gorm:"index" json:"access_expires_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// CreateLinkInvitation creates a pending invitation from owner to the given email.
+// accessExpiresAt optionally limits how long the accepted grant lasts.
+func CreateLinkInvitation(db *gorm.DB, owner *User, email, relationship string, accessExpiresAt *time.Time) (*UserLink, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == strings.ToLower(owner.Email) {
+		return nil, fmt.Errorf("you cannot invite yourself")
+	}
+	if accessExpiresAt != nil && !accessExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("access expiry must be in the future")
+	}
+
+	var existing int64
+	if err := db.Model(&UserLink{}).
+		Where("owner_id = ? AND invite_email = ? AND status IN ?", owner.ID, email, []string{LinkStatusPending, LinkStatusAccepted}).
+		Count(&existing).Error; err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if existing > 0 {
+		return nil, fmt.Errorf("an invitation for this email already exists")
+	}
+
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	link := &UserLink{
+		OwnerID:         owner.ID,
+		InviteEmail:     email,
+		Relationship:    relationship,
+		Status:          LinkStatusPending,
+		Token:           token,
+		ExpiresAt:       time.Now().Add(LinkInvitationTTL),
+		AccessExpiresAt: accessExpiresAt,
+	}
+	if err := db.Create(link).Error; err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	return link, nil
+}
+
+// AcceptLinkInvitation binds a pending invitation to the viewer. The viewer's
+// email must match the invited address.
+func AcceptLinkInvitation(db *gorm.DB, token string, viewer *User) (*UserLink, error) {
+	var link UserLink
+	if err := db.Where("token = ? AND status = ? AND expires_at > ?", token, LinkStatusPending, time.Now()).First(&link).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("invalid or expired invitation")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if !strings.EqualFold(link.InviteEmail, viewer.Email) {
+		return nil, fmt.Errorf("this invitation was sent to a different email address")
+	}
+	if link.OwnerID == viewer.ID {
+		return nil, fmt.Errorf("you cannot accept your own invitation")
+	}
+
+	now := time.Now()
+	if link.AccessExpiresAt != nil && !link.AccessExpiresAt.After(now) {
+		return nil, fmt.Errorf("access granted by this invitation has already expired")
+	}
+
+	// Only a still pending invitation is accepted, so a revoke racing the
+	// accept is never overwritten
+	result := db.Model(&UserLink{}).Where("id = ? AND status = ?", link.ID, LinkStatusPending).Updates(map[string]interface{}{
+		"viewer_id":   viewer.ID,
+		"status":      LinkStatusAccepted,
+		"accepted_at": now,
+	})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to accept invitation: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("invalid or expired invitation")
+	}
+	link.ViewerID = &viewer.ID
+	link.Status = LinkStatusAccepted
+	link.AcceptedAt = &now
+
+	return &link, nil
+}
+
+// FindLinksForUser retrieves links where the user is the owner or the viewer
+func FindLinksForUser(db *gorm.DB, userID uint) ([]UserLink, error) {
+	var links []UserLink
+	err := db.Where("(owner_id = ? OR viewer_id = ?) AND status <> ?", userID, userID, LinkStatusRevoked).
+		Order("created_at desc").Find(&links).Error
+	return links, err
+}
+
+// FindLinkForParticipant finds a link that the user owns or views
+func FindLinkForParticipant(db *gorm.DB, linkID, userID uint) (*UserLink, error) {
+	var link UserLink
+	if err := db.Where("id = ? AND (owner_id = ? OR viewer_id = ?)", linkID, userID, userID).First(&link).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// FindLinkForOwner finds a link that the user owns
+func FindLinkForOwner(db *gorm.DB, linkID, ownerID uint) (*UserLink, error) {
+	var link UserLink
+	if err := db.Where("id = ? AND owner_id = ?", linkID, ownerID).First(&link).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// SetAccessExpiry changes when the grant ends; nil keeps access until revoked
+func (l *UserLink) SetAccessExpiry(db *gorm.DB, accessExpiresAt *time.Time) error {
+	if l.Status == LinkStatusRevoked {
+		return fmt.Errorf("link has been revoked")
+	}
+	if accessExpiresAt != nil && !accessExpiresAt.After(time.Now()) {
+		return fmt.Errorf("access expiry must be in the future")
+	}
+
+	l.AccessExpiresAt = accessExpiresAt
+	return db.Model(l).Update("access_expires_at", accessExpiresAt).Error
+}
+
+// IsActive reports whether the link currently grants access
+func (l *UserLink) IsActive() bool {
+	if l.Status != LinkStatusAccepted {
+		return false
+	}
+	return l.AccessExpiresAt == nil || l.AccessExpiresAt.After(time.Now())
+}
+
+// Revoke ends the link; either side may revoke it
+func (l *UserLink) Revoke(db *gorm.DB) error {
+	l.Status = LinkStatusRevoked
+	return db.Model(l).Update("status", LinkStatusRevoked).Error
+}
+
+// CanViewReports checks if viewer may read owner's reports, either as the owner
+// or through an accepted, unexpired link
+func CanViewReports(db *gorm.DB, viewerID, ownerID uint) (bool, error) {
+	if viewerID == ownerID {
+		return true, nil
+	}
+
+	var count int64
+	err := db.Model(&UserLink{}).
+		Where("owner_id = ? AND viewer_id = ? AND status = ?", ownerID, viewerID, LinkStatusAccepted).
+		Where("access_expires_at IS NULL OR access_expires_at > ?", time.Now()).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// FindLinkedViewers retrieves the users with an active link of the given
+// relationship to owner's reports
+func FindLinkedViewers(db *gorm.DB, ownerID uint, relationship string) ([]User, error) {
+	viewers := db.Session(&gorm.Session{NewDB: true}).Model(&UserLink{}).Select("viewer_id").
+		Where("owner_id = ? AND relationship = ? AND status = ?", ownerID, relationship, LinkStatusAccepted).
+		Where("access_expires_at IS NULL OR access_expires_at > ?", time.Now())
+
+	var users []User
+	if err := db.Where("id IN (?)", viewers).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return users, nil
+}
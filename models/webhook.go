@@ -0,0 +1,393 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Report lifecycle events sent to webhooks
+const (
+	EventReportCreated        = "report.created"
+	EventReportUpdated        = "report.updated"
+	EventTranslationCompleted = "translation.completed"
+)
+
+// WebhookEvents lists every event a webhook can subscribe to
+var WebhookEvents = []string{EventReportCreated, EventReportUpdated, EventTranslationCompleted}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// MaxWebhooksPerUser bounds how many webhooks a user can register
+const MaxWebhooksPerUser = 10
+
+// WebhookSubscription is a URL a user registered to receive signed report
+// lifecycle events at
+type WebhookSubscription struct {
+	ID     uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID uint   `gorm:"not null;index" json:"user_id"`
+	URL    string `gorm:"type:text;not null" json:"url" example:"https://example.com/thinkink/events"`
+	// Comma-separated events the webhook receives
+	Events      string `gorm:"type:text;not null" json:"events" example:"report.created,translation.completed"`
+	Description string `gorm:"type:text" json:"description,omitempty" example:"Clinic EHR sync"`
+	// Key the payloads are signed with; only shown when the webhook is created
+	Secret    string    `gorm:"type:text;not null" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is one attempt series to deliver an event to a webhook,
+// kept as the webhook's delivery log
+type WebhookDelivery struct {
+	ID             uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	SubscriptionID uint           `gorm:"not null;index" json:"subscription_id"`
+	EventID        string         `gorm:"type:varchar(40);not null;index" json:"event_id" example:"evt_0b6f3a52-5d1e-4a8e-9d0c-2f9e3c1b7a44"`
+	Event          string         `gorm:"type:varchar(50);not null" json:"event" example:"report.created"`
+	Payload        datatypes.JSON `gorm:"type:json;not null" json:"payload" swaggertype:"object"`
+	Status         string         `gorm:"type:varchar(20);not null;default:'pending';index" json:"status" example:"delivered"`
+	Attempts       int            `gorm:"not null;default:0" json:"attempts" example:"1"`
+	NextAttemptAt  time.Time      `gorm:"index" json:"next_attempt_at"`
+	// HTTP status of the last attempt; 0 when no response was received
+	ResponseStatus int        `gorm:"not null;default:0" json:"response_status,omitempty" example:"200"`
+	LastError      string     `gorm:"type:text" json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// WebhookPayload is the JSON body POSTed to webhooks
+type WebhookPayload struct {
+	ID        string            `json:"id" example:"evt_0b6f3a52-5d1e-4a8e-9d0c-2f9e3c1b7a44"`
+	Type      string            `json:"type" example:"report.updated"`
+	CreatedAt time.Time         `json:"created_at"`
+	Data      WebhookReportData `json:"data"`
+}
+
+// WebhookReportData describes the report an event is about. Report text is
+// left out; fetch GET /reports/{id} for it.
+type WebhookReportData struct {
+	ReportID          uint      `json:"report_id" example:"42"`
+	UserID            uint      `json:"user_id" example:"7"`
+	Title             string    `json:"title" example:"morning-session.json"`
+	MatchingScale     int       `json:"matching_scale" example:"7"`
+	TranslationStatus string    `json:"translation_status,omitempty" example:"completed"`
+	Archived          bool      `json:"archived"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	// Fields changed by a report.updated event
+	Changed []string `json:"changed,omitempty" example:"matching_scale"`
+}
+
+// Subscribes reports whether the webhook receives the event
+func (s *WebhookSubscription) Subscribes(event string) bool {
+	for _, e := range strings.Split(s.Events, ",") {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateWebhookEvents checks an event selection and returns it in canonical order
+func ValidateWebhookEvents(events []string) ([]string, error) {
+	selected := make(map[string]bool)
+	for _, event := range events {
+		known := false
+		for _, e := range WebhookEvents {
+			known = known || e == event
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown event %q; available events: %s", event, strings.Join(WebhookEvents, ", "))
+		}
+		selected[event] = true
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("at least one event is required")
+	}
+	var ordered []string
+	for _, e := range WebhookEvents {
+		if selected[e] {
+			ordered = append(ordered, e)
+		}
+	}
+	return ordered, nil
+}
+
+// CreateWebhookSubscription registers a webhook with a new signing secret
+func CreateWebhookSubscription(db *gorm.DB, userID uint, url string, events []string, description string) (*WebhookSubscription, error) {
+	events, err := ValidateWebhookEvents(events)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := db.Model(&WebhookSubscription{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if count >= MaxWebhooksPerUser {
+		return nil, fmt.Errorf("at most %d webhooks can be registered", MaxWebhooksPerUser)
+	}
+
+	secret, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+	subscription := &WebhookSubscription{
+		UserID:      userID,
+		URL:         url,
+		Events:      strings.Join(events, ","),
+		Description: description,
+		Secret:      "whsec_" + secret,
+	}
+	if err := db.Create(subscription).Error; err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return subscription, nil
+}
+
+// FindWebhookSubscriptions lists the user's webhooks, oldest first
+func FindWebhookSubscriptions(db *gorm.DB, userID uint) ([]WebhookSubscription, error) {
+	var subscriptions []WebhookSubscription
+	err := db.Where("user_id = ?", userID).Order("created_at asc, id asc").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// FindWebhookSubscription finds one of the user's webhooks
+func FindWebhookSubscription(db *gorm.DB, userID, id uint) (*WebhookSubscription, error) {
+	var subscription WebhookSubscription
+	if err := db.Where("id = ? AND user_id = ?", id, userID).First(&subscription).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// FindWebhookSubscriptionByID finds a webhook regardless of its owner
+func FindWebhookSubscriptionByID(db *gorm.DB, id uint) (*WebhookSubscription, error) {
+	var subscription WebhookSubscription
+	if err := db.First(&subscription, id).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// Delete removes the webhook together with its delivery log
+func (s *WebhookSubscription) Delete(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", s.ID).Delete(&WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(s).Error
+	})
+}
+
+// publishReportEvent queues the event for every webhook of the report's
+// owner that subscribes to it. Called inside the transaction that changes the
+// report, so events are only sent for changes that were saved.
+func publishReportEvent(db *gorm.DB, event string, r *Report, changed []string) error {
+	var subscriptions []WebhookSubscription
+	if err := db.Where("user_id = ?", r.UserID).Find(&subscriptions).Error; err != nil {
+		return fmt.Errorf("failed to find webhooks: %w", err)
+	}
+	var deliveries []WebhookDelivery
+	var eventID string
+	var payload []byte
+	for _, s := range subscriptions {
+		if !s.Subscribes(event) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			if eventID, payload, err = newReportEventPayload(event, r, changed); err != nil {
+				return err
+			}
+		}
+		deliveries = append(deliveries, WebhookDelivery{
+			SubscriptionID: s.ID,
+			EventID:        eventID,
+			Event:          event,
+			Payload:        datatypes.JSON(payload),
+			Status:         WebhookDeliveryPending,
+			NextAttemptAt:  time.Now(),
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := db.Create(&deliveries).Error; err != nil {
+		return fmt.Errorf("failed to queue webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// publishReportChange publishes the event for a change saved by
+// updateWithRevision. A translation that produced text completes the
+// translation; every other change updates the report.
+func publishReportChange(db *gorm.DB, change string, next Report, changes map[string]interface{}) error {
+	next.UpdatedAt = time.Now()
+	if status, ok := changes["translation_status"].(string); ok {
+		next.TranslationStatus = status
+	}
+	if change == RevisionTranslation {
+		if next.Description == "" {
+			return nil
+		}
+		return publishReportEvent(db, EventTranslationCompleted, &next, nil)
+	}
+
+	changed := make([]string, 0, len(changes))
+	for field := range changes {
+		changed = append(changed, field)
+	}
+	sort.Strings(changed)
+	return publishReportEvent(db, EventReportUpdated, &next, changed)
+}
+
+// newReportEventPayload builds the payload of a new event and returns its ID
+func newReportEventPayload(event string, r *Report, changed []string) (string, []byte, error) {
+	payload := WebhookPayload{
+		ID:        "evt_" + uuid.New().String(),
+		Type:      event,
+		CreatedAt: time.Now().UTC(),
+		Data: WebhookReportData{
+			ReportID:          r.ID,
+			UserID:            r.UserID,
+			Title:             r.Title,
+			MatchingScale:     r.MatchingScale,
+			TranslationStatus: r.TranslationStatus,
+			Archived:          r.ArchivedAt != nil,
+			CreatedAt:         r.CreatedAt,
+			UpdatedAt:         r.UpdatedAt,
+			Changed:           changed,
+		},
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+	return payload.ID, data, nil
+}
+
+// ClaimDueWebhookDeliveries locks up to limit pending deliveries that are due
+// and pushes their next attempt past the lease so other workers skip them
+func ClaimDueWebhookDeliveries(db *gorm.DB, limit int, lease time.Duration) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", WebhookDeliveryPending, now).
+			Order("next_attempt_at asc").
+			Limit(limit).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i, d := range deliveries {
+			ids[i] = d.ID
+		}
+		return tx.Model(&WebhookDelivery{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// MarkDelivered records a successful delivery
+func (d *WebhookDelivery) MarkDelivered(db *gorm.DB, responseStatus int) error {
+	now := time.Now()
+	d.Attempts++
+	d.Status = WebhookDeliveryDelivered
+	d.ResponseStatus = responseStatus
+	d.LastError = ""
+	d.DeliveredAt = &now
+	return db.Model(d).Updates(map[string]interface{}{
+		"attempts":        d.Attempts,
+		"status":          d.Status,
+		"response_status": responseStatus,
+		"last_error":      "",
+		"delivered_at":    now,
+	}).Error
+}
+
+// MarkRetry records a failed attempt and schedules the next one
+func (d *WebhookDelivery) MarkRetry(db *gorm.DB, responseStatus int, deliveryErr error, nextAttemptAt time.Time) error {
+	d.Attempts++
+	d.ResponseStatus = responseStatus
+	d.LastError = deliveryErr.Error()
+	d.NextAttemptAt = nextAttemptAt
+	return db.Model(d).Updates(map[string]interface{}{
+		"attempts":        d.Attempts,
+		"response_status": responseStatus,
+		"last_error":      d.LastError,
+		"next_attempt_at": nextAttemptAt,
+	}).Error
+}
+
+// MarkFailed records that the delivery was given up on
+func (d *WebhookDelivery) MarkFailed(db *gorm.DB, responseStatus int, deliveryErr error) error {
+	d.Attempts++
+	d.Status = WebhookDeliveryFailed
+	d.ResponseStatus = responseStatus
+	d.LastError = deliveryErr.Error()
+	return db.Model(d).Updates(map[string]interface{}{
+		"attempts":        d.Attempts,
+		"status":          d.Status,
+		"response_status": responseStatus,
+		"last_error":      d.LastError,
+	}).Error
+}
+
+// FindWebhookDeliveriesPage retrieves one page of the webhook's delivery log,
+// newest first. A cursor takes precedence over the offset.
+func FindWebhookDeliveriesPage(db *gorm.DB, subscriptionID uint, page Page) ([]WebhookDelivery, PageInfo, error) {
+	var info PageInfo
+
+	if err := db.Model(&WebhookDelivery{}).Where("subscription_id = ?", subscriptionID).Count(&info.Total).Error; err != nil {
+		return nil, info, fmt.Errorf("failed to count deliveries: %w", err)
+	}
+
+	query := db.Where("subscription_id = ?", subscriptionID)
+	if page.Cursor != "" {
+		var err error
+		if query, err = afterCursor(query, "created_at", "id", page.Cursor); err != nil {
+			return nil, info, err
+		}
+	} else if page.Offset > 0 {
+		query = query.Offset(page.Offset)
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	var deliveries []WebhookDelivery
+	if err := query.Order("created_at desc, id desc").Limit(page.Limit + 1).Find(&deliveries).Error; err != nil {
+		return nil, info, fmt.Errorf("failed to fetch deliveries: %w", err)
+	}
+
+	if len(deliveries) > page.Limit {
+		deliveries = deliveries[:page.Limit]
+		info.HasMore = true
+		last := deliveries[len(deliveries)-1]
+		info.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return deliveries, info, nil
+}
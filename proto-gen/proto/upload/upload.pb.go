@@ -0,0 +1,443 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v4.23.4
+// source: proto/upload/upload.proto
+
+package upload
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type UploadSignalRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*UploadSignalRequest_Header
+	//	*UploadSignalRequest_Chunk
+	Payload       isUploadSignalRequest_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadSignalRequest) Reset() {
+	*x = UploadSignalRequest{}
+	mi := &file_proto_upload_upload_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadSignalRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadSignalRequest) ProtoMessage() {}
+
+func (x *UploadSignalRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_upload_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadSignalRequest.ProtoReflect.Descriptor instead.
+func (*UploadSignalRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_upload_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *UploadSignalRequest) GetPayload() isUploadSignalRequest_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *UploadSignalRequest) GetHeader() *UploadHeader {
+	if x != nil {
+		if x, ok := x.Payload.(*UploadSignalRequest_Header); ok {
+			return x.Header
+		}
+	}
+	return nil
+}
+
+func (x *UploadSignalRequest) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*UploadSignalRequest_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+type isUploadSignalRequest_Payload interface {
+	isUploadSignalRequest_Payload()
+}
+
+type UploadSignalRequest_Header struct {
+	Header *UploadHeader `protobuf:"bytes,1,opt,name=header,proto3,oneof"` // First message only
+}
+
+type UploadSignalRequest_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"` // The file's next bytes
+}
+
+func (*UploadSignalRequest_Header) isUploadSignalRequest_Payload() {}
+
+func (*UploadSignalRequest_Chunk) isUploadSignalRequest_Payload() {}
+
+// Upload options, as the form fields and headers of POST /upload
+type UploadHeader struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Filename       string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`                                    // Name of the file, e.g. session.json
+	MatchingScale  int32                  `protobuf:"varint,2,opt,name=matching_scale,json=matchingScale,proto3" json:"matching_scale,omitempty"`    // 1-10; 0 for the default of 5
+	MetadataJson   string                 `protobuf:"bytes,3,opt,name=metadata_json,json=metadataJson,proto3" json:"metadata_json,omitempty"`        // Recording metadata as a JSON object
+	TemplateId     uint32                 `protobuf:"varint,4,opt,name=template_id,json=templateId,proto3" json:"template_id,omitempty"`             // Report template the structured notes follow
+	NotesJson      string                 `protobuf:"bytes,5,opt,name=notes_json,json=notesJson,proto3" json:"notes_json,omitempty"`                 // Structured notes as a JSON object
+	AllowDuplicate bool                   `protobuf:"varint,6,opt,name=allow_duplicate,json=allowDuplicate,proto3" json:"allow_duplicate,omitempty"` // Create a new report even if the file was uploaded before
+	Sha256         string                 `protobuf:"bytes,7,opt,name=sha256,proto3" json:"sha256,omitempty"`                                        // Hex SHA-256 of the file, verified once it is stored
+	RecordingId    string                 `protobuf:"bytes,8,opt,name=recording_id,json=recordingId,proto3" json:"recording_id,omitempty"`           // ID the device assigned to the recording, for idempotent uploads
+	DeviceId       string                 `protobuf:"bytes,9,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`                    // Device the recording comes from; taken from the token for device tokens
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UploadHeader) Reset() {
+	*x = UploadHeader{}
+	mi := &file_proto_upload_upload_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadHeader) ProtoMessage() {}
+
+func (x *UploadHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_upload_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadHeader.ProtoReflect.Descriptor instead.
+func (*UploadHeader) Descriptor() ([]byte, []int) {
+	return file_proto_upload_upload_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *UploadHeader) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *UploadHeader) GetMatchingScale() int32 {
+	if x != nil {
+		return x.MatchingScale
+	}
+	return 0
+}
+
+func (x *UploadHeader) GetMetadataJson() string {
+	if x != nil {
+		return x.MetadataJson
+	}
+	return ""
+}
+
+func (x *UploadHeader) GetTemplateId() uint32 {
+	if x != nil {
+		return x.TemplateId
+	}
+	return 0
+}
+
+func (x *UploadHeader) GetNotesJson() string {
+	if x != nil {
+		return x.NotesJson
+	}
+	return ""
+}
+
+func (x *UploadHeader) GetAllowDuplicate() bool {
+	if x != nil {
+		return x.AllowDuplicate
+	}
+	return false
+}
+
+func (x *UploadHeader) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *UploadHeader) GetRecordingId() string {
+	if x != nil {
+		return x.RecordingId
+	}
+	return ""
+}
+
+func (x *UploadHeader) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+// The result of an upload, as the JSON response of POST /upload
+type UploadSignalResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	FileId        uint32                 `protobuf:"varint,2,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	JobId         uint32                 `protobuf:"varint,3,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"` // The background job turning the file into a report
+	StatusUrl     string                 `protobuf:"bytes,4,opt,name=status_url,json=statusUrl,proto3" json:"status_url,omitempty"`
+	ReportId      uint32                 `protobuf:"varint,5,opt,name=report_id,json=reportId,proto3" json:"report_id,omitempty"` // Set for an existing report returned for a duplicate upload
+	MatchingScale int32                  `protobuf:"varint,6,opt,name=matching_scale,json=matchingScale,proto3" json:"matching_scale,omitempty"`
+	Warnings      []string               `protobuf:"bytes,7,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	Queued        bool                   `protobuf:"varint,8,opt,name=queued,proto3" json:"queued,omitempty"`
+	EtaSeconds    int32                  `protobuf:"varint,9,opt,name=eta_seconds,json=etaSeconds,proto3" json:"eta_seconds,omitempty"`
+	Duplicate     bool                   `protobuf:"varint,10,opt,name=duplicate,proto3" json:"duplicate,omitempty"`                                // The file was uploaded before; report_id is the existing report
+	DuplicateOfId uint32                 `protobuf:"varint,11,opt,name=duplicate_of_id,json=duplicateOfId,proto3" json:"duplicate_of_id,omitempty"` // Set when a new report was created for a file uploaded before
+	Replay        bool                   `protobuf:"varint,12,opt,name=replay,proto3" json:"replay,omitempty"`                                      // The recording was uploaded before; this is the first upload's result
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadSignalResponse) Reset() {
+	*x = UploadSignalResponse{}
+	mi := &file_proto_upload_upload_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadSignalResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadSignalResponse) ProtoMessage() {}
+
+func (x *UploadSignalResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_upload_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadSignalResponse.ProtoReflect.Descriptor instead.
+func (*UploadSignalResponse) Descriptor() ([]byte, []int) {
+	return file_proto_upload_upload_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *UploadSignalResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *UploadSignalResponse) GetFileId() uint32 {
+	if x != nil {
+		return x.FileId
+	}
+	return 0
+}
+
+func (x *UploadSignalResponse) GetJobId() uint32 {
+	if x != nil {
+		return x.JobId
+	}
+	return 0
+}
+
+func (x *UploadSignalResponse) GetStatusUrl() string {
+	if x != nil {
+		return x.StatusUrl
+	}
+	return ""
+}
+
+func (x *UploadSignalResponse) GetReportId() uint32 {
+	if x != nil {
+		return x.ReportId
+	}
+	return 0
+}
+
+func (x *UploadSignalResponse) GetMatchingScale() int32 {
+	if x != nil {
+		return x.MatchingScale
+	}
+	return 0
+}
+
+func (x *UploadSignalResponse) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *UploadSignalResponse) GetQueued() bool {
+	if x != nil {
+		return x.Queued
+	}
+	return false
+}
+
+func (x *UploadSignalResponse) GetEtaSeconds() int32 {
+	if x != nil {
+		return x.EtaSeconds
+	}
+	return 0
+}
+
+func (x *UploadSignalResponse) GetDuplicate() bool {
+	if x != nil {
+		return x.Duplicate
+	}
+	return false
+}
+
+func (x *UploadSignalResponse) GetDuplicateOfId() uint32 {
+	if x != nil {
+		return x.DuplicateOfId
+	}
+	return 0
+}
+
+func (x *UploadSignalResponse) GetReplay() bool {
+	if x != nil {
+		return x.Replay
+	}
+	return false
+}
+
+var File_proto_upload_upload_proto protoreflect.FileDescriptor
+
+const file_proto_upload_upload_proto_rawDesc = "" +
+	"\n" +
+	"\x19proto/upload/upload.proto\x12\x06upload\"h\n" +
+	"\x13UploadSignalRequest\x12.\n" +
+	"\x06header\x18\x01 \x01(\v2\x14.upload.UploadHeaderH\x00R\x06header\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\t\n" +
+	"\apayload\"\xb7\x02\n" +
+	"\fUploadHeader\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12%\n" +
+	"\x0ematching_scale\x18\x02 \x01(\x05R\rmatchingScale\x12#\n" +
+	"\rmetadata_json\x18\x03 \x01(\tR\fmetadataJson\x12\x1f\n" +
+	"\vtemplate_id\x18\x04 \x01(\rR\n" +
+	"templateId\x12\x1d\n" +
+	"\n" +
+	"notes_json\x18\x05 \x01(\tR\tnotesJson\x12'\n" +
+	"\x0fallow_duplicate\x18\x06 \x01(\bR\x0eallowDuplicate\x12\x16\n" +
+	"\x06sha256\x18\a \x01(\tR\x06sha256\x12!\n" +
+	"\frecording_id\x18\b \x01(\tR\vrecordingId\x12\x1b\n" +
+	"\tdevice_id\x18\t \x01(\tR\bdeviceId\"\xf6\x02\n" +
+	"\x14UploadSignalResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12\x17\n" +
+	"\afile_id\x18\x02 \x01(\rR\x06fileId\x12\x15\n" +
+	"\x06job_id\x18\x03 \x01(\rR\x05jobId\x12\x1d\n" +
+	"\n" +
+	"status_url\x18\x04 \x01(\tR\tstatusUrl\x12\x1b\n" +
+	"\treport_id\x18\x05 \x01(\rR\breportId\x12%\n" +
+	"\x0ematching_scale\x18\x06 \x01(\x05R\rmatchingScale\x12\x1a\n" +
+	"\bwarnings\x18\a \x03(\tR\bwarnings\x12\x16\n" +
+	"\x06queued\x18\b \x01(\bR\x06queued\x12\x1f\n" +
+	"\veta_seconds\x18\t \x01(\x05R\n" +
+	"etaSeconds\x12\x1c\n" +
+	"\tduplicate\x18\n" +
+	" \x01(\bR\tduplicate\x12&\n" +
+	"\x0fduplicate_of_id\x18\v \x01(\rR\rduplicateOfId\x12\x16\n" +
+	"\x06replay\x18\f \x01(\bR\x06replay2b\n" +
+	"\x13SignalUploadService\x12K\n" +
+	"\fUploadSignal\x12\x1b.upload.UploadSignalRequest\x1a\x1c.upload.UploadSignalResponse(\x01BFZDgithub.com/ThinkInkTeam/thinkink-core-backend/proto-gen/proto/uploadb\x06proto3"
+
+var (
+	file_proto_upload_upload_proto_rawDescOnce sync.Once
+	file_proto_upload_upload_proto_rawDescData []byte
+)
+
+func file_proto_upload_upload_proto_rawDescGZIP() []byte {
+	file_proto_upload_upload_proto_rawDescOnce.Do(func() {
+		file_proto_upload_upload_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_upload_upload_proto_rawDesc), len(file_proto_upload_upload_proto_rawDesc)))
+	})
+	return file_proto_upload_upload_proto_rawDescData
+}
+
+var file_proto_upload_upload_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_proto_upload_upload_proto_goTypes = []any{
+	(*UploadSignalRequest)(nil),  // 0: upload.UploadSignalRequest
+	(*UploadHeader)(nil),         // 1: upload.UploadHeader
+	(*UploadSignalResponse)(nil), // 2: upload.UploadSignalResponse
+}
+var file_proto_upload_upload_proto_depIdxs = []int32{
+	1, // 0: upload.UploadSignalRequest.header:type_name -> upload.UploadHeader
+	0, // 1: upload.SignalUploadService.UploadSignal:input_type -> upload.UploadSignalRequest
+	2, // 2: upload.SignalUploadService.UploadSignal:output_type -> upload.UploadSignalResponse
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_proto_upload_upload_proto_init() }
+func file_proto_upload_upload_proto_init() {
+	if File_proto_upload_upload_proto != nil {
+		return
+	}
+	file_proto_upload_upload_proto_msgTypes[0].OneofWrappers = []any{
+		(*UploadSignalRequest_Header)(nil),
+		(*UploadSignalRequest_Chunk)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_upload_upload_proto_rawDesc), len(file_proto_upload_upload_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_upload_upload_proto_goTypes,
+		DependencyIndexes: file_proto_upload_upload_proto_depIdxs,
+		MessageInfos:      file_proto_upload_upload_proto_msgTypes,
+	}.Build()
+	File_proto_upload_upload_proto = out.File
+	file_proto_upload_upload_proto_goTypes = nil
+	file_proto_upload_upload_proto_depIdxs = nil
+}
@@ -0,0 +1,126 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v4.23.4
+// source: proto/upload/upload.proto
+
+package upload
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SignalUploadService_UploadSignal_FullMethodName = "/upload.SignalUploadService/UploadSignal"
+)
+
+// SignalUploadServiceClient is the client API for SignalUploadService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Recording upload service for headset companion apps. Calls carry the same
+// bearer token as the REST API in the "authorization" metadata.
+type SignalUploadServiceClient interface {
+	// Upload a recording as a stream: an UploadHeader first, then the file's
+	// bytes in chunks. The recording goes through the same checks and
+	// processing as POST /upload.
+	UploadSignal(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadSignalRequest, UploadSignalResponse], error)
+}
+
+type signalUploadServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSignalUploadServiceClient(cc grpc.ClientConnInterface) SignalUploadServiceClient {
+	return &signalUploadServiceClient{cc}
+}
+
+func (c *signalUploadServiceClient) UploadSignal(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadSignalRequest, UploadSignalResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SignalUploadService_ServiceDesc.Streams[0], SignalUploadService_UploadSignal_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UploadSignalRequest, UploadSignalResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SignalUploadService_UploadSignalClient = grpc.ClientStreamingClient[UploadSignalRequest, UploadSignalResponse]
+
+// SignalUploadServiceServer is the server API for SignalUploadService service.
+// All implementations must embed UnimplementedSignalUploadServiceServer
+// for forward compatibility.
+//
+// Recording upload service for headset companion apps. Calls carry the same
+// bearer token as the REST API in the "authorization" metadata.
+type SignalUploadServiceServer interface {
+	// Upload a recording as a stream: an UploadHeader first, then the file's
+	// bytes in chunks. The recording goes through the same checks and
+	// processing as POST /upload.
+	UploadSignal(grpc.ClientStreamingServer[UploadSignalRequest, UploadSignalResponse]) error
+	mustEmbedUnimplementedSignalUploadServiceServer()
+}
+
+// UnimplementedSignalUploadServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSignalUploadServiceServer struct{}
+
+func (UnimplementedSignalUploadServiceServer) UploadSignal(grpc.ClientStreamingServer[UploadSignalRequest, UploadSignalResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadSignal not implemented")
+}
+func (UnimplementedSignalUploadServiceServer) mustEmbedUnimplementedSignalUploadServiceServer() {}
+func (UnimplementedSignalUploadServiceServer) testEmbeddedByValue()                             {}
+
+// UnsafeSignalUploadServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SignalUploadServiceServer will
+// result in compilation errors.
+type UnsafeSignalUploadServiceServer interface {
+	mustEmbedUnimplementedSignalUploadServiceServer()
+}
+
+func RegisterSignalUploadServiceServer(s grpc.ServiceRegistrar, srv SignalUploadServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSignalUploadServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SignalUploadService_ServiceDesc, srv)
+}
+
+func _SignalUploadService_UploadSignal_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SignalUploadServiceServer).UploadSignal(&grpc.GenericServerStream[UploadSignalRequest, UploadSignalResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SignalUploadService_UploadSignalServer = grpc.ClientStreamingServer[UploadSignalRequest, UploadSignalResponse]
+
+// SignalUploadService_ServiceDesc is the grpc.ServiceDesc for SignalUploadService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SignalUploadService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "upload.SignalUploadService",
+	HandlerType: (*SignalUploadServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UploadSignal",
+			Handler:       _SignalUploadService_UploadSignal_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "proto/upload/upload.proto",
+}
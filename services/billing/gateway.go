@@ -0,0 +1,301 @@
+package billing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Gateway is the payment provider boundary. Handlers and jobs only talk to
+// this interface so the Stripe SDK version can change behind it, and tests can
+// swap in StubGateway.
+type Gateway interface {
+	CreateCustomer(ctx context.Context, in CustomerInput) (*Customer, error)
+	GetCustomer(ctx context.Context, customerID string) (*Customer, error)
+	CreateCheckoutSession(ctx context.Context, in CheckoutSessionInput) (*CheckoutSession, error)
+	GetSubscription(ctx context.Context, subscriptionID string) (*Subscription, error)
+	SetCancelAtPeriodEnd(ctx context.Context, subscriptionID string, cancel bool) (*Subscription, error)
+	// SetSubscriptionQuantity changes the quantity of a subscription item,
+	// such as the seats of a team plan, prorating the change
+	SetSubscriptionQuantity(ctx context.Context, subscriptionID, itemID string, quantity int64) (*Subscription, error)
+	// EachSubscription calls fn for every subscription in any status,
+	// including canceled ones, stopping at the first error
+	EachSubscription(ctx context.Context, fn func(*Subscription) error) error
+	// ListPrices returns the active prices of active products
+	ListPrices(ctx context.Context) ([]Price, error)
+	// FindPromotionCode returns the active promotion code with the given
+	// customer-facing code, or ErrPromotionCodeNotFound
+	FindPromotionCode(ctx context.Context, code string) (*PromotionCode, error)
+
+	// Tax IDs of a customer. CreateTaxID returns ErrInvalidTaxID when the
+	// provider rejects the type or value.
+	ListTaxIDs(ctx context.Context, customerID string) ([]TaxID, error)
+	CreateTaxID(ctx context.Context, customerID, taxType, value string) (*TaxID, error)
+	DeleteTaxID(ctx context.Context, customerID, taxID string) error
+
+	// GetInvoice returns an invoice, or ErrInvoiceNotFound
+	GetInvoice(ctx context.Context, invoiceID string) (*Invoice, error)
+
+	// ConstructEvent verifies a webhook signature and returns the raw event
+	ConstructEvent(payload []byte, signature, secret string) (*Event, error)
+	DecodeCheckoutSession(e *Event) (*CheckoutSession, error)
+	DecodeSubscription(e *Event) (*Subscription, error)
+	DecodePaymentMethod(e *Event) (*PaymentMethod, error)
+	DecodeTaxID(e *Event) (*TaxID, error)
+	DecodeInvoice(e *Event) (*Invoice, error)
+
+	// Ping checks that the payment provider is reachable and accepts our credentials
+	Ping(ctx context.Context) error
+}
+
+// ErrPromotionCodeNotFound is returned for unknown, inactive or expired promotion codes
+var ErrPromotionCodeNotFound = errors.New("promotion code not found")
+
+// ErrInvalidTaxID is returned for tax IDs of an unknown type or with a malformed value
+var ErrInvalidTaxID = errors.New("invalid tax ID")
+
+// ErrInvoiceNotFound is returned for unknown invoices
+var ErrInvoiceNotFound = errors.New("invoice not found")
+
+// Address is a postal address attached to a customer
+type Address struct {
+	Line1      string
+	City       string
+	Country    string
+	PostalCode string
+}
+
+// CustomerInput describes a customer to create
+type CustomerInput struct {
+	Name     string
+	Email    string
+	Phone    string
+	Address  *Address
+	Metadata map[string]string
+}
+
+// Customer is a billing customer
+type Customer struct {
+	ID                     string
+	Email                  string
+	DefaultPaymentMethodID string
+}
+
+// CheckoutMode selects between recurring and one-off checkout
+type CheckoutMode string
+
+const (
+	CheckoutModeSubscription CheckoutMode = "subscription"
+	CheckoutModePayment      CheckoutMode = "payment"
+)
+
+// LineItem is a checkout line. Set PriceID for catalog prices, or
+// Currency/UnitAmount/ProductName for ad-hoc amounts.
+type LineItem struct {
+	PriceID     string
+	Quantity    int64
+	Currency    string
+	UnitAmount  int64
+	ProductName string
+}
+
+// CheckoutSessionInput describes a hosted checkout session to create.
+// Currency picks one of a multi-currency price's currencies; empty charges
+// the price's default currency.
+// PromotionCodeID applies a promotion code up front; otherwise
+// AllowPromotionCodes lets the customer enter one on the checkout page.
+// CollectTaxIDs asks business customers for their tax ID, and AutomaticTax
+// has the provider calculate tax from the billing address; with either, the
+// name and address entered at checkout are saved on the customer.
+type CheckoutSessionInput struct {
+	CustomerID          string
+	Mode                CheckoutMode
+	Currency            string
+	LineItems           []LineItem
+	SuccessURL          string
+	CancelURL           string
+	Metadata            map[string]string
+	AllowPromotionCodes bool
+	PromotionCodeID     string
+	CollectTaxIDs       bool
+	AutomaticTax        bool
+}
+
+// CheckoutSession is a hosted checkout session
+type CheckoutSession struct {
+	ID             string
+	URL            string
+	Mode           CheckoutMode
+	Paid           bool
+	CustomerID     string
+	SubscriptionID string
+	Metadata       map[string]string
+}
+
+// Subscription is a recurring subscription
+type Subscription struct {
+	ID         string
+	CustomerID string
+	Status     string
+	PriceID    string
+	// The item of PriceID and how many of it are subscribed, e.g. seats
+	ItemID   string
+	Quantity int64
+	// Price of one unit per Interval, in the smallest currency unit
+	UnitAmount        int64
+	Currency          string
+	Interval          string
+	IntervalCount     int64
+	CancelAtPeriodEnd bool
+	CurrentPeriodEnd  time.Time
+	// The discount applied to the subscription, if any
+	Discount *Discount
+}
+
+// IsActive reports whether the subscription grants access
+func (s *Subscription) IsActive() bool {
+	return s.Status == "active" || s.Status == "trialing"
+}
+
+// MonthlyAmount returns what the subscription bills per month before
+// discounts and tax, in the smallest currency unit; 0 without a known interval
+func (s *Subscription) MonthlyAmount() int64 {
+	total := s.UnitAmount * max(s.Quantity, 1)
+	count := max(s.IntervalCount, 1)
+	switch s.Interval {
+	case "month":
+		return total / count
+	case "year":
+		return total / (12 * count)
+	case "week":
+		return total * 52 / (12 * count)
+	case "day":
+		return total * 365 / (12 * count)
+	}
+	return 0
+}
+
+// Price is a purchasable price of a catalog product. Interval is empty for
+// one-time prices. CurrencyOptions holds the unit amounts of a multi-currency
+// price in its other currencies.
+type Price struct {
+	ID              string
+	ProductID       string
+	ProductName     string
+	Description     string
+	Features        []string
+	Currency        string
+	UnitAmount      int64
+	CurrencyOptions map[string]int64
+	Interval        string
+	IntervalCount   int64
+}
+
+// AmountIn returns the unit amount in the given currency, and whether the
+// price can be charged in it
+func (p Price) AmountIn(currency string) (int64, bool) {
+	if currency == p.Currency {
+		return p.UnitAmount, true
+	}
+	amount, ok := p.CurrencyOptions[currency]
+	return amount, ok
+}
+
+// Coupon is the discount a promotion code grants. Either PercentOff or
+// AmountOff (in Currency's minor unit) is set. Duration is once, repeating
+// or forever.
+type Coupon struct {
+	ID               string
+	Name             string
+	PercentOff       float64
+	AmountOff        int64
+	Currency         string
+	Duration         string
+	DurationInMonths int64
+}
+
+// PromotionCode is a customer-facing code for a coupon
+type PromotionCode struct {
+	ID     string
+	Code   string
+	Coupon Coupon
+}
+
+// Discount is a coupon applied to a subscription. End is zero for
+// discounts that never end.
+type Discount struct {
+	Coupon          Coupon
+	PromotionCodeID string
+	Start           time.Time
+	End             time.Time
+}
+
+// TaxID is a customer's tax ID, such as an EU VAT number. Type is the
+// provider's tax ID type, e.g. eu_vat or gb_vat; VerificationStatus is
+// pending, verified, unverified or unavailable.
+type TaxID struct {
+	ID                 string
+	CustomerID         string
+	Type               string
+	Value              string
+	Country            string
+	VerificationStatus string
+}
+
+// Invoice is a bill for a subscription period or one-off charge.
+// NextPaymentAttempt is zero when no further automatic attempt is scheduled.
+type Invoice struct {
+	ID                 string
+	CustomerID         string
+	SubscriptionID     string
+	AmountDue          int64
+	Currency           string
+	AttemptCount       int64
+	NextPaymentAttempt time.Time
+	// Page where the customer can pay the invoice and update their card
+	HostedInvoiceURL string
+	// Customer-facing invoice number, assigned when the invoice is finalized
+	Number string
+	// Download URL of the invoice PDF; empty for drafts
+	InvoicePDF string
+}
+
+// PaymentMethod is a stored payment method
+type PaymentMethod struct {
+	ID         string
+	CustomerID string
+}
+
+// Event is a verified webhook event whose payload is decoded on demand
+type Event struct {
+	ID   string
+	Type string
+	// Created is when Stripe created the event
+	Created time.Time
+	Data    json.RawMessage
+}
+
+var (
+	gatewayMu sync.RWMutex
+	gateway   Gateway
+)
+
+// SetGateway installs the gateway used by Client
+func SetGateway(g Gateway) {
+	gatewayMu.Lock()
+	defer gatewayMu.Unlock()
+	gateway = g
+}
+
+// Client returns the active gateway. It panics when none is installed rather
+// than falling back to the stub, which accepts unsigned webhook events.
+func Client() Gateway {
+	gatewayMu.RLock()
+	defer gatewayMu.RUnlock()
+	if gateway == nil {
+		panic("billing: no gateway installed; call SetGateway at startup")
+	}
+	return gateway
+}
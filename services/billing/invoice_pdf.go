@@ -0,0 +1,37 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ErrNoInvoicePDF is returned for invoices without a PDF, such as drafts
+var ErrNoInvoicePDF = errors.New("invoice has no PDF")
+
+var pdfClient = &http.Client{Timeout: 60 * time.Second}
+
+// OpenInvoicePDF starts downloading the PDF of an invoice from the payment
+// provider. The caller closes the returned body; its length is -1 when unknown.
+func OpenInvoicePDF(ctx context.Context, inv *Invoice) (io.ReadCloser, int64, error) {
+	if inv.InvoicePDF == "" {
+		return nil, 0, ErrNoInvoicePDF
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, inv.InvoicePDF, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := pdfClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invoice PDF request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("invoice PDF download returned %s", resp.Status)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
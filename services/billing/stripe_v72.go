@@ -0,0 +1,514 @@
+package billing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stripe/stripe-go/v72"
+	"github.com/stripe/stripe-go/v72/client"
+	"github.com/stripe/stripe-go/v72/webhook"
+)
+
+// StripeGateway implements Gateway on top of stripe-go v72.
+// All v72 types stay inside this file.
+type StripeGateway struct {
+	api *client.API
+}
+
+// NewStripeGateway creates a gateway authenticated with the given secret key
+func NewStripeGateway(secretKey string) *StripeGateway {
+	return &StripeGateway{api: client.New(secretKey, nil)}
+}
+
+// CreateCustomer creates a Stripe customer
+func (g *StripeGateway) CreateCustomer(ctx context.Context, in CustomerInput) (*Customer, error) {
+	params := &stripe.CustomerParams{
+		Name:  stripe.String(in.Name),
+		Email: stripe.String(in.Email),
+	}
+	params.Context = ctx
+
+	if in.Phone != "" {
+		params.Phone = stripe.String(in.Phone)
+	}
+	if in.Address != nil {
+		params.Address = &stripe.AddressParams{
+			Line1:      stripe.String(in.Address.Line1),
+			City:       stripe.String(in.Address.City),
+			Country:    stripe.String(in.Address.Country),
+			PostalCode: stripe.String(in.Address.PostalCode),
+		}
+	}
+	for k, v := range in.Metadata {
+		params.AddMetadata(k, v)
+	}
+
+	cus, err := g.api.Customers.New(params)
+	if err != nil {
+		return nil, err
+	}
+	return toCustomer(cus), nil
+}
+
+// GetCustomer retrieves a Stripe customer
+func (g *StripeGateway) GetCustomer(ctx context.Context, customerID string) (*Customer, error) {
+	params := &stripe.CustomerParams{}
+	params.Context = ctx
+
+	cus, err := g.api.Customers.Get(customerID, params)
+	if err != nil {
+		return nil, err
+	}
+	return toCustomer(cus), nil
+}
+
+// Ping fetches the account balance, the cheapest authenticated Stripe call
+func (g *StripeGateway) Ping(ctx context.Context) error {
+	params := &stripe.BalanceParams{}
+	params.Context = ctx
+
+	_, err := g.api.Balance.Get(params)
+	return err
+}
+
+// CreateCheckoutSession creates a hosted Stripe Checkout session
+func (g *StripeGateway) CreateCheckoutSession(ctx context.Context, in CheckoutSessionInput) (*CheckoutSession, error) {
+	params := &stripe.CheckoutSessionParams{
+		Customer:           stripe.String(in.CustomerID),
+		PaymentMethodTypes: stripe.StringSlice([]string{"card"}),
+		Mode:               stripe.String(string(in.Mode)),
+		SuccessURL:         stripe.String(in.SuccessURL),
+		CancelURL:          stripe.String(in.CancelURL),
+	}
+	params.Context = ctx
+
+	for _, item := range in.LineItems {
+		lineItem := &stripe.CheckoutSessionLineItemParams{
+			Quantity: stripe.Int64(item.Quantity),
+		}
+		if item.PriceID != "" {
+			lineItem.Price = stripe.String(item.PriceID)
+		} else {
+			lineItem.PriceData = &stripe.CheckoutSessionLineItemPriceDataParams{
+				Currency: stripe.String(item.Currency),
+				ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
+					Name: stripe.String(item.ProductName),
+				},
+				UnitAmount: stripe.Int64(item.UnitAmount),
+			}
+		}
+		params.LineItems = append(params.LineItems, lineItem)
+	}
+	if in.Currency != "" {
+		params.Currency = stripe.String(in.Currency)
+	}
+	if in.PromotionCodeID != "" {
+		params.Discounts = []*stripe.CheckoutSessionDiscountParams{
+			{PromotionCode: stripe.String(in.PromotionCodeID)},
+		}
+	} else if in.AllowPromotionCodes {
+		params.AllowPromotionCodes = stripe.Bool(true)
+	}
+	if in.CollectTaxIDs {
+		params.TaxIDCollection = &stripe.CheckoutSessionTaxIDCollectionParams{Enabled: stripe.Bool(true)}
+	}
+	if in.AutomaticTax {
+		params.AutomaticTax = &stripe.CheckoutSessionAutomaticTaxParams{Enabled: stripe.Bool(true)}
+	}
+	if (in.CollectTaxIDs || in.AutomaticTax) && in.CustomerID != "" {
+		params.CustomerUpdate = &stripe.CheckoutSessionCustomerUpdateParams{
+			Name:    stripe.String("auto"),
+			Address: stripe.String("auto"),
+		}
+	}
+	for k, v := range in.Metadata {
+		params.AddMetadata(k, v)
+	}
+
+	sess, err := g.api.CheckoutSessions.New(params)
+	if err != nil {
+		return nil, err
+	}
+	return toCheckoutSession(sess), nil
+}
+
+// GetSubscription retrieves a Stripe subscription
+func (g *StripeGateway) GetSubscription(ctx context.Context, subscriptionID string) (*Subscription, error) {
+	params := &stripe.SubscriptionParams{}
+	params.Context = ctx
+
+	s, err := g.api.Subscriptions.Get(subscriptionID, params)
+	if err != nil {
+		return nil, err
+	}
+	return toSubscription(s), nil
+}
+
+// SetCancelAtPeriodEnd schedules or unschedules cancellation at the end of the billing period
+func (g *StripeGateway) SetCancelAtPeriodEnd(ctx context.Context, subscriptionID string, cancel bool) (*Subscription, error) {
+	params := &stripe.SubscriptionParams{
+		CancelAtPeriodEnd: stripe.Bool(cancel),
+	}
+	params.Context = ctx
+
+	s, err := g.api.Subscriptions.Update(subscriptionID, params)
+	if err != nil {
+		return nil, err
+	}
+	return toSubscription(s), nil
+}
+
+// SetSubscriptionQuantity updates the quantity of a subscription item,
+// creating prorations for the rest of the billing period
+func (g *StripeGateway) SetSubscriptionQuantity(ctx context.Context, subscriptionID, itemID string, quantity int64) (*Subscription, error) {
+	params := &stripe.SubscriptionItemParams{
+		Quantity:          stripe.Int64(quantity),
+		ProrationBehavior: stripe.String("create_prorations"),
+	}
+	params.Context = ctx
+
+	if _, err := g.api.SubscriptionItems.Update(itemID, params); err != nil {
+		return nil, err
+	}
+	return g.GetSubscription(ctx, subscriptionID)
+}
+
+// EachSubscription pages through all subscriptions of the account
+func (g *StripeGateway) EachSubscription(ctx context.Context, fn func(*Subscription) error) error {
+	params := &stripe.SubscriptionListParams{Status: "all"}
+	params.Context = ctx
+	params.Filters.AddFilter("limit", "", "100")
+
+	it := g.api.Subscriptions.List(params)
+	for it.Next() {
+		if err := fn(toSubscription(it.Subscription())); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// ListPrices lists active prices with their products expanded, skipping
+// prices of archived products. A product's features are read from its
+// "features" metadata, one per line.
+func (g *StripeGateway) ListPrices(ctx context.Context) ([]Price, error) {
+	params := &stripe.PriceListParams{Active: stripe.Bool(true)}
+	params.Context = ctx
+	params.AddExpand("data.product")
+	params.AddExpand("data.currency_options")
+
+	var prices []Price
+	it := g.api.Prices.List(params)
+	for it.Next() {
+		p := it.Price()
+		if p.Product == nil || !p.Product.Active {
+			continue
+		}
+		prices = append(prices, toPrice(p))
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// FindPromotionCode looks up an active promotion code whose coupon is still valid
+func (g *StripeGateway) FindPromotionCode(ctx context.Context, code string) (*PromotionCode, error) {
+	params := &stripe.PromotionCodeListParams{
+		Active: stripe.Bool(true),
+		Code:   stripe.String(code),
+	}
+	params.Context = ctx
+
+	it := g.api.PromotionCodes.List(params)
+	for it.Next() {
+		p := it.PromotionCode()
+		if p.Coupon == nil || !p.Coupon.Valid {
+			continue
+		}
+		if p.ExpiresAt != 0 && time.Unix(p.ExpiresAt, 0).Before(time.Now()) {
+			continue
+		}
+		return &PromotionCode{ID: p.ID, Code: p.Code, Coupon: toCoupon(p.Coupon)}, nil
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return nil, ErrPromotionCodeNotFound
+}
+
+// ListTaxIDs lists the customer's tax IDs
+func (g *StripeGateway) ListTaxIDs(ctx context.Context, customerID string) ([]TaxID, error) {
+	params := &stripe.TaxIDListParams{Customer: stripe.String(customerID)}
+	params.Context = ctx
+
+	var taxIDs []TaxID
+	it := g.api.TaxIDs.List(params)
+	for it.Next() {
+		taxIDs = append(taxIDs, toTaxID(it.TaxID()))
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return taxIDs, nil
+}
+
+// CreateTaxID adds a tax ID to the customer; Stripe validates its format
+// and verifies EU VAT numbers asynchronously
+func (g *StripeGateway) CreateTaxID(ctx context.Context, customerID, taxType, value string) (*TaxID, error) {
+	params := &stripe.TaxIDParams{
+		Customer: stripe.String(customerID),
+		Type:     stripe.String(taxType),
+		Value:    stripe.String(value),
+	}
+	params.Context = ctx
+
+	t, err := g.api.TaxIDs.New(params)
+	if err != nil {
+		var stripeErr *stripe.Error
+		if errors.As(err, &stripeErr) && (stripeErr.Code == stripe.ErrorCodeTaxIDInvalid || stripeErr.Param == "type") {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTaxID, stripeErr.Msg)
+		}
+		return nil, err
+	}
+	taxID := toTaxID(t)
+	return &taxID, nil
+}
+
+// DeleteTaxID removes a tax ID from the customer
+func (g *StripeGateway) DeleteTaxID(ctx context.Context, customerID, taxID string) error {
+	params := &stripe.TaxIDParams{Customer: stripe.String(customerID)}
+	params.Context = ctx
+
+	_, err := g.api.TaxIDs.Del(taxID, params)
+	return err
+}
+
+// GetInvoice retrieves a Stripe invoice
+func (g *StripeGateway) GetInvoice(ctx context.Context, invoiceID string) (*Invoice, error) {
+	params := &stripe.InvoiceParams{}
+	params.Context = ctx
+
+	inv, err := g.api.Invoices.Get(invoiceID, params)
+	if err != nil {
+		var stripeErr *stripe.Error
+		if errors.As(err, &stripeErr) && stripeErr.Code == stripe.ErrorCodeResourceMissing {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+	return toInvoice(inv), nil
+}
+
+// ConstructEvent verifies the Stripe-Signature header and parses the event envelope
+func (g *StripeGateway) ConstructEvent(payload []byte, signature, secret string) (*Event, error) {
+	event, err := webhook.ConstructEvent(payload, signature, secret)
+	if err != nil {
+		return nil, err
+	}
+	return &Event{
+		ID:      event.ID,
+		Type:    event.Type,
+		Created: time.Unix(event.Created, 0),
+		Data:    event.Data.Raw,
+	}, nil
+}
+
+// DecodeCheckoutSession decodes a checkout.session.* event payload
+func (g *StripeGateway) DecodeCheckoutSession(e *Event) (*CheckoutSession, error) {
+	var sess stripe.CheckoutSession
+	if err := json.Unmarshal(e.Data, &sess); err != nil {
+		return nil, fmt.Errorf("error parsing checkout session: %w", err)
+	}
+	return toCheckoutSession(&sess), nil
+}
+
+// DecodeSubscription decodes a customer.subscription.* event payload
+func (g *StripeGateway) DecodeSubscription(e *Event) (*Subscription, error) {
+	var s stripe.Subscription
+	if err := json.Unmarshal(e.Data, &s); err != nil {
+		return nil, fmt.Errorf("error parsing subscription: %w", err)
+	}
+	return toSubscription(&s), nil
+}
+
+// DecodePaymentMethod decodes a payment_method.* event payload
+func (g *StripeGateway) DecodePaymentMethod(e *Event) (*PaymentMethod, error) {
+	var pm stripe.PaymentMethod
+	if err := json.Unmarshal(e.Data, &pm); err != nil {
+		return nil, fmt.Errorf("error parsing payment method: %w", err)
+	}
+
+	out := &PaymentMethod{ID: pm.ID}
+	if pm.Customer != nil {
+		out.CustomerID = pm.Customer.ID
+	}
+	return out, nil
+}
+
+// DecodeTaxID decodes a customer.tax_id.* event payload
+func (g *StripeGateway) DecodeTaxID(e *Event) (*TaxID, error) {
+	var t stripe.TaxID
+	if err := json.Unmarshal(e.Data, &t); err != nil {
+		return nil, fmt.Errorf("error parsing tax ID: %w", err)
+	}
+	taxID := toTaxID(&t)
+	return &taxID, nil
+}
+
+// DecodeInvoice decodes an invoice.* event payload
+func (g *StripeGateway) DecodeInvoice(e *Event) (*Invoice, error) {
+	var inv stripe.Invoice
+	if err := json.Unmarshal(e.Data, &inv); err != nil {
+		return nil, fmt.Errorf("error parsing invoice: %w", err)
+	}
+	return toInvoice(&inv), nil
+}
+
+func toInvoice(inv *stripe.Invoice) *Invoice {
+	out := &Invoice{
+		ID:               inv.ID,
+		AmountDue:        inv.AmountDue,
+		Currency:         string(inv.Currency),
+		AttemptCount:     inv.AttemptCount,
+		HostedInvoiceURL: inv.HostedInvoiceURL,
+		Number:           inv.Number,
+		InvoicePDF:       inv.InvoicePDF,
+	}
+	if inv.Customer != nil {
+		out.CustomerID = inv.Customer.ID
+	}
+	if inv.Subscription != nil {
+		out.SubscriptionID = inv.Subscription.ID
+	}
+	if inv.NextPaymentAttempt != 0 {
+		out.NextPaymentAttempt = time.Unix(inv.NextPaymentAttempt, 0)
+	}
+	return out
+}
+
+func toCustomer(cus *stripe.Customer) *Customer {
+	out := &Customer{ID: cus.ID, Email: cus.Email}
+	if cus.InvoiceSettings != nil && cus.InvoiceSettings.DefaultPaymentMethod != nil {
+		out.DefaultPaymentMethodID = cus.InvoiceSettings.DefaultPaymentMethod.ID
+	}
+	return out
+}
+
+func toCheckoutSession(sess *stripe.CheckoutSession) *CheckoutSession {
+	out := &CheckoutSession{
+		ID:       sess.ID,
+		URL:      sess.URL,
+		Mode:     CheckoutMode(sess.Mode),
+		Paid:     sess.PaymentStatus == stripe.CheckoutSessionPaymentStatusPaid,
+		Metadata: sess.Metadata,
+	}
+	if sess.Customer != nil {
+		out.CustomerID = sess.Customer.ID
+	}
+	if sess.Subscription != nil {
+		out.SubscriptionID = sess.Subscription.ID
+	}
+	return out
+}
+
+func toSubscription(s *stripe.Subscription) *Subscription {
+	out := &Subscription{
+		ID:                s.ID,
+		Status:            string(s.Status),
+		CancelAtPeriodEnd: s.CancelAtPeriodEnd,
+		CurrentPeriodEnd:  time.Unix(s.CurrentPeriodEnd, 0),
+	}
+	if s.Customer != nil {
+		out.CustomerID = s.Customer.ID
+	}
+	if s.Items != nil && len(s.Items.Data) > 0 {
+		item := s.Items.Data[0]
+		out.ItemID = item.ID
+		out.Quantity = item.Quantity
+		if item.Price != nil {
+			out.PriceID = item.Price.ID
+			out.UnitAmount = item.Price.UnitAmount
+			out.Currency = string(item.Price.Currency)
+			if item.Price.Recurring != nil {
+				out.Interval = string(item.Price.Recurring.Interval)
+				out.IntervalCount = item.Price.Recurring.IntervalCount
+			}
+		}
+	}
+	if s.Discount != nil && s.Discount.Coupon != nil {
+		out.Discount = &Discount{
+			Coupon: toCoupon(s.Discount.Coupon),
+			Start:  time.Unix(s.Discount.Start, 0),
+		}
+		if s.Discount.PromotionCode != nil {
+			out.Discount.PromotionCodeID = s.Discount.PromotionCode.ID
+		}
+		if s.Discount.End != 0 {
+			out.Discount.End = time.Unix(s.Discount.End, 0)
+		}
+	}
+	return out
+}
+
+func toCoupon(c *stripe.Coupon) Coupon {
+	return Coupon{
+		ID:               c.ID,
+		Name:             c.Name,
+		PercentOff:       c.PercentOff,
+		AmountOff:        c.AmountOff,
+		Currency:         string(c.Currency),
+		Duration:         string(c.Duration),
+		DurationInMonths: c.DurationInMonths,
+	}
+}
+
+func toPrice(p *stripe.Price) Price {
+	out := Price{
+		ID:          p.ID,
+		ProductID:   p.Product.ID,
+		ProductName: p.Product.Name,
+		Description: p.Product.Description,
+		Currency:    string(p.Currency),
+		UnitAmount:  p.UnitAmount,
+	}
+	for _, feature := range strings.Split(p.Product.Metadata["features"], "\n") {
+		if feature = strings.TrimSpace(feature); feature != "" {
+			out.Features = append(out.Features, feature)
+		}
+	}
+	for currency, option := range p.CurrencyOptions {
+		if currency == out.Currency || option == nil {
+			continue
+		}
+		if out.CurrencyOptions == nil {
+			out.CurrencyOptions = map[string]int64{}
+		}
+		out.CurrencyOptions[currency] = option.UnitAmount
+	}
+	if p.Recurring != nil {
+		out.Interval = string(p.Recurring.Interval)
+		out.IntervalCount = p.Recurring.IntervalCount
+	}
+	return out
+}
+
+func toTaxID(t *stripe.TaxID) TaxID {
+	out := TaxID{
+		ID:      t.ID,
+		Type:    string(t.Type),
+		Value:   t.Value,
+		Country: t.Country,
+	}
+	if t.Customer != nil {
+		out.CustomerID = t.Customer.ID
+	}
+	if t.Verification != nil {
+		out.VerificationStatus = string(t.Verification.Status)
+	}
+	return out
+}
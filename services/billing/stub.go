@@ -0,0 +1,322 @@
+package billing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StubGateway is an in-memory Gateway for tests and offline development.
+// Webhook payloads are this package's own types encoded as JSON, and signatures are not checked.
+type StubGateway struct {
+	mu            sync.Mutex
+	Customers     map[string]*Customer
+	Sessions      map[string]*CheckoutSession
+	Subscriptions map[string]*Subscription
+	Prices        map[string]*Price
+	// Promotion codes by their customer-facing code
+	PromotionCodes map[string]*PromotionCode
+	TaxIDs         map[string]*TaxID
+	Invoices       map[string]*Invoice
+}
+
+// NewStubGateway creates an empty stub gateway
+func NewStubGateway() *StubGateway {
+	return &StubGateway{
+		Customers:      make(map[string]*Customer),
+		Sessions:       make(map[string]*CheckoutSession),
+		Subscriptions:  make(map[string]*Subscription),
+		Prices:         make(map[string]*Price),
+		PromotionCodes: make(map[string]*PromotionCode),
+		TaxIDs:         make(map[string]*TaxID),
+		Invoices:       make(map[string]*Invoice),
+	}
+}
+
+func stubID(prefix string) string {
+	return prefix + "_" + uuid.New().String()[:8]
+}
+
+// CreateCustomer stores a new customer
+func (g *StubGateway) CreateCustomer(ctx context.Context, in CustomerInput) (*Customer, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	cus := &Customer{ID: stubID("cus"), Email: in.Email}
+	g.Customers[cus.ID] = cus
+	return cus, nil
+}
+
+// GetCustomer returns a stored customer
+func (g *StubGateway) GetCustomer(ctx context.Context, customerID string) (*Customer, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	cus, ok := g.Customers[customerID]
+	if !ok {
+		return nil, fmt.Errorf("no such customer: %s", customerID)
+	}
+	return cus, nil
+}
+
+// Ping always succeeds
+func (g *StubGateway) Ping(ctx context.Context) error {
+	return nil
+}
+
+// CreateCheckoutSession stores a new session. Subscription sessions immediately
+// get an active subscription so flows can be exercised end to end.
+func (g *StubGateway) CreateCheckoutSession(ctx context.Context, in CheckoutSessionInput) (*CheckoutSession, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	sess := &CheckoutSession{
+		ID:         stubID("cs"),
+		Mode:       in.Mode,
+		CustomerID: in.CustomerID,
+		Metadata:   in.Metadata,
+	}
+	sess.URL = "https://checkout.stub.local/" + sess.ID
+
+	if in.Mode == CheckoutModeSubscription && len(in.LineItems) > 0 {
+		s := &Subscription{
+			ID:               stubID("sub"),
+			CustomerID:       in.CustomerID,
+			Status:           "active",
+			PriceID:          in.LineItems[0].PriceID,
+			ItemID:           stubID("si"),
+			Quantity:         in.LineItems[0].Quantity,
+			CurrentPeriodEnd: time.Now().AddDate(0, 1, 0),
+		}
+		for _, p := range g.PromotionCodes {
+			if p.ID == in.PromotionCodeID {
+				s.Discount = &Discount{Coupon: p.Coupon, PromotionCodeID: p.ID, Start: time.Now()}
+			}
+		}
+		g.Subscriptions[s.ID] = s
+		sess.SubscriptionID = s.ID
+	}
+
+	g.Sessions[sess.ID] = sess
+	return sess, nil
+}
+
+// GetSubscription returns a stored subscription
+func (g *StubGateway) GetSubscription(ctx context.Context, subscriptionID string) (*Subscription, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	s, ok := g.Subscriptions[subscriptionID]
+	if !ok {
+		return nil, fmt.Errorf("no such subscription: %s", subscriptionID)
+	}
+	copied := *s
+	return &copied, nil
+}
+
+// SetCancelAtPeriodEnd flags a stored subscription
+func (g *StubGateway) SetCancelAtPeriodEnd(ctx context.Context, subscriptionID string, cancel bool) (*Subscription, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	s, ok := g.Subscriptions[subscriptionID]
+	if !ok {
+		return nil, fmt.Errorf("no such subscription: %s", subscriptionID)
+	}
+	s.CancelAtPeriodEnd = cancel
+	copied := *s
+	return &copied, nil
+}
+
+// SetSubscriptionQuantity changes the quantity of a stored subscription's item
+func (g *StubGateway) SetSubscriptionQuantity(ctx context.Context, subscriptionID, itemID string, quantity int64) (*Subscription, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	s, ok := g.Subscriptions[subscriptionID]
+	if !ok || s.ItemID != itemID {
+		return nil, fmt.Errorf("no such subscription item: %s", itemID)
+	}
+	s.Quantity = quantity
+	copied := *s
+	return &copied, nil
+}
+
+// EachSubscription calls fn for the stored subscriptions ordered by ID
+func (g *StubGateway) EachSubscription(ctx context.Context, fn func(*Subscription) error) error {
+	g.mu.Lock()
+	subs := make([]Subscription, 0, len(g.Subscriptions))
+	for _, s := range g.Subscriptions {
+		subs = append(subs, *s)
+	}
+	g.mu.Unlock()
+
+	sort.Slice(subs, func(i, j int) bool { return subs[i].ID < subs[j].ID })
+	for i := range subs {
+		if err := fn(&subs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListPrices returns the stored prices ordered by ID
+func (g *StubGateway) ListPrices(ctx context.Context) ([]Price, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	prices := make([]Price, 0, len(g.Prices))
+	for _, p := range g.Prices {
+		prices = append(prices, *p)
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].ID < prices[j].ID })
+	return prices, nil
+}
+
+// FindPromotionCode returns a stored promotion code, matching the code case-insensitively
+func (g *StubGateway) FindPromotionCode(ctx context.Context, code string) (*PromotionCode, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for stored, p := range g.PromotionCodes {
+		if strings.EqualFold(stored, code) {
+			copied := *p
+			return &copied, nil
+		}
+	}
+	return nil, ErrPromotionCodeNotFound
+}
+
+// ListTaxIDs returns the customer's stored tax IDs ordered by ID
+func (g *StubGateway) ListTaxIDs(ctx context.Context, customerID string) ([]TaxID, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var taxIDs []TaxID
+	for _, t := range g.TaxIDs {
+		if t.CustomerID == customerID {
+			taxIDs = append(taxIDs, *t)
+		}
+	}
+	sort.Slice(taxIDs, func(i, j int) bool { return taxIDs[i].ID < taxIDs[j].ID })
+	return taxIDs, nil
+}
+
+// CreateTaxID stores a tax ID; only empty types and values are rejected
+func (g *StubGateway) CreateTaxID(ctx context.Context, customerID, taxType, value string) (*TaxID, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if taxType == "" || value == "" {
+		return nil, fmt.Errorf("%w: type and value are required", ErrInvalidTaxID)
+	}
+	t := &TaxID{
+		ID:                 stubID("txi"),
+		CustomerID:         customerID,
+		Type:               taxType,
+		Value:              value,
+		VerificationStatus: "unavailable",
+	}
+	g.TaxIDs[t.ID] = t
+	copied := *t
+	return &copied, nil
+}
+
+// DeleteTaxID removes a stored tax ID
+func (g *StubGateway) DeleteTaxID(ctx context.Context, customerID, taxID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	t, ok := g.TaxIDs[taxID]
+	if !ok || t.CustomerID != customerID {
+		return fmt.Errorf("no such tax ID: %s", taxID)
+	}
+	delete(g.TaxIDs, taxID)
+	return nil
+}
+
+// GetInvoice returns a stored invoice
+func (g *StubGateway) GetInvoice(ctx context.Context, invoiceID string) (*Invoice, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	inv, ok := g.Invoices[invoiceID]
+	if !ok {
+		return nil, ErrInvoiceNotFound
+	}
+	copied := *inv
+	return &copied, nil
+}
+
+// ConstructEvent parses {"id": ..., "type": ..., "data": ...} without verifying the signature,
+// generating an ID when none is given
+func (g *StubGateway) ConstructEvent(payload []byte, signature, secret string) (*Event, error) {
+	var envelope struct {
+		ID      string          `json:"id"`
+		Type    string          `json:"type"`
+		Created int64           `json:"created"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return nil, err
+	}
+	if envelope.ID == "" {
+		envelope.ID = stubID("evt")
+	}
+	created := time.Now()
+	if envelope.Created > 0 {
+		created = time.Unix(envelope.Created, 0)
+	}
+	return &Event{ID: envelope.ID, Type: envelope.Type, Created: created, Data: envelope.Data}, nil
+}
+
+// DecodeCheckoutSession decodes a CheckoutSession payload
+func (g *StubGateway) DecodeCheckoutSession(e *Event) (*CheckoutSession, error) {
+	var sess CheckoutSession
+	if err := json.Unmarshal(e.Data, &sess); err != nil {
+		return nil, err
+	}
+	return &sess, nil
+}
+
+// DecodeSubscription decodes a Subscription payload
+func (g *StubGateway) DecodeSubscription(e *Event) (*Subscription, error) {
+	var s Subscription
+	if err := json.Unmarshal(e.Data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// DecodePaymentMethod decodes a PaymentMethod payload
+func (g *StubGateway) DecodePaymentMethod(e *Event) (*PaymentMethod, error) {
+	var pm PaymentMethod
+	if err := json.Unmarshal(e.Data, &pm); err != nil {
+		return nil, err
+	}
+	return &pm, nil
+}
+
+// DecodeTaxID decodes a TaxID payload
+func (g *StubGateway) DecodeTaxID(e *Event) (*TaxID, error) {
+	var t TaxID
+	if err := json.Unmarshal(e.Data, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// DecodeInvoice decodes an Invoice payload
+func (g *StubGateway) DecodeInvoice(e *Event) (*Invoice, error) {
+	var inv Invoice
+	if err := json.Unmarshal(e.Data, &inv); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
@@ -0,0 +1,138 @@
+// Package branding stores organizations' logos and applies their branding to
+// exported documents and outgoing emails.
+package branding
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // register the JPEG decoder
+	_ "image/png"  // register the PNG decoder
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/pdf"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"gorm.io/gorm"
+)
+
+// Limits on uploaded logos
+const (
+	MaxLogoBytes       = 512 << 10
+	MaxLogoDimension   = 2000
+	logoFilePrefix     = "org-"
+	defaultBrandingDir = "./branding"
+)
+
+// ErrInvalidLogo is returned for logos that are not PNG or JPEG images
+// within the size limits
+var ErrInvalidLogo = errors.New("logo must be a PNG or JPEG image of at most 512KB and 2000x2000 pixels")
+
+// Dir returns the directory logos are stored in, set by BRANDING_DIR
+func Dir() string {
+	return utils.GetEnvWithDefault("BRANDING_DIR", defaultBrandingDir)
+}
+
+// SaveLogo validates and stores an organization's logo, replacing any
+// previous one
+func SaveLogo(db *gorm.DB, orgID uint, data []byte) (*models.OrgBranding, error) {
+	if len(data) > MaxLogoBytes {
+		return nil, ErrInvalidLogo
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "png" && format != "jpeg") {
+		return nil, ErrInvalidLogo
+	}
+	if config.Width < 1 || config.Height < 1 || config.Width > MaxLogoDimension || config.Height > MaxLogoDimension {
+		return nil, ErrInvalidLogo
+	}
+
+	previous, err := models.FindOrgBranding(db, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(Dir(), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create branding directory: %w", err)
+	}
+	// A new name per upload keeps the old logo readable until the row points
+	// at the new one
+	name := fmt.Sprintf("%s%d-%d.%s", logoFilePrefix, orgID, time.Now().UnixNano(), strings.Replace(format, "jpeg", "jpg", 1))
+	if err := os.WriteFile(filepath.Join(Dir(), name), data, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to store logo: %w", err)
+	}
+	if err := models.SetBrandingLogo(db, orgID, name, "image/"+format); err != nil {
+		os.Remove(filepath.Join(Dir(), name))
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if previous != nil && previous.LogoFile != "" {
+		removeLogoFile(previous.LogoFile)
+	}
+	return models.FindOrgBranding(db, orgID)
+}
+
+// ReadLogo returns the contents of the organization's logo, or nil if it has none
+func ReadLogo(b *models.OrgBranding) ([]byte, error) {
+	if b == nil || b.LogoFile == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filepath.Join(Dir(), filepath.Base(b.LogoFile)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read logo: %w", err)
+	}
+	return data, nil
+}
+
+// DeleteLogo removes the organization's logo and keeps the rest of its branding
+func DeleteLogo(db *gorm.DB, b *models.OrgBranding) error {
+	if b.LogoFile == "" {
+		return nil
+	}
+	if err := models.ClearBrandingLogo(db, b.OrganizationID); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	removeLogoFile(b.LogoFile)
+	return nil
+}
+
+// Delete removes the organization's branding together with its logo
+func Delete(db *gorm.DB, orgID uint) error {
+	b, err := models.FindOrgBranding(db, orgID)
+	if err != nil || b == nil {
+		return err
+	}
+	if err := models.DeleteOrgBranding(db, orgID); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if b.LogoFile != "" {
+		removeLogoFile(b.LogoFile)
+	}
+	return nil
+}
+
+// ForPDF converts the branding for PDF exports; nil keeps the defaults
+func ForPDF(b *models.OrgBranding) (*pdf.Branding, error) {
+	if b == nil {
+		return nil, nil
+	}
+	logo, err := ReadLogo(b)
+	if err != nil {
+		return nil, err
+	}
+	return &pdf.Branding{
+		Name:         b.DisplayName,
+		PrimaryColor: b.PrimaryColor,
+		AccentColor:  b.AccentColor,
+		FooterText:   b.FooterText,
+		Logo:         logo,
+	}, nil
+}
+
+// removeLogoFile deletes a logo file; a file that is already gone is fine
+func removeLogoFile(name string) {
+	os.Remove(filepath.Join(Dir(), filepath.Base(name)))
+}
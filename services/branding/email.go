@@ -0,0 +1,87 @@
+package branding
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+)
+
+// Email is an outgoing email with an organization's branding applied
+type Email struct {
+	// FromName is the sender's display name
+	FromName string
+	// Text is the plain-text body with the footer appended
+	Text string
+	// HTML is the branded alternative to Text
+	HTML string
+}
+
+var emailTemplate = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+<body style="margin:0;padding:0;font-family:Helvetica,Arial,sans-serif;color:#222222">
+<div style="max-width:600px;margin:0 auto;padding:24px;border-top:4px solid {{.AccentColor}}">
+{{if .LogoURL}}<img src="{{.LogoURL}}" alt="{{.Name}}" style="max-height:48px;margin-bottom:12px">{{end}}
+<h2 style="margin:0 0 16px;color:{{.PrimaryColor}}">{{.Name}}</h2>
+<div style="white-space:pre-wrap;line-height:1.5">{{.Body}}</div>
+{{if .FooterText}}<p style="margin-top:24px;padding-top:12px;border-top:1px solid {{.AccentColor}};color:#777777;font-size:12px">{{.FooterText}}</p>{{end}}
+</div>
+</body>
+</html>
+`))
+
+// ApplyToEmail brands a plain-text email body for the organization's members:
+// the sender is named after the organization, the footer text is appended and
+// an HTML alternative shows the logo and colors
+func ApplyToEmail(b *models.OrgBranding, body string) (*Email, error) {
+	name := b.DisplayName
+	if name == "" {
+		name = "ThinkInk"
+	}
+	text := body
+	if b.FooterText != "" {
+		text = strings.TrimRight(body, "\n") + "\n\n-- \n" + b.FooterText + "\n"
+	}
+
+	// Colors are validated as #RRGGBB when they are saved
+	data := struct {
+		Name, Body, FooterText, LogoURL string
+		PrimaryColor, AccentColor       template.CSS
+	}{
+		Name:         name,
+		Body:         body,
+		FooterText:   b.FooterText,
+		PrimaryColor: template.CSS(colorOr(b.PrimaryColor, "#222222")),
+		AccentColor:  template.CSS(colorOr(b.AccentColor, "#dddddd")),
+	}
+	if b.LogoFile != "" {
+		data.LogoURL = LogoURL(b)
+	}
+
+	var html strings.Builder
+	if err := emailTemplate.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("failed to render email: %w", err)
+	}
+	return &Email{FromName: b.DisplayName, Text: text, HTML: html.String()}, nil
+}
+
+// LogoURL returns the public address of the organization's logo, based on
+// API_PUBLIC_URL. The version parameter changes with every upload so mail
+// clients do not show a cached old logo.
+func LogoURL(b *models.OrgBranding) string {
+	base := strings.TrimRight(utils.GetEnvWithDefault("API_PUBLIC_URL", "http://localhost:8080"), "/")
+	url := fmt.Sprintf("%s/branding/%d/logo", base, b.OrganizationID)
+	if b.LogoUpdatedAt != nil {
+		url += fmt.Sprintf("?v=%d", b.LogoUpdatedAt.Unix())
+	}
+	return url
+}
+
+func colorOr(color, fallback string) string {
+	if color == "" {
+		return fallback
+	}
+	return color
+}
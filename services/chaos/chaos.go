@@ -0,0 +1,148 @@
+// Package chaos injects faults into requests and ML calls so the frontend and
+// operations can rehearse failure handling against realistic API behavior.
+// It is refused in production.
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+)
+
+// MaxLatency bounds the latency a single request can be given
+const MaxLatency = 30 * time.Second
+
+// Config controls which faults are injected. Rates are probabilities between 0 and 1.
+type Config struct {
+	Enabled bool
+	// Added to every request
+	Latency time.Duration
+	// Share of requests to any route that fail with ErrorStatus
+	ErrorRate float64
+	// Share of requests that fail, per route as "METHOD /path" with gin's
+	// parameter syntax, e.g. "GET /reports/:id"; overrides ErrorRate
+	RouteErrorRates map[string]float64
+	ErrorStatus     int
+	// Share of translations that fail as if the ML service had
+	MLFailureRate float64
+}
+
+var current atomic.Pointer[Config]
+
+// Configure sets the faults injected from now on
+func Configure(cfg Config) {
+	current.Store(&cfg)
+}
+
+// Current returns the active configuration; faults are disabled until Configure is called
+func Current() Config {
+	if cfg := current.Load(); cfg != nil {
+		return *cfg
+	}
+	return Config{}
+}
+
+// FromEnv reads CHAOS_ENABLED, CHAOS_LATENCY, CHAOS_ERROR_RATE,
+// CHAOS_ROUTE_ERROR_RATES, CHAOS_ERROR_STATUS and CHAOS_ML_FAILURE_RATE.
+// Enabling it with APP_ENV=production is an error.
+func FromEnv() (Config, error) {
+	cfg := Config{RouteErrorRates: map[string]float64{}}
+
+	enabled, err := strconv.ParseBool(utils.GetEnvWithDefault("CHAOS_ENABLED", "false"))
+	if err != nil {
+		return cfg, fmt.Errorf("CHAOS_ENABLED must be true or false")
+	}
+	if !enabled {
+		return cfg, nil
+	}
+	if utils.GetEnvWithDefault("APP_ENV", "development") == "production" {
+		return cfg, fmt.Errorf("CHAOS_ENABLED must not be set in production")
+	}
+	cfg.Enabled = true
+
+	if cfg.Latency, err = time.ParseDuration(utils.GetEnvWithDefault("CHAOS_LATENCY", "0s")); err != nil || cfg.Latency < 0 || cfg.Latency > MaxLatency {
+		return cfg, fmt.Errorf("CHAOS_LATENCY must be a duration between 0s and %s", MaxLatency)
+	}
+	if cfg.ErrorRate, err = ParseRate(utils.GetEnvWithDefault("CHAOS_ERROR_RATE", "0")); err != nil {
+		return cfg, fmt.Errorf("CHAOS_ERROR_RATE %v", err)
+	}
+	if cfg.MLFailureRate, err = ParseRate(utils.GetEnvWithDefault("CHAOS_ML_FAILURE_RATE", "0")); err != nil {
+		return cfg, fmt.Errorf("CHAOS_ML_FAILURE_RATE %v", err)
+	}
+	if cfg.ErrorStatus, err = ParseStatus(utils.GetEnvWithDefault("CHAOS_ERROR_STATUS", "503")); err != nil {
+		return cfg, fmt.Errorf("CHAOS_ERROR_STATUS %v", err)
+	}
+
+	// e.g. "GET /reports=0.2,POST /upload=0.5"
+	for _, entry := range strings.Split(utils.GetEnvWithDefault("CHAOS_ROUTE_ERROR_RATES", ""), ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		route, value, ok := strings.Cut(entry, "=")
+		route = strings.Join(strings.Fields(route), " ")
+		if !ok || len(strings.Fields(route)) != 2 {
+			return cfg, fmt.Errorf("CHAOS_ROUTE_ERROR_RATES entries must look like \"GET /reports/:id=0.2\", got %q", entry)
+		}
+		rate, err := ParseRate(value)
+		if err != nil {
+			return cfg, fmt.Errorf("CHAOS_ROUTE_ERROR_RATES[%s] %v", route, err)
+		}
+		cfg.RouteErrorRates[route] = rate
+	}
+	return cfg, nil
+}
+
+// ParseRate reads a probability between 0 and 1
+func ParseRate(value string) (float64, error) {
+	rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || rate < 0 || rate > 1 {
+		return 0, fmt.Errorf("must be a rate between 0 and 1")
+	}
+	return rate, nil
+}
+
+// ParseStatus reads an HTTP error status between 400 and 599
+func ParseStatus(value string) (int, error) {
+	status, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || status < 400 || status > 599 {
+		return 0, fmt.Errorf("must be an HTTP status between 400 and 599")
+	}
+	return status, nil
+}
+
+// Roll reports whether an event with the given rate happens this time
+func Roll(rate float64) bool {
+	return rate > 0 && rand.Float64() < rate
+}
+
+type faultsKey struct{}
+
+// Faults are the faults requested for a single request
+type Faults struct {
+	// Translations made for the request fail
+	FailML bool
+}
+
+// WithFaults returns a context carrying a request's faults
+func WithFaults(ctx context.Context, faults Faults) context.Context {
+	return context.WithValue(ctx, faultsKey{}, faults)
+}
+
+// FailTranslation reports whether a translation should fail as if the ML
+// service had: because the request asked for it or by the configured rate
+func FailTranslation(ctx context.Context) bool {
+	cfg := Current()
+	if !cfg.Enabled {
+		return false
+	}
+	if faults, ok := ctx.Value(faultsKey{}).(Faults); ok && faults.FailML {
+		return true
+	}
+	return Roll(cfg.MLFailureRate)
+}
@@ -0,0 +1,102 @@
+package coldstorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+)
+
+// Backends for REPORT_COLD_STORAGE
+const (
+	BackendFile = "file"
+	BackendS3   = "s3"
+)
+
+// FromEnv builds the content archive named by REPORT_COLD_STORAGE: "file"
+// (default) keeps objects under REPORT_COLD_STORAGE_DIR, "s3" in the
+// S3-compatible bucket given by the REPORT_COLD_STORAGE_S3_* variables
+func FromEnv() (models.ContentArchive, error) {
+	switch backend := utils.GetEnvWithDefault("REPORT_COLD_STORAGE", BackendFile); backend {
+	case BackendFile:
+		return NewFileStore(utils.GetEnvWithDefault("REPORT_COLD_STORAGE_DIR", "./cold-storage")), nil
+	case BackendS3:
+		return NewS3Store(
+			utils.GetEnvWithDefault("REPORT_COLD_STORAGE_S3_ENDPOINT", "https://s3.amazonaws.com"),
+			utils.GetEnvWithDefault("REPORT_COLD_STORAGE_S3_BUCKET", ""),
+			utils.GetEnvWithDefault("REPORT_COLD_STORAGE_S3_REGION", "us-east-1"),
+			utils.GetEnvWithDefault("REPORT_COLD_STORAGE_S3_ACCESS_KEY_ID", ""),
+			utils.GetEnvWithDefault("REPORT_COLD_STORAGE_S3_SECRET_ACCESS_KEY", ""),
+		)
+	default:
+		return nil, fmt.Errorf("REPORT_COLD_STORAGE must be %q or %q, got %q", BackendFile, BackendS3, backend)
+	}
+}
+
+// FileStore keeps objects as files under a directory, for single-host
+// deployments or a mounted bucket
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store rooted at dir
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Dir returns the directory objects are kept in
+func (s *FileStore) Dir() string {
+	return s.dir
+}
+
+func (s *FileStore) path(key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if filepath.IsAbs(cleaned) || cleaned == "." || strings.HasPrefix(cleaned, "..") {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return filepath.Join(s.dir, cleaned), nil
+}
+
+// Put writes the object, replacing any previous version
+func (s *FileStore) Put(ctx context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see a partial object
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Get reads the object
+func (s *FileStore) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+// Delete removes the object; deleting a missing object is not an error
+func (s *FileStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
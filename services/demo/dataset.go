@@ -0,0 +1,261 @@
+// Package demo generates a deterministic in-memory dataset of users, reports
+// and subscription states for frontend development without a database.
+package demo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"gorm.io/datatypes"
+)
+
+// Shape of the generated dataset
+const (
+	UserCount      = 12
+	ReportsPerUser = 24
+	channels       = 8
+	samples        = 32
+	seed           = 4301
+)
+
+// epoch anchors every generated timestamp so responses are identical across restarts
+var epoch = time.Date(2025, time.January, 6, 9, 0, 0, 0, time.UTC)
+
+// Subscription is the subscription state of a demo user
+type Subscription struct {
+	SubscriptionID    string
+	PlanID            string
+	Status            string
+	CancelAtPeriodEnd bool
+	CurrentPeriodEnd  *time.Time
+}
+
+// Active reports whether the subscription grants subscriber features
+func (s *Subscription) Active() bool {
+	return s.Status == "active" || s.Status == "trialing"
+}
+
+// Dataset is the generated demo data. It is read-only once built.
+type Dataset struct {
+	Users         []models.User
+	Reports       []models.Report
+	Subscriptions map[uint]*Subscription
+}
+
+var (
+	once    sync.Once
+	dataset *Dataset
+)
+
+// Default returns the demo dataset, generating it on first use
+func Default() *Dataset {
+	once.Do(func() {
+		dataset = Generate(seed)
+	})
+	return dataset
+}
+
+// subscriptionStates cycles through every state the frontend has to render
+var subscriptionStates = []struct {
+	status      string
+	plan        string
+	cancelAtEnd bool
+	periodDays  int // relative to the epoch; 0 means no subscription
+}{
+	{"active", "price_monthly", false, 30},
+	{"trialing", "price_monthly", false, 14},
+	{"active", "price_yearly", true, 200},
+	{"past_due", "price_monthly", false, -3},
+	{"canceled", "price_monthly", false, -20},
+	{models.SubscriptionStatusExpired, "price_yearly", false, -45},
+	{"", "", false, 0},
+}
+
+var (
+	firstNames = []string{"Amira", "Ben", "Chloe", "Daniel", "Elena", "Farid", "Grace", "Hiro", "Ines", "Jonas", "Kemi", "Lucas"}
+	lastNames  = []string{"Haddad", "Okafor", "Novak", "Silva", "Larsen", "Moreau", "Tanaka", "Kowalski", "Ruiz", "Schmidt", "Mensah", "Rossi"}
+	cities     = []struct{ city, country, timezone, locale string }{
+		{"Cairo", "EG", "Africa/Cairo", "ar-EG"},
+		{"London", "GB", "Europe/London", "en-GB"},
+		{"New York", "US", "America/New_York", "en-US"},
+		{"Berlin", "DE", "Europe/Berlin", "de-DE"},
+		{"Tokyo", "JP", "Asia/Tokyo", "ja-JP"},
+		{"São Paulo", "BR", "America/Sao_Paulo", "pt-BR"},
+	}
+	phrases = []string{
+		"I would like a cup of tea", "Please open the window", "I am feeling tired today",
+		"Can you call my sister", "The music is too loud", "I want to go outside",
+		"Thank you for helping me", "My back hurts a little", "Turn on the television please",
+		"I am hungry", "What time is it", "I slept well last night",
+	}
+	sessions = []string{"morning", "afternoon", "evening"}
+	tagNames = []string{"morning", "post-medication", "noisy-room", "calibration", "good-signal"}
+)
+
+// Generate builds a dataset from seed. The same seed always yields the same data.
+func Generate(seed int64) *Dataset {
+	rng := rand.New(rand.NewSource(seed))
+	d := &Dataset{Subscriptions: make(map[uint]*Subscription)}
+
+	for i := 0; i < UserCount; i++ {
+		id := uint(i + 1)
+		place := cities[rng.Intn(len(cities))]
+		createdAt := epoch.AddDate(0, 0, -rng.Intn(365)-30)
+		lastLogin := epoch.Add(-time.Duration(rng.Intn(72)) * time.Hour)
+		name := firstNames[i%len(firstNames)] + " " + lastNames[rng.Intn(len(lastNames))]
+		user := models.User{
+			ID:               id,
+			Name:             name,
+			Email:            fmt.Sprintf("%s@demo.thinkink.test", strings.ToLower(strings.ReplaceAll(name, " ", "."))),
+			DateOfBirth:      time.Date(1950+rng.Intn(50), time.Month(1+rng.Intn(12)), 1+rng.Intn(28), 0, 0, 0, 0, time.UTC),
+			City:             place.city,
+			Country:          place.country,
+			Timezone:         place.timezone,
+			Locale:           place.locale,
+			CreatedAt:        createdAt,
+			LastLogin:        &lastLogin,
+			Role:             models.RoleUser,
+			RecordingsStatus: "active",
+			EmailVerifiedAt:  &createdAt,
+		}
+		if i == 0 {
+			user.Role = models.RoleAdmin
+		}
+
+		state := subscriptionStates[i%len(subscriptionStates)]
+		if state.periodDays != 0 {
+			periodEnd := epoch.AddDate(0, 0, state.periodDays)
+			subscriptionID := fmt.Sprintf("sub_demo%04d", id)
+			plan, status := state.plan, state.status
+			user.SubscriptionID = &subscriptionID
+			user.CurrentPlanID = &plan
+			user.SubscriptionStatus = &status
+			user.SubscriptionEndsAt = &periodEnd
+			user.FirstSubscribedAt = &createdAt
+			d.Subscriptions[id] = &Subscription{
+				SubscriptionID:    subscriptionID,
+				PlanID:            plan,
+				Status:            status,
+				CancelAtPeriodEnd: state.cancelAtEnd,
+				CurrentPeriodEnd:  &periodEnd,
+			}
+		} else {
+			d.Subscriptions[id] = &Subscription{}
+		}
+
+		for j := 0; j < ReportsPerUser; j++ {
+			d.Reports = append(d.Reports, generateReport(rng, uint(len(d.Reports)+1), id, j))
+		}
+		firstUpload := d.Reports[len(d.Reports)-ReportsPerUser].CreatedAt
+		user.FirstUploadAt = &firstUpload
+		user.FirstReportAt = &firstUpload
+		d.Users = append(d.Users, user)
+	}
+	return d
+}
+
+// generateReport builds the n-th (oldest first) report of a user
+func generateReport(rng *rand.Rand, id, userID uint, n int) models.Report {
+	createdAt := epoch.Add(-time.Duration(ReportsPerUser-n) * 26 * time.Hour).Add(time.Duration(rng.Intn(3600)) * time.Second)
+	session := sessions[rng.Intn(len(sessions))]
+	report := models.Report{
+		ID:                   id,
+		UserID:               userID,
+		Title:                fmt.Sprintf("%s-session-%s.json", session, createdAt.Format("2006-01-02")),
+		Description:          phrases[rng.Intn(len(phrases))],
+		Content:              generateContent(rng),
+		CreatedAt:            createdAt,
+		UpdatedAt:            createdAt.Add(time.Duration(rng.Intn(90)) * time.Second),
+		MatchingScale:        rng.Intn(11),
+		ContentSchemaVersion: models.CurrentContentSchemaVersion,
+		Metadata:             datatypes.JSON(fmt.Sprintf(`{"session_notes":"%s session","device_id":"headset-%02d"}`, session, userID)),
+	}
+	report.SizeBytes = int64(len(report.Content))
+
+	switch {
+	case n == ReportsPerUser-1 && userID%4 == 0:
+		report.TranslationStatus = models.TranslationPending
+		report.Description = ""
+	case n == ReportsPerUser-2 && userID%5 == 0:
+		report.TranslationStatus = models.TranslationFailed
+		report.Description = ""
+	case report.MatchingScale < 4 && report.Description != "":
+		report.Correction = phrases[rng.Intn(len(phrases))]
+	}
+	if n < 3 {
+		archivedAt := createdAt.AddDate(0, 0, 7)
+		report.ArchivedAt = &archivedAt
+	}
+
+	tags := map[string]bool{}
+	if session == "morning" {
+		tags["morning"] = true
+	}
+	for k := rng.Intn(3); k > 0; k-- {
+		tags[tagNames[rng.Intn(len(tagNames))]] = true
+	}
+	for name := range tags {
+		report.Tags = append(report.Tags, name)
+	}
+	sort.Strings(report.Tags)
+	return report
+}
+
+// generateContent builds EEG content matching the current content schema: a
+// few noisy sine waves per channel, a mask marking artifact samples and the
+// electrode impedances
+func generateContent(rng *rand.Rand) datatypes.JSON {
+	eeg := make([][]float64, samples)
+	mask := make([]int, samples)
+	for s := range eeg {
+		eeg[s] = make([]float64, channels)
+		for ch := range eeg[s] {
+			wave := 20*math.Sin(float64(s)*0.4+float64(ch)) + 8*math.Sin(float64(s)*1.3)
+			eeg[s][ch] = math.Round((wave+rng.NormFloat64()*3)*100) / 100
+		}
+		if rng.Intn(10) > 0 {
+			mask[s] = 1
+		}
+	}
+	impedance := make([]float64, channels)
+	for ch := range impedance {
+		impedance[ch] = math.Round((3+rng.Float64()*12)*10) / 10
+	}
+
+	content, _ := json.Marshal(map[string]interface{}{"eeg": eeg, "mask": mask, "impedance": impedance})
+	return content
+}
+
+// User returns the demo user with the given ID
+func (d *Dataset) User(id uint) (*models.User, bool) {
+	if id < 1 || int(id) > len(d.Users) {
+		return nil, false
+	}
+	return &d.Users[id-1], true
+}
+
+// Report returns the demo report with the given ID
+func (d *Dataset) Report(id uint) (*models.Report, bool) {
+	if id < 1 || int(id) > len(d.Reports) {
+		return nil, false
+	}
+	return &d.Reports[id-1], true
+}
+
+// UserReports returns a user's unarchived reports, newest first
+func (d *Dataset) UserReports(userID uint) []models.Report {
+	var reports []models.Report
+	for i := len(d.Reports) - 1; i >= 0; i-- {
+		if d.Reports[i].UserID == userID && d.Reports[i].ArchivedAt == nil {
+			reports = append(reports, d.Reports[i])
+		}
+	}
+	return reports
+}
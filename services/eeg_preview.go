@@ -0,0 +1,62 @@
+package services
+
+import "math"
+
+// EEGPreviewPoints is how many points a waveform preview has per channel
+const EEGPreviewPoints = 200
+
+// EEGPreview is a downsampled waveform of a recording, small enough to draw.
+// Each point covers SamplesPerPoint consecutive samples and holds their
+// minimum and maximum, so spikes survive the downsampling.
+type EEGPreview struct {
+	Points          int                `json:"points" example:"200"`
+	SamplesPerPoint float64            `json:"samples_per_point" example:"76.8"`
+	Series          []EEGPreviewSeries `json:"series"`
+}
+
+// EEGPreviewSeries is the preview of one channel
+type EEGPreviewSeries struct {
+	Channel int       `json:"channel" example:"0"`
+	Min     []float32 `json:"min"`
+	Max     []float32 `json:"max"`
+}
+
+// PreviewEEGData downsamples a recording that passed ValidateEEGData to at
+// most points min/max pairs per channel. Rows the mask marks as padding are
+// left out. It returns nil for a recording without samples.
+func PreviewEEGData(data *EEGData, points int) *EEGPreview {
+	rows := make([][]float32, 0, len(data.Eeg))
+	for i, row := range data.Eeg {
+		if i >= len(data.Msk) || data.Msk[i] != 0 {
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) == 0 || len(rows[0]) == 0 || points <= 0 {
+		return nil
+	}
+	if points > len(rows) {
+		points = len(rows)
+	}
+
+	channels := len(rows[0])
+	preview := &EEGPreview{
+		Points:          points,
+		SamplesPerPoint: float64(len(rows)) / float64(points),
+		Series:          make([]EEGPreviewSeries, channels),
+	}
+	for ch := range preview.Series {
+		preview.Series[ch] = EEGPreviewSeries{Channel: ch, Min: make([]float32, points), Max: make([]float32, points)}
+	}
+	for p := 0; p < points; p++ {
+		start, end := p*len(rows)/points, (p+1)*len(rows)/points
+		for ch := 0; ch < channels; ch++ {
+			lo, hi := float32(math.Inf(1)), float32(math.Inf(-1))
+			for _, row := range rows[start:end] {
+				lo = min(lo, row[ch])
+				hi = max(hi, row[ch])
+			}
+			preview.Series[ch].Min[p], preview.Series[ch].Max[p] = lo, hi
+		}
+	}
+	return preview
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"fmt"
+	"math"
+)
+
+// Quality thresholds
+const (
+	flatlineStdThreshold   = 1e-6 // Channel counts as flat below this standard deviation
+	artifactZThreshold     = 5.0  // Samples further than this many std devs from the mean are artifacts
+	highImpedanceKOhm      = 50.0 // Electrode impedance above this is unreliable
+	maxFlatlineRatio       = 0.25
+	maxMeanArtifactPercent = 20.0
+	maxHighImpedanceRatio  = 0.25
+)
+
+// ChannelQuality holds signal-quality metrics for a single EEG channel
+type ChannelQuality struct {
+	Channel         int      `json:"channel"`
+	Mean            float64  `json:"mean"`
+	StdDev          float64  `json:"std_dev"`
+	Flatline        bool     `json:"flatline"`
+	ArtifactPercent float64  `json:"artifact_percent"`
+	ImpedanceKOhm   *float64 `json:"impedance_kohm,omitempty"`
+	HighImpedance   bool     `json:"high_impedance,omitempty"`
+}
+
+// EEGQualityReport summarises signal quality across all channels
+type EEGQualityReport struct {
+	Samples               int              `json:"samples"`
+	ChannelCount          int              `json:"channel_count"`
+	FlatlineChannels      int              `json:"flatline_channels"`
+	HighImpedanceChannels int              `json:"high_impedance_channels"`
+	MeanArtifactPercent   float64          `json:"mean_artifact_percent"`
+	LowQuality            bool             `json:"low_quality"`
+	Warnings              []string         `json:"warnings,omitempty"`
+	Channels              []ChannelQuality `json:"channels"`
+}
+
+// AnalyzeEEGQuality computes per-channel quality metrics. eeg is indexed as
+// [sample][channel]; samples whose mask value is 0 are padding and ignored.
+// impedance is optional and indexed by channel, in kOhm.
+func AnalyzeEEGQuality(eeg [][]float32, msk []float32, impedance []float32) *EEGQualityReport {
+	rows := make([][]float32, 0, len(eeg))
+	channels := 0
+	for i, row := range eeg {
+		if i < len(msk) && msk[i] == 0 {
+			continue
+		}
+		rows = append(rows, row)
+		if len(row) > channels {
+			channels = len(row)
+		}
+	}
+
+	report := &EEGQualityReport{
+		Samples:      len(rows),
+		ChannelCount: channels,
+		Channels:     make([]ChannelQuality, channels),
+	}
+	if len(rows) == 0 || channels == 0 {
+		report.LowQuality = true
+		report.Warnings = append(report.Warnings, "No EEG samples found")
+		return report
+	}
+
+	totalArtifacts := 0.0
+	for ch := 0; ch < channels; ch++ {
+		q := analyzeChannel(rows, ch)
+		if ch < len(impedance) {
+			z := float64(impedance[ch])
+			q.ImpedanceKOhm = &z
+			q.HighImpedance = z > highImpedanceKOhm
+		}
+
+		if q.Flatline {
+			report.FlatlineChannels++
+		}
+		if q.HighImpedance {
+			report.HighImpedanceChannels++
+		}
+		totalArtifacts += q.ArtifactPercent
+		report.Channels[ch] = q
+	}
+	report.MeanArtifactPercent = round2(totalArtifacts / float64(channels))
+
+	if ratio := float64(report.FlatlineChannels) / float64(channels); ratio > maxFlatlineRatio {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d of %d channels are flat", report.FlatlineChannels, channels))
+	}
+	if report.MeanArtifactPercent > maxMeanArtifactPercent {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%.1f%% of samples contain artifacts", report.MeanArtifactPercent))
+	}
+	if ratio := float64(report.HighImpedanceChannels) / float64(channels); ratio > maxHighImpedanceRatio {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d of %d electrodes report high impedance", report.HighImpedanceChannels, channels))
+	}
+	report.LowQuality = len(report.Warnings) > 0
+
+	return report
+}
+
+// analyzeChannel computes mean, deviation, flatline and artifact share for one channel.
+// Rows shorter than the channel index and non-finite values count as artifacts.
+func analyzeChannel(rows [][]float32, ch int) ChannelQuality {
+	q := ChannelQuality{Channel: ch}
+
+	var sum, sumSq float64
+	n, invalid := 0, 0
+	for _, row := range rows {
+		if ch >= len(row) || !isFinite(row[ch]) {
+			invalid++
+			continue
+		}
+		v := float64(row[ch])
+		sum += v
+		sumSq += v * v
+		n++
+	}
+
+	if n == 0 {
+		q.Flatline = true
+		q.ArtifactPercent = 100
+		return q
+	}
+
+	mean := sum / float64(n)
+	variance := sumSq/float64(n) - mean*mean
+	if variance < 0 {
+		variance = 0
+	}
+	std := math.Sqrt(variance)
+
+	artifacts := invalid
+	if std > flatlineStdThreshold {
+		for _, row := range rows {
+			if ch < len(row) && isFinite(row[ch]) && math.Abs(float64(row[ch])-mean) > artifactZThreshold*std {
+				artifacts++
+			}
+		}
+	}
+
+	q.Mean = round2(mean)
+	q.StdDev = round2(std)
+	q.Flatline = std <= flatlineStdThreshold
+	q.ArtifactPercent = round2(float64(artifacts) / float64(len(rows)) * 100)
+	return q
+}
+
+func isFinite(v float32) bool {
+	f := float64(v)
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
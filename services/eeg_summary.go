@@ -0,0 +1,31 @@
+package services
+
+// EEGSummary describes a recording at a glance
+type EEGSummary struct {
+	Channels int
+	// Rows the mask marks as samples; padding rows are not counted
+	Samples int
+	// The rate the recording declares, and the duration it gives the
+	// samples; both 0 when the recording declares none
+	SamplingRateHz  float64
+	DurationSeconds float64
+}
+
+// SummarizeEEGData measures the channel count, sample count, sampling rate
+// and duration of a recording that passed ValidateEEGData
+func SummarizeEEGData(data *EEGData) EEGSummary {
+	summary := EEGSummary{SamplingRateHz: data.SamplingRate}
+	if len(data.Eeg) > 0 {
+		summary.Channels = len(data.Eeg[0])
+	}
+	for i := range data.Eeg {
+		// Rows without a mask entry count as samples
+		if i >= len(data.Msk) || data.Msk[i] != 0 {
+			summary.Samples++
+		}
+	}
+	if summary.SamplingRateHz > 0 {
+		summary.DurationSeconds = float64(summary.Samples) / summary.SamplingRateHz
+	}
+	return summary
+}
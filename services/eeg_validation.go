@@ -0,0 +1,158 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Sanity limits for recordings sent to the ML service
+const (
+	maxEEGChannels     = 512
+	maxSampleMicrovolt = 10000.0 // EEG stays far below 10mV; larger values are mis-scaled or corrupt
+	maxImpedanceKOhm   = 10000.0
+	maxSamplingRateHz  = 100000.0
+)
+
+// Codes of EEG validation issues
+const (
+	EEGIssueInvalidPayload   = "invalid_payload"
+	EEGIssueNoSamples        = "no_samples"
+	EEGIssueChannelCount     = "channel_count"
+	EEGIssueRowLength        = "row_length"
+	EEGIssueMaskLength       = "mask_length"
+	EEGIssueNonFinite        = "non_finite"
+	EEGIssueOutOfRange       = "out_of_range"
+	EEGIssueImpedanceLength  = "impedance_length"
+	EEGIssueImpedanceInvalid = "impedance_invalid"
+	EEGIssueSamplingRate     = "sampling_rate"
+)
+
+// EEGIssue is one thing wrong with a recording. Row and Channel point at the
+// first offending value, and Count is how many values share the problem.
+type EEGIssue struct {
+	Code    string `json:"code" example:"row_length"`
+	Message string `json:"message" example:"Row 12 has 31 values, but the recording has 32 channels"`
+	Row     *int   `json:"row,omitempty" example:"12"`
+	Channel *int   `json:"channel,omitempty"`
+	Count   int    `json:"count,omitempty" example:"3"`
+}
+
+// EEGValidationError lists everything wrong with a recording
+type EEGValidationError struct {
+	Issues []EEGIssue
+}
+
+func (e *EEGValidationError) Error() string {
+	messages := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		messages[i] = issue.Message
+	}
+	return "invalid EEG recording: " + strings.Join(messages, "; ")
+}
+
+// InvalidEEGPayload reports a file that could not be decoded as a recording
+func InvalidEEGPayload(err error) *EEGValidationError {
+	return &EEGValidationError{Issues: []EEGIssue{{Code: EEGIssueInvalidPayload, Message: err.Error()}}}
+}
+
+// ValidateEEGData checks that a recording is fit to be translated: it has
+// samples, a plausible channel count, rows of equal length, a mask entry per
+// row if it has a mask, and finite samples, impedances and sampling rate
+// within plausible ranges. It returns nil for a sound recording.
+func ValidateEEGData(data *EEGData) *EEGValidationError {
+	v := &eegValidator{}
+	// A sampling rate of 0 is one the recording does not declare
+	if data.SamplingRate < 0 || data.SamplingRate > maxSamplingRateHz {
+		v.add(EEGIssue{Code: EEGIssueSamplingRate, Message: fmt.Sprintf("The sampling rate is %g Hz; it must be above 0 and at most %g", data.SamplingRate, maxSamplingRateHz)})
+	}
+	if len(data.Eeg) == 0 {
+		v.add(EEGIssue{Code: EEGIssueNoSamples, Message: "The recording has no samples"})
+		return v.result()
+	}
+
+	channels := len(data.Eeg[0])
+	if channels == 0 || channels > maxEEGChannels {
+		v.add(EEGIssue{Code: EEGIssueChannelCount, Message: fmt.Sprintf("The recording has %d channels; between 1 and %d are supported", channels, maxEEGChannels)})
+		return v.result()
+	}
+
+	for i, row := range data.Eeg {
+		if len(row) != channels {
+			v.count(EEGIssueRowLength, i, -1, func() string {
+				return fmt.Sprintf("Row %d has %d values, but the recording has %d channels", i, len(row), channels)
+			})
+		}
+		for ch, sample := range row {
+			value := float64(sample)
+			switch {
+			case math.IsNaN(value) || math.IsInf(value, 0):
+				v.count(EEGIssueNonFinite, i, ch, func() string {
+					return fmt.Sprintf("Row %d, channel %d is %v", i, ch, value)
+				})
+			case math.Abs(value) > maxSampleMicrovolt:
+				v.count(EEGIssueOutOfRange, i, ch, func() string {
+					return fmt.Sprintf("Row %d, channel %d is %g µV, beyond the plausible ±%g µV", i, ch, value, maxSampleMicrovolt)
+				})
+			}
+		}
+	}
+
+	// Without a mask every row counts as a sample
+	if len(data.Msk) > 0 && len(data.Msk) != len(data.Eeg) {
+		v.add(EEGIssue{Code: EEGIssueMaskLength, Message: fmt.Sprintf("The mask has %d values, but the recording has %d rows", len(data.Msk), len(data.Eeg))})
+	}
+
+	if len(data.Impedance) > 0 {
+		if len(data.Impedance) != channels {
+			v.add(EEGIssue{Code: EEGIssueImpedanceLength, Message: fmt.Sprintf("The impedance has %d values, but the recording has %d channels", len(data.Impedance), channels)})
+		}
+		for ch, z := range data.Impedance {
+			value := float64(z)
+			if math.IsNaN(value) || value < 0 || value > maxImpedanceKOhm {
+				v.count(EEGIssueImpedanceInvalid, -1, ch, func() string {
+					return fmt.Sprintf("Impedance of channel %d is %g kOhm; it must be between 0 and %g", ch, value, maxImpedanceKOhm)
+				})
+			}
+		}
+	}
+	return v.result()
+}
+
+// eegValidator collects issues, keeping one per code for repeated problems
+type eegValidator struct {
+	issues []EEGIssue
+	byCode map[string]int
+}
+
+func (v *eegValidator) add(issue EEGIssue) {
+	v.issues = append(v.issues, issue)
+}
+
+// count records an occurrence of a repeated problem. Only the first one is
+// described; row or channel is -1 when it does not apply.
+func (v *eegValidator) count(code string, row, channel int, message func() string) {
+	if v.byCode == nil {
+		v.byCode = make(map[string]int)
+	}
+	if i, ok := v.byCode[code]; ok {
+		v.issues[i].Count++
+		return
+	}
+	issue := EEGIssue{Code: code, Message: message(), Count: 1}
+	if row >= 0 {
+		issue.Row = &row
+	}
+	if channel >= 0 {
+		issue.Channel = &channel
+	}
+	v.byCode[code] = len(v.issues)
+	v.add(issue)
+}
+
+func (v *eegValidator) result() *EEGValidationError {
+	if len(v.issues) == 0 {
+		return nil
+	}
+	return &EEGValidationError{Issues: v.issues}
+}
@@ -0,0 +1,72 @@
+package email
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket that allows up to ratePerMinute sends,
+// refilled continuously
+type rateLimiter struct {
+	mu       sync.Mutex
+	capacity float64
+	tokens   float64
+	perSec   float64
+	last     time.Time
+}
+
+// newRateLimiter creates a limiter that starts with a full bucket
+func newRateLimiter(ratePerMinute int) *rateLimiter {
+	capacity := float64(ratePerMinute)
+	return &rateLimiter{
+		capacity: capacity,
+		tokens:   capacity,
+		perSec:   capacity / 60,
+		last:     time.Now(),
+	}
+}
+
+// SetRate changes the refill rate, keeping the tokens already accumulated
+func (rl *rateLimiter) SetRate(ratePerMinute int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	capacity := float64(ratePerMinute)
+	if capacity == rl.capacity {
+		return
+	}
+	rl.capacity = capacity
+	rl.perSec = capacity / 60
+	if rl.tokens > capacity {
+		rl.tokens = capacity
+	}
+}
+
+// Wait blocks until a token is available or ctx is done
+func (rl *rateLimiter) Wait(ctx context.Context) error {
+	for {
+		rl.mu.Lock()
+		now := time.Now()
+		rl.tokens += now.Sub(rl.last).Seconds() * rl.perSec
+		if rl.tokens > rl.capacity {
+			rl.tokens = rl.capacity
+		}
+		rl.last = now
+
+		if rl.tokens >= 1 {
+			rl.tokens--
+			rl.mu.Unlock()
+			return nil
+		}
+
+		wait := time.Duration((1 - rl.tokens) / rl.perSec * float64(time.Second))
+		rl.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
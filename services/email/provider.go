@@ -0,0 +1,146 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"github.com/google/uuid"
+)
+
+// Provider delivers a single email message
+type Provider interface {
+	// Name returns the provider identifier stored on queued messages
+	Name() string
+	// Send delivers the message and returns the provider's message ID
+	Send(ctx context.Context, msg *models.EmailMessage) (string, error)
+}
+
+// PermanentError marks a send failure that must not be retried
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return fmt.Sprintf("permanent failure: %v", e.Err)
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// IsPermanent reports whether err is a non-retryable send failure
+func IsPermanent(err error) bool {
+	var permErr *PermanentError
+	return errors.As(err, &permErr)
+}
+
+// SMTPProvider sends email through an SMTP relay
+type SMTPProvider struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPProvider creates an SMTP provider from environment variables
+func NewSMTPProvider() *SMTPProvider {
+	return &SMTPProvider{
+		host:     utils.GetEnvWithDefault("SMTP_HOST", "localhost"),
+		port:     utils.GetEnvWithDefault("SMTP_PORT", "587"),
+		username: utils.GetEnvWithDefault("SMTP_USERNAME", ""),
+		password: utils.GetEnvWithDefault("SMTP_PASSWORD", ""),
+		from:     utils.GetEnvWithDefault("EMAIL_FROM", "no-reply@thinkink.app"),
+	}
+}
+
+// Name returns the provider identifier
+func (p *SMTPProvider) Name() string {
+	return "smtp"
+}
+
+// Send delivers the message through the configured relay
+func (p *SMTPProvider) Send(ctx context.Context, msg *models.EmailMessage) (string, error) {
+	// A line break in a header value would let it inject further headers
+	if strings.ContainsAny(msg.To, "\r\n") {
+		return "", &PermanentError{Err: errors.New("recipient address contains a line break")}
+	}
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		return "", &PermanentError{Err: errors.New("subject contains a line break")}
+	}
+
+	messageID := fmt.Sprintf("<%s@%s>", uuid.New().String(), p.host)
+
+	from := p.from
+	if msg.FromName != "" {
+		from = (&mail.Address{Name: msg.FromName, Address: p.from}).String()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Message-ID: %s\r\n", messageID)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	if msg.HTMLBody == "" {
+		b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+		b.WriteString(msg.Body)
+	} else {
+		// Branded messages carry the plain text and HTML as alternatives
+		boundary := uuid.New().String()
+		fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+		fmt.Fprintf(&b, "--%s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n", boundary, msg.Body)
+		fmt.Fprintf(&b, "--%s\r\nContent-Type: text/html; charset=UTF-8\r\n\r\n%s\r\n", boundary, msg.HTMLBody)
+		fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	}
+
+	var auth smtp.Auth
+	if p.username != "" {
+		auth = smtp.PlainAuth("", p.username, p.password, p.host)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- smtp.SendMail(p.host+":"+p.port, auth, p.from, []string{msg.To}, []byte(b.String()))
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case err := <-errCh:
+		if err != nil {
+			// 5xx replies are permanent rejections (unknown mailbox, policy block, ...)
+			var tpErr *textproto.Error
+			if errors.As(err, &tpErr) && tpErr.Code >= 500 {
+				return "", &PermanentError{Err: err}
+			}
+			return "", err
+		}
+		return messageID, nil
+	}
+}
+
+// LogProvider writes emails to the server log instead of sending them.
+// It is the default outside production so local setups need no relay.
+type LogProvider struct{}
+
+// Name returns the provider identifier
+func (p *LogProvider) Name() string {
+	return "log"
+}
+
+// Send logs the message
+func (p *LogProvider) Send(ctx context.Context, msg *models.EmailMessage) (string, error) {
+	log.Printf("Email to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return uuid.New().String(), nil
+}
@@ -0,0 +1,214 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/branding"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultBatchSize    = 50
+	defaultPollInterval = 5 * time.Second
+	defaultBaseBackoff  = 30 * time.Second
+	maxBackoff          = 6 * time.Hour
+	// claimLease covers one message's send; deliver renews it per message
+	claimLease  = 5 * time.Minute
+	sendTimeout = 30 * time.Second
+)
+
+// Service drains the persistent email queue through the configured providers
+type Service struct {
+	db           *gorm.DB
+	providers    map[string]Provider
+	limiters     map[string]*rateLimiter
+	batchSize    int
+	pollInterval time.Duration
+	baseBackoff  time.Duration
+}
+
+// DefaultProviderName returns the provider new messages are queued for
+func DefaultProviderName() string {
+	if utils.GetEnvWithDefault("APP_ENV", "development") == "production" {
+		return utils.GetEnvWithDefault("EMAIL_PROVIDER", "smtp")
+	}
+	return utils.GetEnvWithDefault("EMAIL_PROVIDER", "log")
+}
+
+// NewService creates a queue worker with the built-in providers registered
+func NewService(db *gorm.DB) *Service {
+	s := &Service{
+		db:           db,
+		providers:    make(map[string]Provider),
+		limiters:     make(map[string]*rateLimiter),
+		batchSize:    defaultBatchSize,
+		pollInterval: defaultPollInterval,
+		baseBackoff:  defaultBaseBackoff,
+	}
+
+	s.RegisterProvider(NewSMTPProvider())
+	s.RegisterProvider(&LogProvider{})
+
+	return s
+}
+
+// RegisterProvider adds a provider and its rate limiter. The limit is read from
+// EMAIL_RATE_PER_MINUTE_<NAME>, falling back to EMAIL_RATE_PER_MINUTE, and
+// follows config reloads.
+func (s *Service) RegisterProvider(p Provider) {
+	s.providers[p.Name()] = p
+	s.limiters[p.Name()] = newRateLimiter(config.Current().EmailRateFor(p.Name()))
+}
+
+// Enqueue stores an email for asynchronous delivery through the default
+// provider. Recipients who belong to an organization with branding get its
+// sender name, footer and a branded HTML alternative.
+func Enqueue(db *gorm.DB, to, subject, body, category string) error {
+	msg := &models.EmailMessage{
+		Provider: DefaultProviderName(),
+		To:       to,
+		Subject:  subject,
+		Body:     body,
+		Category: category,
+	}
+
+	orgBranding, err := models.FindBrandingForEmail(db, to)
+	if err != nil {
+		return err
+	}
+	if orgBranding != nil {
+		branded, err := branding.ApplyToEmail(orgBranding, body)
+		if err != nil {
+			return err
+		}
+		msg.Body = branded.Text
+		msg.HTMLBody = branded.HTML
+		msg.FromName = branded.FromName
+	}
+
+	return models.EnqueueEmail(db, msg)
+}
+
+// Run polls the queue until ctx is cancelled
+func (s *Service) Run(ctx context.Context) {
+	log.Printf("Email worker started (poll interval %s)", s.pollInterval)
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.ProcessBatch(ctx); err != nil {
+			log.Printf("Email worker error: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Email worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch claims and sends one batch of due messages
+func (s *Service) ProcessBatch(ctx context.Context) error {
+	messages, err := models.ClaimDueEmails(s.db, s.batchSize, claimLease)
+	if err != nil {
+		return err
+	}
+
+	for i := range messages {
+		if ctx.Err() != nil {
+			return nil
+		}
+		s.deliver(ctx, &messages[i])
+	}
+
+	return nil
+}
+
+// deliver sends a single message and records the outcome
+func (s *Service) deliver(ctx context.Context, msg *models.EmailMessage) {
+	// The address may have bounced since the message was queued
+	suppressed, err := models.IsEmailSuppressed(s.db, msg.To)
+	if err != nil {
+		log.Printf("Failed to check suppression for email %d: %v", msg.ID, err)
+		return
+	}
+	if suppressed {
+		if err := msg.MarkSuppressed(s.db); err != nil {
+			log.Printf("Failed to mark email %d as suppressed: %v", msg.ID, err)
+		}
+		return
+	}
+
+	provider, ok := s.providers[msg.Provider]
+	if !ok {
+		s.recordFailure(msg, &PermanentError{Err: fmt.Errorf("unknown email provider %q", msg.Provider)})
+		return
+	}
+
+	limiter := s.limiters[msg.Provider]
+	limiter.SetRate(config.Current().EmailRateFor(msg.Provider))
+	if err := limiter.Wait(ctx); err != nil {
+		return
+	}
+
+	// Waiting for the rate limit may outlast the batch's claim; renew it for
+	// this message, and leave the message alone if another worker took it
+	renewed, err := msg.RenewLease(s.db, claimLease)
+	if err != nil {
+		log.Printf("Failed to renew lease of email %d: %v", msg.ID, err)
+		return
+	}
+	if !renewed {
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	providerMessageID, err := provider.Send(sendCtx, msg)
+	if err != nil {
+		s.recordFailure(msg, err)
+		return
+	}
+
+	if err := msg.MarkSent(s.db, providerMessageID); err != nil {
+		log.Printf("Failed to mark email %d as sent: %v", msg.ID, err)
+	}
+}
+
+// recordFailure schedules a retry with exponential backoff, or gives up
+// when the error is permanent or the attempt budget is spent
+func (s *Service) recordFailure(msg *models.EmailMessage, sendErr error) {
+	if IsPermanent(sendErr) || msg.Attempts+1 >= config.Current().EmailMaxAttempts {
+		log.Printf("Email %d to %s failed permanently: %v", msg.ID, msg.To, sendErr)
+		if err := msg.MarkFailed(s.db, sendErr); err != nil {
+			log.Printf("Failed to mark email %d as failed: %v", msg.ID, err)
+		}
+		return
+	}
+
+	next := time.Now().Add(s.backoff(msg.Attempts))
+	log.Printf("Email %d to %s failed (attempt %d), retrying at %s: %v", msg.ID, msg.To, msg.Attempts+1, next.Format(time.RFC3339), sendErr)
+	if err := msg.MarkRetry(s.db, sendErr, next); err != nil {
+		log.Printf("Failed to schedule retry for email %d: %v", msg.ID, err)
+	}
+}
+
+// backoff returns the delay before the retry following the given number of prior attempts
+func (s *Service) backoff(attempts int) time.Duration {
+	d := time.Duration(float64(s.baseBackoff) * math.Pow(2, float64(attempts)))
+	if d > maxBackoff || d <= 0 {
+		return maxBackoff
+	}
+	return d
+}
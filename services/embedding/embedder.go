@@ -0,0 +1,170 @@
+// Package embedding turns translated report text into vectors so similar
+// sessions can be found with pgvector.
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+)
+
+// Embedder computes one vector per input text. Vectors from different models
+// are not comparable, so each embedder names its model.
+type Embedder interface {
+	Model() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+var defaultEmbedder atomic.Pointer[Embedder]
+
+// SetDefault installs the process-wide embedder. Call it at startup.
+func SetDefault(e Embedder) {
+	defaultEmbedder.Store(&e)
+}
+
+// Default returns the process-wide embedder, the built-in hashing embedder
+// unless another one was installed
+func Default() Embedder {
+	if e := defaultEmbedder.Load(); e != nil {
+		return *e
+	}
+	return NewHashEmbedder(DefaultHashDimensions)
+}
+
+// DefaultHashDimensions is the vector size of the default hashing embedder
+const DefaultHashDimensions = 256
+
+// HashEmbedder embeds text locally by hashing its words and word pairs into a
+// fixed number of dimensions. It needs no model service and finds sessions
+// with shared wording; use an HTTPEmbedder for meaning-based similarity.
+type HashEmbedder struct {
+	dimensions int
+}
+
+// NewHashEmbedder creates a hashing embedder producing vectors of the given size
+func NewHashEmbedder(dimensions int) *HashEmbedder {
+	return &HashEmbedder{dimensions: dimensions}
+}
+
+// Model names the embedder and its size
+func (h *HashEmbedder) Model() string {
+	return fmt.Sprintf("hash-%d", h.dimensions)
+}
+
+// Embed returns an L2-normalized vector per text
+func (h *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, h.dimensions)
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+		})
+		for j, word := range words {
+			h.add(vector, word, 1)
+			if j > 0 {
+				h.add(vector, words[j-1]+" "+word, 0.5)
+			}
+		}
+		vectors[i] = normalize(vector)
+	}
+	return vectors, nil
+}
+
+// add hashes a feature to a dimension and sign, so collisions tend to cancel out
+func (h *HashEmbedder) add(vector []float32, feature string, weight float32) {
+	hasher := fnv.New64a()
+	hasher.Write([]byte(feature))
+	sum := hasher.Sum64()
+	if sum&1 == 1 {
+		weight = -weight
+	}
+	vector[(sum>>1)%uint64(len(vector))] += weight
+}
+
+func normalize(vector []float32) []float32 {
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return vector
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range vector {
+		vector[i] *= scale
+	}
+	return vector
+}
+
+// HTTPEmbedder calls an OpenAI-compatible embeddings endpoint
+type HTTPEmbedder struct {
+	url    string
+	model  string
+	apiKey string
+	client *http.Client
+}
+
+// NewHTTPEmbedder creates an embedder posting to url, e.g.
+// https://api.openai.com/v1/embeddings or a self-hosted equivalent
+func NewHTTPEmbedder(url, model, apiKey string) *HTTPEmbedder {
+	return &HTTPEmbedder{url: url, model: model, apiKey: apiKey, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Model returns the remote model name
+func (h *HTTPEmbedder) Model() string {
+	return h.model
+}
+
+// Embed requests vectors for all texts in one call
+func (h *HTTPEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]interface{}{"model": h.model, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.apiKey)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embedding service returned %s", resp.Status)
+	}
+
+	var decoded struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("invalid embedding response: %w", err)
+	}
+	if len(decoded.Data) != len(texts) {
+		return nil, fmt.Errorf("embedding service returned %d vectors for %d texts", len(decoded.Data), len(texts))
+	}
+	vectors := make([][]float32, len(texts))
+	for _, item := range decoded.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding service returned an out-of-range index %d", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, nil
+}
@@ -0,0 +1,97 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/encryption"
+	"gorm.io/gorm"
+)
+
+// Backoff between attempts to embed text that failed to embed
+const (
+	retryBase = time.Minute
+	retryMax  = 24 * time.Hour
+)
+
+// IndexReports embeds up to limit reports whose translated text has no
+// embedding from the embedder's model yet, or changed since it was embedded,
+// and returns how many were stored. Encrypted report text is never embedded.
+// Reports whose text fails to embed are retried with backoff rather than
+// holding up the others.
+func IndexReports(ctx context.Context, db *gorm.DB, embedder Embedder, limit int) (int, error) {
+	reports, err := models.FindReportsToEmbed(db.WithContext(ctx), embedder.Model(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var texts []string
+	var pending []models.Report
+	for _, report := range reports {
+		// Guard against text encrypted before the user turned encryption off
+		if encryption.IsEncrypted(report.Description) {
+			continue
+		}
+		texts = append(texts, report.Description)
+		pending = append(pending, report)
+	}
+	if len(texts) == 0 {
+		return 0, nil
+	}
+
+	vectors, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		// One text the embedder rejects fails the whole batch; embed the
+		// reports one by one to find it and index the rest
+		return indexEach(ctx, db, embedder, pending)
+	}
+	for i := range pending {
+		report := &pending[i]
+		if err := models.SetReportEmbedding(db, report, embedder.Model(), vectors[i]); err != nil {
+			return i, fmt.Errorf("report %d: %w", report.ID, err)
+		}
+	}
+	return len(pending), nil
+}
+
+// indexEach embeds each report on its own, recording the ones that fail so
+// they are retried later
+func indexEach(ctx context.Context, db *gorm.DB, embedder Embedder, reports []models.Report) (int, error) {
+	indexed := 0
+	for i := range reports {
+		if err := ctx.Err(); err != nil {
+			return indexed, err
+		}
+		report := &reports[i]
+		vectors, err := embedder.Embed(ctx, []string{report.Description})
+		if err == nil && len(vectors) != 1 {
+			err = fmt.Errorf("embedder returned %d vectors for 1 text", len(vectors))
+		}
+		if err != nil {
+			log.Printf("Failed to embed report %d: %v", report.ID, err)
+			if err := models.RecordReportEmbeddingFailure(db, report, embedder.Model(), err, retryBackoff); err != nil {
+				return indexed, fmt.Errorf("report %d: %w", report.ID, err)
+			}
+			continue
+		}
+		if err := models.SetReportEmbedding(db, report, embedder.Model(), vectors[0]); err != nil {
+			return indexed, fmt.Errorf("report %d: %w", report.ID, err)
+		}
+		indexed++
+	}
+	return indexed, nil
+}
+
+// retryBackoff returns the delay before the attempt following the given
+// number of failed ones
+func retryBackoff(attempts int) time.Duration {
+	d := time.Duration(float64(retryBase) * math.Pow(2, float64(attempts-1)))
+	if d > retryMax || d <= 0 {
+		return retryMax
+	}
+	return d
+}
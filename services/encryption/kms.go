@@ -0,0 +1,39 @@
+package encryption
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// KMS derives data keys from a master key that never leaves the key service.
+// The same keyID always yields the same key.
+type KMS interface {
+	DeriveKey(ctx context.Context, keyID string) ([]byte, error)
+}
+
+// localKMS derives keys with HMAC-SHA256 from a master key held in the process
+// environment, for deployments without a managed key service
+type localKMS struct {
+	master []byte
+}
+
+// NewLocalKMS creates a KMS from a base64-encoded master key of at least 32 bytes
+func NewLocalKMS(encodedMaster string) (KMS, error) {
+	master, err := base64.StdEncoding.DecodeString(encodedMaster)
+	if err != nil {
+		return nil, fmt.Errorf("master key must be base64: %w", err)
+	}
+	if len(master) < 32 {
+		return nil, fmt.Errorf("master key must be at least 32 bytes, got %d", len(master))
+	}
+	return &localKMS{master: master}, nil
+}
+
+func (k *localKMS) DeriveKey(ctx context.Context, keyID string) ([]byte, error) {
+	mac := hmac.New(sha256.New, k.master)
+	mac.Write([]byte("thinkink/report-text/" + keyID))
+	return mac.Sum(nil), nil
+}
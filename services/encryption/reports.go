@@ -0,0 +1,147 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"gorm.io/gorm"
+)
+
+// SealReportText encrypts report text for storage if the user has opted in to
+// report text encryption, and returns it unchanged otherwise
+func SealReportText(ctx context.Context, db *gorm.DB, userID uint, text string) (string, error) {
+	if text == "" {
+		return text, nil
+	}
+	var user models.User
+	if err := db.Select("id", "encrypt_report_text").First(&user, userID).Error; err != nil {
+		return "", fmt.Errorf("database error: %w", err)
+	}
+	if !user.EncryptReportText {
+		return text, nil
+	}
+	return Encrypt(ctx, userID, text)
+}
+
+// SealTranslation encrypts a processed translation and the raw ML output it
+// came from, like SealReportText
+func SealTranslation(ctx context.Context, db *gorm.DB, userID uint, description, raw string) (string, string, error) {
+	sealed, err := SealReportText(ctx, db, userID, description)
+	if err != nil {
+		return "", "", err
+	}
+	sealedRaw, err := SealReportText(ctx, db, userID, raw)
+	if err != nil {
+		return "", "", err
+	}
+	return sealed, sealedRaw, nil
+}
+
+// OpenReport decrypts the report text in place for serving it
+func OpenReport(ctx context.Context, report *models.Report) error {
+	description, err := Decrypt(ctx, report.UserID, report.Description)
+	if err != nil {
+		return fmt.Errorf("report %d: %w", report.ID, err)
+	}
+	correction, err := Decrypt(ctx, report.UserID, report.Correction)
+	if err != nil {
+		return fmt.Errorf("report %d: %w", report.ID, err)
+	}
+	raw, err := Decrypt(ctx, report.UserID, report.RawDescription)
+	if err != nil {
+		return fmt.Errorf("report %d: %w", report.ID, err)
+	}
+	report.Description = description
+	report.Correction = correction
+	report.RawDescription = raw
+	return nil
+}
+
+// OpenReports decrypts the reports' text in place for serving them
+func OpenReports(ctx context.Context, reports []models.Report) error {
+	for i := range reports {
+		if err := OpenReport(ctx, &reports[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetReportTextEncryption turns report text encryption on or off for a user,
+// encrypting or decrypting the text of their existing reports, including
+// deleted ones awaiting purge, to match
+func SetReportTextEncryption(ctx context.Context, db *gorm.DB, user *models.User, enabled bool) error {
+	if enabled && !Enabled() {
+		return ErrNotConfigured
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var reports []models.Report
+		if err := tx.Unscoped().Select("id", "user_id", "description", "raw_description", "correction").Where("user_id = ?", user.ID).Find(&reports).Error; err != nil {
+			return err
+		}
+
+		for _, report := range reports {
+			description, err := convertText(ctx, user.ID, report.Description, enabled)
+			if err != nil {
+				return fmt.Errorf("report %d: %w", report.ID, err)
+			}
+			raw, err := convertText(ctx, user.ID, report.RawDescription, enabled)
+			if err != nil {
+				return fmt.Errorf("report %d: %w", report.ID, err)
+			}
+			correction, err := convertText(ctx, user.ID, report.Correction, enabled)
+			if err != nil {
+				return fmt.Errorf("report %d: %w", report.ID, err)
+			}
+			if description == report.Description && raw == report.RawDescription && correction == report.Correction {
+				continue
+			}
+			err = tx.Unscoped().Model(&models.Report{}).Where("id = ?", report.ID).
+				Updates(map[string]interface{}{"description": description, "raw_description": raw, "correction": correction}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		// Earlier versions in the revision history hold report text too
+		var revisions []models.ReportRevision
+		err := tx.Select("report_revisions.id", "report_revisions.description").
+			Joins("JOIN reports ON reports.id = report_revisions.report_id").
+			Where("reports.user_id = ?", user.ID).Find(&revisions).Error
+		if err != nil {
+			return err
+		}
+		for _, revision := range revisions {
+			description, err := convertText(ctx, user.ID, revision.Description, enabled)
+			if err != nil {
+				return fmt.Errorf("report revision %d: %w", revision.ID, err)
+			}
+			if description == revision.Description {
+				continue
+			}
+			if err := tx.Model(&models.ReportRevision{}).Where("id = ?", revision.ID).Update("description", description).Error; err != nil {
+				return err
+			}
+		}
+
+		// Embeddings are derived from the plaintext, so they go with it
+		if enabled {
+			if err := models.DeleteUserEmbeddings(tx, user.ID); err != nil {
+				return err
+			}
+		}
+
+		user.EncryptReportText = enabled
+		return tx.Model(user).Update("encrypt_report_text", enabled).Error
+	})
+}
+
+// convertText encrypts or decrypts a stored value
+func convertText(ctx context.Context, userID uint, value string, encrypt bool) (string, error) {
+	if encrypt {
+		return Encrypt(ctx, userID, value)
+	}
+	return Decrypt(ctx, userID, value)
+}
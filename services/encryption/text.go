@@ -0,0 +1,117 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// prefix marks an encrypted value so plaintext written before encryption was
+// enabled can still be told apart
+const prefix = "enc:v1:"
+
+// ErrNotConfigured is returned when report text encryption is requested but no KMS is configured
+var ErrNotConfigured = errors.New("report text encryption is not configured")
+
+var (
+	kms  atomic.Pointer[KMS]
+	keys sync.Map // user ID -> derived AES key
+)
+
+// Configure installs the KMS used to derive per-user keys. Call it at startup.
+func Configure(k KMS) {
+	kms.Store(&k)
+	keys.Clear()
+}
+
+// Enabled reports whether a KMS is configured
+func Enabled() bool {
+	return kms.Load() != nil
+}
+
+// IsEncrypted reports whether value was produced by Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// userKey returns the user's data key, deriving it from the KMS on first use
+func userKey(ctx context.Context, userID uint) ([]byte, error) {
+	if key, ok := keys.Load(userID); ok {
+		return key.([]byte), nil
+	}
+	k := kms.Load()
+	if k == nil {
+		return nil, ErrNotConfigured
+	}
+	key, err := (*k).DeriveKey(ctx, "user/"+strconv.FormatUint(uint64(userID), 10))
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	keys.Store(userID, key)
+	return key, nil
+}
+
+// userCipher returns an AES-GCM cipher keyed for the user
+func userCipher(ctx context.Context, userID uint) (cipher.AEAD, error) {
+	key, err := userKey(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt seals plaintext with the user's key. Empty and already encrypted values are returned unchanged.
+func Encrypt(ctx context.Context, userID uint, plaintext string) (string, error) {
+	if plaintext == "" || IsEncrypted(plaintext) {
+		return plaintext, nil
+	}
+	gcm, err := userCipher(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	// The user ID is authenticated so ciphertext cannot be moved to another user's report
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), []byte(strconv.FormatUint(uint64(userID), 10)))
+	return prefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value sealed by Encrypt. Plaintext values are returned unchanged.
+func Decrypt(ctx context.Context, userID uint, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, prefix))
+	if err != nil {
+		return "", fmt.Errorf("malformed ciphertext: %w", err)
+	}
+	gcm, err := userCipher(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("malformed ciphertext")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, []byte(strconv.FormatUint(uint64(userID), 10)))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return string(plaintext), nil
+}
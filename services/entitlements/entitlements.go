@@ -0,0 +1,97 @@
+// Package entitlements maps a user's plan to what they may do: the quotas of
+// the plan and the capabilities it includes, such as exporting reports as PDF.
+// Handlers and the ML token validator consult it rather than checking whether
+// the user is subscribed.
+package entitlements
+
+import (
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/plans"
+	"gorm.io/gorm"
+)
+
+// CodeCapabilityUnavailable is the code of errors for capabilities the
+// user's plan lacks, stable so clients can branch on it
+const CodeCapabilityUnavailable = "capability_unavailable"
+
+// Entitlements are what a user's plan allows. Quotas of -1 are unlimited.
+type Entitlements struct {
+	Plan                 string `json:"plan" example:"free"`
+	Source               string `json:"source" example:"plan"`
+	UploadsPerMonth      int    `json:"uploads_per_month" example:"20"`
+	TranslationsPerMonth int    `json:"translations_per_month" example:"20"`
+	StorageBytes         int64  `json:"storage_bytes" example:"524288000"`
+	MaxFileBytes         int64  `json:"max_file_bytes" example:"20971520"`
+	// Capabilities included in the plan
+	Capabilities []string `json:"capabilities" example:"pdf_export,sharing"`
+}
+
+// Resolve returns the user's current entitlements
+func Resolve(db *gorm.DB, user *models.User) (Entitlements, error) {
+	limits, err := plans.ResolveLimits(db, user)
+	if err != nil {
+		return Entitlements{}, err
+	}
+	return fromLimits(limits), nil
+}
+
+// ForUser returns the user's entitlements by ID, reusing their briefly cached
+// plan limits
+func ForUser(db *gorm.DB, userID uint) (Entitlements, error) {
+	limits, err := plans.LimitsForUser(db, userID)
+	if err != nil {
+		return Entitlements{}, err
+	}
+	return fromLimits(limits), nil
+}
+
+// fromLimits adds the capabilities of the plan the limits are based on. Users
+// on the free plan are the only ones without a subscription or seat.
+func fromLimits(limits plans.Limits) Entitlements {
+	capabilities := config.Current().CapabilitiesFor(limits.Plan, limits.Plan != config.PlanFree)
+	return Entitlements{
+		Plan:                 limits.Plan,
+		Source:               limits.Source,
+		UploadsPerMonth:      limits.UploadsPerMonth,
+		TranslationsPerMonth: limits.TranslationsPerMonth,
+		StorageBytes:         limits.StorageBytes,
+		MaxFileBytes:         limits.MaxFileBytes,
+		Capabilities:         append([]string{}, capabilities...),
+	}
+}
+
+// Allows reports whether the plan includes a capability
+func (e Entitlements) Allows(capability string) bool {
+	for _, c := range e.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// CapabilityError is the body of 402 responses to actions the user's plan
+// does not include, with where to upgrade when a plan change would allow it
+type CapabilityError struct {
+	Error      string `json:"error" example:"Your plan does not include pdf_export"`
+	Code       string `json:"code" example:"capability_unavailable"`
+	Capability string `json:"capability" example:"pdf_export"`
+	Plan       string `json:"plan" example:"free"`
+	// Where the user can change plans; omitted when an organization sets the plan
+	UpgradeURL string `json:"upgrade_url,omitempty" example:"https://app.thinkink.io/billing"`
+}
+
+// Unavailable describes an action refused because the plan lacks a capability
+func (e Entitlements) Unavailable(capability string) CapabilityError {
+	err := CapabilityError{
+		Error:      "Your plan does not include " + capability,
+		Code:       CodeCapabilityUnavailable,
+		Capability: capability,
+		Plan:       e.Plan,
+	}
+	if e.Source == "plan" {
+		err.UpgradeURL = config.Current().UpgradeURL
+	}
+	return err
+}
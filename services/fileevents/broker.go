@@ -0,0 +1,134 @@
+// Package fileevents fans out processing progress of uploaded files to the
+// event streams of connected users. Progress is announced over Postgres
+// NOTIFY, so events published by external workers reach every API process.
+package fileevents
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+)
+
+// subscriberBuffer is how many events a slow stream may fall behind before
+// further events to it are dropped
+const subscriberBuffer = 32
+
+// Broker delivers events announced on models.FileEventChannel to the
+// subscribers of the file's owner
+type Broker struct {
+	db *gorm.DB
+
+	mu          sync.Mutex
+	subscribers map[uint]map[chan models.FileEvent]struct{}
+}
+
+// NewBroker creates a broker; events are only delivered while Listen runs
+func NewBroker(db *gorm.DB) *Broker {
+	return &Broker{db: db, subscribers: make(map[uint]map[chan models.FileEvent]struct{})}
+}
+
+var defaultBroker atomic.Pointer[Broker]
+
+// SetDefault installs the process-wide broker. Call it at startup.
+func SetDefault(b *Broker) {
+	defaultBroker.Store(b)
+}
+
+// Default returns the process-wide broker, or nil when events are not streamed
+func Default() *Broker {
+	return defaultBroker.Load()
+}
+
+// Subscribe returns a channel receiving the user's file events and a function
+// that must be called to stop receiving them
+func (b *Broker) Subscribe(userID uint) (<-chan models.FileEvent, func()) {
+	ch := make(chan models.FileEvent, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[chan models.FileEvent]struct{})
+	}
+	b.subscribers[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subscribers[userID], ch)
+		if len(b.subscribers[userID]) == 0 {
+			delete(b.subscribers, userID)
+		}
+		b.mu.Unlock()
+	}
+}
+
+// Publish delivers an event to the owner's subscribers. A subscriber whose
+// buffer is full misses the event rather than holding up the others.
+func (b *Broker) Publish(event models.FileEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[event.UserID] {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("Dropping event for file %d: subscriber of user %d is behind", event.FileID, event.UserID)
+		}
+	}
+}
+
+// Listen delivers events announced on models.FileEventChannel, reconnecting
+// after errors until ctx is cancelled
+func (b *Broker) Listen(ctx context.Context) {
+	for ctx.Err() == nil {
+		if err := b.listen(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("File event listener stopped: %v; reconnecting", err)
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// listen holds a dedicated connection in LISTEN mode until it fails
+func (b *Broker) listen(ctx context.Context) error {
+	sqlDB, err := b.db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		pgConn := driverConn.(*stdlib.Conn).Conn()
+		if _, err := pgConn.Exec(ctx, "LISTEN "+pgx.Identifier{models.FileEventChannel}.Sanitize()); err != nil {
+			return err
+		}
+
+		for {
+			notification, err := pgConn.WaitForNotification(ctx)
+			if err != nil {
+				// The connection is left listening; discard it rather than return it to the pool
+				return fmt.Errorf("%w: %v", driver.ErrBadConn, err)
+			}
+			event, err := models.ParseFileEvent(notification.Payload)
+			if err != nil {
+				log.Printf("Ignoring file event notification: %v", err)
+				continue
+			}
+			b.Publish(event)
+		}
+	})
+}
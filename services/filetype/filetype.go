@@ -0,0 +1,159 @@
+// Package filetype recognizes the format of an uploaded file from its first
+// bytes, so uploads are judged by their content rather than by the name or
+// media type the client claims.
+package filetype
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
+)
+
+// SniffLen is how many leading bytes Sniff looks at
+const SniffLen = 512
+
+// Leading bytes of executables and scripts, which are never accepted
+var executableMagic = []struct {
+	name  string
+	magic []byte
+}{
+	{"an ELF executable", []byte("\x7fELF")},
+	{"a Windows executable", []byte("MZ")},
+	{"a Mach-O executable", []byte{0xfe, 0xed, 0xfa, 0xce}},
+	{"a Mach-O executable", []byte{0xfe, 0xed, 0xfa, 0xcf}},
+	{"a Mach-O executable", []byte{0xce, 0xfa, 0xed, 0xfe}},
+	{"a Mach-O executable", []byte{0xcf, 0xfa, 0xed, 0xfe}},
+	{"a Mach-O executable", []byte{0xca, 0xfe, 0xba, 0xbe}},
+	{"a WebAssembly module", []byte("\x00asm")},
+	{"a script", []byte("#!")},
+}
+
+// Formats claimed by file extensions
+var extensionFormats = map[string]string{
+	".json": config.UploadFormatJSON,
+	".edf":  config.UploadFormatEDF,
+	".bdf":  config.UploadFormatBDF,
+	".csv":  config.UploadFormatCSV,
+	".tsv":  config.UploadFormatCSV,
+}
+
+// Formats claimed by media types. Generic types such as
+// application/octet-stream and text/plain claim no format.
+var mediaTypeFormats = map[string]string{
+	"application/json":          config.UploadFormatJSON,
+	"text/json":                 config.UploadFormatJSON,
+	"application/edf":           config.UploadFormatEDF,
+	"text/csv":                  config.UploadFormatCSV,
+	"application/csv":           config.UploadFormatCSV,
+	"text/tab-separated-values": config.UploadFormatCSV,
+}
+
+// Sniff returns the format of a file starting with head, or "" when it is
+// not one of the upload formats
+func Sniff(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte("0       ")):
+		return config.UploadFormatEDF
+	case bytes.HasPrefix(head, []byte("\xffBIOSEMI")):
+		return config.UploadFormatBDF
+	}
+
+	text := bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))
+	if !isText(text) {
+		return ""
+	}
+	trimmed := bytes.TrimLeft(text, " \t\r\n")
+	switch {
+	case len(trimmed) == 0:
+		return ""
+	case trimmed[0] == '{' || trimmed[0] == '[':
+		return config.UploadFormatJSON
+	case bytes.ContainsAny(trimmed, ",;\t"):
+		return config.UploadFormatCSV
+	}
+	return ""
+}
+
+// isText reports whether head looks like UTF-8 text. The last few bytes may
+// be a character cut off by the end of head.
+func isText(head []byte) bool {
+	if bytes.IndexByte(head, 0) >= 0 {
+		return false
+	}
+	for len(head) > 0 {
+		r, size := utf8.DecodeRune(head)
+		if r == utf8.RuneError && size == 1 {
+			return len(head) < utf8.UTFMax && !utf8.FullRune(head)
+		}
+		head = head[size:]
+	}
+	return true
+}
+
+// executable returns what kind of executable head starts, or ""
+func executable(head []byte) string {
+	for _, e := range executableMagic {
+		if bytes.HasPrefix(head, e.magic) {
+			return e.name
+		}
+	}
+	return ""
+}
+
+// Check returns the format of an upload starting with head, or an error
+// when it is an executable, is not one of the allowed formats, or its
+// content does not match the format its filename or media type claims
+func Check(head []byte, filename, mediaType string, settings *config.Settings) (string, error) {
+	if kind := executable(head); kind != "" {
+		return "", fmt.Errorf("executable files are not accepted (found %s)", kind)
+	}
+
+	format := Sniff(head)
+	if format == "" {
+		return "", fmt.Errorf("unrecognized file content; accepted formats: %s", strings.Join(settings.UploadFormats, ", "))
+	}
+	if !settings.UploadFormatAllowed(format) {
+		return "", fmt.Errorf("%s files are not accepted; accepted formats: %s", strings.ToUpper(format), strings.Join(settings.UploadFormats, ", "))
+	}
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	if claimed, ok := extensionFormats[ext]; ok && claimed != format {
+		return "", fmt.Errorf("file content is %s but its extension is %s", strings.ToUpper(format), ext)
+	}
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		if claimed, ok := mediaTypeFormats[parsed]; ok && claimed != format {
+			return "", fmt.Errorf("file content is %s but it was sent as %s", strings.ToUpper(format), parsed)
+		}
+	}
+	return format, nil
+}
+
+// Zip archives start with a local file header, or an end of central
+// directory record when they are empty
+var zipMagic = [][]byte{[]byte("PK\x03\x04"), []byte("PK\x05\x06")}
+
+// CheckArchive returns an error unless an upload starting with head is a zip
+// archive whose filename and media type do not claim a recording format
+func CheckArchive(head []byte, filename, mediaType string) error {
+	isZip := false
+	for _, magic := range zipMagic {
+		isZip = isZip || bytes.HasPrefix(head, magic)
+	}
+	if !isZip {
+		return fmt.Errorf("file content is not a zip archive")
+	}
+	if ext := strings.ToLower(filepath.Ext(filename)); ext != "" && ext != ".zip" {
+		return fmt.Errorf("file content is a zip archive but its extension is %s", ext)
+	}
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		if _, claimed := mediaTypeFormats[parsed]; claimed {
+			return fmt.Errorf("file content is a zip archive but it was sent as %s", parsed)
+		}
+	}
+	return nil
+}
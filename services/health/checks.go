@@ -0,0 +1,32 @@
+package health
+
+import (
+	"context"
+	"net"
+
+	"gorm.io/gorm"
+)
+
+// DatabaseCheck pings the database connection pool
+func DatabaseCheck(db *gorm.DB) Check {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// TCPCheck verifies that a TCP connection can be opened. address is resolved
+// on every probe so reloaded settings are picked up.
+func TCPCheck(address func() string) Check {
+	return func(ctx context.Context) error {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", address())
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
@@ -0,0 +1,333 @@
+package health
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Component statuses, ordered from best to worst
+const (
+	StatusOperational = "operational"
+	StatusDegraded    = "degraded"
+	StatusOutage      = "outage"
+	StatusUnknown     = "unknown"
+)
+
+// Names of the components the server registers
+const (
+	ComponentAPI       = "api"
+	ComponentDatabase  = "database"
+	ComponentMLService = "ml_service"
+	ComponentPayments  = "payments"
+)
+
+// Readiness levels, from best to worst
+const (
+	ReadinessReady    = "ready"
+	ReadinessDegraded = "degraded"
+	ReadinessNotReady = "not_ready"
+)
+
+// Check probes one component and returns an error if it is unavailable
+type Check func(ctx context.Context) error
+
+// sample is the outcome of a single probe
+type sample struct {
+	at     time.Time
+	status string
+}
+
+// Bucket aggregates the probes in one time window
+type Bucket struct {
+	Start         time.Time `json:"start"`
+	Status        string    `json:"status" example:"operational"`
+	UptimePercent *float64  `json:"uptime_percent,omitempty" example:"100"`
+}
+
+// ComponentStatus is the public view of one component's health
+type ComponentStatus struct {
+	Name          string   `json:"name" example:"database"`
+	Status        string   `json:"status" example:"operational"`
+	UptimePercent *float64 `json:"uptime_percent,omitempty" example:"99.95"`
+	History       []Bucket `json:"history"`
+}
+
+// Summary is the aggregated health of all components. It deliberately carries
+// no error messages, addresses or latencies.
+type Summary struct {
+	Status     string            `json:"status" example:"operational"`
+	UpdatedAt  *time.Time        `json:"updated_at,omitempty"`
+	Components []ComponentStatus `json:"components"`
+}
+
+// Readiness tells load balancers whether the instance can serve traffic. A
+// critical component being down makes it not ready; optional components being
+// down or slow only degrade it, and the features depending on them fail fast.
+type Readiness struct {
+	Status string `json:"status" example:"degraded"`
+	// Critical components that are down
+	Failing []string `json:"failing,omitempty" example:"database"`
+	// Optional components that are down or slow
+	Degraded []string `json:"degraded,omitempty" example:"ml_service"`
+	// When the optional components were last probed
+	ProbedAt *time.Time `json:"probed_at,omitempty"`
+}
+
+// Prober periodically runs component checks and keeps a bounded history of the results
+type Prober struct {
+	mu        sync.RWMutex
+	names     []string
+	checks    map[string]Check
+	critical  map[string]bool
+	history   map[string][]sample
+	updatedAt *time.Time
+
+	// Interval is how often Probe runs; it tells clients when a component's
+	// status may next change
+	Interval time.Duration
+	// Retention is how much history is kept and reported
+	Retention time.Duration
+	// BucketSize is the width of each history bucket
+	BucketSize time.Duration
+	// Timeout bounds each check
+	Timeout time.Duration
+	// SlowThreshold marks a component degraded when its check succeeds but takes longer than this
+	SlowThreshold time.Duration
+}
+
+// NewProber creates a prober keeping 24 hours of history in hourly buckets
+func NewProber() *Prober {
+	return &Prober{
+		checks:        make(map[string]Check),
+		critical:      make(map[string]bool),
+		history:       make(map[string][]sample),
+		Retention:     24 * time.Hour,
+		BucketSize:    time.Hour,
+		Interval:      time.Minute,
+		Timeout:       5 * time.Second,
+		SlowThreshold: 2 * time.Second,
+	}
+}
+
+var defaultProber = NewProber()
+
+// Default returns the process-wide prober
+func Default() *Prober {
+	return defaultProber
+}
+
+// Register adds a component check. Components are reported in registration order.
+func (p *Prober) Register(name string, check Check) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.checks[name]; !ok {
+		p.names = append(p.names, name)
+	}
+	p.checks[name] = check
+}
+
+// RegisterCritical adds a check for a component the instance cannot serve
+// traffic without
+func (p *Prober) RegisterCritical(name string, check Check) {
+	p.Register(name, check)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.critical[name] = true
+}
+
+// Status returns the latest probed status of a component, or StatusUnknown
+// before its first probe
+func (p *Prober) Status(name string) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	samples := p.history[name]
+	if len(samples) == 0 {
+		return StatusUnknown
+	}
+	return samples[len(samples)-1].status
+}
+
+// Readiness checks the critical components live and reports the optional ones
+// from their latest probe
+func (p *Prober) Readiness(ctx context.Context) Readiness {
+	p.mu.RLock()
+	names := append([]string(nil), p.names...)
+	criticalChecks := make(map[string]Check)
+	for name := range p.critical {
+		criticalChecks[name] = p.checks[name]
+	}
+	readiness := Readiness{Status: ReadinessReady, ProbedAt: p.updatedAt}
+	p.mu.RUnlock()
+
+	for _, name := range names {
+		if check, ok := criticalChecks[name]; ok {
+			if p.run(ctx, check) == StatusOutage {
+				readiness.Failing = append(readiness.Failing, name)
+			}
+			continue
+		}
+		if status := p.Status(name); status == StatusOutage || status == StatusDegraded {
+			readiness.Degraded = append(readiness.Degraded, name)
+		}
+	}
+
+	switch {
+	case len(readiness.Failing) > 0:
+		readiness.Status = ReadinessNotReady
+	case len(readiness.Degraded) > 0:
+		readiness.Status = ReadinessDegraded
+	}
+	return readiness
+}
+
+// Probe runs all checks concurrently and records the results. It matches the
+// signature expected by jobs.RunPeriodic.
+func (p *Prober) Probe(ctx context.Context) error {
+	p.mu.RLock()
+	names := append([]string(nil), p.names...)
+	p.mu.RUnlock()
+
+	results := make([]string, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = p.run(ctx, check)
+		}(i, p.checks[name])
+	}
+	wg.Wait()
+
+	now := time.Now()
+	cutoff := now.Add(-p.Retention)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, name := range names {
+		samples := append(p.history[name], sample{at: now, status: results[i]})
+		drop := 0
+		for drop < len(samples) && samples[drop].at.Before(cutoff) {
+			drop++
+		}
+		p.history[name] = samples[drop:]
+	}
+	p.updatedAt = &now
+
+	return nil
+}
+
+// run executes one check and classifies the outcome
+func (p *Prober) run(ctx context.Context, check Check) string {
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := check(ctx); err != nil {
+		return StatusOutage
+	}
+	if time.Since(start) > p.SlowThreshold {
+		return StatusDegraded
+	}
+	return StatusOperational
+}
+
+// Summary aggregates the recorded history
+func (p *Prober) Summary() Summary {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	now := time.Now()
+	start := now.Add(-p.Retention).Truncate(p.BucketSize)
+
+	summary := Summary{
+		Status:     StatusOperational,
+		UpdatedAt:  p.updatedAt,
+		Components: make([]ComponentStatus, 0, len(p.names)),
+	}
+	if p.updatedAt == nil {
+		summary.Status = StatusUnknown
+	}
+
+	for _, name := range p.names {
+		samples := p.history[name]
+
+		component := ComponentStatus{
+			Name:          name,
+			Status:        StatusUnknown,
+			UptimePercent: uptime(samples),
+		}
+		if len(samples) > 0 {
+			component.Status = samples[len(samples)-1].status
+		}
+
+		for bucketStart := start; bucketStart.Before(now); bucketStart = bucketStart.Add(p.BucketSize) {
+			bucketEnd := bucketStart.Add(p.BucketSize)
+			var inBucket []sample
+			for _, s := range samples {
+				if !s.at.Before(bucketStart) && s.at.Before(bucketEnd) {
+					inBucket = append(inBucket, s)
+				}
+			}
+			component.History = append(component.History, Bucket{
+				Start:         bucketStart,
+				Status:        worst(inBucket),
+				UptimePercent: uptime(inBucket),
+			})
+		}
+
+		if severity(component.Status) > severity(summary.Status) {
+			summary.Status = component.Status
+		}
+		summary.Components = append(summary.Components, component)
+	}
+
+	return summary
+}
+
+// worst returns the most severe status among the samples
+func worst(samples []sample) string {
+	if len(samples) == 0 {
+		return StatusUnknown
+	}
+	status := StatusOperational
+	for _, s := range samples {
+		if severity(s.status) > severity(status) {
+			status = s.status
+		}
+	}
+	return status
+}
+
+// uptime returns the share of samples that were not outages, or nil without samples
+func uptime(samples []sample) *float64 {
+	if len(samples) == 0 {
+		return nil
+	}
+	up := 0
+	for _, s := range samples {
+		if s.status != StatusOutage {
+			up++
+		}
+	}
+	percent := math.Round(float64(up)/float64(len(samples))*10000) / 100
+	return &percent
+}
+
+func severity(status string) int {
+	switch status {
+	case StatusOperational:
+		return 0
+	case StatusDegraded:
+		return 1
+	case StatusUnknown:
+		return 2
+	default:
+		return 3
+	}
+}
@@ -0,0 +1,176 @@
+package iap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// App Store receipt validation endpoints
+const (
+	appleProductionURL = "https://buy.itunes.apple.com/verifyReceipt"
+	appleSandboxURL    = "https://sandbox.itunes.apple.com/verifyReceipt"
+)
+
+// Status codes of the verifyReceipt endpoint
+const (
+	appleStatusOK             = 0
+	appleStatusBadSecret      = 21004
+	appleStatusUnavailable    = 21005
+	appleStatusSandboxReceipt = 21007
+)
+
+// AppleVerifier validates auto-renewable subscription receipts with the App
+// Store's verifyReceipt endpoint
+type AppleVerifier struct {
+	bundleID     string
+	sharedSecret string
+	client       *http.Client
+}
+
+// NewAppleVerifier creates a verifier for the app with the given bundle ID,
+// using its App Store Connect shared secret
+func NewAppleVerifier(bundleID, sharedSecret string) *AppleVerifier {
+	return &AppleVerifier{bundleID: bundleID, sharedSecret: sharedSecret, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+type appleReceiptResponse struct {
+	Status      int    `json:"status"`
+	Environment string `json:"environment"`
+	Receipt     struct {
+		BundleID string `json:"bundle_id"`
+	} `json:"receipt"`
+	LatestReceiptInfo []struct {
+		ProductID             string `json:"product_id"`
+		OriginalTransactionID string `json:"original_transaction_id"`
+		ExpiresDateMs         string `json:"expires_date_ms"`
+		CancellationDateMs    string `json:"cancellation_date_ms"`
+		IsTrialPeriod         string `json:"is_trial_period"`
+	} `json:"latest_receipt_info"`
+	PendingRenewalInfo []struct {
+		OriginalTransactionID    string `json:"original_transaction_id"`
+		AutoRenewStatus          string `json:"auto_renew_status"`
+		IsInBillingRetryPeriod   string `json:"is_in_billing_retry_period"`
+		GracePeriodExpiresDateMs string `json:"grace_period_expires_date_ms"`
+	} `json:"pending_renewal_info"`
+}
+
+// Verify validates the receipt and returns the subscription to productID, or
+// when productID is empty the subscription that expires last. Receipts from
+// the sandbox, as used in App Review, are validated there.
+func (a *AppleVerifier) Verify(ctx context.Context, productID, receipt string) (*Subscription, error) {
+	resp, err := a.post(ctx, appleProductionURL, receipt)
+	if err == nil && resp.Status == appleStatusSandboxReceipt {
+		resp, err = a.post(ctx, appleSandboxURL, receipt)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.Status == appleStatusOK:
+	case resp.Status == appleStatusBadSecret:
+		return nil, fmt.Errorf("App Store rejected the shared secret")
+	case resp.Status == appleStatusUnavailable || (resp.Status >= 21100 && resp.Status <= 21199):
+		// Internal data access errors are worth retrying
+		return nil, fmt.Errorf("App Store is temporarily unavailable (status %d)", resp.Status)
+	default:
+		return nil, ErrInvalidReceipt
+	}
+	if resp.Receipt.BundleID != a.bundleID {
+		return nil, ErrInvalidReceipt
+	}
+
+	// The latest transaction of the subscription
+	latest := -1
+	var latestExpiry int64
+	for i, info := range resp.LatestReceiptInfo {
+		if productID != "" && info.ProductID != productID {
+			continue
+		}
+		expiry := parseMillis(info.ExpiresDateMs)
+		if latest < 0 || expiry > latestExpiry {
+			latest, latestExpiry = i, expiry
+		}
+	}
+	if latest < 0 {
+		return nil, ErrInvalidReceipt
+	}
+	info := resp.LatestReceiptInfo[latest]
+
+	sub := &Subscription{
+		Store:         AppStore,
+		ProductID:     info.ProductID,
+		TransactionID: info.OriginalTransactionID,
+		ExpiresAt:     time.UnixMilli(latestExpiry),
+		Sandbox:       resp.Environment == "Sandbox",
+	}
+	var billingRetry bool
+	for _, renewal := range resp.PendingRenewalInfo {
+		if renewal.OriginalTransactionID != info.OriginalTransactionID {
+			continue
+		}
+		sub.AutoRenew = renewal.AutoRenewStatus == "1"
+		billingRetry = renewal.IsInBillingRetryPeriod == "1"
+		if grace := parseMillis(renewal.GracePeriodExpiresDateMs); grace > latestExpiry {
+			sub.ExpiresAt = time.UnixMilli(grace)
+		}
+	}
+
+	now := time.Now()
+	switch {
+	case info.CancellationDateMs != "":
+		// Refunded by Apple
+		sub.Status = "canceled"
+	case sub.ExpiresAt.After(now) && info.IsTrialPeriod == "true":
+		sub.Status = "trialing"
+	case sub.ExpiresAt.After(now):
+		sub.Status = "active"
+	case billingRetry:
+		sub.Status = "past_due"
+	default:
+		sub.Status = "expired"
+	}
+	return sub, nil
+}
+
+func (a *AppleVerifier) post(ctx context.Context, url, receipt string) (*appleReceiptResponse, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"receipt-data":             receipt,
+		"password":                 a.sharedSecret,
+		"exclude-old-transactions": true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("App Store request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("App Store returned %s", resp.Status)
+	}
+
+	var decoded appleReceiptResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("invalid App Store response: %w", err)
+	}
+	return &decoded, nil
+}
+
+// parseMillis parses a Unix timestamp in milliseconds, or returns 0
+func parseMillis(s string) int64 {
+	ms, _ := strconv.ParseInt(s, 10, 64)
+	return ms
+}
@@ -0,0 +1,242 @@
+package iap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	googlePublisherURL   = "https://androidpublisher.googleapis.com/androidpublisher/v3/applications/"
+	googlePublisherScope = "https://www.googleapis.com/auth/androidpublisher"
+)
+
+// GoogleVerifier validates subscription purchase tokens with the Google Play
+// Developer API, authenticating as a service account with access to the app
+type GoogleVerifier struct {
+	packageName string
+	clientEmail string
+	tokenURI    string
+	key         interface{}
+	client      *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expires     time.Time
+}
+
+// NewGoogleVerifier creates a verifier for the app with the given package
+// name from a service account's JSON key
+func NewGoogleVerifier(packageName string, serviceAccountJSON []byte) (*GoogleVerifier, error) {
+	var account struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(serviceAccountJSON, &account); err != nil {
+		return nil, fmt.Errorf("invalid service account key: %w", err)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, fmt.Errorf("service account key lacks client_email or private_key")
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid service account private key: %w", err)
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	return &GoogleVerifier{
+		packageName: packageName,
+		clientEmail: account.ClientEmail,
+		tokenURI:    account.TokenURI,
+		key:         key,
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+type googleSubscriptionResponse struct {
+	SubscriptionState    string          `json:"subscriptionState"`
+	AcknowledgementState string          `json:"acknowledgementState"`
+	TestPurchase         json.RawMessage `json:"testPurchase"`
+	LineItems            []struct {
+		ProductID        string `json:"productId"`
+		ExpiryTime       string `json:"expiryTime"`
+		AutoRenewingPlan *struct {
+			AutoRenewEnabled bool `json:"autoRenewEnabled"`
+		} `json:"autoRenewingPlan"`
+	} `json:"lineItems"`
+}
+
+// Verify looks up the purchase token and acknowledges new purchases, which
+// Google Play otherwise refunds after three days
+func (g *GoogleVerifier) Verify(ctx context.Context, productID, receipt string) (*Subscription, error) {
+	if productID == "" {
+		return nil, ErrInvalidReceipt
+	}
+
+	endpoint := googlePublisherURL + url.PathEscape(g.packageName) + "/purchases/subscriptionsv2/tokens/" + url.PathEscape(receipt)
+	resp, err := g.do(ctx, http.MethodGet, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return nil, ErrInvalidReceipt
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("Google Play returned %s", resp.Status)
+	}
+
+	var decoded googleSubscriptionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("invalid Google Play response: %w", err)
+	}
+
+	sub := &Subscription{
+		Store:         GooglePlay,
+		ProductID:     productID,
+		TransactionID: receipt,
+		Sandbox:       len(decoded.TestPurchase) > 0 && string(decoded.TestPurchase) != "null",
+	}
+	found := false
+	for _, item := range decoded.LineItems {
+		if item.ProductID != productID {
+			continue
+		}
+		found = true
+		if expiry, err := time.Parse(time.RFC3339, item.ExpiryTime); err == nil {
+			sub.ExpiresAt = expiry
+		}
+		sub.AutoRenew = item.AutoRenewingPlan != nil && item.AutoRenewingPlan.AutoRenewEnabled
+	}
+	if !found {
+		return nil, ErrInvalidReceipt
+	}
+
+	switch decoded.SubscriptionState {
+	case "SUBSCRIPTION_STATE_ACTIVE", "SUBSCRIPTION_STATE_IN_GRACE_PERIOD":
+		// Google Play keeps access during its own grace period
+		sub.Status = "active"
+	case "SUBSCRIPTION_STATE_CANCELED":
+		// Not renewing, but paid until it expires
+		sub.Status = "active"
+		if !sub.ExpiresAt.After(time.Now()) {
+			sub.Status = "expired"
+		}
+	case "SUBSCRIPTION_STATE_ON_HOLD":
+		sub.Status = "past_due"
+	case "SUBSCRIPTION_STATE_PAUSED":
+		sub.Status = "paused"
+	case "SUBSCRIPTION_STATE_PENDING":
+		sub.Status = "incomplete"
+	default:
+		sub.Status = "expired"
+	}
+
+	if decoded.AcknowledgementState == "ACKNOWLEDGEMENT_STATE_PENDING" && sub.Status == "active" {
+		if err := g.acknowledge(ctx, productID, receipt); err != nil {
+			return nil, err
+		}
+	}
+	return sub, nil
+}
+
+// acknowledge confirms a purchase to Google Play
+func (g *GoogleVerifier) acknowledge(ctx context.Context, productID, receipt string) error {
+	endpoint := googlePublisherURL + url.PathEscape(g.packageName) + "/purchases/subscriptions/" + url.PathEscape(productID) + "/tokens/" + url.PathEscape(receipt) + ":acknowledge"
+	resp, err := g.do(ctx, http.MethodPost, endpoint)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("Google Play acknowledgement returned %s", resp.Status)
+	}
+	return nil
+}
+
+// do sends an authenticated request to the Google Play Developer API
+func (g *GoogleVerifier) do(ctx context.Context, method, endpoint string) (*http.Response, error) {
+	token, err := g.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var body io.Reader
+	if method == http.MethodPost {
+		body = strings.NewReader("{}")
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Google Play request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// token returns an OAuth access token for the service account, exchanging a
+// signed assertion for a new one shortly before the cached one expires
+func (g *GoogleVerifier) token(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.accessToken != "" && time.Now().Before(g.expires) {
+		return g.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   g.clientEmail,
+		"scope": googlePublisherScope,
+		"aud":   g.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(g.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign service account assertion: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("Google token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Google token endpoint returned %s", resp.Status)
+	}
+
+	var decoded struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return "", fmt.Errorf("invalid Google token response: %w", err)
+	}
+	g.accessToken = decoded.AccessToken
+	g.expires = now.Add(time.Duration(decoded.ExpiresIn)*time.Second - time.Minute)
+	return g.accessToken, nil
+}
@@ -0,0 +1,111 @@
+package ingest
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultDuration seeds the ETA estimate until real translations have been timed
+const defaultDuration = 10 * time.Second
+
+// Pool bounds how many translations run at once and exposes live queue metrics
+// so callers can apply backpressure before accepting more work.
+type Pool struct {
+	slots   chan struct{}
+	waiting atomic.Int64
+	running atomic.Int64
+
+	mu  sync.Mutex
+	avg time.Duration
+}
+
+// NewPool creates a pool running at most workers translations concurrently
+func NewPool(workers int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Pool{slots: make(chan struct{}, workers), avg: defaultDuration}
+}
+
+// DefaultWorkers is the concurrency of the default pool
+const DefaultWorkers = 4
+
+var defaultPool atomic.Pointer[Pool]
+
+// SetDefault installs the process-wide translation pool. Call it at startup.
+func SetDefault(p *Pool) {
+	defaultPool.Store(p)
+}
+
+// Default returns the process-wide translation pool
+func Default() *Pool {
+	if p := defaultPool.Load(); p != nil {
+		return p
+	}
+	defaultPool.CompareAndSwap(nil, NewPool(DefaultWorkers))
+	return defaultPool.Load()
+}
+
+// Workers returns the concurrency limit
+func (p *Pool) Workers() int {
+	return cap(p.slots)
+}
+
+// Depth returns the number of translations waiting for a worker
+func (p *Pool) Depth() int {
+	return int(p.waiting.Load())
+}
+
+// Running returns the number of translations in progress
+func (p *Pool) Running() int {
+	return int(p.running.Load())
+}
+
+// AverageDuration returns the moving average time a translation takes
+func (p *Pool) AverageDuration() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.avg
+}
+
+// ETA estimates how long a newly queued translation would take to finish,
+// from the current backlog and the moving average translation time
+func (p *Pool) ETA() time.Duration {
+	avg := p.AverageDuration()
+
+	ahead := p.Depth() + p.Running()
+	rounds := ahead/p.Workers() + 1
+	return time.Duration(rounds) * avg
+}
+
+// Run waits for a free worker and runs fn, timing it for the ETA estimate.
+// It returns ctx.Err() without running fn if ctx ends while waiting.
+func (p *Pool) Run(ctx context.Context, fn func()) error {
+	p.waiting.Add(1)
+	select {
+	case p.slots <- struct{}{}:
+		p.waiting.Add(-1)
+	case <-ctx.Done():
+		p.waiting.Add(-1)
+		return ctx.Err()
+	}
+	p.running.Add(1)
+	defer func() {
+		p.running.Add(-1)
+		<-p.slots
+	}()
+
+	start := time.Now()
+	fn()
+	p.observe(time.Since(start))
+	return nil
+}
+
+// observe folds a translation duration into the exponential moving average
+func (p *Pool) observe(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.avg = (p.avg*4 + d) / 5
+}
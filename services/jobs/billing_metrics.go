@@ -0,0 +1,232 @@
+package jobs
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/billing"
+	"gorm.io/gorm"
+)
+
+// Where a subscription is billed
+const (
+	billingSourceStripe       = "stripe"
+	billingSourceOrganization = "organization"
+)
+
+// subscriptionEventTypes are the webhook events carrying a subscription's
+// current price and quantity
+var subscriptionEventTypes = []string{"customer.subscription.created", "customer.subscription.updated"}
+
+// PlanMetrics counts the live subscriptions of one plan
+type PlanMetrics struct {
+	PlanID string `json:"plan_id" example:"price_1Oxy3JExamplePriceID"`
+	// stripe, app_store, google_play or organization
+	Source        string `json:"source" example:"stripe"`
+	Subscriptions int    `json:"subscriptions" example:"42"`
+	// Seats of organization subscriptions
+	Seats int `json:"seats,omitempty" example:"0"`
+	// Monthly recurring revenue by currency, in the smallest currency unit
+	MRR map[string]int64 `json:"mrr"`
+}
+
+// BillingMetrics summarizes subscriptions and revenue
+type BillingMetrics struct {
+	// Subscriptions granting their plan now, including past-due ones in their payment grace period
+	ActiveSubscriptions int           `json:"active_subscriptions" example:"120"`
+	ByPlan              []PlanMetrics `json:"by_plan"`
+	// Monthly recurring revenue of paying Stripe subscriptions by currency,
+	// in the smallest currency unit, before discounts and tax
+	MRR map[string]int64 `json:"mrr"`
+	// Start of the current month (UTC) the counts below cover
+	MonthStart       time.Time `json:"month_start"`
+	NewThisMonth     int       `json:"new_this_month" example:"9"`
+	ChurnedThisMonth int       `json:"churned_this_month" example:"3"`
+	// Churned subscriptions over those held at the start of the month
+	ChurnRate float64 `json:"churn_rate" example:"0.026"`
+	// Invoices whose payment failed this month, and their amounts due by currency
+	FailedPaymentsThisMonth int              `json:"failed_payments_this_month" example:"4"`
+	FailedPaymentAmount     map[string]int64 `json:"failed_payment_amount"`
+}
+
+// CollectBillingMetrics computes the billing metrics from the local
+// subscription state and the stored Stripe webhook events, without calling
+// Stripe. MRR counts active and past-due Stripe subscriptions but not trials
+// or App Store and Google Play subscriptions, whose prices are not known.
+func CollectBillingMetrics(db *gorm.DB, now time.Time) (BillingMetrics, error) {
+	now = now.UTC()
+	metrics := BillingMetrics{
+		MRR:                 map[string]int64{},
+		MonthStart:          time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+		FailedPaymentAmount: map[string]int64{},
+	}
+
+	byPlan := map[[2]string]*PlanMetrics{}
+	planMetrics := func(source, planID string) *PlanMetrics {
+		key := [2]string{source, planID}
+		if byPlan[key] == nil {
+			byPlan[key] = &PlanMetrics{PlanID: planID, Source: source, MRR: map[string]int64{}}
+		}
+		return byPlan[key]
+	}
+	// Paying Stripe subscriptions whose price is looked up for MRR
+	paying := map[string]*PlanMetrics{}
+	stripeLive := 0
+
+	users, err := models.FindSubscribers(db)
+	if err != nil {
+		return metrics, err
+	}
+	for i := range users {
+		user := &users[i]
+		if !user.IsSubscribed() {
+			continue
+		}
+		source := user.SubscriptionStore
+		if source == "" {
+			source = billingSourceStripe
+		}
+		plan := planMetrics(source, derefString(user.CurrentPlanID))
+		plan.Subscriptions++
+		metrics.ActiveSubscriptions++
+		if source != billingSourceStripe {
+			continue
+		}
+		stripeLive++
+		if *user.SubscriptionStatus != "trialing" {
+			paying[derefString(user.SubscriptionID)] = plan
+		}
+	}
+
+	orgs, err := models.FindActiveOrgSubscriptions(db)
+	if err != nil {
+		return metrics, err
+	}
+	for _, org := range orgs {
+		plan := planMetrics(billingSourceOrganization, org.PlanID)
+		plan.Subscriptions++
+		plan.Seats += org.Seats
+		metrics.ActiveSubscriptions++
+		stripeLive++
+		if org.Status == "active" {
+			paying[org.SubscriptionID] = plan
+		}
+	}
+
+	if err := addRecurringRevenue(db, paying, &metrics); err != nil {
+		return metrics, err
+	}
+	if err := countSubscriptionChanges(db, &metrics, stripeLive); err != nil {
+		return metrics, err
+	}
+	if err := countFailedPayments(db, &metrics); err != nil {
+		return metrics, err
+	}
+
+	metrics.ByPlan = make([]PlanMetrics, 0, len(byPlan))
+	for _, plan := range byPlan {
+		metrics.ByPlan = append(metrics.ByPlan, *plan)
+	}
+	sort.Slice(metrics.ByPlan, func(i, j int) bool {
+		a, b := metrics.ByPlan[i], metrics.ByPlan[j]
+		if a.Subscriptions != b.Subscriptions {
+			return a.Subscriptions > b.Subscriptions
+		}
+		return a.Source+a.PlanID < b.Source+b.PlanID
+	})
+	return metrics, nil
+}
+
+// addRecurringRevenue adds the monthly amount of each paying subscription, as
+// its latest subscription webhook event priced it
+func addRecurringRevenue(db *gorm.DB, paying map[string]*PlanMetrics, metrics *BillingMetrics) error {
+	ids := make([]string, 0, len(paying))
+	for id := range paying {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	events, err := models.FindLatestStripeEvents(db, subscriptionEventTypes, ids)
+	if err != nil {
+		return err
+	}
+
+	gateway := billing.Client()
+	for _, event := range events {
+		sub, err := gateway.DecodeSubscription(&billing.Event{ID: event.EventID, Type: event.Type, Data: []byte(event.Payload)})
+		if err != nil {
+			log.Printf("Skipping Stripe event %s in billing metrics: %v", event.EventID, err)
+			continue
+		}
+		plan := paying[sub.ID]
+		amount := sub.MonthlyAmount()
+		if plan == nil || amount == 0 {
+			continue
+		}
+		plan.MRR[sub.Currency] += amount
+		metrics.MRR[sub.Currency] += amount
+	}
+	return nil
+}
+
+// countSubscriptionChanges counts the Stripe subscriptions created and
+// deleted this month. The churn rate relates those deleted to the Stripe
+// subscriptions held at the start of the month, derived from the live ones.
+func countSubscriptionChanges(db *gorm.DB, metrics *BillingMetrics, liveNow int) error {
+	events, err := models.FindStripeEventsSince(db, []string{"customer.subscription.created", "customer.subscription.deleted"}, metrics.MonthStart)
+	if err != nil {
+		return err
+	}
+
+	gateway := billing.Client()
+	created, deleted := map[string]bool{}, map[string]bool{}
+	for _, event := range events {
+		sub, err := gateway.DecodeSubscription(&billing.Event{ID: event.EventID, Type: event.Type, Data: []byte(event.Payload)})
+		if err != nil {
+			log.Printf("Skipping Stripe event %s in billing metrics: %v", event.EventID, err)
+			continue
+		}
+		if event.Type == "customer.subscription.created" {
+			created[sub.ID] = true
+		} else {
+			deleted[sub.ID] = true
+		}
+	}
+
+	metrics.NewThisMonth = len(created)
+	metrics.ChurnedThisMonth = len(deleted)
+	// Subscriptions both created and deleted this month cancel out
+	atStart := liveNow - len(created) + len(deleted)
+	if atStart > 0 {
+		metrics.ChurnRate = float64(len(deleted)) / float64(atStart)
+	}
+	return nil
+}
+
+// countFailedPayments counts the invoices whose payment failed this month
+func countFailedPayments(db *gorm.DB, metrics *BillingMetrics) error {
+	events, err := models.FindStripeEventsSince(db, []string{"invoice.payment_failed"}, metrics.MonthStart)
+	if err != nil {
+		return err
+	}
+
+	gateway := billing.Client()
+	failed := map[string]*billing.Invoice{}
+	for _, event := range events {
+		invoice, err := gateway.DecodeInvoice(&billing.Event{ID: event.EventID, Type: event.Type, Data: []byte(event.Payload)})
+		if err != nil {
+			log.Printf("Skipping Stripe event %s in billing metrics: %v", event.EventID, err)
+			continue
+		}
+		// Retries of the same invoice count once, with its latest amount due
+		failed[invoice.ID] = invoice
+	}
+
+	metrics.FailedPaymentsThisMonth = len(failed)
+	for _, invoice := range failed {
+		metrics.FailedPaymentAmount[invoice.Currency] += invoice.AmountDue
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/notifications"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"gorm.io/gorm"
+)
+
+// PaymentGracePeriod returns PAYMENT_GRACE_PERIOD, how long a past-due
+// subscriber keeps their entitlements while Stripe retries the payment
+func PaymentGracePeriod() (time.Duration, error) {
+	grace, err := time.ParseDuration(utils.GetEnvWithDefault("PAYMENT_GRACE_PERIOD", "168h"))
+	if err != nil || grace < 0 {
+		return 0, fmt.Errorf("PAYMENT_GRACE_PERIOD must be a non-negative duration")
+	}
+	return grace, nil
+}
+
+// StartDunning marks the user's subscription past due after a failed payment
+// and tells them, by email and push, to update their payment method before
+// the grace period ends. Later failures of the same episode change nothing.
+// payURL, when known, is the page where the invoice can be paid.
+func StartDunning(ctx context.Context, db *gorm.DB, user *models.User, payURL string) error {
+	grace, err := PaymentGracePeriod()
+	if err != nil {
+		return err
+	}
+
+	graceEndsAt := time.Now().Add(grace)
+	started, err := user.StartPaymentGrace(db, graceEndsAt)
+	if err != nil || !started {
+		return err
+	}
+	log.Printf("Subscription %s of user %d is past due; grace period ends %s", derefString(user.SubscriptionID), user.ID, graceEndsAt.Format(time.RFC3339))
+
+	body := fmt.Sprintf("Hi %s,\n\nWe could not collect the payment for your ThinkInk subscription. Premium features stay available until %s while we retry; please update your payment method before then to keep them.\n", user.Name, user.FormatTimestamp(graceEndsAt))
+	if payURL != "" {
+		body += fmt.Sprintf("\nYou can pay the open invoice and update your card here: %s\n", payURL)
+	}
+	return notifications.Notify(ctx, db, user, "Your ThinkInk payment failed", body, notifications.Channels{Email: true, Push: true})
+}
+
+// DowngradeUnpaidSubscriptions revokes subscriber entitlements of users whose
+// subscription is still past due once the payment grace period ended. A later
+// successful payment restores them through the subscription webhook.
+func DowngradeUnpaidSubscriptions(ctx context.Context, db *gorm.DB) error {
+	users, err := models.FindLapsedPaymentGrace(db, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for i := range users {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		user := &users[i]
+		if err := user.MarkSubscriptionUnpaid(db); err != nil {
+			log.Printf("Failed to downgrade unpaid subscription for user %d: %v", user.ID, err)
+			continue
+		}
+		log.Printf("Downgraded unpaid subscription %s for user %d (past due since %s)", derefString(user.SubscriptionID), user.ID, user.PastDueSince.Format(time.RFC3339))
+
+		body := fmt.Sprintf("Hi %s,\n\nThe payment for your ThinkInk subscription is still outstanding, so premium features are no longer available. Update your payment method in your account settings and they are restored as soon as the payment goes through.\n", user.Name)
+		if err := notifications.Notify(ctx, db, user, "Your ThinkInk subscription is paused", body, notifications.Channels{Email: true, Push: true}); err != nil {
+			log.Printf("Failed to notify user %d of the downgrade: %v", user.ID, err)
+		}
+	}
+
+	return nil
+}
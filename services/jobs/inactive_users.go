@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/email"
+	"gorm.io/gorm"
+)
+
+// FlagInactiveUsers flags users who have not signed in for the given period.
+// Each user is flagged once per inactive stretch; signing in clears the flag.
+// With notify, flagged users are also sent a reminder email.
+func FlagInactiveUsers(ctx context.Context, db *gorm.DB, inactiveFor time.Duration, notify bool) error {
+	users, err := models.FindInactiveUsers(db, time.Now().Add(-inactiveFor), true, nil)
+	if err != nil {
+		return err
+	}
+
+	for i := range users {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		user := &users[i]
+		if err := user.FlagInactive(db); err != nil {
+			log.Printf("Failed to flag inactive user %d: %v", user.ID, err)
+			continue
+		}
+
+		if !notify {
+			continue
+		}
+		body := fmt.Sprintf("Hi %s,\n\nWe haven't seen you on ThinkInk for a while. Your reports are still here whenever you want to pick up where you left off.\n\nSign in to upload a new recording or review your history.\n", user.Name)
+		if err := email.Enqueue(db, user.Email, "We miss you at ThinkInk", body, "marketing"); err != nil {
+			log.Printf("Failed to queue inactivity email for user %d: %v", user.ID, err)
+		}
+	}
+
+	if len(users) > 0 {
+		log.Printf("Flagged %d inactive users", len(users))
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunPeriodic runs fn immediately and then every interval until ctx is cancelled.
+// Errors are logged and do not stop the schedule.
+func RunPeriodic(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	log.Printf("Job %s scheduled every %s", name, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		if err := fn(ctx); err != nil {
+			log.Printf("Job %s failed after %s: %v", name, time.Since(start).Round(time.Millisecond), err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("Job %s stopped", name)
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"fmt"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+)
+
+// Worker modes: background jobs run inside the API process, or in a separate
+// process started with the worker subcommand
+const (
+	ModeEmbedded = "embedded"
+	ModeExternal = "external"
+)
+
+// Mode returns the configured WORKER_MODE
+func Mode() (string, error) {
+	mode := utils.GetEnvWithDefault("WORKER_MODE", ModeEmbedded)
+	if mode != ModeEmbedded && mode != ModeExternal {
+		return "", fmt.Errorf("WORKER_MODE must be %q or %q", ModeEmbedded, ModeExternal)
+	}
+	return mode, nil
+}
+
+// Embedded reports whether background work runs inside the API process
+func Embedded() bool {
+	mode, err := Mode()
+	return err != nil || mode == ModeEmbedded
+}
@@ -0,0 +1,211 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/email"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/plans"
+	"gorm.io/gorm"
+)
+
+// ApplyRecordingRetention enforces the lapse data policy. Users whose
+// subscription has lapsed are notified and get a grace window; if they have
+// not resubscribed when it ends, their raw recordings are archived or purged.
+// Report metadata and translations are always kept.
+func ApplyRecordingRetention(ctx context.Context, db *gorm.DB, uploadDir, archiveDir string) error {
+	if err := scheduleRecordingActions(ctx, db); err != nil {
+		return err
+	}
+	return runRecordingActions(ctx, db, uploadDir, archiveDir)
+}
+
+// scheduleRecordingActions starts the grace window for newly lapsed users
+func scheduleRecordingActions(ctx context.Context, db *gorm.DB) error {
+	users, err := models.FindUsersWithLapsedRecordings(db)
+	if err != nil {
+		return err
+	}
+
+	for i := range users {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		user := &users[i]
+		policy, err := plans.ResolveLapsePolicy(db, user)
+		if err != nil {
+			log.Printf("Failed to resolve lapse policy for user %d: %v", user.ID, err)
+			continue
+		}
+		if policy.Action == config.LapseRetain {
+			continue
+		}
+
+		dueAt := time.Now().AddDate(0, 0, policy.GraceDays)
+		if err := user.ScheduleRecordingsAction(db, policy.Action, dueAt); err != nil {
+			log.Printf("Failed to schedule %s of recordings for user %d: %v", policy.Action, user.ID, err)
+			continue
+		}
+		log.Printf("Scheduled %s of recordings for user %d at %s", policy.Action, user.ID, dueAt.Format(time.RFC3339))
+
+		what := "deleted"
+		if policy.Action == config.LapseArchive {
+			what = "moved to long-term archive and will no longer be available in the app"
+		}
+		body := fmt.Sprintf("Hi %s,\n\nBecause your ThinkInk subscription has ended, the raw EEG recordings attached to your reports will be %s on %s. Your reports and translations will be kept.\n\nResubscribe before then to keep your recordings.\n", user.Name, what, user.FormatTimestamp(dueAt))
+		if err := email.Enqueue(db, user.Email, "Your ThinkInk recordings after your subscription ended", body, "transactional"); err != nil {
+			log.Printf("Failed to queue retention notice for user %d: %v", user.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// runRecordingActions applies due actions and cancels those of users who resubscribed
+func runRecordingActions(ctx context.Context, db *gorm.DB, uploadDir, archiveDir string) error {
+	users, err := models.FindUsersWithScheduledRecordingsAction(db)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range users {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		user := &users[i]
+		if user.IsSubscribed() {
+			if err := user.CancelRecordingsAction(db); err != nil {
+				log.Printf("Failed to cancel recordings action for user %d: %v", user.ID, err)
+			}
+			continue
+		}
+		if user.RecordingsActionDueAt.After(now) || user.RecordingsAction == nil {
+			continue
+		}
+
+		var status string
+		switch *user.RecordingsAction {
+		case config.LapseArchive:
+			err = archiveRecordings(db, user.ID, uploadDir, archiveDir)
+			status = models.RecordingsArchived
+		case config.LapsePurge:
+			err = purgeRecordings(db, user.ID, uploadDir)
+			status = models.RecordingsPurged
+		default:
+			err = fmt.Errorf("unknown action %q", *user.RecordingsAction)
+		}
+		if err != nil {
+			log.Printf("Failed to %s recordings for user %d: %v", *user.RecordingsAction, user.ID, err)
+			continue
+		}
+
+		if err := user.CompleteRecordingsAction(db, status); err != nil {
+			log.Printf("Failed to record recordings status for user %d: %v", user.ID, err)
+			continue
+		}
+		log.Printf("Recordings for user %d %s", user.ID, status)
+
+		body := fmt.Sprintf("Hi %s,\n\nAs notified earlier, the raw EEG recordings attached to your ThinkInk reports have been %s. Your reports and translations are still available.\n", user.Name, status)
+		if err := email.Enqueue(db, user.Email, "Your ThinkInk recordings have been "+status, body, "transactional"); err != nil {
+			log.Printf("Failed to queue retention confirmation for user %d: %v", user.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// userUploads lists the raw files uploaded by a user, which are named "<userID>-<uuid><ext>"
+func userUploads(uploadDir string, userID uint) ([]string, error) {
+	return filepath.Glob(filepath.Join(uploadDir, fmt.Sprintf("%d-*", userID)))
+}
+
+// purgeRecordings deletes the user's raw files and recording data
+func purgeRecordings(db *gorm.DB, userID uint, uploadDir string) error {
+	files, err := userUploads(uploadDir, userID)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return models.ClearReportContents(db, userID)
+}
+
+// archiveRecordings moves the user's raw files and recording data into archiveDir/<userID>
+func archiveRecordings(db *gorm.DB, userID uint, uploadDir, archiveDir string) error {
+	dest := filepath.Join(archiveDir, fmt.Sprint(userID))
+	if err := os.MkdirAll(dest, 0o750); err != nil {
+		return err
+	}
+
+	reports, err := models.FindReportsByUserID(db, userID)
+	if err != nil {
+		return err
+	}
+	for _, r := range reports {
+		if err := r.LoadArchivedContent(context.Background()); err != nil {
+			return err
+		}
+		if len(r.Content) == 0 {
+			continue
+		}
+		data, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dest, fmt.Sprintf("report-%d.json", r.ID)), data, 0o640); err != nil {
+			return err
+		}
+	}
+
+	files, err := userUploads(uploadDir, userID)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := moveFile(f, filepath.Join(dest, filepath.Base(f))); err != nil {
+			return err
+		}
+	}
+
+	return models.ClearReportContents(db, userID)
+}
+
+// moveFile renames src to dst, copying when they are on different filesystems
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"gorm.io/gorm"
+)
+
+// ColdStorageAfterMonths returns REPORT_COLD_STORAGE_AFTER_MONTHS, the age at
+// which report content moves to cold storage; 0 disables archival
+func ColdStorageAfterMonths() (int, error) {
+	months, err := strconv.Atoi(utils.GetEnvWithDefault("REPORT_COLD_STORAGE_AFTER_MONTHS", "0"))
+	if err != nil || months < 0 {
+		return 0, fmt.Errorf("REPORT_COLD_STORAGE_AFTER_MONTHS must be a non-negative integer")
+	}
+	return months, nil
+}
+
+// ArchiveReportContents moves the content of reports created more than months
+// ago into the archive, batchSize at a time, keeping all other report fields
+// in Postgres. It returns how many reports were archived.
+func ArchiveReportContents(ctx context.Context, db *gorm.DB, archive models.ContentArchive, months, batchSize int) (int, error) {
+	cutoff := time.Now().AddDate(0, -months, 0)
+	archived := 0
+	lastID := uint(0)
+	for ctx.Err() == nil {
+		reports, err := models.FindReportsToArchive(db, cutoff, lastID, batchSize)
+		if err != nil {
+			return archived, err
+		}
+		if len(reports) == 0 {
+			break
+		}
+
+		for i := range reports {
+			report := &reports[i]
+			lastID = report.ID
+			key := models.ContentArchiveKey(report.UserID, report.ID)
+			if err := archive.Put(ctx, key, report.Content); err != nil {
+				return archived, fmt.Errorf("failed to archive content of report %d: %w", report.ID, err)
+			}
+
+			ok, err := report.MarkContentArchived(db)
+			if err != nil {
+				return archived, err
+			}
+			if !ok {
+				// Changed while being archived; drop the copy and try again next run
+				if err := archive.Delete(ctx, key); err != nil {
+					log.Printf("Failed to delete stale archived content of report %d: %v", report.ID, err)
+				}
+				continue
+			}
+			archived++
+		}
+	}
+	return archived, nil
+}
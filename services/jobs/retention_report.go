@@ -0,0 +1,127 @@
+package jobs
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"gorm.io/gorm"
+)
+
+// ReportPurgeAfterDays returns the configured REPORT_PURGE_AFTER_DAYS, how
+// long deleted reports are kept before they are permanently removed
+func ReportPurgeAfterDays() (int, error) {
+	days, err := strconv.Atoi(utils.GetEnvWithDefault("REPORT_PURGE_AFTER_DAYS", "30"))
+	if err != nil || days < 0 {
+		return 0, fmt.Errorf("REPORT_PURGE_AFTER_DAYS must be a non-negative integer")
+	}
+	return days, nil
+}
+
+// RetentionPolicy describes the data policies that apply to an organization
+type RetentionPolicy struct {
+	// LapseAction and LapseGraceDays are nil when members follow their plan defaults
+	LapseAction            *string `json:"lapse_action,omitempty" example:"purge"`
+	LapseGraceDays         *int    `json:"lapse_grace_days,omitempty" example:"30"`
+	DefaultLapseAction     string  `json:"default_lapse_action" example:"archive"`
+	DefaultLapseGraceDays  int     `json:"default_lapse_grace_days" example:"30"`
+	DeletedReportPurgeDays int     `json:"deleted_report_purge_days" example:"30"`
+}
+
+// RetentionRecords counts an organization's stored records by age
+type RetentionRecords struct {
+	Reports         models.RetentionAgeCounts `json:"reports"`
+	ReportRevisions models.RetentionAgeCounts `json:"report_revisions"`
+}
+
+// RetentionRecordings summarizes the raw recordings held for an organization
+type RetentionRecordings struct {
+	StoredReports int64            `json:"stored_reports" example:"840"`
+	UsersByStatus map[string]int64 `json:"users_by_status"`
+}
+
+// PendingDeletions lists data scheduled to be removed or archived
+type PendingDeletions struct {
+	DeletedReports             int64                               `json:"deleted_reports" example:"12"`
+	OldestDeletedAt            *time.Time                          `json:"oldest_deleted_at,omitempty"`
+	NextPurgeAt                *time.Time                          `json:"next_purge_at,omitempty"`
+	ScheduledRecordingsActions []models.ScheduledRecordingsActions `json:"scheduled_recordings_actions"`
+}
+
+// RetentionReport is a point-in-time compliance snapshot of the data held
+// for an organization
+type RetentionReport struct {
+	OrganizationID   uint                `json:"organization_id" example:"1"`
+	OrganizationName string              `json:"organization_name" example:"Acme Clinic"`
+	GeneratedAt      time.Time           `json:"generated_at"`
+	Members          int64               `json:"members" example:"25"`
+	Policy           RetentionPolicy     `json:"policy"`
+	Records          RetentionRecords    `json:"records"`
+	Recordings       RetentionRecordings `json:"recordings"`
+	PendingDeletions PendingDeletions    `json:"pending_deletions"`
+	// NotTracked names compliance controls this deployment does not record,
+	// so auditors can tell them apart from an empty result
+	NotTracked []string `json:"not_tracked" example:"legal_holds,data_residency"`
+}
+
+// BuildRetentionReport gathers the retention state of an organization's data
+func BuildRetentionReport(db *gorm.DB, org *models.Organization, purgeAfterDays int) (*RetentionReport, error) {
+	now := time.Now()
+	report := &RetentionReport{
+		OrganizationID:   org.ID,
+		OrganizationName: org.Name,
+		GeneratedAt:      now,
+		NotTracked:       []string{"legal_holds", "data_residency"},
+	}
+
+	defaults := config.Current().LapsePolicyFor(config.PlanPaid)
+	report.Policy = RetentionPolicy{
+		DefaultLapseAction:     defaults.Action,
+		DefaultLapseGraceDays:  defaults.GraceDays,
+		DeletedReportPurgeDays: purgeAfterDays,
+	}
+	ratePlan, err := models.FindOrgRatePlan(db, org.ID)
+	if err != nil {
+		return nil, err
+	}
+	if ratePlan != nil {
+		report.Policy.LapseAction = ratePlan.LapseAction
+		report.Policy.LapseGraceDays = ratePlan.LapseGraceDays
+	}
+
+	if report.Members, err = models.CountOrgMembers(db, org.ID); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if report.Records.Reports, err = models.CountOrgReportsByAge(db, org.ID, now); err != nil {
+		return nil, err
+	}
+	if report.Records.ReportRevisions, err = models.CountOrgReportRevisionsByAge(db, org.ID, now); err != nil {
+		return nil, err
+	}
+
+	if report.Recordings.StoredReports, err = models.CountOrgStoredRecordings(db, org.ID); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if report.Recordings.UsersByStatus, err = models.CountOrgRecordingsByStatus(db, org.ID); err != nil {
+		return nil, err
+	}
+
+	deleted, oldest, err := models.FindOrgDeletedReports(db, org.ID)
+	if err != nil {
+		return nil, err
+	}
+	report.PendingDeletions.DeletedReports = deleted
+	report.PendingDeletions.OldestDeletedAt = oldest
+	if oldest != nil {
+		nextPurge := oldest.AddDate(0, 0, purgeAfterDays)
+		report.PendingDeletions.NextPurgeAt = &nextPurge
+	}
+	if report.PendingDeletions.ScheduledRecordingsActions, err = models.FindOrgScheduledRecordingsActions(db, org.ID); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,209 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"gorm.io/gorm"
+)
+
+// orphanGracePeriod is how old a stored file must be before it counts as
+// orphaned, since uploads store the file before creating its record
+const orphanGracePeriod = time.Hour
+
+// storageCleanupBatch is how many files or records are handled at a time
+const storageCleanupBatch = 500
+
+// FileRetentionDays returns FILE_RETENTION_DAYS, how long uploaded files are
+// kept before they are deleted; 0 keeps them until their owner deletes them
+func FileRetentionDays() (int, error) {
+	days, err := strconv.Atoi(utils.GetEnvWithDefault("FILE_RETENTION_DAYS", "0"))
+	if err != nil || days < 0 {
+		return 0, fmt.Errorf("FILE_RETENTION_DAYS must be a non-negative integer")
+	}
+	return days, nil
+}
+
+// CleanUpStorage deletes files older than the retention period, removes
+// stored files in dirs that no file record refers to and deletes records
+// whose stored file is missing. The run is recorded for the storage metrics.
+func CleanUpStorage(ctx context.Context, db *gorm.DB, retentionDays int, dirs []string) (models.StorageCleanupRun, error) {
+	run := models.StorageCleanupRun{StartedAt: time.Now()}
+
+	if retentionDays > 0 {
+		if err := deleteExpiredFiles(ctx, db, time.Now().AddDate(0, 0, -retentionDays), &run); err != nil {
+			return run, err
+		}
+	}
+	for _, dir := range dirs {
+		if err := removeOrphanedBlobs(ctx, db, dir, &run); err != nil {
+			return run, err
+		}
+	}
+	if err := deleteOrphanedRecords(ctx, db, &run); err != nil {
+		return run, err
+	}
+
+	run.FinishedAt = time.Now()
+	if err := run.Save(db); err != nil {
+		return run, err
+	}
+	return run, nil
+}
+
+// deleteExpiredFiles deletes files uploaded before cutoff. Reports made from
+// them are kept, as when their owner deletes the file.
+func deleteExpiredFiles(ctx context.Context, db *gorm.DB, cutoff time.Time, run *models.StorageCleanupRun) error {
+	for ctx.Err() == nil {
+		files, err := models.FindExpiredFiles(db, cutoff, storageCleanupBatch)
+		if err != nil {
+			return err
+		}
+		for i := range files {
+			file := &files[i]
+			size := storedSize(file.FilePath)
+			if err := file.RemoveStored(); err != nil {
+				return fmt.Errorf("failed to remove file %d: %w", file.ID, err)
+			}
+			if err := file.Delete(db); err != nil {
+				return fmt.Errorf("failed to delete file %d: %w", file.ID, err)
+			}
+			run.ExpiredFiles++
+			run.ReclaimedBytes += size
+		}
+		if len(files) < storageCleanupBatch {
+			return nil
+		}
+	}
+	return nil
+}
+
+// removeOrphanedBlobs removes the files in dir that no file record refers to
+func removeOrphanedBlobs(ctx context.Context, db *gorm.DB, dir string, run *models.StorageCleanupRun) error {
+	d, err := os.Open(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	for ctx.Err() == nil {
+		entries, err := d.ReadDir(storageCleanupBatch)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		sizes := make(map[string]int64, len(entries))
+		paths := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil || !info.Mode().IsRegular() || time.Since(info.ModTime()) < orphanGracePeriod {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			sizes[path] = info.Size()
+			paths = append(paths, path)
+		}
+		if len(paths) == 0 {
+			continue
+		}
+
+		referenced, err := models.FindReferencedFilePaths(db, paths)
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			if referenced[path] {
+				continue
+			}
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				log.Printf("Failed to remove orphaned file %s: %v", path, err)
+				continue
+			}
+			run.OrphanedBlobs++
+			run.ReclaimedBytes += sizes[path]
+		}
+	}
+	return nil
+}
+
+// deleteOrphanedRecords deletes the records of files missing from disk
+func deleteOrphanedRecords(ctx context.Context, db *gorm.DB, run *models.StorageCleanupRun) error {
+	return models.FindFilesInBatches(db, storageCleanupBatch, func(files []models.SingleFile) error {
+		for i := range files {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			file := &files[i]
+			if _, err := os.Stat(file.FilePath); !os.IsNotExist(err) {
+				continue
+			}
+			if err := file.Delete(db); err != nil {
+				return fmt.Errorf("failed to delete file %d: %w", file.ID, err)
+			}
+			run.OrphanedRecords++
+		}
+		return nil
+	})
+}
+
+// storedSize returns the size of a stored file, or 0 when it is gone
+func storedSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// StorageMetrics reports what the storage cleanup job has removed across all processes
+type StorageMetrics struct {
+	models.StorageCleanupTotals
+}
+
+// CollectStorageMetrics adds up the recorded storage cleanup runs
+func CollectStorageMetrics(db *gorm.DB) (StorageMetrics, error) {
+	totals, err := models.SumStorageCleanups(db)
+	if err != nil {
+		return StorageMetrics{}, err
+	}
+	return StorageMetrics{StorageCleanupTotals: totals}, nil
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format
+func (m StorageMetrics) WritePrometheus(w io.Writer) error {
+	lastRun := 0.0
+	if m.LastRunAt != nil {
+		lastRun = float64(m.LastRunAt.Unix())
+	}
+	metrics := []struct {
+		name, kind, help string
+		value            float64
+	}{
+		{"thinkink_storage_cleanup_runs_total", "counter", "Storage cleanup runs.", float64(m.Runs)},
+		{"thinkink_storage_expired_files_total", "counter", "Files deleted for being older than FILE_RETENTION_DAYS.", float64(m.ExpiredFiles)},
+		{"thinkink_storage_orphaned_blobs_total", "counter", "Stored files removed because no file record referred to them.", float64(m.OrphanedBlobs)},
+		{"thinkink_storage_orphaned_records_total", "counter", "File records deleted because their stored file was missing.", float64(m.OrphanedRecords)},
+		{"thinkink_storage_reclaimed_bytes_total", "counter", "Disk space freed by the storage cleanup.", float64(m.ReclaimedBytes)},
+		{"thinkink_storage_cleanup_last_run_timestamp_seconds", "gauge", "When the latest storage cleanup finished; 0 before the first.", lastRun},
+	}
+	for _, metric := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", metric.name, metric.help, metric.name, metric.kind, metric.name, metric.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/iap"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/plans"
+	"gorm.io/gorm"
+)
+
+// SaveStoreSubscription records a validated in-app subscription and, if the
+// user holds it, applies it as their subscription
+func SaveStoreSubscription(db *gorm.DB, user *models.User, purchase *models.StorePurchase, sub *iap.Subscription) error {
+	purchase.ProductID = sub.ProductID
+	purchase.Status = sub.Status
+	expiresAt := sub.ExpiresAt
+	purchase.ExpiresAt = &expiresAt
+	purchase.AutoRenew = sub.AutoRenew
+	purchase.Sandbox = sub.Sandbox
+	purchase.LastValidatedAt = time.Now()
+	if err := models.SaveStorePurchase(db, purchase); err != nil {
+		return err
+	}
+
+	if !user.HoldsStorePurchase(purchase) {
+		return nil
+	}
+	if err := user.UpdateStoreSubscription(db, purchase); err != nil {
+		return err
+	}
+	plans.Invalidate(user.ID)
+	return nil
+}
+
+// RefreshStorePurchases revalidates in-app subscriptions that are about to
+// expire, or have expired but may still renew, so renewals, billing retries
+// and refunds in the App Store and Google Play reach the users holding them
+// before the subscription-expiry job downgrades them. Each purchase is checked
+// at most hourly.
+func RefreshStorePurchases(ctx context.Context, db *gorm.DB) error {
+	now := time.Now()
+	purchases, err := models.FindStorePurchasesDue(db, now.Add(time.Hour), now.Add(-time.Hour), 100)
+	if err != nil {
+		return err
+	}
+
+	for i := range purchases {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		purchase := &purchases[i]
+		verifier := iap.VerifierFor(iap.Store(purchase.Store))
+		if verifier == nil {
+			continue
+		}
+
+		sub, err := verifier.Verify(ctx, purchase.ProductID, purchase.Receipt)
+		if errors.Is(err, iap.ErrInvalidReceipt) {
+			// The store no longer knows the purchase, as with long-expired
+			// Google Play tokens
+			sub = &iap.Subscription{
+				ProductID: purchase.ProductID,
+				Status:    models.SubscriptionStatusExpired,
+				ExpiresAt: derefTime(purchase.ExpiresAt, now),
+				Sandbox:   purchase.Sandbox,
+			}
+		} else if err != nil {
+			log.Printf("Failed to revalidate %s purchase %d: %v", purchase.Store, purchase.ID, err)
+			continue
+		} else if sub.Sandbox && !iap.SandboxAllowed() {
+			// Sandbox purchases recorded while they were allowed stop renewing
+			sub.Status = models.SubscriptionStatusExpired
+		}
+
+		user, err := models.FindUserByID(db, purchase.UserID)
+		if err != nil {
+			log.Printf("Failed to load user %d of %s purchase %d: %v", purchase.UserID, purchase.Store, purchase.ID, err)
+			continue
+		}
+		previous := purchase.Status
+		if err := SaveStoreSubscription(db, user, purchase, sub); err != nil {
+			log.Printf("Failed to save revalidated %s purchase %d: %v", purchase.Store, purchase.ID, err)
+			continue
+		}
+		if previous != sub.Status {
+			log.Printf("Revalidated %s purchase %d of user %d: status %s -> %s", purchase.Store, purchase.ID, user.ID, previous, sub.Status)
+		}
+	}
+
+	return nil
+}
+
+func derefTime(t *time.Time, fallback time.Time) time.Time {
+	if t == nil {
+		return fallback
+	}
+	return *t
+}
@@ -0,0 +1,118 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/billing"
+	"gorm.io/gorm"
+)
+
+// Retry policy for Stripe webhook events that failed to process. Each event's
+// lease is renewed just before it is handled, so it only has to cover one
+// event rather than the whole batch.
+const (
+	stripeEventRetryBase  = 30 * time.Second
+	stripeEventRetryMax   = time.Hour
+	stripeEventRetryLease = 5 * time.Minute
+)
+
+// StripeEventHandler applies a Stripe webhook event. Returning an error
+// retries the event, unless it is a PermanentError.
+type StripeEventHandler func(ctx context.Context, db *gorm.DB, event *billing.Event) error
+
+// PermanentError marks an event that can never be processed, such as one
+// with a malformed payload; it is parked without further retries
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return fmt.Sprintf("permanent failure: %v", e.Err)
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// ProcessStripeEvents handles queued Stripe webhook events in the order they
+// arrived, backing off exponentially between attempts. Events are parked as
+// dead when handle fails permanently or after maxAttempts. Events older than
+// one already processed for the same Stripe object are skipped.
+func ProcessStripeEvents(ctx context.Context, db *gorm.DB, handle StripeEventHandler, maxAttempts int) error {
+	events, err := models.ClaimDueStripeEvents(db, 50, stripeEventRetryLease)
+	if err != nil {
+		return err
+	}
+
+	for i := range events {
+		if ctx.Err() != nil {
+			return nil
+		}
+		processStripeEvent(ctx, db, &events[i], handle, maxAttempts)
+	}
+	return nil
+}
+
+func processStripeEvent(ctx context.Context, db *gorm.DB, event *models.StripeEvent, handle StripeEventHandler, maxAttempts int) {
+	renewed, err := event.RenewLease(db, stripeEventRetryLease)
+	if err != nil {
+		log.Printf("Failed to renew lease of Stripe event %s: %v", event.EventID, err)
+		return
+	}
+	if !renewed {
+		// The lease ran out and another worker took the event over
+		return
+	}
+
+	superseded, err := event.Superseded(db)
+	if err != nil {
+		log.Printf("Failed to check Stripe event %s against newer events: %v", event.EventID, err)
+		return
+	}
+	if superseded {
+		log.Printf("Skipping Stripe event %s (%s): a newer event for %s was already processed", event.EventID, event.Type, event.ObjectID)
+		if err := event.MarkProcessed(db); err != nil {
+			log.Printf("Failed to mark Stripe event %s as processed: %v", event.EventID, err)
+		}
+		return
+	}
+
+	processErr := handle(ctx, db, &billing.Event{ID: event.EventID, Type: event.Type, Data: []byte(event.Payload)})
+	if processErr == nil {
+		if err := event.MarkProcessed(db); err != nil {
+			log.Printf("Failed to mark Stripe event %s as processed: %v", event.EventID, err)
+		}
+		return
+	}
+
+	var permErr *PermanentError
+	if errors.As(processErr, &permErr) || event.Attempts+1 >= maxAttempts {
+		log.Printf("ALERT: Stripe event %s (%s) failed permanently after %d attempts and was parked: %v", event.EventID, event.Type, event.Attempts+1, processErr)
+		if err := event.MarkDead(db, processErr); err != nil {
+			log.Printf("Failed to park Stripe event %s: %v", event.EventID, err)
+		}
+		return
+	}
+
+	next := time.Now().Add(stripeEventRetryBackoff(event.Attempts + 1))
+	log.Printf("Stripe event %s (%s) failed (attempt %d), retrying at %s: %v", event.EventID, event.Type, event.Attempts+1, next.Format(time.RFC3339), processErr)
+	if err := event.MarkRetry(db, processErr, next); err != nil {
+		log.Printf("Failed to schedule retry for Stripe event %s: %v", event.EventID, err)
+	}
+}
+
+// stripeEventRetryBackoff returns the delay before the retry following the
+// given number of prior attempts
+func stripeEventRetryBackoff(attempts int) time.Duration {
+	d := time.Duration(float64(stripeEventRetryBase) * math.Pow(2, float64(attempts-1)))
+	if d > stripeEventRetryMax || d <= 0 {
+		return stripeEventRetryMax
+	}
+	return d
+}
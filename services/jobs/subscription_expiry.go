@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/email"
+	"gorm.io/gorm"
+)
+
+// ExpireLapsedSubscriptions downgrades users whose subscription period ended
+// more than grace ago without a renewal webhook. Stripe remains the source of
+// truth: a late renewal webhook simply overwrites the expired status.
+func ExpireLapsedSubscriptions(ctx context.Context, db *gorm.DB, grace time.Duration) error {
+	cutoff := time.Now().Add(-grace)
+	users, err := models.FindLapsedSubscriptions(db, cutoff)
+	if err != nil {
+		return err
+	}
+
+	for i := range users {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		user := &users[i]
+		endedAt := *user.SubscriptionEndsAt
+		expired, err := user.ExpireSubscription(db, cutoff)
+		if err != nil {
+			log.Printf("Failed to expire subscription for user %d: %v", user.ID, err)
+			continue
+		}
+		if !expired {
+			// Renewed or changed since it was found lapsed
+			continue
+		}
+		log.Printf("Expired subscription %s for user %d (ended %s, no renewal received)", derefString(user.SubscriptionID), user.ID, endedAt.Format(time.RFC3339))
+
+		body := fmt.Sprintf("Hi %s,\n\nYour ThinkInk subscription ended on %s and has not been renewed, so premium features are no longer available.\n\nIf you believe this is a mistake or would like to resubscribe, visit your account settings.\n", user.Name, user.FormatTimestamp(endedAt))
+		if err := email.Enqueue(db, user.Email, "Your ThinkInk subscription has expired", body, "transactional"); err != nil {
+			log.Printf("Failed to queue expiry email for user %d: %v", user.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
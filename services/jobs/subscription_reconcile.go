@@ -0,0 +1,189 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/billing"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/plans"
+	"gorm.io/gorm"
+)
+
+// ReconcileSubscriptions compares every subscription in Stripe with the local
+// billing state and repairs drift left by missed or failed webhooks: status,
+// period end, and for organizations seats. Local subscriptions Stripe no
+// longer knows are canceled. Every correction is logged.
+func ReconcileSubscriptions(ctx context.Context, db *gorm.DB) error {
+	seen := map[string]bool{}
+	checked, corrected := 0, 0
+
+	err := billing.Client().EachSubscription(ctx, func(s *billing.Subscription) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		seen[s.ID] = true
+		checked++
+
+		fixed, err := reconcileSubscription(ctx, db, s)
+		if err != nil {
+			log.Printf("Failed to reconcile subscription %s: %v", s.ID, err)
+			return nil
+		}
+		if fixed {
+			corrected++
+		}
+		return nil
+	})
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		// A partial listing cannot tell which local subscriptions are gone
+		return fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	users, err := models.FindUsersWithLiveSubscription(db)
+	if err != nil {
+		return err
+	}
+	for i := range users {
+		user := &users[i]
+		if seen[*user.SubscriptionID] {
+			continue
+		}
+		previous := derefString(user.SubscriptionStatus)
+		if err := user.UpdateSubscriptionData(db, "", "", "canceled", nil); err != nil {
+			log.Printf("Failed to cancel subscription %s of user %d missing from Stripe: %v", *user.SubscriptionID, user.ID, err)
+			continue
+		}
+		log.Printf("Reconciled user %d: subscription missing from Stripe, status %s -> canceled", user.ID, previous)
+		corrected++
+	}
+
+	if corrected > 0 {
+		log.Printf("Subscription reconciliation checked %d subscriptions and corrected %d", checked, corrected)
+	}
+	return nil
+}
+
+// reconcileSubscription repairs the local copy of one Stripe subscription and
+// reports whether it had drifted
+func reconcileSubscription(ctx context.Context, db *gorm.DB, s *billing.Subscription) (bool, error) {
+	if s.CustomerID == "" {
+		return false, nil
+	}
+
+	org, err := models.FindOrgSubscriptionByCustomer(db, s.CustomerID)
+	if err != nil {
+		return false, err
+	}
+	if org != nil {
+		return reconcileOrgSubscription(db, org, s)
+	}
+
+	user, err := models.FindUserByStripeCustomerID(db, s.CustomerID)
+	if err != nil || user == nil {
+		return false, err
+	}
+	return reconcileUserSubscription(ctx, db, user, s)
+}
+
+// reconcileUserSubscription repairs the subscription a user holds. A live
+// Stripe subscription is adopted when the user holds none, e.g. after a
+// missed checkout webhook.
+func reconcileUserSubscription(ctx context.Context, db *gorm.DB, user *models.User, s *billing.Subscription) (bool, error) {
+	local := derefString(user.SubscriptionStatus)
+	current := derefString(user.SubscriptionID) == s.ID
+	if !current {
+		live := s.IsActive() || s.Status == models.SubscriptionStatusPastDue
+		if !live || local == "active" || local == "trialing" || local == models.SubscriptionStatusPastDue {
+			return false, nil
+		}
+	}
+
+	if s.Status == "canceled" {
+		// As the customer.subscription.deleted webhook leaves it
+		if local == "canceled" || local == models.SubscriptionStatusExpired {
+			return false, nil
+		}
+		if err := user.UpdateSubscriptionData(db, "", "", "canceled", nil); err != nil {
+			return false, err
+		}
+		log.Printf("Reconciled subscription %s of user %d: status %s -> canceled", s.ID, user.ID, local)
+		return true, nil
+	}
+
+	endsAt := user.SubscriptionEndsAt
+	statusDrift := !current || !subscriptionStatusMatches(local, s.Status)
+	endDrift := endsAt == nil || endsAt.Unix() != s.CurrentPeriodEnd.Unix()
+	if !statusDrift && !endDrift {
+		return false, nil
+	}
+
+	status := s.Status
+	if local == models.SubscriptionStatusUnpaid && status == models.SubscriptionStatusPastDue {
+		status = local
+	}
+	planID := s.PriceID
+	if planID == "" {
+		planID = derefString(user.CurrentPlanID)
+	}
+	periodEnd := s.CurrentPeriodEnd
+	if err := user.UpdateSubscriptionData(db, s.ID, planID, status, &periodEnd); err != nil {
+		return false, err
+	}
+	plans.Invalidate(user.ID)
+	log.Printf("Reconciled subscription %s of user %d: status %s -> %s, ends at %s -> %s", s.ID, user.ID, local, status, formatTime(endsAt), periodEnd.Format(time.RFC3339))
+
+	// A failed renewal whose webhook was missed starts the grace period now
+	if status == models.SubscriptionStatusPastDue && user.PastDueSince == nil {
+		if err := StartDunning(ctx, db, user, ""); err != nil {
+			log.Printf("Failed to start payment grace period for user %d: %v", user.ID, err)
+		}
+	}
+	return true, nil
+}
+
+// reconcileOrgSubscription repairs an organization's seat subscription
+func reconcileOrgSubscription(db *gorm.DB, org *models.OrgSubscription, s *billing.Subscription) (bool, error) {
+	if org.SubscriptionID != "" && org.SubscriptionID != s.ID {
+		return false, nil
+	}
+	if org.SubscriptionID == "" && !s.IsActive() {
+		return false, nil
+	}
+
+	seats := int(s.Quantity)
+	endDrift := org.CurrentPeriodEnd == nil || org.CurrentPeriodEnd.Unix() != s.CurrentPeriodEnd.Unix()
+	if org.Status == s.Status && org.Seats == seats && (s.Status == "canceled" || !endDrift) {
+		return false, nil
+	}
+
+	previousStatus, previousSeats, previousEnd, wasActive := org.Status, org.Seats, org.CurrentPeriodEnd, org.IsActive()
+	periodEnd := s.CurrentPeriodEnd
+	if err := org.UpdateSubscription(db, s.ID, s.ItemID, s.PriceID, s.Status, seats, &periodEnd); err != nil {
+		return false, err
+	}
+	if org.IsActive() != wasActive {
+		plans.Invalidate()
+	}
+	log.Printf("Reconciled subscription %s of organization %d: status %s -> %s, seats %d -> %d, ends at %s -> %s",
+		s.ID, org.OrganizationID, previousStatus, s.Status, previousSeats, seats, formatTime(previousEnd), periodEnd.Format(time.RFC3339))
+	return true, nil
+}
+
+// subscriptionStatusMatches reports whether a local status reflects Stripe's.
+// A past-due subscription whose grace period lapsed is unpaid locally.
+func subscriptionStatusMatches(local, stripe string) bool {
+	return local == stripe || (local == models.SubscriptionStatusUnpaid && stripe == models.SubscriptionStatusPastDue)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "none"
+	}
+	return t.Format(time.RFC3339)
+}
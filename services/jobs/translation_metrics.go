@@ -0,0 +1,87 @@
+package jobs
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/ingest"
+	"gorm.io/gorm"
+)
+
+// TranslationMetrics is a snapshot of the shared translation queue and of one
+// worker's share of it: the signals an autoscaler sizes the worker fleet by.
+// Upload jobs waiting to be processed count as queued translations.
+type TranslationMetrics struct {
+	// Reports and upload jobs waiting for any worker
+	QueueDepth int64 `json:"queue_depth" example:"42"`
+	// Reports and upload jobs being processed by any worker
+	Running int64 `json:"running" example:"8"`
+	// How long the longest-waiting report or upload has been queued; 0 when none is
+	OldestPendingSeconds float64 `json:"oldest_pending_seconds" example:"95.5"`
+	// Translations this worker runs at once (TRANSLATION_WORKERS)
+	Capacity int `json:"capacity" example:"4"`
+	// Translations this worker is running
+	InProgress int `json:"in_progress" example:"4"`
+	// Moving average time this worker takes per translation
+	AverageDurationSeconds float64 `json:"average_duration_seconds" example:"12.3"`
+	// Whether this worker is finishing its work before shutting down
+	Draining bool `json:"draining" example:"false"`
+}
+
+// CollectTranslationMetrics reads the queue from the database and this
+// process's pool
+func CollectTranslationMetrics(db *gorm.DB, pool *ingest.Pool, draining bool) (TranslationMetrics, error) {
+	stats, err := models.CountTranslationQueue(db)
+	if err != nil {
+		return TranslationMetrics{}, err
+	}
+	uploads, err := models.CountUploadQueue(db)
+	if err != nil {
+		return TranslationMetrics{}, err
+	}
+
+	metrics := TranslationMetrics{
+		QueueDepth:             stats.Pending + uploads.Queued,
+		Running:                stats.Running + uploads.Running,
+		Capacity:               pool.Workers(),
+		InProgress:             pool.Running(),
+		AverageDurationSeconds: pool.AverageDuration().Seconds(),
+		Draining:               draining,
+	}
+	oldest := stats.OldestPendingAt
+	if uploads.OldestQueuedAt != nil && (oldest == nil || uploads.OldestQueuedAt.Before(*oldest)) {
+		oldest = uploads.OldestQueuedAt
+	}
+	if oldest != nil {
+		metrics.OldestPendingSeconds = time.Since(*oldest).Seconds()
+	}
+	return metrics, nil
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format
+func (m TranslationMetrics) WritePrometheus(w io.Writer) error {
+	draining := 0
+	if m.Draining {
+		draining = 1
+	}
+	gauges := []struct {
+		name, help string
+		value      float64
+	}{
+		{"thinkink_translation_queue_depth", "Reports and uploads waiting for a translation worker.", float64(m.QueueDepth)},
+		{"thinkink_translation_running", "Reports and uploads being processed by any worker.", float64(m.Running)},
+		{"thinkink_translation_oldest_pending_seconds", "Age of the longest-waiting queued report or upload.", m.OldestPendingSeconds},
+		{"thinkink_worker_translation_capacity", "Translations this worker runs at once.", float64(m.Capacity)},
+		{"thinkink_worker_translations_in_progress", "Translations this worker is running.", float64(m.InProgress)},
+		{"thinkink_worker_translation_duration_seconds", "Moving average time this worker takes per translation.", m.AverageDurationSeconds},
+		{"thinkink_worker_draining", "1 while this worker finishes its work before shutting down.", float64(draining)},
+	}
+	for _, g := range gauges {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", g.name, g.help, g.name, g.name, g.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
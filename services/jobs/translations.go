@@ -0,0 +1,160 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/encryption"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/ingest"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/moderation"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/plans"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/postprocess"
+	"gorm.io/gorm"
+)
+
+// inFlight holds the IDs of reports this process has claimed and not yet
+// finished, so a drain that runs out of time can hand them back
+var inFlight = struct {
+	sync.Mutex
+	ids map[uint]struct{}
+}{ids: make(map[uint]struct{})}
+
+// TranslatePending translates reports queued for a dedicated worker. The ML
+// service authenticates the report owner, so the worker signs a token on their behalf.
+func TranslatePending(ctx context.Context, db *gorm.DB, pool *ingest.Pool, address string) error {
+	reports, err := models.ClaimPendingTranslations(db, pool.Workers()*4)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for i := range reports {
+		report := &reports[i]
+		trackInFlight(report.ID, true)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer trackInFlight(report.ID, false)
+			if err := pool.Run(ctx, func() { translateReport(db, report, address) }); err != nil {
+				// Shutting down; leave the report for the next run
+				_ = db.Model(report).Update("translation_status", models.TranslationPending).Error
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// StartTranslation translates a report queued by this process as soon as a
+// translation worker is free, unless another worker claimed it first. ctx
+// carries the request's values but must outlive it.
+func StartTranslation(ctx context.Context, db *gorm.DB, pool *ingest.Pool, report *models.Report, address string) {
+	claimed, err := report.ClaimTranslation(db)
+	if err != nil {
+		log.Printf("Failed to claim translation of report %d: %v", report.ID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+	if err := pool.Run(ctx, func() { translateReport(db, report, address) }); err != nil {
+		// Shutting down; leave the report for the next run
+		_ = db.Model(report).Update("translation_status", models.TranslationPending).Error
+	}
+}
+
+func trackInFlight(reportID uint, claimed bool) {
+	inFlight.Lock()
+	defer inFlight.Unlock()
+	if claimed {
+		inFlight.ids[reportID] = struct{}{}
+	} else {
+		delete(inFlight.ids, reportID)
+	}
+}
+
+// ReleaseInFlight returns the reports this process is still translating to
+// the queue. Call it when a drain times out, just before exiting; a
+// translation finishing afterwards is simply translated again elsewhere.
+func ReleaseInFlight(db *gorm.DB) (int64, error) {
+	inFlight.Lock()
+	ids := make([]uint, 0, len(inFlight.ids))
+	for id := range inFlight.ids {
+		ids = append(ids, id)
+	}
+	inFlight.Unlock()
+	return models.ReleaseTranslations(db, ids)
+}
+
+// translateReport translates a single claimed report and records the usage
+func translateReport(db *gorm.DB, report *models.Report, address string) {
+	if report.SourceFileID != nil {
+		setFileState(db, *report.SourceFileID, models.FileStateTranslating)
+		defer func() {
+			state := models.FileStateFailed
+			if report.TranslationStatus == models.TranslationCompleted {
+				state = models.FileStateCompleted
+			}
+			setFileState(db, *report.SourceFileID, state)
+		}()
+	}
+
+	// fail records a translation that produced nothing, and why, on the source file
+	fail := func(class, message string) {
+		if report.SourceFileID != nil {
+			recordFileError(db, *report.SourceFileID, class, message)
+		}
+		_ = report.FinishTranslation(db, "", "")
+	}
+
+	owner, err := models.FindUserByID(db, report.UserID)
+	if err != nil {
+		log.Printf("Skipping translation for report %d: %v", report.ID, err)
+		fail(models.FileErrorProcessing, "The account that owns the report no longer exists")
+		return
+	}
+	token, err := owner.GenerateJWT()
+	if err != nil {
+		log.Printf("Skipping translation for report %d: %v", report.ID, err)
+		fail(models.FileErrorTranslation, "Failed to authenticate with the translation service")
+		return
+	}
+
+	if err := report.LoadArchivedContent(context.Background()); err != nil {
+		log.Printf("Skipping translation for report %d: %v", report.ID, err)
+		fail(models.FileErrorProcessing, "The recording could not be read from cold storage; retry the translation later")
+		return
+	}
+
+	raw, err := services.TranslateSignal(context.Background(), address, "Bearer "+token, report.Content)
+	if err != nil {
+		log.Printf("Translation failed for report %d: %v", report.ID, err)
+		fail(translationErrorClass(err), err.Error())
+		return
+	}
+	description := raw
+	if names, _, err := postprocess.Effective(db, owner); err != nil {
+		log.Printf("Storing unprocessed translation for report %d: %v", report.ID, err)
+	} else {
+		description = postprocess.Run(names, raw, owner.Locale)
+	}
+	stored, storedRaw, err := encryption.SealTranslation(context.Background(), db, report.UserID, description, raw)
+	if err != nil {
+		log.Printf("Failed to encrypt translation for report %d: %v", report.ID, err)
+		fail(models.FileErrorProcessing, "Failed to encrypt translation")
+		return
+	}
+	if err := report.FinishTranslation(db, stored, storedRaw); err != nil {
+		log.Printf("Failed to store translation for report %d: %v", report.ID, err)
+		return
+	}
+	if err := plans.RecordTranslation(db, report.UserID); err != nil {
+		log.Printf("Failed to record usage for user %d: %v", report.UserID, err)
+	}
+	if err := moderation.Review(context.Background(), db, report, description); err != nil {
+		log.Printf("Failed to moderate report %d: %v", report.ID, err)
+	}
+}
@@ -0,0 +1,349 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/encryption"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/ingest"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/moderation"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/notifications"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/plans"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/postprocess"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ProcessQueuedUploads processes upload jobs waiting for a worker, and jobs
+// whose worker stopped before finishing them
+func ProcessQueuedUploads(ctx context.Context, db *gorm.DB, pool *ingest.Pool, address string) error {
+	claimed, err := models.ClaimUploadJobs(db, pool.Workers()*4)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for i := range claimed {
+		job := &claimed[i]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runUploadJob(ctx, db, pool, job, address)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// StartUploadJob processes a job queued by this process as soon as a
+// translation worker is free, unless another worker claimed it first. ctx
+// carries the upload request's values but must outlive it.
+func StartUploadJob(ctx context.Context, db *gorm.DB, pool *ingest.Pool, job *models.UploadJob, address string) {
+	claimed, err := job.Claim(db)
+	if err != nil {
+		log.Printf("Failed to claim upload job %d: %v", job.ID, err)
+		return
+	}
+	if claimed {
+		runUploadJob(ctx, db, pool, job, address)
+	}
+}
+
+// runUploadJob waits for a translation worker and processes a claimed job
+func runUploadJob(ctx context.Context, db *gorm.DB, pool *ingest.Pool, job *models.UploadJob, address string) {
+	err := pool.Run(ctx, func() {
+		// A started job is finished even if the process begins shutting down
+		processUpload(context.WithoutCancel(ctx), db, job, address)
+	})
+	if err != nil {
+		// Shutting down; leave the job for the next run
+		if err := job.Requeue(db); err != nil {
+			log.Printf("Failed to requeue upload job %d: %v", job.ID, err)
+		}
+	}
+}
+
+// processUpload turns the job's file into a report: it measures signal
+// quality, translates the recording when the plan allows it, creates the
+// report and notifies the owner of the outcome
+func processUpload(ctx context.Context, db *gorm.DB, job *models.UploadJob, address string) {
+	models.PublishFileEvent(db, job, models.FileEventTranslating)
+
+	owner, err := models.FindUserByID(db, job.UserID)
+	if err != nil {
+		log.Printf("Skipping upload job %d: %v", job.ID, err)
+		failUpload(ctx, db, job, nil, models.FileErrorProcessing, "The account that uploaded the file no longer exists")
+		return
+	}
+
+	file, err := models.FindUserFile(db, job.FileID, job.UserID)
+	if err != nil {
+		failUpload(ctx, db, job, owner, models.FileErrorProcessing, "The file was deleted before it was processed")
+		return
+	}
+	// A run that stopped after saving the report, or a run this one took over
+	// from, already turned the file into a report
+	if reportID, err := models.FindReportIDForFile(db, file.ID); err == nil {
+		log.Printf("File %d of upload job %d already has report %d", file.ID, job.ID, reportID)
+		if err := job.Complete(db, reportID, nil); err != nil {
+			log.Printf("Failed to complete upload job %d: %v", job.ID, err)
+			return
+		}
+		setFileState(db, file.ID, models.FileStateCompleted)
+		models.PublishFileEvent(db, job, models.FileEventReportReady)
+		return
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("Failed to look up report of file %d for upload job %d: %v", file.ID, job.ID, err)
+		failUpload(ctx, db, job, owner, models.FileErrorProcessing, "Failed to look up the file's report")
+		return
+	}
+	setFileState(db, file.ID, models.FileStateScanning)
+	// Decode the samples straight from storage rather than reading the file
+	// into memory first
+	f, err := os.Open(file.FilePath)
+	if err != nil {
+		log.Printf("Failed to open file %d for upload job %d: %v", file.ID, job.ID, err)
+		failUpload(ctx, db, job, owner, models.FileErrorStorage, "The file could not be read")
+		return
+	}
+	payload, err := services.DecodeEEGPayload(f)
+	f.Close()
+	if err != nil {
+		log.Printf("File %d of upload job %d holds no EEG samples: %v", file.ID, job.ID, err)
+		failUpload(ctx, db, job, owner, models.FileErrorParse, "The recording could not be parsed: "+err.Error())
+		return
+	}
+	// Never send the ML service a recording that fails the sanity checks
+	if invalid := services.ValidateEEGData(payload); invalid != nil {
+		failUpload(ctx, db, job, owner, models.FileErrorValidation, invalid.Error())
+		return
+	}
+	summary := services.SummarizeEEGData(payload)
+	info := models.RecordingInfo{
+		ChannelCount:    summary.Channels,
+		SampleCount:     summary.Samples,
+		SamplingRateHz:  summary.SamplingRateHz,
+		DurationSeconds: summary.DurationSeconds,
+	}
+	if err := file.SetRecordingInfo(db, info); err != nil {
+		log.Printf("Failed to store recording info of file %d: %v", file.ID, err)
+		file.RecordingInfo = info
+	}
+	storePreview(db, file, payload)
+
+	var template *models.ReportTemplate
+	if job.TemplateID != nil {
+		if template, err = models.FindReportTemplate(db, *job.TemplateID); err != nil {
+			failUpload(ctx, db, job, owner, models.FileErrorProcessing, "Report template not found")
+			return
+		}
+	}
+	var notes map[string]interface{}
+	if len(job.Notes) > 0 {
+		if err := json.Unmarshal(job.Notes, &notes); err != nil {
+			failUpload(ctx, db, job, owner, models.FileErrorProcessing, "notes must be a JSON object")
+			return
+		}
+	}
+
+	// Measure signal quality so unreliable recordings can be flagged
+	quality := services.AnalyzeEEGQuality(payload.Eeg, payload.Msk, payload.Impedance)
+
+	// Translate via the ML server, unless the plan's translation quota was used up
+	description := ""
+	if job.Translate {
+		setFileState(db, file.ID, models.FileStateTranslating)
+		token, err := owner.GenerateJWT()
+		if err != nil {
+			log.Printf("Skipping translation for upload job %d: %v", job.ID, err)
+			recordFileError(db, file.ID, models.FileErrorTranslation, "Failed to authenticate with the translation service")
+		} else if description, err = services.TranslatePayload(ctx, address, "Bearer "+token, payload); err != nil {
+			log.Printf("Translation failed for upload job %d: %v", job.ID, err)
+			recordFileError(db, file.ID, translationErrorClass(err), err.Error())
+		}
+	}
+
+	// Clean up the ML output with the user's post-processors, keeping the raw text
+	rawDescription := description
+	if processed, err := postprocess.Translation(db, owner.ID, rawDescription); err != nil {
+		log.Printf("Storing unprocessed translation for upload job %d: %v", job.ID, err)
+	} else {
+		description = processed
+	}
+
+	// Users who opted in have their translated text stored encrypted
+	storedDescription, storedRaw, err := encryption.SealTranslation(ctx, db, owner.ID, description, rawDescription)
+	if err != nil {
+		log.Printf("Failed to encrypt translation for upload job %d: %v", job.ID, err)
+		failUpload(ctx, db, job, owner, models.FileErrorProcessing, "Failed to encrypt translation")
+		return
+	}
+
+	// Build the report from the samples already decoded instead of parsing
+	// the file again
+	if template != nil {
+		if notes == nil {
+			notes = payload.Notes
+		}
+		if err := template.ValidateNotes(notes); err != nil {
+			failUpload(ctx, db, job, owner, models.FileErrorProcessing, "Failed to convert file to report: "+err.Error())
+			return
+		}
+		if payload.Notes, err = template.PopulateNotes(notes); err != nil {
+			failUpload(ctx, db, job, owner, models.FileErrorProcessing, "Failed to convert file to report: "+err.Error())
+			return
+		}
+	}
+	content, err := services.ReportContent(payload)
+	if err != nil {
+		failUpload(ctx, db, job, owner, models.FileErrorProcessing, "Failed to convert file to report: "+err.Error())
+		return
+	}
+	file.Description = storedDescription
+	report := file.ConvertToReport(content, template)
+	report.RawDescription = storedRaw
+	report.MatchingScale = job.MatchingScale
+	report.Metadata = job.Metadata
+	report.DuplicateOfID = job.DuplicateOfID
+
+	var warnings []string
+	if job.DuplicateOfID != nil {
+		warnings = append(warnings, fmt.Sprintf("The same file was uploaded before as report %d", *job.DuplicateOfID))
+	}
+	if quality != nil {
+		if qualityJSON, err := json.Marshal(quality); err == nil {
+			report.Quality = datatypes.JSON(qualityJSON)
+		}
+		if quality.LowQuality {
+			warnings = append([]string{"Signal quality is too low for a reliable translation"}, quality.Warnings...)
+		}
+	}
+	if !job.Translate {
+		warnings = append(warnings, "Monthly translation quota exceeded; the file was stored without a translation")
+	}
+
+	var warningsJSON datatypes.JSON
+	if len(warnings) > 0 {
+		warningsJSON, _ = json.Marshal(warnings)
+	}
+
+	// Save the report, count the upload and complete the job together, so a
+	// job retried after a crash neither duplicates the report nor the usage
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if _, err := report.CreateReport(tx, owner.ID); err != nil {
+			return err
+		}
+		if err := plans.RecordUpload(tx, owner.ID, file.FileSize, description != ""); err != nil {
+			return fmt.Errorf("failed to record usage: %w", err)
+		}
+		return job.Complete(tx, report.ID, warningsJSON)
+	})
+	if errors.Is(err, models.ErrUploadJobTakenOver) {
+		log.Printf("Dropping result of upload job %d: %v", job.ID, err)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to save report for upload job %d: %v", job.ID, err)
+		failUpload(ctx, db, job, owner, models.FileErrorProcessing, "Failed to save report: "+err.Error())
+		return
+	}
+
+	// Screen the translation and alert linked clinicians if it is flagged
+	if err := moderation.Review(ctx, db, report, description); err != nil {
+		log.Printf("Failed to moderate report %d: %v", report.ID, err)
+	}
+
+	// Advance onboarding: the upload has been stored and turned into a report
+	for _, step := range []string{models.OnboardingFirstUpload, models.OnboardingFirstReport} {
+		if err := owner.CompleteOnboardingStep(db, step); err != nil {
+			log.Printf("Failed to record onboarding step %s for user %d: %v", step, owner.ID, err)
+		}
+	}
+
+	setFileState(db, file.ID, models.FileStateCompleted)
+	models.PublishFileEvent(db, job, models.FileEventReportReady)
+
+	body := fmt.Sprintf("Your recording %q has been processed into report %d.", file.Filename, report.ID)
+	if description == "" && job.Translate {
+		body += " It could not be translated this time."
+	}
+	if err := notifications.Notify(ctx, db, owner, "Your recording is ready", body, notifications.Channels{Push: true}); err != nil {
+		log.Printf("Failed to notify user %d of upload job %d: %v", owner.ID, job.ID, err)
+	}
+}
+
+// storePreview saves a downsampled waveform of the file's recording for the
+// UI, logging rather than failing when it cannot
+func storePreview(db *gorm.DB, file *models.SingleFile, payload *services.EEGData) {
+	preview := services.PreviewEEGData(payload, services.EEGPreviewPoints)
+	if preview == nil {
+		return
+	}
+	series, err := json.Marshal(preview.Series)
+	if err != nil {
+		log.Printf("Failed to encode preview of file %d: %v", file.ID, err)
+		return
+	}
+	err = models.SaveFilePreview(db, &models.FilePreview{
+		FileID:          file.ID,
+		Points:          preview.Points,
+		SamplesPerPoint: preview.SamplesPerPoint,
+		SamplingRateHz:  payload.SamplingRate,
+		Series:          series,
+	})
+	if err != nil {
+		log.Printf("Failed to store preview of file %d: %v", file.ID, err)
+	}
+}
+
+// failUpload records why a job failed, on the job and as a class of error on
+// its file, and tells the owner, if there still is one
+func failUpload(ctx context.Context, db *gorm.DB, job *models.UploadJob, owner *models.User, class, reason string) {
+	if err := job.Fail(db, reason); err != nil {
+		log.Printf("Failed to record failure of upload job %d: %v", job.ID, err)
+	}
+	recordFileError(db, job.FileID, class, reason)
+	setFileState(db, job.FileID, models.FileStateFailed)
+	models.PublishFileEvent(db, job, models.FileEventFailed)
+	if owner == nil {
+		return
+	}
+	body := "Your recording could not be processed: " + reason + ". You can upload it again."
+	if err := notifications.Notify(ctx, db, owner, "Your recording could not be processed", body, notifications.Channels{Push: true}); err != nil {
+		log.Printf("Failed to notify user %d of upload job %d: %v", owner.ID, job.ID, err)
+	}
+}
+
+// setFileState records the step a file reached, logging rather than failing
+// when the file is gone or already moved on
+func setFileState(db *gorm.DB, fileID uint, state string) {
+	if err := models.SetFileState(db, fileID, state); err != nil {
+		log.Printf("Failed to move file %d to state %s: %v", fileID, state, err)
+	}
+}
+
+// recordFileError stores why processing the file failed, logging rather than
+// failing when it cannot
+func recordFileError(db *gorm.DB, fileID uint, class, message string) {
+	if err := models.SetFileError(db, fileID, class, message); err != nil {
+		log.Printf("Failed to record error of file %d: %v", fileID, err)
+	}
+}
+
+// translationErrorClass classifies an error from translating a recording
+func translationErrorClass(err error) string {
+	switch {
+	case errors.Is(err, services.ErrInvalidEEGPayload):
+		return models.FileErrorParse
+	case errors.Is(err, services.ErrMLUnavailable):
+		return models.FileErrorMLUnavailable
+	}
+	return models.FileErrorTranslation
+}
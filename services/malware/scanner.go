@@ -0,0 +1,199 @@
+// Package malware scans uploaded files before they are processed, so infected
+// files are quarantined instead of being parsed or sent to the ML service.
+package malware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+)
+
+// Scanners for MALWARE_SCANNER
+const (
+	ScannerNone   = "none"
+	ScannerClamAV = "clamav"
+)
+
+// defaultQuarantineDir is where infected uploads are kept unless MALWARE_QUARANTINE_DIR is set
+const defaultQuarantineDir = "./quarantine"
+
+// Result is the verdict on one file
+type Result struct {
+	Infected bool
+	// Name of the signature that matched, e.g. Eicar-Signature
+	Signature string
+}
+
+// Scanner checks a file's bytes for malware
+type Scanner interface {
+	Name() string
+	Scan(ctx context.Context, r io.Reader) (Result, error)
+}
+
+var current atomic.Pointer[Scanner]
+
+// Configure installs the process-wide scanner; nil disables scanning. Call it at startup.
+func Configure(s Scanner) {
+	current.Store(&s)
+}
+
+// Current returns the process-wide scanner, or nil when uploads are not scanned
+func Current() Scanner {
+	if s := current.Load(); s != nil {
+		return *s
+	}
+	return nil
+}
+
+// FromEnv builds the scanner named by MALWARE_SCANNER: "none" (default)
+// disables scanning, "clamav" streams files to the clamd daemon at
+// CLAMAV_ADDRESS, waiting at most CLAMAV_TIMEOUT per file
+func FromEnv() (Scanner, error) {
+	switch name := utils.GetEnvWithDefault("MALWARE_SCANNER", ScannerNone); name {
+	case ScannerNone:
+		return nil, nil
+	case ScannerClamAV:
+		timeout, err := time.ParseDuration(utils.GetEnvWithDefault("CLAMAV_TIMEOUT", "30s"))
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("CLAMAV_TIMEOUT must be a positive duration such as 30s")
+		}
+		return NewClamAV(utils.GetEnvWithDefault("CLAMAV_ADDRESS", "tcp://localhost:3310"), timeout)
+	default:
+		return nil, fmt.Errorf("MALWARE_SCANNER must be %q or %q, got %q", ScannerNone, ScannerClamAV, name)
+	}
+}
+
+// QuarantineDir returns the directory infected uploads are moved to, set by MALWARE_QUARANTINE_DIR
+func QuarantineDir() string {
+	return utils.GetEnvWithDefault("MALWARE_QUARANTINE_DIR", defaultQuarantineDir)
+}
+
+// clamavChunkSize is how much of the file goes into one INSTREAM chunk
+const clamavChunkSize = 64 << 10
+
+// ClamAV scans files with a clamd daemon over its INSTREAM command
+type ClamAV struct {
+	network, address string
+	timeout          time.Duration
+}
+
+// NewClamAV creates a scanner for the clamd daemon at address, given as
+// tcp://host:port or unix:///path/to/clamd.sock
+func NewClamAV(address string, timeout time.Duration) (*ClamAV, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("CLAMAV_ADDRESS: %w", err)
+	}
+	switch {
+	case u.Scheme == "tcp" && u.Host != "":
+		return &ClamAV{network: "tcp", address: u.Host, timeout: timeout}, nil
+	case u.Scheme == "unix" && u.Path != "":
+		return &ClamAV{network: "unix", address: u.Path, timeout: timeout}, nil
+	}
+	return nil, fmt.Errorf("CLAMAV_ADDRESS must be tcp://host:port or unix:///path, got %q", address)
+}
+
+// Name identifies the scanner
+func (s *ClamAV) Name() string {
+	return ScannerClamAV
+}
+
+// Address returns the daemon's address, e.g. localhost:3310
+func (s *ClamAV) Address() string {
+	return s.address
+}
+
+// Scan streams r to clamd and reads its verdict
+func (s *ClamAV) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	reply, err := s.command(ctx, "INSTREAM", func(conn net.Conn) error {
+		buf := make([]byte, 4+clamavChunkSize)
+		for {
+			n, err := io.ReadFull(r, buf[4:])
+			if n > 0 {
+				binary.BigEndian.PutUint32(buf[:4], uint32(n))
+				if _, werr := conn.Write(buf[:4+n]); werr != nil {
+					return werr
+				}
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("read file: %w", err)
+			}
+		}
+		// A zero-length chunk ends the stream
+		_, err := conn.Write([]byte{0, 0, 0, 0})
+		return err
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return parseClamAVReply(reply)
+}
+
+// Ping checks that the daemon answers
+func (s *ClamAV) Ping(ctx context.Context) error {
+	reply, err := s.command(ctx, "PING", nil)
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("clamav: unexpected reply %q", reply)
+	}
+	return nil
+}
+
+// command sends a null-terminated clamd command, then whatever send writes,
+// and returns the daemon's one-line reply
+func (s *ClamAV) command(ctx context.Context, name string, send func(conn net.Conn) error) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return "", fmt.Errorf("clamav: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write([]byte("z" + name + "\x00")); err != nil {
+		return "", fmt.Errorf("clamav: %w", err)
+	}
+	if send != nil {
+		if err := send(conn); err != nil {
+			return "", fmt.Errorf("clamav: %w", err)
+		}
+	}
+	reply, err := bufio.NewReader(conn).ReadBytes(0)
+	if err != nil && len(reply) == 0 {
+		return "", fmt.Errorf("clamav: %w", err)
+	}
+	return string(bytes.TrimRight(reply, "\x00\n")), nil
+}
+
+// parseClamAVReply reads a verdict such as "stream: OK" or
+// "stream: Eicar-Signature FOUND"
+func parseClamAVReply(reply string) (Result, error) {
+	verdict := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case verdict == "OK":
+		return Result{}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return Result{Infected: true, Signature: strings.TrimSuffix(verdict, " FOUND")}, nil
+	}
+	return Result{}, fmt.Errorf("clamav: %s", reply)
+}
@@ -0,0 +1,164 @@
+package mlexport
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/encryption"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// batchSize bounds how many reports are loaded at once while streaming
+const batchSize = 200
+
+// Options selects the reports to export
+type Options struct {
+	Since      *time.Time
+	Until      *time.Time
+	Limit      int
+	IncludeEEG bool
+	// Key for the pseudonymous IDs; the same key yields the same IDs across exports
+	PseudonymKey []byte
+}
+
+// Record is one anonymized model-feedback pair, written as a JSON line
+type Record struct {
+	RecordID             string          `json:"record_id"`
+	SubjectID            string          `json:"subject_id"`
+	EEGRef               string          `json:"eeg_ref"`
+	RecordedMonth        string          `json:"recorded_month"`
+	ContentSchemaVersion int             `json:"content_schema_version"`
+	Translation          string          `json:"translation"`
+	Correction           string          `json:"correction"`
+	MatchingScale        int             `json:"matching_scale"`
+	EEG                  json.RawMessage `json:"eeg,omitempty" swaggertype:"object"`
+}
+
+// Scrubbers replacing direct identifiers that users may have typed into corrections
+var scrubbers = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), "[EMAIL]"},
+	{regexp.MustCompile(`https?://\S+`), "[URL]"},
+	{regexp.MustCompile(`\+?\d[\d\s().-]{6,}\d`), "[NUMBER]"},
+}
+
+// scrubText removes email addresses, links and phone-like numbers
+func scrubText(text string) string {
+	for _, s := range scrubbers {
+		text = s.pattern.ReplaceAllString(text, s.replacement)
+	}
+	return text
+}
+
+// pseudonym derives a stable, non-reversible ID
+func pseudonym(key []byte, kind string, id uint) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(kind + ":" + strconv.FormatUint(uint64(id), 10)))
+	return hex.EncodeToString(mac.Sum(nil))[:24]
+}
+
+// eegOnly keeps only the signal fields of report content, dropping anything else
+// an upload may have carried
+func eegOnly(content datatypes.JSON) (json.RawMessage, error) {
+	var decoded map[string]json.RawMessage
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		return nil, err
+	}
+	kept := map[string]json.RawMessage{}
+	for _, field := range []string{"eeg", "mask", "impedance"} {
+		if value, ok := decoded[field]; ok {
+			kept[field] = value
+		}
+	}
+	return json.Marshal(kept)
+}
+
+// Write streams anonymized feedback pairs as JSON lines and returns how many
+// were written. Only reports with both a translation and a user correction are
+// exported; reports of users who opted in to report text encryption are skipped.
+func Write(ctx context.Context, db *gorm.DB, w io.Writer, opts Options) (int, error) {
+	if len(opts.PseudonymKey) == 0 {
+		return 0, fmt.Errorf("pseudonym key is required")
+	}
+
+	query := db.Model(&models.Report{}).
+		Joins("JOIN users ON users.id = reports.user_id AND users.deleted_at IS NULL AND users.encrypt_report_text = false").
+		Where("reports.description <> '' AND reports.correction <> ''")
+	if opts.Since != nil {
+		query = query.Where("reports.created_at >= ?", *opts.Since)
+	}
+	if opts.Until != nil {
+		query = query.Where("reports.created_at < ?", *opts.Until)
+	}
+
+	encoder := json.NewEncoder(w)
+	written := 0
+	var reports []models.Report
+	err := query.Select("reports.*").FindInBatches(&reports, batchSize, func(tx *gorm.DB, batch int) error {
+		for _, report := range reports {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if opts.Limit > 0 && written >= opts.Limit {
+				return errLimitReached
+			}
+			// Guard against text encrypted before the user turned encryption off
+			if encryption.IsEncrypted(report.Description) || encryption.IsEncrypted(report.RawDescription) || encryption.IsEncrypted(report.Correction) {
+				continue
+			}
+			// The model is judged on its own output, before post-processing
+			translation := report.RawDescription
+			if translation == "" {
+				translation = report.Description
+			}
+
+			record := Record{
+				RecordID:             pseudonym(opts.PseudonymKey, "report", report.ID),
+				SubjectID:            pseudonym(opts.PseudonymKey, "user", report.UserID),
+				RecordedMonth:        report.CreatedAt.UTC().Format("2006-01"),
+				ContentSchemaVersion: report.ContentSchemaVersion,
+				Translation:          scrubText(translation),
+				Correction:           scrubText(report.Correction),
+				MatchingScale:        report.MatchingScale,
+			}
+			record.EEGRef = "eeg:" + record.RecordID
+			if opts.IncludeEEG {
+				if err := report.LoadArchivedContent(ctx); err != nil {
+					return err
+				}
+			}
+			if opts.IncludeEEG && len(report.Content) > 0 && string(report.Content) != "null" {
+				eeg, err := eegOnly(report.Content)
+				if err != nil {
+					continue
+				}
+				record.EEG = eeg
+			}
+
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+			written++
+		}
+		return nil
+	}).Error
+	if err == errLimitReached {
+		err = nil
+	}
+	return written, err
+}
+
+// errLimitReached stops batching once the requested number of records is written
+var errLimitReached = fmt.Errorf("export limit reached")
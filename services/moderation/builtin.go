@@ -0,0 +1,50 @@
+package moderation
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+)
+
+// selfHarmKeywordModerator flags phrases that suggest self-harm or suicidal
+// thoughts, plus the organization's own keywords. Phrases of every language
+// are checked since translations do not always follow the owner's locale.
+type selfHarmKeywordModerator struct{}
+
+func (selfHarmKeywordModerator) Name() string { return SelfHarmKeywords }
+
+// selfHarmPhrases are matched on whole words after normalization; keep them
+// specific, as every match alerts a clinician
+var selfHarmPhrases = []string{
+	// en
+	"suicide", "suicidal", "kill myself", "end my life", "take my own life", "want to die",
+	"hurt myself", "harm myself", "self harm", "cut myself", "better off dead", "no reason to live",
+	// de
+	"selbstmord", "suizid", "mich umbringen", "mir das leben nehmen", "nicht mehr leben", "mich verletzen",
+	// fr
+	"me suicider", "me tuer", "en finir", "mettre fin à mes jours", "me faire du mal",
+	// es
+	"suicidio", "suicidarme", "matarme", "quitarme la vida", "hacerme daño",
+}
+
+func (selfHarmKeywordModerator) Check(text, locale string, policy Policy) []string {
+	normalized := " " + normalize(text) + " "
+	for _, phrases := range [][]string{selfHarmPhrases, policy.Keywords} {
+		for _, phrase := range phrases {
+			phrase = normalize(phrase)
+			if phrase != "" && strings.Contains(normalized, " "+phrase+" ") {
+				return []string{models.ModerationSelfHarm}
+			}
+		}
+	}
+	return nil
+}
+
+// normalize lower-cases text and collapses everything but letters and digits
+// to single spaces, so "Self-harm." matches "self harm"
+func normalize(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
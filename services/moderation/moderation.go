@@ -0,0 +1,104 @@
+// Package moderation screens translated text for content a clinician should
+// see, such as signs of self-harm. Flagged reports are marked for review and
+// the owner's linked clinicians are alerted. Moderators are pluggable and
+// configured per organization.
+package moderation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Moderator inspects translated text and returns the categories it flags.
+// locale is the owner's BCP 47 locale; policy carries organization settings
+// such as extra keywords.
+type Moderator interface {
+	Name() string
+	Check(text, locale string, policy Policy) []string
+}
+
+// Built-in moderator names
+const (
+	SelfHarmKeywords = "self_harm_keywords"
+)
+
+// DefaultModerators run for organizations that have not chosen any
+var DefaultModerators = []string{SelfHarmKeywords}
+
+var (
+	mu         sync.RWMutex
+	moderators = map[string]Moderator{}
+)
+
+func init() {
+	Register(selfHarmKeywordModerator{})
+}
+
+// Register adds a moderator, replacing any registered under the same name
+func Register(m Moderator) {
+	mu.Lock()
+	defer mu.Unlock()
+	moderators[m.Name()] = m
+}
+
+// Available lists the registered moderator names
+func Available() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return available()
+}
+
+// Validate checks that every name is a registered moderator and none repeats
+func Validate(names []string) error {
+	mu.RLock()
+	defer mu.RUnlock()
+	seen := map[string]bool{}
+	for _, name := range names {
+		if _, ok := moderators[name]; !ok {
+			return fmt.Errorf("unknown moderator %q; available: %s", name, strings.Join(available(), ", "))
+		}
+		if seen[name] {
+			return fmt.Errorf("moderator %q is listed twice", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// available lists the registered names; callers hold mu
+func available() []string {
+	names := make([]string, 0, len(moderators))
+	for name := range moderators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Finding is a category flagged by one moderator
+type Finding struct {
+	Moderator string
+	Category  string
+}
+
+// Run applies the policy's moderators to text. Unknown names are skipped.
+func Run(policy Policy, text, locale string) []Finding {
+	if !policy.Enabled || strings.TrimSpace(text) == "" {
+		return nil
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	var findings []Finding
+	for _, name := range policy.Moderators {
+		m, ok := moderators[name]
+		if !ok {
+			continue
+		}
+		for _, category := range m.Check(text, locale, policy) {
+			findings = append(findings, Finding{Moderator: name, Category: category})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,166 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/notifications"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Policy is the effective moderation configuration for a user
+type Policy struct {
+	Enabled         bool
+	Moderators      []string
+	Keywords        []string
+	AlertClinicians bool
+	AlertEmail      bool
+}
+
+// DefaultPolicy applies to users outside an organization and to
+// organizations without a policy of their own
+func DefaultPolicy() Policy {
+	return Policy{
+		Enabled:         true,
+		Moderators:      DefaultModerators,
+		AlertClinicians: true,
+		AlertEmail:      true,
+	}
+}
+
+// EncodeModerators validates moderator names and encodes them for storage.
+// A nil list encodes to nil, meaning the defaults apply.
+func EncodeModerators(names []string) (datatypes.JSON, error) {
+	if names == nil {
+		return nil, nil
+	}
+	if err := Validate(names); err != nil {
+		return nil, err
+	}
+	return encodeList(names)
+}
+
+// EncodeKeywords trims and validates extra keywords and encodes them for storage
+func EncodeKeywords(keywords []string) (datatypes.JSON, error) {
+	if len(keywords) == 0 {
+		return nil, nil
+	}
+	cleaned := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		if normalize(keyword) == "" {
+			return nil, fmt.Errorf("keywords must contain letters or digits")
+		}
+		cleaned = append(cleaned, strings.TrimSpace(keyword))
+	}
+	return encodeList(cleaned)
+}
+
+func encodeList(values []string) (datatypes.JSON, error) {
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	return datatypes.JSON(encoded), nil
+}
+
+// decodeList returns the stored values and whether any were set
+func decodeList(stored datatypes.JSON) ([]string, bool) {
+	if len(stored) == 0 || string(stored) == "null" {
+		return nil, false
+	}
+	var values []string
+	if err := json.Unmarshal(stored, &values); err != nil {
+		return nil, false
+	}
+	return values, true
+}
+
+// Effective returns the moderation policy for the user's translations: their
+// organization's, else DefaultPolicy
+func Effective(db *gorm.DB, user *models.User) (Policy, error) {
+	policy := DefaultPolicy()
+	if user.OrganizationID == nil {
+		return policy, nil
+	}
+	stored, err := models.FindOrgModerationPolicy(db, *user.OrganizationID)
+	if err != nil {
+		return policy, fmt.Errorf("database error: %w", err)
+	}
+	if stored == nil {
+		return policy, nil
+	}
+
+	policy.Enabled = stored.Enabled
+	if names, ok := decodeList(stored.Moderators); ok {
+		policy.Moderators = names
+	}
+	policy.Keywords, _ = decodeList(stored.Keywords)
+	policy.AlertClinicians = stored.AlertClinicians
+	policy.AlertEmail = stored.AlertEmail
+	return policy, nil
+}
+
+// Review moderates a report's new translation. text is the plain translated
+// text; the stored description may be encrypted. Flagged reports are marked
+// and, if the policy asks for it, the owner's linked clinicians are alerted.
+func Review(ctx context.Context, db *gorm.DB, report *models.Report, text string) error {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	owner, err := models.FindUserByID(db, report.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch user: %w", err)
+	}
+	policy, err := Effective(db, owner)
+	if err != nil {
+		return err
+	}
+
+	findings := Run(policy, text, owner.Locale)
+	if len(findings) == 0 {
+		return nil
+	}
+	flags := make([]models.ModerationFlag, len(findings))
+	categories := make([]string, 0, len(findings))
+	seen := map[string]bool{}
+	for i, finding := range findings {
+		flags[i] = models.ModerationFlag{Moderator: finding.Moderator, Category: finding.Category}
+		if !seen[finding.Category] {
+			seen[finding.Category] = true
+			categories = append(categories, strings.ReplaceAll(finding.Category, "_", "-"))
+		}
+	}
+	if err := report.Flag(db, flags); err != nil {
+		return fmt.Errorf("failed to flag report: %w", err)
+	}
+	log.Printf("Report %d was flagged by moderation: %s", report.ID, strings.Join(categories, ", "))
+
+	if !policy.AlertClinicians {
+		return nil
+	}
+	return alertClinicians(ctx, db, owner, report, categories, policy.AlertEmail)
+}
+
+// alertClinicians notifies the clinicians linked to the owner of a flagged
+// report. The alert names the report but never includes its text.
+func alertClinicians(ctx context.Context, db *gorm.DB, owner *models.User, report *models.Report, categories []string, sendEmail bool) error {
+	clinicians, err := models.FindLinkedViewers(db, owner.ID, models.LinkRelationshipClinician)
+	if err != nil {
+		return err
+	}
+
+	title := "Report flagged for review"
+	body := fmt.Sprintf("A new report from %s (report %d) was flagged for possible %s content. Please review it.",
+		owner.Name, report.ID, strings.Join(categories, " and "))
+	for i := range clinicians {
+		if err := notifications.Notify(ctx, db, &clinicians[i], title, body, notifications.Channels{Email: sendEmail, Push: true}); err != nil {
+			log.Printf("Failed to alert clinician %d about report %d: %v", clinicians[i].ID, report.ID, err)
+		}
+	}
+	return nil
+}
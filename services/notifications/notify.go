@@ -0,0 +1,128 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/email"
+	"gorm.io/gorm"
+)
+
+// recipientBatchSize bounds how many users are loaded and notified at once
+const recipientBatchSize = 500
+
+// Channels selects the optional delivery channels besides in-app
+type Channels struct {
+	Email bool
+	Push  bool
+}
+
+// Notify sends a single user an in-app notification and, optionally, an email and push
+func Notify(ctx context.Context, db *gorm.DB, user *models.User, title, body string, channels Channels) error {
+	if err := models.CreateNotifications(db, []models.Notification{{UserID: user.ID, Title: title, Body: body}}); err != nil {
+		return fmt.Errorf("failed to store notification: %w", err)
+	}
+	if channels.Email {
+		if err := email.Enqueue(db, user.Email, title, body, "transactional"); err != nil {
+			log.Printf("Failed to queue notification email for user %d: %v", user.ID, err)
+		}
+	}
+	if channels.Push {
+		if err := CurrentPusher().Push(ctx, user.ID, title, body); err != nil {
+			log.Printf("Failed to push notification to user %d: %v", user.ID, err)
+		}
+	}
+	return nil
+}
+
+// SendDueBroadcasts delivers every broadcast whose scheduled time has passed
+func SendDueBroadcasts(ctx context.Context, db *gorm.DB) error {
+	for ctx.Err() == nil {
+		broadcast, err := models.ClaimDueBroadcast(db, time.Now())
+		if err != nil {
+			return err
+		}
+		if broadcast == nil {
+			return nil
+		}
+		deliverBroadcast(ctx, db, broadcast)
+	}
+	return nil
+}
+
+// deliverBroadcast sends a claimed broadcast to its audience in batches and
+// records the delivery stats on the broadcast
+func deliverBroadcast(ctx context.Context, db *gorm.DB, b *models.Broadcast) {
+	query, err := models.BroadcastRecipients(db, b)
+	if err != nil {
+		finishBroadcast(db, b, err)
+		return
+	}
+
+	var users []models.User
+	result := query.Select("id", "email").FindInBatches(&users, recipientBatchSize, func(tx *gorm.DB, batch int) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		notifications := make([]models.Notification, len(users))
+		for i, user := range users {
+			notifications[i] = models.Notification{UserID: user.ID, BroadcastID: &b.ID, Title: b.Title, Body: b.Body}
+		}
+		if err := models.CreateNotifications(db, notifications); err != nil {
+			return err
+		}
+		b.Recipients += len(users)
+		b.InAppDelivered += len(users)
+
+		for _, user := range users {
+			if b.SendEmail {
+				if err := email.Enqueue(db, user.Email, b.Title, b.Body, "marketing"); err != nil {
+					log.Printf("Failed to queue broadcast %d email for user %d: %v", b.ID, user.ID, err)
+				} else {
+					b.EmailsQueued++
+				}
+			}
+			if b.SendPush {
+				if err := CurrentPusher().Push(ctx, user.ID, b.Title, b.Body); err != nil {
+					b.PushFailed++
+				} else {
+					b.PushSent++
+				}
+			}
+		}
+		return nil
+	})
+
+	finishBroadcast(db, b, result.Error)
+}
+
+// finishBroadcast stores the final status and stats of a broadcast
+func finishBroadcast(db *gorm.DB, b *models.Broadcast, deliveryErr error) {
+	now := time.Now()
+	b.SentAt = &now
+	b.Status = models.BroadcastSent
+	if deliveryErr != nil {
+		b.Status = models.BroadcastFailed
+		b.LastError = deliveryErr.Error()
+		log.Printf("Broadcast %d failed after %d recipients: %v", b.ID, b.Recipients, deliveryErr)
+	} else {
+		log.Printf("Broadcast %d delivered to %d recipients", b.ID, b.Recipients)
+	}
+
+	if err := db.Model(b).Updates(map[string]interface{}{
+		"status":           b.Status,
+		"sent_at":          b.SentAt,
+		"recipients":       b.Recipients,
+		"in_app_delivered": b.InAppDelivered,
+		"emails_queued":    b.EmailsQueued,
+		"push_sent":        b.PushSent,
+		"push_failed":      b.PushFailed,
+		"last_error":       b.LastError,
+	}).Error; err != nil {
+		log.Printf("Failed to record broadcast %d stats: %v", b.ID, err)
+	}
+}
@@ -0,0 +1,42 @@
+package notifications
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// Pusher delivers a push notification to a user's devices
+type Pusher interface {
+	Name() string
+	Push(ctx context.Context, userID uint, title, body string) error
+}
+
+// logPusher is used until a real push provider is configured; it only logs
+type logPusher struct{}
+
+func (logPusher) Name() string { return "log" }
+
+func (logPusher) Push(ctx context.Context, userID uint, title, body string) error {
+	log.Printf("Push to user %d: %s", userID, title)
+	return nil
+}
+
+var (
+	pusherMu sync.RWMutex
+	pusher   Pusher = logPusher{}
+)
+
+// SetPusher replaces the push provider
+func SetPusher(p Pusher) {
+	pusherMu.Lock()
+	defer pusherMu.Unlock()
+	pusher = p
+}
+
+// CurrentPusher returns the configured push provider
+func CurrentPusher() Pusher {
+	pusherMu.RLock()
+	defer pusherMu.RUnlock()
+	return pusher
+}
@@ -0,0 +1,316 @@
+// Package pdf writes simple text documents as PDF using the standard
+// Helvetica fonts, which every PDF reader provides, so no fonts are embedded.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// A4 page geometry in points
+const (
+	pageWidth    = 595
+	pageHeight   = 842
+	margin       = 56
+	contentWidth = pageWidth - 2*margin
+	footerSize   = 8
+)
+
+// Font styles
+const (
+	Regular = iota
+	Bold
+)
+
+// Document is a text document laid out top to bottom over A4 pages
+type Document struct {
+	pages     []*bytes.Buffer
+	y         float64 // baseline of the next line on the current page
+	footer    string
+	images    []pdfImage
+	textColor string // fill color operator for text; empty is black
+	ruleColor string // stroke color operator for rules; empty is grey
+}
+
+// New creates an empty document. The footer is printed on every page
+// together with the page number.
+func New(footer string) *Document {
+	d := &Document{footer: footer}
+	d.newPage()
+	return d
+}
+
+func (d *Document) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pageHeight - margin
+}
+
+// Text writes text in the given style and size, wrapped to the page width.
+// Newlines start new lines; blank lines are kept.
+func (d *Document) Text(text string, style int, size float64) {
+	for _, paragraph := range strings.Split(text, "\n") {
+		lines := wrap(encode(paragraph), style, size, contentWidth)
+		if len(lines) == 0 {
+			d.ensureSpace(size)
+			d.y -= lineHeight(size)
+			continue
+		}
+		for _, line := range lines {
+			d.ensureSpace(size)
+			d.show(margin, line, style, size)
+			d.y -= lineHeight(size)
+		}
+	}
+}
+
+// Field writes a bold label followed by its value, with wrapped lines of the
+// value indented past the label
+func (d *Document) Field(label, value string, size float64) {
+	label += ": "
+	labelWidth := textWidth(encode(label), Bold, size)
+	lines := wrap(encode(value), Regular, size, contentWidth-labelWidth)
+	if len(lines) == 0 {
+		lines = [][]byte{nil}
+	}
+	for i, line := range lines {
+		d.ensureSpace(size)
+		if i == 0 {
+			d.show(margin, encode(label), Bold, size)
+		}
+		d.show(margin+labelWidth, line, Regular, size)
+		d.y -= lineHeight(size)
+	}
+}
+
+// PageBreak continues on a new page unless the current page is still empty
+func (d *Document) PageBreak() {
+	if d.page().Len() > 0 {
+		d.newPage()
+	}
+}
+
+// Space adds vertical space
+func (d *Document) Space(points float64) {
+	d.y -= points
+}
+
+// Rule draws a horizontal line across the page
+func (d *Document) Rule() {
+	d.ensureSpace(4)
+	color := d.ruleColor
+	if color == "" {
+		color = "0.7 G"
+	}
+	fmt.Fprintf(d.page(), "%s 0.5 w %d %.2f m %d %.2f l S 0 G\n", color, margin, d.y+4, pageWidth-margin, d.y+4)
+	d.y -= 8
+}
+
+// ensureSpace starts a new page if a line of the given size does not fit
+func (d *Document) ensureSpace(size float64) {
+	if d.y-size < margin+2*footerSize {
+		d.newPage()
+	}
+}
+
+func (d *Document) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+func (d *Document) show(x float64, text []byte, style int, size float64) {
+	if d.textColor != "" {
+		fmt.Fprintf(d.page(), "%s BT /F%d %.1f Tf %.2f %.2f Td (%s) Tj ET 0 g\n", d.textColor, style+1, size, x, d.y, escape(text))
+		return
+	}
+	fmt.Fprintf(d.page(), "BT /F%d %.1f Tf %.2f %.2f Td (%s) Tj ET\n", style+1, size, x, d.y, escape(text))
+}
+
+// WriteTo writes the document as a PDF file
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	out := &countingWriter{w: w}
+	var offsets []int64
+	object := func(body string) {
+		offsets = append(offsets, out.n)
+		fmt.Fprintf(out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// Objects 1-4 are the catalog, page tree and fonts, followed by the
+	// images; each page then adds a page object followed by its content stream
+	fmt.Fprint(out, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	firstPage := 5 + len(d.images)
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	resources := "/Font << /F1 3 0 R /F2 4 0 R >>"
+	if len(d.images) > 0 {
+		names := make([]string, len(d.images))
+		for i, img := range d.images {
+			names[i] = fmt.Sprintf("/Im%d %d 0 R", i+1, 5+i)
+			object(fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode /Length %d >>\nstream\n%s\nendstream",
+				img.width, img.height, len(img.data), img.data))
+		}
+		resources += " /XObject << " + strings.Join(names, " ") + " >>"
+	}
+
+	for i, page := range d.pages {
+		content := page.Bytes()
+		footer := fmt.Sprintf("Page %d of %d", i+1, len(d.pages))
+		if d.footer != "" {
+			footer = d.footer + "  |  " + footer
+		}
+		// Long footers wrap downwards into the bottom margin
+		for j, line := range wrap(encode(footer), Regular, footerSize, contentWidth) {
+			y := float64(margin-footerSize) - float64(j)*lineHeight(footerSize)
+			content = append(content, fmt.Sprintf("0.4 g BT /F1 %d Tf %d %.2f Td (%s) Tj ET 0 g\n", footerSize, margin, y, escape(line))...)
+		}
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << %s >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, resources, firstPage+1+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	xref := out.n
+	fmt.Fprintf(out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.n, out.err
+}
+
+// countingWriter tracks the bytes written for the cross-reference table and
+// keeps the first error so writes can be chained
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
+
+// encode converts text to the WinAnsi encoding used by the standard fonts;
+// characters it cannot represent become '?'
+func encode(text string) []byte {
+	out := make([]byte, 0, len(text))
+	for _, r := range text {
+		if r == '\t' {
+			r = ' '
+		}
+		if c, ok := charmap.Windows1252.EncodeRune(r); ok && c >= 32 {
+			out = append(out, c)
+		} else {
+			out = append(out, '?')
+		}
+	}
+	return out
+}
+
+// escape escapes a PDF literal string
+func escape(text []byte) []byte {
+	var out bytes.Buffer
+	for _, c := range text {
+		switch c {
+		case '\\', '(', ')':
+			out.WriteByte('\\')
+			out.WriteByte(c)
+		default:
+			if c >= 128 {
+				fmt.Fprintf(&out, "\\%03o", c)
+			} else {
+				out.WriteByte(c)
+			}
+		}
+	}
+	return out.Bytes()
+}
+
+// wrap breaks text into lines no wider than width, splitting words that are
+// wider than a line on their own
+func wrap(text []byte, style int, size, width float64) [][]byte {
+	var lines [][]byte
+	var line []byte
+	for _, word := range bytes.Fields(text) {
+		candidate := word
+		if len(line) > 0 {
+			candidate = append(append(append([]byte{}, line...), ' '), word...)
+		}
+		if textWidth(candidate, style, size) <= width {
+			line = candidate
+			continue
+		}
+		if len(line) > 0 {
+			lines = append(lines, line)
+			line = nil
+		}
+		for textWidth(word, style, size) > width {
+			cut := 1
+			for cut < len(word) && textWidth(word[:cut+1], style, size) <= width {
+				cut++
+			}
+			lines = append(lines, word[:cut])
+			word = word[cut:]
+		}
+		line = word
+	}
+	if len(line) > 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func lineHeight(size float64) float64 {
+	return size * 1.35
+}
+
+// textWidth measures WinAnsi-encoded text in points
+func textWidth(text []byte, style int, size float64) float64 {
+	widths := &helveticaWidths
+	if style == Bold {
+		widths = &helveticaBoldWidths
+	}
+	var units int
+	for _, c := range text {
+		if c >= 32 && c <= 126 {
+			units += widths[c-32]
+		} else {
+			units += 556
+		}
+	}
+	return float64(units) * size / 1000
+}
+
+// Glyph widths of printable ASCII in thousandths of the font size
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
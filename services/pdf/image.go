@@ -0,0 +1,101 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	_ "image/jpeg" // register the JPEG decoder
+	_ "image/png"  // register the PNG decoder
+	"strconv"
+)
+
+// pdfImage is an image XObject: 8-bit RGB samples, zlib compressed
+type pdfImage struct {
+	width, height int
+	data          []byte
+}
+
+// AddImage embeds a PNG or JPEG image once so it can be drawn any number of
+// times with DrawImage, and returns its ID. Transparent areas are drawn on white.
+func (d *Document) AddImage(data []byte) (int, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode image: %w", err)
+	}
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return 0, fmt.Errorf("image is empty")
+	}
+
+	var samples bytes.Buffer
+	z := zlib.NewWriter(&samples)
+	row := make([]byte, 0, 3*bounds.Dx())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row = row[:0]
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			// Colors are alpha-premultiplied, so adding the uncovered part of
+			// white composites them onto a white background
+			r, g, b, a := img.At(x, y).RGBA()
+			white := 0xffff - a
+			row = append(row, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+		}
+		z.Write(row)
+	}
+	if err := z.Close(); err != nil {
+		return 0, err
+	}
+
+	d.images = append(d.images, pdfImage{width: bounds.Dx(), height: bounds.Dy(), data: samples.Bytes()})
+	return len(d.images), nil
+}
+
+// DrawImage draws an image added with AddImage at the left margin, scaled
+// down to fit within maxWidth by maxHeight points
+func (d *Document) DrawImage(id int, maxWidth, maxHeight float64) {
+	img := d.images[id-1]
+	width, height := float64(img.width), float64(img.height)
+	scale := 1.0
+	if width*scale > maxWidth {
+		scale = maxWidth / width
+	}
+	if height*scale > maxHeight {
+		scale = maxHeight / height
+	}
+	width, height = width*scale, height*scale
+
+	d.ensureSpace(height)
+	d.y -= height
+	fmt.Fprintf(d.page(), "q %.2f 0 0 %.2f %d %.2f cm /Im%d Do Q\n", width, height, margin, d.y, id)
+	d.y -= 8
+}
+
+// SetTextColor sets the color of the text written after it as #RRGGBB; an
+// empty color restores black
+func (d *Document) SetTextColor(hex string) {
+	d.textColor = ""
+	if r, g, b, ok := parseColor(hex); ok {
+		d.textColor = fmt.Sprintf("%.3f %.3f %.3f rg", r, g, b)
+	}
+}
+
+// SetRuleColor sets the color of the rules drawn after it as #RRGGBB; an
+// empty color restores grey
+func (d *Document) SetRuleColor(hex string) {
+	d.ruleColor = ""
+	if r, g, b, ok := parseColor(hex); ok {
+		d.ruleColor = fmt.Sprintf("%.3f %.3f %.3f RG", r, g, b)
+	}
+}
+
+// parseColor converts #RRGGBB to PDF color components between 0 and 1
+func parseColor(hex string) (r, g, b float64, ok bool) {
+	if len(hex) != 7 || hex[0] != '#' {
+		return 0, 0, 0, false
+	}
+	value, err := strconv.ParseUint(hex[1:], 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return float64(value>>16&0xff) / 255, float64(value>>8&0xff) / 255, float64(value&0xff) / 255, true
+}
@@ -0,0 +1,138 @@
+package pdf
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+)
+
+// Branding customizes the look of a report for the patient's organization.
+// Empty fields keep the ThinkInk defaults.
+type Branding struct {
+	// Name replaces "ThinkInk" in the title and footer
+	Name string
+	// Colors as #RRGGBB for the title and the rules
+	PrimaryColor string
+	AccentColor  string
+	// FooterText is printed in front of every page's footer
+	FooterText string
+	// Logo is a PNG or JPEG drawn above the title
+	Logo []byte
+}
+
+// maxLogoHeight bounds the logo at the top of a report in points
+const maxLogoHeight = 48
+
+// WriteReport writes a report as a PDF for sharing with doctors: the patient's
+// details, the translated text, the matching scale and timestamps in the
+// patient's time zone and locale. The report text must already be decrypted.
+// A nil branding uses the ThinkInk defaults.
+func WriteReport(w io.Writer, report *models.Report, patient *models.User, branding *Branding) (int64, error) {
+	doc, err := newReports(patient, branding, "report "+strconv.FormatUint(uint64(report.ID), 10))
+	if err != nil {
+		return 0, err
+	}
+	doc.Add(report)
+	return doc.WriteTo(w)
+}
+
+// Reports is a PDF of several of a patient's reports, each starting on a new
+// page laid out like WriteReport
+type Reports struct {
+	doc      *Document
+	patient  *models.User
+	branding *Branding
+	name     string
+	logo     int // image ID of the logo, 0 without one
+}
+
+// NewReports starts a PDF of the patient's reports. A nil branding uses the
+// ThinkInk defaults.
+func NewReports(patient *models.User, branding *Branding) (*Reports, error) {
+	return newReports(patient, branding, "reports")
+}
+
+func newReports(patient *models.User, branding *Branding, subject string) (*Reports, error) {
+	if branding == nil {
+		branding = &Branding{}
+	}
+	name := branding.Name
+	if name == "" {
+		name = "ThinkInk"
+	}
+	footer := name + " " + subject + "  |  Generated " + patient.FormatTimestamp(time.Now())
+	if branding.FooterText != "" {
+		footer = branding.FooterText + "  |  " + footer
+	}
+
+	r := &Reports{doc: New(footer), patient: patient, branding: branding, name: name}
+	r.doc.SetRuleColor(branding.AccentColor)
+	if len(branding.Logo) > 0 {
+		logo, err := r.doc.AddImage(branding.Logo)
+		if err != nil {
+			return nil, err
+		}
+		r.logo = logo
+	}
+	return r, nil
+}
+
+// Add writes a report starting on a new page. The report text must already
+// be decrypted.
+func (r *Reports) Add(report *models.Report) {
+	doc, patient := r.doc, r.patient
+	doc.PageBreak()
+
+	if r.logo != 0 {
+		doc.DrawImage(r.logo, contentWidth, maxLogoHeight)
+	}
+	doc.SetTextColor(r.branding.PrimaryColor)
+	doc.Text(r.name+" Report", Bold, 20)
+	doc.SetTextColor("")
+	doc.Text(report.Title, Regular, 12)
+	doc.Space(6)
+	doc.Rule()
+
+	doc.Text("Patient", Bold, 13)
+	doc.Space(2)
+	doc.Field("Name", patient.Name, 10)
+	doc.Field("Date of birth", patient.DateOfBirth.Format("2006-01-02"), 10)
+	doc.Space(10)
+
+	doc.Text("Recording", Bold, 13)
+	doc.Space(2)
+	doc.Field("Report ID", strconv.FormatUint(uint64(report.ID), 10), 10)
+	doc.Field("Recorded", patient.FormatTimestamp(report.CreatedAt), 10)
+	doc.Field("Last updated", patient.FormatTimestamp(report.UpdatedAt), 10)
+	doc.Field("Matching scale", strconv.Itoa(report.MatchingScale), 10)
+	if len(report.Tags) > 0 {
+		doc.Field("Tags", strings.Join(report.Tags, ", "), 10)
+	}
+	doc.Space(10)
+
+	doc.Text("Translation", Bold, 13)
+	doc.Space(2)
+	translation := report.Description
+	if translation == "" {
+		translation = "No translation is available for this recording."
+		if report.TranslationStatus == models.TranslationPending || report.TranslationStatus == models.TranslationRunning {
+			translation = "The translation is still in progress."
+		}
+	}
+	doc.Text(translation, Regular, 11)
+
+	if report.Correction != "" {
+		doc.Space(10)
+		doc.Text("Patient's correction", Bold, 13)
+		doc.Space(2)
+		doc.Text(report.Correction, Regular, 11)
+	}
+}
+
+// WriteTo writes the PDF
+func (r *Reports) WriteTo(w io.Writer) (int64, error) {
+	return r.doc.WriteTo(w)
+}
@@ -0,0 +1,64 @@
+package plans
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"gorm.io/gorm"
+)
+
+// cacheTTL bounds how long resolved limits are reused before the database is
+// consulted again, so admin changes apply within a minute
+const cacheTTL = time.Minute
+
+type cachedLimits struct {
+	limits  Limits
+	expires time.Time
+}
+
+var (
+	cache   = map[uint]cachedLimits{}
+	cacheMu sync.Mutex
+)
+
+// LimitsForUser resolves the user's limits by ID, caching the result briefly
+func LimitsForUser(db *gorm.DB, userID uint) (Limits, error) {
+	cacheMu.Lock()
+	cached, ok := cache[userID]
+	cacheMu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.limits, nil
+	}
+
+	user, err := models.FindUserByID(db, userID)
+	if err != nil {
+		return Limits{}, err
+	}
+
+	limits, err := ResolveLimits(db, user)
+	if err != nil {
+		return Limits{}, err
+	}
+
+	cacheMu.Lock()
+	cache[userID] = cachedLimits{limits: limits, expires: time.Now().Add(cacheTTL)}
+	cacheMu.Unlock()
+
+	return limits, nil
+}
+
+// Invalidate drops cached limits so changes apply on the next request.
+// With no IDs the whole cache is cleared.
+func Invalidate(userIDs ...uint) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	if len(userIDs) == 0 {
+		cache = map[uint]cachedLimits{}
+		return
+	}
+	for _, id := range userIDs {
+		delete(cache, id)
+	}
+}
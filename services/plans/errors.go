@@ -0,0 +1,106 @@
+package plans
+
+import (
+	"math"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
+)
+
+// Codes of limit errors, stable so clients can branch on them
+const (
+	CodeRateLimited   = "rate_limited"
+	CodeQuotaExceeded = "quota_exceeded"
+	CodeQueueFull     = "queue_full"
+)
+
+// Resources limited outside the monthly quotas
+const (
+	ResourceRequests         = "requests"
+	ResourceTranslationQueue = "translation_queue"
+)
+
+// LimitError is the body of 429, 402 and 413 responses: which limit was hit, what
+// is left and when it resets, and where to upgrade when a plan change would lift it
+type LimitError struct {
+	Error     string `json:"error" example:"monthly uploads quota exceeded (limit 20)"`
+	Code      string `json:"code" example:"quota_exceeded"`
+	Resource  string `json:"resource" example:"uploads"`
+	Plan      string `json:"plan" example:"free"`
+	Limit     int64  `json:"limit" example:"20"`
+	Remaining int64  `json:"remaining" example:"0"`
+	// When the limit resets; omitted for storage, which only frees up as reports
+	// are deleted, and for file size
+	Reset *time.Time `json:"reset,omitempty"`
+	// Seconds until retrying can succeed, also sent as Retry-After
+	RetryAfter int `json:"retry_after,omitempty" example:"30"`
+	// Where the user can change plans; omitted when an organization's rate plan sets the limits
+	UpgradeURL string `json:"upgrade_url,omitempty" example:"https://app.thinkink.io/billing"`
+}
+
+// RateLimited describes a request refused by the rate limiter, retryable after wait
+func RateLimited(limits Limits, wait time.Duration) LimitError {
+	return newLimitError(limits, "Rate limit exceeded", CodeRateLimited, ResourceRequests,
+		int64(limits.RequestsPerMinute), 0, wait)
+}
+
+// QuotaExceeded describes an action refused by a monthly or storage quota, or
+// the file size limit. Monthly quotas reset with the usage period; storage and
+// file size carry no reset.
+func QuotaExceeded(limits Limits, usage *Usage, err *QuotaError) LimitError {
+	used := int64(usage.Uploads)
+	switch err.Resource {
+	case ResourceStorage:
+		used = usage.StorageBytes
+	case ResourceTranslations:
+		used = int64(usage.Translations)
+	case ResourceUploadBytes:
+		used = usage.UploadBytes
+	case ResourceFileSize:
+		// Nothing is used up; the file itself is too large
+		used = err.Limit
+	}
+
+	limitErr := newLimitError(limits, err.Error(), CodeQuotaExceeded, err.Resource,
+		err.Limit, remaining(err.Limit, used), time.Until(usage.ResetsAt))
+	if err.Resource == ResourceStorage || err.Resource == ResourceFileSize {
+		limitErr.Reset = nil
+		limitErr.RetryAfter = 0
+	}
+	return limitErr
+}
+
+// QueueFull describes an upload deferred because the translation queue is
+// backed up, retryable once the queue has drained for eta
+func QueueFull(limits Limits, depth, queueLimit int, eta time.Duration) LimitError {
+	return newLimitError(limits, "Translation service is busy, please retry later", CodeQueueFull, ResourceTranslationQueue,
+		int64(queueLimit), remaining(int64(queueLimit), int64(depth)), eta)
+}
+
+func newLimitError(limits Limits, message, code, resource string, limit, left int64, wait time.Duration) LimitError {
+	if wait < 0 {
+		wait = 0
+	}
+	reset := time.Now().Add(wait).UTC().Truncate(time.Second)
+	limitErr := LimitError{
+		Error:      message,
+		Code:       code,
+		Resource:   resource,
+		Plan:       limits.Plan,
+		Limit:      limit,
+		Remaining:  left,
+		Reset:      &reset,
+		RetryAfter: int(math.Ceil(wait.Seconds())),
+	}
+	if limits.Source == "plan" {
+		limitErr.UpgradeURL = config.Current().UpgradeURL
+	}
+	return limitErr
+}
+
+func remaining(limit, used int64) int64 {
+	if limit-used < 0 {
+		return 0
+	}
+	return limit - used
+}
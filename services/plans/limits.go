@@ -0,0 +1,160 @@
+package plans
+
+import (
+	"fmt"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"gorm.io/gorm"
+)
+
+// Unlimited marks a limit that is not enforced
+const Unlimited = -1
+
+// Limits are the effective rate limits and quotas for a user
+type Limits struct {
+	Plan                 string `json:"plan" example:"free"`
+	Source               string `json:"source" example:"plan"`
+	RequestsPerMinute    int    `json:"requests_per_minute" example:"300"`
+	UploadsPerMonth      int    `json:"uploads_per_month" example:"-1"`
+	TranslationsPerMonth int    `json:"translations_per_month" example:"-1"`
+	StorageBytes         int64  `json:"storage_bytes" example:"-1"`
+	// Largest file that may be uploaded; never more than MAX_UPLOAD_SIZE_MB
+	MaxFileBytes int64 `json:"max_file_bytes" example:"52428800"`
+	// Total size of the files that may be uploaded per month
+	UploadBytesPerMonth int64 `json:"upload_bytes_per_month" example:"-1"`
+}
+
+// PlanID returns the plan the user's limits are based on: their Stripe plan
+// while subscribed, otherwise the free plan
+func PlanID(user *models.User) string {
+	if !user.IsSubscribed() {
+		return config.PlanFree
+	}
+	if user.CurrentPlanID != nil && *user.CurrentPlanID != "" {
+		return *user.CurrentPlanID
+	}
+	return config.PlanPaid
+}
+
+// DefaultLimits returns the plan defaults for the user
+func DefaultLimits(user *models.User) Limits {
+	return planLimits(PlanID(user), user.IsSubscribed())
+}
+
+// planLimits returns the defaults of a plan
+func planLimits(planID string, subscribed bool) Limits {
+	settings := config.Current()
+	quota := settings.QuotaFor(planID, subscribed)
+
+	limits := Limits{
+		Plan:                 planID,
+		Source:               "plan",
+		RequestsPerMinute:    settings.FreeRatePerMinute,
+		UploadsPerMonth:      quota.UploadsPerMonth,
+		TranslationsPerMonth: quota.TranslationsPerMonth,
+		StorageBytes:         quota.StorageBytes,
+		MaxFileBytes:         capFileSize(quota.MaxFileBytes),
+		UploadBytesPerMonth:  quota.UploadBytesPerMonth,
+	}
+	if subscribed {
+		limits.RequestsPerMinute = settings.PaidRatePerMinute
+	}
+
+	return limits
+}
+
+// capFileSize bounds a plan's file size limit by MAX_UPLOAD_SIZE_MB, the
+// largest upload the server accepts from anyone
+func capFileSize(limit int64) int64 {
+	ceiling := int64(config.Current().MaxUploadSizeMB) << 20
+	if limit == Unlimited || limit > ceiling {
+		return ceiling
+	}
+	return limit
+}
+
+// ResolveLimits returns the user's effective limits: plan defaults, those of
+// their organization's seat subscription when they have no subscription of
+// their own, overridden field by field by their organization's custom rate
+// plan if one exists
+func ResolveLimits(db *gorm.DB, user *models.User) (Limits, error) {
+	limits := DefaultLimits(user)
+	if user.OrganizationID == nil {
+		return limits, nil
+	}
+
+	if !user.IsSubscribed() {
+		seat, err := user.SeatSubscription(db)
+		if err != nil {
+			return limits, fmt.Errorf("failed to load organization subscription: %w", err)
+		}
+		if seat != nil {
+			planID := seat.PlanID
+			if planID == "" {
+				planID = config.PlanPaid
+			}
+			limits = planLimits(planID, true)
+			limits.Source = "seat"
+		}
+	}
+
+	orgPlan, err := models.FindOrgRatePlan(db, *user.OrganizationID)
+	if err != nil {
+		return limits, fmt.Errorf("failed to load organization rate plan: %w", err)
+	}
+	if orgPlan == nil {
+		return limits, nil
+	}
+
+	limits.Source = "organization"
+	if orgPlan.RequestsPerMinute != nil {
+		limits.RequestsPerMinute = *orgPlan.RequestsPerMinute
+	}
+	if orgPlan.UploadsPerMonth != nil {
+		limits.UploadsPerMonth = *orgPlan.UploadsPerMonth
+	}
+	if orgPlan.TranslationsPerMonth != nil {
+		limits.TranslationsPerMonth = *orgPlan.TranslationsPerMonth
+	}
+	if orgPlan.StorageBytes != nil {
+		limits.StorageBytes = *orgPlan.StorageBytes
+	}
+	if orgPlan.MaxFileBytes != nil {
+		limits.MaxFileBytes = capFileSize(*orgPlan.MaxFileBytes)
+	}
+	if orgPlan.UploadBytesPerMonth != nil {
+		limits.UploadBytesPerMonth = *orgPlan.UploadBytesPerMonth
+	}
+
+	return limits, nil
+}
+
+// ResolveLapsePolicy returns what happens to the user's raw recordings once
+// their subscription lapses: their organization's policy if it sets one,
+// otherwise the policy of the plan they were on
+func ResolveLapsePolicy(db *gorm.DB, user *models.User) (config.LapsePolicy, error) {
+	planID := config.PlanPaid
+	if user.CurrentPlanID != nil && *user.CurrentPlanID != "" {
+		planID = *user.CurrentPlanID
+	}
+	policy := config.Current().LapsePolicyFor(planID)
+
+	if user.OrganizationID == nil {
+		return policy, nil
+	}
+	orgPlan, err := models.FindOrgRatePlan(db, *user.OrganizationID)
+	if err != nil {
+		return policy, fmt.Errorf("failed to load organization rate plan: %w", err)
+	}
+	if orgPlan == nil {
+		return policy, nil
+	}
+	if orgPlan.LapseAction != nil {
+		policy.Action = *orgPlan.LapseAction
+	}
+	if orgPlan.LapseGraceDays != nil {
+		policy.GraceDays = *orgPlan.LapseGraceDays
+	}
+	return policy, nil
+}
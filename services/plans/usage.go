@@ -0,0 +1,128 @@
+package plans
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"gorm.io/gorm"
+)
+
+// Quota resources
+const (
+	ResourceUploads      = "uploads"
+	ResourceTranslations = "translations"
+	ResourceStorage      = "storage"
+	ResourceFileSize     = "file_size"
+	ResourceUploadBytes  = "upload_bytes"
+)
+
+// Usage is a user's consumption in the current period
+type Usage struct {
+	Period       string    `json:"period" example:"2026-10"`
+	ResetsAt     time.Time `json:"resets_at"`
+	Uploads      int       `json:"uploads" example:"3"`
+	Translations int       `json:"translations" example:"3"`
+	StorageBytes int64     `json:"storage_bytes" example:"1048576"`
+	// Total size of the files uploaded this period
+	UploadBytes int64 `json:"upload_bytes" example:"3145728"`
+}
+
+// QuotaError reports that an action would exceed one of the user's quotas
+type QuotaError struct {
+	Resource string
+	Limit    int64
+}
+
+func (e *QuotaError) Error() string {
+	switch e.Resource {
+	case ResourceStorage:
+		return fmt.Sprintf("storage quota exceeded (limit %d bytes)", e.Limit)
+	case ResourceFileSize:
+		return fmt.Sprintf("file is larger than the plan allows (limit %d bytes)", e.Limit)
+	case ResourceUploadBytes:
+		return fmt.Sprintf("monthly upload volume quota exceeded (limit %d bytes)", e.Limit)
+	default:
+		return fmt.Sprintf("monthly %s quota exceeded (limit %d)", e.Resource, e.Limit)
+	}
+}
+
+// CurrentUsage returns the user's usage for the current calendar month.
+// Uploads still being processed count as if they had finished, so a burst of
+// uploads cannot exceed the quotas before their jobs record them.
+func CurrentUsage(db *gorm.DB, userID uint) (*Usage, error) {
+	now := time.Now().UTC()
+	period := models.UsagePeriod(now)
+
+	counter, err := models.FindUsage(db, userID, period)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load usage: %w", err)
+	}
+	pending, err := models.PendingUploadUsage(db, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pending uploads: %w", err)
+	}
+
+	storage, err := models.StorageUsedByUser(db, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute storage: %w", err)
+	}
+
+	return &Usage{
+		Period:       period,
+		ResetsAt:     time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC),
+		Uploads:      counter.Uploads + pending.Uploads,
+		Translations: counter.Translations + pending.Translations,
+		StorageBytes: storage,
+		UploadBytes:  counter.UploadBytes + pending.UploadBytes,
+	}, nil
+}
+
+// CheckUpload returns a *QuotaError if uploading a file of the given size would
+// exceed the user's file size limit, or their upload, upload volume or storage quota
+func CheckUpload(limits Limits, usage *Usage, size int64) error {
+	if exceeds(limits.MaxFileBytes, size) {
+		return &QuotaError{Resource: ResourceFileSize, Limit: limits.MaxFileBytes}
+	}
+	if exceeds(int64(limits.UploadsPerMonth), int64(usage.Uploads)+1) {
+		return &QuotaError{Resource: ResourceUploads, Limit: int64(limits.UploadsPerMonth)}
+	}
+	if exceeds(limits.UploadBytesPerMonth, usage.UploadBytes+size) {
+		return &QuotaError{Resource: ResourceUploadBytes, Limit: limits.UploadBytesPerMonth}
+	}
+	if exceeds(limits.StorageBytes, usage.StorageBytes+size) {
+		return &QuotaError{Resource: ResourceStorage, Limit: limits.StorageBytes}
+	}
+	return nil
+}
+
+// StorageRemaining returns the bytes the user can still store, or Unlimited
+func StorageRemaining(limits Limits, usage *Usage) int64 {
+	if limits.StorageBytes == Unlimited {
+		return Unlimited
+	}
+	return remaining(limits.StorageBytes, usage.StorageBytes)
+}
+
+// CanTranslate reports whether the user has translations left this period
+func CanTranslate(limits Limits, usage *Usage) bool {
+	return !exceeds(int64(limits.TranslationsPerMonth), int64(usage.Translations)+1)
+}
+
+// RecordUpload counts an upload of size bytes, and its translation if one was produced
+func RecordUpload(db *gorm.DB, userID uint, size int64, translated bool) error {
+	translations := 0
+	if translated {
+		translations = 1
+	}
+	return models.IncrementUsage(db, userID, models.UsagePeriod(time.Now()), 1, size, translations)
+}
+
+func exceeds(limit, value int64) bool {
+	return limit != Unlimited && value > limit
+}
+
+// RecordTranslation counts a translation completed after its upload was recorded
+func RecordTranslation(db *gorm.DB, userID uint) error {
+	return models.IncrementUsage(db, userID, models.UsagePeriod(time.Now()), 0, 0, 1)
+}
@@ -0,0 +1,136 @@
+package postprocess
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// language returns the lowercase language subtag of a locale, e.g. "en" for en-US
+func language(locale string) string {
+	lang, _, _ := strings.Cut(strings.ToLower(locale), "-")
+	if lang == "" {
+		return "en"
+	}
+	return lang
+}
+
+// punctuationProcessor normalizes spacing and ends the text with a sentence
+// mark when the model left it off, using a question mark for questions
+type punctuationProcessor struct{}
+
+func (punctuationProcessor) Name() string { return Punctuation }
+
+// questionWords start questions in the languages with a rule; others only get a full stop
+var questionWords = map[string][]string{
+	"en": {"what", "when", "where", "who", "whom", "whose", "why", "how", "which", "can", "could", "would", "will", "shall", "should", "do", "does", "did", "is", "are", "am", "was", "were", "may", "have", "has"},
+	"de": {"was", "wann", "wo", "wer", "wen", "wem", "warum", "wie", "welche", "welcher", "kannst", "können", "ist", "sind", "hast", "haben"},
+	"fr": {"quoi", "quand", "où", "qui", "pourquoi", "comment", "quel", "quelle", "est-ce"},
+	"es": {"qué", "cuándo", "dónde", "quién", "cómo", "cuál"},
+}
+
+// fullStops are the sentence-ending marks per language; Latin marks otherwise
+var fullStops = map[string][2]string{
+	"ja": {"。", "？"},
+	"zh": {"。", "？"},
+	"ar": {".", "؟"},
+}
+
+func (punctuationProcessor) Process(text, locale string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	// Drop spaces the model put before punctuation, e.g. "tea ."
+	for _, mark := range []string{".", ",", "!", "?", ";", ":"} {
+		text = strings.ReplaceAll(text, " "+mark, mark)
+	}
+	if text == "" {
+		return text
+	}
+
+	last, _ := utf8.DecodeLastRuneInString(text)
+	if unicode.IsPunct(last) {
+		return text
+	}
+
+	lang := language(locale)
+	stop, question := ".", "?"
+	if marks, ok := fullStops[lang]; ok {
+		stop, question = marks[0], marks[1]
+	}
+	first := strings.ToLower(strings.Fields(text)[0])
+	for _, word := range questionWords[lang] {
+		if first == word {
+			if lang == "es" {
+				return "¿" + text + question
+			}
+			return text + question
+		}
+	}
+	return text + stop
+}
+
+// casingProcessor capitalizes the start of each sentence and, in English, the pronoun I
+type casingProcessor struct{}
+
+func (casingProcessor) Name() string { return Casing }
+
+func (casingProcessor) Process(text, locale string) string {
+	words := strings.Split(text, " ")
+	sentenceStart := true
+	for i, word := range words {
+		if word == "" {
+			continue
+		}
+		if language(locale) == "en" && (word == "i" || strings.HasPrefix(word, "i'") || word == "i," || word == "i.") {
+			word = "I" + word[1:]
+		}
+		if sentenceStart {
+			word = capitalize(word)
+		}
+		words[i] = word
+		last, _ := utf8.DecodeLastRuneInString(word)
+		sentenceStart = last == '.' || last == '!' || last == '?' || last == '。' || last == '？' || last == '؟'
+	}
+	return strings.Join(words, " ")
+}
+
+// capitalize upper-cases the first letter of word, skipping leading marks such as ¿
+func capitalize(word string) string {
+	for i, r := range word {
+		if unicode.IsLetter(r) {
+			return word[:i] + string(unicode.ToTitle(r)) + word[i+utf8.RuneLen(r):]
+		}
+	}
+	return word
+}
+
+// profanityProcessor masks profane words, keeping their first letter
+type profanityProcessor struct{}
+
+func (profanityProcessor) Name() string { return Profanity }
+
+// profaneWords are masked in any language; the list is kept short and obvious
+var profaneWords = map[string]bool{
+	"damn": true, "hell": true, "shit": true, "fuck": true, "fucking": true, "bitch": true,
+	"bastard": true, "crap": true, "ass": true, "asshole": true, "piss": true, "dick": true,
+	"scheiße": true, "merde": true, "mierda": true, "puta": true,
+}
+
+func (profanityProcessor) Process(text, locale string) string {
+	words := strings.Split(text, " ")
+	for i, word := range words {
+		start := strings.IndexFunc(word, unicode.IsLetter)
+		if start < 0 {
+			continue
+		}
+		end := strings.LastIndexFunc(word, unicode.IsLetter)
+		_, size := utf8.DecodeRuneInString(word[end:])
+		core := word[start : end+size]
+		if !profaneWords[strings.ToLower(core)] {
+			continue
+		}
+		first, firstSize := utf8.DecodeRuneInString(core)
+		masked := string(first) + strings.Repeat("*", utf8.RuneCountInString(core[firstSize:]))
+		words[i] = word[:start] + masked + word[end+size:]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,95 @@
+// Package postprocess cleans up raw ML translations before they are stored:
+// restoring punctuation, fixing casing and optionally masking profanity.
+// Processors are pluggable and chosen per user or organization.
+package postprocess
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Processor transforms translated text. locale is the user's BCP 47 locale,
+// e.g. en-US, for processors whose rules depend on the language.
+type Processor interface {
+	Name() string
+	Process(text, locale string) string
+}
+
+// Built-in processor names
+const (
+	Punctuation = "punctuation"
+	Casing      = "casing"
+	Profanity   = "profanity"
+)
+
+// DefaultProcessors run for users and organizations that have not chosen any
+var DefaultProcessors = []string{Punctuation, Casing}
+
+var (
+	mu         sync.RWMutex
+	processors = map[string]Processor{}
+)
+
+func init() {
+	Register(punctuationProcessor{})
+	Register(casingProcessor{})
+	Register(profanityProcessor{})
+}
+
+// Register adds a processor, replacing any registered under the same name
+func Register(p Processor) {
+	mu.Lock()
+	defer mu.Unlock()
+	processors[p.Name()] = p
+}
+
+// Available lists the registered processor names
+func Available() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return available()
+}
+
+// Validate checks that every name is a registered processor and none repeats
+func Validate(names []string) error {
+	mu.RLock()
+	defer mu.RUnlock()
+	seen := map[string]bool{}
+	for _, name := range names {
+		if _, ok := processors[name]; !ok {
+			return fmt.Errorf("unknown text processor %q; available: %s", name, strings.Join(available(), ", "))
+		}
+		if seen[name] {
+			return fmt.Errorf("text processor %q is listed twice", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// available lists the registered names; callers hold mu
+func available() []string {
+	names := make([]string, 0, len(processors))
+	for name := range processors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run applies the named processors to text in order. Unknown names are skipped.
+func Run(names []string, text, locale string) string {
+	if strings.TrimSpace(text) == "" {
+		return text
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, name := range names {
+		if p, ok := processors[name]; ok {
+			text = p.Process(text, locale)
+		}
+	}
+	return text
+}
@@ -0,0 +1,80 @@
+package postprocess
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Encode validates processor names and encodes them for storage. A nil list
+// encodes to nil, meaning the setting is inherited.
+func Encode(names []string) (datatypes.JSON, error) {
+	if names == nil {
+		return nil, nil
+	}
+	if err := Validate(names); err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(names)
+	if err != nil {
+		return nil, err
+	}
+	return datatypes.JSON(encoded), nil
+}
+
+// decode returns the stored processor names and whether any were set
+func decode(stored datatypes.JSON) ([]string, bool) {
+	if len(stored) == 0 || string(stored) == "null" {
+		return nil, false
+	}
+	var names []string
+	if err := json.Unmarshal(stored, &names); err != nil {
+		return nil, false
+	}
+	return names, true
+}
+
+// Where the effective processors of a user come from
+const (
+	SourceUser         = "user"
+	SourceOrganization = "organization"
+	SourceDefault      = "default"
+)
+
+// Effective returns the processors applied to the user's translations: their
+// own choice, else their organization's, else DefaultProcessors, along with
+// which of those it is
+func Effective(db *gorm.DB, user *models.User) ([]string, string, error) {
+	if names, ok := decode(user.TextProcessors); ok {
+		return names, SourceUser, nil
+	}
+	if user.OrganizationID != nil {
+		org, err := models.FindOrganizationByID(db, *user.OrganizationID)
+		if err != nil {
+			return nil, "", err
+		}
+		if names, ok := decode(org.TextProcessors); ok {
+			return names, SourceOrganization, nil
+		}
+	}
+	return DefaultProcessors, SourceDefault, nil
+}
+
+// Translation post-processes raw ML output with the user's processors and locale
+func Translation(db *gorm.DB, userID uint, raw string) (string, error) {
+	if raw == "" {
+		return raw, nil
+	}
+	user, err := models.FindUserByID(db, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch user: %w", err)
+	}
+	names, _, err := Effective(db, user)
+	if err != nil {
+		return "", err
+	}
+	return Run(names, raw, user.Locale), nil
+}
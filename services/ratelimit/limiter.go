@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// idleTTL is how long an unused bucket is kept before it is discarded
+const idleTTL = 10 * time.Minute
+
+// bucket is a token bucket refilled continuously at ratePerMinute
+type bucket struct {
+	tokens   float64
+	last     time.Time
+	lastUsed time.Time
+}
+
+// Limiter tracks one token bucket per key (e.g. per user). The rate is passed
+// on every call so limit changes take effect immediately.
+type Limiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewLimiter creates an empty limiter
+func NewLimiter() *Limiter {
+	return &Limiter{
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow consumes a token for key and returns the whole tokens left, or -1
+// when the rate is unlimited. When the bucket is empty it returns false and
+// how long until the next token is available.
+func (l *Limiter) Allow(key string, ratePerMinute int) (bool, int, time.Duration) {
+	if ratePerMinute < 0 {
+		return true, -1, 0
+	}
+	if ratePerMinute == 0 {
+		return false, 0, time.Minute
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	capacity := float64(ratePerMinute)
+	perSec := capacity / 60
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*perSec)
+	b.last = now
+	b.lastUsed = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, int(b.tokens), 0
+	}
+
+	wait := time.Duration((1 - b.tokens) / perSec * float64(time.Second))
+	return false, 0, wait
+}
+
+// sweep drops idle buckets so the map does not grow without bound
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleTTL {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.lastUsed) > idleTTL {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
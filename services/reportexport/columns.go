@@ -0,0 +1,106 @@
+package reportexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+)
+
+// column is a selectable export column
+type column struct {
+	name    string
+	numeric bool
+	value   func(r *models.Report) string
+}
+
+// columns lists every selectable column in their default order
+var columns = []column{
+	{"id", true, func(r *models.Report) string { return strconv.FormatUint(uint64(r.ID), 10) }},
+	{"title", false, func(r *models.Report) string { return r.Title }},
+	{"description", false, func(r *models.Report) string { return r.Description }},
+	{"correction", false, func(r *models.Report) string { return r.Correction }},
+	{"matching_scale", true, func(r *models.Report) string { return strconv.Itoa(r.MatchingScale) }},
+	{"tags", false, func(r *models.Report) string { return strings.Join(r.Tags, ", ") }},
+	{"metadata", false, formatMetadata},
+	{"translation_status", false, func(r *models.Report) string { return r.TranslationStatus }},
+	{"size_bytes", true, func(r *models.Report) string { return strconv.FormatInt(r.SizeBytes, 10) }},
+	{"created_at", false, func(r *models.Report) string { return formatTime(&r.CreatedAt) }},
+	{"updated_at", false, func(r *models.Report) string { return formatTime(&r.UpdatedAt) }},
+	{"archived_at", false, func(r *models.Report) string { return formatTime(r.ArchivedAt) }},
+}
+
+// ColumnNames returns the names of all selectable columns in their default order
+func ColumnNames() []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// ParseColumns parses a comma-separated column selection. An empty selection
+// means every column.
+func ParseColumns(selection string) ([]string, error) {
+	if strings.TrimSpace(selection) == "" {
+		return ColumnNames(), nil
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(selection, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		if _, err := lookupColumn(name); err != nil {
+			return nil, err
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return ColumnNames(), nil
+	}
+	return names, nil
+}
+
+func lookupColumn(name string) (column, error) {
+	for _, c := range columns {
+		if c.name == name {
+			return c, nil
+		}
+	}
+	return column{}, fmt.Errorf("unknown column %q; available columns: %s", name, strings.Join(ColumnNames(), ", "))
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatMetadata renders report metadata as "key=value" pairs
+func formatMetadata(r *models.Report) string {
+	if len(r.Metadata) == 0 {
+		return ""
+	}
+	var metadata map[string]string
+	if err := json.Unmarshal(r.Metadata, &metadata); err != nil {
+		return string(r.Metadata)
+	}
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + metadata[key]
+	}
+	return strings.Join(pairs, "; ")
+}
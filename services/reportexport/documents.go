@@ -0,0 +1,117 @@
+package reportexport
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/pdf"
+)
+
+// tableWriter writes reports as spreadsheet rows of the selected columns
+type tableWriter struct {
+	rows    rowWriter
+	columns []column
+	numeric []bool
+}
+
+func newTableWriter(rows rowWriter, columns []column) (*tableWriter, error) {
+	t := &tableWriter{rows: rows, columns: columns, numeric: make([]bool, len(columns))}
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.name
+		t.numeric[i] = c.numeric
+	}
+	if err := rows.writeRow(header, make([]bool, len(header))); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *tableWriter) writeReports(reports []models.Report) error {
+	for i := range reports {
+		values := make([]string, len(t.columns))
+		for j, c := range t.columns {
+			values[j] = c.value(&reports[i])
+		}
+		if err := t.rows.writeRow(values, t.numeric); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *tableWriter) close() error {
+	return t.rows.close()
+}
+
+// pdfWriter writes every report into one PDF, each starting on a new page.
+// The document is laid out in memory and written on close.
+type pdfWriter struct {
+	w   io.Writer
+	doc *pdf.Reports
+}
+
+func newPDFWriter(w io.Writer, t *target) (reportWriter, error) {
+	doc, err := pdf.NewReports(t.owner, t.branding)
+	if err != nil {
+		return nil, err
+	}
+	return &pdfWriter{w: w, doc: doc}, nil
+}
+
+func (p *pdfWriter) writeReports(reports []models.Report) error {
+	for i := range reports {
+		p.doc.Add(&reports[i])
+	}
+	return nil
+}
+
+func (p *pdfWriter) close() error {
+	_, err := p.doc.WriteTo(p.w)
+	return err
+}
+
+// zipWriter writes an archive with each report as JSON, in the format of
+// GET /reports/:id/download, and as a PDF
+type zipWriter struct {
+	archive *zip.Writer
+	target  *target
+}
+
+func newZIPWriter(w io.Writer, t *target) (reportWriter, error) {
+	return &zipWriter{archive: zip.NewWriter(w), target: t}, nil
+}
+
+func (z *zipWriter) writeReports(reports []models.Report) error {
+	for i := range reports {
+		report := &reports[i]
+
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode report %d: %w", report.ID, err)
+		}
+		f, err := z.archive.CreateHeader(&zip.FileHeader{Name: fmt.Sprintf("report-%d.json", report.ID), Method: zip.Deflate, Modified: report.UpdatedAt})
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			return err
+		}
+
+		f, err = z.archive.CreateHeader(&zip.FileHeader{Name: fmt.Sprintf("report-%d.pdf", report.ID), Method: zip.Deflate, Modified: report.UpdatedAt})
+		if err != nil {
+			return err
+		}
+		if _, err := pdf.WriteReport(f, report, z.target.owner, z.target.branding); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (z *zipWriter) close() error {
+	return z.archive.Close()
+}
@@ -0,0 +1,201 @@
+// Package reportexport writes a user's reports as CSV or XLSX spreadsheets,
+// a PDF, a ZIP archive or a FHIR bundle, either streamed directly or generated
+// in the background as export jobs that track their progress.
+package reportexport
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/branding"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/encryption"
+	"gorm.io/gorm"
+)
+
+// batchSize bounds how many reports are loaded at once while writing
+const batchSize = 200
+
+// rowWriter writes spreadsheet rows in one of the tabular formats
+type rowWriter interface {
+	writeRow(values []string, numeric []bool) error
+	close() error
+}
+
+// csvWriter writes rows as CSV
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) writeRow(values []string, numeric []bool) error {
+	// Keep spreadsheet applications from evaluating user text as formulas
+	for i, value := range values {
+		if !numeric[i] && value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+			values[i] = "'" + value
+		}
+	}
+	return c.w.Write(values)
+}
+
+func (c *csvWriter) close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// Write writes the owner's reports matching filter in the given format,
+// oldest first, and returns how many reports were written. Tabular formats
+// write the given columns. Report text is decrypted for the export, and each
+// report's read is recorded as access before it is written. progress, if not
+// nil, is called with the running count after every batch.
+func Write(ctx context.Context, db *gorm.DB, w io.Writer, formatName string, ownerID uint, filter models.ReportFilter, columnNames []string, access models.ReportAccess, progress func(written int) error) (int, error) {
+	f, ok := formats[formatName]
+	if !ok {
+		return 0, fmt.Errorf("unsupported export format %q; supported formats: %s", formatName, formatList())
+	}
+
+	t := &target{}
+	if f.tabular {
+		t.columns = make([]column, len(columnNames))
+		for i, name := range columnNames {
+			c, err := lookupColumn(name)
+			if err != nil {
+				return 0, err
+			}
+			t.columns[i] = c
+		}
+	} else {
+		owner, err := models.FindUserByID(db, ownerID)
+		if err != nil {
+			return 0, err
+		}
+		t.owner = owner
+	}
+	if f.branded {
+		orgBranding, err := models.FindUserBranding(db, t.owner)
+		if err != nil {
+			return 0, err
+		}
+		if t.branding, err = branding.ForPDF(orgBranding); err != nil {
+			return 0, err
+		}
+	}
+
+	out, err := f.newWriter(w, t)
+	if err != nil {
+		return 0, err
+	}
+
+	written := 0
+	var reports []models.Report
+	err = models.FilteredReportsQuery(db, ownerID, filter).FindInBatches(&reports, batchSize, func(tx *gorm.DB, batch int) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := encryption.OpenReports(ctx, reports); err != nil {
+			return err
+		}
+		if f.withContent {
+			if err := models.LoadArchivedContents(ctx, reports); err != nil {
+				return err
+			}
+		}
+		if err := models.LoadReportTags(db, reports); err != nil {
+			return err
+		}
+		if err := models.RecordReportAccesses(db, access, models.ReportIDs(reports)); err != nil {
+			return err
+		}
+		if err := out.writeReports(reports); err != nil {
+			return err
+		}
+		written += len(reports)
+		if progress != nil {
+			return progress(written)
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return written, err
+	}
+	return written, out.close()
+}
+
+// RunPending generates the files of pending exports in dir, one export at a
+// time, until none is left. Completed files are kept for ttl.
+func RunPending(ctx context.Context, db *gorm.DB, dir string, ttl time.Duration) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+	for ctx.Err() == nil {
+		export, err := models.ClaimPendingReportExport(db)
+		if err != nil {
+			return err
+		}
+		if export == nil {
+			return nil
+		}
+		runExport(ctx, db, export, dir, ttl)
+	}
+	return nil
+}
+
+// runExport writes a claimed export to its file and records the outcome
+func runExport(ctx context.Context, db *gorm.DB, export *models.ReportExport, dir string, ttl time.Duration) {
+	path := filepath.Join(dir, FileName(fmt.Sprintf("reports-%d", export.ID), export.Format))
+	rows, err := writeFile(ctx, db, export, path)
+	if err != nil {
+		_ = os.Remove(path)
+		log.Printf("Report export %d failed: %v", export.ID, err)
+	} else {
+		log.Printf("Report export %d wrote %d reports", export.ID, rows)
+	}
+	if err := export.Finish(db, path, rows, err, ttl); err != nil {
+		log.Printf("Failed to record report export %d: %v", export.ID, err)
+	}
+}
+
+func writeFile(ctx context.Context, db *gorm.DB, export *models.ReportExport, path string) (int, error) {
+	filter, err := export.ReportFilter()
+	if err != nil {
+		return 0, fmt.Errorf("invalid filter: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return 0, err
+	}
+	access := models.ReportAccess{ViewerID: &export.RequestedBy, Action: models.AccessExport}
+	rows, err := Write(ctx, db, f, export.Format, export.OwnerID, filter, strings.Split(export.Columns, ","), access, func(written int) error {
+		return export.RecordProgress(db, written)
+	})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return rows, err
+}
+
+// PurgeExpired removes the files and records of expired exports
+func PurgeExpired(ctx context.Context, db *gorm.DB) error {
+	exports, err := models.FindExpiredReportExports(db.WithContext(ctx), time.Now())
+	if err != nil {
+		return err
+	}
+	for i := range exports {
+		if exports[i].FilePath != "" {
+			if err := os.Remove(exports[i].FilePath); err != nil && !os.IsNotExist(err) {
+				log.Printf("Failed to remove report export %d: %v", exports[i].ID, err)
+				continue
+			}
+		}
+		if err := models.DeleteReportExport(db, &exports[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package reportexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+)
+
+// fhirWriter writes reports as a FHIR R4 collection Bundle: the patient, and
+// per report a DiagnosticReport with the translation as its conclusion and
+// Observations for the matching scale and the patient's correction. Entries
+// are streamed as they are written.
+type fhirWriter struct {
+	w          io.Writer
+	patientRef string
+}
+
+// fhirResource is a FHIR resource; fields are kept to what the export fills in
+type fhirResource map[string]interface{}
+
+func newFHIRWriter(w io.Writer, t *target) (reportWriter, error) {
+	f := &fhirWriter{w: w, patientRef: fmt.Sprintf("Patient/user-%d", t.owner.ID)}
+	if _, err := fmt.Fprintf(w, `{"resourceType":"Bundle","type":"collection","timestamp":%q,"entry":[`, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return nil, err
+	}
+
+	patient := fhirResource{
+		"resourceType": "Patient",
+		"id":           fmt.Sprintf("user-%d", t.owner.ID),
+		"name":         []fhirResource{{"text": t.owner.Name}},
+		"telecom":      []fhirResource{{"system": "email", "value": t.owner.Email}},
+	}
+	if !t.owner.DateOfBirth.IsZero() {
+		patient["birthDate"] = t.owner.DateOfBirth.Format("2006-01-02")
+	}
+	if err := f.entry(patient, false); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *fhirWriter) writeReports(reports []models.Report) error {
+	for i := range reports {
+		report := &reports[i]
+		id := fmt.Sprintf("report-%d", report.ID)
+		effective := report.CreatedAt.UTC().Format(time.RFC3339)
+
+		scale := fhirResource{
+			"resourceType":      "Observation",
+			"id":                id + "-matching-scale",
+			"status":            "final",
+			"code":              fhirResource{"text": "Matching scale"},
+			"subject":           fhirResource{"reference": f.patientRef},
+			"effectiveDateTime": effective,
+			"valueInteger":      report.MatchingScale,
+		}
+		results := []fhirResource{{"reference": "Observation/" + id + "-matching-scale"}}
+		if err := f.entry(scale, true); err != nil {
+			return err
+		}
+		if report.Correction != "" {
+			correction := fhirResource{
+				"resourceType":      "Observation",
+				"id":                id + "-correction",
+				"status":            "final",
+				"code":              fhirResource{"text": "Patient's correction"},
+				"subject":           fhirResource{"reference": f.patientRef},
+				"effectiveDateTime": effective,
+				"valueString":       report.Correction,
+			}
+			results = append(results, fhirResource{"reference": "Observation/" + id + "-correction"})
+			if err := f.entry(correction, true); err != nil {
+				return err
+			}
+		}
+
+		// Translations that have not finished are preliminary
+		status := "final"
+		if report.TranslationStatus == models.TranslationPending || report.TranslationStatus == models.TranslationRunning {
+			status = "preliminary"
+		}
+		diagnostic := fhirResource{
+			"resourceType":      "DiagnosticReport",
+			"id":                id,
+			"status":            status,
+			"code":              fhirResource{"text": "EEG-to-text translation"},
+			"subject":           fhirResource{"reference": f.patientRef},
+			"effectiveDateTime": effective,
+			"issued":            report.UpdatedAt.UTC().Format(time.RFC3339),
+			"result":            results,
+		}
+		if report.Description != "" {
+			diagnostic["conclusion"] = report.Description
+		}
+		if err := f.entry(diagnostic, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entry writes a resource as a Bundle entry
+func (f *fhirWriter) entry(resource fhirResource, separate bool) error {
+	data, err := json.Marshal(fhirResource{"resource": resource})
+	if err != nil {
+		return err
+	}
+	if separate {
+		if _, err := io.WriteString(f.w, ","); err != nil {
+			return err
+		}
+	}
+	_, err = f.w.Write(data)
+	return err
+}
+
+func (f *fhirWriter) close() error {
+	_, err := io.WriteString(f.w, "]}")
+	return err
+}
@@ -0,0 +1,121 @@
+package reportexport
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/pdf"
+)
+
+// Export formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatPDF  = "pdf"
+	FormatZIP  = "zip"
+	FormatFHIR = "fhir"
+)
+
+// reportWriter writes the reports of an export one batch at a time
+type reportWriter interface {
+	writeReports(reports []models.Report) error
+	close() error
+}
+
+// target is what the reports of an export are written for
+type target struct {
+	owner    *models.User
+	branding *pdf.Branding
+	columns  []column
+}
+
+// format describes how reports are written in one export format
+type format struct {
+	contentType string
+	extension   string
+	// tabular formats write the selected columns; the others write whole reports
+	tabular bool
+	// branded formats carry the owner's organization branding
+	branded bool
+	// formats with the recording read content moved to cold storage back
+	withContent bool
+	newWriter   func(w io.Writer, t *target) (reportWriter, error)
+}
+
+// formatNames lists the export formats in the order they are documented
+var formatNames = []string{FormatCSV, FormatXLSX, FormatPDF, FormatZIP, FormatFHIR}
+
+var formats = map[string]format{
+	FormatCSV: {
+		contentType: "text/csv; charset=utf-8",
+		extension:   "csv",
+		tabular:     true,
+		newWriter: func(w io.Writer, t *target) (reportWriter, error) {
+			return newTableWriter(&csvWriter{w: csv.NewWriter(w)}, t.columns)
+		},
+	},
+	FormatXLSX: {
+		contentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		extension:   "xlsx",
+		tabular:     true,
+		newWriter: func(w io.Writer, t *target) (reportWriter, error) {
+			rows, err := newXLSXWriter(w)
+			if err != nil {
+				return nil, err
+			}
+			return newTableWriter(rows, t.columns)
+		},
+	},
+	FormatPDF: {
+		contentType: "application/pdf",
+		extension:   "pdf",
+		branded:     true,
+		newWriter:   newPDFWriter,
+	},
+	FormatZIP: {
+		contentType: "application/zip",
+		extension:   "zip",
+		branded:     true,
+		withContent: true,
+		newWriter:   newZIPWriter,
+	},
+	FormatFHIR: {
+		contentType: "application/fhir+json",
+		extension:   "json",
+		newWriter:   newFHIRWriter,
+	},
+}
+
+// Formats returns the supported export formats
+func Formats() []string {
+	return append([]string(nil), formatNames...)
+}
+
+// ValidFormat reports whether format is a supported export format
+func ValidFormat(name string) bool {
+	_, ok := formats[name]
+	return ok
+}
+
+// Tabular reports whether the format writes the selected columns rather than
+// whole reports
+func Tabular(name string) bool {
+	return formats[name].tabular
+}
+
+// ContentType returns the MIME type of an export format
+func ContentType(name string) string {
+	return formats[name].contentType
+}
+
+// FileName returns the download name of an export file
+func FileName(base, name string) string {
+	return base + "." + formats[name].extension
+}
+
+// formatList names the supported formats for error messages
+func formatList() string {
+	return strings.Join(formatNames, ", ")
+}
@@ -0,0 +1,151 @@
+// Package sessionarchive reads zip archives holding the segments of one
+// recording session, listed by a manifest.json at the root of the archive:
+//
+//	{
+//	  "title": "Morning session",
+//	  "recorded_at": "2026-10-17T08:30:00Z",
+//	  "segments": [
+//	    {"file": "segment-01.json", "matching_scale": 6, "metadata": {"device_id": "headset-7"}},
+//	    {"file": "segment-02.json"}
+//	  ]
+//	}
+package sessionarchive
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// ManifestName is where the manifest sits in an archive
+const ManifestName = "manifest.json"
+
+// MaxSegments bounds the segments of one session
+const MaxSegments = 100
+
+// maxManifestSize bounds the manifest's unpacked size
+const maxManifestSize = 1 << 20
+
+// ErrInvalidArchive is returned for archives that cannot be read as a session
+var ErrInvalidArchive = errors.New("invalid session archive")
+
+// Manifest describes a recording session and the segments it was split into
+type Manifest struct {
+	Title      string     `json:"title"`
+	RecordedAt *time.Time `json:"recorded_at,omitempty"`
+	Segments   []Segment  `json:"segments"`
+}
+
+// Segment is one recording in the archive, in session order
+type Segment struct {
+	// Path of the recording inside the archive
+	File string `json:"file"`
+	// Optional 1-10, like the matchingScale of a single upload
+	MatchingScale *int `json:"matching_scale,omitempty"`
+	// Optional recording metadata, like the metadata of a single upload
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+}
+
+// Archive is an opened session archive
+type Archive struct {
+	Manifest Manifest
+	// The archive entries of the manifest's segments, in the same order
+	Files []*zip.File
+
+	reader *zip.ReadCloser
+}
+
+// Open reads the archive at path and checks its manifest: it must list
+// between 1 and MaxSegments distinct files, each present in the archive.
+// Entries the manifest does not list are ignored. Errors wrap
+// ErrInvalidArchive.
+func Open(path string) (*Archive, error) {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: not a readable zip archive", ErrInvalidArchive)
+	}
+	archive := &Archive{reader: reader}
+	if err := archive.load(); err != nil {
+		reader.Close()
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	return archive, nil
+}
+
+// Close releases the archive
+func (a *Archive) Close() error {
+	return a.reader.Close()
+}
+
+func (a *Archive) load() error {
+	entries := make(map[string]*zip.File, len(a.reader.File))
+	for _, f := range a.reader.File {
+		if !f.FileInfo().IsDir() {
+			entries[f.Name] = f
+		}
+	}
+
+	manifestFile, ok := entries[ManifestName]
+	if !ok {
+		return fmt.Errorf("%s is missing from the root of the archive", ManifestName)
+	}
+	if err := a.readManifest(manifestFile); err != nil {
+		return err
+	}
+
+	m := &a.Manifest
+	if len(m.Title) > 255 {
+		return fmt.Errorf("title must be at most 255 characters")
+	}
+	if len(m.Segments) == 0 || len(m.Segments) > MaxSegments {
+		return fmt.Errorf("segments must list between 1 and %d files", MaxSegments)
+	}
+	seen := make(map[string]bool, len(m.Segments))
+	for i, segment := range m.Segments {
+		name := segment.File
+		if name == "" || name == ManifestName || path.IsAbs(name) || path.Clean(name) != name || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("segment %d: file must be a relative path inside the archive other than %s", i, ManifestName)
+		}
+		if seen[name] {
+			return fmt.Errorf("segment %d: %s is listed twice", i, name)
+		}
+		seen[name] = true
+		if segment.MatchingScale != nil && (*segment.MatchingScale < 1 || *segment.MatchingScale > 10) {
+			return fmt.Errorf("segment %d: matching_scale must be between 1 and 10", i)
+		}
+		f, ok := entries[name]
+		if !ok {
+			return fmt.Errorf("segment %d: %s is not in the archive", i, name)
+		}
+		a.Files = append(a.Files, f)
+	}
+	return nil
+}
+
+func (a *Archive) readManifest(f *zip.File) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%s cannot be read: %v", ManifestName, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxManifestSize+1))
+	if err != nil {
+		return fmt.Errorf("%s cannot be read: %v", ManifestName, err)
+	}
+	if len(data) > maxManifestSize {
+		return fmt.Errorf("%s is larger than %dKB", ManifestName, maxManifestSize>>10)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&a.Manifest); err != nil {
+		return fmt.Errorf("%s is not a valid manifest: %v", ManifestName, err)
+	}
+	return nil
+}
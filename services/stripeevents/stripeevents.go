@@ -0,0 +1,88 @@
+// Package stripeevents routes queued Stripe webhook events to the handlers
+// registered for their type. Handlers receive the event's object already
+// decoded, so any package can react to billing events without touching the
+// webhook endpoint or the queue that feeds it.
+package stripeevents
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/billing"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/jobs"
+	"gorm.io/gorm"
+)
+
+// Handlers of each kind of event object. Returning an error retries the
+// event, unless it is a jobs.PermanentError. Events may be delivered more
+// than once, so handlers must be idempotent.
+type (
+	CheckoutSessionHandler func(ctx context.Context, db *gorm.DB, event *billing.Event, sess *billing.CheckoutSession) error
+	SubscriptionHandler    func(ctx context.Context, db *gorm.DB, event *billing.Event, sub *billing.Subscription) error
+	InvoiceHandler         func(ctx context.Context, db *gorm.DB, event *billing.Event, invoice *billing.Invoice) error
+	PaymentMethodHandler   func(ctx context.Context, db *gorm.DB, event *billing.Event, pm *billing.PaymentMethod) error
+	TaxIDHandler           func(ctx context.Context, db *gorm.DB, event *billing.Event, taxID *billing.TaxID) error
+)
+
+var (
+	mu       sync.RWMutex
+	handlers = map[string][]jobs.StripeEventHandler{}
+)
+
+// OnCheckoutSession registers a handler for checkout.session.* events
+func OnCheckoutSession(eventType string, h CheckoutSessionHandler) {
+	register(eventType, billing.Gateway.DecodeCheckoutSession, h)
+}
+
+// OnSubscription registers a handler for customer.subscription.* events
+func OnSubscription(eventType string, h SubscriptionHandler) {
+	register(eventType, billing.Gateway.DecodeSubscription, h)
+}
+
+// OnInvoice registers a handler for invoice.* events
+func OnInvoice(eventType string, h InvoiceHandler) {
+	register(eventType, billing.Gateway.DecodeInvoice, h)
+}
+
+// OnPaymentMethod registers a handler for payment_method.* events
+func OnPaymentMethod(eventType string, h PaymentMethodHandler) {
+	register(eventType, billing.Gateway.DecodePaymentMethod, h)
+}
+
+// OnTaxID registers a handler for customer.tax_id.* events
+func OnTaxID(eventType string, h TaxIDHandler) {
+	register(eventType, billing.Gateway.DecodeTaxID, h)
+}
+
+// register adds a handler decoding the event's object with decode. Handlers
+// of the same type run in the order they were registered.
+func register[T any, H ~func(context.Context, *gorm.DB, *billing.Event, T) error](eventType string, decode func(billing.Gateway, *billing.Event) (T, error), h H) {
+	handle := func(ctx context.Context, db *gorm.DB, event *billing.Event) error {
+		object, err := decode(billing.Client(), event)
+		if err != nil {
+			return &jobs.PermanentError{Err: fmt.Errorf("error parsing webhook payload: %w", err)}
+		}
+		return h(ctx, db, event, object)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	handlers[eventType] = append(handlers[eventType], handle)
+}
+
+// Handle applies a queued Stripe webhook event with the handlers registered
+// for its type, stopping at the first that fails. Events of other types are
+// acknowledged without effect.
+func Handle(ctx context.Context, db *gorm.DB, event *billing.Event) error {
+	mu.RLock()
+	registered := handlers[event.Type]
+	mu.RUnlock()
+
+	for _, handle := range registered {
+		if err := handle(ctx, db, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
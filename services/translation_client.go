@@ -1,9 +1,12 @@
 package services
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"strings"
 	"time"
@@ -12,12 +15,38 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 
 	translationpb "github.com/ThinkInkTeam/thinkink-core-backend/proto-gen/proto/translation"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/chaos"
 )
 
+// ErrInvalidEEGPayload is returned for data that does not decode as an EEG payload
+var ErrInvalidEEGPayload = errors.New("invalid EEG payload")
+
+// ErrMLUnavailable is returned when the ML service cannot be reached
+var ErrMLUnavailable = errors.New("translation service unavailable")
+
 // EEGData represents the structure expected for EEG data
 type EEGData struct {
-	Eeg [][]float32 `json:"eeg"`
-	Msk []float32   `json:"mask"`
+	Eeg       [][]float32 `json:"eeg"`
+	Msk       []float32   `json:"mask"`
+	Impedance []float32   `json:"impedance,omitempty"` // Optional per-channel electrode impedance in kOhm
+	// Optional samples per second of each channel, in Hz
+	SamplingRate float64 `json:"sampling_rate,omitempty"`
+	// Optional structured notes for report templates; not sent to the ML service
+	Notes map[string]interface{} `json:"notes,omitempty"`
+}
+
+// ReportContent encodes the recording as report content in the current
+// schema, so reports are made from the decoded samples rather than by parsing
+// the file again. A recording without a mask gets one marking every sample valid.
+func ReportContent(data *EEGData) ([]byte, error) {
+	content := *data
+	if len(content.Msk) == 0 {
+		content.Msk = make([]float32, len(content.Eeg))
+		for i := range content.Msk {
+			content.Msk[i] = 1
+		}
+	}
+	return json.Marshal(content)
 }
 
 // TranslationClient wraps the gRPC translation client
@@ -104,6 +133,117 @@ func ParseEEGData(data []byte) ([][]float32, []float32, error) {
 
 }
 
+// ParseEEGPayload parses byte data into the full EEG payload, including optional fields
+func ParseEEGPayload(data []byte) (*EEGData, error) {
+	var eegData EEGData
+	if err := json.Unmarshal(data, &eegData); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEEGPayload, err)
+	}
+	return &eegData, nil
+}
+
+// DecodeEEGPayload reads an EEG payload from r one row at a time, so only the
+// decoded samples are held in memory and never the file's text. Fields other
+// than eeg, mask, impedance, sampling_rate and notes are skipped without being
+// decoded.
+func DecodeEEGPayload(r io.Reader) (*EEGData, error) {
+	dec := json.NewDecoder(bufio.NewReaderSize(r, 64<<10))
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEEGPayload, err)
+	}
+
+	var eegData EEGData
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidEEGPayload, err)
+		}
+		// Keys match case-insensitively, as with json.Unmarshal
+		key, _ := token.(string)
+		switch {
+		case strings.EqualFold(key, "eeg"):
+			eegData.Eeg, err = decodeEEGRows(dec)
+		case strings.EqualFold(key, "mask"):
+			err = dec.Decode(&eegData.Msk)
+		case strings.EqualFold(key, "impedance"):
+			err = dec.Decode(&eegData.Impedance)
+		case strings.EqualFold(key, "sampling_rate"):
+			err = dec.Decode(&eegData.SamplingRate)
+		case strings.EqualFold(key, "notes"):
+			// Notes that are not an object are ignored, as templates expect one
+			var notes interface{}
+			if err = dec.Decode(&notes); err == nil {
+				eegData.Notes, _ = notes.(map[string]interface{})
+			}
+		default:
+			err = skipJSONValue(dec)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidEEGPayload, err)
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEEGPayload, err)
+	}
+	return &eegData, nil
+}
+
+// decodeEEGRows decodes an array of sample rows, or null, one row at a time
+func decodeEEGRows(dec *json.Decoder) ([][]float32, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, nil
+	}
+	if token != json.Delim('[') {
+		return nil, fmt.Errorf("eeg must be an array of rows")
+	}
+
+	var rows [][]float32
+	for dec.More() {
+		var row []float32
+		if err := dec.Decode(&row); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, expectDelim(dec, ']')
+}
+
+// skipJSONValue reads past the next value without decoding it
+func skipJSONValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// expectDelim reads the next token and checks that it is delim
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %q, got %v", delim, token)
+	}
+	return nil
+}
+
 // TranslateEEGFromBytes parses byte data and sends it to the ML server for translation
 func (tc *TranslationClient) TranslateEEGFromBytes(token string, data []byte) ([]string, error) {
 	eeg, msk, err := ParseEEGData(data)
@@ -113,3 +253,41 @@ func (tc *TranslationClient) TranslateEEGFromBytes(token string, data []byte) ([
 
 	return tc.TranslateEEG(token, eeg, msk)
 }
+
+// TranslateSignal asks the ML service to translate an EEG file, returning why
+// no translation could be produced
+func TranslateSignal(ctx context.Context, address, authHeader string, fileData []byte) (string, error) {
+	payload, err := ParseEEGPayload(fileData)
+	if err != nil {
+		return "", err
+	}
+	return TranslatePayload(ctx, address, authHeader, payload)
+}
+
+// TranslatePayload asks the ML service to translate an already decoded EEG
+// payload, returning why no translation could be produced. Errors wrap
+// ErrMLUnavailable when the service could not be reached.
+func TranslatePayload(ctx context.Context, address, authHeader string, payload *EEGData) (string, error) {
+	if authHeader == "" {
+		return "", errors.New("no credentials for the translation service")
+	}
+	if chaos.FailTranslation(ctx) {
+		log.Printf("Chaos: failing translation request to ML server")
+		return "", fmt.Errorf("%w: injected fault", ErrMLUnavailable)
+	}
+
+	translationClient, err := NewTranslationClient(address)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrMLUnavailable, err)
+	}
+	defer translationClient.Close()
+
+	translations, err := translationClient.TranslateEEG(authHeader, payload.Eeg, payload.Msk)
+	if err != nil {
+		return "", err
+	}
+	if len(translations) == 0 {
+		return "", errors.New("the translation service returned no text")
+	}
+	return strings.Join(translations, " "), nil
+}
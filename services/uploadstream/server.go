@@ -0,0 +1,233 @@
+// Package uploadstream implements the gRPC upload service device clients
+// stream recordings to
+package uploadstream
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	pb "github.com/ThinkInkTeam/thinkink-core-backend/proto-gen/proto/upload"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the SignalUploadService gRPC server. Streamed
+// recordings are assembled into a POST /upload request served by the REST
+// router, so they pass the same authentication, rate limits, checks and
+// processing as REST uploads.
+type Server struct {
+	pb.UnimplementedSignalUploadServiceServer
+	router http.Handler
+}
+
+// NewServer creates a gRPC upload server feeding uploads to router
+func NewServer(router http.Handler) *Server {
+	return &Server{router: router}
+}
+
+// uploadResult is the JSON response of POST /upload
+type uploadResult struct {
+	Message       string   `json:"message"`
+	FileID        uint     `json:"file_id"`
+	JobID         uint     `json:"job_id"`
+	StatusURL     string   `json:"status_url"`
+	ReportID      uint     `json:"report_id"`
+	MatchingScale int      `json:"matching_scale"`
+	Warnings      []string `json:"warnings"`
+	Queued        bool     `json:"queued"`
+	ETASeconds    int      `json:"eta_seconds"`
+	Duplicate     bool     `json:"duplicate"`
+	DuplicateOfID *uint    `json:"duplicate_of_id"`
+}
+
+// UploadSignal implements the gRPC service method
+func (s *Server) UploadSignal(stream grpc.ClientStreamingServer[pb.UploadSignalRequest, pb.UploadSignalResponse]) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	header := first.GetHeader()
+	if header == nil {
+		return status.Error(codes.InvalidArgument, "the first message must be the upload header")
+	}
+	if header.Filename == "" {
+		return status.Error(codes.InvalidArgument, "filename is required")
+	}
+
+	// The multipart body is written as chunks arrive, so the recording is
+	// never held in memory
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		writer.CloseWithError(writeUploadForm(form, header, stream))
+	}()
+	// Stops the writer when the upload is refused before its body is read
+	defer body.Close()
+
+	ctx := stream.Context()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/upload", body)
+	if err != nil {
+		return status.Error(codes.Internal, "failed to build upload request")
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if auth := md.Get("authorization"); len(auth) > 0 {
+			req.Header.Set("Authorization", auth[0])
+		}
+	}
+	if header.RecordingId != "" {
+		req.Header.Set("X-Recording-ID", header.RecordingId)
+	}
+	if header.DeviceId != "" {
+		req.Header.Set("X-Device-ID", header.DeviceId)
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		req.RemoteAddr = p.Addr.String()
+	}
+
+	recorder := newResponseRecorder()
+	s.router.ServeHTTP(recorder, req)
+
+	if retryAfter := recorder.header.Get("Retry-After"); retryAfter != "" {
+		stream.SetTrailer(metadata.Pairs("retry-after", retryAfter))
+	}
+	if recorder.status != http.StatusOK && recorder.status != http.StatusAccepted {
+		return uploadError(recorder.status, recorder.body.Bytes())
+	}
+
+	var result uploadResult
+	if err := json.Unmarshal(recorder.body.Bytes(), &result); err != nil {
+		return status.Error(codes.Internal, "failed to read upload result")
+	}
+	response := &pb.UploadSignalResponse{
+		Message:       result.Message,
+		FileId:        uint32(result.FileID),
+		JobId:         uint32(result.JobID),
+		StatusUrl:     result.StatusURL,
+		ReportId:      uint32(result.ReportID),
+		MatchingScale: int32(result.MatchingScale),
+		Warnings:      result.Warnings,
+		Queued:        result.Queued,
+		EtaSeconds:    int32(result.ETASeconds),
+		Duplicate:     result.Duplicate,
+		Replay:        recorder.header.Get("X-Idempotent-Replay") == "true",
+	}
+	if result.DuplicateOfID != nil {
+		response.DuplicateOfId = uint32(*result.DuplicateOfID)
+	}
+	return stream.SendAndClose(response)
+}
+
+// writeUploadForm writes the header's options as form fields, then the
+// streamed chunks as the file part
+func writeUploadForm(form *multipart.Writer, header *pb.UploadHeader, stream grpc.ClientStreamingServer[pb.UploadSignalRequest, pb.UploadSignalResponse]) error {
+	fields := map[string]string{
+		"metadata": header.MetadataJson,
+		"notes":    header.NotesJson,
+		"sha256":   header.Sha256,
+	}
+	if header.MatchingScale != 0 {
+		fields["matchingScale"] = strconv.Itoa(int(header.MatchingScale))
+	}
+	if header.TemplateId != 0 {
+		fields["template_id"] = strconv.FormatUint(uint64(header.TemplateId), 10)
+	}
+	if header.AllowDuplicate {
+		fields["allow_duplicate"] = "true"
+	}
+	for name, value := range fields {
+		if value == "" {
+			continue
+		}
+		if err := form.WriteField(name, value); err != nil {
+			return err
+		}
+	}
+
+	part, err := form.CreateFormFile("file", header.Filename)
+	if err != nil {
+		return err
+	}
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if msg.GetHeader() != nil {
+			return fmt.Errorf("only the first message may be the upload header")
+		}
+		if _, err := part.Write(msg.GetChunk()); err != nil {
+			return err
+		}
+	}
+	return form.Close()
+}
+
+// uploadError turns a refused upload's response into a gRPC status
+func uploadError(httpStatus int, body []byte) error {
+	var response struct {
+		Error  string              `json:"error"`
+		Issues []services.EEGIssue `json:"issues"`
+	}
+	message := http.StatusText(httpStatus)
+	if err := json.Unmarshal(body, &response); err == nil && response.Error != "" {
+		message = response.Error
+		// What is wrong with an invalid recording is spelled out
+		for _, issue := range response.Issues {
+			message += "; " + issue.Message
+		}
+	}
+
+	code := codes.Internal
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusConflict:
+		code = codes.Aborted
+	case http.StatusPaymentRequired, http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		code = codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		code = codes.Unavailable
+	}
+	return status.Error(code, message)
+}
+
+// responseRecorder keeps the response the router writes for a streamed upload
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{header: make(http.Header), status: http.StatusOK}
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	return r.body.Write(p)
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+}
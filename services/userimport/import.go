@@ -0,0 +1,269 @@
+package userimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/email"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"gorm.io/gorm"
+)
+
+// BatchSize is how many rows are created per transaction
+const BatchSize = 100
+
+// MaxRows bounds a single import
+const MaxRows = 5000
+
+// requiredColumns must appear in the CSV header; other known columns are optional
+var requiredColumns = []string{"name", "email", "date_of_birth"}
+
+var knownColumns = map[string]bool{
+	"name": true, "email": true, "date_of_birth": true, "mobile": true, "country_code": true,
+	"address": true, "city": true, "country": true, "postal_code": true, "timezone": true, "locale": true,
+}
+
+// Row is one user to create. Line is the CSV line number, counting the header as line 1.
+type Row struct {
+	Line        int
+	Name        string
+	Email       string
+	DateOfBirth time.Time
+	Mobile      string
+	CountryCode string
+	Address     string
+	City        string
+	Country     string
+	PostalCode  string
+	Timezone    string
+	Locale      string
+}
+
+// RowError reports why a CSV line was not imported
+type RowError struct {
+	Line  int    `json:"line" example:"3"`
+	Email string `json:"email,omitempty" example:"jane@example.com"`
+	Error string `json:"error" example:"email already exists"`
+}
+
+// CreatedUser identifies an account created by the import
+type CreatedUser struct {
+	Line  int    `json:"line" example:"2"`
+	ID    uint   `json:"id" example:"42"`
+	Email string `json:"email" example:"john@example.com"`
+}
+
+// Result summarises an import
+type Result struct {
+	TotalRows int           `json:"total_rows" example:"120"`
+	Created   []CreatedUser `json:"created"`
+	Errors    []RowError    `json:"errors"`
+}
+
+// Options configure how imported accounts are created
+type Options struct {
+	OrganizationID *uint
+	// SetPasswordURL is the page the invitation token is appended to
+	SetPasswordURL string
+}
+
+// ParseCSV reads and validates users from CSV. Invalid lines are returned as
+// row errors; an error is returned only when the file as a whole is unusable.
+func ParseCSV(r io.Reader) ([]Row, []RowError, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !knownColumns[name] {
+			return nil, nil, fmt.Errorf("unknown column %q", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, fmt.Errorf("missing required column %q", name)
+		}
+	}
+	reader.FieldsPerRecord = len(header)
+
+	var rows []Row
+	var rowErrors []RowError
+	seen := map[string]int{}
+	line := 1
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rowErrors = append(rowErrors, RowError{Line: line, Error: parseErr.Err.Error()})
+				continue
+			}
+			return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		if len(rows)+len(rowErrors) >= MaxRows {
+			return nil, nil, fmt.Errorf("too many rows (max %d)", MaxRows)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := Row{
+			Line:        line,
+			Name:        field("name"),
+			Email:       strings.ToLower(field("email")),
+			Mobile:      field("mobile"),
+			CountryCode: field("country_code"),
+			Address:     field("address"),
+			City:        field("city"),
+			Country:     field("country"),
+			PostalCode:  field("postal_code"),
+			Timezone:    field("timezone"),
+			Locale:      field("locale"),
+		}
+
+		if err := validateRow(&row, field("date_of_birth")); err != nil {
+			rowErrors = append(rowErrors, RowError{Line: line, Email: row.Email, Error: err.Error()})
+			continue
+		}
+		if first, dup := seen[row.Email]; dup {
+			rowErrors = append(rowErrors, RowError{Line: line, Email: row.Email, Error: fmt.Sprintf("duplicate of line %d", first)})
+			continue
+		}
+		seen[row.Email] = line
+		rows = append(rows, row)
+	}
+
+	return rows, rowErrors, nil
+}
+
+// validateRow checks required fields and normalises optional ones
+func validateRow(row *Row, dateOfBirth string) error {
+	if row.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if _, err := mail.ParseAddress(row.Email); err != nil || row.Email == "" {
+		return fmt.Errorf("invalid email")
+	}
+
+	dob, err := time.Parse("2006-01-02", dateOfBirth)
+	if err != nil {
+		return fmt.Errorf("date_of_birth must be YYYY-MM-DD")
+	}
+	row.DateOfBirth = dob
+
+	if row.Timezone != "" {
+		if err := utils.ValidateTimezone(row.Timezone); err != nil {
+			return err
+		}
+	}
+	if row.Locale != "" {
+		locale, err := utils.ValidateLocale(row.Locale)
+		if err != nil {
+			return err
+		}
+		row.Locale = locale
+	}
+	return nil
+}
+
+// Import creates the accounts in transactions of BatchSize rows. A row that
+// fails is rolled back on its own and reported; the rest of its batch still
+// commits. Each created user is queued an invitation email with a temporary
+// password in the same transaction, so emails only go out for committed accounts.
+func Import(db *gorm.DB, rows []Row, opts Options) *Result {
+	result := &Result{TotalRows: len(rows), Created: []CreatedUser{}, Errors: []RowError{}}
+
+	for start := 0; start < len(rows); start += BatchSize {
+		end := start + BatchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		batch := rows[start:end]
+
+		var created []CreatedUser
+		var rowErrors []RowError
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, row := range batch {
+				tx.SavePoint("import_row")
+				user, err := createUser(tx, row, opts)
+				if err != nil {
+					tx.RollbackTo("import_row")
+					rowErrors = append(rowErrors, RowError{Line: row.Line, Email: row.Email, Error: err.Error()})
+					continue
+				}
+				created = append(created, CreatedUser{Line: row.Line, ID: user.ID, Email: user.Email})
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("User import batch starting at line %d failed: %v", batch[0].Line, err)
+			for _, row := range batch {
+				result.Errors = append(result.Errors, RowError{Line: row.Line, Email: row.Email, Error: "batch failed: " + err.Error()})
+			}
+			continue
+		}
+
+		result.Created = append(result.Created, created...)
+		result.Errors = append(result.Errors, rowErrors...)
+	}
+
+	return result
+}
+
+// createUser creates one account with an undisclosed random password and
+// queues an invitation with a single-use link for choosing the password
+func createUser(tx *gorm.DB, row Row, opts Options) (*models.User, error) {
+	password, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := models.CreateUser(tx, row.Name, row.Email, password, row.DateOfBirth, row.Mobile, row.CountryCode,
+		row.Address, row.City, row.Country, row.PostalCode, row.Timezone, row.Locale, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{"must_change_password": true}
+	if opts.OrganizationID != nil {
+		updates["organization_id"] = *opts.OrganizationID
+	}
+	if err := tx.Model(user).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	token, err := user.GenerateInvitationToken(tx)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := user.FormatTimestamp(time.Now().Add(models.InvitationTokenTTL))
+	body := fmt.Sprintf("Hi %s,\n\nAn account has been created for you on ThinkInk with the email address %s.\n\nChoose your password with the link below. It can be used once and expires at %s.\n\n%s\n", user.Name, user.Email, expiresAt, opts.SetPasswordURL+token)
+	if err := email.Enqueue(tx, user.Email, "Your ThinkInk account", body, "transactional"); err != nil {
+		return nil, fmt.Errorf("failed to queue invitation: %w", err)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,153 @@
+package validation
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+)
+
+// BlacklistSnapshot is an in-memory copy of the hashes of unexpired
+// blacklisted tokens. It is rebuilt by Refresh and kept current between
+// refreshes by Listen, so token validation can keep answering from memory
+// through brief database outages.
+type BlacklistSnapshot struct {
+	db           *gorm.DB
+	maxStaleness time.Duration
+
+	mu          sync.RWMutex
+	hashes      map[string]time.Time // token hash -> token expiry
+	refreshedAt time.Time
+}
+
+// NewBlacklistSnapshot creates an empty snapshot. It is trusted for
+// maxStaleness after each successful refresh.
+func NewBlacklistSnapshot(db *gorm.DB, maxStaleness time.Duration) *BlacklistSnapshot {
+	return &BlacklistSnapshot{
+		db:           db,
+		maxStaleness: maxStaleness,
+		hashes:       make(map[string]time.Time),
+	}
+}
+
+// Refresh reloads the snapshot from the database. On failure the previous
+// snapshot is kept until it goes stale.
+func (s *BlacklistSnapshot) Refresh(ctx context.Context) error {
+	tokens, err := models.FindActiveBlacklistedTokens(s.db.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to load token blacklist: %w", err)
+	}
+
+	hashes := make(map[string]time.Time, len(tokens))
+	for _, token := range tokens {
+		hashes[models.HashToken(token.Token)] = token.ExpiresAt
+	}
+
+	s.mu.Lock()
+	// Keep tokens announced while the query ran
+	for hash, expiresAt := range s.hashes {
+		if _, ok := hashes[hash]; !ok && time.Now().Before(expiresAt) {
+			hashes[hash] = expiresAt
+		}
+	}
+	s.hashes = hashes
+	s.refreshedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// Add records a blacklisted token hash until it expires
+func (s *BlacklistSnapshot) Add(hash string, expiresAt time.Time) {
+	s.mu.Lock()
+	s.hashes[hash] = expiresAt
+	s.mu.Unlock()
+}
+
+// Lookup reports whether the token is blacklisted according to the snapshot.
+// ok is false when the snapshot has never loaded or is older than its maximum
+// staleness, in which case the caller must check the database instead.
+func (s *BlacklistSnapshot) Lookup(token string) (blacklisted, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.refreshedAt.IsZero() || time.Since(s.refreshedAt) > s.maxStaleness {
+		return false, false
+	}
+	expiresAt, found := s.hashes[models.HashToken(token)]
+	return found && time.Now().Before(expiresAt), true
+}
+
+// Listen applies tokens announced on models.BlacklistChannel as they are
+// blacklisted, reconnecting after errors until ctx is cancelled
+func (s *BlacklistSnapshot) Listen(ctx context.Context) {
+	for ctx.Err() == nil {
+		if err := s.listen(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Token blacklist listener stopped: %v; reconnecting", err)
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// listen holds a dedicated connection in LISTEN mode until it fails
+func (s *BlacklistSnapshot) listen(ctx context.Context) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		pgConn := driverConn.(*stdlib.Conn).Conn()
+		if _, err := pgConn.Exec(ctx, "LISTEN "+pgx.Identifier{models.BlacklistChannel}.Sanitize()); err != nil {
+			return err
+		}
+
+		// Catch up on anything announced while the listener was down
+		if err := s.Refresh(ctx); err != nil {
+			log.Printf("%v", err)
+		}
+
+		for {
+			notification, err := pgConn.WaitForNotification(ctx)
+			if err != nil {
+				// The connection is left listening; discard it rather than return it to the pool
+				return fmt.Errorf("%w: %v", driver.ErrBadConn, err)
+			}
+			hash, expiresAt, err := parseBlacklistNotification(notification.Payload)
+			if err != nil {
+				log.Printf("Ignoring token blacklist notification: %v", err)
+				continue
+			}
+			s.Add(hash, expiresAt)
+		}
+	})
+}
+
+// parseBlacklistNotification decodes a "<token hash>:<expiry unix seconds>" payload
+func parseBlacklistNotification(payload string) (string, time.Time, error) {
+	hash, expiry, found := strings.Cut(payload, ":")
+	if !found || hash == "" {
+		return "", time.Time{}, fmt.Errorf("malformed payload %q", payload)
+	}
+	seconds, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("malformed expiry in payload %q", payload)
+	}
+	return hash, time.Unix(seconds, 0), nil
+}
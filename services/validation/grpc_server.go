@@ -13,10 +13,10 @@ type Server struct {
 	tokenValidator *TokenValidator
 }
 
-// NewServer creates a new gRPC validation server
+// NewServer creates a new gRPC validation server using the process-wide token validator
 func NewServer() *Server {
 	return &Server{
-		tokenValidator: NewTokenValidator(),
+		tokenValidator: Default(),
 	}
 }
 
@@ -1,21 +1,63 @@
 package validation
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/ThinkInkTeam/thinkink-core-backend/config"
 	"github.com/ThinkInkTeam/thinkink-core-backend/database"
 	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/services/entitlements"
 	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// maxKnownUsers bounds the last-known subscription cache before stale entries are pruned
+const maxKnownUsers = 10000
+
 // TokenValidator handles JWT token validation and user subscription checks
-type TokenValidator struct{}
+type TokenValidator struct {
+	blacklist *BlacklistSnapshot
+
+	// Last known subscription status per user, used while the database is unreachable
+	mu    sync.Mutex
+	known map[uint]knownUser
+}
+
+type knownUser struct {
+	subscribed bool
+	checkedAt  time.Time
+}
+
+// NewTokenValidator creates a new TokenValidator instance that checks the
+// blacklist against the given snapshot
+func NewTokenValidator(blacklist *BlacklistSnapshot) *TokenValidator {
+	return &TokenValidator{
+		blacklist: blacklist,
+		known:     make(map[uint]knownUser),
+	}
+}
+
+var defaultValidator atomic.Pointer[TokenValidator]
 
-// NewTokenValidator creates a new TokenValidator instance
-func NewTokenValidator() *TokenValidator {
-	return &TokenValidator{}
+// SetDefault installs the process-wide token validator. Call it at startup.
+func SetDefault(tv *TokenValidator) {
+	defaultValidator.Store(tv)
+}
+
+// Default returns the process-wide token validator. Without SetDefault it has
+// no blacklist snapshot and checks every token against the database.
+func Default() *TokenValidator {
+	if tv := defaultValidator.Load(); tv != nil {
+		return tv
+	}
+	defaultValidator.CompareAndSwap(nil, NewTokenValidator(NewBlacklistSnapshot(database.DB, 0)))
+	return defaultValidator.Load()
 }
 
 // ValidateToken validates a JWT token and checks if the user has an active subscription
@@ -28,9 +70,16 @@ func (tv *TokenValidator) ValidateToken(tokenString string) bool {
 	// Remove "Bearer " prefix if present
 	tokenString = strings.TrimPrefix(tokenString, "Bearer ")
 
-	// Check if token is blacklisted
-	isBlacklisted, err := models.IsTokenBlacklisted(database.DB, tokenString)
-	if err != nil || isBlacklisted {
+	// Check if token is blacklisted, from the snapshot while it is fresh
+	isBlacklisted, ok := tv.blacklist.Lookup(tokenString)
+	if !ok {
+		var err error
+		isBlacklisted, err = models.IsTokenBlacklisted(database.DB, tokenString)
+		if err != nil {
+			return false
+		}
+	}
+	if isBlacklisted {
 		return false
 	}
 
@@ -64,12 +113,77 @@ func (tv *TokenValidator) ValidateToken(tokenString string) bool {
 
 	userID := uint(userIDFloat.(float64))
 
-	// Find user and check subscription
+	// Device tokens may only reach the ingestion endpoints, as over HTTP
+	if !models.AudienceAllowed(models.TokenAudience(claims), models.MLServiceRoute) {
+		return false
+	}
+
+	// Find user and check subscription. Deactivated users are soft-deleted and not found.
 	user, err := models.FindUserByID(database.DB, userID)
 	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			tv.forget(userID)
+			return false
+		}
+		// Fall back to the last known status while the database is unreachable
+		subscribed, ok := tv.lastKnown(userID)
+		if ok {
+			log.Printf("Using last known subscription status for user %d: %v", userID, err)
+		}
+		return ok && subscribed
+	}
+
+	// Imported accounts must choose their own password first
+	if user.MustChangePassword {
 		return false
 	}
 
-	// Check if user has active subscription
-	return user.IsSubscribed()
+	// Check that the user's plan, of their own or through a seat of their
+	// organization, includes calling the ML service
+	ent, err := entitlements.Resolve(database.DB, user)
+	if err != nil {
+		subscribed, ok := tv.lastKnown(userID)
+		if ok {
+			log.Printf("Using last known subscription status for user %d: %v", userID, err)
+		}
+		return ok && subscribed
+	}
+	subscribed := ent.Allows(config.CapabilityMLAPI)
+	tv.remember(userID, subscribed)
+	return subscribed
+}
+
+// lastKnown returns the user's subscription status if it was seen within the
+// blacklist snapshot's maximum staleness
+func (tv *TokenValidator) lastKnown(userID uint) (bool, bool) {
+	tv.mu.Lock()
+	defer tv.mu.Unlock()
+
+	known, ok := tv.known[userID]
+	if !ok || time.Since(known.checkedAt) > tv.blacklist.maxStaleness {
+		return false, false
+	}
+	return known.subscribed, true
+}
+
+// remember records the user's current subscription status
+func (tv *TokenValidator) remember(userID uint, subscribed bool) {
+	tv.mu.Lock()
+	defer tv.mu.Unlock()
+
+	if len(tv.known) >= maxKnownUsers {
+		for id, known := range tv.known {
+			if time.Since(known.checkedAt) > tv.blacklist.maxStaleness {
+				delete(tv.known, id)
+			}
+		}
+	}
+	tv.known[userID] = knownUser{subscribed: subscribed, checkedAt: time.Now()}
+}
+
+// forget drops the user's status, e.g. once the account is gone
+func (tv *TokenValidator) forget(userID uint) {
+	tv.mu.Lock()
+	delete(tv.known, userID)
+	tv.mu.Unlock()
 }
@@ -0,0 +1,244 @@
+// Package webhooks delivers report lifecycle events to the URLs users
+// registered, signing each payload with the webhook's secret
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ThinkInkTeam/thinkink-core-backend/models"
+	"github.com/ThinkInkTeam/thinkink-core-backend/utils"
+	"gorm.io/gorm"
+)
+
+// Headers sent with every delivery
+const (
+	SignatureHeader = "X-ThinkInk-Signature"
+	EventHeader     = "X-ThinkInk-Event"
+	DeliveryHeader  = "X-ThinkInk-Delivery"
+)
+
+const (
+	batchSize = 50
+	// deliveryConcurrency bounds how many deliveries of a batch are in flight
+	deliveryConcurrency = 10
+	deliveryTimeout     = 10 * time.Second
+	// claimLease covers sending a whole batch, deliveryConcurrency at a
+	// time, twice over, so no other worker claims deliveries still being sent
+	claimLease  = 2 * ((batchSize+deliveryConcurrency-1)/deliveryConcurrency + 1) * deliveryTimeout
+	baseBackoff = 30 * time.Second
+	maxBackoff  = 6 * time.Hour
+	// MaxAttempts is how often a delivery is tried before it is marked failed
+	MaxAttempts = 8
+	// maxErrorLength bounds the response excerpt kept in the delivery log
+	maxErrorLength = 500
+)
+
+// errPrivateAddress is returned when a webhook URL resolves to an internal address
+var errPrivateAddress = errors.New("webhook URL resolves to a private address")
+
+// production reports whether webhook URLs must be public HTTPS endpoints
+func production() bool {
+	return utils.GetEnvWithDefault("APP_ENV", "development") == "production"
+}
+
+// ValidateURL checks that a webhook URL can receive deliveries. Outside
+// production plain HTTP is allowed for local testing.
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("webhook URL must be an absolute URL")
+	}
+	switch u.Scheme {
+	case "https":
+	case "http":
+		if production() {
+			return fmt.Errorf("webhook URL must use https")
+		}
+	default:
+		return fmt.Errorf("webhook URL must use https")
+	}
+	if u.User != nil {
+		return fmt.Errorf("webhook URL must not contain credentials")
+	}
+	if u.Fragment != "" {
+		return fmt.Errorf("webhook URL must not contain a fragment")
+	}
+	return nil
+}
+
+// Sign returns the signature header value for a payload sent at t:
+// t=<unix seconds>,v1=<hex HMAC-SHA256 of "<unix seconds>.<payload>">
+func Sign(secret string, t time.Time, payload []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// client posts deliveries without following redirects. In production it
+// refuses to connect to loopback, private and link-local addresses so
+// webhooks cannot reach internal services.
+var client = &http.Client{
+	Timeout: deliveryTimeout,
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				if !production() {
+					return nil
+				}
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				ip := net.ParseIP(host)
+				if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+					ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+					return errPrivateAddress
+				}
+				return nil
+			},
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	},
+}
+
+// DeliverDue claims and sends one batch of due deliveries, up to
+// deliveryConcurrency at a time
+func DeliverDue(ctx context.Context, db *gorm.DB) error {
+	deliveries, err := models.ClaimDueWebhookDeliveries(db, batchSize, claimLease)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, deliveryConcurrency)
+	for i := range deliveries {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			// The rest are sent once their lease expires
+			wg.Wait()
+			return nil
+		}
+		d := &deliveries[i]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			deliver(ctx, db, d)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// deliver posts a single delivery and records the outcome
+func deliver(ctx context.Context, db *gorm.DB, d *models.WebhookDelivery) {
+	subscription, err := models.FindWebhookSubscriptionByID(db, d.SubscriptionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// The webhook was deleted after the event was queued
+			if err := d.MarkFailed(db, 0, fmt.Errorf("webhook was deleted")); err != nil {
+				log.Printf("Failed to mark webhook delivery %d as failed: %v", d.ID, err)
+			}
+			return
+		}
+		log.Printf("Failed to find webhook for delivery %d: %v", d.ID, err)
+		return
+	}
+
+	status, err := post(ctx, subscription, d)
+	if err == nil {
+		if err := d.MarkDelivered(db, status); err != nil {
+			log.Printf("Failed to mark webhook delivery %d as delivered: %v", d.ID, err)
+		}
+		return
+	}
+
+	if d.Attempts+1 >= MaxAttempts {
+		log.Printf("Webhook delivery %d to %s failed permanently: %v", d.ID, subscription.URL, err)
+		if err := d.MarkFailed(db, status, err); err != nil {
+			log.Printf("Failed to mark webhook delivery %d as failed: %v", d.ID, err)
+		}
+		return
+	}
+	next := time.Now().Add(backoff(d.Attempts))
+	if err := d.MarkRetry(db, status, err, next); err != nil {
+		log.Printf("Failed to schedule retry for webhook delivery %d: %v", d.ID, err)
+	}
+}
+
+// post sends the delivery's payload and returns the response status. Any
+// status outside 2xx is an error.
+func post(ctx context.Context, subscription *models.WebhookSubscription, d *models.WebhookDelivery) (int, error) {
+	if err := ValidateURL(subscription.URL); err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ThinkInk-Webhooks/1.0")
+	req.Header.Set(EventHeader, d.Event)
+	req.Header.Set(DeliveryHeader, d.EventID)
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, time.Now(), d.Payload))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, truncate(err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorLength))
+		message := "endpoint responded with " + resp.Status
+		if len(bytes.TrimSpace(body)) > 0 {
+			message += ": " + string(bytes.TrimSpace(body))
+		}
+		return resp.StatusCode, truncate(message)
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
+
+// truncate returns message as an error no longer than maxErrorLength
+func truncate(message string) error {
+	if len(message) > maxErrorLength {
+		message = message[:maxErrorLength]
+	}
+	return errors.New(message)
+}
+
+// backoff returns the delay before the retry following the given number of prior attempts
+func backoff(attempts int) time.Duration {
+	d := time.Duration(float64(baseBackoff) * math.Pow(2, float64(attempts)))
+	if d > maxBackoff || d <= 0 {
+		return maxBackoff
+	}
+	return d
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	DefaultTimezone = "UTC"
+	DefaultLocale   = "en-US"
+)
+
+// timestampLayouts maps locales to their conventional date-time layout.
+// Exact tags win over their base language; anything else falls back to en-US.
+var timestampLayouts = map[string]string{
+	"en-US": "Jan 2, 2006 3:04 PM MST",
+	"en":    "2 Jan 2006 15:04 MST",
+	"de":    "02.01.2006 15:04 MST",
+	"fr":    "02/01/2006 15:04 MST",
+	"es":    "02/01/2006 15:04 MST",
+	"it":    "02/01/2006 15:04 MST",
+	"pt":    "02/01/2006 15:04 MST",
+	"nl":    "02-01-2006 15:04 MST",
+	"ar":    "02/01/2006 15:04 MST",
+	"ja":    "2006/01/02 15:04 MST",
+	"zh":    "2006-01-02 15:04 MST",
+	"ko":    "2006. 01. 02. 15:04 MST",
+}
+
+// ValidateTimezone checks that tz is UTC or a known IANA time zone name.
+// time.LoadLocation also accepts "" and "Local", which name no zone and would
+// follow the server's own setting, so they are rejected.
+func ValidateTimezone(tz string) error {
+	if tz == "" || tz == "Local" {
+		return fmt.Errorf("invalid timezone %q", tz)
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return fmt.Errorf("invalid timezone %q", tz)
+	}
+	return nil
+}
+
+// ValidateLocale checks that locale is a well-formed BCP 47 language tag and returns its canonical form
+func ValidateLocale(locale string) (string, error) {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return "", fmt.Errorf("invalid locale %q", locale)
+	}
+	return tag.String(), nil
+}
+
+// FormatLocalTime formats t in the given time zone using the locale's date-time layout.
+// Invalid or empty values fall back to UTC and en-US.
+func FormatLocalTime(t time.Time, tz, locale string) string {
+	loc, err := time.LoadLocation(tz)
+	if err != nil || ValidateTimezone(tz) != nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format(timestampLayout(locale))
+}
+
+// timestampLayout picks the layout for a locale
+func timestampLayout(locale string) string {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return timestampLayouts[DefaultLocale]
+	}
+	if layout, ok := timestampLayouts[tag.String()]; ok {
+		return layout
+	}
+	base, _ := tag.Base()
+	if layout, ok := timestampLayouts[base.String()]; ok {
+		return layout
+	}
+	return timestampLayouts[DefaultLocale]
+}